@@ -0,0 +1,61 @@
+package habit
+
+import "fmt"
+
+// Archive archives the Habit with the given name and saves the store. An
+// archived Habit is no longer tracked or shown in summaries, but its history is
+// kept and it can be listed with PrintArchived. An error is returned if the
+// Habit does not exist or is already archived, or if the store cannot be saved.
+func (t *Tracker) Archive(hbtName string) error {
+	return t.setArchived(hbtName, true)
+}
+
+// Unarchive restores the archived Habit with the given name so that it is
+// tracked again, and saves the store. An error is returned if the Habit does
+// not exist or is not archived, or if the store cannot be saved.
+func (t *Tracker) Unarchive(hbtName string) error {
+	return t.setArchived(hbtName, false)
+}
+
+// setArchived archives or unarchives the Habit with the given name and saves
+// the store.
+func (t *Tracker) setArchived(hbtName string, archived bool) error {
+	hbt, ok := t.store.Get(hbtName)
+	if !ok {
+		return errHabitNotFound(hbtName)
+	}
+	if hbt.Archived == archived {
+		if archived {
+			return fmt.Errorf("habit '%s' is already archived", hbtName)
+		}
+		return fmt.Errorf("habit '%s' is not archived", hbtName)
+	}
+	hbt.Archived = archived
+	t.store.Add(hbt)
+	err := t.save()
+	if err != nil {
+		return err
+	}
+	if archived {
+		fmt.Fprintf(t.output, "The habit '%s' has been archived. Its history is kept.\n", hbtName)
+		return nil
+	}
+	fmt.Fprintf(t.output, "The habit '%s' has been unarchived. Welcome back!\n", hbtName)
+	return nil
+}
+
+// PrintArchived writes each archived Habit with its current and longest
+// streaks, its frequency and its tags to the given Tracker's output, sorted by
+// name. If any tags are given, only the Habits with at least one of the tags
+// are listed. An error is returned if the list cannot be written.
+func (t *Tracker) PrintArchived(tags ...string) error {
+	habits := t.sortedHabits(true, tags...)
+	if len(habits) < 1 {
+		_, err := fmt.Fprintln(t.output, "You don't have any archived habits.")
+		if err != nil {
+			return fmt.Errorf("error writing list: %w", err)
+		}
+		return nil
+	}
+	return t.printHabits(habits)
+}
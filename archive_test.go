@@ -0,0 +1,109 @@
+package habit_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+)
+
+func TestTracker_ArchivedHabitIsOnlyListedAsArchived(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T13:00:00Z")
+	lastDone := habit.Now().Add(-time.Hour)
+	store, err := habit.OpenStore(t.TempDir() + "/test.store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{
+		Name:          "programming",
+		CurrentStreak: 2,
+		LongestStreak: 2,
+		LastDone:      lastDone,
+		History:       []habit.Completion{{At: lastDone}},
+	})
+	store.Add(habit.Habit{Name: "reading", CurrentStreak: 1, LongestStreak: 1, LastDone: lastDone})
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Archive("programming")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.PrintList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.PrintArchived()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "The habit 'programming' has been archived. Its history is kept.\n" +
+		"reading: current streak 1, longest streak 1, daily, last 30 days ·····························●\n" +
+		"programming: current streak 2, longest streak 2, daily\n"
+	got := output.String()
+	if want != got {
+		t.Errorf("want output %q, got output %q", want, got)
+	}
+	if summaries := tracker.Summarize(); len(summaries) != 1 || summaries[0].Name != "reading" {
+		t.Errorf("want summary of 'reading' only, got %v", summaries)
+	}
+	hbt, ok := store.Get("programming")
+	if !ok || len(hbt.History) != 1 {
+		t.Errorf("want archived habit with its history in store, got %v", hbt)
+	}
+}
+
+func TestTracker_TrackReturnsErrorForArchivedHabit(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T13:00:00Z")
+	store, err := habit.OpenStore(t.TempDir() + "/test.store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{Name: "programming", Archived: true})
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Track("programming")
+	if err == nil {
+		t.Error("expected an error tracking an archived habit")
+	}
+	err = tracker.Unarchive("programming")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Track("programming")
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTracker_ArchiveReturnsErrorForInvalidInput(t *testing.T) {
+	t.Parallel()
+	store, err := habit.OpenStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{Name: "programming"})
+	store.Add(habit.Habit{Name: "reading", Archived: true})
+	tracker, err := habit.NewTracker(habit.WithStore(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Archive("writing")
+	if err == nil {
+		t.Error("expected an error archiving a habit that does not exist")
+	}
+	err = tracker.Archive("reading")
+	if err == nil {
+		t.Error("expected an error archiving a habit that is already archived")
+	}
+	err = tracker.Unarchive("programming")
+	if err == nil {
+		t.Error("expected an error unarchiving a habit that is not archived")
+	}
+}
@@ -0,0 +1,461 @@
+package habit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// An AuditEntry records a change to a single Habit in an audit log. Only what
+// changed is recorded, such as the completion appended by tracking the Habit,
+// so that the Habit is rebuilt by replaying every entry for it since it was
+// created.
+type AuditEntry struct {
+	// At is the timestamp when the change was saved.
+	At time.Time `json:"at"`
+	// Operation is the operation that made the change, such as the name of
+	// the CLI command "track".
+	Operation string `json:"operation"`
+	// Habit is the name of the changed habit.
+	Habit string `json:"habit"`
+	// Created is true if the change created the habit.
+	Created bool `json:"created,omitempty"`
+	// Deleted is true if the change deleted the habit.
+	Deleted bool `json:"deleted,omitempty"`
+	// Fields holds the new JSON-encoded values of the habit's fields that
+	// changed, other than its history, by their JSON names. A field that
+	// changed to its zero value is null.
+	Fields map[string]json.RawMessage `json:"fields,omitempty"`
+	// Previous holds the JSON-encoded values of the same fields before the
+	// change, or of all the habit's fields if the change deleted it, so that
+	// the change can be reviewed and reverted. A field that changed from its
+	// zero value is null.
+	Previous map[string]json.RawMessage `json:"previous,omitempty"`
+	// Removed is the number of completions removed from the end of the
+	// habit's history, before Added were appended.
+	Removed int `json:"removed,omitempty"`
+	// RemovedCompletions holds the completions removed from the end of the
+	// habit's history, or its whole history if the change deleted it.
+	RemovedCompletions []Completion `json:"removed_completions,omitempty"`
+	// Added holds the completions appended to the habit's history.
+	Added []Completion `json:"added,omitempty"`
+}
+
+// newAuditEntry returns the AuditEntry recording the given Change, saved at
+// the given time by the given operation. The history of the Habit after the
+// change is recorded as the completions removed from the end of its history
+// before the change and those appended in their place.
+func newAuditEntry(at time.Time, operation string, ch Change) (AuditEntry, error) {
+	e := AuditEntry{At: at, Operation: operation, Habit: ch.Habit, Created: ch.Before == nil}
+	var before Habit
+	if ch.Before != nil {
+		before = *ch.Before
+	}
+	beforeFields, err := habitFields(before)
+	if err != nil {
+		return AuditEntry{}, err
+	}
+	if ch.After == nil {
+		e.Deleted = true
+		if ch.Before != nil {
+			e.Previous = beforeFields
+			e.RemovedCompletions = before.History
+		}
+		return e, nil
+	}
+	after := *ch.After
+	kept := 0
+	for kept < len(before.History) && kept < len(after.History) && sameCompletion(before.History[kept], after.History[kept]) {
+		kept++
+	}
+	e.Removed = len(before.History) - kept
+	e.RemovedCompletions = before.History[kept:]
+	e.Added = after.History[kept:]
+	afterFields, err := habitFields(after)
+	if err != nil {
+		return AuditEntry{}, err
+	}
+	for name, value := range afterFields {
+		if !bytes.Equal(beforeFields[name], value) {
+			e.setField(name, beforeFields[name], value)
+		}
+	}
+	for name, value := range beforeFields {
+		if _, ok := afterFields[name]; !ok {
+			e.setField(name, value, nil)
+		}
+	}
+	if e.Created {
+		e.Previous = nil
+	}
+	return e, nil
+}
+
+// setField records the given previous and new values of the habit field with
+// the given JSON name. A missing value is recorded as null.
+func (e *AuditEntry) setField(name string, previous, value json.RawMessage) {
+	if e.Fields == nil {
+		e.Fields = map[string]json.RawMessage{}
+		e.Previous = map[string]json.RawMessage{}
+	}
+	if previous == nil {
+		previous = json.RawMessage("null")
+	}
+	if value == nil {
+		value = json.RawMessage("null")
+	}
+	e.Previous[name] = previous
+	e.Fields[name] = value
+}
+
+// habitFields returns the JSON-encoded fields of the given Habit, other than
+// its history, by their JSON names.
+func habitFields(h Habit) (map[string]json.RawMessage, error) {
+	h.History = nil
+	data, err := json.Marshal(h)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding habit '%s': %w", h.Name, err)
+	}
+	fields := map[string]json.RawMessage{}
+	err = json.Unmarshal(data, &fields)
+	return fields, err
+}
+
+// apply returns the Habit as it was after the change recorded by the
+// AuditEntry to the given Habit, which is nil if it did not exist, or nil if
+// the change deleted it. An error is returned if the entry cannot be applied
+// to the Habit.
+func (e AuditEntry) apply(h *Habit) (*Habit, error) {
+	if e.Deleted {
+		return nil, nil
+	}
+	var before Habit
+	if h != nil && !e.Created {
+		before = *h
+	}
+	fields, err := habitFields(before)
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range e.Fields {
+		if string(value) == "null" {
+			delete(fields, name)
+			continue
+		}
+		fields[name] = value
+	}
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	var after Habit
+	err = json.Unmarshal(data, &after)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding fields of habit '%s': %w", e.Habit, err)
+	}
+	if e.Removed > len(before.History) {
+		return nil, fmt.Errorf("cannot remove %d completions from the %d of habit '%s'", e.Removed, len(before.History), e.Habit)
+	}
+	kept := before.History[:len(before.History)-e.Removed]
+	after.History = append(append([]Completion(nil), kept...), e.Added...)
+	if len(after.History) == 0 {
+		after.History = nil
+	}
+	return &after, nil
+}
+
+// auditStore is a Store that appends an AuditEntry to an audit log for every
+// Habit that changed each time it is saved.
+type auditStore struct {
+	Store
+	// path is the path of the audit log file.
+	path string
+	// operation is recorded as the Operation of every AuditEntry.
+	operation string
+	// changeSet records the Habits changed since the last save.
+	changeSet
+}
+
+// WithAuditLog returns a Store that keeps its Habits in the given Store and,
+// each time it is saved, appends an AuditEntry for every Habit that changed
+// since the last save, attributed to the given operation, to the audit log
+// file at the given path. The audit log is created if it does not exist and is
+// only ever appended to, one JSON-encoded AuditEntry per line, so that it can
+// be reviewed with PrintAuditLog and replayed with RestoreAuditLog.
+func WithAuditLog(s Store, path, operation string) Store {
+	return &auditStore{Store: s, path: path, operation: operation}
+}
+
+// Add adds or updates the given habit in the underlying store.
+func (a *auditStore) Add(h Habit) {
+	a.touch(a.Store, h.Name)
+	a.Store.Add(h)
+}
+
+// Delete deletes the habit with the given name from the underlying store.
+func (a *auditStore) Delete(name string) {
+	a.touch(a.Store, name)
+	a.Store.Delete(name)
+}
+
+// Save saves the underlying store and then appends an AuditEntry for each
+// Habit changed since the last save to the audit log. An error is returned if
+// the store cannot be saved or the audit log cannot be written.
+func (a *auditStore) Save() error {
+	return a.SaveContext(context.Background())
+}
+
+// SaveContext saves the underlying store under the given context and then
+// appends the audit log like Save. Nothing is logged if the context is done
+// before the store is saved.
+func (a *auditStore) SaveContext(ctx context.Context) error {
+	err := SaveContext(ctx, a.Store)
+	if err != nil {
+		return err
+	}
+	changes := a.changes(a.Store)
+	a.reset()
+	if len(changes) == 0 {
+		return nil
+	}
+	f, err := os.OpenFile(a.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("store saved, but cannot write audit log: %w", err)
+	}
+	defer f.Close()
+	enc := json.NewEncoder(f)
+	now := Now()
+	for _, ch := range changes {
+		e, err := newAuditEntry(now, a.operation, ch)
+		if err == nil {
+			err = enc.Encode(e)
+		}
+		if err != nil {
+			return fmt.Errorf("store saved, but cannot write audit log: %w", err)
+		}
+	}
+	return f.Close()
+}
+
+// Reload reloads the underlying store, if it supports reloading, discarding
+// the changes recorded since the last save.
+func (a *auditStore) Reload() error {
+	return a.LoadContext(context.Background())
+}
+
+// LoadContext reloads the underlying store under the given context like
+// Reload.
+func (a *auditStore) LoadContext(ctx context.Context) error {
+	a.reset()
+	return LoadContext(ctx, a.Store)
+}
+
+// lockChange takes the lock of the underlying store for a change and reloads
+// it, if it is only locked while it changes, discarding the changes recorded
+// since the last save.
+func (a *auditStore) lockChange() (func(), error) {
+	a.reset()
+	return lockChange(a.Store)
+}
+
+// unmigrated returns the data in the underlying store's file before any
+// migrations, if the underlying store has a file.
+func (a *auditStore) unmigrated() (int, map[string]Habit, error) {
+	return unmigrated(a.Store)
+}
+
+// unencrypted reports whether the underlying store is encrypted but its file
+// is not yet.
+func (a *auditStore) unencrypted() bool {
+	return unencrypted(a.Store)
+}
+
+// syncCodec returns the codec of the habit data the underlying store syncs
+// with a remote.
+func (a *auditStore) syncCodec() codec {
+	return syncCodec(a.Store)
+}
+
+// Close closes the underlying store, if it needs closing.
+func (a *auditStore) Close() error {
+	if c, ok := a.Store.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// ReadAuditLog reads the AuditEntries of an audit log written by a Store
+// returned by WithAuditLog from r, in the order they were written. Entries
+// that record the whole Habit before and after the change, as earlier
+// versions wrote them, are read as the AuditEntries recording what changed.
+// An error is returned if the log cannot be read or decoded.
+func ReadAuditLog(r io.Reader) ([]AuditEntry, error) {
+	var entries []AuditEntry
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 16<<20)
+	for line := 1; sc.Scan(); line++ {
+		if strings.TrimSpace(sc.Text()) == "" {
+			continue
+		}
+		var e struct {
+			AuditEntry
+			Before *Habit `json:"before"`
+			After  *Habit `json:"after"`
+		}
+		err := json.Unmarshal(sc.Bytes(), &e)
+		if err == nil && (e.Before != nil || e.After != nil) {
+			e.AuditEntry, err = newAuditEntry(e.At, e.Operation, Change{Habit: e.Habit, Before: e.Before, After: e.After})
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid audit log entry on line %d: %w", line, err)
+		}
+		entries = append(entries, e.AuditEntry)
+	}
+	return entries, sc.Err()
+}
+
+// PrintAuditLog writes the changes recorded in the audit log read from r to
+// the given Tracker's output, one per line and oldest first, with the time,
+// the operation and a description of the change. If a habit name is given,
+// only the changes to that Habit are written. An error is returned if the log
+// cannot be read.
+func (t *Tracker) PrintAuditLog(r io.Reader, hbtName string) error {
+	entries, err := ReadAuditLog(r)
+	if err != nil {
+		return err
+	}
+	// The Habits are rebuilt as the log is read, so that each change can be
+	// described by comparing the Habit before and after it.
+	habits := map[string]*Habit{}
+	printed := 0
+	for _, e := range entries {
+		before, known := habits[e.Habit]
+		after, err := e.apply(before)
+		if err != nil {
+			return err
+		}
+		habits[e.Habit] = after
+		if hbtName != "" && e.Habit != hbtName {
+			continue
+		}
+		if !known && !e.Created {
+			// The log was started after the Habit was created, so its
+			// state before the change is not known.
+			before = nil
+		}
+		fmt.Fprintf(t.output, "%s  %-8s %s\n", e.At.In(t.calendar.location).Format(logTimeFormat),
+			e.Operation, describeChange(e, before, after))
+		printed++
+	}
+	if printed == 0 {
+		fmt.Fprintln(t.output, "No changes have been recorded.")
+	}
+	return nil
+}
+
+// describeChange returns a description of the change recorded by the given
+// AuditEntry, which changed the given Habit before into the given Habit after,
+// such as "updated 'reading' (streak 3 -> 4, 3 -> 4 completions)". The
+// streak and completions are left out if before is nil for an update, since
+// the Habit is not known before it.
+func describeChange(e AuditEntry, before, after *Habit) string {
+	switch {
+	case e.Created:
+		return fmt.Sprintf("created '%s'", e.Habit)
+	case e.Deleted:
+		return fmt.Sprintf("deleted '%s'", e.Habit)
+	case before == nil:
+		return fmt.Sprintf("updated '%s'", e.Habit)
+	}
+	var changes []string
+	if before.CurrentStreak != after.CurrentStreak {
+		changes = append(changes, fmt.Sprintf("streak %d -> %d", before.CurrentStreak, after.CurrentStreak))
+	}
+	if len(before.History) != len(after.History) {
+		changes = append(changes, fmt.Sprintf("%d -> %d completions", len(before.History), len(after.History)))
+	}
+	if len(changes) == 0 {
+		return fmt.Sprintf("updated '%s'", e.Habit)
+	}
+	return fmt.Sprintf("updated '%s' (%s)", e.Habit, strings.Join(changes, ", "))
+}
+
+// RestoreAuditLog reconstructs habit data by replaying the audit log read from
+// r into the given Store, which should be empty, and saves the Store. The
+// Habits are restored as they were after the last change recorded for each,
+// by applying every change recorded for them since they were created, so the
+// log must have been kept since the original store was created. An error is
+// returned if the log cannot be read, if it records no changes, if it records
+// a change to a Habit before creating it, or if the Store cannot be saved.
+func RestoreAuditLog(r io.Reader, s Store) error {
+	entries, err := ReadAuditLog(r)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return errors.New("the audit log records no changes to restore")
+	}
+	habits := map[string]*Habit{}
+	var names []string
+	for _, e := range entries {
+		before, known := habits[e.Habit]
+		if before == nil && !e.Created && !e.Deleted {
+			return fmt.Errorf("the audit log records a change to habit '%s' on %s before creating it",
+				e.Habit, e.At.Format(time.RFC3339))
+		}
+		if !known {
+			names = append(names, e.Habit)
+		}
+		habits[e.Habit], err = e.apply(before)
+		if err != nil {
+			return err
+		}
+	}
+	for _, name := range names {
+		if habits[name] == nil {
+			s.Delete(name)
+			continue
+		}
+		s.Add(*habits[name])
+	}
+	return s.Save()
+}
+
+// damaged returns the data that the underlying store could not decode, if it
+// was opened with WithSalvage.
+func (a *auditStore) damaged() []damagedRecord {
+	return damagedRecords(a.Store)
+}
+
+// quarantine moves the data that the underlying store could not decode to a
+// quarantine file.
+func (a *auditStore) quarantine() (string, error) {
+	return quarantine(a.Store)
+}
+
+// backups returns the backups of the underlying store's file, if it keeps
+// any.
+func (a *auditStore) backups() ([]Backup, error) {
+	b, ok := a.Store.(backupStore)
+	if !ok {
+		return nil, errNoBackups
+	}
+	return b.backups()
+}
+
+// backupHabits returns the habits of the underlying store's backup with the
+// given ID.
+func (a *auditStore) backupHabits(id string) (map[string]Habit, error) {
+	b, ok := a.Store.(backupStore)
+	if !ok {
+		return nil, errNoBackups
+	}
+	return b.backupHabits(id)
+}
@@ -0,0 +1,289 @@
+package habit_test
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+// auditChange summarizes an AuditEntry.
+type auditChange struct {
+	At, Operation, Habit string
+	Created, Deleted     bool
+}
+
+func readAuditChanges(t *testing.T, path string) []auditChange {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	entries, err := habit.ReadAuditLog(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var changes []auditChange
+	for _, e := range entries {
+		changes = append(changes, auditChange{
+			At: e.At.Format("15:04"), Operation: e.Operation, Habit: e.Habit,
+			Created: e.Created, Deleted: e.Deleted,
+		})
+	}
+	return changes
+}
+
+func TestWithAuditLog_RecordsEveryChangedHabitOnSave(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T09:00:00Z")
+	logPath := filepath.Join(t.TempDir(), "audit.log")
+	inner := &memStore{habits: map[string]habit.Habit{}}
+	tracker, err := habit.NewTracker(
+		habit.WithStore(habit.WithAuditLog(inner, logPath, "track")),
+		habit.WithOutput(new(bytes.Buffer)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.TrackAll("programming", "reading")
+	if err != nil {
+		t.Fatal(err)
+	}
+	habit.Now = getTimeFunc(t, "2024-02-06T10:00:00Z")
+	err = tracker.Rename("reading", "books")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []auditChange{
+		{At: "09:00", Operation: "track", Habit: "programming", Created: true},
+		{At: "09:00", Operation: "track", Habit: "reading", Created: true},
+		{At: "10:00", Operation: "track", Habit: "books", Created: true},
+		{At: "10:00", Operation: "track", Habit: "reading", Deleted: true},
+	}
+	if got := readAuditChanges(t, logPath); !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+	if inner.saves != 2 {
+		t.Errorf("want 2 saves of the underlying store, got %d", inner.saves)
+	}
+}
+
+func TestWithAuditLog_RecordsOnlyWhatChanged(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-05T09:00:00Z")
+	logPath := filepath.Join(t.TempDir(), "audit.log")
+	store := &memStore{habits: map[string]habit.Habit{}}
+	tracker, err := habit.NewTracker(
+		habit.WithStore(habit.WithAuditLog(store, logPath, "track")),
+		habit.WithOutput(new(bytes.Buffer)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, now := range []string{"2024-02-05T09:00:00Z", "2024-02-06T09:00:00Z", "2024-02-07T09:00:00Z"} {
+		habit.Now = getTimeFunc(t, now)
+		err = tracker.Track("reading")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	f, err := os.Open(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	entries, err := habit.ReadAuditLog(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("want 3 entries, got %d", len(entries))
+	}
+	last := entries[2]
+	wantAdded := []habit.Completion{{At: time.Date(2024, time.February, 7, 9, 0, 0, 0, time.UTC)}}
+	if last.Created || last.Removed != 0 || !cmp.Equal(wantAdded, last.Added) {
+		t.Errorf("want only the new completion recorded, got %+v", last)
+	}
+	wantFields := []string{"current_streak", "last_done", "longest_streak", "undo"}
+	var gotFields []string
+	for name := range last.Fields {
+		gotFields = append(gotFields, name)
+	}
+	sort.Strings(gotFields)
+	if !cmp.Equal(wantFields, gotFields) {
+		t.Error(cmp.Diff(wantFields, gotFields))
+	}
+	_, err = f.Seek(0, io.SeekStart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored := &memStore{habits: map[string]habit.Habit{}}
+	err = habit.RestoreAuditLog(f, restored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(store.habits, restored.habits) {
+		t.Error(cmp.Diff(store.habits, restored.habits))
+	}
+}
+
+func TestWithAuditLog_RecordsPreviousValuesOfChanges(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T09:00:00Z")
+	logPath := filepath.Join(t.TempDir(), "audit.log")
+	day := time.Date(2024, time.February, 5, 9, 0, 0, 0, time.UTC)
+	store := habit.WithAuditLog(&memStore{habits: map[string]habit.Habit{
+		"reading": {Name: "reading", CurrentStreak: 1, LongestStreak: 1, LastDone: day,
+			Tags: []string{"books"}, History: []habit.Completion{{At: day}}},
+	}}, logPath, "undo")
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(new(bytes.Buffer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Untag("reading", "books")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Delete("reading")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	entries, err := habit.ReadAuditLog(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("want 2 entries, got %+v", entries)
+	}
+	untag := entries[0]
+	if got := string(untag.Fields["tags"]); got != "null" {
+		t.Errorf("want tags recorded as removed, got %s", got)
+	}
+	if got := string(untag.Previous["tags"]); got != `["books"]` {
+		t.Errorf("want previous tags recorded, got %s", got)
+	}
+	del := entries[1]
+	if !del.Deleted || string(del.Previous["current_streak"]) != "1" {
+		t.Errorf("want the deleted habit's fields recorded, got %+v", del)
+	}
+	want := []habit.Completion{{At: day}}
+	if !cmp.Equal(want, del.RemovedCompletions) {
+		t.Error(cmp.Diff(want, del.RemovedCompletions))
+	}
+}
+
+func TestRestoreAuditLogReturnsErrorGivenChangeBeforeCreation(t *testing.T) {
+	t.Parallel()
+	log := `{"at":"2024-02-07T08:00:00Z","operation":"track","habit":"reading","fields":{"current_streak":2},"added":[{"at":"2024-02-07T08:00:00Z"}]}
+`
+	err := habit.RestoreAuditLog(strings.NewReader(log), &memStore{habits: map[string]habit.Habit{}})
+	if err == nil {
+		t.Error("want error restoring a log started after the habit was created")
+	}
+}
+
+func TestWithAuditLog_SkipsUnchangedHabits(t *testing.T) {
+	t.Parallel()
+	logPath := filepath.Join(t.TempDir(), "audit.log")
+	hbt := habit.Habit{Name: "reading", CurrentStreak: 1, LongestStreak: 1}
+	store := habit.WithAuditLog(&memStore{habits: map[string]habit.Habit{"reading": hbt}}, logPath, "tag")
+	store.Add(hbt)
+	store.Add(habit.Habit{Name: "scratch"})
+	store.Delete("scratch")
+	err := store.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = os.Stat(logPath)
+	if !os.IsNotExist(err) {
+		t.Errorf("want no audit log without changes, got %v", err)
+	}
+}
+
+func TestTracker_PrintAuditLogDescribesChanges(t *testing.T) {
+	t.Parallel()
+	log := `{"at":"2024-02-06T09:00:00Z","operation":"track","habit":"reading","after":{"name":"reading","current_streak":1,"history":[{"at":"2024-02-06T09:00:00Z"}]}}
+{"at":"2024-02-07T08:00:00Z","operation":"track","habit":"reading","before":{"name":"reading","current_streak":1,"history":[{"at":"2024-02-06T09:00:00Z"}]},"after":{"name":"reading","current_streak":2,"history":[{"at":"2024-02-06T09:00:00Z"},{"at":"2024-02-07T08:00:00Z"}]}}
+{"at":"2024-02-07T08:05:00Z","operation":"tag","habit":"reading","before":{"name":"reading","current_streak":2},"after":{"name":"reading","current_streak":2,"tags":["books"]}}
+{"at":"2024-02-08T20:00:00Z","operation":"delete","habit":"programming","before":{"name":"programming"}}
+`
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(&memStore{}), habit.WithOutput(output), habit.WithLocation(time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.PrintAuditLog(strings.NewReader(log), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "2024-02-06 09:00  track    created 'reading'\n" +
+		"2024-02-07 08:00  track    updated 'reading' (streak 1 -> 2, 1 -> 2 completions)\n" +
+		"2024-02-07 08:05  tag      updated 'reading'\n" +
+		"2024-02-08 20:00  delete   deleted 'programming'\n"
+	if got := output.String(); want != got {
+		t.Error(cmp.Diff(want, got))
+	}
+	output.Reset()
+	err = tracker.PrintAuditLog(strings.NewReader(log), "writing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = "No changes have been recorded.\n"
+	if got := output.String(); want != got {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestRestoreAuditLog_ReplaysChangesIntoStore(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T09:00:00Z")
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "audit.log")
+	original := &memStore{habits: map[string]habit.Habit{}}
+	tracker, err := habit.NewTracker(
+		habit.WithStore(habit.WithAuditLog(original, logPath, "track")),
+		habit.WithOutput(new(bytes.Buffer)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"programming", "reading", "scratch"} {
+		err = tracker.Track(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = tracker.Delete("scratch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Rename("reading", "books")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	restored := &memStore{habits: map[string]habit.Habit{}}
+	err = habit.RestoreAuditLog(f, restored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(original.habits, restored.habits) {
+		t.Error(cmp.Diff(original.habits, restored.habits))
+	}
+	if restored.saves != 1 {
+		t.Errorf("want 1 save, got %d", restored.saves)
+	}
+}
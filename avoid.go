@@ -0,0 +1,86 @@
+package habit
+
+import (
+	"fmt"
+	"time"
+)
+
+// Avoid starts tracking a new Habit with the given name that is something to
+// avoid, such as smoking, and saves the store. The streak of a Habit to avoid
+// is the number of days since it was started or since the last relapse
+// recorded with Relapse, and grows without the Habit being tracked. An error is
+// returned if a Habit with the name already exists or the store cannot be
+// saved.
+func (t *Tracker) Avoid(hbtName string) error {
+	_, ok := t.store.Get(hbtName)
+	if ok {
+		return fmt.Errorf("habit '%s' already exists", hbtName)
+	}
+	t.store.Add(Habit{
+		Name:     hbtName,
+		Avoid:    true,
+		LastDone: t.now(),
+	})
+	err := t.save()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(t.output, "Good luck avoiding '%s'! Your streak grows every day you stay away from it.\n", hbtName)
+	return nil
+}
+
+// Relapse records that the Habit to avoid with the given name was done,
+// restarting its streak, and saves the store. An error is returned if the
+// Habit does not exist or is not a Habit to avoid, or if the store cannot be
+// saved.
+func (t *Tracker) Relapse(hbtName string) error {
+	hbt, ok := t.store.Get(hbtName)
+	if !ok {
+		return errHabitNotFound(hbtName)
+	}
+	if !hbt.Avoid {
+		return fmt.Errorf("habit '%s' is not a habit to avoid", hbtName)
+	}
+	now := t.now()
+	current, longest := hbt.streaks(now, t.calendar)
+	hbt.LongestStreak = longest
+	hbt.CurrentStreak = 0
+	hbt.LastDone = now
+	hbt.History = append(hbt.History, Completion{At: now})
+	hbt.Undo = nil
+	t.store.Add(hbt)
+	err := t.save()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(t.output, "You avoided '%s' for %d %s before this relapse. Your streak starts again today. You can do it!\n",
+		hbtName, current, Daily.unit(current))
+	t.emit(Event{Type: EventStreakBroken, Habit: hbtName, Streak: current, Frequency: Daily, At: now})
+	return nil
+}
+
+// streaks returns the Habit's current and longest streaks as of the given
+// timestamp, with calendar dates taken from the given calendar. For a Habit to
+// avoid, the current streak is the number of days since it was started or
+// last done; for other Habits, both are the streaks stored on the Habit.
+func (h Habit) streaks(now time.Time, cal calendar) (current, longest int) {
+	if !h.Avoid {
+		return h.CurrentStreak, h.LongestStreak
+	}
+	current = cal.daysBetween(h.LastDone, now)
+	if current < 0 {
+		current = 0
+	}
+	return current, max(current, h.LongestStreak)
+}
+
+// summarizeAvoid returns the summary message for the given Habit to avoid as
+// of the given timestamp.
+func (t *Tracker) summarizeAvoid(hbt Habit, now time.Time) string {
+	data := t.messageData(hbt)
+	data.Streak, _ = hbt.streaks(now, t.calendar)
+	if data.Streak > 1 && data.Streak > hbt.LongestStreak {
+		return t.text("summary_avoid_best", data)
+	}
+	return t.text("summary_avoid", data)
+}
@@ -0,0 +1,113 @@
+package habit_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+)
+
+func TestTracker_AvoidStreakGrowsWithDaysSinceLastRelapse(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-01T20:00:00Z")
+	store, err := habit.OpenStore(t.TempDir() + "/test.store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Avoid("smoking")
+	if err != nil {
+		t.Fatal(err)
+	}
+	habit.Now = getTimeFunc(t, "2024-02-06T09:00:00Z")
+	err = tracker.PrintList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Relapse("smoking")
+	if err != nil {
+		t.Fatal(err)
+	}
+	habit.Now = getTimeFunc(t, "2024-02-08T09:00:00Z")
+	err = tracker.PrintSummary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Good luck avoiding 'smoking'! Your streak grows every day you stay away from it.\n" +
+		"smoking: current streak 5, longest streak 5, avoid, last 30 days ························●●●●●·\n" +
+		"You avoided 'smoking' for 5 days before this relapse. Your streak starts again today. You can do it!\n" +
+		"You've avoided 'smoking' for 2 days. Keep it up!\n"
+	got := output.String()
+	if want != got {
+		t.Errorf("want output %q, got output %q", want, got)
+	}
+	summaries := tracker.Summarize()
+	if len(summaries) != 1 || summaries[0].CurrentStreak != 2 || summaries[0].LongestStreak != 5 {
+		t.Errorf("want summary with current streak 2 and longest streak 5, got %v", summaries)
+	}
+}
+
+func TestTracker_PrintSummaryAnnouncesNewPersonalBestForHabitToAvoid(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-10T09:00:00Z")
+	store, err := habit.OpenStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{
+		Name:          "smoking",
+		Avoid:         true,
+		LongestStreak: 3,
+		LastDone:      time.Date(2024, time.February, 6, 22, 0, 0, 0, time.UTC),
+	})
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.PrintSummary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "You've avoided 'smoking' for 4 days. That's a new personal best. Keep it up!\n"
+	got := output.String()
+	if want != got {
+		t.Errorf("want output %q, got output %q", want, got)
+	}
+	if due := tracker.Due(); len(due) != 0 {
+		t.Errorf("want no habits to avoid to be due, got %d", len(due))
+	}
+}
+
+func TestTracker_AvoidAndRelapseReturnErrorForInvalidInput(t *testing.T) {
+	t.Parallel()
+	store, err := habit.OpenStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{Name: "running"})
+	store.Add(habit.Habit{Name: "smoking", Avoid: true})
+	tracker, err := habit.NewTracker(habit.WithStore(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Avoid("running")
+	if err == nil {
+		t.Error("expected an error avoiding a habit that already exists")
+	}
+	err = tracker.Relapse("drinking")
+	if err == nil {
+		t.Error("expected an error relapsing on a habit that does not exist")
+	}
+	err = tracker.Relapse("running")
+	if err == nil {
+		t.Error("expected an error relapsing on a habit that is not a habit to avoid")
+	}
+	err = tracker.Track("smoking")
+	if err == nil {
+		t.Error("expected an error tracking a habit to avoid")
+	}
+}
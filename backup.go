@@ -0,0 +1,248 @@
+package habit
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// backupTimeLayout is the layout of the timestamp that identifies a backup
+// kept by a store opened with WithBackupDir.
+const backupTimeLayout = "20060102T150405Z"
+
+// bakID is the ID of the backup kept with a ".bak" extension by a store opened
+// with WithBackup.
+const bakID = "bak"
+
+// A Backup is a copy of a store file as it was before one of its saves.
+type Backup struct {
+	// ID identifies the backup to RestoreBackup. It is the time the backup
+	// was made, formatted as YYYYMMDDTHHMMSSZ in UTC, or "bak" for the
+	// backup kept with a ".bak" extension.
+	ID string `json:"id"`
+	// At is the time the backup was made.
+	At time.Time `json:"at"`
+	// Size is the size of the backup file in bytes.
+	Size int64 `json:"size"`
+	// Path is the path of the backup file.
+	Path string `json:"path"`
+}
+
+// WithBackupDir returns a storeOption that makes a store copy its file into
+// the given directory before each save, named after the store file and the
+// time of the save, so that any of its recent versions can be restored with
+// Tracker.RestoreBackup. After each copy, the oldest backups are removed so
+// that at most keep backups are kept, and backups older than maxAge are
+// removed. A keep or maxAge of zero means no limit.
+func WithBackupDir(dir string, keep int, maxAge time.Duration) storeOption {
+	return func(s *FileStore) {
+		s.backupDir = dir
+		s.backupKeep = keep
+		s.backupMaxAge = maxAge
+	}
+}
+
+// rotateBackups copies the store's file into its backup directory and removes
+// the backups beyond its limits. It is a no-op if the store has no backup
+// directory or no file yet.
+func (s *FileStore) rotateBackups() error {
+	if s.backupDir == "" {
+		return nil
+	}
+	if _, err := os.Stat(s.path); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	err := os.MkdirAll(s.backupDir, 0o700)
+	if err != nil {
+		return err
+	}
+	backups, err := s.rotatedBackups()
+	if err != nil {
+		return err
+	}
+	now := Now().UTC()
+	id := now.Format(backupTimeLayout)
+	// Saves within the same second are told apart by a counter.
+	counter := 0
+	for _, b := range backups {
+		if stamp, _, _ := strings.Cut(b.ID, "-"); stamp == id {
+			counter = max(counter, backupCounter(b.ID))
+		}
+	}
+	if counter > 0 {
+		id += "-" + strconv.Itoa(counter+1)
+	}
+	path := filepath.Join(s.backupDir, filepath.Base(s.path)+"."+id)
+	err = backupFile(s.path, path)
+	if err != nil {
+		return err
+	}
+	backups, err = s.rotatedBackups()
+	if err != nil {
+		return err
+	}
+	for i, b := range backups {
+		if (s.backupKeep > 0 && i >= s.backupKeep) || (s.backupMaxAge > 0 && now.Sub(b.At) > s.backupMaxAge) {
+			err = os.Remove(b.Path)
+			if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// rotatedBackups returns the backups in the store's backup directory, most
+// recent first.
+func (s *FileStore) rotatedBackups() ([]Backup, error) {
+	if s.backupDir == "" {
+		return nil, nil
+	}
+	entries, err := os.ReadDir(s.backupDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	prefix := filepath.Base(s.path) + "."
+	var backups []Backup
+	for _, e := range entries {
+		id, ok := strings.CutPrefix(e.Name(), prefix)
+		if !ok || e.IsDir() {
+			continue
+		}
+		stamp, _, _ := strings.Cut(id, "-")
+		at, err := time.Parse(backupTimeLayout, stamp)
+		if err != nil {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		backups = append(backups, Backup{ID: id, At: at, Size: info.Size(), Path: filepath.Join(s.backupDir, e.Name())})
+	}
+	sort.SliceStable(backups, func(i, j int) bool {
+		if backups[i].At.Equal(backups[j].At) {
+			return backupCounter(backups[i].ID) > backupCounter(backups[j].ID)
+		}
+		return backups[i].At.After(backups[j].At)
+	})
+	return backups, nil
+}
+
+// backupCounter returns the counter telling apart backups made within the
+// same second with the given ID, which is 1 for the first of them.
+func backupCounter(id string) int {
+	_, counter, ok := strings.Cut(id, "-")
+	if !ok {
+		return 1
+	}
+	n, err := strconv.Atoi(counter)
+	if err != nil {
+		return 1
+	}
+	return n
+}
+
+// backups returns the backups of the store's file, most recent first: the
+// backup with a ".bak" extension, if there is one, followed by those in its
+// backup directory.
+func (s *FileStore) backups() ([]Backup, error) {
+	var backups []Backup
+	info, err := os.Stat(s.path + ".bak")
+	if err == nil {
+		backups = append(backups, Backup{ID: bakID, At: info.ModTime(), Size: info.Size(), Path: s.path + ".bak"})
+	}
+	rotated, err := s.rotatedBackups()
+	if err != nil {
+		return nil, fmt.Errorf("error listing backups of store %q: %w", s.path, err)
+	}
+	return append(backups, rotated...), nil
+}
+
+// backupHabits returns the habits of the store's backup with the given ID. An
+// error is returned if there is no such backup or it cannot be decoded.
+func (s *FileStore) backupHabits(id string) (map[string]Habit, error) {
+	backups, err := s.backups()
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range backups {
+		if b.ID != id {
+			continue
+		}
+		raw, err := os.ReadFile(b.Path)
+		if err != nil {
+			return nil, fmt.Errorf("error reading backup %q: %w", b.Path, err)
+		}
+		data := map[string]Habit{}
+		err = s.codec.Decode(bytes.NewReader(raw), &data)
+		if err != nil {
+			return nil, errorOf(ErrStoreCorrupt, "error decoding backup %q: %w", b.Path, err)
+		}
+		return data, nil
+	}
+	return nil, fmt.Errorf("no backup with id '%s'", id)
+}
+
+// A backupStore is a Store that keeps backups of its file.
+type backupStore interface {
+	// backups returns the backups of the store's file, most recent first.
+	backups() ([]Backup, error)
+	// backupHabits returns the habits of the backup with the given ID.
+	backupHabits(id string) (map[string]Habit, error)
+}
+
+// errNoBackups is returned for a Store that does not keep backups.
+var errNoBackups = errors.New("this store keeps no backups")
+
+// Backups returns the backups of the Tracker's store file, most recent first.
+// An error is returned if the store does not keep backups, such as a SQLite or
+// remote store, or if they cannot be listed.
+func (t *Tracker) Backups() ([]Backup, error) {
+	b, ok := t.store.(backupStore)
+	if !ok {
+		return nil, errNoBackups
+	}
+	return b.backups()
+}
+
+// RestoreBackup replaces the Habits in the Tracker's store with those of the
+// backup of its file with the given ID, as listed by Backups, saves the store
+// and writes a message saying so to the Tracker's output. If the store keeps
+// backups in a directory, the Habits replaced are themselves backed up by the
+// save, so that the restore can be undone. An error is returned if there is no
+// such backup, it cannot be decoded, or the store cannot be saved.
+func (t *Tracker) RestoreBackup(id string) error {
+	b, ok := t.store.(backupStore)
+	if !ok {
+		return errNoBackups
+	}
+	habits, err := b.backupHabits(id)
+	if err != nil {
+		return err
+	}
+	for _, hbt := range t.store.All() {
+		if _, ok := habits[hbt.Name]; !ok {
+			t.store.Delete(hbt.Name)
+		}
+	}
+	for _, hbt := range habits {
+		t.store.Add(hbt)
+	}
+	err = t.save()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(t.output, "Restored %d %s from the backup %s.\n", len(habits), plural(len(habits), "habit"), id)
+	return nil
+}
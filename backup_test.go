@@ -0,0 +1,122 @@
+package habit_test
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestStore_SaveWithBackupDirKeepsLimitedNumberOfBackups(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T13:00:00Z")
+	dir := t.TempDir()
+	path := filepath.Join(dir, "habit.store")
+	store, err := habit.OpenStore(path, habit.WithBackupDir(filepath.Join(dir, "backups"), 2, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"habit1", "habit2", "habit3", "habit4"} {
+		store.Add(habit.Habit{Name: name})
+		err = store.Save()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	backups, err := tracker.Backups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, b := range backups {
+		ids = append(ids, b.ID)
+	}
+	want := []string{"20240206T130000Z-3", "20240206T130000Z-2"}
+	if !cmp.Equal(want, ids) {
+		t.Error(cmp.Diff(want, ids))
+	}
+	err = tracker.RestoreBackup("20240206T130000Z-2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := "Restored 2 habits from the backup 20240206T130000Z-2.\n", output.String(); want != got {
+		t.Errorf("want output %q, got output %q", want, got)
+	}
+	wantHabits := []habit.Habit{{Name: "habit1"}, {Name: "habit2"}}
+	if got := store.All(); !cmp.Equal(wantHabits, got, habitSliceCmpOpt) {
+		t.Error(cmp.Diff(wantHabits, got, habitSliceCmpOpt))
+	}
+	// The habits replaced by the restore are backed up in turn.
+	backups, err = tracker.Backups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 2 || backups[0].ID != "20240206T130000Z-4" {
+		t.Errorf("want the replaced habits backed up first, got %v", backups)
+	}
+}
+
+func TestStore_SaveWithBackupDirRemovesBackupsOlderThanMaxAge(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-01-01T13:00:00Z")
+	dir := t.TempDir()
+	path := filepath.Join(dir, "habit.store")
+	store, err := habit.OpenStore(path, habit.WithBackupDir(filepath.Join(dir, "backups"), 0, 7*24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"habit1", "habit2"} {
+		store.Add(habit.Habit{Name: name})
+		err = store.Save()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	habit.Now = getTimeFunc(t, "2024-02-06T13:00:00Z")
+	store.Add(habit.Habit{Name: "habit3"})
+	err = store.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker, err := habit.NewTracker(habit.WithStore(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	backups, err := tracker.Backups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 1 || backups[0].ID != "20240206T130000Z" {
+		t.Errorf("want only the backup made on 2024-02-06, got %v", backups)
+	}
+}
+
+func TestTracker_RestoreBackupReturnsErrorForUnknownBackup(t *testing.T) {
+	t.Parallel()
+	store, err := habit.OpenStore(filepath.Join(t.TempDir(), "habit.store"), habit.WithBackup())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker, err := habit.NewTracker(habit.WithStore(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.RestoreBackup("20240206T130000Z")
+	if err == nil {
+		t.Error("want an error restoring a backup that does not exist")
+	}
+	tracker, err = habit.NewTracker(habit.WithStore(&memStore{habits: map[string]habit.Habit{}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = tracker.Backups()
+	if err == nil {
+		t.Error("want an error listing the backups of a store without backups")
+	}
+}
@@ -0,0 +1,109 @@
+package habit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// A BatchCompletion is a completion of a Habit submitted to TrackBatch, such
+// as one queued by a phone while it was offline.
+type BatchCompletion struct {
+	// Habit is the name of the habit that was done.
+	Habit string `json:"habit"`
+	// At is the timestamp when the habit was done. It is the time the batch
+	// is tracked if zero.
+	At time.Time `json:"at,omitempty"`
+	// Note is a freeform note to attach to the completion.
+	Note string `json:"note,omitempty"`
+	// ID is the completion's idempotency key, if any, as given to TrackOnce,
+	// so that a batch sent again after a lost response tracks nothing twice.
+	ID string `json:"id,omitempty"`
+}
+
+// A BatchResult is the outcome of tracking a BatchCompletion.
+type BatchResult struct {
+	// Habit is the name of the habit of the completion.
+	Habit string `json:"habit"`
+	// At is the timestamp of the completion.
+	At time.Time `json:"at"`
+	// Message is the message written when the completion was tracked, or
+	// had already been tracked with its idempotency key. It is empty if the
+	// completion could not be tracked.
+	Message string `json:"message,omitempty"`
+	// Error is why the completion could not be tracked. It is empty if the
+	// completion was tracked.
+	Error string `json:"error,omitempty"`
+}
+
+// TrackBatch records each of the given completions as TrackOnce does, in
+// chronological order so that streaks build up as if they had been tracked
+// one at a time, and saves the store once. A completion that cannot be
+// tracked, such as one in the future or of an archived Habit, is skipped
+// without holding up the others, and the reason is given in its BatchResult.
+// The results are returned in the order of the completions, and the message of
+// each tracked completion is written to the Tracker's output. An error is
+// returned, and nothing is reported as tracked, if no completions are given or
+// the store cannot be saved.
+func (t *Tracker) TrackBatch(completions []BatchCompletion) ([]BatchResult, error) {
+	return t.TrackBatchContext(context.Background(), completions)
+}
+
+// TrackBatchContext tracks the given completions like TrackBatch, saving the
+// store under the given context.
+func (t *Tracker) TrackBatchContext(ctx context.Context, completions []BatchCompletion) ([]BatchResult, error) {
+	if len(completions) < 1 {
+		return nil, errors.New("no completions to track")
+	}
+	now := t.now()
+	order := make([]int, len(completions))
+	results := make([]BatchResult, len(completions))
+	for i, c := range completions {
+		order[i] = i
+		at := c.At
+		if at.IsZero() {
+			at = now
+		}
+		results[i] = BatchResult{Habit: c.Habit, At: at}
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return results[order[i]].At.Before(results[order[j]].At)
+	})
+	tracked := make([]trackResult, len(completions))
+	for _, i := range order {
+		res := &results[i]
+		if res.Habit == "" {
+			res.Error = "missing habit name"
+			continue
+		}
+		id := completions[i].ID
+		if hbt, ok := t.store.Get(res.Habit); ok && id != "" && hbt.hasCompletion(id) {
+			res.Message = alreadyTracked(res.Habit, id)
+			continue
+		}
+		err := t.checkTrackable(res.Habit, res.At)
+		if err != nil {
+			res.Error = err.Error()
+			continue
+		}
+		tracked[i] = t.record(res.Habit, Completion{At: res.At, Note: completions[i].Note, ID: id})
+		res.Message = tracked[i].message
+	}
+	err := t.saveContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, i := range order {
+		if tracked[i].hbt.Name == "" {
+			continue
+		}
+		t.logTracked(ctx, tracked[i].hbt, results[i].At)
+		fmt.Fprintln(t.output, results[i].Message)
+		for _, e := range tracked[i].events {
+			t.emit(e)
+		}
+	}
+	return results, nil
+}
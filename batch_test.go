@@ -0,0 +1,85 @@
+package habit_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTrackBatch_TracksCompletionsInChronologicalOrderWithSingleSave(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T20:00:00Z")
+	store := &memStore{habits: map[string]habit.Habit{
+		"reading": {Name: "reading", Archived: true},
+	}}
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(new(bytes.Buffer)), habit.WithLocation(time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	day := func(d, hour int) time.Time {
+		return time.Date(2024, time.February, d, hour, 0, 0, 0, time.UTC)
+	}
+	results, err := tracker.TrackBatch([]habit.BatchCompletion{
+		{Habit: "programming", At: day(6, 9), Note: "fixed a bug"},
+		{Habit: "programming", At: day(4, 20)},
+		{Habit: "reading", At: day(5, 8)},
+		{Habit: "programming", At: day(5, 12)},
+		{Habit: "programming", At: day(7, 9)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var errs []bool
+	for _, res := range results {
+		errs = append(errs, res.Error != "")
+	}
+	wantErrs := []bool{false, false, true, false, true}
+	if !cmp.Equal(wantErrs, errs) {
+		t.Error(cmp.Diff(wantErrs, errs))
+	}
+	if store.saves != 1 {
+		t.Errorf("want 1 save, got %d", store.saves)
+	}
+	want := habit.Habit{
+		Name:          "programming",
+		CurrentStreak: 3,
+		LongestStreak: 3,
+		LastDone:      day(6, 9),
+		History: []habit.Completion{
+			{At: day(4, 20)},
+			{At: day(5, 12)},
+			{At: day(6, 9), Note: "fixed a bug"},
+		},
+		Undo: &habit.UndoRecord{
+			Completion:    day(6, 9),
+			CurrentStreak: 2,
+			LongestStreak: 2,
+			LastDone:      day(5, 12),
+		},
+	}
+	got, ok := store.Get("programming")
+	if !ok {
+		t.Fatal("want habit 'programming' to be stored")
+	}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestTrackBatch_ReturnsErrorForNoCompletions(t *testing.T) {
+	t.Parallel()
+	store := &memStore{habits: map[string]habit.Habit{}}
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(new(bytes.Buffer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = tracker.TrackBatch(nil)
+	if err == nil {
+		t.Error("want error for no completions")
+	}
+	if store.saves != 0 {
+		t.Errorf("want no saves, got %d", store.saves)
+	}
+}
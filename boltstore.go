@@ -0,0 +1,467 @@
+package habit
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// A BoltStore provides a store for Habits that is persisted to a bbolt
+// database file, so that saving a change only rewrites the Habits that
+// changed rather than the whole file. Each Habit is kept in a bucket of its
+// own, holding its fields and a nested bucket with one key per completion in
+// its history. Changes made with Add and Delete are buffered in memory until
+// Save commits them in a single transaction.
+//
+// The database file is locked while a BoltStore is open, so other processes
+// wait for it to be closed before they can open it. With WithLockPerChange, the
+// database file is only kept open, and locked, while a change is made, and
+// opened for each read in between.
+type BoltStore struct {
+	db            *bolt.DB
+	path          string
+	lockTimeout   time.Duration
+	lockPerChange bool
+	pending       map[string]*Habit
+	err           error
+	mtx           sync.Mutex
+}
+
+// Names of the buckets and keys of a BoltStore's database.
+var (
+	boltHabitsBucket  = []byte("habits")
+	boltMetaBucket    = []byte("meta")
+	boltHistoryBucket = []byte("history")
+	boltHabitKey      = []byte("habit")
+	boltVersionKey    = []byte("schema_version")
+)
+
+// boltLockTimeout is how long OpenBoltStore waits for another process to close
+// the database file, unless WithLock or WithLockPerChange sets another timeout.
+const boltLockTimeout = 5 * time.Second
+
+// OpenBoltStore opens the bbolt database file at the given path, creating it
+// if it does not exist, and returns a BoltStore backed by it, configured with
+// the given options and upgrading the habits it holds to SchemaVersion if
+// necessary. The file is locked for as long as the store is open, as with
+// WithLock, unless WithLockPerChange is given. An error wrapping
+// ErrStoreLocked is returned if another process keeps the file open, and other
+// errors if an option other than WithLock and WithLockPerChange is given, or
+// if the file cannot be opened or its habits cannot be upgraded.
+func OpenBoltStore(path string, opts ...storeOption) (*BoltStore, error) {
+	cfg, err := databaseOptions("bolt", path, opts)
+	if err != nil {
+		return nil, err
+	}
+	s := &BoltStore{
+		path:          path,
+		lockTimeout:   boltLockTimeout,
+		lockPerChange: cfg.lockPerChange,
+		pending:       map[string]*Habit{},
+	}
+	if cfg.locking || cfg.lockPerChange {
+		s.lockTimeout = cfg.lockTimeout
+	}
+	db, err := s.openDB()
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(migrateBolt)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error migrating bolt store %q: %w", path, err)
+	}
+	if s.lockPerChange {
+		return s, db.Close()
+	}
+	s.db = db
+	return s, nil
+}
+
+// openDB opens and locks the store's database file, waiting up to the store's
+// lock timeout for another process to close it.
+func (s *BoltStore) openDB() (*bolt.DB, error) {
+	db, err := bolt.Open(s.path, 0o644, &bolt.Options{Timeout: s.lockTimeout})
+	if errors.Is(err, bolt.ErrTimeout) {
+		return nil, errorOf(ErrStoreLocked, "store %q is locked by another habit process", s.path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error opening bolt store %q: %w", s.path, err)
+	}
+	return db, nil
+}
+
+// view calls fn in a read-only transaction of the store's database, opening
+// the database for the call if the store only keeps it open while a change is
+// made. The caller must hold s.mtx.
+func (s *BoltStore) view(fn func(tx *bolt.Tx) error) error {
+	db, release, err := s.database()
+	if err != nil {
+		return err
+	}
+	defer release()
+	return db.View(fn)
+}
+
+// update calls fn in a read-write transaction of the store's database, as
+// view does.
+func (s *BoltStore) update(fn func(tx *bolt.Tx) error) error {
+	db, release, err := s.database()
+	if err != nil {
+		return err
+	}
+	defer release()
+	return db.Update(fn)
+}
+
+// database returns the store's open database, or opens it if the store only
+// keeps it open while a change is made, and a function that closes it again
+// in that case. The caller must hold s.mtx.
+func (s *BoltStore) database() (*bolt.DB, func(), error) {
+	if s.db != nil {
+		return s.db, func() {}, nil
+	}
+	db, err := s.openDB()
+	if err != nil {
+		return nil, nil, err
+	}
+	return db, func() { db.Close() }, nil
+}
+
+// migrateBolt creates the buckets of a BoltStore's database if they do not
+// exist and upgrades its habits to SchemaVersion, which is recorded in the meta
+// bucket.
+func migrateBolt(tx *bolt.Tx) error {
+	habits, err := tx.CreateBucketIfNotExists(boltHabitsBucket)
+	if err != nil {
+		return err
+	}
+	meta, err := tx.CreateBucketIfNotExists(boltMetaBucket)
+	if err != nil {
+		return err
+	}
+	version := 0
+	if value := meta.Get(boltVersionKey); value != nil {
+		version, err = strconv.Atoi(string(value))
+		if err != nil {
+			return errorOf(ErrStoreCorrupt, "invalid schema version %q", value)
+		}
+	} else if first, _ := habits.Cursor().First(); first == nil {
+		// A new database holds no habits to upgrade.
+		version = SchemaVersion
+	}
+	if version != SchemaVersion {
+		data := map[string]Habit{}
+		err = habits.ForEachBucket(func(name []byte) error {
+			h, err := readBoltHabit(habits.Bucket(name))
+			if err != nil {
+				return errorOf(ErrStoreCorrupt, "error decoding habit '%s': %w", name, err)
+			}
+			data[string(name)] = h
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		err = migrate(version, data)
+		if err != nil {
+			return err
+		}
+		for _, h := range data {
+			err = writeBoltHabit(habits, h)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return meta.Put(boltVersionKey, []byte(strconv.Itoa(SchemaVersion)))
+}
+
+// readBoltHabit returns the Habit held in the given habit bucket.
+func readBoltHabit(b *bolt.Bucket) (Habit, error) {
+	var h Habit
+	err := json.Unmarshal(b.Get(boltHabitKey), &h)
+	if err != nil {
+		return Habit{}, err
+	}
+	history := b.Bucket(boltHistoryBucket)
+	if history == nil {
+		return h, nil
+	}
+	err = history.ForEach(func(_, value []byte) error {
+		var c Completion
+		err := json.Unmarshal(value, &c)
+		if err != nil {
+			return err
+		}
+		h.History = append(h.History, c)
+		return nil
+	})
+	return h, err
+}
+
+// writeBoltHabit writes the given Habit to its bucket in the given habits
+// bucket. Only the completions that changed are written to the Habit's history
+// bucket, and those no longer in its history are deleted.
+func writeBoltHabit(habits *bolt.Bucket, h Habit) error {
+	b, err := habits.CreateBucketIfNotExists([]byte(h.Name))
+	if err != nil {
+		return fmt.Errorf("error saving habit '%s': %w", h.Name, err)
+	}
+	history := h.History
+	h.History = nil
+	value, err := json.Marshal(h)
+	if err != nil {
+		return fmt.Errorf("error encoding habit '%s': %w", h.Name, err)
+	}
+	err = b.Put(boltHabitKey, value)
+	if err != nil {
+		return fmt.Errorf("error saving habit '%s': %w", h.Name, err)
+	}
+	hb, err := b.CreateBucketIfNotExists(boltHistoryBucket)
+	if err != nil {
+		return fmt.Errorf("error saving habit '%s': %w", h.Name, err)
+	}
+	completions := make(map[string][]byte, len(history))
+	seen := map[int64]int{}
+	for _, c := range history {
+		value, err := json.Marshal(c)
+		if err != nil {
+			return fmt.Errorf("error encoding habit '%s': %w", h.Name, err)
+		}
+		completions[string(completionKey(c.At, seen[c.At.UnixNano()]))] = value
+		seen[c.At.UnixNano()]++
+	}
+	var stale [][]byte
+	err = hb.ForEach(func(key, _ []byte) error {
+		if _, ok := completions[string(key)]; !ok {
+			stale = append(stale, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, key := range stale {
+		err = hb.Delete(key)
+		if err != nil {
+			return fmt.Errorf("error saving habit '%s': %w", h.Name, err)
+		}
+	}
+	for key, value := range completions {
+		if bytes.Equal(hb.Get([]byte(key)), value) {
+			continue
+		}
+		err = hb.Put([]byte(key), value)
+		if err != nil {
+			return fmt.Errorf("error saving habit '%s': %w", h.Name, err)
+		}
+	}
+	return nil
+}
+
+// completionKey returns the key of a completion at the given timestamp, which
+// is preceded by the given number of completions at the same timestamp in a
+// Habit's history. Keys sort in the order of the history, which is
+// chronological, and stay the same when earlier completions are inserted.
+func completionKey(at time.Time, index int) []byte {
+	key := make([]byte, 12)
+	// Flipping the sign bit sorts timestamps before 1970 first.
+	binary.BigEndian.PutUint64(key, uint64(at.UnixNano())^(1<<63))
+	binary.BigEndian.PutUint32(key[8:], uint32(index))
+	return key
+}
+
+// Get returns the habit with the given name and a bool indicating if the habit
+// exists in the store.
+func (s *BoltStore) Get(name string) (Habit, bool) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if h, ok := s.pending[name]; ok {
+		if h == nil {
+			return Habit{}, false
+		}
+		return *h, true
+	}
+	var h Habit
+	found := false
+	err := s.view(func(tx *bolt.Tx) error {
+		b := tx.Bucket(boltHabitsBucket).Bucket([]byte(name))
+		if b == nil {
+			return nil
+		}
+		var err error
+		h, err = readBoltHabit(b)
+		if err != nil {
+			return errorOf(ErrStoreCorrupt, "error decoding habit '%s': %w", name, err)
+		}
+		found = true
+		return nil
+	})
+	if err != nil {
+		s.setErr(err)
+		return Habit{}, false
+	}
+	return h, found
+}
+
+// Add adds or updates the given habit in the store.
+func (s *BoltStore) Add(h Habit) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.pending[h.Name] = &h
+}
+
+// Delete deletes the habit with the given name from the store. If the
+// habit does not exist in the store, then the delete is a no-op.
+func (s *BoltStore) Delete(name string) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.pending[name] = nil
+}
+
+// All returns a list of all habits contained in the store.
+func (s *BoltStore) All() []Habit {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	var habits []Habit
+	err := s.view(func(tx *bolt.Tx) error {
+		b := tx.Bucket(boltHabitsBucket)
+		return b.ForEachBucket(func(name []byte) error {
+			if _, ok := s.pending[string(name)]; ok {
+				return nil
+			}
+			h, err := readBoltHabit(b.Bucket(name))
+			if err != nil {
+				return errorOf(ErrStoreCorrupt, "error decoding habit '%s': %w", name, err)
+			}
+			habits = append(habits, h)
+			return nil
+		})
+	})
+	if err != nil {
+		s.setErr(err)
+	}
+	for _, h := range s.pending {
+		if h != nil {
+			habits = append(habits, *h)
+		}
+	}
+	return habits
+}
+
+// Save commits the changes made since the last Save to the database in a
+// single transaction. An error is returned if a previous read failed or if
+// the changes cannot be committed.
+func (s *BoltStore) Save() error {
+	return s.SaveContext(context.Background())
+}
+
+// SaveContext commits the changes like Save, unless the given context is done
+// before the transaction starts, in which case the changes are kept for the
+// next save.
+func (s *BoltStore) SaveContext(ctx context.Context) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.err != nil {
+		err := s.err
+		s.err = nil
+		return err
+	}
+	err := ctx.Err()
+	if err != nil {
+		return err
+	}
+	err = s.update(func(tx *bolt.Tx) error {
+		habits := tx.Bucket(boltHabitsBucket)
+		for name, h := range s.pending {
+			if h == nil {
+				err := habits.DeleteBucket([]byte(name))
+				if err != nil && !errors.Is(err, bolt.ErrBucketNotFound) {
+					return fmt.Errorf("error deleting habit '%s': %w", name, err)
+				}
+				continue
+			}
+			err := writeBoltHabit(habits, *h)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	s.pending = map[string]*Habit{}
+	return nil
+}
+
+// LoadContext discards the changes made since the last save, so that the
+// store reads the habits committed to the database. Reads from the database
+// file cannot be cancelled, so the context is only checked before they start.
+func (s *BoltStore) LoadContext(ctx context.Context) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.pending = map[string]*Habit{}
+	s.err = nil
+	return ctx.Err()
+}
+
+// lockChange opens and locks the store's database and discards the changes
+// made since the last save, if the store was opened with WithLockPerChange,
+// and returns a function that closes the database again. For other stores,
+// whose database is locked while they are open, it does nothing. An error is
+// returned if the database cannot be opened.
+func (s *BoltStore) lockChange() (func(), error) {
+	if !s.lockPerChange {
+		return func() {}, nil
+	}
+	// Opening waits for other changes to close the database, so it must not
+	// hold s.mtx, which they need to finish.
+	db, err := s.openDB()
+	if err != nil {
+		return nil, err
+	}
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.db = db
+	s.pending = map[string]*Habit{}
+	s.err = nil
+	return func() {
+		s.mtx.Lock()
+		defer s.mtx.Unlock()
+		db.Close()
+		if s.db == db {
+			s.db = nil
+		}
+	}, nil
+}
+
+// Close closes the underlying database, releasing its lock. Changes that have
+// not been saved are discarded.
+func (s *BoltStore) Close() error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.db == nil {
+		return nil
+	}
+	err := s.db.Close()
+	s.db = nil
+	return err
+}
+
+// setErr records the first error encountered by a read so that it can be
+// returned by the next call to Save. The caller must hold s.mtx.
+func (s *BoltStore) setErr(err error) {
+	if s.err == nil {
+		s.err = err
+	}
+}
@@ -0,0 +1,215 @@
+package habit_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBoltStore_SaveSavesStorePersistently(t *testing.T) {
+	t.Parallel()
+	path := t.TempDir() + "/habits.bolt"
+	store, err := habit.OpenBoltStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := time.Date(2024, time.February, 5, 20, 0, 0, 0, time.UTC)
+	store.Add(habit.Habit{Name: "habit1", CurrentStreak: 2, LongestStreak: 2, LastDone: done,
+		History: []habit.Completion{{At: done.Add(-24 * time.Hour)}, {At: done, Note: "read a chapter"}, {At: done}}})
+	store.Add(habit.Habit{Name: "habit2", Tags: []string{"health"}})
+	store.Add(habit.Habit{Name: "habit3"})
+	store.Delete("habit3")
+	err = store.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = store.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	store2, err := habit.OpenBoltStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store2.Close()
+	want := []habit.Habit{
+		{Name: "habit1", CurrentStreak: 2, LongestStreak: 2, LastDone: done,
+			History: []habit.Completion{{At: done.Add(-24 * time.Hour)}, {At: done, Note: "read a chapter"}, {At: done}}},
+		{Name: "habit2", Tags: []string{"health"}},
+	}
+	got := store2.All()
+	if !cmp.Equal(want, got, habitSliceCmpOpt) {
+		t.Error(cmp.Diff(want, got, habitSliceCmpOpt))
+	}
+}
+
+func TestBoltStore_SaveRewritesChangedHistory(t *testing.T) {
+	t.Parallel()
+	store, err := habit.OpenBoltStore(t.TempDir() + "/habits.bolt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	done := time.Date(2024, time.February, 5, 20, 0, 0, 0, time.UTC)
+	store.Add(habit.Habit{Name: "habit1", History: []habit.Completion{{At: done}, {At: done.Add(time.Hour)}}})
+	err = store.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := habit.Habit{Name: "habit1", History: []habit.Completion{
+		{At: done.Add(-24 * time.Hour), Note: "backdated"},
+		{At: done, Note: "edited"},
+	}}
+	store.Add(want)
+	err = store.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, ok := store.Get("habit1")
+	if !ok {
+		t.Fatal("expected ok to be true when getting saved habit")
+	}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestBoltStore_GetReturnsUnsavedChanges(t *testing.T) {
+	t.Parallel()
+	store, err := habit.OpenBoltStore(t.TempDir() + "/habits.bolt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	store.Add(habit.Habit{Name: "habit1"})
+	err = store.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{Name: "habit2", CurrentStreak: 1})
+	got, ok := store.Get("habit2")
+	if !ok {
+		t.Fatal("expected ok to be true when getting unsaved habit")
+	}
+	want := habit.Habit{Name: "habit2", CurrentStreak: 1}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+	store.Delete("habit1")
+	_, ok = store.Get("habit1")
+	if ok {
+		t.Error("wanted ok to be false when getting habit deleted but not yet saved")
+	}
+	_, ok = store.Get("nonexistent-key")
+	if ok {
+		t.Error("wanted ok to be false when getting non-existent key")
+	}
+}
+
+func TestBoltStore_SaveContextKeepsChangesGivenCancelledContext(t *testing.T) {
+	t.Parallel()
+	store, err := habit.OpenBoltStore(t.TempDir() + "/habits.bolt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	store.Add(habit.Habit{Name: "habit1"})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = store.SaveContext(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("want error %v, got %v", context.Canceled, err)
+	}
+	err = store.SaveContext(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = store.LoadContext(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, ok := store.Get("habit1")
+	if !ok {
+		t.Error("want habit kept after a cancelled save to be saved by the next save")
+	}
+}
+
+func TestOpenOpensBoltStoreGivenBoltExtension(t *testing.T) {
+	t.Parallel()
+	store, err := habit.Open(t.TempDir() + "/habits.bbolt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs, ok := store.(*habit.BoltStore)
+	if !ok {
+		t.Fatalf("want *habit.BoltStore, got %T", store)
+	}
+	defer bs.Close()
+}
+
+func TestOpenBoltStoreReturnsErrorNamingUnsupportedOptions(t *testing.T) {
+	t.Parallel()
+	_, err := habit.Open(t.TempDir()+"/habits.bolt", habit.WithBackupDir(t.TempDir(), 0, 0))
+	if err == nil || !strings.Contains(err.Error(), "WithBackupDir") {
+		t.Fatalf("want error naming WithBackupDir, got %v", err)
+	}
+}
+
+func TestBoltStoreWithLockPerChangeLetsOtherStoresSaveBetweenChanges(t *testing.T) {
+	t.Parallel()
+	path := t.TempDir() + "/habits.bolt"
+	store, err := habit.OpenBoltStore(path, habit.WithLockPerChange(100*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(io.Discard))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(habit.NewServer(tracker))
+	defer srv.Close()
+	track := func(name string) int {
+		t.Helper()
+		resp, err := http.Post(srv.URL+"/habits/"+name+"/track", "application/json", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	other, err := habit.OpenBoltStore(path, habit.WithLock(100*time.Millisecond))
+	if err != nil {
+		t.Fatalf("want store unlocked between changes, got %v", err)
+	}
+	if got := track("reading"); got != http.StatusServiceUnavailable {
+		t.Errorf("want status %d while another store holds the lock, got %d", http.StatusServiceUnavailable, got)
+	}
+	other.Add(habit.Habit{Name: "cycling"})
+	err = other.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other.Close()
+	if got := track("reading"); got != http.StatusOK {
+		t.Fatalf("want status %d, got %d", http.StatusOK, got)
+	}
+	var got []string
+	for _, hbt := range store.All() {
+		got = append(got, hbt.Name)
+	}
+	sort.Strings(got)
+	want := []string{"cycling", "reading"}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
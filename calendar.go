@@ -0,0 +1,41 @@
+package habit
+
+import "time"
+
+// A calendar determines the calendar date on which a timestamp falls, and so
+// the boundaries of each Habit's periods.
+type calendar struct {
+	// location is the time zone in which dates are determined.
+	location *time.Location
+	// dayStart is the hour, from 0 to 23, at which each date starts. Timestamps
+	// between midnight and this hour count toward the previous date.
+	dayStart int
+}
+
+// date returns midnight UTC on the calendar date of the given timestamp, so
+// that dates in any location can be compared and subtracted without daylight
+// saving time changes getting in the way.
+func (c calendar) date(t time.Time) time.Time {
+	shifted := t.In(c.location).Add(-time.Duration(c.dayStart) * time.Hour)
+	year, month, day := shifted.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// day returns midnight in the calendar's location on the calendar date of the
+// given timestamp.
+func (c calendar) day(t time.Time) time.Time {
+	year, month, day := c.date(t).Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, c.location)
+}
+
+// daysBetween accepts 2 timestamps and returns the number of calendar days
+// from the date of the first to the date of the second.
+func (c calendar) daysBetween(t1, t2 time.Time) int {
+	return int(c.date(t2).Sub(c.date(t1)).Hours() / 24)
+}
+
+// start returns the timestamp at which the calendar date of the given
+// timestamp starts, which is later than midnight if days start later.
+func (c calendar) start(t time.Time) time.Time {
+	return c.day(t).Add(time.Duration(c.dayStart) * time.Hour)
+}
@@ -0,0 +1,383 @@
+package habit
+
+import (
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// DefaultChartDays is the number of days, up to and including today, covered
+// by a chart by default.
+const DefaultChartDays = 365
+
+// A ChartKind is a kind of chart of a Habit.
+type ChartKind int
+
+const (
+	// ChartLine is a line chart of the Habit's streak on each day.
+	ChartLine ChartKind = iota
+	// ChartCalendar is a GitHub-style calendar of the days the Habit was
+	// done.
+	ChartCalendar
+)
+
+// String returns the command-line name of the ChartKind.
+func (k ChartKind) String() string {
+	switch k {
+	case ChartLine:
+		return "line"
+	case ChartCalendar:
+		return "calendar"
+	}
+	return fmt.Sprintf("ChartKind(%d)", int(k))
+}
+
+// ParseChartKind accepts "line" or "calendar" and returns the corresponding
+// ChartKind. An error is returned if the value is not recognized.
+func ParseChartKind(value string) (ChartKind, error) {
+	for _, k := range []ChartKind{ChartLine, ChartCalendar} {
+		if value == k.String() {
+			return k, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid chart kind %q (want line or calendar)", value)
+}
+
+// A ChartFormat is an image format that charts can be written in.
+type ChartFormat int
+
+const (
+	// ChartSVG is the SVG vector image format, which can be embedded in web
+	// pages.
+	ChartSVG ChartFormat = iota
+	// ChartPNG is the PNG bitmap image format.
+	ChartPNG
+)
+
+// ChartFormatForPath returns the ChartFormat implied by the extension of the
+// given file path, ".svg" or ".png". An error is returned for any other
+// extension.
+func ChartFormatForPath(path string) (ChartFormat, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".svg":
+		return ChartSVG, nil
+	case ".png":
+		return ChartPNG, nil
+	}
+	return 0, fmt.Errorf("cannot tell the chart format of %q (want a .svg or .png file)", path)
+}
+
+// Chart colors.
+var (
+	chartBackground = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	chartInk        = color.RGBA{R: 0x24, G: 0x29, B: 0x2f, A: 0xff}
+	chartGrid       = color.RGBA{R: 0xd0, G: 0xd7, B: 0xde, A: 0xff}
+	chartStreak     = color.RGBA{R: 0x2f, G: 0x81, B: 0xf7, A: 0xff}
+	chartDone       = color.RGBA{R: 0x40, G: 0xc4, B: 0x63, A: 0xff}
+	chartMissed     = color.RGBA{R: 0xeb, G: 0xed, B: 0xf0, A: 0xff}
+)
+
+// WriteChart writes a chart of the Habit with the given name over the given
+// number of days, up to and including today, to w in the given format. A line
+// chart plots the Habit's streak at the end of each day, and a calendar chart
+// marks the days the Habit was done in a grid of weeks like PrintHeatmap. An
+// error is returned if the Habit does not exist, the number of days is not
+// positive or the chart cannot be written.
+func (t *Tracker) WriteChart(w io.Writer, hbtName string, kind ChartKind, format ChartFormat, days int) error {
+	hbt, ok := t.store.Get(hbtName)
+	if !ok {
+		return errHabitNotFound(hbtName)
+	}
+	if days < 1 {
+		return fmt.Errorf("invalid number of days %d (want at least 1)", days)
+	}
+	var c chart
+	switch kind {
+	case ChartLine:
+		c = t.lineChart(hbt, days)
+	case ChartCalendar:
+		c = t.calendarChart(hbt, days)
+	default:
+		return fmt.Errorf("unknown chart kind %v", kind)
+	}
+	switch format {
+	case ChartSVG:
+		return c.writeSVG(w)
+	case ChartPNG:
+		return c.writePNG(w)
+	}
+	return fmt.Errorf("unknown chart format %d", int(format))
+}
+
+// dailyStreaks returns the given Habit's streak at the end of each of the
+// given number of days up to and including the one containing the given
+// timestamp, oldest first, or as of the timestamp for its own day. For a Habit
+// to avoid, the streak is the number of days since it was started or last
+// done, as in Habit.streaks.
+func (t *Tracker) dailyStreaks(hbt Habit, days int, now time.Time) []int {
+	hbt.History = slices.Clone(hbt.History)
+	sort.SliceStable(hbt.History, func(i, j int) bool {
+		return hbt.History[i].At.Before(hbt.History[j].At)
+	})
+	var streaks []int
+	if !hbt.Avoid {
+		streaks = completionStreaks(hbt, t.calendar)
+	} else if len(hbt.History) == 0 && !hbt.LastDone.IsZero() {
+		// A Habit to avoid that was never done is counted from when it
+		// was started.
+		hbt.History = []Completion{{At: hbt.LastDone}}
+	}
+	today := t.calendar.start(now)
+	values := make([]int, days)
+	last := -1
+	for i := range values {
+		end := today.AddDate(0, 0, i-days+1)
+		if i == days-1 {
+			end = now
+		} else {
+			end = end.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		}
+		for last+1 < len(hbt.History) && !hbt.History[last+1].At.After(end) {
+			last++
+		}
+		switch {
+		case last < 0:
+		case hbt.Avoid:
+			values[i] = t.calendar.daysBetween(hbt.History[last].At, end)
+		case hbt.periodsBetween(hbt.History[last].At, end, t.calendar) < 1:
+			values[i] = streaks[last]
+		}
+	}
+	return values
+}
+
+// Line chart layout, in pixels.
+const (
+	lineChartWidth  = 720
+	lineChartHeight = 300
+	lineChartLeft   = 48
+	lineChartRight  = 24
+	lineChartTop    = 48
+	lineChartBottom = 36
+)
+
+// lineChart returns a line chart of the given Habit's streak at the end of each
+// of the given number of days up to and including today.
+func (t *Tracker) lineChart(hbt Habit, days int) chart {
+	now := t.now()
+	streaks := t.dailyStreaks(hbt, days, now)
+	top := max(slices.Max(streaks), 1)
+	c := chart{width: lineChartWidth, height: lineChartHeight}
+	plotWidth := lineChartWidth - lineChartLeft - lineChartRight
+	plotHeight := lineChartHeight - lineChartTop - lineChartBottom
+	bottom := lineChartTop + plotHeight
+	c.title(fmt.Sprintf("'%s': %s streak over the last %d %s", hbt.Name, hbt.Frequency.unit(1), days, Daily.unit(days)))
+	c.rects = append(c.rects,
+		chartRect{x: lineChartLeft, y: lineChartTop, w: plotWidth, h: 1, fill: chartGrid},
+		chartRect{x: lineChartLeft, y: bottom, w: plotWidth, h: 1, fill: chartInk})
+	c.texts = append(c.texts,
+		chartText{x: lineChartLeft - 6, y: lineChartTop + 4, anchor: "end", s: fmt.Sprint(top)},
+		chartText{x: lineChartLeft - 6, y: bottom + 4, anchor: "end", s: "0"})
+	first := t.calendar.date(now).AddDate(0, 0, 1-days)
+	c.texts = append(c.texts,
+		chartText{x: lineChartLeft, y: bottom + 20, s: first.Format("Jan 2, 2006")},
+		chartText{x: lineChartLeft + plotWidth, y: bottom + 20, anchor: "end", s: t.calendar.date(now).Format("Jan 2, 2006")})
+	line := chartLine{stroke: chartStreak}
+	for i, streak := range streaks {
+		x := lineChartLeft
+		if days > 1 {
+			x += i * plotWidth / (days - 1)
+		}
+		line.points = append(line.points, image.Point{X: x, Y: bottom - streak*plotHeight/top})
+	}
+	c.lines = append(c.lines, line)
+	return c
+}
+
+// Calendar chart layout, in pixels.
+const (
+	calendarCell   = 12
+	calendarGap    = 2
+	calendarLeft   = 40
+	calendarRight  = 16
+	calendarTop    = 56
+	calendarBottom = 40
+)
+
+// calendarChart returns a calendar chart of the days the given Habit was done
+// in each week, from Monday to Sunday, that contains any of the given number
+// of days up to and including today.
+func (t *Tracker) calendarChart(hbt Habit, days int) chart {
+	today := t.calendar.date(t.now())
+	first := today.AddDate(0, 0, 1-days)
+	start := first.AddDate(0, 0, -weekdayIndex(first))
+	weeks := int(today.Sub(start).Hours()/24)/7 + 1
+	done := map[time.Time]bool{}
+	for _, c := range hbt.History {
+		done[t.calendar.date(c.At)] = true
+	}
+	step := calendarCell + calendarGap
+	c := chart{
+		width:  calendarLeft + weeks*step + calendarRight,
+		height: calendarTop + 7*step + calendarBottom,
+	}
+	count := 0
+	for week := 0; week < weeks; week++ {
+		for row := 0; row < 7; row++ {
+			day := start.AddDate(0, 0, week*7+row)
+			if day.Before(first) || day.After(today) {
+				continue
+			}
+			fill := chartMissed
+			if done[day] {
+				fill = chartDone
+				count++
+			}
+			c.rects = append(c.rects, chartRect{
+				x: calendarLeft + week*step, y: calendarTop + row*step,
+				w: calendarCell, h: calendarCell, fill: fill,
+			})
+			if day.Day() == 1 {
+				c.texts = append(c.texts, chartText{x: calendarLeft + week*step, y: calendarTop - 8, s: day.Format("Jan")})
+			}
+		}
+	}
+	for row, name := range []string{"Mon", "Wed", "Fri"} {
+		c.texts = append(c.texts, chartText{x: calendarLeft - 6, y: calendarTop + row*2*step + calendarCell - 2, anchor: "end", s: name})
+	}
+	c.title(fmt.Sprintf("'%s': done on %d of the last %d %s", hbt.Name, count, days, Daily.unit(days)))
+	legend := calendarTop + 7*step + 12
+	c.rects = append(c.rects,
+		chartRect{x: calendarLeft, y: legend, w: calendarCell, h: calendarCell, fill: chartDone},
+		chartRect{x: calendarLeft + 60, y: legend, w: calendarCell, h: calendarCell, fill: chartMissed})
+	c.texts = append(c.texts,
+		chartText{x: calendarLeft + step + 2, y: legend + calendarCell - 2, s: "done"},
+		chartText{x: calendarLeft + 60 + step + 2, y: legend + calendarCell - 2, s: "missed"})
+	return c
+}
+
+// A chart is a drawing made of rectangles, lines and text on a white
+// background, which can be written as an SVG or PNG image.
+type chart struct {
+	width, height int
+	rects         []chartRect
+	lines         []chartLine
+	texts         []chartText
+}
+
+// A chartRect is a filled rectangle with its top left corner at x and y.
+type chartRect struct {
+	x, y, w, h int
+	fill       color.RGBA
+}
+
+// A chartLine is a 2-pixel wide line through its points.
+type chartLine struct {
+	points []image.Point
+	stroke color.RGBA
+}
+
+// A chartText is a line of text with its baseline at y, starting at x unless
+// its anchor is "end", in which case it ends at x.
+type chartText struct {
+	x, y   int
+	anchor string
+	s      string
+}
+
+// title adds the given title to the top left of the chart.
+func (c *chart) title(s string) {
+	c.texts = append(c.texts, chartText{x: 16, y: 24, s: s})
+}
+
+// hexColor returns the given color in the #rrggbb notation.
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// writeSVG writes the chart to w as an SVG image.
+func (c chart) writeSVG(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\">\n",
+		c.width, c.height, c.width, c.height)
+	fmt.Fprintf(&b, "<rect width=\"100%%\" height=\"100%%\" fill=\"%s\"/>\n", hexColor(chartBackground))
+	for _, r := range c.rects {
+		fmt.Fprintf(&b, "<rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" fill=\"%s\"/>\n",
+			r.x, r.y, r.w, r.h, hexColor(r.fill))
+	}
+	for _, l := range c.lines {
+		points := make([]string, len(l.points))
+		for i, p := range l.points {
+			points[i] = fmt.Sprintf("%d,%d", p.X, p.Y)
+		}
+		fmt.Fprintf(&b, "<polyline points=\"%s\" fill=\"none\" stroke=\"%s\" stroke-width=\"2\" stroke-linejoin=\"round\"/>\n",
+			strings.Join(points, " "), hexColor(l.stroke))
+	}
+	for _, t := range c.texts {
+		anchor := ""
+		if t.anchor == "end" {
+			anchor = ` text-anchor="end"`
+		}
+		fmt.Fprintf(&b, "<text x=\"%d\" y=\"%d\"%s font-family=\"sans-serif\" font-size=\"12\" fill=\"%s\">%s</text>\n",
+			t.x, t.y, anchor, hexColor(chartInk), html.EscapeString(t.s))
+	}
+	b.WriteString("</svg>\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writePNG writes the chart to w as a PNG image, with its text in a built-in
+// bitmap font.
+func (c chart) writePNG(w io.Writer) error {
+	img := image.NewRGBA(image.Rect(0, 0, c.width, c.height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(chartBackground), image.Point{}, draw.Src)
+	for _, r := range c.rects {
+		draw.Draw(img, image.Rect(r.x, r.y, r.x+r.w, r.y+r.h), image.NewUniform(r.fill), image.Point{}, draw.Src)
+	}
+	for _, l := range c.lines {
+		for i := 1; i < len(l.points); i++ {
+			drawSegment(img, l.points[i-1], l.points[i], l.stroke)
+		}
+	}
+	d := font.Drawer{Dst: img, Src: image.NewUniform(chartInk), Face: basicfont.Face7x13}
+	for _, t := range c.texts {
+		x := fixed.I(t.x)
+		if t.anchor == "end" {
+			x -= d.MeasureString(t.s)
+		}
+		d.Dot = fixed.Point26_6{X: x, Y: fixed.I(t.y)}
+		d.DrawString(t.s)
+	}
+	return png.Encode(w, img)
+}
+
+// drawSegment draws a 2-pixel wide line from a to b on the given image.
+func drawSegment(img *image.RGBA, a, b image.Point, c color.RGBA) {
+	dx, dy := b.X-a.X, b.Y-a.Y
+	steps := max(abs(dx), abs(dy), 1)
+	for i := 0; i <= steps; i++ {
+		x, y := a.X+dx*i/steps, a.Y+dy*i/steps
+		draw.Draw(img, image.Rect(x-1, y-1, x+1, y+1), image.NewUniform(c), image.Point{}, draw.Src)
+	}
+}
+
+// abs returns the absolute value of n.
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,115 @@
+package habit_test
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+)
+
+func newChartTracker(t *testing.T) *habit.Tracker {
+	t.Helper()
+	habit.Now = getTimeFunc(t, "2024-01-10T09:00:00Z")
+	// The completions fall on consecutive days, so that they make a streak.
+	history := []habit.Completion{
+		{At: time.Date(2024, time.January, 6, 20, 0, 0, 0, time.UTC)},
+		{At: time.Date(2024, time.January, 7, 19, 0, 0, 0, time.UTC)},
+		{At: time.Date(2024, time.January, 8, 18, 0, 0, 0, time.UTC)},
+	}
+	store := &memStore{habits: map[string]habit.Habit{
+		"reading": {Name: "reading", CurrentStreak: 3, LongestStreak: 3, History: history, LastDone: history[2].At},
+	}}
+	tracker, err := habit.NewTracker(habit.WithStore(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tracker
+}
+
+func TestTracker_WriteChartDrawsLineOfDailyStreaksAsSVG(t *testing.T) {
+	tracker := newChartTracker(t)
+	var buf bytes.Buffer
+	err := tracker.WriteChart(&buf, "reading", habit.ChartLine, habit.ChartSVG, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The streak grows from January 6 to 8 and is broken once January 9 has
+	// passed without a completion, so the line rises to the top of the chart
+	// and drops to 0 on January 10.
+	svg := buf.String()
+	for _, want := range []string{
+		`<svg xmlns="http://www.w3.org/2000/svg" width="720" height="300"`,
+		`>&#39;reading&#39;: day streak over the last 5 days</text>`,
+		`<polyline points="48,192 210,120 372,48 534,48 696,264"`,
+		`>Jan 6, 2024</text>`,
+		`>Jan 10, 2024</text>`,
+	} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("want SVG to contain %q, got:\n%s", want, svg)
+		}
+	}
+}
+
+func TestTracker_WriteChartMarksDaysDoneInCalendar(t *testing.T) {
+	tracker := newChartTracker(t)
+	var buf bytes.Buffer
+	err := tracker.WriteChart(&buf, "reading", habit.ChartCalendar, habit.ChartSVG, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	svg := buf.String()
+	if want := ">&#39;reading&#39;: done on 3 of the last 5 days</text>"; !strings.Contains(svg, want) {
+		t.Errorf("want SVG to contain %q, got:\n%s", want, svg)
+	}
+	// Besides the 3 days done, the legend has a green square.
+	if got := strings.Count(svg, `fill="#40c463"`); got != 4 {
+		t.Errorf("want 4 green squares, got %d", got)
+	}
+	if got := strings.Count(svg, `fill="#ebedf0"`); got != 3 {
+		t.Errorf("want 3 grey squares, got %d", got)
+	}
+}
+
+func TestTracker_WriteChartDrawsPNG(t *testing.T) {
+	tracker := newChartTracker(t)
+	var buf bytes.Buffer
+	err := tracker.WriteChart(&buf, "reading", habit.ChartLine, habit.ChartPNG, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := img.Bounds().Size(); got.X != 720 || got.Y != 300 {
+		t.Errorf("want a 720x300 image, got %v", got)
+	}
+	want := color.RGBA{R: 0x2f, G: 0x81, B: 0xf7, A: 0xff}
+	if got := color.RGBAModel.Convert(img.At(372, 48)); got != want {
+		t.Errorf("want the peak of the line at (372, 48) colored %v, got %v", want, got)
+	}
+}
+
+func TestTracker_WriteChartReturnsErrorForInvalidArguments(t *testing.T) {
+	tracker := newChartTracker(t)
+	err := tracker.WriteChart(new(bytes.Buffer), "nonexistent", habit.ChartLine, habit.ChartSVG, 5)
+	if err == nil {
+		t.Error("want an error for a habit that does not exist")
+	}
+	err = tracker.WriteChart(new(bytes.Buffer), "reading", habit.ChartLine, habit.ChartSVG, 0)
+	if err == nil {
+		t.Error("want an error for 0 days")
+	}
+	_, err = habit.ParseChartKind("pie")
+	if err == nil {
+		t.Error("want an error for chart kind 'pie'")
+	}
+	_, err = habit.ChartFormatForPath("chart.jpg")
+	if err == nil {
+		t.Error("want an error for a .jpg file")
+	}
+}
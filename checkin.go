@@ -0,0 +1,72 @@
+package habit
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+)
+
+// CheckIn walks through each Habit returned by Due, asking on the Tracker's
+// output whether it was done and reading each answer, one per line, from the
+// Tracker's input. Answering "y" or "yes" tracks the Habit, "n" or "no" leaves
+// it undone, and "s" or "skip" skips it for now; any other answer repeats the
+// question. The Habits answered with yes are tracked together in a single save
+// once every Habit has been asked about, or once the input ends, after which
+// the remaining Habits count as skipped. A store that is only locked while it
+// changes is locked for that save alone, not while waiting for answers. An
+// error is returned if the Habits cannot be tracked.
+func (t *Tracker) CheckIn() error {
+	due := t.Due()
+	if len(due) < 1 {
+		fmt.Fprintln(t.output, "You've done all of your habits today. Nice work!")
+		return nil
+	}
+	sc := bufio.NewScanner(t.input)
+	var done []string
+	notDone, skipped := 0, 0
+	for i, hbt := range due {
+		answer, ok := t.ask(sc, fmt.Sprintf("Did you do '%s' %s? [y/n/skip] ", hbt.Name, hbt.Frequency.current()))
+		if !ok {
+			fmt.Fprintln(t.output)
+			skipped += len(due) - i
+			break
+		}
+		switch answer {
+		case "y":
+			done = append(done, hbt.Name)
+		case "n":
+			notDone++
+		case "s":
+			skipped++
+		}
+	}
+	fmt.Fprintf(t.output, "Checked in on %d %s: %d done, %d not done, %d skipped.\n",
+		len(due), habitsUnit(len(due)), len(done), notDone, skipped)
+	if len(done) < 1 {
+		return nil
+	}
+	return t.update(func() error {
+		return t.TrackAll(done...)
+	})
+}
+
+// ask writes the given question to the Tracker's output and reads answers
+// from the given scanner until one is valid, returning "y", "n" or "s" for
+// yes, no and skip. It returns false if the input ends first.
+func (t *Tracker) ask(sc *bufio.Scanner, question string) (string, bool) {
+	for {
+		fmt.Fprint(t.output, question)
+		if !sc.Scan() {
+			return "", false
+		}
+		switch strings.ToLower(strings.TrimSpace(sc.Text())) {
+		case "y", "yes":
+			return "y", true
+		case "n", "no":
+			return "n", true
+		case "s", "skip":
+			return "s", true
+		}
+		fmt.Fprintln(t.output, "Please answer y, n or skip.")
+	}
+}
@@ -0,0 +1,79 @@
+package habit_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTracker_CheckInTracksHabitsAnsweredYesInOneSave(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T09:00:00Z")
+	yesterday := time.Date(2024, time.February, 5, 20, 0, 0, 0, time.UTC)
+	store := &memStore{habits: map[string]habit.Habit{}}
+	for _, name := range []string{"journal", "reading", "running", "stretching"} {
+		store.habits[name] = habit.Habit{
+			Name: name, CurrentStreak: 1, LongestStreak: 1, LastDone: yesterday,
+			History: []habit.Completion{{At: yesterday}},
+		}
+	}
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(
+		habit.WithStore(store),
+		habit.WithOutput(output),
+		habit.WithInput(strings.NewReader("y\nmaybe\nskip\nn\nYes\n")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.CheckIn()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Did you do 'journal' today? [y/n/skip] " +
+		"Did you do 'reading' today? [y/n/skip] Please answer y, n or skip.\n" +
+		"Did you do 'reading' today? [y/n/skip] " +
+		"Did you do 'running' today? [y/n/skip] " +
+		"Did you do 'stretching' today? [y/n/skip] " +
+		"Checked in on 4 habits: 2 done, 1 not done, 1 skipped.\n" +
+		"Nice work! You tracked 2 habits: 'journal' (2-day streak) and 'stretching' (2-day streak).\n"
+	if got := output.String(); want != got {
+		t.Error(cmp.Diff(want, got))
+	}
+	if store.saves != 1 {
+		t.Errorf("want 1 save, got %d", store.saves)
+	}
+}
+
+func TestTracker_CheckInSkipsRemainingHabitsWhenInputEnds(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T09:00:00Z")
+	store := &memStore{habits: map[string]habit.Habit{
+		"journal": {Name: "journal"},
+		"reading": {Name: "reading"},
+	}}
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(
+		habit.WithStore(store),
+		habit.WithOutput(output),
+		habit.WithInput(strings.NewReader("n\n")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.CheckIn()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Did you do 'journal' today? [y/n/skip] " +
+		"Did you do 'reading' today? [y/n/skip] \n" +
+		"Checked in on 2 habits: 0 done, 1 not done, 1 skipped.\n"
+	if got := output.String(); want != got {
+		t.Error(cmp.Diff(want, got))
+	}
+	if store.saves != 0 {
+		t.Errorf("want no saves, got %d", store.saves)
+	}
+}
@@ -0,0 +1,128 @@
+package habit
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"io"
+)
+
+// checksumMagic precedes the SHA-256 checksum appended to a store file by
+// checksumCodec.
+const checksumMagic = "HABITSUM"
+
+// checksumSize is the size of the trailer appended by checksumCodec.
+const checksumSize = len(checksumMagic) + sha256.Size
+
+// errChecksumMismatch is returned when the data of a store file does not match
+// the checksum appended to it.
+var errChecksumMismatch = errors.New("store file checksum does not match its data")
+
+// errMissingChecksum is returned when a store file has no checksum but is not
+// a complete store file written before checksums were added, such as a file
+// cut short, which loses its checksum along with the end of its data.
+var errMissingChecksum = errors.New("store file has no checksum and is not a complete store file from before checksums")
+
+// checksumCodec appends a SHA-256 checksum of the data encoded by an inner
+// codec, so that a store file damaged on disk, such as by a failing drive or a
+// sync client, is detected when it is read rather than decoded as garbage.
+// Data without a checksum is only decoded if the inner codec finds it to be a
+// complete store file written before checksums were added.
+type checksumCodec struct {
+	inner codec
+}
+
+// Encode encodes the given habit data with the inner codec and writes it to w
+// followed by its checksum.
+func (c checksumCodec) Encode(w io.Writer, data map[string]Habit) error {
+	var buf bytes.Buffer
+	err := c.inner.Encode(&buf, data)
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(buf.Bytes())
+	buf.WriteString(checksumMagic)
+	buf.Write(sum[:])
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// Decode verifies the checksum of the habit data read from r and decodes it
+// with the inner codec into the given map.
+func (c checksumCodec) Decode(r io.Reader, data *map[string]Habit) error {
+	raw, err := c.verify(r)
+	if err != nil {
+		return err
+	}
+	return c.inner.Decode(bytes.NewReader(raw), data)
+}
+
+// decodeRaw verifies the checksum of the habit data read from r and decodes it
+// with the inner codec without migrating it.
+func (c checksumCodec) decodeRaw(r io.Reader) (versionedData, error) {
+	inner, ok := c.inner.(rawDecoder)
+	if !ok {
+		return versionedData{}, errors.New("cannot decode unmigrated habit data")
+	}
+	raw, err := c.verify(r)
+	if err != nil {
+		return versionedData{}, err
+	}
+	return inner.decodeRaw(bytes.NewReader(raw))
+}
+
+// verify reads habit data written by Encode from r and returns the data
+// encoded by the inner codec, without its checksum. Data without a checksum is
+// returned as is if it is a complete store file written before checksums were
+// added, and otherwise an errMissingChecksum error is returned. An
+// errChecksumMismatch error is returned if the data does not match its
+// checksum.
+func (c checksumCodec) verify(r io.Reader) ([]byte, error) {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data, sum, ok := splitChecksum(raw)
+	if !ok {
+		l, ok := c.inner.(legacyDecoder)
+		if !ok || !l.isLegacy(raw) {
+			return nil, errMissingChecksum
+		}
+		return raw, nil
+	}
+	want := sha256.Sum256(data)
+	if !bytes.Equal(want[:], sum) {
+		return nil, errChecksumMismatch
+	}
+	return data, nil
+}
+
+// A legacyDecoder is a codec that can tell whether data is a complete store
+// file written by it before checksums were added.
+type legacyDecoder interface {
+	// isLegacy reports whether the given data is a complete store file
+	// written before checksums were added.
+	isLegacy(raw []byte) bool
+}
+
+// splitChecksum splits the given data written by checksumCodec into the data
+// encoded by the inner codec and its checksum, and reports whether it has a
+// checksum.
+func splitChecksum(raw []byte) (data, sum []byte, ok bool) {
+	n := len(raw) - checksumSize
+	if n < 0 || string(raw[n:n+len(checksumMagic)]) != checksumMagic {
+		return raw, nil, false
+	}
+	return raw[:n], raw[n+len(checksumMagic):], true
+}
+
+// restoredBackup returns the path of the backup whose habits the given Store
+// loaded because its file was damaged, or an empty string if it loaded its
+// file or has none.
+func restoredBackup(s Store) string {
+	r, ok := s.(interface{ restoredBackup() string })
+	if !ok {
+		return ""
+	}
+	return r.restoredBackup()
+}
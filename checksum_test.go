@@ -0,0 +1,203 @@
+package habit_test
+
+import (
+	"errors"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+// damageFile flips a bit in the middle of the file at the given path.
+func damageFile(t *testing.T, path string) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[len(data)/2] ^= 0x01
+	err = os.WriteFile(path, data, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOpenStoreReturnsErrorForStoreNotMatchingChecksum(t *testing.T) {
+	t.Parallel()
+	path := t.TempDir() + "/temp.store"
+	store, err := habit.OpenStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{Name: "reading", CurrentStreak: 3, LongestStreak: 3, Tags: []string{"evening"}})
+	err = store.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	damageFile(t, path)
+	_, err = habit.OpenStore(path)
+	if !errors.Is(err, habit.ErrStoreCorrupt) {
+		t.Errorf("want ErrStoreCorrupt, got %v", err)
+	}
+}
+
+func TestOpenStoreFallsBackToBackupOfStoreNotMatchingChecksum(t *testing.T) {
+	t.Parallel()
+	path := t.TempDir() + "/temp.store"
+	store, err := habit.OpenStore(path, habit.WithBackup())
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{Name: "habit1"})
+	err = store.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{Name: "habit2"})
+	err = store.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	damageFile(t, path)
+	store, err = habit.OpenStore(path, habit.WithBackup())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []habit.Habit{{Name: "habit1"}}
+	if got := store.All(); !cmp.Equal(want, got, habitSliceCmpOpt) {
+		t.Error(cmp.Diff(want, got, habitSliceCmpOpt))
+	}
+	// Saving the restored habits must not replace the good backup with the
+	// damaged store file.
+	store.Add(habit.Habit{Name: "habit3"})
+	err = store.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	backup, err := habit.OpenStore(path + ".bak")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := backup.All(); !cmp.Equal(want, got, habitSliceCmpOpt) {
+		t.Error(cmp.Diff(want, got, habitSliceCmpOpt))
+	}
+}
+
+func TestTracker_CheckKeepsHabitsOfStoreOnlyFailingChecksum(t *testing.T) {
+	t.Parallel()
+	path := t.TempDir() + "/temp.store"
+	store, err := habit.OpenStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{Name: "reading"})
+	err = store.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Damage the checksum rather than the data.
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[len(data)-1] ^= 0x01
+	err = os.WriteFile(path, data, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err = habit.OpenStore(path, habit.WithSalvage())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(io.Discard))
+	if err != nil {
+		t.Fatal(err)
+	}
+	problems, err := tracker.Check(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(problems) != 1 {
+		t.Errorf("want 1 problem, got %v", problems)
+	}
+	store, err = habit.OpenStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := store.Get("reading"); !ok {
+		t.Error("want the habits of the store kept")
+	}
+}
+
+func TestOpenStoreReturnsErrorForStoreCutShortLosingItsChecksum(t *testing.T) {
+	t.Parallel()
+	path := t.TempDir() + "/temp.store"
+	store, err := habit.OpenStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{Name: "reading", CurrentStreak: 3, LongestStreak: 3})
+	err = store.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(path, data[:len(data)-10], 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = habit.OpenStore(path)
+	if !errors.Is(err, habit.ErrStoreCorrupt) {
+		t.Errorf("want ErrStoreCorrupt, got %v", err)
+	}
+}
+
+func TestOpenStoreFallsBackToBackupOfDamagedJSONAndEncryptedStores(t *testing.T) {
+	t.Parallel()
+	openers := map[string]func(path string) (*habit.FileStore, error){
+		"temp.json": func(path string) (*habit.FileStore, error) {
+			return habit.OpenStore(path, habit.WithBackup())
+		},
+		"temp.store": func(path string) (*habit.FileStore, error) {
+			return habit.OpenEncryptedStore(path, []byte("correct horse"), habit.WithBackup())
+		},
+	}
+	for name, open := range openers {
+		path := t.TempDir() + "/" + name
+		store, err := open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		store.Add(habit.Habit{Name: "habit1"})
+		err = store.Save()
+		if err != nil {
+			t.Fatal(err)
+		}
+		store.Add(habit.Habit{Name: "habit2"})
+		err = store.Save()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = os.WriteFile(path, data[:len(data)/2], 0o600)
+		if err != nil {
+			t.Fatal(err)
+		}
+		store, err = open(path)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		want := []habit.Habit{{Name: "habit1"}}
+		if got := store.All(); !cmp.Equal(want, got, habitSliceCmpOpt) {
+			t.Errorf("%s: %s", name, cmp.Diff(want, got, habitSliceCmpOpt))
+		}
+	}
+}
@@ -0,0 +1,2314 @@
+package habit
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+	"unicode"
+
+	"google.golang.org/grpc"
+)
+
+// A command is a subcommand of the habit CLI.
+type command struct {
+	// name is the name used to invoke the command.
+	name string
+	// aliases are alternative names that also invoke the command.
+	aliases []string
+	// args describes the command's flags and arguments in usage output.
+	args string
+	// summary is a one-line description of the command for usage output.
+	summary string
+	// unlocked is true for long-running commands, which open the store with
+	// WithLockPerChange instead of holding its lock while they run, so that
+	// other habit processes can still save changes.
+	unlocked bool
+	// readOnly is true for commands that only read the store, such as prompt,
+	// which runs for every shell prompt. They never take its lock, and treat
+	// a store file that does not exist as empty without creating any file.
+	readOnly bool
+	// external is true for commands with effects beyond changing the store,
+	// such as sending email or serving requests, which a dry run cannot hold
+	// back.
+	external bool
+	// salvage is true for commands that check and repair the store, which
+	// open it with WithSalvage so that they can run although parts of it
+	// cannot be decoded.
+	salvage bool
+	// run parses the command's arguments with the given flag set, runs the
+	// command against the tracker and returns an exit code where 0 means the
+	// command was successful.
+	run func(tracker *Tracker, fset *flag.FlagSet, args []string) int
+}
+
+// commands lists the subcommands of the habit CLI in the order they are shown
+// in usage output.
+var commands = []command{
+	{
+		name:    "track",
+		aliases: []string{"done"},
+		args:    "[-match] [-date YYYY-MM-DD] [-m note] [-id key] <habit-name> | <habit-name>...",
+		summary: "record that you did one or more habits, starting any that are new; with -match, names may be abbreviated",
+		run:     runTrack,
+	},
+	{
+		name:    "avoid",
+		args:    "<habit-name>",
+		summary: "start a streak of staying away from something, such as smoking",
+		run:     runAvoid,
+	},
+	{
+		name:    "relapse",
+		args:    "<habit-name>",
+		summary: "record that you did a habit you're avoiding, restarting its streak",
+		run:     runRelapse,
+	},
+	{
+		name:     "tui",
+		summary:  "check in on your habits interactively, one keystroke per habit",
+		unlocked: true,
+		run:      runTUI,
+	},
+	{
+		name:     "checkin",
+		args:     "[-mood 1-5 [-note text]]",
+		summary:  "answer y, n or skip for each habit still due and track the ones you did, or record how your day went",
+		unlocked: true,
+		run:      runCheckIn,
+	},
+	{
+		name:    "summary",
+		args:    "[-json] [-tag tag]...",
+		summary: "show how all your habits are going (the default command)",
+		run:     runSummary,
+	},
+	{
+		name:    "list",
+		args:    "[-json | -table | -archived] [-tag tag]...",
+		summary: "list your habits with their streaks and frequencies",
+		run:     runList,
+	},
+	{
+		name:    "log",
+		args:    "[-id key] <habit-name> [amount]",
+		summary: "log an amount of a quantity habit, or show every time you did a habit",
+		run:     runLog,
+	},
+	{
+		name:    "target",
+		args:    "<habit-name> <amount> [unit]",
+		summary: "set the amount of a quantity habit to log each day, such as 8 glasses",
+		run:     runTarget,
+	},
+	{
+		name:     "start",
+		args:     "[-live] [-for duration] <habit-name>",
+		summary:  "start timing a session of a habit with a target in minutes, optionally showing a live timer",
+		unlocked: true,
+		run:      runStart,
+	},
+	{
+		name:    "stop",
+		args:    "<habit-name>",
+		summary: "stop timing a session of a habit and log its minutes",
+		run:     runStop,
+	},
+	{
+		name:    "goal",
+		args:    "<habit-name> <periods>",
+		summary: "set a goal for a habit, such as 30 for a 30-day challenge, or 0 to remove it",
+		run:     runGoal,
+	},
+	{
+		name:    "find",
+		args:    "<query>",
+		summary: "list the habits whose names match a query, such as prog or pgm for programming",
+		run:     runFind,
+	},
+	{
+		name:    "stats",
+		args:    "[-days n] [habit-name | correlate | mood]",
+		summary: "show completion rates, streaks and weekdays for your habits, or which of them go together with each other or with your mood",
+		run:     runStats,
+	},
+	{
+		name:    "risk",
+		args:    "[-drop points]",
+		summary: "flag habits done much less often in the last 2 weeks than in the month before",
+		run:     runRisk,
+	},
+	{
+		name:    "heatmap",
+		args:    "[-period month|year] <habit-name>",
+		summary: "show a calendar of the days you did a habit",
+		run:     runHeatmap,
+	},
+	{
+		name:    "chart",
+		args:    "[-kind line|calendar] [-days n] [-out file.svg|file.png] <habit-name>",
+		summary: "draw a chart of a habit's streak or of the days you did it as an SVG or PNG image",
+		run:     runChart,
+	},
+	{
+		name:    "report",
+		args:    "[-format markdown] [-period month|week] [-date YYYY-MM-DD]",
+		summary: "write a review of your habits for pasting into a journal",
+		run:     runReport,
+	},
+	{
+		name:     "digest",
+		args:     "[-period week|month] [-date YYYY-MM-DD] [-send]",
+		summary:  "summarize last week or month of your habits, optionally by email",
+		external: true,
+		run:      runDigest,
+	},
+	{
+		name:    "history",
+		args:    "[-audit] [-restore <store-file>] [habit-name]",
+		summary: "show the completions of a habit, or with -audit the changes recorded in the audit log",
+		run:     runHistory,
+	},
+	{
+		name:    "undo",
+		args:    "<habit-name>",
+		summary: "undo the last time you tracked a habit",
+		run:     runUndo,
+	},
+	{
+		name:    "delete",
+		args:    "<habit-name>",
+		summary: "stop tracking a habit and remove it",
+		run:     runDelete,
+	},
+	{
+		name:    "rename",
+		args:    "<habit-name> <new-habit-name>",
+		summary: "rename a habit, keeping its streak",
+		run:     runRename,
+	},
+	{
+		name:    "frequency",
+		args:    "<habit-name> <daily|weekly|days>",
+		summary: "set how often a habit must be done",
+		run:     runFrequency,
+	},
+	{
+		name:    "schedule",
+		args:    "<habit-name> <days|weekdays|weekends|daily>...",
+		summary: "set the days of the week a daily habit must be done, such as mon wed fri",
+		run:     runSchedule,
+	},
+	{
+		name:    "freeze",
+		args:    "<habit-name> <count>",
+		summary: "give a habit streak freezes that each survive one missed period",
+		run:     runFreeze,
+	},
+	{
+		name:    "tag",
+		args:    "<habit-name> <tag>...",
+		summary: "add tags, such as health or morning, to a habit",
+		run:     runTag,
+	},
+	{
+		name:    "untag",
+		args:    "<habit-name> <tag>...",
+		summary: "remove tags from a habit",
+		run:     runUntag,
+	},
+	{
+		name:    "routine",
+		args:    "[add <routine> <habit-name>... | remove <habit-name>... | done <routine>]",
+		summary: "group habits into a routine, such as morning, and track the whole routine at once",
+		run:     runRoutine,
+	},
+	{
+		name:    "share",
+		args:    "[-days n] [-url base-url] <habit-name>...",
+		summary: "print a read-only link to the streaks of some habits, served by 'habit serve' without a token",
+		run:     runShare,
+	},
+	{
+		name:    "score",
+		summary: "show the points and level earned by doing habits, if gamification is turned on in the config file",
+		run:     runScore,
+	},
+	{
+		name:    "goals",
+		args:    "[add <goal> <habit-name>[=weight]... | remove <goal> <habit-name>...]",
+		summary: "group habits into a weighted goal, such as \"Get fit\", and show each goal's adherence over the last 7 days",
+		run:     runGoals,
+	},
+	{
+		name:    "archive",
+		args:    "<habit-name>",
+		summary: "stop tracking a habit but keep its history",
+		run:     runArchive,
+	},
+	{
+		name:    "unarchive",
+		args:    "<habit-name>",
+		summary: "start tracking an archived habit again",
+		run:     runUnarchive,
+	},
+	{
+		name:    "pause",
+		args:    "[-until YYYY-MM-DD] <habit-name>",
+		summary: "pause a habit so that its streak is kept while you're away",
+		run:     runPause,
+	},
+	{
+		name:    "resume",
+		args:    "<habit-name>",
+		summary: "resume a paused habit",
+		run:     runResume,
+	},
+	{
+		name:    "reminder",
+		args:    "<habit-name> <HH:MM>",
+		summary: "set the time of day you'd like to do a habit",
+		run:     runReminder,
+	},
+	{
+		name:    "deadline",
+		args:    "<habit-name> <HH:MM|none>",
+		summary: "set the time of day by which a daily habit should be done, after which it is overdue",
+		run:     runDeadline,
+	},
+	{
+		name:     "remind",
+		args:     "[-at HH:MM] [-every DURATION] [-terminal]",
+		summary:  "remind you every day of the habits you haven't done yet",
+		unlocked: true,
+		external: true,
+		run:      runRemind,
+	},
+	{
+		name:    "snooze",
+		args:    "<habit-name> <duration>",
+		summary: "hold back reminders of a habit for a while, such as 1h",
+		run:     runSnooze,
+	},
+	{
+		name:    "due",
+		summary: "list the habits you haven't done yet",
+		run:     runDue,
+	},
+	{
+		name:    "today",
+		args:    "[-q]",
+		summary: "list the names of the habits still due, exiting with status 6 if there are any",
+		run:     runToday,
+	},
+	{
+		name:     "prompt",
+		args:     "[-color]",
+		summary:  "print a one-line status for shell prompts",
+		readOnly: true,
+		run:      runPrompt,
+	},
+	{
+		name:    "init",
+		args:    "-from <template-file>",
+		summary: "create a set of habits declared in a YAML template file",
+		run:     runInit,
+	},
+	{
+		name:    "import",
+		args:    "[-format format] [-merge-strategy strategy] <file> | -from habitica",
+		summary: "import habits from a store, JSON or CSV file, a Loop Habit Tracker, Streaks or Apple Health export, or your Habitica account",
+		run:     runImport,
+	},
+	{
+		name:    "merge",
+		args:    "<store-file>",
+		summary: "merge the habits of another store file into yours, keeping every completion",
+		run:     runMerge,
+	},
+	{
+		name:    "migrate",
+		summary: "rewrite your store file in the latest format",
+		run:     runMigrate,
+	},
+	{
+		name:    "compact",
+		args:    "-keep <2y|18m|8w|90d>",
+		summary: "prune completions older than a retention window into monthly counts to keep your store small",
+		run:     runCompact,
+	},
+	{
+		name:    "backup",
+		args:    "list | restore <id>",
+		summary: "list the backups of your store file, or replace your habits with those of a backup",
+		run:     runBackup,
+	},
+	{
+		name:    "recompute",
+		args:    "[<habit-name>...]",
+		summary: "recompute the streaks of the given habits, or of all habits, from their history",
+		run:     runRecompute,
+	},
+	{
+		name:    "fsck",
+		args:    "[-repair]",
+		summary: "check your store for damaged records and inconsistent habits, and repair them with -repair",
+		salvage: true,
+		run:     runFsck,
+	},
+	{
+		name:    "export",
+		args:    "[-format store|json|jsonl|csv|ics] [-o file]",
+		summary: "export your habits to standard output or a file",
+		run:     runExport,
+	},
+	{
+		name:     "sync",
+		args:     "[-remote url]",
+		summary:  "merge your habits with a git repository, S3 bucket or WebDAV file shared by your devices",
+		external: true,
+		run:      runSync,
+	},
+	{
+		name:     "serve",
+		args:     "[-addr host:port] [-tls-cert file -tls-key file | -acme-domain domain...]",
+		summary:  "serve a JSON REST API, and optionally a gRPC API, for your habits",
+		unlocked: true,
+		external: true,
+		run:      runServe,
+	},
+	{
+		name:     "watch",
+		args:     "[-interval duration] [-tag tag]...",
+		summary:  "keep a live table of your habits on screen, redrawn when they change",
+		unlocked: true,
+		run:      runWatch,
+	},
+	{
+		name:     "tray",
+		summary:  "sit in the system tray showing how many habits are left today, with a menu to mark them done",
+		unlocked: true,
+		external: true,
+		run:      runTray,
+	},
+	{
+		name:     "profile",
+		args:     "[create <profile-name>]",
+		summary:  "list your profiles, or create a profile with a store of its own",
+		unlocked: true,
+		run:      runProfile,
+	},
+}
+
+func init() {
+	// The completion command is added here rather than in the commands
+	// literal because its completion scripts are generated from the
+	// commands themselves.
+	commands = append(commands, command{
+		name:     "completion",
+		args:     "[-habits] [bash|zsh|fish]",
+		summary:  "print a shell completion script, or the names of your habits with -habits",
+		unlocked: true,
+		run:      runCompletion,
+	})
+}
+
+// lockTimeout is how long the CLI waits for another habit process to release
+// the store before failing.
+const lockTimeout = 5 * time.Second
+
+// defaultBackupKeep is the number of timestamped backups kept when the config
+// file does not set one.
+const defaultBackupKeep = 10
+
+// shutdownTimeout is how long the serve command waits for the requests in
+// progress to finish and the stores to be saved when it is stopped.
+const shutdownTimeout = 10 * time.Second
+
+// tokenEnv is the environment variable holding the bearer token used by the
+// serve command and by remote stores.
+const tokenEnv = "HABIT_TOKEN"
+
+// shareSecretEnv is the environment variable holding the secret that share
+// links are signed with, overriding the config file.
+const shareSecretEnv = "HABIT_SHARE_SECRET"
+
+// passphraseEnv is the environment variable holding the passphrase of an
+// encrypted store. If it is not set, the passphrase is read from the keyring.
+const passphraseEnv = "HABIT_PASSPHRASE"
+
+// smtpPasswordEnv is the environment variable holding the password of the
+// mail server that digests are sent through, overriding the config file.
+const smtpPasswordEnv = "HABIT_SMTP_PASSWORD"
+
+// webhooksEnv is the environment variable holding the comma-separated URLs of
+// webhooks that are notified of habit events.
+const webhooksEnv = "HABIT_WEBHOOKS"
+
+// userEnv is the environment variable naming the user whose habits are kept in
+// a PostgreSQL store. If it is not set, the name of the current user is used.
+const userEnv = "HABIT_USER"
+
+// cliConfig holds the Config loaded by Main, which provides defaults for the
+// flags of commands.
+var cliConfig Config
+
+// findCommand returns the command with the given name or alias and a bool
+// indicating if the command exists.
+func findCommand(name string) (command, bool) {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, true
+		}
+		for _, alias := range cmd.aliases {
+			if alias == name {
+				return cmd, true
+			}
+		}
+	}
+	return command{}, false
+}
+
+// usage writes the usage output of the habit CLI to stdout.
+func usage() {
+	fmt.Println(`Usage: habit [-config <config-file>] [-store <store-file> | -profile <profile-name>] [-backup] [-backup-dir <dir>] [-encrypt] [-day-start <hour>] [-audit-log <log-file>] [-dry-run] [-messages <template-file>] [-log-level <level>] [command] [arguments]
+
+habit is a tool that helps users track and establish a new habit, by reporting
+their current streak. Running habit without a command shows a summary of all
+your habits.
+
+Commands:`)
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	for _, cmd := range commands {
+		fmt.Fprintf(tw, "  %s %s\t%s\n", cmd.name, cmd.args, cmd.summary)
+	}
+	tw.Flush()
+	fmt.Println(`
+The default store file is 'habit.store'. This file will be
+created automatically the first time a habit is set using
+'habit track <habit-name>'. Store files with a '.json' extension
+are saved as JSON instead, and store files with a '.db',
+'.sqlite', or '.sqlite3' extension are SQLite databases.
+Store files with a '.bolt' or '.bbolt' extension are bbolt
+databases, which only rewrite the habits that changed when
+saved and stay locked while a habit process has them open.
+A store that is an http:// or https:// URL uses the habits
+served by 'habit serve' on another machine, authenticating
+with the HABIT_TOKEN environment variable if it is set.
+A store that is a redis:// or rediss:// URL, such as
+redis://:password@localhost:6379/0, keeps the habits on a
+Redis server, under keys starting with the URL's prefix
+parameter or "habit:". A store that is a postgres:// or
+postgresql:// URL keeps the habits in a PostgreSQL database
+shared by several users, each seeing only their own habits:
+those of the user named by the HABIT_USER environment variable,
+or of the current user if it is not set.
+
+When the config file lists users, each with a name and a token,
+'habit serve' on a postgres:// store also accepts each user's
+token as a bearer token, and the requests carrying it only see
+and change that user's habits.
+
+With -profile, or the HABIT_PROFILE environment variable, the
+store of the given profile is used instead, so that work and
+personal habits can be kept apart. Create a profile with its own
+store file with 'habit profile create <profile-name>', or map a
+profile to any store in the [profiles] table of the config file.
+
+With -encrypt, the store file is encrypted with the passphrase
+in the HABIT_PASSPHRASE environment variable, or the one saved
+in your keyring for the service 'habit' and account 'store'.
+Encrypt an existing store file once with 'habit -encrypt migrate';
+an unencrypted store file is refused otherwise.
+
+With -audit-log, every change to your habits is appended to the
+given log file along with the command that made it. Review the
+changes with 'habit history -audit', or rebuild a lost store from
+them with 'habit history -audit -restore <store-file>'. The log is
+not encrypted, so it cannot be kept along with -encrypt.
+
+With -dry-run, commands such as delete, import, merge and
+migrate show the changes they would make to your habits as a
+diff instead of saving them.
+
+With -log-level, structured logs at or above the given level
+(debug, info, warn or error) are written to standard error,
+such as when the store is opened and saved and how long that
+took, when a habit is tracked, or each request handled by
+'habit serve'.
+
+With -messages, the messages written when a habit is tracked
+are read from the given Go text/template file, which defines
+any of the templates "new_habit", "again", "streak" and "reset"
+in terms of {{.Name}}, {{.Streak}}, {{.LongestStreak}},
+{{.DaysSince}}, {{.Unit}} and {{.Current}}.
+
+Set HABIT_WEBHOOKS to a comma-separated list of webhook URLs
+to be notified when a habit is created, reaches a streak
+milestone, or breaks its streak. Slack and Discord webhook
+URLs receive chat messages; other URLs receive JSON events.
+
+Messages are written in the language of the locale given by
+the LC_ALL, LC_MESSAGES or LANG environment variable, or by
+the locale in the config file: English (en), Spanish (es) or
+German (de).
+
+Output is colorized when writing to a terminal unless the
+NO_COLOR environment variable is set.
+
+Defaults for the store, day start, time zone, output format,
+locale, messages, webhooks and reminders are read from
+config.toml, config.yaml or config.yml in the habit directory
+of your config directory, such as ~/.config/habit, or from the
+file given with -config or the HABIT_CONFIG environment
+variable. Flags and environment variables take precedence over
+the config file.
+
+Exit status:
+  0  the command was successful
+  1  the command failed, or 'habit fsck' found problems it was not asked to
+     repair
+  2  a named habit does not exist
+  3  the store is locked by another habit process
+  4  the store is corrupt and cannot be read
+  5  'habit checkin' found nothing due today
+  6  'habit due' or 'habit today' found habits still due`)
+}
+
+// Main is the driver for the CLI. It reads command-line arguments and runs the
+// requested subcommand, such as tracking a Habit or printing a summary of all
+// stored Habits. It returns ExitOK if the command was successful, and otherwise
+// one of the other Exit codes describing why it failed.
+func Main() int {
+	flag.Usage = usage
+	configFile := flag.String("config", "", "path of the config file")
+	storePath := flag.String("store", DefaultStorePath, "path of the store file")
+	profile := flag.String("profile", "", "name of the profile whose store to use instead of -store")
+	encrypt := flag.Bool("encrypt", false, "encrypt the store file with the passphrase in "+passphraseEnv+" or the keyring")
+	backup := flag.Bool("backup", false, "keep a copy of the previous store file with a '.bak' extension when saving")
+	backupDir := flag.String("backup-dir", "", "directory to keep timestamped copies of the previous store file in when saving")
+	dayStart := flag.Int("day-start", 0, "hour (0-23) at which each day starts, so that habits done after midnight count toward the previous day")
+	auditLog := flag.String("audit-log", "", "path of a log file recording every change to your habits")
+	dryRun := flag.Bool("dry-run", false, "show the changes a command would make to your habits without saving them")
+	messages := flag.String("messages", "", "path of a text/template file customizing the messages written when a habit is tracked")
+	logLevel := flag.String("log-level", "", "write structured logs at or above the given level (debug, info, warn or error) to standard error")
+	flag.Parse()
+	args := flag.Args()
+	var err error
+	cliConfig, err = LoadConfig(configPath(*configFile))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if !isFlagSet(flag.CommandLine, "store") && cliConfig.Store != "" {
+		*storePath = cliConfig.Store
+	}
+	if !isFlagSet(flag.CommandLine, "day-start") {
+		*dayStart = cliConfig.DayStart
+	}
+	if !isFlagSet(flag.CommandLine, "backup") {
+		*backup = cliConfig.Backup
+	}
+	if !isFlagSet(flag.CommandLine, "backup-dir") {
+		*backupDir = cliConfig.Backups.Dir
+	}
+	if !isFlagSet(flag.CommandLine, "encrypt") {
+		*encrypt = cliConfig.Encrypt
+	}
+	if !isFlagSet(flag.CommandLine, "messages") {
+		*messages = cliConfig.Messages
+	}
+	if isFlagSet(flag.CommandLine, "audit-log") {
+		// The history command reads the audit log given in the config.
+		cliConfig.AuditLog = *auditLog
+	}
+	if *encrypt && cliConfig.AuditLog != "" {
+		// The audit log would record in plain text the habits the store
+		// keeps encrypted.
+		fmt.Fprintln(os.Stderr, "cannot keep an audit log of an encrypted store")
+		return 1
+	}
+	name := "summary"
+	if len(args) > 0 {
+		name, args = args[0], args[1:]
+	}
+	cmd, ok := findCommand(name)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q; run 'habit -help' for usage\n", name)
+		return 1
+	}
+	if *profile == "" {
+		*profile = os.Getenv(profileEnv)
+	}
+	if *profile != "" && cmd.name != "profile" {
+		if isFlagSet(flag.CommandLine, "store") {
+			fmt.Fprintln(os.Stderr, "cannot use -store with -profile")
+			return 1
+		}
+		*storePath, err = cliConfig.ProfileStore(*profile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+	// The watch command watches the store file in use.
+	cliConfig.Store = *storePath
+	if *dryRun && cmd.external {
+		fmt.Fprintf(os.Stderr, "cannot run the %s command with -dry-run\n", cmd.name)
+		return 1
+	}
+	var logHandler slog.Handler = discardHandler{}
+	if *logLevel != "" {
+		var level slog.Level
+		err = level.UnmarshalText([]byte(*logLevel))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid log level %q (want debug, info, warn or error)\n", *logLevel)
+			return 1
+		}
+		logHandler = slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level})
+	}
+	logger := slog.New(logHandler)
+	var storeOpts []storeOption
+	if cmd.unlocked || cmd.readOnly {
+		// A read-only command makes no changes, so it never takes the lock.
+		storeOpts = append(storeOpts, WithLockPerChange(lockTimeout))
+	} else {
+		storeOpts = append(storeOpts, WithLock(lockTimeout))
+	}
+	if *backup {
+		storeOpts = append(storeOpts, WithBackup())
+	}
+	if *backupDir != "" {
+		opt, err := backupDirOption(*backupDir, cliConfig.Backups)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		storeOpts = append(storeOpts, opt)
+	}
+	// Database stores cannot be salvaged, since a habit they cannot decode
+	// does not keep them from opening.
+	if cmd.salvage && !databaseStore(*storePath) {
+		storeOpts = append(storeOpts, WithSalvage())
+	}
+	if cmd.name == "migrate" {
+		// Only the migrate command encrypts a store file that is not
+		// encrypted yet; otherwise such a file is refused.
+		storeOpts = append(storeOpts, WithPlaintextMigration())
+	}
+	var store Store
+	opened := time.Now()
+	switch {
+	case cmd.readOnly && missingStoreFile(*storePath):
+		store, err = OpenStore("")
+	case *encrypt:
+		store, err = openEncrypted(*storePath, storeOpts)
+	case isRedisStore(*storePath):
+		store, err = openRedisURL(*storePath)
+	case isPostgresStore(*storePath):
+		store, err = openPostgresURL(*storePath)
+	case isRemoteStore(*storePath):
+		store, err = OpenHTTPStore(*storePath, os.Getenv(tokenEnv))
+	default:
+		store, err = Open(*storePath, storeOpts...)
+	}
+	if err != nil {
+		logger.Error("store open failed", "store", *storePath, "error", err)
+		return exitCode(err)
+	}
+	logger.Debug("store opened", "store", *storePath, "duration", time.Since(opened))
+	if backup := restoredBackup(store); backup != "" {
+		logger.Warn("store damaged, using backup", "store", *storePath, "backup", backup)
+		if !cmd.salvage {
+			fmt.Fprintf(os.Stderr, "The store %s is damaged, so the habits of its backup %s are used instead; run 'habit fsck' for details.\n", *storePath, backup)
+		}
+	}
+	if cliConfig.AuditLog != "" {
+		store = WithAuditLog(store, cliConfig.AuditLog, cmd.name)
+	}
+	var preview *Preview
+	if *dryRun {
+		preview = NewPreview(store)
+		store = preview
+	}
+	if closer, ok := store.(io.Closer); ok {
+		defer closer.Close()
+	}
+	opts := []option{WithStore(store), WithDayStartHour(*dayStart), WithLogger(logHandler)}
+	switch moodLog := moodLogPath(*storePath); {
+	case *encrypt:
+		// The mood log would record in plain text the days of an
+		// encrypted store.
+		opts = append(opts, withoutMoodLog(errMoodLogEncrypted))
+	case moodLog != "":
+		opts = append(opts, WithMoodLog(moodLog))
+	}
+	// Deletions are only recorded for a store that syncs, since only the sync
+	// command empties the log.
+	if deletionLog := deletionLogPath(*storePath); deletionLog != "" && cliConfig.Sync != "" && !*dryRun {
+		opts = append(opts, WithDeletionLog(deletionLog))
+	}
+	if cliConfig.Gamification {
+		opts = append(opts, WithGamification())
+	}
+	if loc := cliConfig.Location(); loc != nil && os.Getenv("TZ") == "" {
+		opts = append(opts, WithLocation(loc))
+	}
+	locale := cliConfig.Locale
+	if locale == "" {
+		locale = LocaleFromEnv()
+	}
+	// Messages are written in English in system locales without a catalog.
+	if _, ok := lookupLocale(locale); ok {
+		opts = append(opts, WithLocale(locale))
+	}
+	if *messages != "" {
+		opts = append(opts, WithMessageFile(*messages))
+	}
+	webhooks := cliConfig.Webhooks
+	if value, ok := os.LookupEnv(webhooksEnv); ok {
+		webhooks = strings.FieldsFunc(value, func(r rune) bool {
+			return r == ',' || unicode.IsSpace(r)
+		})
+	}
+	if *dryRun {
+		// Webhooks would announce changes that are never saved.
+		webhooks = nil
+	}
+	for _, value := range webhooks {
+		hook, err := ParseWebhook(value)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		opts = append(opts, WithEventHandler(hook.Handle))
+	}
+	opts = append(opts, WithColor(colorSupported(os.Stdout)))
+	tracker, err := NewTracker(opts...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fset := flag.NewFlagSet("habit "+cmd.name, flag.ContinueOnError)
+	fset.Usage = func() {
+		fmt.Fprintf(fset.Output(), "Usage: habit %s %s\n", cmd.name, cmd.args)
+		fset.PrintDefaults()
+	}
+	code := cmd.run(tracker, fset, args)
+	if preview == nil || code != 0 {
+		return code
+	}
+	return printPreview(preview)
+}
+
+// moodLogPath returns the path of the mood log kept alongside the store at the
+// given path or URL: the one in the config, or else a file named after a local
+// store file with a ".moods" extension. It returns "" for a remote store with
+// no mood log in the config.
+func moodLogPath(storePath string) string {
+	if cliConfig.MoodLog != "" {
+		return cliConfig.MoodLog
+	}
+	if isRemoteStore(storePath) || isRedisStore(storePath) || isPostgresStore(storePath) {
+		return ""
+	}
+	return strings.TrimSuffix(storePath, filepath.Ext(storePath)) + ".moods"
+}
+
+// deletionLogPath returns the path of the file recording the habits deleted
+// from the store at the given path until they are synced: the path of the
+// store file with a ".deleted" extension. It returns "" for a remote store.
+func deletionLogPath(storePath string) string {
+	if isRemoteStore(storePath) || isRedisStore(storePath) || isPostgresStore(storePath) {
+		return ""
+	}
+	return strings.TrimSuffix(storePath, filepath.Ext(storePath)) + ".deleted"
+}
+
+// missingStoreFile returns true if the store at the given path is a local file
+// that does not exist yet.
+func missingStoreFile(storePath string) bool {
+	if isRemoteStore(storePath) || isRedisStore(storePath) || isPostgresStore(storePath) {
+		return false
+	}
+	_, err := os.Stat(storePath)
+	return errors.Is(err, fs.ErrNotExist)
+}
+
+// printPreview writes the changes recorded by the given Preview of a dry run
+// to standard output.
+func printPreview(preview *Preview) int {
+	changes := preview.Changes()
+	if len(changes) == 0 {
+		fmt.Println("Dry run: nothing would change.")
+		return 0
+	}
+	fmt.Println("Dry run: no changes were saved.")
+	return exitCode(WriteChanges(os.Stdout, changes))
+}
+
+// openEncrypted opens the encrypted store file at the given path with the
+// passphrase in the HABIT_PASSPHRASE environment variable, or the one saved in
+// the keyring if the variable is not set.
+func openEncrypted(path string, opts []storeOption) (Store, error) {
+	if isRemoteStore(path) || isRedisStore(path) || isPostgresStore(path) {
+		return nil, fmt.Errorf("cannot encrypt remote store %q", path)
+	}
+	switch filepath.Ext(path) {
+	case ".db", ".sqlite", ".sqlite3":
+		return nil, fmt.Errorf("cannot encrypt SQLite store %q", path)
+	case ".bolt", ".bbolt":
+		return nil, fmt.Errorf("cannot encrypt bolt store %q", path)
+	}
+	key := []byte(os.Getenv(passphraseEnv))
+	if len(key) == 0 {
+		var err error
+		key, err = KeyringPassphrase()
+		if err != nil {
+			return nil, fmt.Errorf("%w; set %s to the store passphrase", err, passphraseEnv)
+		}
+	}
+	store, err := OpenEncryptedStore(path, key, opts...)
+	if errors.Is(err, errNotEncrypted) {
+		return nil, fmt.Errorf("%w; run 'habit -encrypt migrate' to encrypt it", err)
+	}
+	return store, err
+}
+
+// parseArgs parses the given arguments with the flag set and reports whether
+// parsing succeeded and exactly n positional arguments remain. A negative n
+// allows any number of positional arguments. If the arguments are invalid, the
+// flag set's usage is printed.
+func parseArgs(fset *flag.FlagSet, args []string, n int) bool {
+	err := fset.Parse(args)
+	if err != nil {
+		return false
+	}
+	if n >= 0 && fset.NArg() != n {
+		fset.Usage()
+		return false
+	}
+	return true
+}
+
+// isFlagSet reports whether any of the named flags were given when the flag
+// set was parsed.
+func isFlagSet(fset *flag.FlagSet, names ...string) bool {
+	set := false
+	fset.Visit(func(f *flag.Flag) {
+		for _, name := range names {
+			if f.Name == name {
+				set = true
+			}
+		}
+	})
+	return set
+}
+
+// Exit codes returned by Main, so that shell scripts can react differently to
+// each kind of failure.
+const (
+	// ExitOK means the command was successful.
+	ExitOK = 0
+	// ExitError means the command failed for a reason without its own exit
+	// code, such as invalid arguments.
+	ExitError = 1
+	// ExitHabitNotFound means a named habit does not exist.
+	ExitHabitNotFound = 2
+	// ExitStoreLocked means the store is locked by another habit process.
+	ExitStoreLocked = 3
+	// ExitStoreCorrupt means the store's data cannot be decoded.
+	ExitStoreCorrupt = 4
+	// ExitNothingDue means the checkin command found every habit already
+	// done.
+	ExitNothingDue = 5
+	// ExitHabitsDue means the due or today command found habits still due.
+	ExitHabitsDue = 6
+)
+
+// exitCode accepts the error returned by running a command, prints it to
+// stderr if it is non-nil, and returns the corresponding exit code.
+func exitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	fmt.Fprintln(os.Stderr, err)
+	switch {
+	case errors.Is(err, ErrHabitNotFound):
+		return ExitHabitNotFound
+	case errors.Is(err, ErrStoreLocked):
+		return ExitStoreLocked
+	case errors.Is(err, ErrStoreCorrupt):
+		return ExitStoreCorrupt
+	}
+	return ExitError
+}
+
+// runTrack runs the track command, which tracks the named habit either now or
+// on the date given with the -date flag, attaching the note given with the -m
+// flag. Several named habits are tracked now in a single save. A name that is
+// not exactly the name of a habit starts a new habit, unless the -match flag
+// is given, in which case it is resolved to the only habit starting with it.
+func runTrack(tracker *Tracker, fset *flag.FlagSet, args []string) int {
+	match := fset.Bool("match", false, "track the only habit whose name starts with each given name, instead of starting a new habit")
+	date := fset.String("date", "", "date the habit was done, as YYYY-MM-DD or an RFC 3339 timestamp")
+	note := fset.String("m", "", "note to attach to the completion, such as \"5k in the rain\"")
+	id := fset.String("id", "", "idempotency `key` with which the habit is only tracked once, such as one generated by a script that may retry")
+	if !parseArgs(fset, args, -1) {
+		return 1
+	}
+	if fset.NArg() < 1 || (fset.NArg() > 1 && (*date != "" || *note != "" || *id != "")) {
+		fset.Usage()
+		return 1
+	}
+	names := fset.Args()
+	if *match {
+		for i, query := range names {
+			name, err := tracker.resolve(query)
+			if err != nil {
+				return exitCode(err)
+			}
+			names[i] = name
+		}
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if len(names) > 1 {
+		return exitCode(tracker.TrackAllContext(ctx, names...))
+	}
+	if *date == "" && *note == "" && *id == "" {
+		return exitCode(tracker.TrackContext(ctx, names[0]))
+	}
+	at := tracker.now()
+	if *date != "" {
+		var err error
+		at, err = parseDate(*date, tracker.calendar)
+		if err != nil {
+			return exitCode(err)
+		}
+	}
+	return exitCode(tracker.TrackOnceContext(ctx, names[0], *id, at, *note))
+}
+
+// runAvoid runs the avoid command, which starts tracking the named habit to
+// avoid.
+func runAvoid(tracker *Tracker, fset *flag.FlagSet, args []string) int {
+	if !parseArgs(fset, args, 1) {
+		return 1
+	}
+	return exitCode(tracker.Avoid(fset.Arg(0)))
+}
+
+// runRelapse runs the relapse command, which records a relapse of the named
+// habit to avoid.
+func runRelapse(tracker *Tracker, fset *flag.FlagSet, args []string) int {
+	if !parseArgs(fset, args, 1) {
+		return 1
+	}
+	return exitCode(tracker.Relapse(fset.Arg(0)))
+}
+
+// parseDate accepts an RFC 3339 timestamp or a YYYY-MM-DD date and returns the
+// corresponding timestamp. A date without a time is given the current time of
+// day in the location of the given calendar, on the following day if that time
+// falls before the calendar's day starts, so that the timestamp falls on the
+// given date.
+func parseDate(value string, cal calendar) (time.Time, error) {
+	at, err := time.Parse(time.RFC3339, value)
+	if err == nil {
+		return at, nil
+	}
+	now := Now().In(cal.location)
+	day, err := time.ParseInLocation(time.DateOnly, value, cal.location)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q (want YYYY-MM-DD)", value)
+	}
+	if now.Hour() < cal.dayStart {
+		day = day.AddDate(0, 0, 1)
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(),
+		now.Hour(), now.Minute(), now.Second(), now.Nanosecond(), now.Location()), nil
+}
+
+// runTUI runs the tui command, which shows an interactive terminal UI for
+// tracking habits.
+func runTUI(tracker *Tracker, fset *flag.FlagSet, args []string) int {
+	if !parseArgs(fset, args, 0) {
+		return 1
+	}
+	return exitCode(tracker.RunTUI(os.Stdin, os.Stdout))
+}
+
+// runCheckIn runs the checkin command, which asks about each habit still due,
+// reading the answers from standard input, and exits with ExitNothingDue if
+// there are none. With the -mood flag, it records today's mood instead.
+func runCheckIn(tracker *Tracker, fset *flag.FlagSet, args []string) int {
+	mood := fset.Int("mood", 0, fmt.Sprintf("record how your day went, from %d (bad) to %d (great)", MinMood, MaxMood))
+	note := fset.String("note", "", "note about your day to record with -mood")
+	if !parseArgs(fset, args, 0) {
+		return 1
+	}
+	if isFlagSet(fset, "mood") {
+		return exitCode(tracker.RecordMood(*mood, *note))
+	}
+	if *note != "" {
+		fmt.Fprintln(os.Stderr, "-note can only be given with -mood")
+		return 1
+	}
+	nothingDue := len(tracker.Due()) < 1
+	err := tracker.CheckIn()
+	if err == nil && nothingDue {
+		return ExitNothingDue
+	}
+	return exitCode(err)
+}
+
+// runSummary runs the summary command, which prints a summary of all habits.
+func runSummary(tracker *Tracker, fset *flag.FlagSet, args []string) int {
+	asJSON := fset.Bool("json", cliConfig.Output == OutputJSON, "print the summary as JSON")
+	var tags tagsFlag
+	fset.Var(&tags, "tag", "only summarize habits with this tag (can be repeated)")
+	if !parseArgs(fset, args, 0) {
+		return 1
+	}
+	if *asJSON {
+		return exitCode(tracker.PrintSummaryJSON(tags...))
+	}
+	return exitCode(tracker.PrintSummary(tags...))
+}
+
+// runList runs the list command, which lists all habits with their streaks, or
+// the archived habits if the -archived flag is given.
+func runList(tracker *Tracker, fset *flag.FlagSet, args []string) int {
+	asJSON := fset.Bool("json", false, "print the list as JSON")
+	asTable := fset.Bool("table", false, "print the list as a table with each habit's status")
+	archived := fset.Bool("archived", false, "list archived habits instead")
+	var tags tagsFlag
+	fset.Var(&tags, "tag", "only list habits with this tag (can be repeated)")
+	if !parseArgs(fset, args, 0) {
+		return 1
+	}
+	if !isFlagSet(fset, "json", "table", "archived") {
+		*asJSON = cliConfig.Output == OutputJSON
+		*asTable = cliConfig.Output == OutputTable
+	}
+	if (*asJSON && *archived) || (*asTable && (*asJSON || *archived)) {
+		fset.Usage()
+		return 1
+	}
+	if *archived {
+		return exitCode(tracker.PrintArchived(tags...))
+	}
+	if *asJSON {
+		return exitCode(tracker.PrintSummaryJSON(tags...))
+	}
+	if *asTable {
+		return exitCode(tracker.PrintTable(tags...))
+	}
+	return exitCode(tracker.PrintList(tags...))
+}
+
+// runLog runs the log command, which logs the given amount of the named
+// quantity habit, or prints the completions of the named habit with their
+// notes if no amount is given.
+func runLog(tracker *Tracker, fset *flag.FlagSet, args []string) int {
+	id := fset.String("id", "", "idempotency `key` with which the amount is only logged once")
+	if !parseArgs(fset, args, -1) {
+		return 1
+	}
+	switch fset.NArg() {
+	case 1:
+		return exitCode(tracker.PrintLog(fset.Arg(0)))
+	case 2:
+		amount, err := strconv.ParseFloat(fset.Arg(1), 64)
+		if err != nil {
+			return exitCode(fmt.Errorf("invalid amount %q", fset.Arg(1)))
+		}
+		return exitCode(tracker.LogAmountOnce(fset.Arg(0), amount, *id))
+	}
+	fset.Usage()
+	return 1
+}
+
+// runTarget runs the target command, which sets the target amount and unit of
+// the named quantity habit.
+func runTarget(tracker *Tracker, fset *flag.FlagSet, args []string) int {
+	if !parseArgs(fset, args, -1) {
+		return 1
+	}
+	if fset.NArg() < 2 || fset.NArg() > 3 {
+		fset.Usage()
+		return 1
+	}
+	target, err := strconv.ParseFloat(fset.Arg(1), 64)
+	if err != nil {
+		return exitCode(fmt.Errorf("invalid target %q", fset.Arg(1)))
+	}
+	return exitCode(tracker.SetTarget(fset.Arg(0), target, fset.Arg(2)))
+}
+
+// runStart runs the start command, which starts a session of the named
+// duration habit, and with -live or -for times it on screen until it is
+// interrupted or its duration has elapsed.
+func runStart(tracker *Tracker, fset *flag.FlagSet, args []string) int {
+	live := fset.Bool("live", false, "show a live timer and stop the session on Ctrl+C")
+	d := fset.Duration("for", 0, "stop the session after this `duration`, such as 25m for a pomodoro, showing a live timer")
+	if !parseArgs(fset, args, 1) {
+		return 1
+	}
+	if *d < 0 {
+		fmt.Fprintf(os.Stderr, "invalid session duration %s (want a positive duration, such as 25m)\n", *d)
+		return 1
+	}
+	if !*live && *d == 0 {
+		return exitCode(tracker.StartSession(fset.Arg(0)))
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return exitCode(tracker.TimeSession(ctx, fset.Arg(0), *d))
+}
+
+// runStop runs the stop command, which stops the running session of the named
+// duration habit.
+func runStop(tracker *Tracker, fset *flag.FlagSet, args []string) int {
+	if !parseArgs(fset, args, 1) {
+		return 1
+	}
+	return exitCode(tracker.StopSession(fset.Arg(0)))
+}
+
+// runGoal runs the goal command, which sets the length of the streak the named
+// habit is being done for.
+func runGoal(tracker *Tracker, fset *flag.FlagSet, args []string) int {
+	if !parseArgs(fset, args, 2) {
+		return 1
+	}
+	goal, err := strconv.Atoi(fset.Arg(1))
+	if err != nil {
+		return exitCode(fmt.Errorf("invalid goal %q", fset.Arg(1)))
+	}
+	return exitCode(tracker.SetGoal(fset.Arg(0), goal))
+}
+
+// runFind runs the find command, which lists the names of the habits matching
+// the given query, best matches first, and exits with status 1 if none match.
+func runFind(tracker *Tracker, fset *flag.FlagSet, args []string) int {
+	if !parseArgs(fset, args, 1) {
+		return 1
+	}
+	matches := tracker.Find(fset.Arg(0))
+	if len(matches) < 1 {
+		fmt.Fprintf(os.Stderr, "No habits match '%s'.\n", fset.Arg(0))
+		return 1
+	}
+	for _, hbt := range matches {
+		fmt.Fprintln(tracker.output, hbt.Name)
+	}
+	return 0
+}
+
+// runStats runs the stats command, which prints statistics for the named habit
+// or for all habits if no name is given, or with "correlate", how closely the
+// days on which each pair of habits was done match, or with "mood", how closely
+// each habit being done goes together with the mood recorded by checkin.
+func runStats(tracker *Tracker, fset *flag.FlagSet, args []string) int {
+	days := fset.Int("days", DefaultStatsWindow, "number of days over which to compute completion rates")
+	if !parseArgs(fset, args, -1) {
+		return 1
+	}
+	if fset.NArg() > 1 {
+		fset.Usage()
+		return 1
+	}
+	switch fset.Arg(0) {
+	case "correlate":
+		if !isFlagSet(fset, "days") {
+			*days = DefaultCorrelationWindow
+		}
+		return exitCode(tracker.PrintCorrelations(*days))
+	case "mood":
+		if !isFlagSet(fset, "days") {
+			*days = DefaultCorrelationWindow
+		}
+		return exitCode(tracker.PrintMoodCorrelations(*days))
+	}
+	return exitCode(tracker.PrintStats(fset.Arg(0), *days))
+}
+
+// runShare runs the share command, which prints a read-only link to the named
+// habits on the server at the base URL given with the -url flag, signed with
+// the share secret.
+func runShare(tracker *Tracker, fset *flag.FlagSet, args []string) int {
+	days := fset.Int("days", DefaultShareDays, "number of days for which the link is valid")
+	baseURL := fset.String("url", "http://localhost:8080", "base URL of the server started by 'habit serve'")
+	if !parseArgs(fset, args, -1) {
+		return 1
+	}
+	if fset.NArg() < 1 {
+		fset.Usage()
+		return 1
+	}
+	secret := shareSecret()
+	if secret == "" {
+		fmt.Fprintf(os.Stderr, "Sharing is turned off. Set %s or share_secret in the config file to create share links.\n", shareSecretEnv)
+		return 1
+	}
+	token, expires, err := NewServer(tracker, WithShareSecret(secret)).ShareToken(fset.Args(), *days)
+	if err != nil {
+		return exitCode(err)
+	}
+	fmt.Println(ShareURL(*baseURL, token))
+	fmt.Printf("Anyone with the link can see these habits until %s.\n", expires.Local().Format(time.DateOnly))
+	return 0
+}
+
+// shareSecret returns the secret that share links are signed with: the value
+// of the HABIT_SHARE_SECRET environment variable if it is set, and otherwise
+// the one in the config file.
+func shareSecret() string {
+	if secret, ok := os.LookupEnv(shareSecretEnv); ok {
+		return secret
+	}
+	return cliConfig.ShareSecret
+}
+
+// runScore runs the score command, which prints the points and level earned by
+// doing habits.
+func runScore(tracker *Tracker, fset *flag.FlagSet, args []string) int {
+	if !parseArgs(fset, args, 0) {
+		return 1
+	}
+	tracker.PrintScore()
+	return 0
+}
+
+// runRisk runs the risk command, which lists the habits whose completion rate
+// has dropped recently.
+func runRisk(tracker *Tracker, fset *flag.FlagSet, args []string) int {
+	drop := fset.Float64("drop", DefaultRiskDrop, "drop in completion rate, in percentage points, from which to flag a habit")
+	if !parseArgs(fset, args, 0) {
+		return 1
+	}
+	return exitCode(tracker.PrintRisks(*drop))
+}
+
+// runHeatmap runs the heatmap command, which prints a calendar heatmap of the
+// named habit's completions.
+func runHeatmap(tracker *Tracker, fset *flag.FlagSet, args []string) int {
+	periodName := fset.String("period", "month", "span of the calendar: month or year")
+	if !parseArgs(fset, args, 1) {
+		return 1
+	}
+	period, err := ParseHeatmapPeriod(*periodName)
+	if err != nil {
+		return exitCode(err)
+	}
+	return exitCode(tracker.PrintHeatmap(fset.Arg(0), period))
+}
+
+// runChart runs the chart command, which writes a chart of the named habit to
+// the SVG or PNG file given with the -out flag, or as SVG to standard output.
+func runChart(tracker *Tracker, fset *flag.FlagSet, args []string) int {
+	kindName := fset.String("kind", ChartLine.String(), "kind of chart: line or calendar")
+	days := fset.Int("days", DefaultChartDays, "number of days to chart")
+	path := fset.String("out", "", "SVG or PNG file to write instead of writing SVG to standard output")
+	if !parseArgs(fset, args, 1) {
+		return 1
+	}
+	kind, err := ParseChartKind(*kindName)
+	if err != nil {
+		return exitCode(err)
+	}
+	if *path == "" {
+		return exitCode(tracker.WriteChart(os.Stdout, fset.Arg(0), kind, ChartSVG, *days))
+	}
+	format, err := ChartFormatForPath(*path)
+	if err != nil {
+		return exitCode(err)
+	}
+	var buf bytes.Buffer
+	err = tracker.WriteChart(&buf, fset.Arg(0), kind, format, *days)
+	if err != nil {
+		return exitCode(err)
+	}
+	return exitCode(os.WriteFile(*path, buf.Bytes(), 0o644))
+}
+
+// runReport runs the report command, which writes a review of every habit over
+// the month or week containing today, or the date given with the -date flag.
+func runReport(tracker *Tracker, fset *flag.FlagSet, args []string) int {
+	formatName := fset.String("format", "markdown", "format of the report: markdown")
+	periodName := fset.String("period", "month", "span of the report: month or week")
+	dateValue := fset.String("date", "", "report on the period containing this date (YYYY-MM-DD) instead of today")
+	if !parseArgs(fset, args, 0) {
+		return 1
+	}
+	format, err := ParseReportFormat(*formatName)
+	if err != nil {
+		return exitCode(err)
+	}
+	period, err := ParseReportPeriod(*periodName)
+	if err != nil {
+		return exitCode(err)
+	}
+	date := tracker.now()
+	if *dateValue != "" {
+		date, err = time.ParseInLocation(time.DateOnly, *dateValue, tracker.calendar.location)
+		if err != nil {
+			return exitCode(fmt.Errorf("invalid date %q (want YYYY-MM-DD)", *dateValue))
+		}
+	}
+	return exitCode(tracker.PrintReport(period, format, date))
+}
+
+// runDigest runs the digest command, which writes a digest of the last week or
+// month before today, or before the date given with the -date flag, or emails
+// it with the SMTP settings of the config file if the -send flag is set.
+func runDigest(tracker *Tracker, fset *flag.FlagSet, args []string) int {
+	periodName := fset.String("period", "week", "span of the digest: week or month")
+	dateValue := fset.String("date", "", "digest the period before the one containing this date (YYYY-MM-DD) instead of today")
+	send := fset.Bool("send", false, "email the digest with the smtp settings of the config file")
+	if !parseArgs(fset, args, 0) {
+		return 1
+	}
+	period, err := ParseReportPeriod(*periodName)
+	if err != nil {
+		return exitCode(err)
+	}
+	date := tracker.now()
+	if *dateValue != "" {
+		date, err = time.ParseInLocation(time.DateOnly, *dateValue, tracker.calendar.location)
+		if err != nil {
+			return exitCode(fmt.Errorf("invalid date %q (want YYYY-MM-DD)", *dateValue))
+		}
+	}
+	if !*send {
+		return exitCode(tracker.PrintDigest(period, date))
+	}
+	cfg := cliConfig.SMTP
+	if password, ok := os.LookupEnv(smtpPasswordEnv); ok {
+		cfg.Password = password
+	}
+	return exitCode(tracker.SendDigest(period, date, cfg))
+}
+
+// runHistory runs the history command, which writes the completions of the
+// named habit, or with the -audit flag the changes recorded in the audit log,
+// optionally only those to the named habit. With the -restore flag, the audit
+// log is instead replayed into a new store file.
+func runHistory(tracker *Tracker, fset *flag.FlagSet, args []string) int {
+	audit := fset.Bool("audit", false, "show the changes recorded in the audit log")
+	restore := fset.String("restore", "", "rebuild the habits recorded in the audit log into this new store file")
+	if !parseArgs(fset, args, -1) {
+		return 1
+	}
+	if fset.NArg() > 1 || (!*audit && (fset.NArg() != 1 || *restore != "")) {
+		fset.Usage()
+		return 1
+	}
+	if !*audit {
+		return exitCode(tracker.PrintLog(fset.Arg(0)))
+	}
+	if cliConfig.AuditLog == "" {
+		fmt.Fprintln(os.Stderr, "no audit log is kept; set 'audit_log' in the config file or use -audit-log")
+		return 1
+	}
+	f, err := os.Open(cliConfig.AuditLog)
+	if err != nil {
+		return exitCode(err)
+	}
+	defer f.Close()
+	if *restore == "" {
+		return exitCode(tracker.PrintAuditLog(f, fset.Arg(0)))
+	}
+	_, err = os.Stat(*restore)
+	if err == nil {
+		return exitCode(fmt.Errorf("cannot restore into %s, which already exists", *restore))
+	}
+	store, err := Open(*restore)
+	if err != nil {
+		return exitCode(err)
+	}
+	if closer, ok := store.(io.Closer); ok {
+		defer closer.Close()
+	}
+	err = RestoreAuditLog(f, store)
+	if err != nil {
+		return exitCode(err)
+	}
+	fmt.Fprintf(tracker.output, "Restored %d %s from the audit log into %s.\n",
+		len(store.All()), habitsUnit(len(store.All())), *restore)
+	return 0
+}
+
+// runUndo runs the undo command, which undoes the most recent completion of
+// the named habit.
+func runUndo(tracker *Tracker, fset *flag.FlagSet, args []string) int {
+	if !parseArgs(fset, args, 1) {
+		return 1
+	}
+	return exitCode(tracker.Undo(fset.Arg(0)))
+}
+
+// runDelete runs the delete command, which deletes the named habit.
+func runDelete(tracker *Tracker, fset *flag.FlagSet, args []string) int {
+	if !parseArgs(fset, args, 1) {
+		return 1
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return exitCode(tracker.DeleteContext(ctx, fset.Arg(0)))
+}
+
+// runRename runs the rename command, which renames the named habit.
+func runRename(tracker *Tracker, fset *flag.FlagSet, args []string) int {
+	if !parseArgs(fset, args, 2) {
+		return 1
+	}
+	return exitCode(tracker.Rename(fset.Arg(0), fset.Arg(1)))
+}
+
+// runFrequency runs the frequency command, which sets the frequency of the
+// named habit.
+func runFrequency(tracker *Tracker, fset *flag.FlagSet, args []string) int {
+	if !parseArgs(fset, args, 2) {
+		return 1
+	}
+	freq, err := ParseFrequency(fset.Arg(1))
+	if err != nil {
+		return exitCode(err)
+	}
+	return exitCode(tracker.SetFrequency(fset.Arg(0), freq))
+}
+
+// runSchedule runs the schedule command, which sets the days of the week on
+// which the named habit must be done.
+func runSchedule(tracker *Tracker, fset *flag.FlagSet, args []string) int {
+	if !parseArgs(fset, args, -1) {
+		return 1
+	}
+	if fset.NArg() < 2 {
+		fset.Usage()
+		return 1
+	}
+	weekdays, err := ParseWeekdays(strings.Join(fset.Args()[1:], " "))
+	if err != nil {
+		return exitCode(err)
+	}
+	return exitCode(tracker.SetSchedule(fset.Arg(0), weekdays))
+}
+
+// runFreeze runs the freeze command, which sets the number of streak freezes
+// left for the named habit.
+func runFreeze(tracker *Tracker, fset *flag.FlagSet, args []string) int {
+	if !parseArgs(fset, args, 2) {
+		return 1
+	}
+	count, err := strconv.Atoi(fset.Arg(1))
+	if err != nil {
+		return exitCode(fmt.Errorf("invalid number of streak freezes %q", fset.Arg(1)))
+	}
+	return exitCode(tracker.SetFreezes(fset.Arg(0), count))
+}
+
+// runTag runs the tag command, which adds the given tags to the named habit.
+func runTag(tracker *Tracker, fset *flag.FlagSet, args []string) int {
+	if !parseArgs(fset, args, -1) {
+		return 1
+	}
+	if fset.NArg() < 2 {
+		fset.Usage()
+		return 1
+	}
+	return exitCode(tracker.Tag(fset.Arg(0), fset.Args()[1:]...))
+}
+
+// runUntag runs the untag command, which removes the given tags from the named
+// habit.
+func runUntag(tracker *Tracker, fset *flag.FlagSet, args []string) int {
+	if !parseArgs(fset, args, -1) {
+		return 1
+	}
+	if fset.NArg() < 2 {
+		fset.Usage()
+		return 1
+	}
+	return exitCode(tracker.Untag(fset.Arg(0), fset.Args()[1:]...))
+}
+
+// runRoutine runs the routine command, which adds habits to a routine, removes
+// them from their routines, or tracks every habit in a routine, depending on
+// its first argument. Without arguments, it lists the routines.
+func runRoutine(tracker *Tracker, fset *flag.FlagSet, args []string) int {
+	if !parseArgs(fset, args, -1) {
+		return 1
+	}
+	rest := fset.Args()
+	if len(rest) > 0 {
+		rest = rest[1:]
+	}
+	switch {
+	case fset.NArg() == 0:
+		tracker.PrintRoutines()
+		return 0
+	case fset.Arg(0) == "add" && len(rest) >= 2:
+		return exitCode(tracker.AddToRoutine(rest[0], rest[1:]...))
+	case fset.Arg(0) == "remove" && len(rest) >= 1:
+		return exitCode(tracker.RemoveFromRoutine(rest...))
+	case fset.Arg(0) == "done" && len(rest) == 1:
+		return exitCode(tracker.DoRoutine(rest[0]))
+	}
+	fset.Usage()
+	return 1
+}
+
+// runGoals runs the goals command, which adds habits to a named goal or removes
+// them from it, depending on its first argument. Without arguments, it lists
+// the goals with their adherence.
+func runGoals(tracker *Tracker, fset *flag.FlagSet, args []string) int {
+	if !parseArgs(fset, args, -1) {
+		return 1
+	}
+	rest := fset.Args()
+	if len(rest) > 0 {
+		rest = rest[1:]
+	}
+	switch {
+	case fset.NArg() == 0:
+		tracker.PrintGoals()
+		return 0
+	case fset.Arg(0) == "add" && len(rest) >= 2:
+		members, err := parseGoalMembers(rest[1:])
+		if err != nil {
+			return exitCode(err)
+		}
+		return exitCode(tracker.AddToGoal(rest[0], members...))
+	case fset.Arg(0) == "remove" && len(rest) >= 2:
+		return exitCode(tracker.RemoveFromGoal(rest[0], rest[1:]...))
+	}
+	fset.Usage()
+	return 1
+}
+
+// parseGoalMembers parses habit names, each optionally followed by "=" and
+// its weight in a goal, such as "running=2". A habit without a weight has a
+// weight of 1.
+func parseGoalMembers(args []string) ([]GoalMember, error) {
+	members := make([]GoalMember, len(args))
+	for i, arg := range args {
+		name, weight, ok := strings.Cut(arg, "=")
+		members[i] = GoalMember{Habit: name, Weight: 1}
+		if !ok {
+			continue
+		}
+		w, err := strconv.ParseFloat(weight, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid weight %q for habit '%s' (want a number, such as 2)", weight, name)
+		}
+		members[i].Weight = w
+	}
+	return members, nil
+}
+
+// tagsFlag is a flag.Value that collects the values of a repeatable flag.
+type tagsFlag []string
+
+// String returns the collected values separated by commas.
+func (f *tagsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+// Set adds the given value to the collected values.
+func (f *tagsFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+// runArchive runs the archive command, which archives the named habit.
+func runArchive(tracker *Tracker, fset *flag.FlagSet, args []string) int {
+	if !parseArgs(fset, args, 1) {
+		return 1
+	}
+	return exitCode(tracker.Archive(fset.Arg(0)))
+}
+
+// runUnarchive runs the unarchive command, which restores the named archived
+// habit.
+func runUnarchive(tracker *Tracker, fset *flag.FlagSet, args []string) int {
+	if !parseArgs(fset, args, 1) {
+		return 1
+	}
+	return exitCode(tracker.Unarchive(fset.Arg(0)))
+}
+
+// runPause runs the pause command, which pauses the named habit until the date
+// given with the -until flag or until it is resumed.
+func runPause(tracker *Tracker, fset *flag.FlagSet, args []string) int {
+	untilDate := fset.String("until", "", "date on which the habit resumes, as YYYY-MM-DD (default: until resumed)")
+	if !parseArgs(fset, args, 1) {
+		return 1
+	}
+	var until time.Time
+	if *untilDate != "" {
+		day, err := time.ParseInLocation(time.DateOnly, *untilDate, tracker.calendar.location)
+		if err != nil {
+			return exitCode(fmt.Errorf("invalid date %q (want YYYY-MM-DD)", *untilDate))
+		}
+		until = day.Add(time.Duration(tracker.calendar.dayStart) * time.Hour)
+	}
+	return exitCode(tracker.Pause(fset.Arg(0), until))
+}
+
+// runResume runs the resume command, which ends the pause of the named habit.
+func runResume(tracker *Tracker, fset *flag.FlagSet, args []string) int {
+	if !parseArgs(fset, args, 1) {
+		return 1
+	}
+	return exitCode(tracker.Resume(fset.Arg(0)))
+}
+
+// runReminder runs the reminder command, which sets the reminder time of the
+// named habit.
+func runReminder(tracker *Tracker, fset *flag.FlagSet, args []string) int {
+	if !parseArgs(fset, args, 2) {
+		return 1
+	}
+	at, err := ParseTimeOfDay(fset.Arg(1))
+	if err != nil {
+		return exitCode(err)
+	}
+	return exitCode(tracker.SetReminder(fset.Arg(0), at))
+}
+
+// runDue runs the due command, which lists the habits not done yet in their
+// current period and exits with ExitHabitsDue if there are any.
+func runDue(tracker *Tracker, fset *flag.FlagSet, args []string) int {
+	if !parseArgs(fset, args, 0) {
+		return 1
+	}
+	if tracker.PrintDue() > 0 {
+		return ExitHabitsDue
+	}
+	return ExitOK
+}
+
+// runToday runs the today command, which lists the names of the habits still
+// due and exits with ExitHabitsDue if there are any, so that it can be used in
+// shell prompts and scripts. The -q flag only sets the exit status.
+func runToday(tracker *Tracker, fset *flag.FlagSet, args []string) int {
+	quiet := fset.Bool("q", false, fmt.Sprintf("print nothing, only exiting with status %d if any habits are due", ExitHabitsDue))
+	if !parseArgs(fset, args, 0) {
+		return 1
+	}
+	remaining := 0
+	if *quiet {
+		remaining = len(tracker.Due())
+	} else {
+		remaining = tracker.PrintToday()
+	}
+	if remaining > 0 {
+		return ExitHabitsDue
+	}
+	return ExitOK
+}
+
+// runDeadline runs the deadline command, which sets or, given "none", removes
+// the deadline of the named habit.
+func runDeadline(tracker *Tracker, fset *flag.FlagSet, args []string) int {
+	if !parseArgs(fset, args, 2) {
+		return 1
+	}
+	if fset.Arg(1) == "none" {
+		return exitCode(tracker.SetDeadline(fset.Arg(0), nil))
+	}
+	at, err := ParseTimeOfDay(fset.Arg(1))
+	if err != nil {
+		return exitCode(err)
+	}
+	return exitCode(tracker.SetDeadline(fset.Arg(0), &at))
+}
+
+// runRemind runs the remind command, which sends a desktop notification, or
+// prints a message with the -terminal flag, listing the habits that are still
+// due every day at the time given with the -at flag, until it is interrupted.
+func runRemind(tracker *Tracker, fset *flag.FlagSet, args []string) int {
+	defaultAt := "20:00"
+	if cliConfig.Reminder.At != "" {
+		defaultAt = cliConfig.Reminder.At
+	}
+	atValue := fset.String("at", defaultAt, "time of day to send the reminder (HH:MM)")
+	terminal := fset.Bool("terminal", cliConfig.Reminder.Terminal, "print reminders instead of sending desktop notifications")
+	everyValue := fset.String("every", cliConfig.Reminder.Every, "repeat reminders at this interval (such as 30m) while habits are still due")
+	if !parseArgs(fset, args, 0) {
+		return 1
+	}
+	at, err := ParseTimeOfDay(*atValue)
+	if err != nil {
+		return exitCode(err)
+	}
+	var every time.Duration
+	if *everyValue != "" {
+		every, err = time.ParseDuration(*everyValue)
+		if err != nil || every <= 0 {
+			fmt.Fprintf(os.Stderr, "invalid reminder interval %q (want a positive duration, such as 30m)\n", *everyValue)
+			return 1
+		}
+	}
+	notifier := TerminalNotifier(os.Stdout)
+	if !*terminal {
+		notifier = DesktopNotifier(os.Stdout)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if every > 0 {
+		fmt.Printf("Reminding you of habits that are still due every day at %s and every %s after. Press Ctrl+C to stop.\n", at, every)
+	} else {
+		fmt.Printf("Reminding you of habits that are still due every day at %s. Press Ctrl+C to stop.\n", at)
+	}
+	return exitCode(tracker.RemindEvery(ctx, at, every, notifier))
+}
+
+// runSnooze runs the snooze command, which holds back reminders of the named
+// habit for the given duration.
+func runSnooze(tracker *Tracker, fset *flag.FlagSet, args []string) int {
+	if !parseArgs(fset, args, 2) {
+		return 1
+	}
+	d, err := time.ParseDuration(fset.Arg(1))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid snooze duration %q (want a duration, such as 1h or 30m)\n", fset.Arg(1))
+		return 1
+	}
+	return exitCode(tracker.Snooze(fset.Arg(0), d))
+}
+
+// runPrompt runs the prompt command, which prints the tracker's prompt summary
+// without a trailing newline. The summary is only colorized with the -color
+// flag, even on a terminal, since shell prompts do not expect escape sequences.
+func runPrompt(tracker *Tracker, fset *flag.FlagSet, args []string) int {
+	color := fset.Bool("color", false, "colorize the summary, which is plain by default even on a terminal")
+	if !parseArgs(fset, args, 0) {
+		return 1
+	}
+	err := WithColor(*color)(tracker)
+	if err != nil {
+		return exitCode(err)
+	}
+	fmt.Fprint(tracker.output, tracker.Prompt())
+	return 0
+}
+
+// runInit runs the init command, which creates the habits declared in the
+// template file given with the -from flag.
+func runInit(tracker *Tracker, fset *flag.FlagSet, args []string) int {
+	from := fset.String("from", "", "YAML template file declaring the habits to create")
+	if !parseArgs(fset, args, 0) {
+		return 1
+	}
+	if *from == "" {
+		fset.Usage()
+		return 1
+	}
+	f, err := os.Open(*from)
+	if err != nil {
+		return exitCode(err)
+	}
+	defer f.Close()
+	tmpl, err := LoadTemplate(f)
+	if err != nil {
+		return exitCode(err)
+	}
+	return exitCode(tracker.CreateFromTemplate(tmpl))
+}
+
+// runImport runs the import command, which imports the named file into the
+// tracker. The file's format is taken from its extension unless given with the
+// -format flag.
+func runImport(tracker *Tracker, fset *flag.FlagSet, args []string) int {
+	formatName := fset.String("format", "", "format of the file: store, json, csv, loop, streaks, or health (default from the file extension)")
+	strategyName := fset.String("merge-strategy", MergeLatest.String(),
+		"how to resolve habits that already exist: keep-existing, overwrite, or latest")
+	from := fset.String("from", "", "import from a service instead of a file: habitica, whose account is given with -user and -token")
+	user := fset.String("user", "", "user ID of the Habitica account to import from with -from habitica")
+	token := fset.String("token", "", "API token of the Habitica account to import from with -from habitica (default from "+habiticaTokenEnv+")")
+	if !parseArgs(fset, args, -1) {
+		return 1
+	}
+	if *from != "" {
+		if fset.NArg() != 0 {
+			fset.Usage()
+			return 1
+		}
+		return importFrom(tracker, *from, *user, *token, *strategyName)
+	}
+	if fset.NArg() != 1 {
+		fset.Usage()
+		return 1
+	}
+	format := FormatForPath(fset.Arg(0))
+	if *formatName != "" {
+		var err error
+		format, err = ParseFormat(*formatName)
+		if err != nil {
+			return exitCode(err)
+		}
+	}
+	strategy, err := ParseMergeStrategy(*strategyName)
+	if err != nil {
+		return exitCode(err)
+	}
+	f, err := os.Open(fset.Arg(0))
+	if err != nil {
+		return exitCode(err)
+	}
+	defer f.Close()
+	return exitCode(tracker.Import(f, format, strategy))
+}
+
+// habiticaTokenEnv is the environment variable holding the API token of the
+// Habitica account to import from, if the -token flag is not given.
+const habiticaTokenEnv = "HABITICA_TOKEN"
+
+// importFrom imports the habits of the given user of the named service, which
+// must be "habitica", authenticating with the given API token or the one in
+// the HABITICA_TOKEN environment variable.
+func importFrom(tracker *Tracker, service, user, token, strategyName string) int {
+	if service != "habitica" {
+		fmt.Fprintf(os.Stderr, "unknown import source %q (want habitica)\n", service)
+		return 1
+	}
+	if token == "" {
+		token = os.Getenv(habiticaTokenEnv)
+	}
+	if user == "" || token == "" {
+		fmt.Fprintf(os.Stderr, "importing from Habitica needs -user and -token or %s\n", habiticaTokenEnv)
+		return 1
+	}
+	strategy, err := ParseMergeStrategy(strategyName)
+	if err != nil {
+		return exitCode(err)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return exitCode(tracker.ImportHabitica(ctx, DefaultHabiticaURL, user, token, strategy))
+}
+
+// runMigrate runs the migrate command, which rewrites the store file at the
+// current schema version.
+func runMigrate(tracker *Tracker, fset *flag.FlagSet, args []string) int {
+	if !parseArgs(fset, args, 0) {
+		return 1
+	}
+	return exitCode(tracker.Migrate())
+}
+
+// runCompact runs the compact command, which prunes the completions older than
+// the retention window given with the -keep flag from the history of every
+// habit.
+func runCompact(tracker *Tracker, fset *flag.FlagSet, args []string) int {
+	keep := fset.String("keep", "", "how much history to keep, in years, months, weeks or days, such as 2y")
+	if !parseArgs(fset, args, 0) {
+		return 1
+	}
+	if *keep == "" {
+		fset.Usage()
+		return 1
+	}
+	before, err := retentionCutoff(*keep, tracker.now())
+	if err != nil {
+		return exitCode(err)
+	}
+	return exitCode(tracker.Compact(before))
+}
+
+// runBackup runs the backup command, which lists the backups of the store
+// file or restores the one with the given ID.
+func runBackup(tracker *Tracker, fset *flag.FlagSet, args []string) int {
+	if !parseArgs(fset, args, -1) {
+		return 1
+	}
+	switch {
+	case fset.Arg(0) == "list" && fset.NArg() == 1:
+		backups, err := tracker.Backups()
+		if err != nil {
+			return exitCode(err)
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		for _, b := range backups {
+			fmt.Fprintf(tw, "%s\t%s\t%d bytes\n", b.ID, b.At.In(tracker.calendar.location).Format("2006-01-02 15:04:05"), b.Size)
+		}
+		tw.Flush()
+		return 0
+	case fset.Arg(0) == "restore" && fset.NArg() == 2:
+		return exitCode(tracker.RestoreBackup(fset.Arg(1)))
+	}
+	fset.Usage()
+	return 1
+}
+
+// backupDirOption returns the storeOption keeping timestamped backups in the
+// given directory, with the limits of the given BackupConfig. An error is
+// returned if its maximum age is invalid.
+func backupDirOption(dir string, cfg BackupConfig) (storeOption, error) {
+	keep := cfg.Keep
+	if keep == 0 {
+		keep = defaultBackupKeep
+	}
+	var maxAge time.Duration
+	if cfg.MaxAge != "" {
+		now := time.Now()
+		cutoff, err := retentionCutoff(cfg.MaxAge, now)
+		if err != nil {
+			return nil, fmt.Errorf("invalid backups max_age in config file: %w", err)
+		}
+		maxAge = now.Sub(cutoff)
+	}
+	return WithBackupDir(dir, keep, maxAge), nil
+}
+
+// runRecompute runs the recompute command, which recomputes the streaks of
+// the habits given as its arguments, or of every habit, from their histories.
+func runRecompute(tracker *Tracker, fset *flag.FlagSet, args []string) int {
+	if !parseArgs(fset, args, -1) {
+		return 1
+	}
+	return exitCode(tracker.Recompute(fset.Args()...))
+}
+
+// runFsck runs the fsck command, which checks the store for problems and
+// repairs them if the -repair flag is given. It fails if problems are found
+// and not repaired.
+func runFsck(tracker *Tracker, fset *flag.FlagSet, args []string) int {
+	repair := fset.Bool("repair", false, "fix the problems found, moving records that cannot be decoded to a quarantine file")
+	if !parseArgs(fset, args, 0) {
+		return 1
+	}
+	problems, err := tracker.Check(*repair)
+	if err != nil {
+		return exitCode(err)
+	}
+	if len(problems) > 0 && !*repair {
+		return 1
+	}
+	return ExitOK
+}
+
+// runMerge runs the merge command, which merges the habits of the store file
+// given as its argument into the tracker's store. The other store file is not
+// changed.
+func runMerge(tracker *Tracker, fset *flag.FlagSet, args []string) int {
+	if !parseArgs(fset, args, 1) {
+		return 1
+	}
+	// Opening a store that does not exist creates an empty one, which would
+	// make a mistyped path merge nothing without complaint.
+	_, err := os.Stat(fset.Arg(0))
+	if err != nil {
+		return exitCode(err)
+	}
+	other, err := Open(fset.Arg(0))
+	if err != nil {
+		return exitCode(err)
+	}
+	if closer, ok := other.(io.Closer); ok {
+		defer closer.Close()
+	}
+	return exitCode(tracker.Merge(other))
+}
+
+// runExport runs the export command, which writes every habit to standard
+// output or to the file given with the -o flag.
+func runExport(tracker *Tracker, fset *flag.FlagSet, args []string) int {
+	formatName := fset.String("format", FormatCSV.String(), "format to export: store, json, jsonl, csv, or ics")
+	path := fset.String("o", "", "file to write instead of standard output")
+	if !parseArgs(fset, args, 0) {
+		return 1
+	}
+	format, err := ParseFormat(*formatName)
+	if err != nil {
+		return exitCode(err)
+	}
+	if *path == "" {
+		return exitCode(tracker.Export(os.Stdout, format))
+	}
+	f, err := os.Create(*path)
+	if err != nil {
+		return exitCode(err)
+	}
+	err = tracker.Export(f, format)
+	closeErr := f.Close()
+	if err == nil {
+		err = closeErr
+	}
+	return exitCode(err)
+}
+
+// runSync runs the sync command, which merges the tracker's habits with the
+// sync remote given with the -remote flag or in the config file.
+func runSync(tracker *Tracker, fset *flag.FlagSet, args []string) int {
+	remoteURL := fset.String("remote", cliConfig.Sync, "URL of the git repository, s3:// object or webdav:// file to sync with")
+	if !parseArgs(fset, args, 0) {
+		return 1
+	}
+	if *remoteURL == "" {
+		fmt.Fprintln(os.Stderr, "no sync remote given; set 'sync' in the config file or use -remote")
+		return 1
+	}
+	remote, err := ParseSyncRemote(*remoteURL)
+	if err != nil {
+		return exitCode(err)
+	}
+	return exitCode(tracker.Sync(remote))
+}
+
+// runServe runs the serve command, which serves a JSON REST API for the
+// tracker's habits on the address given with the -addr flag until the process
+// is stopped, along with the gRPC API on the address given with the -grpc-addr
+// flag, if any. If the HABIT_TOKEN environment variable is set, requests must
+// carry it as a bearer token. If the config file has users, requests may carry
+// a user's token instead and then only see that user's habits. Share links
+// signed with the share secret are served without a token. Both APIs are
+// served over TLS with the certificate given with the -tls-cert and -tls-key
+// flags, or with certificates from Let's Encrypt for the domains given with
+// -acme-domain. On SIGINT or SIGTERM the servers stop accepting requests,
+// finish the ones in progress and save the stores before the command exits.
+func runServe(tracker *Tracker, fset *flag.FlagSet, args []string) int {
+	addr := fset.String("addr", ":8080", "address to listen on")
+	grpcAddr := fset.String("grpc-addr", "", "address to also serve the gRPC API on")
+	rateLimit := fset.Float64("rate-limit", 0, "requests per second allowed from each token or client, or 0 for no limit")
+	burst := fset.Int("burst", 10, "requests allowed at once beyond the rate limit")
+	maxBody := fset.Int64("max-body", DefaultMaxBodySize, "largest request body in bytes, or 0 for no limit")
+	tlsCert := fset.String("tls-cert", "", "PEM `file` of the certificate to serve over TLS")
+	tlsKey := fset.String("tls-key", "", "PEM `file` of the private key of the -tls-cert certificate")
+	var domains tagsFlag
+	fset.Var(&domains, "acme-domain", "domain to serve over TLS with a certificate from Let's Encrypt (can be repeated)")
+	acmeCache := fset.String("acme-cache", "", "`directory` to cache Let's Encrypt certificates in (default in the user cache directory)")
+	if !parseArgs(fset, args, 0) {
+		return 1
+	}
+	opts := []serverOption{
+		WithToken(os.Getenv(tokenEnv)),
+		WithRateLimit(*rateLimit, *burst),
+		WithMaxBodySize(*maxBody),
+		WithShareSecret(shareSecret()),
+	}
+	var tlsConfig *tls.Config
+	switch {
+	case (*tlsCert != "" || *tlsKey != "") && len(domains) > 0:
+		fmt.Fprintln(os.Stderr, "-tls-cert and -tls-key cannot be used with -acme-domain")
+		return 1
+	case *tlsCert != "" || *tlsKey != "":
+		if *tlsCert == "" || *tlsKey == "" {
+			fmt.Fprintln(os.Stderr, "-tls-cert and -tls-key must be given together")
+			return 1
+		}
+		var err error
+		tlsConfig, err = LoadTLSConfig(*tlsCert, *tlsKey)
+		if err != nil {
+			return exitCode(err)
+		}
+	case len(domains) > 0:
+		var err error
+		tlsConfig, err = AutocertTLSConfig(*acmeCache, domains...)
+		if err != nil {
+			return exitCode(err)
+		}
+	}
+	if tlsConfig != nil {
+		opts = append(opts, WithTLS(tlsConfig))
+	}
+	if len(cliConfig.Users) > 0 {
+		stores, err := userStores(tracker.store)
+		if err != nil {
+			return exitCode(err)
+		}
+		opts = append(opts, WithUsers(cliConfig.userTokens(), stores))
+	}
+	srv := NewServer(tracker, opts...)
+	httpSrv := &http.Server{Addr: *addr, Handler: srv, TLSConfig: tlsConfig}
+	var grpcSrv *grpc.Server
+	errs := make(chan error, 2)
+	if *grpcAddr != "" {
+		lis, err := net.Listen("tcp", *grpcAddr)
+		if err != nil {
+			return exitCode(err)
+		}
+		fmt.Printf("Serving habits over gRPC on %s\n", *grpcAddr)
+		grpcSrv = srv.GRPCServer()
+		go func() {
+			errs <- grpcSrv.Serve(lis)
+		}()
+	}
+	fmt.Printf("Serving habits on %s\n", *addr)
+	go func() {
+		if tlsConfig != nil {
+			// The certificates are in the TLS configuration.
+			errs <- httpSrv.ListenAndServeTLS("", "")
+			return
+		}
+		errs <- httpSrv.ListenAndServe()
+	}()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	select {
+	case err := <-errs:
+		return exitCode(err)
+	case <-ctx.Done():
+	}
+	fmt.Println("Shutting down")
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	err := httpSrv.Shutdown(ctx)
+	if grpcSrv != nil {
+		stopped := make(chan struct{})
+		go func() {
+			grpcSrv.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-ctx.Done():
+			// Calls streaming changes to habits only end when the client
+			// hangs up.
+			grpcSrv.Stop()
+		}
+	}
+	// The stores are saved even if the requests in progress took too long.
+	return exitCode(errors.Join(err, srv.Flush(context.Background())))
+}
+
+// userStores returns the UserStores of the PostgreSQL database that holds the
+// given store, keeping every user's habits apart. An error is returned if the
+// store is not a PostgresStore, since other stores hold the habits of a single
+// user.
+func userStores(store Store) (UserStores, error) {
+	if a, ok := store.(*auditStore); ok {
+		store = a.Store
+	}
+	ps, ok := store.(*PostgresStore)
+	if !ok {
+		return nil, errors.New("users in the config file need a postgres:// store to keep their habits apart")
+	}
+	db := &PostgresDB{db: ps.db}
+	return func(user string) (Store, error) {
+		s, err := db.Store(user)
+		if err != nil {
+			return nil, err
+		}
+		if cliConfig.AuditLog != "" {
+			return WithAuditLog(s, cliConfig.AuditLog, "serve"), nil
+		}
+		return s, nil
+	}, nil
+}
+
+// runWatch runs the watch command, which redraws the table of habits every
+// interval given with the -interval flag and whenever the store file changes,
+// until the process is stopped.
+func runWatch(tracker *Tracker, fset *flag.FlagSet, args []string) int {
+	interval := fset.Duration("interval", DefaultWatchInterval, "how often to redraw the table when the store does not change")
+	var tags tagsFlag
+	fset.Var(&tags, "tag", "only list habits with this tag (can be repeated)")
+	if !parseArgs(fset, args, 0) {
+		return 1
+	}
+	path := cliConfig.Store
+	if isRemoteStore(path) || isRedisStore(path) || isPostgresStore(path) {
+		// Only store files can be watched for changes.
+		path = ""
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return exitCode(tracker.Watch(ctx, *interval, path, tags...))
+}
+
+// runTray runs the tray command, which shows the habits left today in the
+// system tray until the user quits it.
+func runTray(tracker *Tracker, fset *flag.FlagSet, args []string) int {
+	if !parseArgs(fset, args, 0) {
+		return 1
+	}
+	path := cliConfig.Store
+	if isRemoteStore(path) || isRedisStore(path) || isPostgresStore(path) {
+		// Only store files can be watched for changes.
+		path = ""
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return exitCode(tracker.RunTray(ctx, path, DesktopNotifier(os.Stdout)))
+}
+
+// runProfile runs the profile command, which lists the profiles, or creates
+// the named profile with "create".
+func runProfile(_ *Tracker, fset *flag.FlagSet, args []string) int {
+	if !parseArgs(fset, args, -1) {
+		return 1
+	}
+	switch {
+	case fset.NArg() == 0:
+		names, err := cliConfig.ProfileNames()
+		if err != nil {
+			return exitCode(err)
+		}
+		for _, name := range names {
+			fmt.Println(name)
+		}
+		return 0
+	case fset.Arg(0) == "create" && fset.NArg() == 2:
+		name := fset.Arg(1)
+		if _, ok := cliConfig.Profiles[name]; ok {
+			fmt.Fprintf(os.Stderr, "profile '%s' already exists in the config file\n", name)
+			return 1
+		}
+		path, err := CreateProfile(name)
+		if err != nil {
+			return exitCode(err)
+		}
+		fmt.Printf("Created profile '%s' with the store %s. Use it with 'habit -profile %s'.\n", name, path, name)
+		return 0
+	}
+	fset.Usage()
+	return 1
+}
+
+// runCompletion runs the completion command, which writes a completion script
+// for the given shell to standard output, or the names of every habit with the
+// -habits flag.
+func runCompletion(tracker *Tracker, fset *flag.FlagSet, args []string) int {
+	habits := fset.Bool("habits", false, "print the name of every habit, one per line")
+	if !parseArgs(fset, args, -1) {
+		return 1
+	}
+	if *habits {
+		if fset.NArg() != 0 {
+			fset.Usage()
+			return 1
+		}
+		tracker.PrintHabitNames()
+		return 0
+	}
+	if fset.NArg() != 1 {
+		fset.Usage()
+		return 1
+	}
+	shell, err := ParseShell(fset.Arg(0))
+	if err != nil {
+		return exitCode(err)
+	}
+	return exitCode(WriteCompletion(os.Stdout, shell))
+}
@@ -0,0 +1,32 @@
+package habit
+
+import "os"
+
+// ANSI escape sequences used to colorize output when color is enabled.
+const (
+	colorRed    = "\033[31m"
+	colorGreen  = "\033[32m"
+	colorYellow = "\033[33m"
+	colorReset  = "\033[0m"
+)
+
+// colorize returns the given text wrapped in the given ANSI color escape
+// sequence if the Tracker was created with color enabled, and unchanged
+// otherwise.
+func (t *Tracker) colorize(color, text string) string {
+	if !t.color {
+		return text
+	}
+	return color + text + colorReset
+}
+
+// colorSupported reports whether output written to the given file should be
+// colorized by default: the file must be a terminal and the NO_COLOR
+// environment variable (https://no-color.org) must be unset or empty.
+func colorSupported(f *os.File) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,177 @@
+package habit
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// A Compaction records the completions pruned from the history of a Habit by
+// Compact.
+type Compaction struct {
+	// Before is the timestamp before which completions were pruned.
+	Before time.Time `json:"before"`
+	// Months counts the completions pruned in each month, in chronological
+	// order.
+	Months []MonthlyCount `json:"months"`
+}
+
+// A MonthlyCount is the number of completions of a Habit within a calendar
+// month that were pruned from its history by Compact.
+type MonthlyCount struct {
+	// Month is the month, formatted as YYYY-MM.
+	Month string `json:"month"`
+	// Count is the number of completions pruned from the month.
+	Count int `json:"count"`
+}
+
+// completions returns the number of times the Habit has been done, counting
+// both the completions in its history and those pruned from it.
+func (h Habit) completions() int {
+	n := len(h.History)
+	if h.Compacted != nil {
+		for _, mc := range h.Compacted.Months {
+			n += mc.Count
+		}
+	}
+	return n
+}
+
+// Compact prunes the completions done before the given timestamp from the
+// history of every Habit, adding them up into a MonthlyCount for each calendar
+// month they fall in, and saves the store, so that the store stays small and
+// quick to load after years of use. The completions of a Habit's current
+// streak are kept however old they are, so that its streak can still be
+// recomputed, and a Habit's streaks and total number of completions are
+// unchanged, although statistics by weekday no longer count the pruned
+// completions. A message saying how many completions were pruned is written to
+// the Tracker's output. An error is returned if the store cannot be saved.
+func (t *Tracker) Compact(before time.Time) error {
+	pruned, habits := 0, 0
+	for _, hbt := range t.store.All() {
+		n := t.compact(&hbt, before)
+		if n == 0 {
+			continue
+		}
+		t.store.Add(hbt)
+		pruned += n
+		habits++
+	}
+	if pruned == 0 {
+		fmt.Fprintf(t.output, "No completions before %s to prune.\n", t.calendar.day(before).Format(time.DateOnly))
+		return nil
+	}
+	err := t.save()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(t.output, "Pruned %d %s before %s from %d %s.\n", pruned, plural(pruned, "completion"),
+		t.calendar.day(before).Format(time.DateOnly), habits, plural(habits, "habit"))
+	return nil
+}
+
+// compact prunes the completions of the given Habit done before the given
+// timestamp, other than those of its current streak, into its monthly counts
+// and returns the number of completions pruned.
+func (t *Tracker) compact(hbt *Habit, before time.Time) int {
+	if !hbt.Avoid {
+		if start, ok := currentStreakStart(*hbt, t.calendar); ok && start.Before(before) {
+			before = start
+		}
+	}
+	i := sort.Search(len(hbt.History), func(i int) bool {
+		return !hbt.History[i].At.Before(before)
+	})
+	if i == 0 {
+		return 0
+	}
+	compaction := Compaction{Before: before}
+	counts := map[string]int{}
+	if hbt.Compacted != nil {
+		compaction.Before = laterOf(before, hbt.Compacted.Before)
+		for _, mc := range hbt.Compacted.Months {
+			counts[mc.Month] = mc.Count
+		}
+	}
+	for _, c := range hbt.History[:i] {
+		counts[t.calendar.day(c.At).Format("2006-01")]++
+	}
+	compaction.Months = monthlyCounts(counts)
+	hbt.Compacted = &compaction
+	hbt.History = append([]Completion(nil), hbt.History[i:]...)
+	return i
+}
+
+// currentStreakStart returns the timestamp of the first completion of the
+// current streak of the given Habit, following the rules of computeStreaks,
+// and reports whether the Habit has any completions.
+func currentStreakStart(hbt Habit, cal calendar) (time.Time, bool) {
+	if len(hbt.History) == 0 {
+		return time.Time{}, false
+	}
+	start := len(hbt.History) - 1
+	for start > 0 {
+		prev, c := hbt.History[start-1], hbt.History[start]
+		if hbt.periodsBetween(prev.At, c.At, cal) >= 1 && !c.Frozen {
+			break
+		}
+		start--
+	}
+	return hbt.History[start].At, true
+}
+
+// monthlyCounts returns the given counts by month as MonthlyCounts in
+// chronological order.
+func monthlyCounts(counts map[string]int) []MonthlyCount {
+	var mcs []MonthlyCount
+	for month, count := range counts {
+		mcs = append(mcs, MonthlyCount{Month: month, Count: count})
+	}
+	sort.Slice(mcs, func(i, j int) bool {
+		return mcs[i].Month < mcs[j].Month
+	})
+	return mcs
+}
+
+// retentionCutoff returns the timestamp the given retention window, such as
+// "2y", "18m", "8w" or "90d" for years, months, weeks or days, reaches back to
+// from the given timestamp. An error is returned if the window is not a
+// positive number followed by one of those units.
+func retentionCutoff(window string, now time.Time) (time.Time, error) {
+	invalid := fmt.Errorf("invalid retention window %q (want a number of years, months, weeks or days, such as 2y, 18m, 8w or 90d)", window)
+	if len(window) < 2 {
+		return time.Time{}, invalid
+	}
+	n, err := strconv.Atoi(window[:len(window)-1])
+	if err != nil || n <= 0 {
+		return time.Time{}, invalid
+	}
+	switch window[len(window)-1] {
+	case 'y':
+		return now.AddDate(-n, 0, 0), nil
+	case 'm':
+		return now.AddDate(0, -n, 0), nil
+	case 'w':
+		return now.AddDate(0, 0, -7*n), nil
+	case 'd':
+		return now.AddDate(0, 0, -n), nil
+	}
+	return time.Time{}, invalid
+}
+
+// laterOf returns the later of the given timestamps.
+func laterOf(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+// plural returns the given noun, followed by "s" unless the given count is 1.
+func plural(count int, noun string) string {
+	if count == 1 {
+		return noun
+	}
+	return noun + "s"
+}
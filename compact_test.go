@@ -0,0 +1,113 @@
+package habit_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTracker_CompactPrunesOldCompletionsIntoMonthlyCounts(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T13:00:00Z")
+	at := func(s string) time.Time {
+		ts, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ts
+	}
+	reading := habit.Habit{
+		Name:          "reading",
+		CurrentStreak: 3,
+		LongestStreak: 3,
+		LastDone:      at("2024-02-06T09:00:00Z"),
+		History: []habit.Completion{
+			{At: at("2022-01-10T20:00:00Z")},
+			{At: at("2022-01-20T20:00:00Z")},
+			{At: at("2022-03-05T20:00:00Z")},
+			{At: at("2024-02-04T20:00:00Z")},
+			{At: at("2024-02-05T12:00:00Z")},
+			{At: at("2024-02-06T09:00:00Z")},
+		},
+	}
+	// Every completion of the current streak of cleaning is kept, although
+	// it started before the cutoff.
+	cleaning := habit.Habit{
+		Name:          "cleaning",
+		Frequency:     habit.Weekly,
+		CurrentStreak: 3,
+		LongestStreak: 3,
+		LastDone:      at("2024-01-03T10:00:00Z"),
+		History: []habit.Completion{
+			{At: at("2023-12-22T10:00:00Z")},
+			{At: at("2023-12-28T10:00:00Z")},
+			{At: at("2024-01-03T10:00:00Z")},
+		},
+	}
+	store := &memStore{habits: map[string]habit.Habit{"reading": reading, "cleaning": cleaning}}
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output), habit.WithLocation(time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := at("2024-01-01T00:00:00Z")
+	err = tracker.Compact(before)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := "Pruned 3 completions before 2024-01-01 from 1 habit.\n", output.String(); want != got {
+		t.Errorf("want output %q, got output %q", want, got)
+	}
+	if store.saves != 1 {
+		t.Errorf("want 1 save, got %d", store.saves)
+	}
+	wantReading := reading
+	wantReading.History = reading.History[3:]
+	wantReading.Compacted = &habit.Compaction{
+		Before: before,
+		Months: []habit.MonthlyCount{{Month: "2022-01", Count: 2}, {Month: "2022-03", Count: 1}},
+	}
+	if !cmp.Equal(wantReading, store.habits["reading"]) {
+		t.Error(cmp.Diff(wantReading, store.habits["reading"]))
+	}
+	if !cmp.Equal(cleaning, store.habits["cleaning"]) {
+		t.Error(cmp.Diff(cleaning, store.habits["cleaning"]))
+	}
+	summaries := tracker.Summarize()
+	for _, s := range summaries {
+		if s.Name == "reading" && s.Completions != 6 {
+			t.Errorf("want 6 completions of reading counting pruned ones, got %d", s.Completions)
+		}
+	}
+}
+
+func TestMergeStores_DoesNotRestoreCompletionsPrunedFromEitherStore(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T13:00:00Z")
+	old := time.Date(2022, time.January, 10, 20, 0, 0, 0, time.UTC)
+	recent := time.Date(2024, time.February, 6, 9, 0, 0, 0, time.UTC)
+	compacted := habit.Habit{
+		Name:          "reading",
+		CurrentStreak: 1,
+		LongestStreak: 1,
+		LastDone:      recent,
+		History:       []habit.Completion{{At: recent}},
+		Compacted: &habit.Compaction{
+			Before: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC),
+			Months: []habit.MonthlyCount{{Month: "2022-01", Count: 1}},
+		},
+	}
+	full := compacted
+	full.History = []habit.Completion{{At: old}, {At: recent}}
+	full.Compacted = nil
+	a := &memStore{habits: map[string]habit.Habit{"reading": full}}
+	b := &memStore{habits: map[string]habit.Habit{"reading": compacted}}
+	err := habit.MergeStores(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(compacted, a.habits["reading"]) {
+		t.Error(cmp.Diff(compacted, a.habits["reading"]))
+	}
+}
@@ -0,0 +1,216 @@
+package habit
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// A Shell is a command-line shell for which completion scripts can be
+// generated.
+type Shell int
+
+const (
+	// Bash is the GNU Bourne-Again Shell.
+	Bash Shell = iota
+	// Zsh is the Z shell.
+	Zsh
+	// Fish is the friendly interactive shell.
+	Fish
+)
+
+// ParseShell accepts "bash", "zsh" or "fish" and returns the corresponding
+// Shell. An error is returned if the value is not recognized.
+func ParseShell(value string) (Shell, error) {
+	switch value {
+	case "bash":
+		return Bash, nil
+	case "zsh":
+		return Zsh, nil
+	case "fish":
+		return Fish, nil
+	}
+	return 0, fmt.Errorf("invalid shell %q (want bash, zsh or fish)", value)
+}
+
+// WriteCompletion writes a script to the given writer that completes the
+// commands of the habit CLI in the given Shell, along with the names of
+// existing habits for commands that take one. Habit names are read when
+// completing by running 'habit completion -habits', passing on any -store or
+// -config flag already typed.
+func WriteCompletion(w io.Writer, shell Shell) error {
+	switch shell {
+	case Bash:
+		return writeBashCompletion(w)
+	case Zsh:
+		return writeZshCompletion(w)
+	case Fish:
+		return writeFishCompletion(w)
+	}
+	return fmt.Errorf("unsupported shell %d", shell)
+}
+
+// PrintHabitNames writes the name of every Habit, including archived Habits,
+// to the given Tracker's output, one per line and sorted by name.
+func (t *Tracker) PrintHabitNames() {
+	var names []string
+	for _, hbt := range t.store.All() {
+		names = append(names, hbt.Name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintln(t.output, name)
+	}
+}
+
+// commandNames returns the names and aliases of the CLI's commands. If
+// habitArg is true, only the commands that take a habit name are returned.
+func commandNames(habitArg bool) []string {
+	var names []string
+	for _, cmd := range commands {
+		if habitArg && !strings.Contains(cmd.args, "habit-name") {
+			continue
+		}
+		names = append(names, cmd.name)
+		names = append(names, cmd.aliases...)
+	}
+	return names
+}
+
+// writeBashCompletion writes the bash completion script to the given writer.
+func writeBashCompletion(w io.Writer) error {
+	_, err := fmt.Fprintf(w, `# bash completion for habit
+# Load it with: source <(habit completion bash)
+
+_habit() {
+	local cur=${COMP_WORDS[COMP_CWORD]}
+	local i cmd= opts=()
+	for ((i = 1; i < COMP_CWORD; i++)); do
+		case ${COMP_WORDS[i]} in
+		-store | -config)
+			opts+=("${COMP_WORDS[i]}" "${COMP_WORDS[i+1]}")
+			((i++))
+			;;
+		-day-start) ((i++)) ;;
+		-*) ;;
+		*)
+			cmd=${COMP_WORDS[i]}
+			break
+			;;
+		esac
+	done
+	if [[ -z $cmd ]]; then
+		COMPREPLY=($(compgen -W "%s" -- "$cur"))
+		return
+	fi
+	case $cmd in
+	%s)
+		local IFS=$'\n'
+		COMPREPLY=($(compgen -W "$(habit "${opts[@]}" completion -habits 2>/dev/null)" -- "$cur"))
+		;;
+	esac
+}
+
+complete -F _habit habit
+`, strings.Join(commandNames(false), " "), strings.Join(commandNames(true), " | "))
+	return err
+}
+
+// writeZshCompletion writes the zsh completion script to the given writer.
+func writeZshCompletion(w io.Writer) error {
+	var descriptions strings.Builder
+	for _, cmd := range commands {
+		for _, name := range append([]string{cmd.name}, cmd.aliases...) {
+			fmt.Fprintf(&descriptions, "\t\t%s\n", zshQuote(name+":"+cmd.summary))
+		}
+	}
+	_, err := fmt.Fprintf(w, `#compdef habit
+# zsh completion for habit
+# Load it with: source <(habit completion zsh)
+
+_habit() {
+	local -a commands habits opts
+	local i cmd
+	commands=(
+%s	)
+	for ((i = 2; i < CURRENT; i++)); do
+		case $words[i] in
+		-store | -config)
+			opts+=($words[i] $words[i+1])
+			((i++))
+			;;
+		-day-start) ((i++)) ;;
+		-*) ;;
+		*)
+			cmd=$words[i]
+			break
+			;;
+		esac
+	done
+	if [[ -z $cmd ]]; then
+		_describe -t commands 'habit command' commands
+		return
+	fi
+	case $cmd in
+	%s)
+		habits=(${(f)"$(habit $opts completion -habits 2>/dev/null)"})
+		compadd -a habits
+		;;
+	esac
+}
+
+compdef _habit habit
+`, descriptions.String(), strings.Join(commandNames(true), " | "))
+	return err
+}
+
+// writeFishCompletion writes the fish completion script to the given writer.
+func writeFishCompletion(w io.Writer) error {
+	_, err := fmt.Fprint(w, `# fish completion for habit
+# Load it with: habit completion fish | source
+
+function __habit_habits
+	set -l opts
+	set -l tokens (commandline -opc)
+	for i in (seq 2 (count $tokens))
+		switch $tokens[$i]
+			case -store -config
+				set -a opts $tokens[$i] $tokens[(math $i + 1)]
+		end
+	end
+	habit $opts completion -habits 2>/dev/null
+end
+
+complete -c habit -f
+`)
+	if err != nil {
+		return err
+	}
+	for _, cmd := range commands {
+		for _, name := range append([]string{cmd.name}, cmd.aliases...) {
+			_, err = fmt.Fprintf(w, "complete -c habit -n __fish_use_subcommand -a %s -d %s\n",
+				name, fishQuote(cmd.summary))
+			if err != nil {
+				return err
+			}
+		}
+	}
+	_, err = fmt.Fprintf(w, "complete -c habit -n '__fish_seen_subcommand_from %s' -a '(__habit_habits)'\n",
+		strings.Join(commandNames(true), " "))
+	return err
+}
+
+// zshQuote returns the given text as a single-quoted zsh word, with colons
+// other than the first escaped for _describe.
+func zshQuote(text string) string {
+	name, desc, _ := strings.Cut(text, ":")
+	text = name + ":" + strings.ReplaceAll(desc, ":", `\:`)
+	return "'" + strings.ReplaceAll(text, "'", `'\''`) + "'"
+}
+
+// fishQuote returns the given text as a single-quoted fish word.
+func fishQuote(text string) string {
+	text = strings.ReplaceAll(text, `\`, `\\`)
+	return "'" + strings.ReplaceAll(text, "'", `\'`) + "'"
+}
@@ -0,0 +1,88 @@
+package habit_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseShell_AcceptsSupportedShells(t *testing.T) {
+	t.Parallel()
+	testCases := map[string]habit.Shell{
+		"bash": habit.Bash,
+		"zsh":  habit.Zsh,
+		"fish": habit.Fish,
+	}
+	for value, want := range testCases {
+		got, err := habit.ParseShell(value)
+		if err != nil {
+			t.Fatalf("%s: %v", value, err)
+		}
+		if want != got {
+			t.Errorf("%s: want %d, got %d", value, want, got)
+		}
+	}
+	_, err := habit.ParseShell("powershell")
+	if err == nil {
+		t.Error("want error for unsupported shell, got nil")
+	}
+}
+
+func TestWriteCompletion_CompletesCommandsAndHabitNames(t *testing.T) {
+	t.Parallel()
+	testCases := map[habit.Shell][]string{
+		habit.Bash: {
+			"complete -F _habit habit",
+			"track done avoid",
+			"track | done | avoid",
+			"completion -habits",
+		},
+		habit.Zsh: {
+			"#compdef habit",
+			`'relapse:record that you did a habit you'\''re avoiding, restarting its streak'`,
+			"track | done | avoid",
+			"completion -habits",
+		},
+		habit.Fish: {
+			"complete -c habit -n __fish_use_subcommand -a relapse -d 'record that you did a habit you\\'re avoiding, restarting its streak'",
+			"__fish_seen_subcommand_from track done avoid",
+			"completion -habits",
+		},
+	}
+	for shell, want := range testCases {
+		output := new(bytes.Buffer)
+		err := habit.WriteCompletion(output, shell)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, line := range want {
+			if !strings.Contains(output.String(), line) {
+				t.Errorf("shell %d: want script containing %q, got:\n%s", shell, line, output)
+			}
+		}
+	}
+}
+
+func TestTracker_PrintHabitNamesWritesEveryHabitSortedByName(t *testing.T) {
+	t.Parallel()
+	store, err := habit.OpenStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{Name: "reading"})
+	store.Add(habit.Habit{Name: "cycling", Archived: true})
+	store.Add(habit.Habit{Name: "programming"})
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker.PrintHabitNames()
+	want := "cycling\nprogramming\nreading\n"
+	if got := output.String(); want != got {
+		t.Error(cmp.Diff(want, got))
+	}
+}
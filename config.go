@@ -0,0 +1,353 @@
+package habit
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats that may be given as the default output in a Config.
+const (
+	OutputText  = "text"
+	OutputJSON  = "json"
+	OutputTable = "table"
+)
+
+// Config holds defaults for the habit CLI, read from a TOML or YAML config
+// file. Flags and environment variables take precedence over a Config, and a
+// Config takes precedence over the CLI's built-in defaults. Zero values mean
+// that the built-in default is used.
+type Config struct {
+	// Store is the path or URL of the store.
+	Store string `toml:"store" yaml:"store"`
+	// Backup is true if a copy of the previous store file should be kept when
+	// saving.
+	Backup bool `toml:"backup" yaml:"backup"`
+	// Backups holds the settings of the timestamped backups of the store
+	// file kept before each save.
+	Backups BackupConfig `toml:"backups" yaml:"backups"`
+	// Encrypt is true if the store file should be encrypted with the
+	// passphrase in the HABIT_PASSPHRASE environment variable or the keyring.
+	Encrypt bool `toml:"encrypt" yaml:"encrypt"`
+	// DayStart is the hour (0-23) at which each day starts.
+	DayStart int `toml:"day_start" yaml:"day_start"`
+	// Timezone is the IANA name of the location used to decide which calendar
+	// day a completion falls on, such as "Europe/London".
+	Timezone string `toml:"timezone" yaml:"timezone"`
+	// Output is the default output format of the summary and list commands:
+	// "text", "json" or "table". Summaries are printed as text when the
+	// output is "table".
+	Output string `toml:"output" yaml:"output"`
+	// AuditLog is the path of the audit log file that every change to the
+	// habits is appended to. No audit log is kept if it is empty.
+	AuditLog string `toml:"audit_log" yaml:"audit_log"`
+	// MoodLog is the path of the file that the moods recorded with the
+	// checkin command are appended to. It defaults to a file named after the
+	// store file with a ".moods" extension, in the same directory.
+	MoodLog string `toml:"mood_log" yaml:"mood_log"`
+	// Gamification is true if points should be awarded each time a habit is
+	// done, adding up to levels shown by the score command.
+	Gamification bool `toml:"gamification" yaml:"gamification"`
+	// Locale is the name of the locale that messages are written in, such
+	// as "es" or "de_DE". It takes precedence over the LC_ALL, LC_MESSAGES
+	// and LANG environment variables, which name the locale of the whole
+	// system.
+	Locale string `toml:"locale" yaml:"locale"`
+	// Messages is the path of a text/template file defining the messages
+	// written when a habit is tracked, as accepted by WithMessageFile. The
+	// built-in messages are used if it is empty.
+	Messages string `toml:"messages" yaml:"messages"`
+	// Sync is the URL of the remote that the sync command merges the store
+	// with, as accepted by ParseSyncRemote. When it is set, the habits
+	// deleted from a local store are recorded until they are synced.
+	Sync string `toml:"sync" yaml:"sync"`
+	// ShareSecret is the secret that the share links created by the share
+	// command and served by the serve command are signed with. Sharing is
+	// turned off if it is empty.
+	ShareSecret string `toml:"share_secret" yaml:"share_secret"`
+	// Webhooks are the URLs of webhooks that are notified of habit events.
+	Webhooks []string `toml:"webhooks" yaml:"webhooks"`
+	// Profiles holds the settings of profiles, selected with the -profile
+	// flag, by profile name, so that a profile can keep its habits in any
+	// store, such as a database.
+	Profiles map[string]ProfileConfig `toml:"profiles" yaml:"profiles"`
+	// Users are the users of the server started by the serve command, each
+	// authenticating with a token of their own and seeing only their own
+	// habits.
+	Users []UserConfig `toml:"users" yaml:"users"`
+	// Reminder holds the defaults of the remind command.
+	Reminder ReminderConfig `toml:"reminder" yaml:"reminder"`
+	// SMTP holds the settings of the mail server that the digest command
+	// sends digests through.
+	SMTP SMTPConfig `toml:"smtp" yaml:"smtp"`
+}
+
+// ProfileConfig holds the settings of a profile.
+type ProfileConfig struct {
+	// Store is the path or URL of the profile's store.
+	Store string `toml:"store" yaml:"store"`
+}
+
+// UserConfig holds a user of the server started by the serve command.
+type UserConfig struct {
+	// Name is the name of the user, whose habits are kept apart from other
+	// users' habits in the store.
+	Name string `toml:"name" yaml:"name"`
+	// Token is the bearer token, or API key, that the user's requests carry.
+	Token string `toml:"token" yaml:"token"`
+}
+
+// BackupConfig holds the settings of the timestamped backups of the store
+// file kept before each save.
+type BackupConfig struct {
+	// Dir is the directory the backups are kept in. No timestamped backups
+	// are kept if it is empty.
+	Dir string `toml:"dir" yaml:"dir"`
+	// Keep is the number of backups kept. It defaults to 10.
+	Keep int `toml:"keep" yaml:"keep"`
+	// MaxAge is how long backups are kept, in years, months, weeks or days,
+	// such as "90d". Backups are kept however old they are if it is empty.
+	MaxAge string `toml:"max_age" yaml:"max_age"`
+}
+
+// ReminderConfig holds the defaults of the remind command.
+type ReminderConfig struct {
+	// At is the time of day at which reminders are sent, as HH:MM.
+	At string `toml:"at" yaml:"at"`
+	// Terminal is true if reminders should be printed instead of sent as
+	// desktop notifications.
+	Terminal bool `toml:"terminal" yaml:"terminal"`
+	// Every is the interval, such as "30m", at which reminders are repeated
+	// for the rest of the day while habits are still due. Reminders are sent
+	// once a day if it is empty.
+	Every string `toml:"every" yaml:"every"`
+}
+
+// SMTPConfig holds the settings of the mail server that digests are sent
+// through.
+type SMTPConfig struct {
+	// Host is the host name of the mail server, such as "smtp.gmail.com".
+	Host string `toml:"host" yaml:"host"`
+	// Port is the port of the mail server. It defaults to 587.
+	Port int `toml:"port" yaml:"port"`
+	// Username is the user to authenticate as. No authentication is used if
+	// it is empty.
+	Username string `toml:"username" yaml:"username"`
+	// Password is the password to authenticate with. The HABIT_SMTP_PASSWORD
+	// environment variable takes precedence over it.
+	Password string `toml:"password" yaml:"password"`
+	// From is the address digests are sent from.
+	From string `toml:"from" yaml:"from"`
+	// To are the addresses digests are sent to.
+	To []string `toml:"to" yaml:"to"`
+}
+
+// configEnv is the environment variable holding the path of the config file,
+// overriding the default path.
+const configEnv = "HABIT_CONFIG"
+
+// configNames are the names of the files, within the habit directory of the
+// user's config directory, that are looked for in order when no config path is
+// given.
+var configNames = []string{"config.toml", "config.yaml", "config.yml"}
+
+// DefaultConfigPath returns the path of the user's config file, which is the
+// first of config.toml, config.yaml and config.yml that exists in the habit
+// directory of the user's config directory, such as ~/.config/habit on Linux.
+// An empty string is returned if none of them exist.
+func DefaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	for _, name := range configNames {
+		path := filepath.Join(dir, "habit", name)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// LoadConfig reads the Config from the file at the given path, which is parsed
+// as YAML if it has a '.yaml' or '.yml' extension and as TOML otherwise. An
+// empty path returns an empty Config. An error is returned if the file cannot
+// be read or parsed, has unknown keys, or holds invalid values.
+func LoadConfig(path string) (Config, error) {
+	var cfg Config
+	if path == "" {
+		return cfg, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, fmt.Errorf("reading config: %w", err)
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		err = dec.Decode(&cfg)
+		if errors.Is(err, io.EOF) {
+			err = nil
+		}
+	default:
+		var md toml.MetaData
+		md, err = toml.Decode(string(data), &cfg)
+		if err == nil {
+			if undecoded := md.Undecoded(); len(undecoded) > 0 {
+				err = fmt.Errorf("unknown key %q", undecoded[0].String())
+			}
+		}
+	}
+	if err != nil {
+		return Config{}, fmt.Errorf("parsing config %s: %w", path, err)
+	}
+	err = cfg.validate()
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	cfg.Store = expandHome(cfg.Store)
+	cfg.AuditLog = expandHome(cfg.AuditLog)
+	cfg.MoodLog = expandHome(cfg.MoodLog)
+	cfg.Messages = expandHome(cfg.Messages)
+	for name, p := range cfg.Profiles {
+		p.Store = expandHome(p.Store)
+		cfg.Profiles[name] = p
+	}
+	return cfg, nil
+}
+
+// validate returns an error if any of the Config's values are invalid.
+func (c Config) validate() error {
+	if c.DayStart < 0 || c.DayStart > 23 {
+		return fmt.Errorf("invalid day_start %d (want an hour from 0 to 23)", c.DayStart)
+	}
+	if c.Timezone != "" {
+		_, err := time.LoadLocation(c.Timezone)
+		if err != nil {
+			return fmt.Errorf("invalid timezone %q", c.Timezone)
+		}
+	}
+	if c.Locale != "" {
+		if _, ok := lookupLocale(c.Locale); !ok {
+			return fmt.Errorf("invalid locale %q (want one of %s)", c.Locale, strings.Join(Locales(), ", "))
+		}
+	}
+	switch c.Output {
+	case "", OutputText, OutputJSON, OutputTable:
+	default:
+		return fmt.Errorf("invalid output %q (want %s, %s or %s)", c.Output, OutputText, OutputJSON, OutputTable)
+	}
+	for _, value := range c.Webhooks {
+		_, err := ParseWebhook(value)
+		if err != nil {
+			return err
+		}
+	}
+	for name, p := range c.Profiles {
+		err := validateProfileName(name)
+		if err != nil {
+			return err
+		}
+		if p.Store == "" {
+			return fmt.Errorf("invalid profile %q: store is required", name)
+		}
+	}
+	names := map[string]bool{}
+	tokens := map[string]bool{}
+	for _, u := range c.Users {
+		switch {
+		case u.Name == "":
+			return errors.New("invalid user: name is required")
+		case u.Token == "":
+			return fmt.Errorf("invalid user %q: token is required", u.Name)
+		case names[u.Name]:
+			return fmt.Errorf("duplicate user %q", u.Name)
+		case tokens[u.Token]:
+			return fmt.Errorf("invalid user %q: token is already used by another user", u.Name)
+		}
+		names[u.Name] = true
+		tokens[u.Token] = true
+	}
+	if c.Reminder.At != "" {
+		_, err := ParseTimeOfDay(c.Reminder.At)
+		if err != nil {
+			return err
+		}
+	}
+	return c.SMTP.validate()
+}
+
+// validate returns an error if the SMTPConfig is set but incomplete.
+func (c SMTPConfig) validate() error {
+	if c.Host == "" && c.From == "" && len(c.To) == 0 {
+		return nil
+	}
+	switch {
+	case c.Host == "":
+		return errors.New("invalid smtp settings: host is required")
+	case c.From == "":
+		return errors.New("invalid smtp settings: from is required")
+	case len(c.To) == 0:
+		return errors.New("invalid smtp settings: at least one to address is required")
+	case c.Port < 0 || c.Port > 65535:
+		return fmt.Errorf("invalid smtp port %d", c.Port)
+	}
+	return nil
+}
+
+// userTokens returns the token of each of the Config's users by user name.
+func (c Config) userTokens() map[string]string {
+	tokens := make(map[string]string, len(c.Users))
+	for _, u := range c.Users {
+		tokens[u.Name] = u.Token
+	}
+	return tokens
+}
+
+// Location returns the location named by the Config's Timezone, or nil if no
+// timezone is set.
+func (c Config) Location() *time.Location {
+	if c.Timezone == "" {
+		return nil
+	}
+	loc, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return nil
+	}
+	return loc
+}
+
+// expandHome accepts a path and returns it with a leading '~' replaced by the
+// user's home directory.
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
+
+// configPath returns the path of the config file to load: the path given with
+// the -config flag if set, then the path in the HABIT_CONFIG environment
+// variable, then DefaultConfigPath.
+func configPath(flagValue string) string {
+	if flagValue != "" {
+		return flagValue
+	}
+	if path := os.Getenv(configEnv); path != "" {
+		return path
+	}
+	return DefaultConfigPath()
+}
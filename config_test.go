@@ -0,0 +1,142 @@
+package habit_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+func writeConfig(t *testing.T, name, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	err := os.WriteFile(path, []byte(data), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfig_ReadsTOMLAndYAMLConfigs(t *testing.T) {
+	t.Parallel()
+	want := habit.Config{
+		Store:    "/tmp/habits.json",
+		Backup:   true,
+		DayStart: 4,
+		Timezone: "Europe/London",
+		Output:   habit.OutputTable,
+		Webhooks: []string{"https://example.com/hook"},
+		Reminder: habit.ReminderConfig{At: "21:30", Terminal: true},
+	}
+	testCases := map[string]string{
+		"config.toml": `store = "/tmp/habits.json"
+backup = true
+day_start = 4
+timezone = "Europe/London"
+output = "table"
+webhooks = ["https://example.com/hook"]
+
+[reminder]
+at = "21:30"
+terminal = true
+`,
+		"config.yaml": `store: /tmp/habits.json
+backup: true
+day_start: 4
+timezone: Europe/London
+output: table
+webhooks:
+  - https://example.com/hook
+reminder:
+  at: "21:30"
+  terminal: true
+`,
+	}
+	for name, data := range testCases {
+		got, err := habit.LoadConfig(writeConfig(t, name, data))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !cmp.Equal(want, got) {
+			t.Errorf("%s: %s", name, cmp.Diff(want, got))
+		}
+	}
+}
+
+func TestLoadConfig_ReturnsEmptyConfigGivenEmptyPathOrFile(t *testing.T) {
+	t.Parallel()
+	for _, path := range []string{"", writeConfig(t, "config.yml", ""), writeConfig(t, "config.toml", "")} {
+		got, err := habit.LoadConfig(path)
+		if err != nil {
+			t.Fatalf("%q: %v", path, err)
+		}
+		if !cmp.Equal(habit.Config{}, got) {
+			t.Errorf("%q: want empty config, got %+v", path, got)
+		}
+	}
+}
+
+func TestLoadConfig_ExpandsHomeDirectoryInStorePath(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip(err)
+	}
+	got, err := habit.LoadConfig(writeConfig(t, "config.toml", `store = "~/habit.store"`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := filepath.Join(home, "habit.store")
+	if want != got.Store {
+		t.Errorf("want store %q, got %q", want, got.Store)
+	}
+}
+
+func TestLoadConfig_ReturnsErrorGivenInvalidConfig(t *testing.T) {
+	t.Parallel()
+	testCases := map[string]struct {
+		name, data, want string
+	}{
+		"missing file":       {name: "", want: "reading config"},
+		"invalid TOML":       {name: "config.toml", data: `store = `, want: "parsing config"},
+		"invalid YAML":       {name: "config.yaml", data: "store: [", want: "parsing config"},
+		"unknown TOML key":   {name: "config.toml", data: `colour = true`, want: `unknown key "colour"`},
+		"unknown YAML key":   {name: "config.yaml", data: "colour: true", want: "field colour not found"},
+		"invalid day":        {name: "config.toml", data: `day_start = 24`, want: "invalid day_start 24"},
+		"invalid timezone":   {name: "config.toml", data: `timezone = "Mars/Olympus"`, want: `invalid timezone "Mars/Olympus"`},
+		"invalid output":     {name: "config.toml", data: `output = "xml"`, want: `invalid output "xml"`},
+		"invalid reminder":   {name: "config.yaml", data: "reminder:\n  at: noon", want: "noon"},
+		"invalid webhook":    {name: "config.toml", data: `webhooks = ["not a url"]`, want: "webhook"},
+		"incomplete smtp":    {name: "config.toml", data: "[smtp]\nhost = \"smtp.example.com\"", want: "from is required"},
+		"user without token": {name: "config.toml", data: "[[users]]\nname = \"alice\"", want: `invalid user "alice": token is required`},
+		"duplicate user token": {name: "config.yaml", data: "users:\n- {name: alice, token: secret}\n- {name: bob, token: secret}",
+			want: `invalid user "bob": token is already used`},
+	}
+	for desc, tc := range testCases {
+		path := filepath.Join(t.TempDir(), "missing.toml")
+		if tc.name != "" {
+			path = writeConfig(t, tc.name, tc.data)
+		}
+		_, err := habit.LoadConfig(path)
+		if err == nil {
+			t.Errorf("%s: want error, got nil", desc)
+			continue
+		}
+		if !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: want error containing %q, got %q", desc, tc.want, err)
+		}
+	}
+}
+
+func TestConfig_LocationReturnsNilWithoutTimezone(t *testing.T) {
+	t.Parallel()
+	if loc := (habit.Config{}).Location(); loc != nil {
+		t.Errorf("want nil location, got %v", loc)
+	}
+	loc := habit.Config{Timezone: "America/New_York"}.Location()
+	if loc == nil || loc.String() != "America/New_York" {
+		t.Errorf("want America/New_York, got %v", loc)
+	}
+}
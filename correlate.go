@@ -0,0 +1,156 @@
+package habit
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// DefaultCorrelationWindow is the number of days before today over which
+// correlations between Habits are computed by default.
+const DefaultCorrelationWindow = 90
+
+// minCorrelationDays is the fewest days two Habits must both have been tracked
+// within the window for their correlation to be computed.
+const minCorrelationDays = 14
+
+// keystoneCorrelation is the coefficient from which two Habits count as done
+// together for picking the keystone habit.
+const keystoneCorrelation = 0.4
+
+// A Correlation is how closely the days on which two Habits were done match.
+type Correlation struct {
+	// Habit is the name of the first habit, which sorts before Other.
+	Habit string `json:"habit"`
+	// Other is the name of the second habit.
+	Other string `json:"other"`
+	// Coefficient is the correlation between the days on which the two
+	// habits were done, from -1 to 1: 1 if they were always done on the same
+	// days, -1 if never, and 0 if doing one says nothing about the other.
+	Coefficient float64 `json:"coefficient"`
+	// Days is the number of days compared: the days within the window since
+	// both habits were first done.
+	Days int `json:"days"`
+	// Together is the number of those days on which both habits were done.
+	Together int `json:"together"`
+}
+
+// correlationStrength returns a word describing the strength of a correlation
+// with the given coefficient.
+func correlationStrength(coefficient float64) string {
+	switch r := math.Abs(coefficient); {
+	case r >= 0.7:
+		return "strong"
+	case r >= keystoneCorrelation:
+		return "moderate"
+	case r >= 0.2:
+		return "weak"
+	}
+	return "no"
+}
+
+// Correlations returns the correlation between the days on which each pair of
+// active Habits was done over the given number of days before today, sorted by
+// the strength of the correlation, strongest first. Today is left out, since
+// the Habits still due today would otherwise look as if they were skipped.
+// Only the days since both Habits of a pair were first done are compared, and
+// pairs compared over fewer than 14 days, or of which either Habit was done on
+// all or none of the days compared, are left out. An error is returned if the
+// window is not positive.
+func (t *Tracker) Correlations(window int) ([]Correlation, error) {
+	if window < 1 {
+		return nil, fmt.Errorf("invalid correlation window %d (want at least 1 day)", window)
+	}
+	today := t.calendar.date(t.now())
+	first := today.AddDate(0, 0, -window)
+	habits := t.sortedHabits(false)
+	days := make([]map[time.Time]bool, len(habits))
+	starts := make([]time.Time, len(habits))
+	for i, hbt := range habits {
+		days[i] = map[time.Time]bool{}
+		for _, c := range hbt.History {
+			day := t.calendar.date(c.At)
+			days[i][day] = true
+			if starts[i].IsZero() || day.Before(starts[i]) {
+				starts[i] = day
+			}
+		}
+	}
+	correlations := []Correlation{}
+	for i := range habits {
+		for j := i + 1; j < len(habits); j++ {
+			if starts[i].IsZero() || starts[j].IsZero() {
+				continue
+			}
+			from := laterOf(first, laterOf(starts[i], starts[j]))
+			// n[a][b] counts the days on which the first habit was done if a
+			// is 1 and the second if b is 1.
+			var n [2][2]int
+			for day := from; day.Before(today); day = day.AddDate(0, 0, 1) {
+				n[b2i(days[i][day])][b2i(days[j][day])]++
+			}
+			total := n[0][0] + n[0][1] + n[1][0] + n[1][1]
+			product := float64(n[1][0]+n[1][1]) * float64(n[0][0]+n[0][1]) *
+				float64(n[0][1]+n[1][1]) * float64(n[0][0]+n[1][0])
+			if total < minCorrelationDays || product == 0 {
+				continue
+			}
+			correlations = append(correlations, Correlation{
+				Habit:       habits[i].Name,
+				Other:       habits[j].Name,
+				Coefficient: float64(n[1][1]*n[0][0]-n[1][0]*n[0][1]) / math.Sqrt(product),
+				Days:        total,
+				Together:    n[1][1],
+			})
+		}
+	}
+	sort.SliceStable(correlations, func(i, j int) bool {
+		return math.Abs(correlations[i].Coefficient) > math.Abs(correlations[j].Coefficient)
+	})
+	return correlations, nil
+}
+
+// b2i returns 1 for true and 0 for false.
+func b2i(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// PrintCorrelations writes the correlations between the Habits, as returned by
+// Correlations with the given window, to the Tracker's output, followed by the
+// keystone habit, the Habit at least moderately correlated with the most other
+// Habits, if it is correlated with at least 2. An error is returned if the
+// window is not positive.
+func (t *Tracker) PrintCorrelations(window int) error {
+	correlations, err := t.Correlations(window)
+	if err != nil {
+		return err
+	}
+	if len(correlations) == 0 {
+		fmt.Fprintf(t.output, "Not enough history to correlate your habits: track at least 2 habits on some but not all of %d days.\n",
+			minCorrelationDays)
+		return nil
+	}
+	linked := map[string]int{}
+	for _, c := range correlations {
+		fmt.Fprintf(t.output, "'%s' and '%s': %+.2f, %s correlation (done together on %d of %d days)\n",
+			c.Habit, c.Other, c.Coefficient, correlationStrength(c.Coefficient), c.Together, c.Days)
+		if c.Coefficient >= keystoneCorrelation {
+			linked[c.Habit]++
+			linked[c.Other]++
+		}
+	}
+	keystone := ""
+	for name, n := range linked {
+		if n > linked[keystone] || (n == linked[keystone] && name < keystone) {
+			keystone = name
+		}
+	}
+	if linked[keystone] >= 2 {
+		fmt.Fprintf(t.output, "Keystone habit: '%s', done together with %d other habits.\n", keystone, linked[keystone])
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package habit_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+)
+
+// februaryHistory returns completions at 20:00 UTC on each day of February 2024
+// for which done returns true.
+func februaryHistory(done func(day int) bool) []habit.Completion {
+	var history []habit.Completion
+	for day := 1; day <= 29; day++ {
+		if done(day) {
+			history = append(history, habit.Completion{At: time.Date(2024, time.February, day, 20, 0, 0, 0, time.UTC)})
+		}
+	}
+	return history
+}
+
+func TestTracker_PrintCorrelationsReportsPairsAndKeystoneHabit(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-03-01T09:00:00Z")
+	even := func(day int) bool { return day%2 == 0 }
+	store := &memStore{habits: map[string]habit.Habit{
+		"exercise":   {Name: "exercise", History: februaryHistory(even)},
+		"meditation": {Name: "meditation", History: februaryHistory(func(day int) bool { return !even(day) })},
+		"sleep":      {Name: "sleep", History: februaryHistory(even)},
+		"water":      {Name: "water", History: februaryHistory(func(day int) bool { return even(day) || day == 1 })},
+		"yoga":       {Name: "yoga", History: februaryHistory(func(day int) bool { return day > 20 })},
+	}}
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.PrintCorrelations(habit.DefaultCorrelationWindow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Yoga, started 9 days ago, has too little history to be correlated.
+	want := "'exercise' and 'meditation': -1.00, strong correlation (done together on 0 of 28 days)\n" +
+		"'exercise' and 'sleep': +1.00, strong correlation (done together on 14 of 28 days)\n" +
+		"'exercise' and 'water': +1.00, strong correlation (done together on 14 of 28 days)\n" +
+		"'meditation' and 'sleep': -1.00, strong correlation (done together on 0 of 28 days)\n" +
+		"'sleep' and 'water': +1.00, strong correlation (done together on 14 of 28 days)\n" +
+		"'meditation' and 'water': -0.93, strong correlation (done together on 1 of 29 days)\n" +
+		"Keystone habit: 'exercise', done together with 2 other habits.\n"
+	if got := output.String(); want != got {
+		t.Errorf("want output %q, got output %q", want, got)
+	}
+}
+
+func TestTracker_CorrelationsReturnsErrorForInvalidWindow(t *testing.T) {
+	t.Parallel()
+	tracker, err := habit.NewTracker(habit.WithStore(&memStore{habits: map[string]habit.Habit{}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = tracker.Correlations(0)
+	if err == nil {
+		t.Error("want an error for a window of 0 days")
+	}
+}
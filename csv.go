@@ -0,0 +1,383 @@
+package habit
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// csvHeader is the header row written by csvCodec. Only the name column is
+// required when decoding, and columns may appear in any order, so that files
+// produced by spreadsheets and other trackers can be imported.
+var csvHeader = []string{"name", "frequency", "reminder", "completed_at", "note", "frozen",
+	"tags", "target", "unit", "weekdays", "avoid", "archived", "freezes", "pauses"}
+
+// csvListSeparator separates the tags and the pauses of a Habit within their
+// columns.
+const csvListSeparator = ";"
+
+// csvCodec encodes habit data as CSV with one row per completion and its note.
+// A Habit that has never been done is written as a single row with an empty
+// completed_at column. The settings of a Habit, such as its tags, schedule and
+// pauses, are repeated on each of its rows, so that a file exported by habit
+// can be imported without losing them. Streaks are not written; they are
+// recomputed from the completions when decoding.
+type csvCodec struct {
+	// calendar determines the dates on which streaks are recomputed, and
+	// its location is the time zone in which dates without a time are read.
+	calendar calendar
+}
+
+// Encode writes the given habit data to w as CSV, sorted by habit name and
+// completion time.
+func (csvCodec) Encode(w io.Writer, data map[string]Habit) error {
+	names := make([]string, 0, len(data))
+	for name := range data {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	cw := csv.NewWriter(w)
+	err := cw.Write(csvHeader)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		hbt := data[name]
+		reminder := ""
+		if hbt.ReminderTime != nil {
+			reminder = hbt.ReminderTime.String()
+		}
+		row := []string{hbt.Name, csvFrequency(hbt.Frequency), reminder, "", "", "",
+			strings.Join(hbt.Tags, csvListSeparator), csvNumber(hbt.Target), hbt.Unit, csvWeekdays(hbt.Weekdays),
+			csvBool(hbt.Avoid), csvBool(hbt.Archived), csvNumber(float64(hbt.Freezes)), csvPauses(hbt.Pauses)}
+		if len(hbt.History) == 0 {
+			err = cw.Write(row)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		for _, c := range hbt.History {
+			row[3] = c.At.Format(time.RFC3339)
+			row[4] = c.Note
+			row[5] = csvBool(c.Frozen)
+			err = cw.Write(row)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// Decode reads CSV-encoded habit data from r into the given map. The
+// completed_at column accepts RFC3339 timestamps or YYYY-MM-DD dates, which are
+// taken as midnight in the location of the codec's calendar. The settings of a
+// Habit are taken from the last of its rows that has them.
+func (c csvCodec) Decode(r io.Reader, data *map[string]Habit) error {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		return fmt.Errorf("error reading CSV header: %w", err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[name] = i
+	}
+	if _, ok := columns["name"]; !ok {
+		return fmt.Errorf("CSV header %q has no name column", header)
+	}
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return record[i]
+	}
+	habits := map[string]Habit{}
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		line, _ := cr.FieldPos(0)
+		name := field(record, "name")
+		if name == "" {
+			return fmt.Errorf("line %d: missing habit name", line)
+		}
+		hbt, ok := habits[name]
+		if !ok {
+			hbt = Habit{Name: name}
+		}
+		if value := field(record, "frequency"); value != "" {
+			hbt.Frequency, err = ParseFrequency(value)
+			if err != nil {
+				return fmt.Errorf("line %d: %w", line, err)
+			}
+		}
+		if value := field(record, "reminder"); value != "" {
+			tod, err := ParseTimeOfDay(value)
+			if err != nil {
+				return fmt.Errorf("line %d: %w", line, err)
+			}
+			hbt.ReminderTime = &tod
+		}
+		err = decodeCSVSettings(&hbt, func(name string) string { return field(record, name) })
+		if err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		if value := field(record, "completed_at"); value != "" {
+			at, err := parseCSVTime(value, c.calendar.location)
+			if err != nil {
+				return fmt.Errorf("line %d: %w", line, err)
+			}
+			frozen, err := parseCSVBool("frozen", field(record, "frozen"))
+			if err != nil {
+				return fmt.Errorf("line %d: %w", line, err)
+			}
+			hbt.History = append(hbt.History, Completion{At: at, Note: field(record, "note"), Frozen: frozen})
+		}
+		habits[name] = hbt
+	}
+	for name, hbt := range habits {
+		sort.Slice(hbt.History, func(i, j int) bool {
+			return hbt.History[i].At.Before(hbt.History[j].At)
+		})
+		if len(hbt.History) > 0 {
+			hbt.CurrentStreak, hbt.LongestStreak = computeStreaks(hbt, c.calendar)
+			hbt.LastDone = hbt.History[len(hbt.History)-1].At
+		}
+		habits[name] = hbt
+	}
+	*data = habits
+	return nil
+}
+
+// csvFrequency returns the value written to the frequency column for the
+// given Frequency, which ParseFrequency accepts.
+func csvFrequency(f Frequency) string {
+	switch f.Days() {
+	case 1:
+		return "daily"
+	case 7:
+		return "weekly"
+	}
+	return strconv.Itoa(f.Days())
+}
+
+// decodeCSVSettings sets the settings of the given Habit found in the columns
+// of a row, whose values are returned by field, leaving those whose columns
+// are empty or missing unchanged. An error is returned if a value is invalid.
+func decodeCSVSettings(hbt *Habit, field func(name string) string) error {
+	var err error
+	if value := field("tags"); value != "" {
+		hbt.Tags = nil
+		for _, tag := range strings.Split(value, csvListSeparator) {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				hbt.Tags = append(hbt.Tags, tag)
+			}
+		}
+	}
+	if value := field("target"); value != "" {
+		hbt.Target, err = strconv.ParseFloat(value, 64)
+		if err != nil || hbt.Target < 0 {
+			return fmt.Errorf("invalid target %q", value)
+		}
+	}
+	if value := field("unit"); value != "" {
+		hbt.Unit = value
+	}
+	if value := field("weekdays"); value != "" {
+		hbt.Weekdays, err = ParseWeekdays(value)
+		if err != nil {
+			return err
+		}
+	}
+	if value := field("avoid"); value != "" {
+		hbt.Avoid, err = parseCSVBool("avoid", value)
+		if err != nil {
+			return err
+		}
+	}
+	if value := field("archived"); value != "" {
+		hbt.Archived, err = parseCSVBool("archived", value)
+		if err != nil {
+			return err
+		}
+	}
+	if value := field("freezes"); value != "" {
+		hbt.Freezes, err = strconv.Atoi(value)
+		if err != nil || hbt.Freezes < 0 {
+			return fmt.Errorf("invalid number of streak freezes %q", value)
+		}
+	}
+	if value := field("pauses"); value != "" {
+		hbt.Pauses, err = parseCSVPauses(value)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// csvNumber returns the given number as written to a CSV column, which is
+// empty for zero.
+func csvNumber(n float64) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
+
+// csvBool returns the given flag as written to a CSV column: "true", or empty
+// for false.
+func csvBool(b bool) string {
+	if !b {
+		return ""
+	}
+	return "true"
+}
+
+// parseCSVBool accepts the value of the CSV column with the given name, which
+// is true or false in any form strconv.ParseBool accepts, or empty for false.
+func parseCSVBool(column, value string) (bool, error) {
+	if value == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s value %q (want true or false)", column, value)
+	}
+	return b, nil
+}
+
+// csvWeekdays returns the given days of the week as written to the weekdays
+// column, such as "mon wed fri", which ParseWeekdays accepts.
+func csvWeekdays(weekdays []time.Weekday) string {
+	names := make([]string, len(weekdays))
+	for i, day := range weekdays {
+		names[i] = strings.ToLower(day.String()[:3])
+	}
+	return strings.Join(names, " ")
+}
+
+// csvPauses returns the given Pauses as written to the pauses column: the
+// RFC3339 start and end of each Pause separated by a slash, with nothing after
+// the slash for a Pause that lasts until the Habit is resumed, such as
+// "2024-02-01T20:00:00Z/2024-02-05T00:00:00Z;2024-03-01T08:00:00Z/".
+func csvPauses(pauses []Pause) string {
+	values := make([]string, len(pauses))
+	for i, p := range pauses {
+		values[i] = p.From.Format(time.RFC3339) + "/"
+		if !p.Until.IsZero() {
+			values[i] += p.Until.Format(time.RFC3339)
+		}
+	}
+	return strings.Join(values, csvListSeparator)
+}
+
+// parseCSVPauses accepts the value of the pauses column written by csvPauses
+// and returns the Pauses it holds.
+func parseCSVPauses(value string) ([]Pause, error) {
+	var pauses []Pause
+	for _, span := range strings.Split(value, csvListSeparator) {
+		from, until, ok := strings.Cut(strings.TrimSpace(span), "/")
+		if !ok {
+			return nil, fmt.Errorf("invalid pause %q (want <from>/<until> as RFC3339 timestamps)", span)
+		}
+		var p Pause
+		var err error
+		p.From, err = time.Parse(time.RFC3339, from)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pause %q (want <from>/<until> as RFC3339 timestamps)", span)
+		}
+		if until != "" {
+			p.Until, err = time.Parse(time.RFC3339, until)
+			if err != nil || !p.Until.After(p.From) {
+				return nil, fmt.Errorf("invalid pause %q (want <from>/<until> as RFC3339 timestamps)", span)
+			}
+		}
+		pauses = append(pauses, p)
+	}
+	return pauses, nil
+}
+
+// parseCSVTime accepts an RFC3339 timestamp or a YYYY-MM-DD date, which is
+// taken as midnight in the given location, and returns the corresponding time.
+func parseCSVTime(value string, loc *time.Location) (time.Time, error) {
+	at, err := time.Parse(time.RFC3339, value)
+	if err == nil {
+		return at, nil
+	}
+	at, err = time.ParseInLocation(time.DateOnly, value, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid completion time %q (want RFC3339 or YYYY-MM-DD)", value)
+	}
+	return at, nil
+}
+
+// A csvTable holds the records of a CSV file exported by another app, whose
+// columns are found by the names in its header row.
+type csvTable struct {
+	// columns holds the index of each column by its name in lower case.
+	columns map[string]int
+	// records are the rows after the header row.
+	records [][]string
+}
+
+// readCSVTable reads a CSV file with a header row from r. An error is returned
+// if the file cannot be read or has no header row.
+func readCSVTable(r io.Reader) (csvTable, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+	records, err := cr.ReadAll()
+	if err != nil {
+		return csvTable{}, err
+	}
+	if len(records) == 0 {
+		return csvTable{}, errors.New("missing CSV header")
+	}
+	table := csvTable{columns: map[string]int{}, records: records[1:]}
+	for i, name := range records[0] {
+		// Spreadsheets may start the file with a byte order mark.
+		name = strings.TrimPrefix(name, "\ufeff")
+		table.columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	return table, nil
+}
+
+// has reports whether the csvTable has any of the columns with the given names.
+func (t csvTable) has(names ...string) bool {
+	for _, name := range names {
+		if _, ok := t.columns[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// field returns the trimmed value of the first of the columns with the given
+// names that the csvTable has in the given record, or an empty string if it
+// has none of them.
+func (t csvTable) field(record []string, names ...string) string {
+	for _, name := range names {
+		if i, ok := t.columns[name]; ok {
+			if i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+	}
+	return ""
+}
@@ -0,0 +1,169 @@
+package habit_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTracker_ExportCSVWritesOneRowPerCompletion(t *testing.T) {
+	t.Parallel()
+	lastDone := time.Date(2024, time.February, 6, 13, 0, 0, 0, time.UTC)
+	store, err := habit.OpenStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{
+		Name:          "programming",
+		CurrentStreak: 2,
+		LongestStreak: 2,
+		LastDone:      lastDone,
+		History:       []habit.Completion{{At: lastDone.AddDate(0, 0, -1)}, {At: lastDone, Note: "5k in the rain, again"}},
+	})
+	store.Add(habit.Habit{Name: "reading, aloud", Frequency: 3})
+	tracker, err := habit.NewTracker(habit.WithStore(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	output := new(bytes.Buffer)
+	err = tracker.Export(output, habit.FormatCSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "name,frequency,reminder,completed_at,note,frozen,tags,target,unit,weekdays,avoid,archived,freezes,pauses\n" +
+		"programming,daily,,2024-02-05T13:00:00Z,,,,,,,,,,\n" +
+		"programming,daily,,2024-02-06T13:00:00Z,\"5k in the rain, again\",,,,,,,,,\n" +
+		"\"reading, aloud\",3,,,,,,,,,,,,\n"
+	got := output.String()
+	if want != got {
+		t.Errorf("want output %q, got output %q", want, got)
+	}
+}
+
+func TestTracker_ImportCSVKeepsSettingsOfExportedHabits(t *testing.T) {
+	t.Parallel()
+	day := func(d int) time.Time { return time.Date(2024, time.February, d, 9, 0, 0, 0, time.UTC) }
+	habits := []habit.Habit{
+		{
+			Name:          "running",
+			CurrentStreak: 2,
+			LongestStreak: 2,
+			LastDone:      day(7),
+			Frequency:     habit.Daily,
+			History:       []habit.Completion{{At: day(5)}, {At: day(7), Frozen: true}},
+			Freezes:       1,
+			Pauses:        []habit.Pause{{From: day(1), Until: day(3)}, {From: day(8)}},
+			Tags:          []string{"health", "outdoors"},
+			Weekdays:      []time.Weekday{time.Monday, time.Wednesday, time.Friday},
+			Target:        2.5,
+			Unit:          "km",
+		},
+		{Name: "smoking", Frequency: habit.Daily, Avoid: true, Archived: true},
+	}
+	source, err := habit.OpenStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, hbt := range habits {
+		source.Add(hbt)
+	}
+	exporter, err := habit.NewTracker(habit.WithStore(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	csv := new(bytes.Buffer)
+	err = exporter.Export(csv, habit.FormatCSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := habit.OpenStore(t.TempDir() + "/test.store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(new(bytes.Buffer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Import(csv, habit.FormatCSV, habit.MergeOverwrite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range habits {
+		got, ok := store.Get(want.Name)
+		if !ok {
+			t.Fatalf("expected habit '%s' to be in the store", want.Name)
+		}
+		if !cmp.Equal(want, got) {
+			t.Error(cmp.Diff(want, got))
+		}
+	}
+}
+
+func TestTracker_ImportCSVRecomputesStreaksFromCompletions(t *testing.T) {
+	t.Parallel()
+	input := "completed_at,name\n" +
+		"2024-02-06,programming\n" +
+		"2024-02-01,programming\n" +
+		"2024-02-05,programming\n"
+	store, err := habit.OpenStore(t.TempDir() + "/test.store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Import(strings.NewReader(input), habit.FormatCSV, habit.MergeLatest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	day := func(d int) time.Time { return time.Date(2024, time.February, d, 0, 0, 0, 0, time.UTC) }
+	want := habit.Habit{
+		Name:          "programming",
+		CurrentStreak: 2,
+		LongestStreak: 2,
+		LastDone:      day(6),
+		History:       []habit.Completion{{At: day(1)}, {At: day(5)}, {At: day(6)}},
+	}
+	got, ok := store.Get("programming")
+	if !ok {
+		t.Fatal("expected imported habit to be in the store")
+	}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+	wantOutput := "Imported 1 habit using the 'latest' merge strategy.\n"
+	if wantOutput != output.String() {
+		t.Errorf("want output %q, got output %q", wantOutput, output.String())
+	}
+}
+
+func TestTracker_ImportCSVReturnsErrorForInvalidRows(t *testing.T) {
+	t.Parallel()
+	testCases := map[string]string{
+		"missing name column": "habit,completed_at\nprogramming,2024-02-06\n",
+		"empty name":          "name,completed_at\n,2024-02-06\n",
+		"invalid time":        "name,completed_at\nprogramming,yesterday\n",
+		"invalid frequency":   "name,frequency\nprogramming,hourly\n",
+		"invalid reminder":    "name,reminder\nprogramming,25:00\n",
+	}
+	for name, input := range testCases {
+		store, err := habit.OpenStore(t.TempDir() + "/test.store")
+		if err != nil {
+			t.Fatal(err)
+		}
+		tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(new(bytes.Buffer)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = tracker.Import(strings.NewReader(input), habit.FormatCSV, habit.MergeLatest)
+		if err == nil {
+			t.Errorf("%s: expected an error importing invalid CSV", name)
+		}
+	}
+}
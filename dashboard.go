@@ -0,0 +1,39 @@
+package habit
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+	"strings"
+)
+
+// dashboardFiles holds the single-page web dashboard served by a Server, which
+// shows a card for every habit with its streak, a heatmap of its completions
+// and a button to mark it done, using the Server's REST API.
+//
+//go:embed dashboard
+var dashboardFiles embed.FS
+
+// serveDashboard serves the file of the web dashboard that the given request
+// asks for, such as the page itself at /, and reports whether the request was
+// for one. The files hold no habit data, so they are served without a token;
+// the page asks for one when the API requires it.
+func (s *Server) serveDashboard(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+	files, err := fs.Sub(dashboardFiles, "dashboard")
+	if err != nil {
+		return false
+	}
+	name := strings.TrimPrefix(r.URL.Path, "/")
+	if name == "" {
+		name = "index.html"
+	}
+	info, err := fs.Stat(files, name)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	http.FileServer(http.FS(files)).ServeHTTP(w, r)
+	return true
+}
@@ -0,0 +1,126 @@
+// The habit dashboard, which shows a card for every habit with its streak, a
+// heatmap of the last 26 weeks and a button to mark it done, using the REST
+// API of habit serve.
+"use strict";
+
+const weeks = 26;
+const tokenKey = "habit-token";
+
+const statusLine = document.getElementById("status");
+const habitsList = document.getElementById("habits");
+const cardTemplate = document.getElementById("card");
+const tokenButton = document.getElementById("token");
+
+// api sends a request to the given path of version 1 of the REST API with the
+// saved bearer token, asking for a token and retrying if the server rejects
+// the request.
+async function api(path, options = {}) {
+	const headers = { ...options.headers };
+	const token = localStorage.getItem(tokenKey);
+	if (token) {
+		headers.Authorization = "Bearer " + token;
+	}
+	const resp = await fetch("v1/" + path, { ...options, headers });
+	if (resp.status === 401) {
+		const entered = prompt("API token for this habit server:");
+		if (!entered) {
+			throw new Error("an API token is required");
+		}
+		localStorage.setItem(tokenKey, entered);
+		tokenButton.hidden = false;
+		return api(path, options);
+	}
+	const body = await resp.json();
+	if (!resp.ok) {
+		throw new Error(body.error || resp.statusText);
+	}
+	return body;
+}
+
+// dateKey returns the local calendar date of the given time as YYYY-MM-DD.
+function dateKey(date) {
+	const pad = (n) => String(n).padStart(2, "0");
+	return `${date.getFullYear()}-${pad(date.getMonth() + 1)}-${pad(date.getDate())}`;
+}
+
+// renderHeatmap fills the given element with a cell for every day of the last
+// weeks, in columns from Monday to Sunday, marking the days the habit was done.
+function renderHeatmap(element, history) {
+	const done = new Set((history || []).map((c) => dateKey(new Date(c.at))));
+	const today = new Date();
+	today.setHours(0, 0, 0, 0);
+	const start = new Date(today);
+	start.setDate(start.getDate() - ((today.getDay() + 6) % 7) - (weeks - 1) * 7);
+	element.replaceChildren();
+	for (let day = new Date(start); day.getTime() <= today.getTime() + 6 * 864e5; day.setDate(day.getDate() + 1)) {
+		const cell = document.createElement("span");
+		const key = dateKey(day);
+		if (day > today) {
+			cell.className = "future";
+		} else if (done.has(key)) {
+			cell.className = "done";
+		}
+		cell.title = key;
+		element.append(cell);
+	}
+}
+
+// renderCard returns the card of the habit with the given summary and full
+// details.
+function renderCard(summary, details) {
+	const card = cardTemplate.content.firstElementChild.cloneNode(true);
+	card.querySelector(".name").textContent = summary.name;
+	card.querySelector(".current").textContent = summary.current_streak;
+	card.querySelector(".unit").textContent = { daily: "day streak", weekly: "week streak" }[summary.frequency] || "streak";
+	const parts = [`longest ${summary.longest_streak}`, summary.frequency];
+	if (summary.target) {
+		parts.push(`${summary.amount || 0} of ${summary.target} ${summary.unit || ""}`.trim());
+	}
+	if (summary.tags) {
+		parts.push(summary.tags.map((tag) => "#" + tag).join(" "));
+	}
+	card.querySelector(".details").textContent = parts.join(" · ");
+	renderHeatmap(card.querySelector(".heatmap"), details.history);
+	if (summary.streak_active && !summary.done_this_period && summary.current_streak > 0) {
+		card.classList.add("at-risk");
+	}
+	const button = card.querySelector(".done");
+	if (summary.done_this_period) {
+		button.textContent = "Done";
+		button.disabled = true;
+	}
+	button.addEventListener("click", async () => {
+		button.disabled = true;
+		try {
+			const resp = await api(`habits/${encodeURIComponent(summary.name)}/track`, { method: "POST" });
+			statusLine.textContent = resp.message;
+			await load();
+		} catch (err) {
+			statusLine.textContent = err.message;
+			button.disabled = false;
+		}
+	});
+	return card;
+}
+
+// load fetches every habit and redraws the cards.
+async function load() {
+	try {
+		const summaries = await api("habits");
+		const details = await Promise.all(summaries.map((s) => api(`habits/${encodeURIComponent(s.name)}`)));
+		habitsList.replaceChildren(...summaries.map((s, i) => renderCard(s, details[i])));
+		if (summaries.length === 0) {
+			statusLine.textContent = "No habits yet. Track one with 'habit track <name>'.";
+		}
+	} catch (err) {
+		statusLine.textContent = err.message;
+	}
+}
+
+tokenButton.hidden = !localStorage.getItem(tokenKey);
+tokenButton.addEventListener("click", () => {
+	localStorage.removeItem(tokenKey);
+	load();
+});
+
+load();
@@ -0,0 +1,62 @@
+package habit
+
+import (
+	"fmt"
+	"time"
+)
+
+// SetDeadline sets the time of day by which the daily Habit with the given
+// name should be done each day, such as 22:00 for journaling, and saves the
+// store. A nil deadline removes the Habit's deadline. Once the deadline has
+// passed, a Habit that has not been done is overdue, although its streak is
+// only broken as usual. An error is returned if the Habit does not exist or is
+// not a daily Habit, or if the store cannot be saved.
+func (t *Tracker) SetDeadline(hbtName string, deadline *TimeOfDay) error {
+	hbt, ok := t.store.Get(hbtName)
+	if !ok {
+		return errHabitNotFound(hbtName)
+	}
+	if deadline != nil && hbt.Frequency.Days() != 1 {
+		return fmt.Errorf("habit '%s' is tracked %s; only daily habits can have a deadline", hbtName, hbt.Frequency)
+	}
+	hbt.Deadline = deadline
+	t.store.Add(hbt)
+	err := t.save()
+	if err != nil {
+		return err
+	}
+	if deadline == nil {
+		fmt.Fprintf(t.output, "The habit '%s' no longer has a deadline.\n", hbtName)
+		return nil
+	}
+	fmt.Fprintf(t.output, "The habit '%s' is now due by %s each day.\n", hbtName, deadline)
+	return nil
+}
+
+// deadlineOn returns the timestamp of the Habit's deadline on the calendar
+// date of the given timestamp in the given calendar. If days start later than
+// midnight, a deadline before the start of the day falls on the following
+// morning. The Habit must have a deadline.
+func (h Habit) deadlineOn(at time.Time, cal calendar) time.Time {
+	deadline := h.Deadline.On(cal.day(at))
+	if deadline.Before(cal.start(at)) {
+		deadline = h.Deadline.On(cal.day(at).AddDate(0, 0, 1))
+	}
+	return deadline
+}
+
+// overdue returns true if the Habit has a deadline that has passed by the
+// given timestamp without the Habit being done on that calendar date.
+func (h Habit) overdue(now time.Time, cal calendar) bool {
+	if h.Deadline == nil || h.Frequency.Days() != 1 || h.doneThisPeriod(now, cal) {
+		return false
+	}
+	return !now.Before(h.deadlineOn(now, cal))
+}
+
+// onTime returns true if the given timestamp, when the Habit was done, is no
+// later than the Habit's deadline on that calendar date. Habits without a
+// deadline are always done on time.
+func (h Habit) onTime(at time.Time, cal calendar) bool {
+	return h.Deadline == nil || !at.After(h.deadlineOn(at, cal))
+}
@@ -0,0 +1,120 @@
+package habit_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTracker_SetDeadlineSetsAndRemovesDeadline(t *testing.T) {
+	t.Parallel()
+	store := &memStore{habits: map[string]habit.Habit{
+		"journal": {Name: "journal"},
+		"laundry": {Name: "laundry", Frequency: habit.Weekly},
+	}}
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	deadline := habit.TimeOfDay{Hour: 22}
+	err = tracker.SetDeadline("journal", &deadline)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := store.habits["journal"].Deadline; !cmp.Equal(&deadline, got) {
+		t.Error(cmp.Diff(&deadline, got))
+	}
+	err = tracker.SetDeadline("journal", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := store.habits["journal"].Deadline; got != nil {
+		t.Errorf("want deadline removed, got %v", got)
+	}
+	err = tracker.SetDeadline("laundry", &deadline)
+	if err == nil {
+		t.Error("want error setting a deadline for a weekly habit, got nil")
+	}
+	err = tracker.SetDeadline("running", &deadline)
+	if err == nil {
+		t.Error("want error setting a deadline for a habit that does not exist, got nil")
+	}
+	want := "The habit 'journal' is now due by 22:00 each day.\n" +
+		"The habit 'journal' no longer has a deadline.\n"
+	if got := output.String(); want != got {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestTracker_PrintDueAndPrintTodayMarkHabitsPastDeadlineOverdue(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T21:00:00Z")
+	yesterday := time.Date(2024, time.February, 5, 21, 30, 0, 0, time.UTC)
+	store := &memStore{habits: map[string]habit.Habit{
+		"journal": {Name: "journal", LastDone: yesterday, Deadline: &habit.TimeOfDay{Hour: 22}},
+		"stretch": {Name: "stretch", LastDone: yesterday, Deadline: &habit.TimeOfDay{Hour: 20, Minute: 30}},
+	}}
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker.PrintDue()
+	if got := tracker.PrintToday(); got != 2 {
+		t.Errorf("want 2 habits due, got %d", got)
+	}
+	notifier := &recordingNotifier{}
+	err = tracker.RemindDue(notifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "'journal' is due by 22:00.\n" +
+		"'stretch' is overdue: it was due by 20:30.\n" +
+		"journal\n" +
+		"stretch (overdue)\n"
+	if got := output.String(); want != got {
+		t.Error(cmp.Diff(want, got))
+	}
+	wantMessages := []string{"You haven't done 'journal' or 'stretch' yet. Do them soon to keep your streaks going! " +
+		"'stretch' (due by 20:30) is overdue."}
+	if !cmp.Equal(wantMessages, notifier.messages) {
+		t.Error(cmp.Diff(wantMessages, notifier.messages))
+	}
+}
+
+func TestTracker_StatsReportsDaysDoneByDeadline(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T23:00:00Z")
+	var history []habit.Completion
+	for day, hour := range []int{21, 23, 20, 22} {
+		history = append(history, habit.Completion{At: time.Date(2024, time.February, 3+day, hour, 0, 0, 0, time.UTC)})
+	}
+	history = append(history, habit.Completion{At: time.Date(2024, time.February, 6, 22, 30, 0, 0, time.UTC)})
+	store := &memStore{habits: map[string]habit.Habit{
+		"journal": {
+			Name: "journal", CurrentStreak: 4, LongestStreak: 4, LastDone: history[len(history)-1].At,
+			History: history, Deadline: &habit.TimeOfDay{Hour: 22},
+		},
+	}}
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats, err := tracker.Stats("journal", 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats[0].OnTime != 3 || stats[0].OnTimeRate() != 75 {
+		t.Errorf("want 3 days (75%%) on time, got %d (%.0f%%)", stats[0].OnTime, stats[0].OnTimeRate())
+	}
+	err = tracker.PrintStats("journal", 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "  Done by 22:00 in 3 of those 4 days (75% on time).\n"; !bytes.Contains(output.Bytes(), []byte(want)) {
+		t.Errorf("want output to contain %q, got %q", want, output.String())
+	}
+}
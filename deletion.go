@@ -0,0 +1,160 @@
+package habit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// WithDeletionLog returns an option that makes a Tracker record when it
+// deletes, renames or undoes Habits out of its store in the file at the given
+// path, so that Sync deletes them on the other devices too instead of bringing
+// them back. The file is encoded like the habit data of a sync remote,
+// encrypted if the store is, and is emptied once the deletions are synced.
+func WithDeletionLog(path string) option {
+	return func(t *Tracker) error {
+		if path == "" {
+			return errors.New("deletion log path must be non-empty")
+		}
+		t.deletionLog = path
+		return nil
+	}
+}
+
+// syncJSONCodec encodes habit data as JSON like jsonCodec, along with when
+// Habits were deleted, for the habit data of sync remotes and deletion logs.
+type syncJSONCodec struct {
+	// deleted holds when each deleted Habit was deleted, by name. Encode
+	// writes it and Decode replaces it.
+	deleted *map[string]time.Time
+}
+
+// syncData is the habit data written by syncJSONCodec.
+type syncData struct {
+	Version int                  `json:"version"`
+	Habits  map[string]Habit     `json:"habits"`
+	Deleted map[string]time.Time `json:"deleted,omitempty"`
+}
+
+// Encode writes the given habit data and the deletions as JSON to w.
+func (c syncJSONCodec) Encode(w io.Writer, data map[string]Habit) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(syncData{Version: SchemaVersion, Habits: data, Deleted: *c.deleted})
+}
+
+// Decode reads JSON-encoded habit data from r into the given map like
+// jsonCodec, and the deletions recorded with it, if any.
+func (c syncJSONCodec) Decode(r io.Reader, data *map[string]Habit) error {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	err = jsonCodec{}.Decode(bytes.NewReader(raw), data)
+	if err != nil {
+		return err
+	}
+	*c.deleted = nil
+	var sd syncData
+	// Unversioned data maps habit names to Habits, and may fail to decode
+	// as syncData if it has habits named "version" or "deleted".
+	if json.Unmarshal(raw, &sd) == nil && sd.Version != 0 {
+		*c.deleted = sd.Deleted
+	}
+	return nil
+}
+
+// deletionCodec returns the codec of the habit data the given Store syncs with
+// a remote, which writes the given deletions along with the Habits and reads
+// them into it.
+func deletionCodec(s Store, deleted *map[string]time.Time) codec {
+	c := syncJSONCodec{deleted: deleted}
+	if ec, ok := syncCodec(s).(encryptedCodec); ok {
+		ec.inner = c
+		return ec
+	}
+	return c
+}
+
+// deletions returns when each Habit recorded in the Tracker's deletion log was
+// deleted, by name. It returns no deletions if the Tracker keeps no deletion
+// log or nothing was deleted since the last sync.
+func (t *Tracker) deletions() (map[string]time.Time, error) {
+	if t.deletionLog == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(t.deletionLog)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read deletion log: %w", err)
+	}
+	var deleted map[string]time.Time
+	err = deletionCodec(t.store, &deleted).Decode(bytes.NewReader(data), &map[string]Habit{})
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode deletion log %s: %w", t.deletionLog, err)
+	}
+	return deleted, nil
+}
+
+// recordDeletion records the Habit with the given name as deleted now in the
+// Tracker's deletion log, if it keeps one.
+func (t *Tracker) recordDeletion(name string) error {
+	if t.deletionLog == "" {
+		return nil
+	}
+	deleted, err := t.deletions()
+	if err != nil {
+		return err
+	}
+	if deleted == nil {
+		deleted = map[string]time.Time{}
+	}
+	deleted[name] = t.now()
+	var buf bytes.Buffer
+	err = deletionCodec(t.store, &deleted).Encode(&buf, map[string]Habit{})
+	if err != nil {
+		return err
+	}
+	dir, base := filepath.Split(t.deletionLog)
+	if dir == "" {
+		dir = "."
+	}
+	f, err := os.CreateTemp(dir, base+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("cannot write deletion log: %w", err)
+	}
+	defer os.Remove(f.Name())
+	_, err = f.Write(buf.Bytes())
+	if err == nil {
+		err = f.Close()
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), t.deletionLog)
+	}
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("cannot write deletion log: %w", err)
+	}
+	return nil
+}
+
+// clearDeletions empties the Tracker's deletion log once its deletions are
+// synced.
+func (t *Tracker) clearDeletions() error {
+	if t.deletionLog == "" {
+		return nil
+	}
+	err := os.Remove(t.deletionLog)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("cannot clear deletion log: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package habit
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultSMTPPort is the mail submission port used when an SMTPConfig has no
+// port.
+const defaultSMTPPort = 587
+
+// digestPeriod returns the first and last days of the last complete
+// ReportPeriod before the one containing the given day, such as the previous
+// Monday to Sunday for ReportWeek, along with a title describing it.
+func digestPeriod(period ReportPeriod, day time.Time) (first, last time.Time, title string) {
+	switch period {
+	case ReportWeek:
+		first = day.AddDate(0, 0, -weekdayIndex(day)-7)
+		last = first.AddDate(0, 0, 6)
+		title = "the week of " + first.Format("2 January 2006")
+	default:
+		first = day.AddDate(0, -1, 1-day.Day())
+		last = first.AddDate(0, 1, -1)
+		title = first.Format("January 2006")
+	}
+	return first, last, title
+}
+
+// digest returns the subject and body of a digest of every active Habit over
+// the last complete ReportPeriod before the one containing the given date.
+func (t *Tracker) digest(period ReportPeriod, date time.Time) (subject, body string) {
+	first, last, title := digestPeriod(period, t.calendar.day(date))
+	subject = "Your habit digest for " + title
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s\n\n", subject)
+	var habits []Habit
+	for _, hbt := range t.sortedHabits(false) {
+		if !hbt.Avoid && len(hbt.History) > 0 && !t.calendar.day(hbt.History[0].At).After(last) {
+			habits = append(habits, hbt)
+		}
+	}
+	if len(habits) < 1 {
+		fmt.Fprintln(&buf, "You weren't tracking any habits.")
+		return subject, buf.String()
+	}
+	start := t.calendar.start(first)
+	end := t.calendar.start(last.AddDate(0, 0, 1))
+	for _, hbt := range habits {
+		freq := hbt.Frequency
+		from := first
+		if created := t.calendar.day(hbt.History[0].At); created.After(from) {
+			from = created
+		}
+		periods := map[int]bool{}
+		for _, c := range hbt.History {
+			day := t.calendar.day(c.At)
+			if !day.Before(first) && !day.After(last) {
+				periods[freq.periodIndex(c.At, t.calendar)] = true
+			}
+		}
+		total := freq.periodIndex(last, t.calendar) - freq.periodIndex(from, t.calendar) + 1
+		done := len(periods)
+		fmt.Fprintf(&buf, "- %s: done %d of %d %s, missed %d; %s.\n", hbt.Name, done, total,
+			freq.unit(total), total-done, streakChange(hbt, start, end, t.calendar))
+	}
+	return subject, buf.String()
+}
+
+// streakChange describes how the given Habit's streak changed between the
+// given timestamps.
+func streakChange(hbt Habit, start, end time.Time, cal calendar) string {
+	before, _ := streakAt(hbt, start, cal)
+	after, last := streakAt(hbt, end, cal)
+	switch {
+	case before == after:
+		return fmt.Sprintf("streak unchanged at %d %s", after, hbt.Frequency.unit(after))
+	case after == 0:
+		return fmt.Sprintf("streak of %d %s broken", last, hbt.Frequency.unit(last))
+	case before == 0 || after < before:
+		return fmt.Sprintf("new streak of %d %s", after, hbt.Frequency.unit(after))
+	}
+	return fmt.Sprintf("streak up from %d to %d %s", before, after, hbt.Frequency.unit(after))
+}
+
+// streakAt returns the given Habit's streak at the given timestamp, which is
+// zero if the streak had already been broken by then, along with its streak as
+// of its last completion before the timestamp.
+func streakAt(hbt Habit, at time.Time, cal calendar) (streak, last int) {
+	streaks := completionStreaks(hbt, cal)
+	for i := len(hbt.History) - 1; i >= 0; i-- {
+		c := hbt.History[i]
+		if !c.At.Before(at) {
+			continue
+		}
+		if hbt.periodsBetween(c.At, at, cal) >= 1 {
+			return 0, streaks[i]
+		}
+		return streaks[i], streaks[i]
+	}
+	return 0, 0
+}
+
+// PrintDigest writes a digest of every active Habit over the last complete
+// ReportPeriod before the one containing the given date to the given Tracker's
+// output, such as last week's digest when run on a Monday. For each Habit the
+// digest lists how many periods of its frequency it was done and missed, and
+// how its streak changed. Archived Habits, Habits to avoid and Habits created
+// after the period are left out.
+func (t *Tracker) PrintDigest(period ReportPeriod, date time.Time) error {
+	_, body := t.digest(period, date)
+	_, err := fmt.Fprint(t.output, body)
+	if err != nil {
+		return fmt.Errorf("error writing digest: %w", err)
+	}
+	return nil
+}
+
+// SendDigest emails the digest written by PrintDigest through the mail server
+// described by the given SMTPConfig, and writes a confirmation to the given
+// Tracker's output. The server must support STARTTLS if a username is set,
+// unless it runs on the local machine. An error is returned if the config is
+// incomplete or the email cannot be sent.
+func (t *Tracker) SendDigest(period ReportPeriod, date time.Time, cfg SMTPConfig) error {
+	if cfg.Host == "" {
+		return errors.New("no SMTP server is configured; set [smtp] in the config file")
+	}
+	err := cfg.validate()
+	if err != nil {
+		return err
+	}
+	subject, body := t.digest(period, date)
+	port := cfg.Port
+	if port == 0 {
+		port = defaultSMTPPort
+	}
+	var auth smtp.Auth
+	if cfg.Username != "" {
+		auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+	}
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", cfg.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(cfg.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", subject)
+	fmt.Fprintf(&msg, "Date: %s\r\n", t.now().Format(time.RFC1123Z))
+	fmt.Fprint(&msg, "MIME-Version: 1.0\r\n")
+	fmt.Fprint(&msg, "Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	fmt.Fprint(&msg, strings.ReplaceAll(body, "\n", "\r\n"))
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(port))
+	err = smtp.SendMail(addr, auth, cfg.From, cfg.To, msg.Bytes())
+	if err != nil {
+		return fmt.Errorf("error sending digest: %w", err)
+	}
+	fmt.Fprintf(t.output, "Sent your habit digest to %s.\n", joinList(cfg.To, "and"))
+	return nil
+}
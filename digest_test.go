@@ -0,0 +1,185 @@
+package habit_test
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+// completions returns a completion on each of the given days of February
+// 2024, an hour earlier each day so that completions on consecutive days are
+// less than 24 hours apart.
+func completions(days ...int) []habit.Completion {
+	var history []habit.Completion
+	for _, d := range days {
+		history = append(history, habit.Completion{At: time.Date(2024, time.February, d, 20-d, 0, 0, 0, time.UTC)})
+	}
+	return history
+}
+
+// digestStore returns a store of habits tracked before and during the week of
+// 5 February 2024.
+func digestStore() *memStore {
+	habits := map[string]habit.Habit{
+		"programming": {Name: "programming", History: completions(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11)},
+		"reading":     {Name: "reading", History: completions(2, 3, 4, 5, 6)},
+		"writing":     {Name: "writing", History: completions(9, 11)},
+		"cooking":     {Name: "cooking", History: completions(12)},
+		"drawing":     {Name: "drawing", History: completions(5, 6), Archived: true},
+	}
+	for name, hbt := range habits {
+		hbt.LastDone = hbt.History[len(hbt.History)-1].At
+		habits[name] = hbt
+	}
+	return &memStore{habits: habits}
+}
+
+const wantWeekDigest = `Your habit digest for the week of 5 February 2024
+
+- programming: done 7 of 7 days, missed 0; streak up from 4 to 11 days.
+- reading: done 2 of 7 days, missed 5; streak of 5 days broken.
+- writing: done 2 of 3 days, missed 1; new streak of 1 day.
+`
+
+func TestTracker_PrintDigestSummarizesPreviousWeek(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-12T08:00:00Z")
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(digestStore()), habit.WithOutput(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.PrintDigest(habit.ReportWeek, habit.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := output.String(); wantWeekDigest != got {
+		t.Error(cmp.Diff(wantWeekDigest, got))
+	}
+}
+
+func TestTracker_PrintDigestSummarizesPreviousMonth(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-03-01T08:00:00Z")
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(digestStore()), habit.WithOutput(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.PrintDigest(habit.ReportMonth, habit.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `Your habit digest for February 2024
+
+- cooking: done 1 of 18 days, missed 17; streak unchanged at 0 days.
+- programming: done 11 of 29 days, missed 18; streak unchanged at 0 days.
+- reading: done 5 of 28 days, missed 23; streak unchanged at 0 days.
+- writing: done 2 of 21 days, missed 19; streak unchanged at 0 days.
+`
+	if got := output.String(); want != got {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+// smtpServer accepts a single SMTP session on a local port and sends the
+// message it receives on its channel.
+func smtpServer(t *testing.T) (port int, messages <-chan string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	ch := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		reply := func(line string) { fmt.Fprintf(conn, "%s\r\n", line) }
+		reply("220 localhost ESMTP")
+		var data strings.Builder
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			cmd := strings.ToUpper(strings.TrimSpace(line))
+			switch {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				reply("250 localhost")
+			case cmd == "DATA":
+				reply("354 go ahead")
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil || line == ".\r\n" {
+						break
+					}
+					data.WriteString(line)
+				}
+				ch <- data.String()
+				reply("250 ok")
+			case cmd == "QUIT":
+				reply("221 bye")
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+	}()
+	return ln.Addr().(*net.TCPAddr).Port, ch
+}
+
+func TestTracker_SendDigestEmailsDigest(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-12T08:00:00Z")
+	port, messages := smtpServer(t)
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(digestStore()), habit.WithOutput(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := habit.SMTPConfig{Host: "127.0.0.1", Port: port, From: "habit@example.com", To: []string{"me@example.com"}}
+	err = tracker.SendDigest(habit.ReportWeek, habit.Now(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := <-messages
+	for _, want := range []string{
+		"From: habit@example.com\r\n",
+		"To: me@example.com\r\n",
+		"Subject: Your habit digest for the week of 5 February 2024\r\n",
+		strings.ReplaceAll(wantWeekDigest, "\n", "\r\n"),
+	} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("want message to contain %q, got %q", want, msg)
+		}
+	}
+	want := "Sent your habit digest to me@example.com.\n"
+	if got := output.String(); want != got {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestTracker_SendDigestReturnsErrorWithoutSMTPServer(t *testing.T) {
+	t.Parallel()
+	tracker, err := habit.NewTracker(habit.WithStore(digestStore()), habit.WithOutput(new(bytes.Buffer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.SendDigest(habit.ReportWeek, time.Now(), habit.SMTPConfig{})
+	if err == nil {
+		t.Error("want error, got nil")
+	}
+	err = tracker.SendDigest(habit.ReportWeek, time.Now(), habit.SMTPConfig{Host: "localhost", Port: 25, To: []string{"me@example.com"}})
+	if err == nil || !strings.Contains(err.Error(), "from") {
+		t.Errorf("want error about missing from address, got %v", err)
+	}
+}
@@ -0,0 +1,138 @@
+package habit
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// A TimeOfDay represents a wall-clock time of day, such as the preferred time
+// to perform a Habit.
+type TimeOfDay struct {
+	// Hour is the hour of the day in the range [0, 23].
+	Hour int `json:"hour"`
+	// Minute is the minute of the hour in the range [0, 59].
+	Minute int `json:"minute"`
+}
+
+// ParseTimeOfDay accepts a time of day in 24-hour "HH:MM" format and returns
+// the corresponding TimeOfDay. An error is returned if the value cannot be
+// parsed.
+func ParseTimeOfDay(value string) (TimeOfDay, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return TimeOfDay{}, fmt.Errorf("invalid time of day %q (want HH:MM): %w", value, err)
+	}
+	return TimeOfDay{Hour: t.Hour(), Minute: t.Minute()}, nil
+}
+
+// String returns the TimeOfDay in 24-hour "HH:MM" format.
+func (tod TimeOfDay) String() string {
+	return fmt.Sprintf("%02d:%02d", tod.Hour, tod.Minute)
+}
+
+// On returns the timestamp of the TimeOfDay on the calendar date of the given
+// timestamp, in the same location.
+func (tod TimeOfDay) On(day time.Time) time.Time {
+	year, month, date := day.Date()
+	return time.Date(year, month, date, tod.Hour, tod.Minute, 0, 0, day.Location())
+}
+
+// SetReminder sets the preferred time of day to perform the Habit with the
+// given name and saves the store. An error is returned if the Habit does not
+// exist or the store cannot be saved.
+func (t *Tracker) SetReminder(hbtName string, at TimeOfDay) error {
+	hbt, ok := t.store.Get(hbtName)
+	if !ok {
+		return errHabitNotFound(hbtName)
+	}
+	hbt.ReminderTime = &at
+	t.store.Add(hbt)
+	err := t.save()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(t.output, "You'll be reminded to do '%s' at %s.\n", hbtName, at)
+	return nil
+}
+
+// Due returns the Habits that have not been done yet in their current period
+// and are neither paused, off their schedule today nor Habits to avoid,
+// ordered by reminder time. Habits without a reminder time are ordered last,
+// by name.
+func (t *Tracker) Due() []Habit {
+	now := t.now()
+	var due []Habit
+	for _, hbt := range t.sortedHabits(false) {
+		if !t.expected(hbt, now) || hbt.doneThisPeriod(now, t.calendar) {
+			continue
+		}
+		due = append(due, hbt)
+	}
+	sort.Slice(due, func(i, j int) bool {
+		ri, rj := due[i].ReminderTime, due[j].ReminderTime
+		switch {
+		case ri == nil && rj == nil:
+			return due[i].Name < due[j].Name
+		case ri == nil || rj == nil:
+			return rj == nil
+		case *ri != *rj:
+			return ri.On(now).Before(rj.On(now))
+		}
+		return due[i].Name < due[j].Name
+	})
+	return due
+}
+
+// expected returns true if the given Habit is expected to be done in its
+// period containing the given timestamp, that is, if it is neither paused,
+// off its schedule nor a Habit to avoid.
+func (t *Tracker) expected(hbt Habit, now time.Time) bool {
+	return !hbt.Avoid && !hbt.Paused(now) && hbt.scheduled(now, t.calendar)
+}
+
+// PrintDue writes the Habits that have not been done yet in their current
+// period to the given Tracker's output, flagging the ones whose reminder time
+// or deadline has already passed, and returns how many there are.
+func (t *Tracker) PrintDue() int {
+	due := t.Due()
+	if len(due) < 1 {
+		fmt.Fprintln(t.output, "You've done all of your habits today. Nice work!")
+		return 0
+	}
+	now := t.now()
+	for _, hbt := range due {
+		switch {
+		case hbt.overdue(now, t.calendar):
+			fmt.Fprintf(t.output, "'%s' is overdue: it was due by %s.\n", hbt.Name, hbt.Deadline)
+		case hbt.ReminderTime == nil && hbt.Deadline != nil:
+			fmt.Fprintf(t.output, "'%s' is due by %s.\n", hbt.Name, hbt.Deadline)
+		case hbt.ReminderTime == nil:
+			fmt.Fprintf(t.output, "'%s' is due today.\n", hbt.Name)
+		case now.Before(hbt.ReminderTime.On(now)):
+			fmt.Fprintf(t.output, "'%s' is due at %s.\n", hbt.Name, hbt.ReminderTime)
+		default:
+			fmt.Fprintf(t.output, "'%s' was due at %s. Do it soon to keep your streak going!\n",
+				hbt.Name, hbt.ReminderTime)
+		}
+	}
+	return len(due)
+}
+
+// PrintToday writes the name of each Habit returned by Due to the given
+// Tracker's output, one per line and in the same order, marking the ones past
+// their deadline as overdue, and returns how many there are. Nothing is
+// written once every Habit has been done, so that the output can be shown as
+// is in a shell prompt or status bar.
+func (t *Tracker) PrintToday() int {
+	due := t.Due()
+	now := t.now()
+	for _, hbt := range due {
+		if hbt.overdue(now, t.calendar) {
+			fmt.Fprintf(t.output, "%s (overdue)\n", hbt.Name)
+			continue
+		}
+		fmt.Fprintln(t.output, hbt.Name)
+	}
+	return len(due)
+}
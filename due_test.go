@@ -0,0 +1,104 @@
+package habit_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTracker_DueOrdersHabitsNotDoneTodayByReminderTime(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T12:00:00Z")
+	yesterday, err := time.Parse(time.RFC3339, "2024-02-05T09:00:00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := habit.OpenStore(t.TempDir() + "/test.store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{Name: "reading", LastDone: yesterday, ReminderTime: &habit.TimeOfDay{Hour: 21}})
+	store.Add(habit.Habit{Name: "running", LastDone: yesterday, ReminderTime: &habit.TimeOfDay{Hour: 7, Minute: 30}})
+	store.Add(habit.Habit{Name: "stretching", LastDone: yesterday})
+	store.Add(habit.Habit{Name: "journaling", LastDone: habit.Now(), ReminderTime: &habit.TimeOfDay{Hour: 6}})
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var gotNames []string
+	for _, hbt := range tracker.Due() {
+		gotNames = append(gotNames, hbt.Name)
+	}
+	wantNames := []string{"running", "reading", "stretching"}
+	if !cmp.Equal(wantNames, gotNames) {
+		t.Error(cmp.Diff(wantNames, gotNames))
+	}
+	if got := tracker.PrintDue(); got != 3 {
+		t.Errorf("want PrintDue to report 3 habits due, got %d", got)
+	}
+	wantOutput := "'running' was due at 07:30. Do it soon to keep your streak going!\n" +
+		"'reading' is due at 21:00.\n" +
+		"'stretching' is due today.\n"
+	gotOutput := output.String()
+	if wantOutput != gotOutput {
+		t.Errorf("want output %q, got output %q", wantOutput, gotOutput)
+	}
+}
+
+func TestTracker_SetReminderReturnsErrorForNonExistentHabit(t *testing.T) {
+	t.Parallel()
+	store, err := habit.OpenStore(t.TempDir() + "/test.store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker, err := habit.NewTracker(habit.WithStore(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.SetReminder("nonexistent", habit.TimeOfDay{Hour: 8})
+	if err == nil {
+		t.Error("expected an error when setting reminder for non-existent habit")
+	}
+}
+
+func TestParseTimeOfDayReturnsErrorForInvalidValue(t *testing.T) {
+	t.Parallel()
+	_, err := habit.ParseTimeOfDay("25:61")
+	if err == nil {
+		t.Error("expected an error when parsing invalid time of day")
+	}
+}
+
+func TestTracker_PrintTodayListsDueHabitNamesAndCountsThem(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T12:00:00Z")
+	yesterday := time.Date(2024, time.February, 5, 9, 0, 0, 0, time.UTC)
+	store := &memStore{habits: map[string]habit.Habit{
+		"reading":    {Name: "reading", LastDone: yesterday},
+		"journaling": {Name: "journaling", LastDone: habit.Now()},
+		"cycling":    {Name: "cycling", LastDone: yesterday, Frequency: habit.Weekly},
+		"smoking":    {Name: "smoking", LastDone: yesterday, Avoid: true},
+	}}
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tracker.PrintToday(); got != 1 {
+		t.Errorf("want 1 habit remaining, got %d", got)
+	}
+	want := "reading\n"
+	if got := output.String(); want != got {
+		t.Error(cmp.Diff(want, got))
+	}
+	store.habits["reading"] = habit.Habit{Name: "reading", LastDone: habit.Now()}
+	output.Reset()
+	if got := tracker.PrintToday(); got != 0 {
+		t.Errorf("want no habits remaining, got %d", got)
+	}
+	if got := output.String(); got != "" {
+		t.Errorf("want no output, got %q", got)
+	}
+}
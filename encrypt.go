@@ -0,0 +1,242 @@
+package habit
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// encryptedMagic begins every encrypted store file, followed by the version of
+// the encrypted file layout.
+const encryptedMagic = "HABITENC"
+
+// encryptedLayout is the version of the layout of encrypted store files: the
+// magic string, this version byte, the scrypt salt and the AES-GCM nonce,
+// followed by the sealed data of the inner codec. The header is authenticated
+// along with the data.
+const encryptedLayout byte = 1
+
+// Sizes of the parts of an encrypted store file's header.
+const (
+	saltSize   = 16
+	nonceSize  = 12
+	headerSize = len(encryptedMagic) + 1 + saltSize + nonceSize
+)
+
+// scrypt parameters used to derive a 256-bit AES key from a passphrase, as
+// recommended for interactive logins.
+const (
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	scryptKeyLen = 32
+)
+
+// errDecrypt is returned when an encrypted store cannot be decrypted.
+var errDecrypt = errors.New("cannot decrypt store: wrong passphrase or corrupted file")
+
+// errNotEncrypted is returned when the file of an encrypted store is not
+// encrypted and the store was not opened with WithPlaintextMigration.
+var errNotEncrypted = errors.New("store file is not encrypted")
+
+// OpenEncryptedStore opens the store file at the given path like OpenStore, but
+// encrypts the file at rest with AES-256-GCM using a key derived from the given
+// passphrase with scrypt, so that it can be synced safely through cloud drives.
+// The habit data is decrypted when the store is opened and encrypted again on
+// every Save, with a fresh salt and nonce. An existing unencrypted store file is
+// only read with WithPlaintextMigration, so that a file swapped for one that is
+// not encrypted is not trusted. An error is returned if the passphrase is
+// empty, if the file is not encrypted or cannot be decrypted with the
+// passphrase, or if the file cannot be opened or decoded.
+func OpenEncryptedStore(path string, key []byte, opts ...storeOption) (*FileStore, error) {
+	if len(key) == 0 {
+		return nil, errors.New("the store passphrase must not be empty")
+	}
+	var inner codec = gobCodec{}
+	if filepath.Ext(path) == ".json" {
+		inner = jsonCodec{}
+	}
+	return openStore(path, encryptedCodec{inner: inner, passphrase: key}, opts)
+}
+
+// WithPlaintextMigration returns a storeOption that lets a store opened with
+// OpenEncryptedStore read a store file that is not encrypted yet, so that an
+// existing store can be encrypted once by saving it, as Tracker.Migrate does.
+// It has no effect on other stores.
+func WithPlaintextMigration() storeOption {
+	return func(s *FileStore) {
+		if c, ok := s.codec.(encryptedCodec); ok {
+			c.plaintext = true
+			s.codec = c
+		}
+	}
+}
+
+// encryptedCodec encrypts the habit data encoded by an inner codec.
+type encryptedCodec struct {
+	inner      codec
+	passphrase []byte
+	// plaintext allows data that is not encrypted to be decoded with the
+	// inner codec directly, set by WithPlaintextMigration.
+	plaintext bool
+}
+
+// Encode encodes the given habit data with the inner codec and writes it to w
+// encrypted with a key derived from the passphrase and a random salt.
+func (c encryptedCodec) Encode(w io.Writer, data map[string]Habit) error {
+	var plain bytes.Buffer
+	err := c.inner.Encode(&plain, data)
+	if err != nil {
+		return err
+	}
+	header := make([]byte, headerSize)
+	copy(header, encryptedMagic)
+	header[len(encryptedMagic)] = encryptedLayout
+	salt := header[len(encryptedMagic)+1 : len(encryptedMagic)+1+saltSize]
+	nonce := header[len(encryptedMagic)+1+saltSize:]
+	_, err = rand.Read(header[len(encryptedMagic)+1:])
+	if err != nil {
+		return err
+	}
+	aead, err := c.aead(salt)
+	if err != nil {
+		return err
+	}
+	sealed := aead.Seal(nil, nonce, plain.Bytes(), header)
+	_, err = w.Write(append(header, sealed...))
+	return err
+}
+
+// Decode reads habit data encrypted by Encode from r, decrypts it and decodes
+// it with the inner codec into the given map. Data that is not encrypted is
+// decoded with the inner codec directly if the codec allows plaintext.
+func (c encryptedCodec) Decode(r io.Reader, data *map[string]Habit) error {
+	plain, err := c.decrypt(r)
+	if err != nil {
+		return err
+	}
+	return c.inner.Decode(bytes.NewReader(plain), data)
+}
+
+// decodeRaw reads habit data encrypted by Encode from r and decrypts it,
+// decoding it with the inner codec without migrating it.
+func (c encryptedCodec) decodeRaw(r io.Reader) (versionedData, error) {
+	inner, ok := c.inner.(rawDecoder)
+	if !ok {
+		return versionedData{}, errors.New("cannot decode unmigrated habit data")
+	}
+	plain, err := c.decrypt(r)
+	if err != nil {
+		return versionedData{}, err
+	}
+	return inner.decodeRaw(bytes.NewReader(plain))
+}
+
+// decrypt reads habit data encrypted by Encode from r and returns the data
+// encoded by the inner codec. Data that is not encrypted is returned as is if
+// the codec allows plaintext, and errNotEncrypted is returned otherwise.
+func (c encryptedCodec) decrypt(r io.Reader) ([]byte, error) {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if !isEncrypted(raw) {
+		if !c.plaintext {
+			return nil, errNotEncrypted
+		}
+		return raw, nil
+	}
+	if len(raw) < headerSize {
+		return nil, errDecrypt
+	}
+	if layout := raw[len(encryptedMagic)]; layout != encryptedLayout {
+		return nil, fmt.Errorf("unsupported encrypted store layout %d", layout)
+	}
+	header := raw[:headerSize]
+	salt := header[len(encryptedMagic)+1 : len(encryptedMagic)+1+saltSize]
+	nonce := header[len(encryptedMagic)+1+saltSize:]
+	aead, err := c.aead(salt)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := aead.Open(nil, nonce, raw[headerSize:], header)
+	if err != nil {
+		return nil, errDecrypt
+	}
+	return plain, nil
+}
+
+// isEncrypted reports whether the given data was encrypted by an
+// encryptedCodec.
+func isEncrypted(raw []byte) bool {
+	return bytes.HasPrefix(raw, []byte(encryptedMagic))
+}
+
+// unencrypted reports whether the store is encrypted but its file is not yet,
+// which is only read with WithPlaintextMigration.
+func (s *FileStore) unencrypted() bool {
+	if _, ok := s.codec.(encryptedCodec); !ok {
+		return false
+	}
+	raw, err := os.ReadFile(s.path)
+	return err == nil && !isEncrypted(raw)
+}
+
+// unencrypted reports whether the given Store is encrypted but its file is not
+// yet, so that saving it encrypts the file.
+func unencrypted(s Store) bool {
+	u, ok := s.(interface{ unencrypted() bool })
+	return ok && u.unencrypted()
+}
+
+// aead returns the AES-GCM cipher keyed with the key derived from the codec's
+// passphrase and the given salt.
+func (c encryptedCodec) aead(salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key(c.passphrase, salt, scryptN, scryptR, scryptP, scryptKeyLen)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// keyringService and keyringAccount identify the store passphrase in the
+// operating system's keyring.
+const (
+	keyringService = "habit"
+	keyringAccount = "store"
+)
+
+// KeyringPassphrase returns the store passphrase saved in the operating
+// system's keyring under the service "habit" and the account "store". It uses
+// the security command on macOS and secret-tool, from libsecret, elsewhere. An
+// error is returned if the command is missing or no passphrase is saved.
+func KeyringPassphrase() ([]byte, error) {
+	cmd := exec.Command("secret-tool", "lookup", "service", keyringService, "account", keyringAccount)
+	if runtime.GOOS == "darwin" {
+		cmd = exec.Command("security", "find-generic-password", "-s", keyringService, "-a", keyringAccount, "-w")
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("cannot read the store passphrase from the keyring with %s: %w", cmd.Args[0], err)
+	}
+	passphrase := strings.TrimRight(string(out), "\r\n")
+	if passphrase == "" {
+		return nil, errors.New("no store passphrase is saved in the keyring")
+	}
+	return []byte(passphrase), nil
+}
@@ -0,0 +1,125 @@
+package habit_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestOpenEncryptedStore_SaveEncryptsHabitsAtRest(t *testing.T) {
+	t.Parallel()
+	for _, name := range []string{"habits.store", "habits.json"} {
+		path := t.TempDir() + "/" + name
+		store, err := habit.OpenEncryptedStore(path, []byte("correct horse"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		store.Add(habit.Habit{Name: "programming", CurrentStreak: 2, LongestStreak: 2})
+		err = store.Save()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bytes.Contains(data, []byte("programming")) {
+			t.Errorf("%s: want encrypted file, got habit name in plain text", name)
+		}
+		store2, err := habit.OpenEncryptedStore(path, []byte("correct horse"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []habit.Habit{{Name: "programming", CurrentStreak: 2, LongestStreak: 2}}
+		if got := store2.All(); !cmp.Equal(want, got) {
+			t.Errorf("%s: %s", name, cmp.Diff(want, got))
+		}
+	}
+}
+
+func TestOpenEncryptedStoreReturnsErrorGivenWrongPassphrase(t *testing.T) {
+	t.Parallel()
+	path := t.TempDir() + "/habits.store"
+	store, err := habit.OpenEncryptedStore(path, []byte("correct horse"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{Name: "programming"})
+	err = store.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = habit.OpenEncryptedStore(path, []byte("battery staple"))
+	if err == nil {
+		t.Error("want error for wrong passphrase, got nil")
+	}
+	_, err = habit.OpenStore(path)
+	if err == nil {
+		t.Error("want error opening encrypted store without a passphrase, got nil")
+	}
+}
+
+func TestOpenEncryptedStoreReturnsErrorGivenEmptyPassphrase(t *testing.T) {
+	t.Parallel()
+	_, err := habit.OpenEncryptedStore(t.TempDir()+"/habits.store", nil)
+	if err == nil {
+		t.Error("want error for empty passphrase, got nil")
+	}
+}
+
+func TestOpenEncryptedStoreReturnsErrorForUnencryptedStore(t *testing.T) {
+	t.Parallel()
+	path := t.TempDir() + "/habits.json"
+	plain, err := habit.OpenStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain.Add(habit.Habit{Name: "programming"})
+	err = plain.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = habit.OpenEncryptedStore(path, []byte("correct horse"))
+	if err == nil {
+		t.Fatal("want error opening unencrypted store file as encrypted, got nil")
+	}
+	if !errors.Is(err, habit.ErrStoreCorrupt) {
+		t.Errorf("want ErrStoreCorrupt, got %v", err)
+	}
+}
+
+func TestOpenEncryptedStoreWithPlaintextMigrationReadsAndEncryptsUnencryptedStore(t *testing.T) {
+	t.Parallel()
+	path := t.TempDir() + "/habits.json"
+	plain, err := habit.OpenStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain.Add(habit.Habit{Name: "programming"})
+	err = plain.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := habit.OpenEncryptedStore(path, []byte("correct horse"), habit.WithPlaintextMigration())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := store.Get("programming"); !ok {
+		t.Fatal("expected habit 'programming' to be present in store")
+	}
+	err = store.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, []byte("programming")) {
+		t.Error("want store to be encrypted once saved, got habit name in plain text")
+	}
+}
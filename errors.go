@@ -0,0 +1,59 @@
+package habit
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors that library consumers and the CLI can test for with errors.Is to
+// tell kinds of failures apart. The errors returned by this package have more
+// specific messages, such as the name of the habit that does not exist.
+var (
+	// ErrHabitNotFound is returned when a named Habit does not exist.
+	ErrHabitNotFound = errors.New("habit does not exist")
+	// ErrFutureTimestamp is returned when a Habit cannot be tracked at a
+	// timestamp because it is in the future, or because the Habit was last
+	// done after it.
+	ErrFutureTimestamp = errors.New("timestamp is in the future")
+	// ErrStoreCorrupt is returned when the data in a store cannot be
+	// decoded.
+	ErrStoreCorrupt = errors.New("store is corrupt")
+	// ErrStoreLocked is returned when a store cannot be opened or changed
+	// because another habit process holds its lock.
+	ErrStoreLocked = errors.New("store is locked by another habit process")
+	// ErrRemoteChanged is returned by SyncRemote.Push when the data on the
+	// remote changed since it was last pulled, such as by another device
+	// syncing at the same time, so that pushing would overwrite it.
+	ErrRemoteChanged = errors.New("sync remote changed since it was pulled")
+)
+
+// A kindError is an error of one of the kinds above, with its own message.
+type kindError struct {
+	// kind is the sentinel error identifying the kind of error.
+	kind error
+	// err is the error describing what went wrong.
+	err error
+}
+
+// errorOf returns an error of the given kind, with a message formatted like
+// fmt.Errorf, so that errors.Is reports the error as both the given kind and
+// any error wrapped with %w.
+func errorOf(kind error, format string, args ...any) error {
+	return &kindError{kind: kind, err: fmt.Errorf(format, args...)}
+}
+
+// errHabitNotFound returns an ErrHabitNotFound error for the Habit with the
+// given name.
+func errHabitNotFound(name string) error {
+	return errorOf(ErrHabitNotFound, "habit '%s' does not exist", name)
+}
+
+// Error returns the message of the error.
+func (e *kindError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the kind of the error and the error it describes.
+func (e *kindError) Unwrap() []error {
+	return []error{e.kind, e.err}
+}
@@ -0,0 +1,62 @@
+package habit_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+)
+
+func TestTrackerErrorsAreHabitNotFoundOrFutureTimestamp(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T09:00:00Z")
+	lastDone := time.Date(2024, time.February, 6, 8, 0, 0, 0, time.UTC)
+	store := &memStore{habits: map[string]habit.Habit{"reading": {Name: "reading", LastDone: lastDone}}}
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(new(bytes.Buffer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"delete missing habit", tracker.Delete("running"), habit.ErrHabitNotFound},
+		{"tag missing habit", tracker.Tag("running", "health"), habit.ErrHabitNotFound},
+		{"track tomorrow", tracker.TrackAt("reading", lastDone.AddDate(0, 0, 1)), habit.ErrFutureTimestamp},
+	}
+	for _, tc := range tests {
+		if !errors.Is(tc.err, tc.want) {
+			t.Errorf("%s: want %v, got %v", tc.name, tc.want, tc.err)
+		}
+		if errors.Is(tc.err, habit.ErrStoreCorrupt) {
+			t.Errorf("%s: want an error other than ErrStoreCorrupt, got %v", tc.name, tc.err)
+		}
+	}
+	if want := "habit 'running' does not exist"; tests[0].err.Error() != want {
+		t.Errorf("want message %q, got %q", want, tests[0].err.Error())
+	}
+}
+
+func TestOpenStoreReturnsErrStoreCorruptForUndecodableData(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	err := os.WriteFile(dir+"/garbage.json", []byte("{not json"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = habit.OpenJSONStore(dir + "/garbage.json")
+	if !errors.Is(err, habit.ErrStoreCorrupt) {
+		t.Errorf("want ErrStoreCorrupt, got %v", err)
+	}
+	err = os.WriteFile(dir+"/newer.json", []byte(`{"version": 999, "habits": {}}`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = habit.OpenJSONStore(dir + "/newer.json")
+	if err == nil || errors.Is(err, habit.ErrStoreCorrupt) {
+		t.Errorf("want an error other than ErrStoreCorrupt for a newer schema version, got %v", err)
+	}
+}
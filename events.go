@@ -0,0 +1,96 @@
+package habit
+
+import (
+	"fmt"
+	"time"
+)
+
+// An EventType identifies something notable that happened to a Habit.
+type EventType string
+
+const (
+	// EventHabitCreated is emitted when a new Habit is tracked for the first
+	// time.
+	EventHabitCreated EventType = "habit_created"
+	// EventStreakMilestone is emitted when a Habit's streak reaches one of
+	// the streak milestones.
+	EventStreakMilestone EventType = "streak_milestone"
+	// EventStreakBroken is emitted when a Habit's streak is broken and starts
+	// over.
+	EventStreakBroken EventType = "streak_broken"
+	// EventGoalReached is emitted when a Habit's streak reaches the Habit's
+	// goal.
+	EventGoalReached EventType = "goal_reached"
+	// EventPointsAwarded is emitted when a Habit earns points, if points are
+	// awarded with WithGamification.
+	EventPointsAwarded EventType = "points_awarded"
+	// EventLevelUp is emitted when the points earned by all Habits reach a
+	// new level.
+	EventLevelUp EventType = "level_up"
+)
+
+// An Event describes something notable that happened to a Habit.
+type Event struct {
+	// Type identifies what happened.
+	Type EventType `json:"type"`
+	// Habit is the name of the habit.
+	Habit string `json:"habit"`
+	// Streak is the streak that was reached for an EventStreakMilestone or
+	// EventGoalReached, or that was broken for an EventStreakBroken.
+	Streak int `json:"streak,omitempty"`
+	// Points is the number of points earned for an EventPointsAwarded.
+	Points int `json:"points,omitempty"`
+	// Level is the level reached for an EventLevelUp.
+	Level int `json:"level,omitempty"`
+	// Frequency is how often the habit must be done.
+	Frequency Frequency `json:"frequency"`
+	// At is the timestamp when the event happened.
+	At time.Time `json:"at"`
+}
+
+// Message returns a human-readable description of the Event.
+func (e Event) Message() string {
+	switch e.Type {
+	case EventHabitCreated:
+		return fmt.Sprintf("Started a new habit '%s'.", e.Habit)
+	case EventStreakMilestone:
+		return fmt.Sprintf("Reached a %d-%s streak for '%s'!", e.Streak, e.Frequency.unit(1), e.Habit)
+	case EventStreakBroken:
+		return fmt.Sprintf("The %d-%s streak for '%s' was broken.", e.Streak, e.Frequency.unit(1), e.Habit)
+	case EventGoalReached:
+		return fmt.Sprintf("Reached the %d-%s goal for '%s'!", e.Streak, e.Frequency.unit(1), e.Habit)
+	case EventPointsAwarded:
+		return fmt.Sprintf("Earned %d points for '%s'.", e.Points, e.Habit)
+	case EventLevelUp:
+		return fmt.Sprintf("Reached level %d by doing '%s'!", e.Level, e.Habit)
+	}
+	return fmt.Sprintf("Event %s for '%s'.", e.Type, e.Habit)
+}
+
+// An EventHandler is called with each Event emitted by a Tracker. An error is
+// returned if the Event cannot be handled.
+type EventHandler func(Event) error
+
+// WithEventHandler returns an option that makes a Tracker call the given
+// EventHandler with each Event it emits. It may be given more than once.
+func WithEventHandler(handler EventHandler) option {
+	return func(t *Tracker) error {
+		if handler == nil {
+			return fmt.Errorf("nil event handler")
+		}
+		t.handlers = append(t.handlers, handler)
+		return nil
+	}
+}
+
+// emit calls each of the Tracker's EventHandlers with the given Event. Since
+// the change that caused the Event has already been saved, handler errors are
+// reported as warnings on the Tracker's output instead of being returned.
+func (t *Tracker) emit(e Event) {
+	for _, handle := range t.handlers {
+		err := handle(e)
+		if err != nil {
+			fmt.Fprintf(t.output, "Warning: could not handle %s event for '%s': %v\n", e.Type, e.Habit, err)
+		}
+	}
+}
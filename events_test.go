@@ -0,0 +1,108 @@
+package habit_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTracker_TrackEmitsEventsForNewHabitsMilestonesAndBrokenStreaks(t *testing.T) {
+	store, err := habit.OpenStore(t.TempDir() + "/test.store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lastDone := time.Date(2024, time.February, 5, 13, 0, 0, 0, time.UTC)
+	store.Add(habit.Habit{Name: "programming", CurrentStreak: 6, LongestStreak: 6, LastDone: lastDone})
+	store.Add(habit.Habit{Name: "reading", CurrentStreak: 12, LongestStreak: 12, LastDone: lastDone.AddDate(0, 0, -2)})
+	var got []habit.Event
+	tracker, err := habit.NewTracker(
+		habit.WithStore(store),
+		habit.WithOutput(new(bytes.Buffer)),
+		habit.WithEventHandler(func(e habit.Event) error {
+			got = append(got, e)
+			return nil
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	habit.Now = getTimeFunc(t, "2024-02-06T09:00:00Z")
+	for _, name := range []string{"running", "programming", "programming", "reading"} {
+		err = tracker.Track(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []habit.Event{
+		{Type: habit.EventHabitCreated, Habit: "running", At: habit.Now()},
+		{Type: habit.EventStreakMilestone, Habit: "programming", Streak: 7, At: habit.Now()},
+		{Type: habit.EventStreakBroken, Habit: "reading", Streak: 12, At: habit.Now()},
+	}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestTracker_TrackWritesWarningIfEventHandlerFails(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T09:00:00Z")
+	store, err := habit.OpenStore(t.TempDir() + "/test.store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(
+		habit.WithStore(store),
+		habit.WithOutput(output),
+		habit.WithEventHandler(func(habit.Event) error {
+			return errors.New("connection refused")
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Track("running")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Congratulations on starting your new habit 'running'! Don't forget to do it again.\n" +
+		"Warning: could not handle habit_created event for 'running': connection refused\n"
+	if want != output.String() {
+		t.Errorf("want output %q, got %q", want, output.String())
+	}
+	_, ok := store.Get("running")
+	if !ok {
+		t.Error("want habit to be tracked even though the event handler failed")
+	}
+}
+
+func TestEvent_MessageDescribesEvent(t *testing.T) {
+	testCases := map[string]struct {
+		event habit.Event
+		want  string
+	}{
+		"created": {
+			event: habit.Event{Type: habit.EventHabitCreated, Habit: "running"},
+			want:  "Started a new habit 'running'.",
+		},
+		"milestone": {
+			event: habit.Event{Type: habit.EventStreakMilestone, Habit: "running", Streak: 30},
+			want:  "Reached a 30-day streak for 'running'!",
+		},
+		"weekly broken": {
+			event: habit.Event{Type: habit.EventStreakBroken, Habit: "running", Streak: 4, Frequency: habit.Weekly},
+			want:  "The 4-week streak for 'running' was broken.",
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got := tc.event.Message()
+			if tc.want != got {
+				t.Errorf("want %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package habit
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// A Format is an encoding of habit data that can be exported and imported.
+type Format int
+
+const (
+	// FormatStore is the GOB encoding used by store files.
+	FormatStore Format = iota
+	// FormatJSON is the indented JSON encoding used by JSON store files.
+	FormatJSON
+	// FormatCSV is a spreadsheet-friendly encoding with one row per
+	// completion.
+	FormatCSV
+	// FormatICS is an iCalendar feed of completions and upcoming due dates,
+	// which can only be exported.
+	FormatICS
+	// FormatLoop is the SQLite backup or zip archive of CSV files exported by
+	// Loop Habit Tracker, the Android app, which can only be imported.
+	FormatLoop
+	// FormatStreaks is the CSV file exported by Streaks, the iOS app, which
+	// can only be imported.
+	FormatStreaks
+	// FormatHealth is a CSV file of the mindfulness sessions and workouts
+	// recorded by Apple Health, which can only be imported.
+	FormatHealth
+	// FormatJSONL is a JSON Lines event stream with one line per completion,
+	// which can only be exported.
+	FormatJSONL
+)
+
+// String returns the command-line name of the Format.
+func (f Format) String() string {
+	switch f {
+	case FormatStore:
+		return "store"
+	case FormatJSON:
+		return "json"
+	case FormatCSV:
+		return "csv"
+	case FormatICS:
+		return "ics"
+	case FormatLoop:
+		return "loop"
+	case FormatStreaks:
+		return "streaks"
+	case FormatHealth:
+		return "health"
+	case FormatJSONL:
+		return "jsonl"
+	}
+	return fmt.Sprintf("Format(%d)", int(f))
+}
+
+// ParseFormat accepts the command-line name of a format and returns the
+// corresponding Format. An error is returned if the name is not recognized.
+func ParseFormat(name string) (Format, error) {
+	for _, f := range []Format{FormatStore, FormatJSON, FormatCSV, FormatICS, FormatLoop, FormatStreaks, FormatHealth, FormatJSONL} {
+		if f.String() == strings.ToLower(name) {
+			return f, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown format %q (want store, json, jsonl, csv, ics, loop, streaks, or health)", name)
+}
+
+// FormatForPath returns the Format implied by the extension of the given file
+// path: FormatCSV for .csv files, FormatJSON for .json files, FormatJSONL for
+// .jsonl and .ndjson files, FormatICS for .ics files, FormatLoop for .zip files
+// and FormatStore otherwise.
+func FormatForPath(path string) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return FormatCSV
+	case ".json":
+		return FormatJSON
+	case ".jsonl", ".ndjson":
+		return FormatJSONL
+	case ".ics":
+		return FormatICS
+	case ".zip":
+		return FormatLoop
+	}
+	return FormatStore
+}
+
+// codec returns the codec that encodes and decodes habit data in the Format,
+// determining calendar dates with the given calendar where the Format needs
+// them.
+func (f Format) codec(cal calendar) codec {
+	switch f {
+	case FormatJSON:
+		return jsonCodec{}
+	case FormatCSV:
+		return csvCodec{calendar: cal}
+	case FormatICS:
+		return icsCodec{calendar: cal, now: Now}
+	case FormatLoop:
+		return loopCodec{calendar: cal}
+	case FormatStreaks:
+		return streaksCodec{calendar: cal}
+	case FormatHealth:
+		return healthCodec{calendar: cal}
+	case FormatJSONL:
+		return jsonlCodec{}
+	}
+	return checksumCodec{inner: gobCodec{}}
+}
+
+// Export writes every tracked Habit to w encoded in the given Format. An error
+// is returned if the habits cannot be written.
+func (t *Tracker) Export(w io.Writer, format Format) error {
+	data := map[string]Habit{}
+	for _, hbt := range t.store.All() {
+		data[hbt.Name] = hbt
+	}
+	err := format.codec(t.calendar).Encode(w, data)
+	if err != nil {
+		return fmt.Errorf("error exporting habits as %s: %w", format, err)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package habit_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseFormat(t *testing.T) {
+	t.Parallel()
+	for _, want := range []habit.Format{habit.FormatStore, habit.FormatJSON, habit.FormatCSV, habit.FormatLoop, habit.FormatStreaks, habit.FormatHealth, habit.FormatJSONL} {
+		got, err := habit.ParseFormat(want.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want != got {
+			t.Errorf("want format %s, got %s", want, got)
+		}
+	}
+	_, err := habit.ParseFormat("xml")
+	if err == nil {
+		t.Error("expected an error parsing an unknown format")
+	}
+}
+
+func TestFormatForPath(t *testing.T) {
+	t.Parallel()
+	testCases := map[string]habit.Format{
+		"habits.csv":   habit.FormatCSV,
+		"habits.CSV":   habit.FormatCSV,
+		"habits.json":  habit.FormatJSON,
+		"habit.store":  habit.FormatStore,
+		"loop.zip":     habit.FormatLoop,
+		"events.jsonl": habit.FormatJSONL,
+		"habits":       habit.FormatStore,
+	}
+	for path, want := range testCases {
+		got := habit.FormatForPath(path)
+		if want != got {
+			t.Errorf("%s: want format %s, got %s", path, want, got)
+		}
+	}
+}
+
+func TestTracker_ExportedHabitsCanBeImported(t *testing.T) {
+	t.Parallel()
+	lastDone := time.Date(2024, time.February, 6, 13, 0, 0, 0, time.UTC)
+	reminder := habit.TimeOfDay{Hour: 7, Minute: 30}
+	want := []habit.Habit{
+		{
+			Name:          "programming",
+			CurrentStreak: 2,
+			LongestStreak: 2,
+			LastDone:      lastDone,
+			Frequency:     habit.Daily,
+			ReminderTime:  &reminder,
+			History: []habit.Completion{
+				{At: lastDone.Add(-20 * time.Hour)},
+				{At: lastDone, Note: "5k in the rain"},
+			},
+		},
+		{Name: "reading", Frequency: habit.Weekly},
+	}
+	for _, format := range []habit.Format{habit.FormatStore, habit.FormatJSON, habit.FormatCSV} {
+		src, err := habit.OpenStore("")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, hbt := range want {
+			src.Add(hbt)
+		}
+		exporter, err := habit.NewTracker(habit.WithStore(src))
+		if err != nil {
+			t.Fatal(err)
+		}
+		buf := new(bytes.Buffer)
+		err = exporter.Export(buf, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dst, err := habit.OpenStore(t.TempDir() + "/test.store")
+		if err != nil {
+			t.Fatal(err)
+		}
+		importer, err := habit.NewTracker(habit.WithStore(dst), habit.WithOutput(new(bytes.Buffer)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = importer.Import(buf, format, habit.MergeOverwrite)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := dst.All()
+		if !cmp.Equal(want, got, habitSliceCmpOpt) {
+			t.Errorf("%s: %s", format, cmp.Diff(want, got, habitSliceCmpOpt))
+		}
+	}
+}
+
+func TestTracker_ExportJSONLWritesOneEventPerCompletion(t *testing.T) {
+	t.Parallel()
+	run := time.Date(2024, time.February, 6, 7, 0, 0, 0, time.UTC)
+	store := &memStore{habits: map[string]habit.Habit{
+		"running": {Name: "running", History: []habit.Completion{
+			{At: run.AddDate(0, 0, -1)}, {At: run, Note: "5k in the rain"},
+		}},
+		"water": {Name: "water", Target: 8, Unit: "glasses", History: []habit.Completion{
+			{At: run, Amount: 8.5},
+		}},
+		"reading": {Name: "reading"},
+	}}
+	tracker, err := habit.NewTracker(habit.WithStore(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := new(bytes.Buffer)
+	err = tracker.Export(buf, habit.FormatJSONL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"habit":"running","timestamp":"2024-02-05T07:00:00Z"}
+{"habit":"running","timestamp":"2024-02-06T07:00:00Z","note":"5k in the rain"}
+{"habit":"water","timestamp":"2024-02-06T07:00:00Z","amount":8.5}
+`
+	if want != buf.String() {
+		t.Error(cmp.Diff(want, buf.String()))
+	}
+}
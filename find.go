@@ -0,0 +1,96 @@
+package habit
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// How closely a habit name matches a query, from best to worst.
+const (
+	matchExact = iota
+	matchPrefix
+	matchSubstring
+	matchFuzzy
+	noMatch
+)
+
+// matchName returns how closely the given habit name matches the given query,
+// ignoring case: exactly, as a prefix, as a substring, or fuzzily, with the
+// characters of the query appearing in the name in order, such as "pgm" in
+// "programming".
+func matchName(name, query string) int {
+	name, query = strings.ToLower(name), strings.ToLower(query)
+	switch {
+	case name == query:
+		return matchExact
+	case strings.HasPrefix(name, query):
+		return matchPrefix
+	case strings.Contains(name, query):
+		return matchSubstring
+	}
+	rest := name
+	for _, r := range query {
+		i := strings.IndexRune(rest, r)
+		if i < 0 {
+			return noMatch
+		}
+		rest = rest[i+utf8.RuneLen(r):]
+	}
+	return matchFuzzy
+}
+
+// Find returns the tracked Habits whose names match the given query, ignoring
+// case, best matches first: an exact match, then names starting with the
+// query, then names containing it, then names containing its characters in
+// order, such as "programming" for "pgm". Matches that are equally good are
+// sorted by name. An empty query matches nothing.
+func (t *Tracker) Find(query string) []Habit {
+	if query == "" {
+		return nil
+	}
+	var matches []Habit
+	ranks := map[string]int{}
+	for _, hbt := range t.sortedHabits(false) {
+		rank := matchName(hbt.Name, query)
+		if rank == noMatch {
+			continue
+		}
+		ranks[hbt.Name] = rank
+		matches = append(matches, hbt)
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return ranks[matches[i].Name] < ranks[matches[j].Name]
+	})
+	return matches
+}
+
+// resolve returns the name of the Habit that the given query refers to: the
+// query itself if a Habit has exactly that name or no tracked Habit's name
+// starts with it, so that a new Habit can be started, or else the name of the
+// single Habit whose name starts with it. Names that only contain the query,
+// or its characters in order, are never resolved to, since resolve is used
+// to record changes and a loose match would change the wrong Habit. An error
+// listing the candidates is returned if several names start with the query.
+func (t *Tracker) resolve(query string) (string, error) {
+	if _, ok := t.store.Get(query); ok {
+		return query, nil
+	}
+	var candidates []string
+	for _, hbt := range t.Find(query) {
+		if matchName(hbt.Name, query) <= matchPrefix {
+			candidates = append(candidates, hbt.Name)
+		}
+	}
+	switch len(candidates) {
+	case 0:
+		return query, nil
+	case 1:
+		return candidates[0], nil
+	}
+	for i, name := range candidates {
+		candidates[i] = fmt.Sprintf("'%s'", name)
+	}
+	return "", fmt.Errorf("habit name '%s' is ambiguous: it could be %s", query, joinList(candidates, "or"))
+}
@@ -0,0 +1,57 @@
+package habit_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+func newFindTracker(t *testing.T, names ...string) *habit.Tracker {
+	t.Helper()
+	store := &memStore{habits: map[string]habit.Habit{}}
+	for _, name := range names {
+		store.habits[name] = habit.Habit{Name: name}
+	}
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(new(bytes.Buffer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tracker
+}
+
+func TestTracker_FindReturnsBestMatchesFirst(t *testing.T) {
+	t.Parallel()
+	tracker := newFindTracker(t, "programming", "Pro Reading", "deep programming", "gym", "pigment")
+	tests := map[string][]string{
+		"pro":         {"Pro Reading", "programming", "deep programming"},
+		"PROGRAMMING": {"programming", "deep programming"},
+		"pgm":         {"deep programming", "pigment", "programming"},
+		"swim":        nil,
+		"":            nil,
+	}
+	for query, want := range tests {
+		var got []string
+		for _, hbt := range tracker.Find(query) {
+			got = append(got, hbt.Name)
+		}
+		if !cmp.Equal(want, got) {
+			t.Errorf("%q: %s", query, cmp.Diff(want, got))
+		}
+	}
+}
+
+func TestTracker_FindSkipsArchivedHabits(t *testing.T) {
+	t.Parallel()
+	store := &memStore{habits: map[string]habit.Habit{
+		"programming": {Name: "programming", Archived: true},
+	}}
+	tracker, err := habit.NewTracker(habit.WithStore(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tracker.Find("prog"); len(got) != 0 {
+		t.Errorf("want no matches, got %v", got)
+	}
+}
@@ -0,0 +1,68 @@
+package habit
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// SetFreezes sets the number of streak freezes left for the Habit with the
+// given name and saves the store. Each streak freeze keeps the Habit's streak
+// going the next time exactly one period is missed. An error is returned if the
+// Habit does not exist, the count is negative, or the store cannot be saved.
+func (t *Tracker) SetFreezes(hbtName string, count int) error {
+	if count < 0 {
+		return errors.New("the number of streak freezes cannot be negative")
+	}
+	hbt, ok := t.store.Get(hbtName)
+	if !ok {
+		return errHabitNotFound(hbtName)
+	}
+	hbt.Freezes = count
+	t.store.Add(hbt)
+	err := t.save()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(t.output, "The habit '%s' now has %d %s.\n", hbtName, count, freezeUnit(count))
+	return nil
+}
+
+// missedPeriods returns the number of whole periods of the Habit's Frequency
+// between the period in which it was last done and the period containing the
+// given timestamp, as periodsBetween does.
+func (h Habit) missedPeriods(at time.Time, cal calendar) int {
+	return h.periodsBetween(h.LastDone, at, cal)
+}
+
+// periodsBetween returns the number of whole periods of the Habit's Frequency
+// between the periods containing the 2 given timestamps, with calendar dates
+// taken from the given calendar. For a Habit scheduled on certain days of the
+// week, only the scheduled dates are counted, and periods during which the
+// Habit was paused, even for part of the period, are not counted, so that
+// neither can break its streak.
+func (h Habit) periodsBetween(from, to time.Time, cal calendar) int {
+	if len(h.Weekdays) > 0 && h.Frequency.Days() == 1 {
+		return h.scheduledDaysBetween(from, to, cal)
+	}
+	first, last := h.Frequency.periodIndex(from, cal), h.Frequency.periodIndex(to, cal)
+	if len(h.Pauses) == 0 {
+		return max(0, last-first-1)
+	}
+	missed := 0
+	for i := first + 1; i < last; i++ {
+		if h.pausedTime(h.Frequency.periodStart(i, cal), h.Frequency.periodStart(i+1, cal)) == 0 {
+			missed++
+		}
+	}
+	return missed
+}
+
+// freezeUnit returns "streak freeze" pluralized according to the given count,
+// for use in output messages.
+func freezeUnit(count int) string {
+	if count == 1 {
+		return "streak freeze"
+	}
+	return "streak freezes"
+}
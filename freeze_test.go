@@ -0,0 +1,199 @@
+package habit_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTracker_TrackUsesStreakFreezeForExactlyOneMissedDay(t *testing.T) {
+	lastDone, err := time.Parse(time.RFC3339, "2024-02-04T13:00:00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := habit.OpenStore(t.TempDir() + "/test.store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{
+		Name:          "programming",
+		CurrentStreak: 3,
+		LongestStreak: 3,
+		LastDone:      lastDone,
+		History:       []habit.Completion{{At: lastDone}},
+		Freezes:       2,
+	})
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	habit.Now = getTimeFunc(t, "2024-02-06T09:00:00Z")
+	err = tracker.Track("programming")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantOutput := "You missed a day of 'programming', so a streak freeze kept your 4-day streak going. You have 1 streak freeze left.\n"
+	if wantOutput != output.String() {
+		t.Errorf("want output %q, got output %q", wantOutput, output.String())
+	}
+	got, ok := store.Get("programming")
+	if !ok {
+		t.Fatal("expected habit 'programming' to be present in store")
+	}
+	now := habit.Now()
+	want := habit.Habit{
+		Name:          "programming",
+		CurrentStreak: 4,
+		LongestStreak: 4,
+		LastDone:      now,
+		History:       []habit.Completion{{At: lastDone}, {At: now, Frozen: true}},
+		Freezes:       1,
+		Undo: &habit.UndoRecord{
+			Completion:    now,
+			CurrentStreak: 3,
+			LongestStreak: 3,
+			LastDone:      lastDone,
+			Freezes:       2,
+		},
+	}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestTracker_TrackDoesNotUseStreakFreezeForMoreThanOneMissedDay(t *testing.T) {
+	lastDone, err := time.Parse(time.RFC3339, "2024-02-03T13:00:00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := habit.OpenStore(t.TempDir() + "/test.store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{
+		Name:          "programming",
+		CurrentStreak: 3,
+		LongestStreak: 3,
+		LastDone:      lastDone,
+		Freezes:       2,
+	})
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(io.Discard))
+	if err != nil {
+		t.Fatal(err)
+	}
+	habit.Now = getTimeFunc(t, "2024-02-06T09:00:00Z")
+	err = tracker.Track("programming")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, ok := store.Get("programming")
+	if !ok {
+		t.Fatal("expected habit 'programming' to be present in store")
+	}
+	if got.CurrentStreak != 1 || got.Freezes != 2 {
+		t.Errorf("want streak 1 with 2 freezes left, got streak %d with %d freezes left", got.CurrentStreak, got.Freezes)
+	}
+}
+
+func TestTracker_UndoRestoresUsedStreakFreeze(t *testing.T) {
+	lastDone, err := time.Parse(time.RFC3339, "2024-02-04T13:00:00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := habit.OpenStore(t.TempDir() + "/test.store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{
+		Name:          "programming",
+		CurrentStreak: 3,
+		LongestStreak: 3,
+		LastDone:      lastDone,
+		History:       []habit.Completion{{At: lastDone}},
+		Freezes:       1,
+	})
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(io.Discard))
+	if err != nil {
+		t.Fatal(err)
+	}
+	habit.Now = getTimeFunc(t, "2024-02-06T09:00:00Z")
+	err = tracker.Track("programming")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Undo("programming")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, ok := store.Get("programming")
+	if !ok {
+		t.Fatal("expected habit 'programming' to be present in store")
+	}
+	if got.CurrentStreak != 3 || got.Freezes != 1 {
+		t.Errorf("want streak 3 with 1 freeze left, got streak %d with %d freezes left", got.CurrentStreak, got.Freezes)
+	}
+}
+
+func TestTracker_TrackAtKeepsFrozenStreakWhenBackdating(t *testing.T) {
+	first, err := time.Parse(time.RFC3339, "2024-02-01T13:00:00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	frozen, err := time.Parse(time.RFC3339, "2024-02-03T09:00:00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := habit.OpenStore(t.TempDir() + "/test.store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{
+		Name:          "programming",
+		CurrentStreak: 2,
+		LongestStreak: 2,
+		LastDone:      frozen,
+		History:       []habit.Completion{{At: first}, {At: frozen, Frozen: true}},
+	})
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(io.Discard))
+	if err != nil {
+		t.Fatal(err)
+	}
+	habit.Now = getTimeFunc(t, "2024-02-03T12:00:00Z")
+	err = tracker.TrackAt("programming", first.Add(-20*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, ok := store.Get("programming")
+	if !ok {
+		t.Fatal("expected habit 'programming' to be present in store")
+	}
+	if got.CurrentStreak != 3 {
+		t.Errorf("want streak 3, got %d", got.CurrentStreak)
+	}
+}
+
+func TestTracker_SetFreezesReturnsErrorForInvalidInput(t *testing.T) {
+	t.Parallel()
+	store, err := habit.OpenStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{Name: "programming"})
+	tracker, err := habit.NewTracker(habit.WithStore(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.SetFreezes("reading", 1)
+	if err == nil {
+		t.Error("expected an error setting streak freezes for a habit that does not exist")
+	}
+	err = tracker.SetFreezes("programming", -1)
+	if err == nil {
+		t.Error("expected an error setting a negative number of streak freezes")
+	}
+}
@@ -0,0 +1,129 @@
+package habit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A Frequency is the number of days in the period within which a Habit must be
+// done again to keep its streak going. Doing a Habit more than once within the
+// same calendar period does not extend its streak. The zero value is treated
+// as Daily so that Habits stored before frequencies were introduced remain
+// daily habits.
+type Frequency int
+
+const (
+	// Daily habits must be done every day.
+	Daily Frequency = 1
+	// Weekly habits must be done every 7 days.
+	Weekly Frequency = 7
+)
+
+// ParseFrequency accepts "daily", "weekly", or a positive number of days and
+// returns the corresponding Frequency. An error is returned if the value cannot
+// be parsed.
+func ParseFrequency(value string) (Frequency, error) {
+	switch strings.ToLower(value) {
+	case "daily":
+		return Daily, nil
+	case "weekly":
+		return Weekly, nil
+	}
+	days, err := strconv.Atoi(value)
+	if err != nil || days < 1 {
+		return 0, fmt.Errorf("invalid frequency %q (want daily, weekly, or a positive number of days)", value)
+	}
+	return Frequency(days), nil
+}
+
+// Days returns the number of days in the Frequency's period.
+func (f Frequency) Days() int {
+	if f < 1 {
+		return 1
+	}
+	return int(f)
+}
+
+// String returns a human-readable description of the Frequency.
+func (f Frequency) String() string {
+	switch f.Days() {
+	case 1:
+		return "daily"
+	case 7:
+		return "weekly"
+	}
+	return fmt.Sprintf("every %d days", f.Days())
+}
+
+// unit returns the name of the Frequency's period, pluralized according to
+// the given count, for use in output messages.
+func (f Frequency) unit(count int) string {
+	unit := "period"
+	switch f.Days() {
+	case 1:
+		unit = "day"
+	case 7:
+		unit = "week"
+	}
+	if count == 1 {
+		return unit
+	}
+	return unit + "s"
+}
+
+// current returns a description of the Frequency's current period, such as
+// "today" or "this week", for use in output messages.
+func (f Frequency) current() string {
+	switch f.Days() {
+	case 1:
+		return "today"
+	case 7:
+		return "this week"
+	}
+	return "this period"
+}
+
+// SetFrequency sets how often the Habit with the given name must be done to
+// keep its streak going and saves the store. An error is returned if the Habit
+// does not exist or the store cannot be saved.
+func (t *Tracker) SetFrequency(hbtName string, freq Frequency) error {
+	hbt, ok := t.store.Get(hbtName)
+	if !ok {
+		return errHabitNotFound(hbtName)
+	}
+	hbt.Frequency = freq
+	t.store.Add(hbt)
+	err := t.save()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(t.output, "The habit '%s' is now tracked %s.\n", hbtName, freq)
+	return nil
+}
+
+// periodAnchor is the date from which Frequency periods are counted. It is a
+// Monday so that weekly periods run from Monday to Sunday.
+var periodAnchor = time.Date(1970, time.January, 5, 0, 0, 0, 0, time.UTC)
+
+// periodIndex returns the index of the calendar period of the given Frequency
+// that contains the date of the given timestamp in the given calendar.
+func (f Frequency) periodIndex(t time.Time, cal calendar) int {
+	days := int(cal.date(t).Sub(periodAnchor).Hours() / 24)
+	index := days / f.Days()
+	if days < 0 && days%f.Days() != 0 {
+		index--
+	}
+	return index
+}
+
+// doneThisPeriod returns true if the Habit was done within the calendar period
+// of its Frequency that contains the given timestamp, with calendar dates
+// taken from the given calendar.
+func (h Habit) doneThisPeriod(now time.Time, cal calendar) bool {
+	if h.LastDone.IsZero() {
+		return false
+	}
+	return h.Frequency.periodIndex(h.LastDone, cal) == h.Frequency.periodIndex(now, cal)
+}
@@ -0,0 +1,145 @@
+package habit_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTracker_TrackComputesStreakRelativeToFrequency(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-10T12:00:00Z")
+	testCases := map[string]struct {
+		lastDone   string
+		frequency  habit.Frequency
+		wantStreak int
+		wantOutput string
+	}{
+		"Weekly habit done again 6 days later continues streak": {
+			lastDone:   "2024-02-04T13:00:00Z",
+			frequency:  habit.Weekly,
+			wantStreak: 4,
+			wantOutput: "Nice work: you've done the habit 'calling-grandma' for 4 weeks in a row now.\n",
+		},
+		"Weekly habit done again 3 days later does not modify streak": {
+			lastDone:   "2024-02-07T13:00:00Z",
+			frequency:  habit.Weekly,
+			wantStreak: 3,
+			wantOutput: "Way to go practicing your habit 'calling-grandma' more than once this week!\n",
+		},
+		"Weekly habit done again 8 days later in the next calendar week continues streak": {
+			lastDone:   "2024-02-02T12:00:00Z",
+			frequency:  habit.Weekly,
+			wantStreak: 4,
+			wantOutput: "Nice work: you've done the habit 'calling-grandma' for 4 weeks in a row now.\n",
+		},
+		"Weekly habit not done in the previous calendar week resets streak": {
+			lastDone:   "2024-01-28T12:00:00Z",
+			frequency:  habit.Weekly,
+			wantStreak: 1,
+			wantOutput: "You last did the habit 'calling-grandma' 13 days ago, so you're starting a new streak today. Good luck!\n",
+		},
+		"Every-3-days habit done again 2 days later continues streak": {
+			lastDone:   "2024-02-08T13:00:00Z",
+			frequency:  habit.Frequency(3),
+			wantStreak: 4,
+			wantOutput: "Nice work: you've done the habit 'calling-grandma' for 4 periods in a row now.\n",
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			lastDone, err := time.Parse(time.RFC3339, tc.lastDone)
+			if err != nil {
+				t.Fatal(err)
+			}
+			store, err := habit.OpenStore(t.TempDir() + "/test.store")
+			if err != nil {
+				t.Fatal(err)
+			}
+			store.Add(habit.Habit{
+				Name:          "calling-grandma",
+				CurrentStreak: 3,
+				LongestStreak: 3,
+				LastDone:      lastDone,
+				Frequency:     tc.frequency,
+			})
+			output := new(bytes.Buffer)
+			tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output))
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = tracker.Track("calling-grandma")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, ok := store.Get("calling-grandma")
+			if !ok {
+				t.Fatal("expected habit 'calling-grandma' to be present in store")
+			}
+			if tc.wantStreak != got.CurrentStreak {
+				t.Errorf("want streak %d, got streak %d", tc.wantStreak, got.CurrentStreak)
+			}
+			gotOutput := output.String()
+			if tc.wantOutput != gotOutput {
+				t.Errorf("want output %q, got output %q", tc.wantOutput, gotOutput)
+			}
+		})
+	}
+}
+
+func TestTracker_PrintSummaryDoesNotExpireWeeklyHabitWithinItsPeriod(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-10T12:00:00Z")
+	lastDone, err := time.Parse(time.RFC3339, "2024-02-06T12:00:00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := habit.OpenStore(t.TempDir() + "/test.store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{
+		Name:          "calling-grandma",
+		CurrentStreak: 2,
+		LongestStreak: 5,
+		LastDone:      lastDone,
+		Frequency:     habit.Weekly,
+	})
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.PrintSummary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "You are currently on a 2-week streak for 'calling-grandma'. Keep it going!\n"
+	got := output.String()
+	if want != got {
+		t.Errorf("want output %q, got output %q", want, got)
+	}
+}
+
+func TestParseFrequency(t *testing.T) {
+	t.Parallel()
+	testCases := map[string]habit.Frequency{
+		"daily":  habit.Daily,
+		"weekly": habit.Weekly,
+		"3":      habit.Frequency(3),
+	}
+	for input, want := range testCases {
+		got, err := habit.ParseFrequency(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !cmp.Equal(want, got) {
+			t.Errorf("ParseFrequency(%q): want %v, got %v", input, want, got)
+		}
+	}
+	_, err := habit.ParseFrequency("0")
+	if err == nil {
+		t.Error("expected an error when parsing non-positive frequency")
+	}
+}
@@ -0,0 +1,370 @@
+package habit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// A Problem is an inconsistency in a store found by Tracker.Check.
+type Problem struct {
+	// Habit is the name of the habit with the problem, or the key of a
+	// record that cannot be decoded. It is empty for a store file that cannot
+	// be decoded at all.
+	Habit string `json:"habit,omitempty"`
+	// Description describes the problem.
+	Description string `json:"description"`
+	// Fix describes how Check repairs the problem when asked to.
+	Fix string `json:"fix"`
+}
+
+// String returns a line describing the Problem and its fix.
+func (p Problem) String() string {
+	if p.Habit == "" {
+		return fmt.Sprintf("store: %s (fix: %s)", p.Description, p.Fix)
+	}
+	return fmt.Sprintf("habit '%s': %s (fix: %s)", p.Habit, p.Description, p.Fix)
+}
+
+// Check validates the Tracker's store and writes the Problems it finds to the
+// Tracker's output, one per line. It finds records of a store opened with
+// WithSalvage that could not be decoded, Habits whose names differ only in
+// case or surrounding spaces, completions, last completions and logged
+// amounts in the future, histories out of chronological order, and negative
+// streaks. If repair is true, the Problems are also fixed and the store is
+// saved: Habits with clashing names are merged like MergeStores does, future
+// data is removed, histories are sorted, streaks are recomputed from the
+// history, and undecodable records are moved to a quarantine file next to the
+// store file so that they can be recovered by hand. The Problems found are
+// returned. An error is returned if the repairs cannot be saved.
+func (t *Tracker) Check(repair bool) ([]Problem, error) {
+	var problems []Problem
+	damage := damagedRecords(t.store)
+	for _, d := range damage {
+		description := fmt.Sprintf("the record cannot be decoded: %v", d.err)
+		if d.key == "" {
+			description = fmt.Sprintf("the store file cannot be decoded: %v", d.err)
+		}
+		problems = append(problems, Problem{Habit: d.key, Description: description, Fix: d.fix})
+	}
+	habits := t.store.All()
+	sort.Slice(habits, func(i, j int) bool {
+		return habits[i].Name < habits[j].Name
+	})
+	problems = append(problems, t.checkNames(&habits, repair)...)
+	now := t.now()
+	for _, hbt := range habits {
+		found := t.checkHabit(&hbt, now)
+		if len(found) > 0 && repair {
+			t.store.Add(hbt)
+		}
+		problems = append(problems, found...)
+	}
+	for _, p := range problems {
+		fmt.Fprintln(t.output, p)
+	}
+	if len(problems) == 0 {
+		fmt.Fprintf(t.output, "No problems found in %d %s.\n", len(habits), plural(len(habits), "habit"))
+		return nil, nil
+	}
+	if !repair {
+		fmt.Fprintf(t.output, "Found %d %s.\n", len(problems), plural(len(problems), "problem"))
+		return problems, nil
+	}
+	if len(damage) > 0 {
+		path, err := quarantine(t.store)
+		if err != nil {
+			return problems, err
+		}
+		fmt.Fprintf(t.output, "Moved %d damaged %s to %s.\n", len(damage), plural(len(damage), "record"), path)
+	}
+	err := t.save()
+	if err != nil {
+		return problems, err
+	}
+	fmt.Fprintf(t.output, "Repaired %d %s.\n", len(problems), plural(len(problems), "problem"))
+	return problems, nil
+}
+
+// checkNames returns a Problem for every group of the given Habits, sorted by
+// name, whose names are the same apart from case and surrounding spaces. If
+// repair is true, each group is merged into a single Habit, named like the one
+// done most recently, both in the store and in the given Habits.
+func (t *Tracker) checkNames(habits *[]Habit, repair bool) []Problem {
+	groups := map[string][]Habit{}
+	for _, hbt := range *habits {
+		key := foldName(hbt.Name)
+		groups[key] = append(groups[key], hbt)
+	}
+	var problems []Problem
+	var checked []Habit
+	for _, hbt := range *habits {
+		group := groups[foldName(hbt.Name)]
+		if len(group) == 1 || !repair && hbt.Name != group[0].Name {
+			checked = append(checked, hbt)
+			continue
+		}
+		if hbt.Name != group[0].Name {
+			// The group was merged at its first Habit.
+			continue
+		}
+		merged := group[0]
+		var others []string
+		for _, other := range group[1:] {
+			merged = mergeHabits(merged, other, t.calendar)
+			others = append(others, fmt.Sprintf("'%s'", other.Name))
+		}
+		problems = append(problems, Problem{
+			Habit:       hbt.Name,
+			Description: fmt.Sprintf("the name clashes with %s", joinList(others, "and")),
+			Fix:         fmt.Sprintf("merge them into '%s'", merged.Name),
+		})
+		if !repair {
+			checked = append(checked, hbt)
+			continue
+		}
+		for _, other := range group {
+			t.store.Delete(other.Name)
+		}
+		t.store.Add(merged)
+		checked = append(checked, merged)
+	}
+	*habits = checked
+	return problems
+}
+
+// foldName returns the given habit name without case and surrounding spaces,
+// so that names that look alike compare equal.
+func foldName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// checkHabit returns the Problems with the data of the given Habit as of the
+// given timestamp, fixing them in the Habit.
+func (t *Tracker) checkHabit(hbt *Habit, now time.Time) []Problem {
+	var problems []Problem
+	add := func(description, fix string) {
+		problems = append(problems, Problem{Habit: hbt.Name, Description: description, Fix: fix})
+	}
+	hbt.History = slices.Clone(hbt.History)
+	recompute := false
+	if !sort.SliceIsSorted(hbt.History, func(i, j int) bool {
+		return hbt.History[i].At.Before(hbt.History[j].At)
+	}) {
+		add("the history is out of chronological order", "sort it and recompute the streaks")
+		sort.SliceStable(hbt.History, func(i, j int) bool {
+			return hbt.History[i].At.Before(hbt.History[j].At)
+		})
+		recompute = true
+	}
+	future := 0
+	for _, c := range hbt.History {
+		if c.At.After(now) {
+			future++
+		}
+	}
+	if future > 0 {
+		verb := "are"
+		if future == 1 {
+			verb = "is"
+		}
+		add(fmt.Sprintf("%d %s %s in the future", future, plural(future, "completion"), verb),
+			"remove them and recompute the streaks")
+		hbt.History = hbt.History[:len(hbt.History)-future]
+		hbt.Undo = nil
+		recompute = true
+	}
+	if hbt.LastDone.After(now) {
+		add("the last completion is in the future", "set it to the last completion in the history")
+	}
+	if hbt.LastDone.After(now) || recompute {
+		hbt.LastDone = time.Time{}
+		if len(hbt.History) > 0 {
+			hbt.LastDone = hbt.History[len(hbt.History)-1].At
+		}
+	}
+	if hbt.AmountAt.After(now) {
+		add("the amount was logged in the future", "reset the amount")
+		hbt.Amount, hbt.AmountAt, hbt.AmountIDs = 0, time.Time{}, nil
+	}
+	if hbt.CurrentStreak < 0 || hbt.LongestStreak < 0 {
+		add("a streak is negative", "recompute the streaks")
+		hbt.CurrentStreak, hbt.LongestStreak = max(hbt.CurrentStreak, 0), max(hbt.LongestStreak, 0)
+		recompute = true
+	}
+	if recompute && !hbt.Avoid {
+		hbt.CurrentStreak, hbt.LongestStreak = t.recompute(*hbt)
+	}
+	return problems
+}
+
+// A damagedRecord is data that a store opened with WithSalvage could not
+// decode.
+type damagedRecord struct {
+	// key is the key of the habit record, or empty if the whole store file
+	// could not be decoded.
+	key string
+	// data is the undecoded data.
+	data []byte
+	// err is why the data could not be decoded.
+	err error
+	// fix describes what quarantining the data leaves in the store.
+	fix string
+}
+
+// damagedRecords returns the data that the given Store, if it was opened with
+// WithSalvage, could not decode.
+func damagedRecords(s Store) []damagedRecord {
+	d, ok := s.(interface{ damaged() []damagedRecord })
+	if !ok {
+		return nil
+	}
+	return d.damaged()
+}
+
+// quarantine moves the data that the given Store could not decode to a
+// quarantine file and returns the file's path. An error is returned if the
+// Store cannot quarantine data or the file cannot be written.
+func quarantine(s Store) (string, error) {
+	q, ok := s.(interface{ quarantine() (string, error) })
+	if !ok {
+		return "", errors.New("cannot quarantine the damaged records of this store")
+	}
+	return q.quarantine()
+}
+
+// salvageable reports whether the store's codec can be salvaged. Encrypted
+// data is not, since failing to decrypt it usually means the passphrase is
+// wrong.
+func (s *FileStore) salvageable() bool {
+	switch s.codec.(type) {
+	case jsonCodec, gobCodec, checksumCodec:
+		return true
+	}
+	return false
+}
+
+// salvageData loads the habit records of the given store file data, which
+// failed to decode with the given error, that can be decoded on their own and
+// sets the rest aside. Only the records of JSON data can be decoded on their
+// own; other data is set aside as a whole, keeping its habits if it only fails
+// its checksum and otherwise leaving the store empty.
+func (s *FileStore) salvageData(raw []byte, decodeErr error) error {
+	s.data = map[string]Habit{}
+	if _, ok := s.codec.(jsonCodec); ok {
+		if version, records, ok := jsonRecords(raw); ok {
+			habits := map[string]Habit{}
+			for key, record := range records {
+				var hbt Habit
+				err := json.Unmarshal(record, &hbt)
+				if err != nil {
+					s.damage = append(s.damage, damagedRecord{key: key, data: record, err: err,
+						fix: "move it to a quarantine file"})
+					continue
+				}
+				habits[key] = hbt
+			}
+			sort.Slice(s.damage, func(i, j int) bool {
+				return s.damage[i].key < s.damage[j].key
+			})
+			err := decodeVersioned(versionedData{Version: version, Habits: habits}, &s.data)
+			if err != nil {
+				return fmt.Errorf("error decoding store data: %w", err)
+			}
+			return nil
+		}
+	}
+	if c, ok := s.codec.(checksumCodec); ok {
+		// Data that does not match its checksum may still decode.
+		data, _, _ := splitChecksum(raw)
+		err := c.inner.Decode(bytes.NewReader(data), &s.data)
+		if err == nil {
+			s.damage = []damagedRecord{{data: raw, err: decodeErr,
+				fix: "move its contents to a quarantine file and keep the habits that still decode"}}
+			return nil
+		}
+		s.data = map[string]Habit{}
+	}
+	s.damage = []damagedRecord{{data: raw, err: decodeErr,
+		fix: "move its contents to a quarantine file and start an empty store"}}
+	return nil
+}
+
+// jsonRecords splits the given JSON-encoded habit data, versioned or not, into
+// the undecoded record of each habit and returns them with the data's schema
+// version. It reports false if the data is not a JSON object of records.
+func jsonRecords(raw []byte) (int, map[string]json.RawMessage, bool) {
+	var vd struct {
+		Version int                        `json:"version"`
+		Habits  map[string]json.RawMessage `json:"habits"`
+	}
+	err := json.Unmarshal(raw, &vd)
+	if err == nil && vd.Version != 0 {
+		return vd.Version, vd.Habits, true
+	}
+	var records map[string]json.RawMessage
+	err = json.Unmarshal(raw, &records)
+	if err != nil {
+		return 0, nil, false
+	}
+	if _, ok := records["habits"]; ok {
+		// Versioned data with a damaged version.
+		return 0, nil, false
+	}
+	return 0, records, true
+}
+
+// damaged returns the data that the store could not decode when it was
+// opened with WithSalvage.
+func (s *FileStore) damaged() []damagedRecord {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return append([]damagedRecord(nil), s.damage...)
+}
+
+// quarantine writes the data that the store could not decode to a file named
+// like the store file with a ".quarantine" extension appended, and forgets it
+// so that saving the store leaves it out. A store file that could not be
+// decoded at all is copied as it is, and damaged records are written as a JSON
+// object of records by key. The quarantine file's path is returned. An error
+// is returned if the quarantine file already exists or cannot be written.
+func (s *FileStore) quarantine() (string, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	path := s.path + ".quarantine"
+	if len(s.damage) == 0 {
+		return path, nil
+	}
+	data := s.damage[0].data
+	if s.damage[0].key != "" {
+		records := map[string]json.RawMessage{}
+		for _, d := range s.damage {
+			records[d.key] = d.data
+		}
+		var err error
+		data, err = json.MarshalIndent(records, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("error encoding damaged records: %w", err)
+		}
+	}
+	_, err := os.Stat(path)
+	if err == nil {
+		return "", fmt.Errorf("quarantine file %q already exists; move it away and try again", path)
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return "", fmt.Errorf("error writing quarantine file %q: %w", path, err)
+	}
+	err = os.WriteFile(path, data, 0o600)
+	if err != nil {
+		return "", fmt.Errorf("error writing quarantine file %q: %w", path, err)
+	}
+	s.damage = nil
+	return path, nil
+}
@@ -0,0 +1,225 @@
+package habit_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTracker_CheckReportsProblemsWithoutChangingStore(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T13:00:00Z")
+	now := habit.Now()
+	habits := map[string]habit.Habit{
+		"reading": {
+			Name:          "reading",
+			CurrentStreak: 2,
+			LongestStreak: 2,
+			LastDone:      now.Add(48 * time.Hour),
+			History: []habit.Completion{
+				{At: now.Add(-2 * time.Hour)},
+				{At: now.Add(48 * time.Hour)},
+			},
+		},
+		"Reading ": {Name: "Reading ", CurrentStreak: 1, LongestStreak: 1, LastDone: now.Add(-72 * time.Hour),
+			History: []habit.Completion{{At: now.Add(-72 * time.Hour)}}},
+		"cleaning": {Name: "cleaning", CurrentStreak: -1, LongestStreak: 1, LastDone: now.Add(-time.Hour),
+			History: []habit.Completion{{At: now.Add(-time.Hour)}}},
+	}
+	store := &memStore{habits: map[string]habit.Habit{}}
+	for name, hbt := range habits {
+		store.habits[name] = hbt
+	}
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output), habit.WithLocation(time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	problems, err := tracker.Check(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []habit.Problem{
+		{Habit: "Reading ", Description: "the name clashes with 'reading'", Fix: "merge them into 'reading'"},
+		{Habit: "cleaning", Description: "a streak is negative", Fix: "recompute the streaks"},
+		{Habit: "reading", Description: "1 completion is in the future", Fix: "remove them and recompute the streaks"},
+		{Habit: "reading", Description: "the last completion is in the future", Fix: "set it to the last completion in the history"},
+	}
+	if !cmp.Equal(want, problems) {
+		t.Error(cmp.Diff(want, problems))
+	}
+	if want := "Found 4 problems.\n"; !strings.HasSuffix(output.String(), want) {
+		t.Errorf("want output ending in %q, got output %q", want, output.String())
+	}
+	if store.saves != 0 {
+		t.Errorf("want no saves, got %d", store.saves)
+	}
+	if !cmp.Equal(habits, store.habits) {
+		t.Error(cmp.Diff(habits, store.habits))
+	}
+}
+
+func TestTracker_CheckRepairsProblems(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T13:00:00Z")
+	now := habit.Now()
+	store := &memStore{habits: map[string]habit.Habit{
+		"reading": {
+			Name:          "reading",
+			CurrentStreak: 2,
+			LongestStreak: 2,
+			LastDone:      now.Add(48 * time.Hour),
+			History: []habit.Completion{
+				{At: now.Add(-2 * time.Hour)},
+				{At: now.Add(48 * time.Hour)},
+			},
+		},
+		"Reading ": {Name: "Reading ", CurrentStreak: 1, LongestStreak: 1, LastDone: now.Add(-20 * time.Hour),
+			History: []habit.Completion{{At: now.Add(-20 * time.Hour)}}},
+		"cleaning": {Name: "cleaning", CurrentStreak: -1, LongestStreak: 1, LastDone: now.Add(-time.Hour),
+			History: []habit.Completion{{At: now.Add(-time.Hour)}, {At: now.Add(-20 * time.Hour)}}},
+	}}
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output), habit.WithLocation(time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	problems, err := tracker.Check(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(problems) != 5 {
+		t.Errorf("want 5 problems, got %d: %v", len(problems), problems)
+	}
+	if store.saves != 1 {
+		t.Errorf("want 1 save, got %d", store.saves)
+	}
+	want := map[string]habit.Habit{
+		"reading": {
+			Name:          "reading",
+			CurrentStreak: 2,
+			LongestStreak: 2,
+			LastDone:      now.Add(-2 * time.Hour),
+			History: []habit.Completion{
+				{At: now.Add(-20 * time.Hour)},
+				{At: now.Add(-2 * time.Hour)},
+			},
+		},
+		"cleaning": {
+			Name:          "cleaning",
+			CurrentStreak: 2,
+			LongestStreak: 2,
+			LastDone:      now.Add(-time.Hour),
+			History:       []habit.Completion{{At: now.Add(-20 * time.Hour)}, {At: now.Add(-time.Hour)}},
+		},
+	}
+	if !cmp.Equal(want, store.habits) {
+		t.Error(cmp.Diff(want, store.habits))
+	}
+	output.Reset()
+	problems, err = tracker.Check(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(problems) != 0 {
+		t.Errorf("want no problems after repair, got %v", problems)
+	}
+	if want, got := "No problems found in 2 habits.\n", output.String(); want != got {
+		t.Errorf("want output %q, got output %q", want, got)
+	}
+}
+
+func TestTracker_CheckQuarantinesRecordsThatCannotBeDecoded(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "habit.json")
+	data := `{
+  "version": 1,
+  "habits": {
+    "meditation": {"name": "meditation", "current_streak": "many"},
+    "reading": {"name": "reading", "current_streak": 1, "longest_streak": 1, "last_done": "2024-02-06T09:00:00Z",
+      "history": [{"at": "2024-02-06T09:00:00Z"}]}
+  }
+}`
+	err := os.WriteFile(path, []byte(data), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = habit.OpenJSONStore(path)
+	if !errors.Is(err, habit.ErrStoreCorrupt) {
+		t.Fatalf("want ErrStoreCorrupt opening the store without salvage, got %v", err)
+	}
+	store, err := habit.OpenJSONStore(path, habit.WithSalvage())
+	if err != nil {
+		t.Fatal(err)
+	}
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	problems, err := tracker.Check(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(problems) != 1 || problems[0].Habit != "meditation" {
+		t.Errorf("want a problem with the meditation record, got %v", problems)
+	}
+	quarantined, err := os.ReadFile(path + ".quarantine")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `"current_streak": "many"`; !bytes.Contains(quarantined, []byte(want)) {
+		t.Errorf("want quarantine file containing %q, got %q", want, quarantined)
+	}
+	store, err = habit.OpenJSONStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := store.Get("meditation"); ok {
+		t.Error("want the damaged record removed from the store")
+	}
+	if _, ok := store.Get("reading"); !ok {
+		t.Error("want the decodable record kept in the store")
+	}
+}
+
+func TestTracker_CheckQuarantinesStoreFileThatCannotBeDecoded(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "habit.store")
+	garbage := []byte("not a gob-encoded store")
+	err := os.WriteFile(path, garbage, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := habit.OpenStore(path, habit.WithSalvage())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(new(bytes.Buffer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	problems, err := tracker.Check(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(problems) != 1 || problems[0].Habit != "" {
+		t.Errorf("want a problem with the store file, got %v", problems)
+	}
+	quarantined, err := os.ReadFile(path + ".quarantine")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(garbage, quarantined) {
+		t.Errorf("want the store file quarantined as it was, got %q", quarantined)
+	}
+	_, err = habit.OpenStore(path)
+	if err != nil {
+		t.Errorf("want the repaired store to open, got %v", err)
+	}
+}
@@ -0,0 +1,131 @@
+package habit
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// goalBarWidth is the number of cells in a progress bar toward a goal.
+const goalBarWidth = 10
+
+// A GoalProgress is the progress of a Habit toward its goal.
+type GoalProgress struct {
+	// Habit is the name of the habit.
+	Habit string `json:"habit"`
+	// Goal is the length of the streak the habit is being done for, in
+	// periods of its frequency.
+	Goal int `json:"goal"`
+	// Streak is the habit's current streak, or zero if it has been broken.
+	Streak int `json:"streak"`
+	// Percent is the percentage of the goal the streak has reached, at most
+	// 100.
+	Percent float64 `json:"percent"`
+}
+
+// SetGoal sets the length of the streak the Habit with the given name is being
+// done for, in periods of its frequency, such as 30 for a 30-day challenge,
+// and saves the store. A goal of zero removes the Habit's goal. Progress
+// toward the goal is shown in summaries, and the Habit can be archived once
+// the goal is reached. An error is returned if the goal is negative, the Habit
+// does not exist or the store cannot be saved.
+func (t *Tracker) SetGoal(hbtName string, goal int) error {
+	if goal < 0 {
+		return errors.New("the goal must be a positive number of periods, or 0 to remove it")
+	}
+	hbt, ok := t.store.Get(hbtName)
+	if !ok {
+		return errHabitNotFound(hbtName)
+	}
+	hbt.Goal = goal
+	t.store.Add(hbt)
+	err := t.save()
+	if err != nil {
+		return err
+	}
+	if goal == 0 {
+		fmt.Fprintf(t.output, "The habit '%s' no longer has a goal.\n", hbtName)
+		return nil
+	}
+	fmt.Fprintf(t.output, "The habit '%s' now has a goal of %d %s in a row. You're on %s.\n",
+		hbtName, goal, hbt.Frequency.unit(goal), hbt.goalProgress(t.now(), t.calendar))
+	return nil
+}
+
+// GoalProgress returns the progress toward its goal of each tracked Habit
+// that has one, set with SetGoal, sorted by name. If any tags are given, only
+// the Habits with at least one of the tags are included.
+func (t *Tracker) GoalProgress(tags ...string) []GoalProgress {
+	now := t.now()
+	progress := []GoalProgress{}
+	for _, hbt := range t.sortedHabits(false, tags...) {
+		if hbt.Goal <= 0 {
+			continue
+		}
+		progress = append(progress, GoalProgress{
+			Habit:   hbt.Name,
+			Goal:    hbt.Goal,
+			Streak:  hbt.goalStreak(now, t.calendar),
+			Percent: hbt.goalPercent(now, t.calendar),
+		})
+	}
+	return progress
+}
+
+// goalProgress returns the progress of the Habit toward its goal as of the
+// given timestamp, such as "day 12/30", counting the periods of its current
+// streak.
+func (h Habit) goalProgress(now time.Time, cal calendar) string {
+	return fmt.Sprintf("%s %d/%d", h.Frequency.unit(1), h.goalStreak(now, cal), h.Goal)
+}
+
+// goalStreak returns the current streak of the Habit as of the given
+// timestamp, or zero if it has been broken.
+func (h Habit) goalStreak(now time.Time, cal calendar) int {
+	current, _ := h.streaks(now, cal)
+	if !h.Avoid && (h.LastDone.IsZero() || h.missedPeriods(now, cal) >= 1) {
+		return 0
+	}
+	return current
+}
+
+// goalPercent returns the percentage of its goal the Habit's current streak
+// has reached as of the given timestamp, at most 100, or zero if it has no
+// goal.
+func (h Habit) goalPercent(now time.Time, cal calendar) float64 {
+	if h.Goal <= 0 {
+		return 0
+	}
+	return min(100, 100*float64(h.goalStreak(now, cal))/float64(h.Goal))
+}
+
+// progressBar returns a bar of goalBarWidth cells filled in proportion to the
+// given percentage, followed by the percentage rounded down, such as
+// "████░░░░░░ 40%".
+func progressBar(percent float64) string {
+	filled := int(percent / 100 * goalBarWidth)
+	return strings.Repeat("█", filled) + strings.Repeat("░", goalBarWidth-filled) +
+		fmt.Sprintf(" %d%%", int(percent))
+}
+
+// describeGoal returns the progress of the given Habit toward its goal as of
+// the given timestamp for summaries, such as
+// "Goal: day 12/30. ████░░░░░░ 40%", or an empty string if it has no goal.
+func (t *Tracker) describeGoal(hbt Habit, now time.Time) string {
+	if hbt.Goal <= 0 {
+		return ""
+	}
+	data := t.messageData(hbt)
+	data.Progress = hbt.goalStreak(now, t.calendar)
+	if data.Progress >= hbt.Goal {
+		return t.text("summary_goal_reached", data)
+	}
+	return t.text("summary_goal", data) + " " + progressBar(hbt.goalPercent(now, t.calendar))
+}
+
+// reachGoal reports whether the Habit's current streak, which was the given
+// previous streak before it was tracked, has just reached its goal.
+func (h Habit) reachGoal(previousStreak int) bool {
+	return h.Goal > 0 && h.CurrentStreak == h.Goal && previousStreak < h.Goal
+}
@@ -0,0 +1,162 @@
+package habit_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTracker_SetGoalReportsProgress(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T09:00:00Z")
+	lastDone := time.Date(2024, time.February, 5, 20, 0, 0, 0, time.UTC)
+	store := &memStore{habits: map[string]habit.Habit{
+		"meditation": {Name: "meditation", CurrentStreak: 12, LongestStreak: 12, LastDone: lastDone},
+	}}
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.SetGoal("meditation", 30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := store.habits["meditation"].Goal; got != 30 {
+		t.Errorf("want goal 30, got %d", got)
+	}
+	err = tracker.PrintSummary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.SetGoal("meditation", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "The habit 'meditation' now has a goal of 30 days in a row. You're on day 12/30.\n" +
+		"You are currently on a 12-day streak for 'meditation'. That's a new personal best. Keep it going! Goal: day 12/30. ████░░░░░░ 40%\n" +
+		"The habit 'meditation' no longer has a goal.\n"
+	if got := output.String(); want != got {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestTracker_SetGoalReturnsErrorForInvalidInput(t *testing.T) {
+	t.Parallel()
+	store := &memStore{habits: map[string]habit.Habit{"meditation": {Name: "meditation"}}}
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(new(bytes.Buffer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.SetGoal("meditation", -1)
+	if err == nil {
+		t.Error("want error for a negative goal, got nil")
+	}
+	err = tracker.SetGoal("running", 30)
+	if err == nil {
+		t.Error("want error for a habit that does not exist, got nil")
+	}
+}
+
+func TestTracker_TrackCongratulatesOnReachingGoal(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T09:00:00Z")
+	lastDone := time.Date(2024, time.February, 5, 20, 0, 0, 0, time.UTC)
+	store := &memStore{habits: map[string]habit.Habit{
+		"meditation": {
+			Name: "meditation", CurrentStreak: 29, LongestStreak: 29, Goal: 30,
+			LastDone: lastDone, History: []habit.Completion{{At: lastDone}},
+		},
+	}}
+	output := new(bytes.Buffer)
+	var events []habit.Event
+	tracker, err := habit.NewTracker(
+		habit.WithStore(store),
+		habit.WithOutput(output),
+		habit.WithEventHandler(func(e habit.Event) error {
+			events = append(events, e)
+			return nil
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Track("meditation")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.PrintSummary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Nice work: you've done the habit 'meditation' for 30 days in a row now. " +
+		"That's a streak milestone: you've earned the 30-day badge! " +
+		"You've reached your goal of 30 days in a row! If you're done with it, run 'habit archive meditation'.\n" +
+		"You are currently on a 30-day streak for 'meditation'. That's a new personal best. Keep it going! " +
+		"Strength: 10%. Goal of 30 days reached! Badges: 30-day.\n"
+	if got := output.String(); want != got {
+		t.Error(cmp.Diff(want, got))
+	}
+	var types []habit.EventType
+	for _, e := range events {
+		types = append(types, e.Type)
+	}
+	wantTypes := []habit.EventType{habit.EventStreakMilestone, habit.EventGoalReached}
+	if !cmp.Equal(wantTypes, types) {
+		t.Error(cmp.Diff(wantTypes, types))
+	}
+}
+
+func TestTracker_PrintSummaryResetsGoalProgressOfBrokenStreak(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T09:00:00Z")
+	lastDone := time.Date(2024, time.February, 3, 20, 0, 0, 0, time.UTC)
+	store := &memStore{habits: map[string]habit.Habit{
+		"meditation": {Name: "meditation", CurrentStreak: 12, LongestStreak: 12, Goal: 30, LastDone: lastDone},
+	}}
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.PrintSummary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "It's been 3 days since you did 'meditation'. Stay positive and get back on it! Goal: day 0/30. ░░░░░░░░░░ 0%\n"
+	if got := output.String(); want != got {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestTracker_GoalProgressReportsPercentageOfGoalReached(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T09:00:00Z")
+	lastDone := time.Date(2024, time.February, 5, 20, 0, 0, 0, time.UTC)
+	store := &memStore{habits: map[string]habit.Habit{
+		"meditation": {Name: "meditation", CurrentStreak: 33, LongestStreak: 33, Goal: 66, LastDone: lastDone},
+		"reading":    {Name: "reading", CurrentStreak: 12, LongestStreak: 12, LastDone: lastDone},
+		"running":    {Name: "running", CurrentStreak: 40, LongestStreak: 40, Goal: 30, LastDone: lastDone, Tags: []string{"health"}},
+	}}
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []habit.GoalProgress{
+		{Habit: "meditation", Goal: 66, Streak: 33, Percent: 50},
+		{Habit: "running", Goal: 30, Streak: 40, Percent: 100},
+	}
+	if got := tracker.GoalProgress(); !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+	err = tracker.PrintList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantOutput := "meditation: current streak 33, longest streak 33, daily, goal day 33/66 █████░░░░░ 50%, last 30 days ····························●·\n" +
+		"reading: current streak 12, longest streak 12, daily, last 30 days ····························●·\n" +
+		"running: current streak 40, longest streak 40, daily, goal day 40/30 ██████████ 100%, last 30 days ····························●· [health]\n"
+	if got := output.String(); wantOutput != got {
+		t.Error(cmp.Diff(wantOutput, got))
+	}
+}
@@ -0,0 +1,453 @@
+package habit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+)
+
+// graphqlTrackerKey is the key of the Tracker that handles a GraphQL request
+// in the context passed to the schema's resolvers.
+type graphqlTrackerKey struct{}
+
+// A graphqlRequest is the body of a POST request to the /graphql endpoint.
+type graphqlRequest struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// graphqlSchema returns the GraphQL schema served by the /graphql endpoint,
+// which is built the first time it is needed:
+//
+//	type Query {
+//	  habits(tags: [String!], archived: Boolean = false): [Habit!]!
+//	  habit(name: String!): Habit
+//	  completions(habit: String, since: String, until: String): [Completion!]!
+//	  stats(habit: String, days: Int = 30): [Stats!]!
+//	}
+//	type Mutation {
+//	  track(habit: String!, at: String, note: String): TrackResult!
+//	  delete(habit: String!): String!
+//	}
+//
+// Timestamps are RFC3339 strings.
+var graphqlSchema = sync.OnceValues(newGraphQLSchema)
+
+// newGraphQLSchema builds the schema returned by graphqlSchema.
+func newGraphQLSchema() (graphql.Schema, error) {
+	completion := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Completion",
+		Description: "A single time a habit was done.",
+		Fields: graphql.Fields{
+			"habit":  {Type: graphql.NewNonNull(graphql.String)},
+			"at":     {Type: graphql.NewNonNull(graphql.String)},
+			"note":   {Type: graphql.NewNonNull(graphql.String)},
+			"amount": {Type: graphql.NewNonNull(graphql.Float)},
+			"frozen": {Type: graphql.NewNonNull(graphql.Boolean)},
+		},
+	})
+	hbt := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Habit",
+		Description: "A tracked habit.",
+		Fields: graphql.Fields{
+			"name":          {Type: graphql.NewNonNull(graphql.String)},
+			"frequency":     {Type: graphql.NewNonNull(graphql.String)},
+			"currentStreak": {Type: graphql.NewNonNull(graphql.Int)},
+			"longestStreak": {Type: graphql.NewNonNull(graphql.Int)},
+			"lastDone":      {Type: graphql.String},
+			"reminderTime":  {Type: graphql.String},
+			"archived":      {Type: graphql.NewNonNull(graphql.Boolean)},
+			"avoid":         {Type: graphql.NewNonNull(graphql.Boolean)},
+			"tags":          {Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String)))},
+			"routine":       {Type: graphql.String},
+			"goal":          {Type: graphql.NewNonNull(graphql.Int)},
+			"target":        {Type: graphql.NewNonNull(graphql.Float)},
+			"unit":          {Type: graphql.String},
+			"freezes":       {Type: graphql.NewNonNull(graphql.Int)},
+			"completions":   {Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(completion)))},
+		},
+	})
+	stats := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Stats",
+		Description: "Statistics of a habit over a window of days.",
+		Fields: graphql.Fields{
+			"name":            {Type: graphql.NewNonNull(graphql.String)},
+			"frequency":       {Type: graphql.NewNonNull(graphql.String)},
+			"completions":     {Type: graphql.NewNonNull(graphql.Int)},
+			"window":          {Type: graphql.NewNonNull(graphql.Int)},
+			"periodsDone":     {Type: graphql.NewNonNull(graphql.Int)},
+			"periodsInWindow": {Type: graphql.NewNonNull(graphql.Int)},
+			"currentStreak":   {Type: graphql.NewNonNull(graphql.Int)},
+			"longestStreak":   {Type: graphql.NewNonNull(graphql.Int)},
+			"averageStreak":   {Type: graphql.NewNonNull(graphql.Float)},
+			"completionRate":  {Type: graphql.NewNonNull(graphql.Float)},
+			"bestWeekday":     {Type: graphql.String},
+			"worstWeekday":    {Type: graphql.String},
+		},
+	})
+	trackResult := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "TrackResult",
+		Description: "The result of tracking a habit.",
+		Fields: graphql.Fields{
+			"message": {Type: graphql.NewNonNull(graphql.String)},
+			"habit":   {Type: graphql.NewNonNull(hbt)},
+		},
+	})
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"habits": {
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(hbt))),
+				Args: graphql.FieldConfigArgument{
+					"tags":     {Type: graphql.NewList(graphql.NewNonNull(graphql.String))},
+					"archived": {Type: graphql.Boolean, DefaultValue: false},
+				},
+				Resolve: resolveHabits,
+			},
+			"habit": {
+				Type: hbt,
+				Args: graphql.FieldConfigArgument{
+					"name": {Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: resolveHabit,
+			},
+			"completions": {
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(completion))),
+				Args: graphql.FieldConfigArgument{
+					"habit": {Type: graphql.String},
+					"since": {Type: graphql.String},
+					"until": {Type: graphql.String},
+				},
+				Resolve: resolveCompletions,
+			},
+			"stats": {
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(stats))),
+				Args: graphql.FieldConfigArgument{
+					"habit": {Type: graphql.String},
+					"days":  {Type: graphql.Int, DefaultValue: DefaultStatsWindow},
+				},
+				Resolve: resolveStats,
+			},
+		},
+	})
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"track": {
+				Type: graphql.NewNonNull(trackResult),
+				Args: graphql.FieldConfigArgument{
+					"habit": {Type: graphql.NewNonNull(graphql.String)},
+					"at":    {Type: graphql.String},
+					"note":  {Type: graphql.String},
+				},
+				Resolve: resolveTrack,
+			},
+			"delete": {
+				Type: graphql.NewNonNull(graphql.String),
+				Args: graphql.FieldConfigArgument{
+					"habit": {Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: resolveDelete,
+			},
+		},
+	})
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query, Mutation: mutation})
+}
+
+// graphqlTracker returns the Tracker that handles the GraphQL request with the
+// given resolver parameters.
+func graphqlTracker(p graphql.ResolveParams) *Tracker {
+	return p.Context.Value(graphqlTrackerKey{}).(*Tracker)
+}
+
+// resolveHabits resolves Query.habits.
+func resolveHabits(p graphql.ResolveParams) (any, error) {
+	var tags []string
+	if values, ok := p.Args["tags"].([]any); ok {
+		for _, v := range values {
+			tags = append(tags, v.(string))
+		}
+	}
+	archived, _ := p.Args["archived"].(bool)
+	habits := []map[string]any{}
+	for _, hbt := range graphqlTracker(p).sortedHabits(archived, tags...) {
+		habits = append(habits, graphqlHabit(hbt))
+	}
+	return habits, nil
+}
+
+// resolveHabit resolves Query.habit, which is null if there is no such habit.
+func resolveHabit(p graphql.ResolveParams) (any, error) {
+	hbt, ok := graphqlTracker(p).store.Get(p.Args["name"].(string))
+	if !ok {
+		return nil, nil
+	}
+	return graphqlHabit(hbt), nil
+}
+
+// resolveCompletions resolves Query.completions, which lists the completions
+// of every habit, or of the given habit, from the given time and until the
+// given time in chronological order.
+func resolveCompletions(p graphql.ResolveParams) (any, error) {
+	t := graphqlTracker(p)
+	var since, until time.Time
+	for arg, bound := range map[string]*time.Time{"since": &since, "until": &until} {
+		value, ok := p.Args[arg].(string)
+		if !ok {
+			continue
+		}
+		var err error
+		*bound, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q (want RFC3339)", arg, value)
+		}
+	}
+	var habits []Habit
+	if name, ok := p.Args["habit"].(string); ok {
+		hbt, ok := t.store.Get(name)
+		if !ok {
+			return nil, errHabitNotFound(name)
+		}
+		habits = append(habits, hbt)
+	} else {
+		habits = t.store.All()
+	}
+	type habitCompletion struct {
+		name string
+		c    Completion
+	}
+	var found []habitCompletion
+	for _, hbt := range habits {
+		for _, c := range hbt.History {
+			if !since.IsZero() && c.At.Before(since) || !until.IsZero() && c.At.After(until) {
+				continue
+			}
+			found = append(found, habitCompletion{hbt.Name, c})
+		}
+	}
+	sort.Slice(found, func(i, j int) bool {
+		if !found[i].c.At.Equal(found[j].c.At) {
+			return found[i].c.At.Before(found[j].c.At)
+		}
+		return found[i].name < found[j].name
+	})
+	completions := []map[string]any{}
+	for _, hc := range found {
+		completions = append(completions, graphqlCompletion(hc.name, hc.c))
+	}
+	return completions, nil
+}
+
+// resolveStats resolves Query.stats, which holds the statistics of every
+// active habit, or of the given habit, over the given number of days.
+func resolveStats(p graphql.ResolveParams) (any, error) {
+	name, _ := p.Args["habit"].(string)
+	days, _ := p.Args["days"].(int)
+	stats, err := graphqlTracker(p).Stats(name, days)
+	if err != nil {
+		return nil, err
+	}
+	result := []map[string]any{}
+	for _, st := range stats {
+		s := map[string]any{
+			"name":            st.Name,
+			"frequency":       st.Frequency.String(),
+			"completions":     st.Completions,
+			"window":          st.Window,
+			"periodsDone":     st.PeriodsDone,
+			"periodsInWindow": st.PeriodsInWindow,
+			"currentStreak":   st.CurrentStreak,
+			"longestStreak":   st.LongestStreak,
+			"averageStreak":   st.AverageStreak,
+			"completionRate":  st.CompletionRate(),
+		}
+		if st.Completions > 0 {
+			s["bestWeekday"] = st.BestWeekday().String()
+			s["worstWeekday"] = st.WorstWeekday().String()
+		}
+		result = append(result, s)
+	}
+	return result, nil
+}
+
+// resolveTrack resolves Mutation.track, which tracks the given habit at the
+// given time, or now, with the given note, and saves the store.
+func resolveTrack(p graphql.ResolveParams) (any, error) {
+	name := p.Args["habit"].(string)
+	note, _ := p.Args["note"].(string)
+	output := new(bytes.Buffer)
+	t := graphqlTracker(p).withOutput(output)
+	at := t.now()
+	if value, ok := p.Args["at"].(string); ok {
+		var err error
+		at, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid at %q (want RFC3339)", value)
+		}
+	}
+	err := t.TrackNoteContext(p.Context, name, at, note)
+	if err != nil {
+		return nil, err
+	}
+	hbt, _ := t.store.Get(name)
+	return map[string]any{
+		"message": strings.TrimSpace(output.String()),
+		"habit":   graphqlHabit(hbt),
+	}, nil
+}
+
+// resolveDelete resolves Mutation.delete, which deletes the given habit, saves
+// the store and returns the Tracker's message.
+func resolveDelete(p graphql.ResolveParams) (any, error) {
+	name := p.Args["habit"].(string)
+	output := new(bytes.Buffer)
+	t := graphqlTracker(p).withOutput(output)
+	if _, ok := t.store.Get(name); !ok {
+		return nil, errHabitNotFound(name)
+	}
+	err := t.DeleteContext(p.Context, name)
+	if err != nil {
+		return nil, err
+	}
+	return strings.TrimSpace(output.String()), nil
+}
+
+// graphqlHabit returns the fields of the GraphQL Habit object for the given
+// Habit.
+func graphqlHabit(hbt Habit) map[string]any {
+	fields := map[string]any{
+		"name":          hbt.Name,
+		"frequency":     hbt.Frequency.String(),
+		"currentStreak": hbt.CurrentStreak,
+		"longestStreak": hbt.LongestStreak,
+		"archived":      hbt.Archived,
+		"avoid":         hbt.Avoid,
+		"tags":          append([]string{}, hbt.Tags...),
+		"goal":          hbt.Goal,
+		"target":        hbt.Target,
+		"freezes":       hbt.Freezes,
+	}
+	if !hbt.LastDone.IsZero() {
+		fields["lastDone"] = hbt.LastDone.Format(time.RFC3339)
+	}
+	if hbt.ReminderTime != nil {
+		fields["reminderTime"] = hbt.ReminderTime.String()
+	}
+	if hbt.Routine != "" {
+		fields["routine"] = hbt.Routine
+	}
+	if hbt.Unit != "" {
+		fields["unit"] = hbt.Unit
+	}
+	completions := make([]map[string]any, len(hbt.History))
+	for i, c := range hbt.History {
+		completions[i] = graphqlCompletion(hbt.Name, c)
+	}
+	fields["completions"] = completions
+	return fields
+}
+
+// graphqlCompletion returns the fields of the GraphQL Completion object for
+// the given completion of the Habit with the given name.
+func graphqlCompletion(hbtName string, c Completion) map[string]any {
+	return map[string]any{
+		"habit":  hbtName,
+		"at":     c.At.Format(time.RFC3339),
+		"note":   c.Note,
+		"amount": c.Amount,
+		"frozen": c.Frozen,
+	}
+}
+
+// graphql handles GET and POST /graphql, which executes the GraphQL query in
+// the ?query= parameter or the JSON request body against the habits of the
+// given Tracker. Errors in the query are reported in the response's errors
+// field, as GraphQL clients expect.
+func (s *Server) graphql(t *Tracker, w http.ResponseWriter, r *http.Request) {
+	var req graphqlRequest
+	switch r.Method {
+	case http.MethodGet:
+		req.Query = r.URL.Query().Get("query")
+		req.OperationName = r.URL.Query().Get("operationName")
+		if vars := r.URL.Query().Get("variables"); vars != "" {
+			err := json.Unmarshal([]byte(vars), &req.Variables)
+			if err != nil {
+				writeJSON(w, http.StatusBadRequest, errorResponse{Error: fmt.Sprintf("invalid variables: %v", err)})
+				return
+			}
+		}
+	case http.MethodPost:
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil && !errors.Is(err, io.EOF) {
+			writeJSON(w, bodyErrorStatus(err), errorResponse{Error: fmt.Sprintf("invalid request body: %v", err)})
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		writeJSON(w, http.StatusMethodNotAllowed, errorResponse{
+			Error: fmt.Sprintf("method %s not allowed", r.Method),
+		})
+		return
+	}
+	if req.Query == "" {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "missing query"})
+		return
+	}
+	// Mutations change habits, so they must not be sent in links.
+	if r.Method == http.MethodGet && isGraphQLMutation(req.Query, req.OperationName) {
+		w.Header().Set("Allow", http.MethodPost)
+		writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "mutations must be sent with POST"})
+		return
+	}
+	schema, err := graphqlSchema()
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: err.Error()})
+		return
+	}
+	result := graphql.Do(graphql.Params{
+		Schema:         schema,
+		RequestString:  req.Query,
+		OperationName:  req.OperationName,
+		VariableValues: req.Variables,
+		Context:        context.WithValue(r.Context(), graphqlTrackerKey{}, t),
+	})
+	writeJSON(w, http.StatusOK, result)
+}
+
+// isGraphQLMutation reports whether the operation with the given name, or the
+// only operation, in the given GraphQL query is a mutation. A query that cannot
+// be parsed is not a mutation, so that its syntax errors are reported when it
+// is executed.
+func isGraphQLMutation(query, operationName string) bool {
+	doc, err := parser.Parse(parser.ParseParams{Source: query})
+	if err != nil {
+		return false
+	}
+	for _, def := range doc.Definitions {
+		op, ok := def.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		name := ""
+		if op.Name != nil {
+			name = op.Name.Value
+		}
+		if (operationName == "" || name == operationName) && op.Operation == ast.OperationTypeMutation {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,122 @@
+package habit_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+// postGraphQL posts the given GraphQL query with the given variables to the
+// /graphql endpoint of the server at the given URL and returns the decoded
+// response.
+func postGraphQL(t *testing.T, serverURL, query string, variables map[string]any) map[string]any {
+	t.Helper()
+	body, err := json.Marshal(map[string]any{"query": query, "variables": variables})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.Post(serverURL+"/graphql", "application/json", strings.NewReader(string(body)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("want status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	var result map[string]any
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func TestServer_GraphQLQueryReturnsRequestedFields(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T13:00:00Z")
+	lastDone := habit.Now().Add(-time.Hour)
+	srv, _ := newTestServer(t,
+		habit.Habit{Name: "reading", CurrentStreak: 2, LongestStreak: 3, LastDone: lastDone, Tags: []string{"learning"},
+			History: []habit.Completion{{At: lastDone.Add(-24 * time.Hour)}, {At: lastDone, Note: "chapter 3"}}},
+		habit.Habit{Name: "running", CurrentStreak: 1, LongestStreak: 1, LastDone: lastDone.Add(-time.Hour),
+			History: []habit.Completion{{At: lastDone.Add(-time.Hour)}}},
+	)
+	got := postGraphQL(t, srv.URL, `query($tag: String!) {
+		habits(tags: [$tag]) { name currentStreak longestStreak }
+		completions(since: "2024-02-06T00:00:00Z") { habit at note }
+		stats(habit: "reading", days: 7) { name completions }
+	}`, map[string]any{"tag": "learning"})
+	want := map[string]any{"data": map[string]any{
+		"habits": []any{
+			map[string]any{"name": "reading", "currentStreak": 2.0, "longestStreak": 3.0},
+		},
+		"completions": []any{
+			map[string]any{"habit": "running", "at": "2024-02-06T11:00:00Z", "note": ""},
+			map[string]any{"habit": "reading", "at": "2024-02-06T12:00:00Z", "note": "chapter 3"},
+		},
+		"stats": []any{
+			map[string]any{"name": "reading", "completions": 2.0},
+		},
+	}}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestServer_GraphQLMutationsTrackAndDeleteHabits(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T13:00:00Z")
+	srv, store := newTestServer(t, habit.Habit{Name: "reading"})
+	got := postGraphQL(t, srv.URL, `mutation {
+		track(habit: "running", note: "5k") { message habit { name currentStreak completions { note } } }
+	}`, nil)
+	want := map[string]any{"data": map[string]any{
+		"track": map[string]any{
+			"message": "Congratulations on starting your new habit 'running'! Don't forget to do it again.",
+			"habit": map[string]any{"name": "running", "currentStreak": 1.0,
+				"completions": []any{map[string]any{"note": "5k"}}},
+		},
+	}}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+	got = postGraphQL(t, srv.URL, `mutation { delete(habit: "reading") }`, nil)
+	if _, ok := got["errors"]; ok {
+		t.Fatalf("unexpected errors %v", got["errors"])
+	}
+	if _, ok := store.Get("reading"); ok {
+		t.Error("want habit 'reading' to be deleted")
+	}
+	got = postGraphQL(t, srv.URL, `mutation { delete(habit: "reading") }`, nil)
+	if _, ok := got["errors"]; !ok {
+		t.Error("want an error deleting a habit that does not exist")
+	}
+}
+
+func TestServer_GraphQLRejectsMutationsSentWithGet(t *testing.T) {
+	srv, store := newTestServer(t, habit.Habit{Name: "reading"})
+	query := url.QueryEscape(`mutation { delete(habit: "reading") }`)
+	resp, err := http.Get(srv.URL + "/graphql?query=" + query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("want status %d, got %d", http.StatusMethodNotAllowed, resp.StatusCode)
+	}
+	if _, ok := store.Get("reading"); !ok {
+		t.Error("want habit 'reading' not to be deleted")
+	}
+	resp, err = http.Get(srv.URL + "/graphql?query=" + url.QueryEscape(`{ habit(name: "reading") { name } }`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("want status %d for a query, got %d", http.StatusOK, resp.StatusCode)
+	}
+}
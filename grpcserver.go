@@ -0,0 +1,312 @@
+package habit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/aculclasure/habit/habitpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// watchInterval is how often WatchHabits checks the store for changed habits.
+const watchInterval = time.Second
+
+// grpcService implements the habit gRPC service on top of a Server, so that
+// gRPC and REST requests share the Server's tracker, tokens, users and
+// serialization.
+type grpcService struct {
+	habitpb.UnimplementedHabitServiceServer
+	server *Server
+}
+
+// GRPCServer returns a gRPC server that serves the habit gRPC service defined in
+// package habitpb with the Server's Tracker. If the Server has a token or
+// users, every call must carry one of their tokens as a bearer token in its
+// "authorization" metadata, and a call carrying a user's token is tied to that
+// user like a REST request.
+func (s *Server) GRPCServer() *grpc.Server {
+	var opts []grpc.ServerOption
+	if s.maxBodySize > 0 {
+		opts = append(opts, grpc.MaxRecvMsgSize(int(s.maxBodySize)))
+	}
+	if s.tlsConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(s.tlsConfig)))
+	}
+	srv := grpc.NewServer(append(opts,
+		grpc.UnaryInterceptor(func(ctx context.Context, req any, _ *grpc.UnaryServerInfo,
+			handler grpc.UnaryHandler) (any, error) {
+			ctx, err := s.authorizeGRPC(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.StreamInterceptor(func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo,
+			handler grpc.StreamHandler) error {
+			ctx, err := s.authorizeGRPC(ss.Context())
+			if err != nil {
+				return err
+			}
+			return handler(srv, &userServerStream{ServerStream: ss, ctx: ctx})
+		}),
+	)...)
+	habitpb.RegisterHabitServiceServer(srv, &grpcService{server: s})
+	return srv
+}
+
+// authorizeGRPC returns an Unauthenticated error unless the call with the
+// given context carries the Server's token or the token of one of its users,
+// as checked by authenticate, and a ResourceExhausted error if the call is
+// beyond the Server's rate limit, or its address has made too many calls with
+// invalid tokens. It returns the call's context, tied to the user whose token
+// it carries, if any.
+func (s *Server) authorizeGRPC(ctx context.Context) (context.Context, error) {
+	addr := ""
+	if p, ok := peer.FromContext(ctx); ok {
+		addr = p.Addr.String()
+	}
+	if s.limiter != nil && s.limiter.exhausted(authKey(addr)) {
+		return ctx, status.Error(codes.ResourceExhausted, "too many failed authentication attempts")
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		values = []string{""}
+	}
+	for _, value := range values {
+		user, ok := s.authenticate(value)
+		if !ok {
+			continue
+		}
+		if user != "" {
+			ctx = context.WithValue(ctx, userContextKey{}, user)
+		}
+		if s.limiter != nil && !s.limiter.allow(s.rateKey(user, addr)) {
+			return ctx, status.Error(codes.ResourceExhausted, "rate limit exceeded")
+		}
+		return ctx, nil
+	}
+	if s.limiter != nil {
+		s.limiter.allow(authKey(addr))
+	}
+	return ctx, status.Error(codes.Unauthenticated, "missing or invalid token")
+}
+
+// A userServerStream is a grpc.ServerStream whose context is tied to the user
+// whose token the call carries.
+type userServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the stream's context.
+func (s *userServerStream) Context() context.Context {
+	return s.ctx
+}
+
+// userTracker returns the Tracker that handles the call with the given
+// context, as Server.userTracker does for REST requests, having taken the lock
+// of its store as REST requests do, along with a function that releases the
+// lock. An Internal error is returned if the user's store cannot be opened,
+// and an Unavailable error if another habit process holds its lock. The caller
+// must hold the Server's mtx.
+func (g *grpcService) userTracker(ctx context.Context) (*Tracker, func(), error) {
+	t, err := g.server.userTracker(ctx)
+	if err != nil {
+		return nil, nil, status.Error(codes.Internal, err.Error())
+	}
+	unlock, err := lockChange(t.store)
+	if errors.Is(err, ErrStoreLocked) {
+		return nil, nil, status.Error(codes.Unavailable, err.Error())
+	}
+	if err != nil {
+		return nil, nil, status.Error(codes.Internal, err.Error())
+	}
+	return t, unlock, nil
+}
+
+// TrackHabit marks a habit as done, creating it if it does not exist.
+func (g *grpcService) TrackHabit(ctx context.Context, req *habitpb.TrackHabitRequest) (*habitpb.TrackHabitResponse, error) {
+	g.server.mtx.Lock()
+	defer g.server.mtx.Unlock()
+	if req.GetName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "habit name must not be empty")
+	}
+	t, unlock, err := g.userTracker(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	output := new(bytes.Buffer)
+	t = t.withOutput(output)
+	switch {
+	case req.GetAt() == nil && req.GetNote() == "":
+		err = t.TrackContext(ctx, req.GetName())
+	case req.GetAt() == nil:
+		err = t.TrackNoteContext(ctx, req.GetName(), t.now(), req.GetNote())
+	default:
+		err = t.TrackNoteContext(ctx, req.GetName(), req.GetAt().AsTime(), req.GetNote())
+	}
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	hbt, _ := t.store.Get(req.GetName())
+	return &habitpb.TrackHabitResponse{
+		Message: strings.TrimSpace(output.String()),
+		Habit:   t.habitProto(hbt),
+	}, nil
+}
+
+// ListHabits returns every habit, optionally filtered by tag.
+func (g *grpcService) ListHabits(ctx context.Context, req *habitpb.ListHabitsRequest) (*habitpb.ListHabitsResponse, error) {
+	g.server.mtx.Lock()
+	defer g.server.mtx.Unlock()
+	t, unlock, err := g.userTracker(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	resp := &habitpb.ListHabitsResponse{}
+	for _, hbt := range t.sortedHabits(req.GetArchived(), req.GetTags()...) {
+		resp.Habits = append(resp.Habits, t.habitProto(hbt))
+	}
+	return resp, nil
+}
+
+// GetStats returns statistics for one habit or for every habit.
+func (g *grpcService) GetStats(ctx context.Context, req *habitpb.GetStatsRequest) (*habitpb.GetStatsResponse, error) {
+	g.server.mtx.Lock()
+	defer g.server.mtx.Unlock()
+	t, unlock, err := g.userTracker(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	if req.GetName() != "" {
+		_, ok := t.store.Get(req.GetName())
+		if !ok {
+			return nil, status.Errorf(codes.NotFound, "habit '%s' does not exist", req.GetName())
+		}
+	}
+	window := DefaultStatsWindow
+	if req.GetDays() != 0 {
+		window = int(req.GetDays())
+	}
+	stats, err := t.Stats(req.GetName(), window)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	resp := &habitpb.GetStatsResponse{}
+	for _, st := range stats {
+		pb := &habitpb.HabitStats{
+			Name:            st.Name,
+			FrequencyDays:   int32(st.Frequency.Days()),
+			Completions:     int32(st.Completions),
+			WindowDays:      int32(st.Window),
+			PeriodsDone:     int32(st.PeriodsDone),
+			PeriodsInWindow: int32(st.PeriodsInWindow),
+			CurrentStreak:   int32(st.CurrentStreak),
+			LongestStreak:   int32(st.LongestStreak),
+			AverageStreak:   st.AverageStreak,
+			CompletionRate:  st.CompletionRate(),
+		}
+		for _, n := range st.Weekdays {
+			pb.Weekdays = append(pb.Weekdays, int32(n))
+		}
+		resp.Stats = append(resp.Stats, pb)
+	}
+	return resp, nil
+}
+
+// WatchHabits sends an event for each watched habit, and then an event each
+// time a watched habit changes or is deleted, until the call is cancelled.
+func (g *grpcService) WatchHabits(req *habitpb.WatchHabitsRequest, stream habitpb.HabitService_WatchHabitsServer) error {
+	watched := func(name string) bool {
+		if len(req.GetNames()) == 0 {
+			return true
+		}
+		for _, n := range req.GetNames() {
+			if n == name {
+				return true
+			}
+		}
+		return false
+	}
+	seen := map[string]Habit{}
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+	for {
+		var events []*habitpb.HabitEvent
+		g.server.mtx.Lock()
+		t, unlock, err := g.userTracker(stream.Context())
+		if err != nil {
+			g.server.mtx.Unlock()
+			return err
+		}
+		current := map[string]Habit{}
+		for _, hbt := range t.sortedHabits(false) {
+			if !watched(hbt.Name) {
+				continue
+			}
+			current[hbt.Name] = hbt
+			prev, ok := seen[hbt.Name]
+			if !ok || !reflect.DeepEqual(prev, hbt) {
+				events = append(events, &habitpb.HabitEvent{Habit: t.habitProto(hbt)})
+			}
+		}
+		for name := range seen {
+			if _, ok := current[name]; !ok {
+				events = append(events, &habitpb.HabitEvent{
+					Habit:   &habitpb.Habit{Name: name},
+					Deleted: true,
+				})
+			}
+		}
+		unlock()
+		g.server.mtx.Unlock()
+		seen = current
+		for _, event := range events {
+			err := stream.Send(event)
+			if err != nil {
+				return err
+			}
+		}
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// habitProto returns the given Habit as a habitpb.Habit as of the current
+// time.
+func (t *Tracker) habitProto(hbt Habit) *habitpb.Habit {
+	now := t.now()
+	current, longest := hbt.streaks(now, t.calendar)
+	pb := &habitpb.Habit{
+		Name:          hbt.Name,
+		CurrentStreak: int32(current),
+		LongestStreak: int32(longest),
+		FrequencyDays: int32(hbt.Frequency.Days()),
+		Tags:          hbt.Tags,
+		Archived:      hbt.Archived,
+		Paused:        hbt.Paused(now),
+		Avoid:         hbt.Avoid,
+		Completions:   int32(hbt.completions()),
+	}
+	if !hbt.LastDone.IsZero() {
+		pb.LastDone = timestamppb.New(hbt.LastDone)
+	}
+	return pb
+}
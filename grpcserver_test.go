@@ -0,0 +1,233 @@
+package habit_test
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+	"github.com/aculclasure/habit/habitpb"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func newTestGRPCClient(t *testing.T, token string, habits ...habit.Habit) habitpb.HabitServiceClient {
+	t.Helper()
+	store, err := habit.OpenStore(t.TempDir() + "/test.store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, hbt := range habits {
+		store.Add(hbt)
+	}
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(new(bytes.Buffer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := habit.NewServer(tracker, habit.WithToken(token)).GRPCServer()
+	lis := bufconn.Listen(1 << 20)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return habitpb.NewHabitServiceClient(conn)
+}
+
+func TestGRPCServer_TrackHabitTracksHabitAndReturnsMessage(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T13:00:00Z")
+	client := newTestGRPCClient(t, "")
+	got, err := client.TrackHabit(context.Background(), &habitpb.TrackHabitRequest{Name: "programming"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &habitpb.TrackHabitResponse{
+		Message: "Congratulations on starting your new habit 'programming'! Don't forget to do it again.",
+		Habit: &habitpb.Habit{
+			Name:          "programming",
+			CurrentStreak: 1,
+			LongestStreak: 1,
+			LastDone:      timestamppb.New(habit.Now()),
+			FrequencyDays: 1,
+			Completions:   1,
+		},
+	}
+	if !cmp.Equal(want, got, protocmp.Transform()) {
+		t.Error(cmp.Diff(want, got, protocmp.Transform()))
+	}
+}
+
+func TestGRPCServer_ListHabitsReturnsHabitsSortedByNameAndFilteredByTag(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T13:00:00Z")
+	client := newTestGRPCClient(t, "",
+		habit.Habit{Name: "running", Tags: []string{"health"}},
+		habit.Habit{Name: "reading"},
+		habit.Habit{Name: "cycling", Tags: []string{"health"}},
+	)
+	resp, err := client.ListHabits(context.Background(), &habitpb.ListHabitsRequest{Tags: []string{"health"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, hbt := range resp.GetHabits() {
+		got = append(got, hbt.GetName())
+	}
+	want := []string{"cycling", "running"}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestGRPCServer_GetStatsReturnsNotFoundForMissingHabit(t *testing.T) {
+	client := newTestGRPCClient(t, "")
+	_, err := client.GetStats(context.Background(), &habitpb.GetStatsRequest{Name: "missing"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("want code %s, got error %v", codes.NotFound, err)
+	}
+}
+
+func TestGRPCServer_GetStatsReturnsStatsForHabit(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T20:00:00Z")
+	lastDone := time.Date(2024, time.February, 6, 13, 0, 0, 0, time.UTC)
+	client := newTestGRPCClient(t, "", habit.Habit{
+		Name:          "programming",
+		CurrentStreak: 1,
+		LongestStreak: 1,
+		LastDone:      lastDone,
+		History:       []habit.Completion{{At: lastDone}},
+	})
+	got, err := client.GetStats(context.Background(), &habitpb.GetStatsRequest{Name: "programming", Days: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &habitpb.GetStatsResponse{Stats: []*habitpb.HabitStats{{
+		Name:            "programming",
+		FrequencyDays:   1,
+		Completions:     1,
+		WindowDays:      10,
+		PeriodsDone:     1,
+		PeriodsInWindow: 10,
+		CurrentStreak:   1,
+		LongestStreak:   1,
+		AverageStreak:   1,
+		CompletionRate:  10,
+		Weekdays:        []int32{0, 0, 1, 0, 0, 0, 0},
+	}}}
+	if !cmp.Equal(want, got, protocmp.Transform()) {
+		t.Error(cmp.Diff(want, got, protocmp.Transform()))
+	}
+}
+
+func TestGRPCServer_WatchHabitsStreamsCurrentHabitsAndChanges(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T13:00:00Z")
+	client := newTestGRPCClient(t, "", habit.Habit{Name: "reading"})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	stream, err := client.WatchHabits(ctx, &habitpb.WatchHabitsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	event, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.GetHabit().GetName() != "reading" {
+		t.Fatalf("want event for 'reading', got %v", event)
+	}
+	_, err = client.TrackHabit(ctx, &habitpb.TrackHabitRequest{Name: "programming"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	event, err = stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.GetHabit().GetName() != "programming" || event.GetHabit().GetCurrentStreak() != 1 {
+		t.Errorf("want event for tracked habit 'programming', got %v", event)
+	}
+}
+
+func TestGRPCServer_RejectsCallsWithoutToken(t *testing.T) {
+	client := newTestGRPCClient(t, "secret")
+	_, err := client.ListHabits(context.Background(), &habitpb.ListHabitsRequest{})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("want code %s, got error %v", codes.Unauthenticated, err)
+	}
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer secret")
+	_, err = client.ListHabits(ctx, &habitpb.ListHabitsRequest{})
+	if err != nil {
+		t.Errorf("want no error for valid token, got %v", err)
+	}
+}
+
+func TestGRPCServer_ScopesCallsToUserOfToken(t *testing.T) {
+	store, err := habit.OpenStore(t.TempDir() + "/test.store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{Name: "shared"})
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(new(bytes.Buffer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stores := map[string]habit.Store{
+		"alice": &memStore{habits: map[string]habit.Habit{"reading": {Name: "reading"}}},
+	}
+	srv := habit.NewServer(tracker, habit.WithToken("admin-token"),
+		habit.WithUsers(map[string]string{"alice": "alice-token"}, func(user string) (habit.Store, error) {
+			return stores[user], nil
+		})).GRPCServer()
+	lis := bufconn.Listen(1 << 20)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := habitpb.NewHabitServiceClient(conn)
+	testCases := map[string]struct {
+		token string
+		want  []string
+	}{
+		"user token":   {token: "alice-token", want: []string{"reading"}},
+		"server token": {token: "admin-token", want: []string{"shared"}},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+tc.token)
+			resp, err := client.ListHabits(ctx, &habitpb.ListHabitsRequest{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, hbt := range resp.GetHabits() {
+				got = append(got, hbt.GetName())
+			}
+			if !cmp.Equal(tc.want, got) {
+				t.Error(cmp.Diff(tc.want, got))
+			}
+		})
+	}
+}
@@ -2,14 +2,23 @@
 package habit
 
 import (
+	"context"
 	"errors"
-	"flag"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
+	"slices"
+	"sort"
+	"strings"
+	"text/template"
 	"time"
 )
 
+// DefaultStorePath is the path of the store file used when no other store is
+// given.
+const DefaultStorePath = "habit.store"
+
 // Now provides a seam to allow the time.Now() function to be overriden for
 // testing.
 var Now = time.Now
@@ -17,20 +26,168 @@ var Now = time.Now
 // A Habit represents a habit that can be tracked.
 type Habit struct {
 	// Name is the name of the habit.
-	Name string
+	Name string `json:"name"`
 	// CurrentStreak is the number of days in a row this habit has
 	// been performed.
-	CurrentStreak int
+	CurrentStreak int `json:"current_streak"`
+	// LongestStreak is the personal record for the number of days in a row
+	// this habit has been performed.
+	LongestStreak int `json:"longest_streak"`
 	// LastDone is the timestamp when the habit was last done.
-	LastDone time.Time
+	LastDone time.Time `json:"last_done"`
+	// Frequency is how often the habit must be done to keep its streak going.
+	Frequency Frequency `json:"frequency,omitempty"`
+	// ReminderTime is the preferred time of day to do the habit. It is nil if
+	// no reminder time has been set.
+	ReminderTime *TimeOfDay `json:"reminder_time,omitempty"`
+	// Deadline is the time of day by which a daily habit should be done,
+	// after which it is overdue. It is nil if the habit has no deadline.
+	Deadline *TimeOfDay `json:"deadline,omitempty"`
+	// History is the record of every time the habit was done, in
+	// chronological order.
+	History []Completion `json:"history,omitempty"`
+	// Freezes is the number of streak freezes left. A streak freeze keeps
+	// the habit's streak going when exactly one period is missed.
+	Freezes int `json:"freezes,omitempty"`
+	// Pauses records the spans of time during which the habit was paused, in
+	// chronological order.
+	Pauses []Pause `json:"pauses,omitempty"`
+	// Archived is true if the habit is no longer tracked but its history is
+	// kept.
+	Archived bool `json:"archived,omitempty"`
+	// Tags are the categories the habit belongs to, such as "health", in
+	// sorted order.
+	Tags []string `json:"tags,omitempty"`
+	// Routine is the name of the routine the habit belongs to, such as
+	// "morning", so that it can be tracked together with the routine's other
+	// habits. It is empty if the habit is not in a routine.
+	Routine string `json:"routine,omitempty"`
+	// GoalWeights are the weights of the habit in the named goals it counts
+	// toward, such as 2 in "Get fit", keyed by goal name. A habit can count
+	// toward several goals, or none.
+	GoalWeights map[string]float64 `json:"goal_weights,omitempty"`
+	// Goal is the length, in periods of the habit's frequency, of the streak
+	// the habit is being done for, such as 30 for a 30-day challenge. It is
+	// zero if the habit has no goal.
+	Goal int `json:"goal,omitempty"`
+	// Weekdays are the days of the week on which a daily habit must be done,
+	// in order from Sunday, such as Monday, Wednesday and Friday for a gym
+	// habit. The other days are skipped rather than breaking its streak. A
+	// habit without weekdays must be done every day.
+	Weekdays []time.Weekday `json:"weekdays,omitempty"`
+	// Target is the amount that must be logged within each period for a
+	// quantity habit to be done. It is zero for habits that are simply done
+	// or not.
+	Target float64 `json:"target,omitempty"`
+	// Unit is the unit in which a quantity habit's amounts are measured, such
+	// as "glasses".
+	Unit string `json:"unit,omitempty"`
+	// Amount is the amount of a quantity habit logged within the period that
+	// contains AmountAt.
+	Amount float64 `json:"amount,omitempty"`
+	// AmountAt is the timestamp when an amount of a quantity habit was last
+	// logged.
+	AmountAt time.Time `json:"amount_at,omitempty"`
+	// AmountIDs are the idempotency keys of the amounts of a quantity habit
+	// logged within the period that contains AmountAt.
+	AmountIDs []string `json:"amount_ids,omitempty"`
+	// Sessions are the spans of time spent on a duration habit, a quantity
+	// habit whose target is in minutes, in chronological order. The last
+	// session may still be running.
+	Sessions []Session `json:"sessions,omitempty"`
+	// Compacted records the completions pruned from History by Compact. It
+	// is nil if the history was never compacted.
+	Compacted *Compaction `json:"compacted,omitempty"`
+	// Badges are the streak milestones the habit has reached, in the order
+	// they were earned.
+	Badges []Badge `json:"badges,omitempty"`
+	// Points is the number of points the habit has earned when points are
+	// awarded with WithGamification.
+	Points int `json:"points,omitempty"`
+	// Avoid is true if the habit is something to avoid, such as smoking. Its
+	// LastDone and History then record relapses, and its current streak is
+	// the number of days since the last relapse.
+	Avoid bool `json:"avoid,omitempty"`
+	// SnoozedUntil is the time until which reminders of the habit are held
+	// back, set with Snooze. It is nil if the habit was never snoozed.
+	SnoozedUntil *time.Time `json:"snoozed_until,omitempty"`
+	// Undo records the state of the habit before its most recent completion
+	// was tracked. It is nil if there is nothing to undo.
+	Undo *UndoRecord `json:"undo,omitempty"`
+}
+
+// An UndoRecord records the state of a Habit before its most recent completion
+// was tracked, so that the completion can be undone.
+type UndoRecord struct {
+	// Completion is the timestamp of the completion to remove when undoing.
+	Completion time.Time `json:"completion"`
+	// CurrentStreak is the habit's current streak before the completion.
+	CurrentStreak int `json:"current_streak"`
+	// LongestStreak is the habit's longest streak before the completion.
+	LongestStreak int `json:"longest_streak"`
+	// LastDone is the habit's last completion timestamp before the completion.
+	LastDone time.Time `json:"last_done"`
+	// Freezes is the number of streak freezes the habit had left before the
+	// completion.
+	Freezes int `json:"freezes,omitempty"`
+	// Points is the number of points the habit had earned before the
+	// completion.
+	Points int `json:"points,omitempty"`
+}
+
+// A Completion records a single time a Habit was done.
+type Completion struct {
+	// At is the timestamp when the habit was done.
+	At time.Time `json:"at"`
+	// Frozen is true if a streak freeze was used to keep the habit's streak
+	// going despite the period missed before this completion.
+	Frozen bool `json:"frozen,omitempty"`
+	// Note is a freeform comment about the completion. It is empty if no
+	// note was attached.
+	Note string `json:"note,omitempty"`
+	// Amount is the amount of a quantity habit logged within its period when
+	// the completion reached the habit's target. It is zero for completions
+	// of habits that are simply done.
+	Amount float64 `json:"amount,omitempty"`
+	// ID is the idempotency key the completion was tracked with, so that
+	// tracking it again with the same key changes nothing. It is empty if the
+	// completion was tracked without one.
+	ID string `json:"id,omitempty"`
 }
 
 // A Tracker provides habit-tracking and summarization logic.
 type Tracker struct {
 	// output is the io.Writer to write the habit summary output to.
 	output io.Writer
+	// input is the io.Reader to read answers to interactive prompts from.
+	input io.Reader
 	// store is the data repository that stores Habits.
-	store *store
+	store Store
+	// color determines whether output is colorized with ANSI escape sequences.
+	color bool
+	// calendar determines calendar dates, and so the boundaries of each
+	// Habit's periods.
+	calendar calendar
+	// handlers are called with each Event the Tracker emits.
+	handlers []EventHandler
+	// logger receives the Tracker's structured logs.
+	logger *slog.Logger
+	// messages holds the templates of the messages written when a Habit is
+	// tracked.
+	messages *template.Template
+	// gamification determines whether points are awarded when Habits are
+	// done.
+	gamification bool
+	// moodLog is the path of the file that daily mood check-ins are
+	// appended to. Moods cannot be recorded if it is empty.
+	moodLog string
+	// noMoodLog is the error returned for moods if moodLog is empty, or
+	// errNoMoodLog if it is nil.
+	noMoodLog error
+	// deletionLog is the path of the file recording when Habits were
+	// deleted until they are synced. Deletions are not recorded if it is
+	// empty.
+	deletionLog string
 }
 
 // option provides a functional option that can be used in the NewTracker()
@@ -49,9 +206,22 @@ func WithOutput(output io.Writer) option {
 	}
 }
 
-// WithStore accepts a store and returns an option that wires the store to a
-// Tracker.
-func WithStore(store *store) option {
+// WithInput accepts an io.Reader and returns an option that makes a Tracker
+// read answers to interactive prompts, such as those of CheckIn, from the
+// io.Reader.
+func WithInput(input io.Reader) option {
+	return func(t *Tracker) error {
+		if input == nil {
+			return errors.New("input reader must be non-nil")
+		}
+		t.input = input
+		return nil
+	}
+}
+
+// WithStore accepts any Store implementation and returns an option that wires
+// the Store to a Tracker.
+func WithStore(store Store) option {
 	return func(t *Tracker) error {
 		if store == nil {
 			return errors.New("habit store must be non-nil")
@@ -61,19 +231,54 @@ func WithStore(store *store) option {
 	}
 }
 
+// WithColor accepts a bool and returns an option that enables or disables
+// colorized output for a Tracker.
+func WithColor(color bool) option {
+	return func(t *Tracker) error {
+		t.color = color
+		return nil
+	}
+}
+
+// WithLocation accepts a time zone and returns an option that makes a Tracker
+// determine calendar dates, such as whether a Habit was already done today, in
+// that time zone regardless of the time zone its timestamps were stored in.
+func WithLocation(loc *time.Location) option {
+	return func(t *Tracker) error {
+		if loc == nil {
+			return errors.New("location must be non-nil")
+		}
+		t.calendar.location = loc
+		return nil
+	}
+}
+
+// WithDayStartHour accepts an hour from 0 to 23 and returns an option that
+// makes each day start at that hour for a Tracker, so that a Habit done after
+// midnight but before that hour counts toward the previous day.
+func WithDayStartHour(hour int) option {
+	return func(t *Tracker) error {
+		if hour < 0 || hour > 23 {
+			return fmt.Errorf("invalid day start hour %d (want 0 to 23)", hour)
+		}
+		t.calendar.dayStart = hour
+		return nil
+	}
+}
+
 // NewTracker accepts an optional list of options and returns a Tracker
 // initialized with these options. If no options are provided, the Tracker
-// stores its data to a local file "habit.store" and writes to stdout. An error
-// is returned if there is a problem opening the data store or if any of the
-// opts returns an error.
+// stores its data to a local file "habit.store", writes to stdout and starts
+// each day at midnight in the local time zone, which can be set with the TZ
+// environment variable. An error is returned if there is a problem opening the
+// data store or if any of the opts returns an error.
 func NewTracker(opts ...option) (*Tracker, error) {
-	s, err := OpenStore("habit.store")
-	if err != nil {
-		return nil, err
-	}
 	t := &Tracker{
-		output: os.Stdout,
-		store:  s,
+		output:   os.Stdout,
+		input:    os.Stdin,
+		calendar: calendar{location: time.Local},
+		logger:   slog.New(discardHandler{}),
+		messages: parseMessages(),
 	}
 	for _, opt := range opts {
 		err := opt(t)
@@ -81,121 +286,600 @@ func NewTracker(opts ...option) (*Tracker, error) {
 			return nil, err
 		}
 	}
+	if t.store == nil {
+		s, err := OpenStore(DefaultStorePath)
+		if err != nil {
+			return nil, err
+		}
+		t.store = s
+	}
 	return t, nil
 }
 
+// withOutput returns a copy of the Tracker that writes its output to the given
+// io.Writer, sharing the Tracker's store and settings.
+func (t *Tracker) withOutput(output io.Writer) *Tracker {
+	c := *t
+	c.output = output
+	return &c
+}
+
+// withStore returns a copy of the Tracker that reads and changes the habits in
+// the given Store.
+func (t *Tracker) withStore(store Store) *Tracker {
+	c := *t
+	c.store = store
+	return &c
+}
+
+// now returns the current time in the Tracker's location.
+func (t *Tracker) now() time.Time {
+	return Now().In(t.calendar.location)
+}
+
 // Track adds a new Habit to the store or updates an already-existing Habit in
 // the store. An error is returned if an update is attempted on a Habit with a
 // timestamp in the future or if the store cannot be saved after adding/updating
 // a Habit.
 func (t *Tracker) Track(hbtName string) error {
-	now := Now()
-	hbt, ok := t.store.data[hbtName]
-	if !ok {
-		t.store.Add(Habit{
-			Name:          hbtName,
-			CurrentStreak: 1,
-			LastDone:      now,
-		})
-		err := t.store.Save()
+	return t.TrackContext(context.Background(), hbtName)
+}
+
+// TrackContext tracks the Habit with the given name like Track, saving the
+// store under the given context so that a slow store gives up once the context
+// is done.
+func (t *Tracker) TrackContext(ctx context.Context, hbtName string) error {
+	now := t.now()
+	hbt, ok := t.store.Get(hbtName)
+	if ok && now.Before(hbt.LastDone) {
+		return errorOf(ErrFutureTimestamp, "current time %q cannot precede last time habit '%s' was updated on %q",
+			now.Format(time.RFC3339),
+			hbtName,
+			hbt.LastDone.Format(time.RFC3339))
+	}
+	return t.TrackNoteContext(ctx, hbtName, now, "")
+}
+
+// TrackAt records the Habit with the given name as done at the given
+// timestamp, adding the Habit to the store if it does not exist yet. A
+// timestamp before the Habit was last done is inserted into the Habit's
+// history and its streaks are recomputed from the history. An error is
+// returned if the timestamp is in the future, if the Habit is archived, or if
+// the store cannot be saved.
+func (t *Tracker) TrackAt(hbtName string, at time.Time) error {
+	return t.TrackNote(hbtName, at, "")
+}
+
+// TrackNote records the Habit with the given name as done at the given
+// timestamp like TrackAt, attaching the given freeform note, such as "5k in the
+// rain", to the completion. An empty note attaches nothing.
+func (t *Tracker) TrackNote(hbtName string, at time.Time, note string) error {
+	return t.TrackNoteContext(context.Background(), hbtName, at, note)
+}
+
+// TrackNoteContext tracks the Habit with the given name like TrackNote, saving
+// the store under the given context.
+func (t *Tracker) TrackNoteContext(ctx context.Context, hbtName string, at time.Time, note string) error {
+	return t.trackContext(ctx, hbtName, Completion{At: at, Note: note})
+}
+
+// trackContext records the Habit with the given name as done with the given
+// completion, saving the store under the given context.
+func (t *Tracker) trackContext(ctx context.Context, hbtName string, c Completion) error {
+	err := t.checkTrackable(hbtName, c.At)
+	if err != nil {
+		return err
+	}
+	res := t.record(hbtName, c)
+	err = t.saveContext(ctx)
+	if err != nil {
+		return err
+	}
+	t.logTracked(ctx, res.hbt, c.At)
+	fmt.Fprintln(t.output, res.message)
+	for _, e := range res.events {
+		t.emit(e)
+	}
+	return nil
+}
+
+// TrackAll records each of the Habits with the given names as done now, adding
+// any that do not exist yet, saves the store once and writes a single message
+// listing the Habits and their streaks. No Habit is tracked if an error is
+// returned, which happens if no names or a name more than once are given, if
+// any of the Habits cannot be tracked by Track, or if the store cannot be
+// saved.
+func (t *Tracker) TrackAll(hbtNames ...string) error {
+	return t.TrackAllContext(context.Background(), hbtNames...)
+}
+
+// TrackAllContext tracks each of the Habits with the given names like
+// TrackAll, saving the store under the given context.
+func (t *Tracker) TrackAllContext(ctx context.Context, hbtNames ...string) error {
+	if len(hbtNames) < 1 {
+		return errors.New("no habits to track")
+	}
+	now := t.now()
+	seen := map[string]bool{}
+	for _, name := range hbtNames {
+		if seen[name] {
+			return fmt.Errorf("habit '%s' is given more than once", name)
+		}
+		seen[name] = true
+		err := t.checkTrackable(name, now)
 		if err != nil {
 			return err
 		}
-		fmt.Fprintf(t.output, "Congratulations on starting your new habit '%s'! Don't forget to do it again.\n", hbtName)
-		return nil
+		hbt, ok := t.store.Get(name)
+		if ok && now.Before(hbt.LastDone) {
+			return errorOf(ErrFutureTimestamp, "current time %q cannot precede last time habit '%s' was updated on %q",
+				now.Format(time.RFC3339), name, hbt.LastDone.Format(time.RFC3339))
+		}
+	}
+	var results []trackResult
+	for _, name := range hbtNames {
+		results = append(results, t.record(name, Completion{At: now}))
+	}
+	err := t.saveContext(ctx)
+	if err != nil {
+		return err
+	}
+	for _, res := range results {
+		t.logTracked(ctx, res.hbt, now)
+	}
+	if len(results) == 1 {
+		fmt.Fprintln(t.output, results[0].message)
+	} else {
+		summaries := make([]string, len(results))
+		for i, res := range results {
+			summaries[i] = t.text("tracked_habit", MessageData{Name: res.hbt.Name, Summary: res.summary})
+		}
+		fmt.Fprintln(t.output, t.text("tracked_all", MessageData{Count: len(results), List: t.joinWords(summaries, "and")}))
+	}
+	for _, res := range results {
+		for _, e := range res.events {
+			t.emit(e)
+		}
+	}
+	return nil
+}
+
+// checkTrackable returns an error if the Habit with the given name cannot be
+// tracked at the given timestamp, because the timestamp is in the future or the
+// Habit is archived or a habit to avoid.
+func (t *Tracker) checkTrackable(hbtName string, at time.Time) error {
+	if at.After(t.now()) {
+		return errorOf(ErrFutureTimestamp, "cannot track habit '%s' at %q because it is in the future",
+			hbtName, at.Format(time.RFC3339))
+	}
+	hbt, ok := t.store.Get(hbtName)
+	if ok && hbt.Archived {
+		return fmt.Errorf("habit '%s' is archived; unarchive it to track it again", hbtName)
+	}
+	if ok && hbt.Avoid {
+		return fmt.Errorf("habit '%s' is a habit to avoid; use 'habit relapse' if you did it", hbtName)
+	}
+	return nil
+}
+
+// A trackResult describes the outcome of recording a completion of a Habit.
+type trackResult struct {
+	// hbt is the Habit after the completion was recorded.
+	hbt Habit
+	// message is the message written when the Habit is tracked on its own.
+	message string
+	// summary is a short description of the Habit's streak, such as "5-day
+	// streak", written when several Habits are tracked at once.
+	summary string
+	// events are the events to emit once the store has been saved.
+	events []Event
+}
+
+// record records the Habit with the given name, which must be trackable at the
+// timestamp of the given completion, as done with the completion and adds it
+// to the store without saving the store.
+func (t *Tracker) record(hbtName string, c Completion) trackResult {
+	at := c.At
+	hbt, ok := t.store.Get(hbtName)
+	if !ok || hbt.LastDone.IsZero() {
+		if !ok {
+			hbt = Habit{Name: hbtName}
+		}
+		hbt.CurrentStreak = 1
+		hbt.LongestStreak = 1
+		hbt.LastDone = at
+		hbt.History = []Completion{c}
+		hbt.Undo = &UndoRecord{Completion: at, Points: hbt.Points}
+		res := trackResult{
+			message: t.message(messageNewHabit, hbt, 0),
+			summary: t.word("tracked_new_habit"),
+		}
+		if !ok {
+			res.events = append(res.events, Event{Type: EventHabitCreated, Habit: hbtName, Frequency: hbt.Frequency, At: at})
+		}
+		if t.gamification {
+			t.award(&hbt, at, &res)
+		}
+		t.store.Add(hbt)
+		res.hbt = hbt
+		return res
+	}
+	hbt.Undo = &UndoRecord{
+		Completion:    at,
+		CurrentStreak: hbt.CurrentStreak,
+		LongestStreak: hbt.LongestStreak,
+		LastDone:      hbt.LastDone,
+		Freezes:       hbt.Freezes,
+		Points:        hbt.Points,
 	}
-	dayOutput := "days"
-	daysSince := int(now.Sub(hbt.LastDone).Hours() / 24)
-	if daysSince == 1 {
-		dayOutput = "day"
+	if at.Before(hbt.LastDone) {
+		return t.backdate(hbt, c)
 	}
+	missed := hbt.missedPeriods(at, t.calendar)
+	daysSince := t.calendar.daysBetween(hbt.LastDone, at)
+	frozen := false
+	again := false
+	var res trackResult
 	switch {
-	case now.Before(hbt.LastDone):
-		return fmt.Errorf("current time %q cannot precede last time habit '%s' was updated on %q",
-			now.Format(time.RFC3339),
-			hbtName,
-			hbt.LastDone.Format(time.RFC3339))
-	case sameDate(now, hbt.LastDone):
-		fmt.Fprintf(t.output, "Way to go practicing your habit '%s' more than once today!\n",
-			hbtName)
-	case daysSince > 0:
+	case hbt.doneThisPeriod(at, t.calendar):
+		again = true
+		res.message = t.message(messageAgain, hbt, daysSince)
+		res.summary = t.message("tracked_again", hbt, daysSince)
+	case missed == 1 && hbt.Freezes > 0:
+		hbt.Freezes--
+		hbt.CurrentStreak++
+		frozen = true
+		res.message = t.message("freeze", hbt, daysSince)
+		res.summary = t.message("tracked_freeze", hbt, daysSince)
+	case missed >= 1:
+		res.events = append(res.events, Event{Type: EventStreakBroken, Habit: hbtName, Streak: hbt.CurrentStreak,
+			Frequency: hbt.Frequency, At: at})
 		hbt.CurrentStreak = 1
-		fmt.Fprintf(t.output, "You last did the habit '%s' %d %s ago, so you're starting a new streak today. Good luck!\n",
-			hbtName, daysSince, dayOutput)
+		res.message = t.message(messageReset, hbt, daysSince)
+		res.summary = t.word("tracked_reset")
 	default:
 		hbt.CurrentStreak++
-		fmt.Fprintf(t.output, "Nice work: you've done the habit '%s' for %d %s in a row now.\n",
-			hbtName, hbt.CurrentStreak, dayOutput)
+		res.message = t.message(messageStreak, hbt, daysSince)
+		res.summary = t.message("tracked_streak", hbt, daysSince)
+	}
+	if len(res.events) == 0 && hbt.CurrentStreak > hbt.Undo.CurrentStreak && isStreakMilestone(hbt.CurrentStreak) {
+		celebration := "milestone_again"
+		if hbt.celebrate(at) {
+			celebration = "milestone"
+			res.summary += ", " + t.word("tracked_badge")
+		}
+		res.message += " " + t.message(celebration, hbt, daysSince)
+		res.events = append(res.events, Event{Type: EventStreakMilestone, Habit: hbtName, Streak: hbt.CurrentStreak,
+			Frequency: hbt.Frequency, At: at})
+	}
+	if hbt.reachGoal(hbt.Undo.CurrentStreak) {
+		res.message += " " + t.message("goal_reached", hbt, daysSince)
+		res.summary += ", " + t.word("tracked_goal")
+		res.events = append(res.events, Event{Type: EventGoalReached, Habit: hbtName, Streak: hbt.CurrentStreak,
+			Frequency: hbt.Frequency, At: at})
+	}
+	if t.gamification && !again {
+		t.award(&hbt, at, &res)
+	}
+	if hbt.CurrentStreak > hbt.LongestStreak {
+		hbt.LongestStreak = hbt.CurrentStreak
+	}
+	hbt.LastDone = at
+	c.Frozen = frozen
+	hbt.History = append(hbt.History, c)
+	t.store.Add(hbt)
+	res.hbt = hbt
+	return res
+}
+
+// backdate inserts the given completion, which precedes the last time the
+// given Habit was done, into the Habit's history, recomputes its streaks from
+// the history and adds the Habit to the store without saving the store.
+func (t *Tracker) backdate(hbt Habit, c Completion) trackResult {
+	hbt.History = append(slices.Clone(hbt.History), c)
+	sort.SliceStable(hbt.History, func(i, j int) bool {
+		return hbt.History[i].At.Before(hbt.History[j].At)
+	})
+	current, longest := computeStreaks(hbt, t.calendar)
+	hbt.CurrentStreak = current
+	if longest > hbt.LongestStreak {
+		hbt.LongestStreak = longest
 	}
-	hbt.LastDone = now
 	t.store.Add(hbt)
-	err := t.store.Save()
+	data := t.messageData(hbt)
+	data.Date = t.calendar.day(c.At).Format(time.DateOnly)
+	return trackResult{
+		hbt:     hbt,
+		message: t.text("backdated", data),
+		summary: t.text("tracked_backdated", data),
+	}
+}
+
+// joinList joins the given items into an English list, separating the last
+// two items with the given conjunction, such as "and" or "or".
+func joinList(items []string, conjunction string) string {
+	if len(items) < 2 {
+		return strings.Join(items, "")
+	}
+	return strings.Join(items[:len(items)-1], ", ") + " " + conjunction + " " + items[len(items)-1]
+}
+
+// Undo reverses the most recent completion tracked for the Habit with the given
+// name, restoring its previous streaks and last completion timestamp, and saves
+// the store. If the completion was the Habit's only completion, the Habit is
+// removed from the store. Only the most recent completion can be undone. An
+// error is returned if the Habit does not exist, if there is nothing to undo,
+// or if the store cannot be saved.
+func (t *Tracker) Undo(hbtName string) error {
+	hbt, ok := t.store.Get(hbtName)
+	if !ok {
+		return errHabitNotFound(hbtName)
+	}
+	if hbt.Undo == nil {
+		return fmt.Errorf("there is nothing to undo for habit '%s'", hbtName)
+	}
+	rec := hbt.Undo
+	for i := len(hbt.History) - 1; i >= 0; i-- {
+		if hbt.History[i].At.Equal(rec.Completion) {
+			hbt.History = slices.Delete(slices.Clone(hbt.History), i, i+1)
+			break
+		}
+	}
+	if len(hbt.History) == 0 {
+		t.store.Delete(hbtName)
+		err := t.save()
+		if err != nil {
+			return err
+		}
+		err = t.recordDeletion(hbtName)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(t.output, "Undid the only completion of '%s', so the habit has been removed.\n", hbtName)
+		return nil
+	}
+	hbt.CurrentStreak = rec.CurrentStreak
+	hbt.LongestStreak = rec.LongestStreak
+	hbt.LastDone = rec.LastDone
+	hbt.Freezes = rec.Freezes
+	hbt.Points = rec.Points
+	hbt.revokeBadges(rec.Completion)
+	hbt.Undo = nil
+	t.store.Add(hbt)
+	err := t.save()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(t.output, "Undid the last completion of '%s'. You're back on a %d-%s streak.\n",
+		hbtName, hbt.CurrentStreak, hbt.Frequency.unit(1))
+	return nil
+}
+
+// Delete removes the Habit with the given name from the store and saves the
+// store. An error is returned if the Habit does not exist or the store cannot
+// be saved.
+func (t *Tracker) Delete(hbtName string) error {
+	return t.DeleteContext(context.Background(), hbtName)
+}
+
+// DeleteContext deletes the Habit with the given name like Delete, saving the
+// store under the given context.
+func (t *Tracker) DeleteContext(ctx context.Context, hbtName string) error {
+	_, ok := t.store.Get(hbtName)
+	if !ok {
+		return errHabitNotFound(hbtName)
+	}
+	t.store.Delete(hbtName)
+	err := t.saveContext(ctx)
 	if err != nil {
 		return err
 	}
+	err = t.recordDeletion(hbtName)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(t.output, "The habit '%s' has been deleted.\n", hbtName)
 	return nil
 }
 
-// PrintSummary writes a summary of tracked Habits to the given Tracker's output.
-func (t Tracker) PrintSummary() {
-	if len(t.store.data) < 1 {
-		fmt.Fprintln(t.output, "You're not currently tracking any habits.")
-		return
+// Rename renames the Habit with the given old name to the given new name,
+// keeping its streak, and saves the store with a single save. An error is
+// returned if the Habit does not exist, if a Habit with the new name already
+// exists, or if the store cannot be saved.
+func (t *Tracker) Rename(oldName, newName string) error {
+	hbt, ok := t.store.Get(oldName)
+	if !ok {
+		return errHabitNotFound(oldName)
+	}
+	_, ok = t.store.Get(newName)
+	if ok {
+		return fmt.Errorf("habit '%s' already exists", newName)
 	}
-	now := Now()
-	for _, hbt := range t.store.data {
-		daysSince := int(now.Sub(hbt.LastDone).Hours() / 24)
-		if daysSince > 0 {
-			dayOutput := "days"
-			if daysSince == 1 {
-				dayOutput = "day"
+	t.store.Delete(oldName)
+	hbt.Name = newName
+	t.store.Add(hbt)
+	err := t.save()
+	if err != nil {
+		return err
+	}
+	err = t.recordDeletion(oldName)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(t.output, "The habit '%s' has been renamed to '%s'.\n", oldName, newName)
+	return nil
+}
+
+// PrintSummary writes a summary of tracked Habits to the given Tracker's
+// output, sorted by name, with the Habits in each routine grouped under the
+// routine's name after the Habits that are not in a routine, followed by the
+// adherence of each named goal of the summarized Habits. If any tags are
+// given, only the Habits with at least one of the tags are summarized. An
+// error is returned if the summary cannot be written.
+func (t *Tracker) PrintSummary(tags ...string) error {
+	habits := t.sortedHabits(false, tags...)
+	if len(habits) < 1 {
+		_, err := fmt.Fprintln(t.output, t.noHabitsMessage(tags))
+		if err != nil {
+			return fmt.Errorf("error writing summary: %w", err)
+		}
+		return nil
+	}
+	now := t.now()
+	routines, groups := groupByRoutine(habits)
+	for _, routine := range routines {
+		indent := ""
+		if routine != "" {
+			indent = "  "
+			_, err := fmt.Fprintln(t.output, t.text("summary_routine", MessageData{Routine: routine}))
+			if err != nil {
+				return fmt.Errorf("error writing summary: %w", err)
+			}
+		}
+		for _, hbt := range groups[routine] {
+			line := t.summarize(hbt, now)
+			if strength := t.describeStrength(hbt, now); strength != "" {
+				line += " " + strength
+			}
+			if goal := t.describeGoal(hbt, now); goal != "" {
+				line += " " + goal
+			}
+			if len(hbt.Badges) > 0 {
+				line += " " + t.describeBadges(hbt)
+			}
+			_, err := fmt.Fprintln(t.output, indent+line)
+			if err != nil {
+				return fmt.Errorf("error writing summary: %w", err)
 			}
-			fmt.Fprintf(t.output, "It's been %d %s since you did '%s'. Stay positive and get back on it!\n",
-				daysSince, dayOutput, hbt.Name)
-			continue
 		}
-		fmt.Fprintf(t.output, "You are currently on a %d-day streak for '%s'. Keep it going!\n",
-			hbt.CurrentStreak, hbt.Name)
 	}
+	for _, line := range t.summarizeGoals(habits) {
+		_, err := fmt.Fprintln(t.output, line)
+		if err != nil {
+			return fmt.Errorf("error writing summary: %w", err)
+		}
+	}
+	return nil
 }
 
-// Main is the driver for the CLI. It reads command-line arguments and allows
-// a new Habit to be added, an existing Habit to be updated, or a summary of all
-// stored Habits to be printed. It returns an exit code where 0 means the
-// command was successful and anything other than 0 means the command failed.
-func Main() int {
-	flag.Usage = func() {
-		fmt.Println(`Usage: habit <habit-name>
+// summarize returns the summary message for the given Habit as of the given
+// timestamp.
+func (t *Tracker) summarize(hbt Habit, now time.Time) string {
+	if hbt.Avoid {
+		return t.summarizeAvoid(hbt, now)
+	}
+	data := t.messageData(hbt)
+	if hbt.Paused(now) {
+		return t.text("summary_paused", data)
+	}
+	if hbt.Target > 0 {
+		data.Amount = formatAmount(hbt.amountThisPeriod(now, t.calendar), "")
+		data.Target = formatAmount(hbt.Target, hbt.Unit)
+		progress := t.text("summary_amount", data)
+		if hbt.CurrentStreak > 0 && hbt.missedPeriods(now, t.calendar) < 1 {
+			progress += " " + t.text("summary_amount_streak", data)
+		}
+		return progress
+	}
+	if hbt.LastDone.IsZero() {
+		return t.text("summary_not_started", data)
+	}
+	if hbt.missedPeriods(now, t.calendar) >= 1 {
+		data.DaysSince = t.calendar.daysBetween(hbt.LastDone, now)
+		return t.text("summary_broken", data)
+	}
+	if hbt.CurrentStreak > 1 && hbt.CurrentStreak >= hbt.LongestStreak {
+		return t.text("summary_best", data)
+	}
+	return t.text("summary_streak", data)
+}
 
-habit is a tool that helps users track and establish a new habit, by reporting
-their current streak. 
-			
-The default store file is 'habit.store'. This file will be
-created automatically the first time a habbit is set using
-'habit <habit-name>'.`)
+// describeBadges returns the names of the given Habit's Badges for summaries,
+// such as "Badges: 7-day, 30-day.".
+func (t *Tracker) describeBadges(hbt Habit) string {
+	names := make([]string, len(hbt.Badges))
+	for i, b := range hbt.Badges {
+		data := t.messageData(hbt)
+		data.Streak = b.Streak
+		names[i] = t.text("summary_badge", data)
 	}
-	flag.Parse()
-	tracker, err := NewTracker()
-	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		return 1
+	return t.text("summary_badges", MessageData{Count: len(names), List: strings.Join(names, ", ")})
+}
+
+// sortedHabits returns the Habits in the Tracker's store that are archived,
+// or the ones that are not, sorted by name. If any tags are given, only the
+// Habits with at least one of the tags are returned.
+func (t *Tracker) sortedHabits(archived bool, tags ...string) []Habit {
+	var habits []Habit
+	for _, hbt := range t.store.All() {
+		if hbt.Archived == archived && hbt.hasAnyTag(tags) {
+			habits = append(habits, hbt)
+		}
 	}
-	if len(os.Args) > 1 {
-		err = tracker.Track(os.Args[1])
+	sort.Slice(habits, func(i, j int) bool {
+		return habits[i].Name < habits[j].Name
+	})
+	return habits
+}
+
+// PrintList writes each tracked Habit with its current and longest streaks,
+// its frequency, a sparkline of the last 30 days and its tags to the given
+// Tracker's output, sorted by name. If any tags are given, only the Habits
+// with at least one of the tags are listed. An error is returned if the list
+// cannot be written.
+func (t *Tracker) PrintList(tags ...string) error {
+	habits := t.sortedHabits(false, tags...)
+	if len(habits) < 1 {
+		_, err := fmt.Fprintln(t.output, t.noHabitsMessage(tags))
 		if err != nil {
-			fmt.Fprintln(os.Stderr, err)
-			return 1
+			return fmt.Errorf("error writing list: %w", err)
 		}
-		return 0
+		return nil
+	}
+	return t.printHabits(habits)
+}
+
+// noHabitsMessage returns the message reported when no Habits are tracked, or
+// none are tagged with any of the given tags.
+func (t *Tracker) noHabitsMessage(tags []string) string {
+	if len(tags) < 1 {
+		return t.word("no_habits")
 	}
-	tracker.PrintSummary()
-	return 0
+	list := strings.Join(tags, " "+t.word("or")+" ")
+	return t.text("no_habits_tagged", MessageData{Count: len(tags), List: list})
 }
 
-// sameDate accepts 2 timestamps and returns true if they occur on the same
-// calendar date.
-func sameDate(t1, t2 time.Time) bool {
-	t1Year, t1Month, t1Day := t1.Date()
-	t2Year, t2Month, t2Day := t2.Date()
-	return (t2Year == t1Year) && (t2Month == t1Month) && (t2Day == t1Day)
+// printHabits writes each of the given Habits with its current and longest
+// streaks, its frequency, its progress toward its goal, if it has one, a
+// sparkline of the last 30 days unless it is archived, and its tags to the
+// given Tracker's output. An error is returned if they cannot be written.
+func (t *Tracker) printHabits(habits []Habit) error {
+	now := t.now()
+	for _, hbt := range habits {
+		tags := ""
+		if len(hbt.Tags) > 0 {
+			tags = " [" + strings.Join(hbt.Tags, ", ") + "]"
+		}
+		freq := hbt.Frequency.String()
+		if hbt.Avoid {
+			freq = "avoid"
+		} else if len(hbt.Weekdays) > 0 {
+			freq += " " + describeWeekdays(hbt.Weekdays)
+		}
+		goal := ""
+		if hbt.Goal > 0 {
+			goal = fmt.Sprintf(", goal %s %s", hbt.goalProgress(now, t.calendar), progressBar(hbt.goalPercent(now, t.calendar)))
+		}
+		sparkline := ""
+		if !hbt.Archived {
+			sparkline = fmt.Sprintf(", last %d days %s", sparklineDays, hbt.sparkline(now, t.calendar))
+		}
+		current, longest := hbt.streaks(now, t.calendar)
+		_, err := fmt.Fprintf(t.output, "%s: current streak %d, longest streak %d, %s%s%s%s\n",
+			hbt.Name, current, longest, freq, goal, sparkline, tags)
+		if err != nil {
+			return fmt.Errorf("error writing list: %w", err)
+		}
+	}
+	return nil
 }
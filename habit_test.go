@@ -2,7 +2,11 @@ package habit_test
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"io"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -49,7 +53,9 @@ func TestTracker_TrackDoesNotModifyStreakMoreThanOnceOnSameCalendarDay(t *testin
 	store.Add(habit.Habit{
 		Name:          "programming",
 		CurrentStreak: 7,
+		LongestStreak: 7,
 		LastDone:      lastDone,
+		History:       []habit.Completion{{At: lastDone}},
 	})
 	output := io.Discard
 	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output))
@@ -64,7 +70,15 @@ func TestTracker_TrackDoesNotModifyStreakMoreThanOnceOnSameCalendarDay(t *testin
 	want := habit.Habit{
 		Name:          "programming",
 		CurrentStreak: 7,
+		LongestStreak: 7,
 		LastDone:      habit.Now(),
+		History:       []habit.Completion{{At: lastDone}, {At: habit.Now()}},
+		Undo: &habit.UndoRecord{
+			Completion:    habit.Now(),
+			CurrentStreak: 7,
+			LongestStreak: 7,
+			LastDone:      lastDone,
+		},
 	}
 	got, ok := store.Get("programming")
 	if !ok {
@@ -81,7 +95,7 @@ func TestTracker_TrackResetsStreakForHabitsOneOrMoreDaysOld(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	exercisingLastDone, err := time.Parse(time.RFC3339, "2024-02-05T13:05:00Z")
+	exercisingLastDone, err := time.Parse(time.RFC3339, "2024-02-04T23:55:00Z")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -94,27 +108,47 @@ func TestTracker_TrackResetsStreakForHabitsOneOrMoreDaysOld(t *testing.T) {
 			input: habit.Habit{
 				Name:          "programming",
 				CurrentStreak: 5,
+				LongestStreak: 5,
 				LastDone:      programmingLastDone,
+				History:       []habit.Completion{{At: programmingLastDone}},
 			},
 			wantHabit: habit.Habit{
 				Name:          "programming",
 				CurrentStreak: 1,
+				LongestStreak: 5,
 				LastDone:      habit.Now(),
+				History:       []habit.Completion{{At: programmingLastDone}, {At: habit.Now()}},
+				Undo: &habit.UndoRecord{
+					Completion:    habit.Now(),
+					CurrentStreak: 5,
+					LongestStreak: 5,
+					LastDone:      programmingLastDone,
+				},
 			},
 			wantOutput: "You last did the habit 'programming' 2 days ago, so you're starting a new streak today. Good luck!\n",
 		},
-		"Habit last done exactly 1 day ago resets streak": {
+		"Habit last done under 2 days ago but before the previous calendar day resets streak": {
 			input: habit.Habit{
 				Name:          "exercising",
 				CurrentStreak: 5,
+				LongestStreak: 5,
 				LastDone:      exercisingLastDone,
+				History:       []habit.Completion{{At: exercisingLastDone}},
 			},
 			wantHabit: habit.Habit{
 				Name:          "exercising",
 				CurrentStreak: 1,
+				LongestStreak: 5,
 				LastDone:      habit.Now(),
+				History:       []habit.Completion{{At: exercisingLastDone}, {At: habit.Now()}},
+				Undo: &habit.UndoRecord{
+					Completion:    habit.Now(),
+					CurrentStreak: 5,
+					LongestStreak: 5,
+					LastDone:      exercisingLastDone,
+				},
 			},
-			wantOutput: "You last did the habit 'exercising' 1 day ago, so you're starting a new streak today. Good luck!\n",
+			wantOutput: "You last did the habit 'exercising' 2 days ago, so you're starting a new streak today. Good luck!\n",
 		},
 	}
 	for name, tc := range testCases {
@@ -161,7 +195,9 @@ func TestTracker_TrackCorrectlyIncrementsStreakForHabitLessThan1DayOld(t *testin
 	store.Add(habit.Habit{
 		Name:          "programming",
 		CurrentStreak: 1,
+		LongestStreak: 1,
 		LastDone:      lastDone,
+		History:       []habit.Completion{{At: lastDone}},
 	})
 	output := new(bytes.Buffer)
 	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output))
@@ -176,7 +212,15 @@ func TestTracker_TrackCorrectlyIncrementsStreakForHabitLessThan1DayOld(t *testin
 	want := habit.Habit{
 		Name:          "programming",
 		CurrentStreak: 2,
+		LongestStreak: 2,
 		LastDone:      habit.Now(),
+		History:       []habit.Completion{{At: lastDone}, {At: habit.Now()}},
+		Undo: &habit.UndoRecord{
+			Completion:    habit.Now(),
+			CurrentStreak: 1,
+			LongestStreak: 1,
+			LastDone:      lastDone,
+		},
 	}
 	got, ok := store.Get("programming")
 	if !ok {
@@ -199,14 +243,14 @@ func TestTracker_PrintSummaryPrintsExpectedMessageForHabitsWithExpiredStreaks(t
 		t.Fatal(err)
 	}
 	habit.Now = getTimeFunc(t, "2024-02-06T13:00:00Z")
-	oneDayAgo, err := time.Parse(time.RFC3339, "2024-02-05T12:30:00Z")
+	twoDaysAgo, err := time.Parse(time.RFC3339, "2024-02-04T12:30:00Z")
 	if err != nil {
 		t.Fatal(err)
 	}
 	store.Add(habit.Habit{
 		Name:          "programming",
 		CurrentStreak: 1,
-		LastDone:      oneDayAgo,
+		LastDone:      twoDaysAgo,
 	})
 	threeDaysAgo, err := time.Parse(time.RFC3339, "2024-02-03T13:00:00Z")
 	if err != nil {
@@ -222,9 +266,12 @@ func TestTracker_PrintSummaryPrintsExpectedMessageForHabitsWithExpiredStreaks(t
 	if err != nil {
 		t.Fatal(err)
 	}
-	tracker.PrintSummary()
+	err = tracker.PrintSummary()
+	if err != nil {
+		t.Fatal(err)
+	}
 	wantSubstrings := []string{
-		"It's been 1 day since you did 'programming'. Stay positive and get back on it!\n",
+		"It's been 2 days since you did 'programming'. Stay positive and get back on it!\n",
 		"It's been 3 days since you did 'exercising'. Stay positive and get back on it!\n",
 	}
 	got := output.String()
@@ -235,16 +282,540 @@ func TestTracker_PrintSummaryPrintsExpectedMessageForHabitsWithExpiredStreaks(t
 	}
 }
 
+func TestTracker_PrintSummaryPrintsHabitsSortedByName(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T13:00:00Z")
+	store, err := habit.OpenStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"running", "exercising", "programming", "reading"} {
+		store.Add(habit.Habit{
+			Name:          name,
+			CurrentStreak: 1,
+			LongestStreak: 1,
+			LastDone:      habit.Now(),
+		})
+	}
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithOutput(output), habit.WithStore(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.PrintSummary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "You are currently on a 1-day streak for 'exercising'. Keep it going!\n" +
+		"You are currently on a 1-day streak for 'programming'. Keep it going!\n" +
+		"You are currently on a 1-day streak for 'reading'. Keep it going!\n" +
+		"You are currently on a 1-day streak for 'running'. Keep it going!\n"
+	got := output.String()
+	if want != got {
+		t.Errorf("want output %q, got output %q", want, got)
+	}
+}
+
+// errWriter is an io.Writer that always fails.
+type errWriter struct{}
+
+func (errWriter) Write([]byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestTracker_PrintSummaryReturnsErrorIfOutputCannotBeWritten(t *testing.T) {
+	t.Parallel()
+	store, err := habit.OpenStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{Name: "programming"})
+	tracker, err := habit.NewTracker(habit.WithOutput(errWriter{}), habit.WithStore(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.PrintSummary()
+	if err == nil {
+		t.Error("expected an error when summary cannot be written")
+	}
+}
+
+func TestTracker_PrintListReturnsErrorIfOutputCannotBeWritten(t *testing.T) {
+	t.Parallel()
+	store, err := habit.OpenStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker, err := habit.NewTracker(habit.WithOutput(errWriter{}), habit.WithStore(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.PrintList()
+	if err == nil {
+		t.Error("expected an error when the message for an empty list cannot be written")
+	}
+	store.Add(habit.Habit{Name: "programming"})
+	err = tracker.PrintList()
+	if err == nil {
+		t.Error("expected an error when list cannot be written")
+	}
+}
+
+func TestTracker_TrackAppendsEveryCompletionToHistory(t *testing.T) {
+	store, err := habit.OpenStore(t.TempDir() + "/test.store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(io.Discard))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want []habit.Completion
+	for _, timestamp := range []string{"2024-02-05T08:00:00Z", "2024-02-05T20:00:00Z", "2024-02-06T07:00:00Z"} {
+		habit.Now = getTimeFunc(t, timestamp)
+		err = tracker.Track("programming")
+		if err != nil {
+			t.Fatal(err)
+		}
+		want = append(want, habit.Completion{At: habit.Now()})
+	}
+	got, ok := store.Get("programming")
+	if !ok {
+		t.Fatal("expected habit 'programming' to be present in store")
+	}
+	if !cmp.Equal(want, got.History) {
+		t.Error(cmp.Diff(want, got.History))
+	}
+}
+
+func TestTracker_TrackAtRecomputesStreakForBackdatedCompletion(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-05T09:00:00Z")
+	first, err := time.Parse(time.RFC3339, "2024-02-03T20:00:00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	last, err := time.Parse(time.RFC3339, "2024-02-05T08:00:00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	backdated, err := time.Parse(time.RFC3339, "2024-02-04T14:00:00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := habit.OpenStore(t.TempDir() + "/test.store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{
+		Name:          "programming",
+		CurrentStreak: 1,
+		LongestStreak: 1,
+		LastDone:      last,
+		History:       []habit.Completion{{At: first}, {At: last}},
+	})
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.TrackAt("programming", backdated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := habit.Habit{
+		Name:          "programming",
+		CurrentStreak: 3,
+		LongestStreak: 3,
+		LastDone:      last,
+		History:       []habit.Completion{{At: first}, {At: backdated}, {At: last}},
+		Undo: &habit.UndoRecord{
+			Completion:    backdated,
+			CurrentStreak: 1,
+			LongestStreak: 1,
+			LastDone:      last,
+		},
+	}
+	got, ok := store.Get("programming")
+	if !ok {
+		t.Fatal("expected habit 'programming' to be present in store")
+	}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+	wantOutput := "Logged the habit 'programming' as done on 2024-02-04. You're now on a 3-day streak.\n"
+	gotOutput := output.String()
+	if wantOutput != gotOutput {
+		t.Errorf("want output %q, got output %q", wantOutput, gotOutput)
+	}
+}
+
+func TestTracker_TrackAtReturnsErrorForFutureTimestamp(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-05T09:00:00Z")
+	store, err := habit.OpenStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(io.Discard))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.TrackAt("programming", habit.Now().Add(time.Hour))
+	if err == nil {
+		t.Error("expected an error when tracking a habit in the future")
+	}
+}
+
+func TestTracker_TrackDoesNotLowerLongestStreak(t *testing.T) {
+	lastDone, err := time.Parse(time.RFC3339, "2024-02-05T13:00:00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := habit.OpenStore(t.TempDir() + "/test.store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{
+		Name:          "programming",
+		CurrentStreak: 3,
+		LongestStreak: 10,
+		LastDone:      lastDone,
+	})
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(io.Discard))
+	if err != nil {
+		t.Fatal(err)
+	}
+	habit.Now = getTimeFunc(t, "2024-02-06T12:59:00Z")
+	err = tracker.Track("programming")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, ok := store.Get("programming")
+	if !ok {
+		t.Fatal("expected habit 'programming' to be present in store")
+	}
+	if got.LongestStreak != 10 {
+		t.Errorf("want longest streak 10, got %d", got.LongestStreak)
+	}
+}
+
+func TestTracker_PrintSummaryAnnouncesNewPersonalBest(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T13:00:00Z")
+	store, err := habit.OpenStore(t.TempDir() + "/test.store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{
+		Name:          "programming",
+		CurrentStreak: 6,
+		LongestStreak: 6,
+		LastDone:      habit.Now(),
+	})
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithOutput(output), habit.WithStore(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.PrintSummary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "You are currently on a 6-day streak for 'programming'. That's a new personal best. Keep it going!\n"
+	got := output.String()
+	if want != got {
+		t.Errorf("want output %q, got output %q", want, got)
+	}
+}
+
+func TestTracker_UndoRestoresStateBeforeMostRecentCompletion(t *testing.T) {
+	lastDone, err := time.Parse(time.RFC3339, "2024-02-05T13:00:00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := habit.OpenStore(t.TempDir() + "/test.store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := habit.Habit{
+		Name:          "programming",
+		CurrentStreak: 4,
+		LongestStreak: 4,
+		LastDone:      lastDone,
+		History:       []habit.Completion{{At: lastDone}},
+	}
+	store.Add(before)
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	habit.Now = getTimeFunc(t, "2024-02-06T12:00:00Z")
+	err = tracker.Track("programming")
+	if err != nil {
+		t.Fatal(err)
+	}
+	output.Reset()
+	err = tracker.Undo("programming")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, ok := store.Get("programming")
+	if !ok {
+		t.Fatal("expected habit 'programming' to be present in store")
+	}
+	if !cmp.Equal(before, got) {
+		t.Error(cmp.Diff(before, got))
+	}
+	wantOutput := "Undid the last completion of 'programming'. You're back on a 4-day streak.\n"
+	gotOutput := output.String()
+	if wantOutput != gotOutput {
+		t.Errorf("want output %q, got output %q", wantOutput, gotOutput)
+	}
+	err = tracker.Undo("programming")
+	if err == nil {
+		t.Error("expected an error when undoing a habit twice")
+	}
+}
+
+func TestTracker_UndoRemovesHabitWithOnlyOneCompletion(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T12:00:00Z")
+	store, err := habit.OpenStore(t.TempDir() + "/test.store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(io.Discard))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Track("programming")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Undo("programming")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, ok := store.Get("programming")
+	if ok {
+		t.Error("expected habit 'programming' to be removed from store")
+	}
+}
+
+func TestTracker_UndoOfBackdatedCompletionLeavesStoredHistoryIntact(t *testing.T) {
+	days := []time.Time{
+		time.Date(2024, time.February, 1, 9, 0, 0, 0, time.UTC),
+		time.Date(2024, time.February, 3, 9, 0, 0, 0, time.UTC),
+		time.Date(2024, time.February, 6, 9, 0, 0, 0, time.UTC),
+	}
+	// newStore returns a store in which 'programming' was done on the first
+	// and last days and then backdated to the middle day.
+	newStore := func() *memStore {
+		store := &memStore{habits: map[string]habit.Habit{}}
+		tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(io.Discard))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, at := range []time.Time{days[0], days[2], days[1]} {
+			err = tracker.TrackAt("programming", at)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		return store
+	}
+	habit.Now = getTimeFunc(t, "2024-02-06T12:00:00Z")
+	wantAfter := []habit.Completion{{At: days[0]}, {At: days[2]}}
+
+	logPath := filepath.Join(t.TempDir(), "audit.log")
+	store := newStore()
+	tracker, err := habit.NewTracker(habit.WithStore(habit.WithAuditLog(store, logPath, "undo")), habit.WithOutput(io.Discard))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Undo("programming")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := store.habits["programming"].History; !cmp.Equal(wantAfter, got) {
+		t.Error(cmp.Diff(wantAfter, got))
+	}
+	f, err := os.Open(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	entries, err := habit.ReadAuditLog(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Removed != 2 || !cmp.Equal(wantAfter[1:], entries[0].Added) {
+		t.Errorf("want the last 2 completions replaced by the last day, got %+v", entries)
+	}
+
+	preview := habit.NewPreview(newStore())
+	tracker, err = habit.NewTracker(habit.WithStore(preview), habit.WithOutput(io.Discard))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Undo("programming")
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes := preview.Changes()
+	if len(changes) != 1 {
+		t.Fatalf("want 1 change, got %+v", changes)
+	}
+	wantBefore := []habit.Completion{{At: days[0]}, {At: days[1]}, {At: days[2]}}
+	if got := changes[0].Before.History; !cmp.Equal(wantBefore, got) {
+		t.Error(cmp.Diff(wantBefore, got))
+	}
+	if got := changes[0].After.History; !cmp.Equal(wantAfter, got) {
+		t.Error(cmp.Diff(wantAfter, got))
+	}
+}
+
+func TestTracker_DeleteRemovesHabitAndSavesStore(t *testing.T) {
+	t.Parallel()
+	path := t.TempDir() + "/test.store"
+	store, err := habit.OpenStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{Name: "programming"})
+	store.Add(habit.Habit{Name: "exercising"})
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Delete("programming")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store2, err := habit.OpenStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []habit.Habit{{Name: "exercising"}}
+	got := store2.All()
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+	wantOutput := "The habit 'programming' has been deleted.\n"
+	gotOutput := output.String()
+	if wantOutput != gotOutput {
+		t.Errorf("want output %q, got output %q", wantOutput, gotOutput)
+	}
+}
+
+func TestTracker_DeleteReturnsErrorForNonExistentHabit(t *testing.T) {
+	t.Parallel()
+	store, err := habit.OpenStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker, err := habit.NewTracker(habit.WithStore(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Delete("nonexistent")
+	if err == nil {
+		t.Error("expected an error when deleting non-existent habit")
+	}
+}
+
+func TestTracker_TrackContextDoesNotSaveGivenCancelledContext(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T12:00:00Z")
+	store := &memStore{habits: map[string]habit.Habit{}}
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = tracker.TrackContext(ctx, "programming")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("want error %v, got %v", context.Canceled, err)
+	}
+	if store.saves != 0 {
+		t.Errorf("want store not to be saved, got %d saves", store.saves)
+	}
+	if output.Len() != 0 {
+		t.Errorf("want no output, got %q", output.String())
+	}
+}
+
+func TestTracker_RenameKeepsStreakUnderNewName(t *testing.T) {
+	t.Parallel()
+	path := t.TempDir() + "/test.store"
+	store, err := habit.OpenStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{Name: "programing", CurrentStreak: 4, LongestStreak: 6})
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(io.Discard))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Rename("programing", "programming")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store2, err := habit.OpenStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []habit.Habit{{Name: "programming", CurrentStreak: 4, LongestStreak: 6}}
+	got := store2.All()
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestTracker_RenameReturnsErrorIfTargetNameExists(t *testing.T) {
+	t.Parallel()
+	store, err := habit.OpenStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{Name: "programing", CurrentStreak: 4})
+	store.Add(habit.Habit{Name: "programming", CurrentStreak: 1})
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(io.Discard))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Rename("programing", "programming")
+	if err == nil {
+		t.Fatal("expected an error when renaming habit to an existing name")
+	}
+	want := []habit.Habit{
+		{Name: "programing", CurrentStreak: 4},
+		{Name: "programming", CurrentStreak: 1},
+	}
+	got := store.All()
+	if !cmp.Equal(want, got, habitSliceCmpOpt) {
+		t.Error(cmp.Diff(want, got, habitSliceCmpOpt))
+	}
+}
+
 func TestMain(m *testing.M) {
-	testscript.RunMain(m, map[string]func() int{
+	// Trackers determine calendar dates in the local time zone by default, so
+	// pin it to keep day boundaries the same on every machine.
+	time.Local = time.UTC
+	os.Exit(testscript.RunMain(m, map[string]func() int{
 		"habit": habit.Main,
-	})
+	}))
 }
 
 func Test(t *testing.T) {
 	habit.Now = getTimeFunc(t, "2024-01-02T00:00:30Z")
 	testscript.Run(t, testscript.Params{
 		Dir: "testdata/script",
+		Setup: func(env *testscript.Env) error {
+			// Scripts that depend on the time of day track habits at a fixed
+			// time yesterday rather than whenever the test happens to run.
+			env.Setenv("YESTERDAY", time.Now().UTC().AddDate(0, 0, -1).Format(time.DateOnly))
+			return nil
+		},
 	})
 }
 
@@ -258,3 +829,230 @@ func getTimeFunc(t *testing.T, timestamp string) func() time.Time {
 		return testTime
 	}
 }
+
+// memStore is a minimal in-memory Store used to verify that a Tracker accepts
+// any Store implementation.
+type memStore struct {
+	habits map[string]habit.Habit
+	saves  int
+}
+
+func (m *memStore) Get(name string) (habit.Habit, bool) {
+	h, ok := m.habits[name]
+	return h, ok
+}
+
+func (m *memStore) Add(h habit.Habit) {
+	m.habits[h.Name] = h
+}
+
+func (m *memStore) Delete(name string) {
+	delete(m.habits, name)
+}
+
+func (m *memStore) All() []habit.Habit {
+	var habits []habit.Habit
+	for _, h := range m.habits {
+		habits = append(habits, h)
+	}
+	return habits
+}
+
+func (m *memStore) Save() error {
+	m.saves++
+	return nil
+}
+
+func TestTracker_TrackUsesCustomStoreImplementation(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T13:00:00Z")
+	store := &memStore{habits: map[string]habit.Habit{}}
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(io.Discard))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Track("programming")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := habit.Habit{
+		Name:          "programming",
+		CurrentStreak: 1,
+		LongestStreak: 1,
+		LastDone:      habit.Now(),
+		History:       []habit.Completion{{At: habit.Now()}},
+		Undo:          &habit.UndoRecord{Completion: habit.Now()},
+	}
+	got, ok := store.Get("programming")
+	if !ok {
+		t.Fatal("expected habit 'programming' to be present in custom store")
+	}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+	if store.saves != 1 {
+		t.Errorf("want custom store to be saved once, got %d saves", store.saves)
+	}
+}
+
+func TestTracker_TrackAllSavesStoreOnceAndWritesOneMessage(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T13:00:00Z")
+	now := habit.Now()
+	store := &memStore{habits: map[string]habit.Habit{
+		"programming": {Name: "programming", CurrentStreak: 4, LongestStreak: 4, LastDone: now.Add(-20 * time.Hour)},
+		"reading":     {Name: "reading", CurrentStreak: 9, LongestStreak: 9, LastDone: now.Add(-72 * time.Hour)},
+	}}
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.TrackAll("programming", "exercising", "reading")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if store.saves != 1 {
+		t.Errorf("want store to be saved once, got %d saves", store.saves)
+	}
+	want := "Nice work! You tracked 3 habits: 'programming' (5-day streak), 'exercising' (new habit) and 'reading' (new streak).\n"
+	if got := output.String(); want != got {
+		t.Error(cmp.Diff(want, got))
+	}
+	for name, streak := range map[string]int{"programming": 5, "exercising": 1, "reading": 1} {
+		hbt, ok := store.Get(name)
+		if !ok {
+			t.Fatalf("expected habit '%s' to be present in store", name)
+		}
+		if hbt.CurrentStreak != streak || !hbt.LastDone.Equal(now) {
+			t.Errorf("%s: want streak %d last done %v, got streak %d last done %v",
+				name, streak, now, hbt.CurrentStreak, hbt.LastDone)
+		}
+	}
+}
+
+func TestTracker_TrackAllTracksNothingIfAnyHabitCannotBeTracked(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T13:00:00Z")
+	testCases := map[string][]string{
+		"archived habit": {"programming", "cycling"},
+		"avoided habit":  {"programming", "smoking"},
+		"repeated habit": {"programming", "reading", "programming"},
+		"no habits":      nil,
+	}
+	for desc, names := range testCases {
+		store := &memStore{habits: map[string]habit.Habit{
+			"cycling": {Name: "cycling", Archived: true},
+			"smoking": {Name: "smoking", Avoid: true},
+		}}
+		tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(io.Discard))
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = tracker.TrackAll(names...)
+		if err == nil {
+			t.Errorf("%s: want error, got nil", desc)
+		}
+		if _, ok := store.Get("programming"); ok || store.saves != 0 {
+			t.Errorf("%s: want nothing tracked, got %d saves", desc, store.saves)
+		}
+	}
+}
+
+func TestWithStoreReturnsErrorForNilStore(t *testing.T) {
+	t.Parallel()
+	_, err := habit.NewTracker(habit.WithStore(nil))
+	if err == nil {
+		t.Error("expected an error when creating tracker with nil store")
+	}
+}
+
+func TestWithLocationReturnsErrorForNilLocation(t *testing.T) {
+	t.Parallel()
+	_, err := habit.NewTracker(habit.WithLocation(nil), habit.WithStore(&memStore{}))
+	if err == nil {
+		t.Error("expected an error when creating tracker with nil location")
+	}
+}
+
+func TestTracker_TrackDeterminesCalendarDaysInTrackerLocation(t *testing.T) {
+	// 03:00 UTC on February 6 is still February 5 in New York.
+	lastDone, err := time.Parse(time.RFC3339, "2024-02-06T03:00:00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := habit.OpenStore(t.TempDir() + "/test.store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{
+		Name:          "programming",
+		CurrentStreak: 1,
+		LongestStreak: 1,
+		LastDone:      lastDone,
+		History:       []habit.Completion{{At: lastDone}},
+	})
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(
+		habit.WithStore(store),
+		habit.WithOutput(output),
+		habit.WithLocation(time.FixedZone("EST", -5*60*60)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	habit.Now = getTimeFunc(t, "2024-02-06T14:00:00Z")
+	err = tracker.Track("programming")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Nice work: you've done the habit 'programming' for 2 days in a row now.\n"
+	got := output.String()
+	if want != got {
+		t.Errorf("want output %q, got output %q", want, got)
+	}
+}
+
+func TestWithDayStartHourReturnsErrorForInvalidHour(t *testing.T) {
+	t.Parallel()
+	for _, hour := range []int{-1, 24} {
+		_, err := habit.NewTracker(habit.WithDayStartHour(hour), habit.WithStore(&memStore{}))
+		if err == nil {
+			t.Errorf("expected an error when creating tracker with day start hour %d", hour)
+		}
+	}
+}
+
+func TestTracker_TrackCountsHabitDoneBeforeDayStartTowardPreviousDay(t *testing.T) {
+	lastDone, err := time.Parse(time.RFC3339, "2024-02-05T22:00:00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := habit.OpenStore(t.TempDir() + "/test.store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{
+		Name:          "programming",
+		CurrentStreak: 1,
+		LongestStreak: 1,
+		LastDone:      lastDone,
+		History:       []habit.Completion{{At: lastDone}},
+	})
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(
+		habit.WithStore(store),
+		habit.WithOutput(output),
+		habit.WithDayStartHour(4),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	habit.Now = getTimeFunc(t, "2024-02-06T01:30:00Z")
+	err = tracker.Track("programming")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Way to go practicing your habit 'programming' more than once today!\n"
+	got := output.String()
+	if want != got {
+		t.Errorf("want output %q, got output %q", want, got)
+	}
+}
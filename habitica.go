@@ -0,0 +1,291 @@
+package habit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultHabiticaURL is the base URL of the Habitica API.
+const DefaultHabiticaURL = "https://habitica.com/api/v3"
+
+// habiticaTimeout is how long ImportHabitica waits for each response from the
+// Habitica API.
+const habiticaTimeout = 30 * time.Second
+
+// A habiticaTask is a task of a Habitica user, as returned by the Habitica
+// API. Only the fields that are imported are decoded.
+type habiticaTask struct {
+	Type      string         `json:"type"`
+	Text      string         `json:"text"`
+	Tags      []string       `json:"tags"`
+	CreatedAt time.Time      `json:"createdAt"`
+	Up        bool           `json:"up"`
+	Down      bool           `json:"down"`
+	Frequency string         `json:"frequency"`
+	EveryX    int            `json:"everyX"`
+	Repeat    map[string]any `json:"repeat"`
+	Streak    int            `json:"streak"`
+	History   []habiticaDay  `json:"history"`
+}
+
+// A habiticaDay is an entry in the history of a Habitica task, recorded each
+// day the task's value changed.
+type habiticaDay struct {
+	Date  habiticaTime `json:"date"`
+	Value float64      `json:"value"`
+	// Completed is set for dailies, and is nil in older histories.
+	Completed *bool `json:"completed"`
+	// ScoredUp and ScoredDown are set for habits, and are nil in older
+	// histories.
+	ScoredUp   *int `json:"scoredUp"`
+	ScoredDown *int `json:"scoredDown"`
+}
+
+// A habiticaTime is a timestamp in a Habitica task history, which is a number
+// of milliseconds since the Unix epoch or, in older histories, a date string.
+type habiticaTime struct {
+	time.Time
+}
+
+// UnmarshalJSON decodes a Habitica history timestamp.
+func (h *habiticaTime) UnmarshalJSON(data []byte) error {
+	var millis float64
+	if err := json.Unmarshal(data, &millis); err == nil {
+		h.Time = time.UnixMilli(int64(millis)).UTC()
+		return nil
+	}
+	var value string
+	err := json.Unmarshal(data, &value)
+	if err != nil {
+		return fmt.Errorf("invalid Habitica timestamp %s", data)
+	}
+	if millis, err := strconv.ParseInt(value, 10, 64); err == nil {
+		h.Time = time.UnixMilli(millis).UTC()
+		return nil
+	}
+	h.Time, err = time.Parse(time.RFC3339, value)
+	if err != nil {
+		return fmt.Errorf("invalid Habitica timestamp %q", value)
+	}
+	return nil
+}
+
+// habiticaWeekdays are the keys of a Habitica daily's repeat days.
+var habiticaWeekdays = map[string]time.Weekday{
+	"su": time.Sunday,
+	"m":  time.Monday,
+	"t":  time.Tuesday,
+	"w":  time.Wednesday,
+	"th": time.Thursday,
+	"f":  time.Friday,
+	"s":  time.Saturday,
+}
+
+// ImportHabitica fetches the habits and dailies of the Habitica user with the
+// given user ID and API token from the Habitica API at the given base URL,
+// such as DefaultHabiticaURL, and adds them to the Tracker's store with their
+// histories, tags and streaks, resolving name collisions with the given
+// MergeStrategy. Dailies and habits scored up become Habits, and habits only
+// scored down become Habits to avoid, whose relapses are the times they were
+// scored down. To-dos and rewards are not imported. An error is returned if
+// the tasks cannot be fetched or the store cannot be saved.
+func (t *Tracker) ImportHabitica(ctx context.Context, baseURL, user, token string, strategy MergeStrategy) error {
+	client := &http.Client{Timeout: habiticaTimeout}
+	var tasks []habiticaTask
+	err := getHabitica(ctx, client, baseURL+"/tasks/user", user, token, &tasks)
+	if err != nil {
+		return fmt.Errorf("error fetching Habitica tasks: %w", err)
+	}
+	var tags []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+	err = getHabitica(ctx, client, baseURL+"/tags", user, token, &tags)
+	if err != nil {
+		return fmt.Errorf("error fetching Habitica tags: %w", err)
+	}
+	tagNames := map[string]string{}
+	for _, tag := range tags {
+		tagNames[tag.ID] = tag.Name
+	}
+	imported := map[string]Habit{}
+	for _, task := range tasks {
+		hbt, ok := task.habit(tagNames, t.calendar)
+		if !ok {
+			continue
+		}
+		addUnique(imported, hbt)
+	}
+	return t.importHabits(imported, strategy)
+}
+
+// getHabitica gets the given URL of the Habitica API, authenticating as the
+// given user with the given API token, and decodes the data of the response
+// into v.
+func getHabitica(ctx context.Context, client *http.Client, url, user, token string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("x-api-user", user)
+	req.Header.Set("x-api-key", token)
+	// Habitica asks third-party tools to identify themselves.
+	req.Header.Set("x-client", user+"-habit")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	var body struct {
+		Success bool            `json:"success"`
+		Data    json.RawMessage `json:"data"`
+		Message string          `json:"message"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&body)
+	if resp.StatusCode != http.StatusOK {
+		if body.Message != "" {
+			return fmt.Errorf("%s: %s", resp.Status, body.Message)
+		}
+		return fmt.Errorf("unexpected response %s", resp.Status)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid response: %w", err)
+	}
+	if !body.Success {
+		return fmt.Errorf("request failed: %s", body.Message)
+	}
+	return json.Unmarshal(body.Data, v)
+}
+
+// habit returns the Habit imported from the Habitica task, with the names of
+// its tags looked up in the given tag names and its streaks computed with the
+// given calendar, and reports whether the task is imported at all.
+func (task habiticaTask) habit(tagNames map[string]string, cal calendar) (Habit, bool) {
+	name := strings.TrimSpace(task.Text)
+	if name == "" {
+		return Habit{}, false
+	}
+	hbt := Habit{Name: name}
+	for _, id := range task.Tags {
+		if tag, ok := tagNames[id]; ok {
+			hbt.Tags = append(hbt.Tags, tag)
+		}
+	}
+	sort.Strings(hbt.Tags)
+	switch {
+	case task.Type == "daily":
+		hbt.Frequency, hbt.Weekdays = task.schedule()
+		hbt.History = task.completions(func(day habiticaDay, prev float64) int {
+			if day.Completed != nil {
+				if *day.Completed {
+					return 1
+				}
+				return 0
+			}
+			// Older histories only record the value, which rises when the
+			// daily is done.
+			if day.Value > prev {
+				return 1
+			}
+			return 0
+		})
+	case task.Type == "habit" && (task.Up || !task.Down):
+		hbt.History = task.completions(func(day habiticaDay, prev float64) int {
+			if day.ScoredUp != nil {
+				return *day.ScoredUp
+			}
+			if day.Value > prev {
+				return 1
+			}
+			return 0
+		})
+	case task.Type == "habit":
+		hbt.Avoid = true
+		hbt.History = task.completions(func(day habiticaDay, prev float64) int {
+			if day.ScoredDown != nil {
+				return *day.ScoredDown
+			}
+			if day.Value < prev {
+				return 1
+			}
+			return 0
+		})
+		hbt.LastDone = task.CreatedAt
+		since := task.CreatedAt
+		for _, c := range hbt.History {
+			hbt.LongestStreak = max(hbt.LongestStreak, cal.daysBetween(since, c.At))
+			since = c.At
+		}
+		if len(hbt.History) > 0 {
+			hbt.LastDone = hbt.History[len(hbt.History)-1].At
+		}
+		return hbt, true
+	default:
+		return Habit{}, false
+	}
+	if len(hbt.History) > 0 {
+		hbt.CurrentStreak, hbt.LongestStreak = computeStreaks(hbt, cal)
+		hbt.LastDone = hbt.History[len(hbt.History)-1].At
+	}
+	// Habitica's own streak of a daily counts days its history may no longer
+	// hold in full.
+	if task.Streak > hbt.CurrentStreak {
+		hbt.CurrentStreak = task.Streak
+		hbt.LongestStreak = max(hbt.LongestStreak, task.Streak)
+	}
+	return hbt, true
+}
+
+// schedule returns the Frequency and weekdays of a Habitica daily. Dailies due
+// every few months or years are given the Frequency of the same number of days.
+func (task habiticaTask) schedule() (Frequency, []time.Weekday) {
+	every := max(task.EveryX, 1)
+	switch task.Frequency {
+	case "weekly":
+		if every > 1 {
+			return Frequency(7 * every), nil
+		}
+		var weekdays []time.Weekday
+		for key, day := range habiticaWeekdays {
+			if on, _ := task.Repeat[key].(bool); on {
+				weekdays = append(weekdays, day)
+			}
+		}
+		if len(weekdays) == len(habiticaWeekdays) {
+			return Daily, nil
+		}
+		sort.Slice(weekdays, func(i, j int) bool { return weekdays[i] < weekdays[j] })
+		return Daily, weekdays
+	case "monthly":
+		return Frequency(30 * every), nil
+	case "yearly":
+		return Frequency(365 * every), nil
+	}
+	return Frequency(every), nil
+}
+
+// completions returns the completions recorded in the Habitica task's history
+// in chronological order, with the given function returning the number of
+// times the task was done on each day of its history, given the task's value
+// on the day before.
+func (task habiticaTask) completions(done func(day habiticaDay, prev float64) int) []Completion {
+	history := append([]habiticaDay(nil), task.History...)
+	sort.SliceStable(history, func(i, j int) bool {
+		return history[i].Date.Before(history[j].Date.Time)
+	})
+	var completions []Completion
+	prev := 0.0
+	for _, day := range history {
+		for i := done(day, prev); i > 0; i-- {
+			completions = append(completions, Completion{At: day.Date.Time})
+		}
+		prev = day.Value
+	}
+	return completions
+}
@@ -0,0 +1,98 @@
+package habit_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+// habiticaTasks is a Habitica API response listing a daily, a habit scored up,
+// a habit only scored down and a to-do.
+const habiticaTasks = `{"success": true, "data": [
+	{"type": "daily", "text": "Read", "tags": ["t1"], "frequency": "weekly", "everyX": 1,
+	 "repeat": {"su": false, "m": true, "t": false, "w": true, "th": false, "f": true, "s": false},
+	 "streak": 2, "history": [
+		{"date": 1707163200000, "value": 1, "completed": true, "isDue": true},
+		{"date": 1707336000000, "value": 2, "completed": true, "isDue": true}
+	]},
+	{"type": "habit", "text": "Drink water", "up": true, "down": false, "history": [
+		{"date": 1707163200000, "value": 2, "scoredUp": 2, "scoredDown": 0}
+	]},
+	{"type": "habit", "text": "Smoke", "up": false, "down": true, "createdAt": "2024-01-01T12:00:00Z", "history": [
+		{"date": 1704888000000, "value": -1, "scoredUp": 0, "scoredDown": 1}
+	]},
+	{"type": "todo", "text": "File taxes"}
+]}`
+
+func TestTracker_ImportHabiticaConvertsDailiesAndHabits(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("x-api-user") != "user-id" || r.Header.Get("x-api-key") != "api-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"success": false, "message": "Missing authentication headers."}`))
+			return
+		}
+		switch r.URL.Path {
+		case "/tasks/user":
+			w.Write([]byte(habiticaTasks))
+		case "/tags":
+			w.Write([]byte(`{"success": true, "data": [{"id": "t1", "name": "learning"}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	store := &memStore{habits: map[string]habit.Habit{}}
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output), habit.WithLocation(time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.ImportHabitica(context.Background(), srv.URL, "user-id", "api-key", habit.MergeLatest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	monday := time.Date(2024, time.February, 5, 20, 0, 0, 0, time.UTC)
+	wednesday := monday.AddDate(0, 0, 2)
+	relapse := time.Date(2024, time.January, 10, 12, 0, 0, 0, time.UTC)
+	want := []habit.Habit{
+		{Name: "Read", CurrentStreak: 2, LongestStreak: 2, LastDone: wednesday, Frequency: habit.Daily, Tags: []string{"learning"},
+			Weekdays: []time.Weekday{time.Monday, time.Wednesday, time.Friday},
+			History:  []habit.Completion{{At: monday}, {At: wednesday}}},
+		{Name: "Drink water", CurrentStreak: 1, LongestStreak: 1, LastDone: monday,
+			History: []habit.Completion{{At: monday}, {At: monday}}},
+		{Name: "Smoke", Avoid: true, LongestStreak: 9, LastDone: relapse,
+			History: []habit.Completion{{At: relapse}}},
+	}
+	got := store.All()
+	if !cmp.Equal(want, got, habitSliceCmpOpt) {
+		t.Error(cmp.Diff(want, got, habitSliceCmpOpt))
+	}
+	if output.String() != "Imported 3 habits using the 'latest' merge strategy.\n" {
+		t.Errorf("unexpected output %q", output.String())
+	}
+}
+
+func TestTracker_ImportHabiticaReturnsErrorGivenInvalidToken(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"success": false, "message": "There is no account that uses those credentials."}`))
+	}))
+	defer srv.Close()
+	tracker, err := habit.NewTracker(habit.WithStore(&memStore{habits: map[string]habit.Habit{}}),
+		habit.WithOutput(new(bytes.Buffer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.ImportHabitica(context.Background(), srv.URL, "user-id", "wrong", habit.MergeLatest)
+	if err == nil {
+		t.Error("want error for invalid token, got nil")
+	}
+}
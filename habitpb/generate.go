@@ -0,0 +1,5 @@
+// Package habitpb holds the protocol buffer definition of the habit gRPC
+// service and the Go code generated from it.
+package habitpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative habit.proto
@@ -0,0 +1,256 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: habit.proto
+
+package habitpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	HabitService_TrackHabit_FullMethodName  = "/habit.v1.HabitService/TrackHabit"
+	HabitService_ListHabits_FullMethodName  = "/habit.v1.HabitService/ListHabits"
+	HabitService_GetStats_FullMethodName    = "/habit.v1.HabitService/GetStats"
+	HabitService_WatchHabits_FullMethodName = "/habit.v1.HabitService/WatchHabits"
+)
+
+// HabitServiceClient is the client API for HabitService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type HabitServiceClient interface {
+	// TrackHabit marks a habit as done, creating it if it does not exist.
+	TrackHabit(ctx context.Context, in *TrackHabitRequest, opts ...grpc.CallOption) (*TrackHabitResponse, error)
+	// ListHabits returns every habit, optionally filtered by tag.
+	ListHabits(ctx context.Context, in *ListHabitsRequest, opts ...grpc.CallOption) (*ListHabitsResponse, error)
+	// GetStats returns statistics for one habit or for every habit.
+	GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*GetStatsResponse, error)
+	// WatchHabits streams the current habits and then every change to them.
+	WatchHabits(ctx context.Context, in *WatchHabitsRequest, opts ...grpc.CallOption) (HabitService_WatchHabitsClient, error)
+}
+
+type habitServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewHabitServiceClient(cc grpc.ClientConnInterface) HabitServiceClient {
+	return &habitServiceClient{cc}
+}
+
+func (c *habitServiceClient) TrackHabit(ctx context.Context, in *TrackHabitRequest, opts ...grpc.CallOption) (*TrackHabitResponse, error) {
+	out := new(TrackHabitResponse)
+	err := c.cc.Invoke(ctx, HabitService_TrackHabit_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *habitServiceClient) ListHabits(ctx context.Context, in *ListHabitsRequest, opts ...grpc.CallOption) (*ListHabitsResponse, error) {
+	out := new(ListHabitsResponse)
+	err := c.cc.Invoke(ctx, HabitService_ListHabits_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *habitServiceClient) GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*GetStatsResponse, error) {
+	out := new(GetStatsResponse)
+	err := c.cc.Invoke(ctx, HabitService_GetStats_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *habitServiceClient) WatchHabits(ctx context.Context, in *WatchHabitsRequest, opts ...grpc.CallOption) (HabitService_WatchHabitsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &HabitService_ServiceDesc.Streams[0], HabitService_WatchHabits_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &habitServiceWatchHabitsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type HabitService_WatchHabitsClient interface {
+	Recv() (*HabitEvent, error)
+	grpc.ClientStream
+}
+
+type habitServiceWatchHabitsClient struct {
+	grpc.ClientStream
+}
+
+func (x *habitServiceWatchHabitsClient) Recv() (*HabitEvent, error) {
+	m := new(HabitEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// HabitServiceServer is the server API for HabitService service.
+// All implementations must embed UnimplementedHabitServiceServer
+// for forward compatibility
+type HabitServiceServer interface {
+	// TrackHabit marks a habit as done, creating it if it does not exist.
+	TrackHabit(context.Context, *TrackHabitRequest) (*TrackHabitResponse, error)
+	// ListHabits returns every habit, optionally filtered by tag.
+	ListHabits(context.Context, *ListHabitsRequest) (*ListHabitsResponse, error)
+	// GetStats returns statistics for one habit or for every habit.
+	GetStats(context.Context, *GetStatsRequest) (*GetStatsResponse, error)
+	// WatchHabits streams the current habits and then every change to them.
+	WatchHabits(*WatchHabitsRequest, HabitService_WatchHabitsServer) error
+	mustEmbedUnimplementedHabitServiceServer()
+}
+
+// UnimplementedHabitServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedHabitServiceServer struct {
+}
+
+func (UnimplementedHabitServiceServer) TrackHabit(context.Context, *TrackHabitRequest) (*TrackHabitResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TrackHabit not implemented")
+}
+func (UnimplementedHabitServiceServer) ListHabits(context.Context, *ListHabitsRequest) (*ListHabitsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListHabits not implemented")
+}
+func (UnimplementedHabitServiceServer) GetStats(context.Context, *GetStatsRequest) (*GetStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStats not implemented")
+}
+func (UnimplementedHabitServiceServer) WatchHabits(*WatchHabitsRequest, HabitService_WatchHabitsServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchHabits not implemented")
+}
+func (UnimplementedHabitServiceServer) mustEmbedUnimplementedHabitServiceServer() {}
+
+// UnsafeHabitServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to HabitServiceServer will
+// result in compilation errors.
+type UnsafeHabitServiceServer interface {
+	mustEmbedUnimplementedHabitServiceServer()
+}
+
+func RegisterHabitServiceServer(s grpc.ServiceRegistrar, srv HabitServiceServer) {
+	s.RegisterService(&HabitService_ServiceDesc, srv)
+}
+
+func _HabitService_TrackHabit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TrackHabitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HabitServiceServer).TrackHabit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HabitService_TrackHabit_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HabitServiceServer).TrackHabit(ctx, req.(*TrackHabitRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HabitService_ListHabits_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListHabitsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HabitServiceServer).ListHabits(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HabitService_ListHabits_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HabitServiceServer).ListHabits(ctx, req.(*ListHabitsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HabitService_GetStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HabitServiceServer).GetStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HabitService_GetStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HabitServiceServer).GetStats(ctx, req.(*GetStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HabitService_WatchHabits_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchHabitsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(HabitServiceServer).WatchHabits(m, &habitServiceWatchHabitsServer{stream})
+}
+
+type HabitService_WatchHabitsServer interface {
+	Send(*HabitEvent) error
+	grpc.ServerStream
+}
+
+type habitServiceWatchHabitsServer struct {
+	grpc.ServerStream
+}
+
+func (x *habitServiceWatchHabitsServer) Send(m *HabitEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// HabitService_ServiceDesc is the grpc.ServiceDesc for HabitService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var HabitService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "habit.v1.HabitService",
+	HandlerType: (*HabitServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "TrackHabit",
+			Handler:    _HabitService_TrackHabit_Handler,
+		},
+		{
+			MethodName: "ListHabits",
+			Handler:    _HabitService_ListHabits_Handler,
+		},
+		{
+			MethodName: "GetStats",
+			Handler:    _HabitService_GetStats_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchHabits",
+			Handler:       _HabitService_WatchHabits_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "habit.proto",
+}
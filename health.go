@@ -0,0 +1,119 @@
+package habit
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// errHealthExport is returned when encoding Apple Health data, which can only
+// be imported.
+var errHealthExport = errors.New("exporting Apple Health files is not supported")
+
+// Prefixes of the types of the Apple Health records that are imported.
+const (
+	healthMindfulType = "HKCategoryTypeIdentifierMindfulSession"
+	healthWorkoutType = "HKWorkoutActivityType"
+)
+
+// healthDateLayouts are the layouts of the start dates of Apple Health
+// records: the layout of Apple Health's own export, and RFC3339.
+var healthDateLayouts = []string{"2006-01-02 15:04:05 -0700", time.RFC3339}
+
+// healthCodec decodes the mindfulness sessions and workouts in a CSV file of
+// Apple Health records, as converted from Apple Health's export.xml, which has
+// a row for every record with the record's type in its type or
+// workoutActivityType column, such as "HKCategoryTypeIdentifierMindfulSession"
+// or "HKWorkoutActivityTypeRunning", and the time the record started in its
+// startDate column. Mindfulness sessions become completions of a Habit named
+// "Mindfulness", and workouts completions of a Habit named after the kind of
+// workout, such as "Running". Other records are skipped. Streaks are
+// recomputed with computeStreaks. The codec cannot encode.
+type healthCodec struct {
+	// calendar determines the dates on which streaks are recomputed.
+	calendar calendar
+}
+
+// Encode returns an error, since Apple Health data can only be imported.
+func (healthCodec) Encode(io.Writer, map[string]Habit) error {
+	return errHealthExport
+}
+
+// Decode reads the mindfulness sessions and workouts in a CSV file of Apple
+// Health records from r into data.
+func (c healthCodec) Decode(r io.Reader, data *map[string]Habit) error {
+	table, err := readCSVTable(r)
+	if err != nil {
+		return fmt.Errorf("error reading Apple Health records: %w", err)
+	}
+	if !table.has("type", "workoutactivitytype") || !table.has("startdate") {
+		return errors.New("not Apple Health records: want type and startDate columns")
+	}
+	habits := map[string]Habit{}
+	for i, record := range table.records {
+		line := i + 2
+		name := healthHabitName(table.field(record, "workoutactivitytype", "type"))
+		if name == "" {
+			continue
+		}
+		start := table.field(record, "startdate")
+		at, err := parseHealthTime(start)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		hbt := habits[name]
+		hbt.Name = name
+		hbt.History = append(hbt.History, Completion{At: at})
+		habits[name] = hbt
+	}
+	for name, hbt := range habits {
+		sort.Slice(hbt.History, func(i, j int) bool {
+			return hbt.History[i].At.Before(hbt.History[j].At)
+		})
+		hbt.CurrentStreak, hbt.LongestStreak = computeStreaks(hbt, c.calendar)
+		hbt.LastDone = hbt.History[len(hbt.History)-1].At
+		habits[name] = hbt
+	}
+	*data = habits
+	return nil
+}
+
+// healthHabitName returns the name of the Habit that an Apple Health record of
+// the given type is a completion of, or an empty string if records of the type
+// are not imported. Workout types are named by splitting their activity into
+// words, so that "HKWorkoutActivityTypeTraditionalStrengthTraining" becomes
+// "Traditional Strength Training".
+func healthHabitName(recordType string) string {
+	if recordType == healthMindfulType {
+		return "Mindfulness"
+	}
+	activity, ok := strings.CutPrefix(recordType, healthWorkoutType)
+	if !ok || activity == "" {
+		return ""
+	}
+	var name strings.Builder
+	prev := ' '
+	for _, r := range activity {
+		if unicode.IsUpper(r) && unicode.IsLower(prev) {
+			name.WriteByte(' ')
+		}
+		name.WriteRune(r)
+		prev = r
+	}
+	return name.String()
+}
+
+// parseHealthTime parses the start date of an Apple Health record.
+func parseHealthTime(value string) (time.Time, error) {
+	for _, layout := range healthDateLayouts {
+		at, err := time.Parse(layout, value)
+		if err == nil {
+			return at, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid start date %q (want YYYY-MM-DD HH:MM:SS -0700 or RFC3339)", value)
+}
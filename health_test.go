@@ -0,0 +1,42 @@
+package habit_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTracker_ImportHealthConvertsMindfulnessAndWorkouts(t *testing.T) {
+	t.Parallel()
+	records := "type,sourceName,unit,creationDate,startDate,endDate,value\n" +
+		"HKCategoryTypeIdentifierMindfulSession,Breathe,,2024-02-05 07:10:00 +0000,2024-02-05 07:00:00 +0000,2024-02-05 07:10:00 +0000,\n" +
+		"HKQuantityTypeIdentifierStepCount,iPhone,count,2024-02-05 09:00:00 +0000,2024-02-05 08:00:00 +0000,2024-02-05 09:00:00 +0000,1200\n" +
+		"HKCategoryTypeIdentifierMindfulSession,Breathe,,2024-02-06 21:10:00 +0000,2024-02-06 21:00:00 +0000,2024-02-06 21:10:00 +0000,\n" +
+		"HKWorkoutActivityTypeTraditionalStrengthTraining,Watch,,2024-02-06 19:00:00 +0000,2024-02-06 18:00:00 +0000,2024-02-06 19:00:00 +0000,\n"
+	store := &memStore{habits: map[string]habit.Habit{}}
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(new(bytes.Buffer)), habit.WithLocation(time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Import(strings.NewReader(records), habit.FormatHealth, habit.MergeLatest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := time.Date(2024, time.February, 5, 7, 0, 0, 0, time.UTC)
+	second := time.Date(2024, time.February, 6, 21, 0, 0, 0, time.UTC)
+	workout := time.Date(2024, time.February, 6, 18, 0, 0, 0, time.UTC)
+	want := []habit.Habit{
+		{Name: "Mindfulness", CurrentStreak: 2, LongestStreak: 2, LastDone: second,
+			History: []habit.Completion{{At: first}, {At: second}}},
+		{Name: "Traditional Strength Training", CurrentStreak: 1, LongestStreak: 1, LastDone: workout,
+			History: []habit.Completion{{At: workout}}},
+	}
+	got := store.All()
+	if !cmp.Equal(want, got, habitSliceCmpOpt) {
+		t.Error(cmp.Diff(want, got, habitSliceCmpOpt))
+	}
+}
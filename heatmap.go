@@ -0,0 +1,108 @@
+package habit
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// A HeatmapPeriod is the span of time covered by a heatmap.
+type HeatmapPeriod int
+
+const (
+	// HeatmapMonth covers the current calendar month.
+	HeatmapMonth HeatmapPeriod = iota
+	// HeatmapYear covers the 52 weeks up to and including the current week.
+	HeatmapYear
+)
+
+// ParseHeatmapPeriod accepts "month" or "year" and returns the corresponding
+// HeatmapPeriod. An error is returned if the value is not recognized.
+func ParseHeatmapPeriod(value string) (HeatmapPeriod, error) {
+	switch value {
+	case "month":
+		return HeatmapMonth, nil
+	case "year":
+		return HeatmapYear, nil
+	}
+	return 0, fmt.Errorf("invalid heatmap period %q (want month or year)", value)
+}
+
+// Heatmap cell glyphs.
+const (
+	heatmapDone   = "■"
+	heatmapMissed = "·"
+	heatmapEmpty  = " "
+)
+
+// PrintHeatmap writes a GitHub-style contribution calendar of the completion
+// history of the Habit with the given name to the given Tracker's output. Each
+// column is a week from Monday to Sunday and each cell is a day, marked if the
+// habit was done that day. An error is returned if the Habit does not exist.
+func (t *Tracker) PrintHeatmap(hbtName string, period HeatmapPeriod) error {
+	hbt, ok := t.store.Get(hbtName)
+	if !ok {
+		return errHabitNotFound(hbtName)
+	}
+	now := t.now()
+	today := t.calendar.day(now)
+	first, last := today, today
+	switch period {
+	case HeatmapYear:
+		first = today.AddDate(0, 0, -7*51-weekdayIndex(today))
+	default:
+		first = today.AddDate(0, 0, 1-today.Day())
+		last = first.AddDate(0, 1, -1)
+	}
+	done := map[time.Time]bool{}
+	for _, c := range hbt.History {
+		done[t.calendar.day(c.At)] = true
+	}
+	// The grid starts on the Monday of the first week.
+	start := first.AddDate(0, 0, -weekdayIndex(first))
+	weeks := t.calendar.daysBetween(start, last)/7 + 1
+	fmt.Fprintf(t.output, "'%s' from %s to %s\n", hbtName,
+		first.Format(time.DateOnly), last.Format(time.DateOnly))
+	fmt.Fprintln(t.output, "    "+monthLabels(start, first, last, weeks))
+	for row := 0; row < 7; row++ {
+		var line strings.Builder
+		line.WriteString(time.Weekday((row + 1) % 7).String()[:3])
+		for week := 0; week < weeks; week++ {
+			day := start.AddDate(0, 0, week*7+row)
+			cell := heatmapMissed
+			switch {
+			case day.Before(first) || day.After(last) || day.After(today):
+				cell = heatmapEmpty
+			case done[day]:
+				cell = heatmapDone
+			}
+			line.WriteString(" " + cell)
+		}
+		fmt.Fprintln(t.output, strings.TrimRight(line.String(), " "))
+	}
+	fmt.Fprintf(t.output, "%s done  %s missed\n", heatmapDone, heatmapMissed)
+	return nil
+}
+
+// monthLabels returns a header line for a heatmap grid starting on the given
+// Monday and spanning the given number of weeks, with the abbreviated month
+// name above each week that contains the first day of a month between first
+// and last.
+func monthLabels(start, first, last time.Time, weeks int) string {
+	labels := []byte(strings.Repeat(" ", weeks*2+2))
+	for week := 0; week < weeks; week++ {
+		for day := 0; day < 7; day++ {
+			date := start.AddDate(0, 0, week*7+day)
+			if date.Day() == 1 && !date.Before(first) && !date.After(last) {
+				copy(labels[week*2:], date.Format("Jan"))
+			}
+		}
+	}
+	return strings.TrimRight(string(labels), " ")
+}
+
+// weekdayIndex returns the index of the given timestamp's weekday in a week
+// that starts on Monday.
+func weekdayIndex(t time.Time) int {
+	return (int(t.Weekday()) + 6) % 7
+}
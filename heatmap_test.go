@@ -0,0 +1,62 @@
+package habit_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTracker_PrintHeatmapRendersCurrentMonth(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-14T20:00:00Z")
+	var history []habit.Completion
+	for _, day := range []int{1, 2, 5, 6, 7, 13, 14} {
+		history = append(history, habit.Completion{At: time.Date(2024, time.February, day, 9, 0, 0, 0, time.UTC)})
+	}
+	store, err := habit.OpenStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{Name: "programming", History: history})
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.PrintHeatmap("programming", habit.HeatmapMonth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "'programming' from 2024-02-01 to 2024-02-29\n" +
+		"    Feb\n" +
+		"Mon   ■ ·\n" +
+		"Tue   ■ ■\n" +
+		"Wed   ■ ■\n" +
+		"Thu ■ ·\n" +
+		"Fri ■ ·\n" +
+		"Sat · ·\n" +
+		"Sun · ·\n" +
+		"■ done  · missed\n"
+	got := output.String()
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestTracker_PrintHeatmapReturnsErrorForNonExistentHabit(t *testing.T) {
+	t.Parallel()
+	store, err := habit.OpenStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker, err := habit.NewTracker(habit.WithStore(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.PrintHeatmap("nonexistent", habit.HeatmapYear)
+	if err == nil {
+		t.Error("expected an error when printing heatmap for non-existent habit")
+	}
+}
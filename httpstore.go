@@ -0,0 +1,233 @@
+package habit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// An HTTPStore provides a store for Habits that is persisted by a remote
+// Server, so that the CLI on several machines can share one habit database.
+// Changes made with Add and Delete are buffered in memory until Save sends them
+// to the Server.
+type HTTPStore struct {
+	baseURL string
+	token   string
+	client  *http.Client
+	pending map[string]*Habit
+	err     error
+	mtx     sync.Mutex
+}
+
+// httpStoreTimeout is how long an HTTPStore waits for each response from the
+// Server.
+const httpStoreTimeout = 30 * time.Second
+
+// OpenHTTPStore returns an HTTPStore backed by the Server at the given base
+// URL, authenticating with the given bearer token unless it is empty. An error
+// is returned if the URL is invalid or the Server cannot be reached.
+func OpenHTTPStore(baseURL, token string) (*HTTPStore, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid remote store URL %q", baseURL)
+	}
+	s := &HTTPStore{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		token:   token,
+		client:  &http.Client{Timeout: httpStoreTimeout},
+		pending: map[string]*Habit{},
+	}
+	err = s.do(context.Background(), http.MethodGet, "/habits", nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error opening remote store %q: %w", baseURL, err)
+	}
+	return s, nil
+}
+
+// isRemoteStore reports whether the given store path is the URL of a remote
+// Server.
+func isRemoteStore(path string) bool {
+	return strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://")
+}
+
+// Get returns the habit with the given name and a bool indicating if the habit
+// exists in the store.
+func (s *HTTPStore) Get(name string) (Habit, bool) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if h, ok := s.pending[name]; ok {
+		if h == nil {
+			return Habit{}, false
+		}
+		return *h, true
+	}
+	var h Habit
+	err := s.do(context.Background(), http.MethodGet, "/habits/"+url.PathEscape(name), nil, &h)
+	if err == errNotFound {
+		return Habit{}, false
+	}
+	if err != nil {
+		s.setErr(fmt.Errorf("error getting habit '%s': %w", name, err))
+		return Habit{}, false
+	}
+	return h, true
+}
+
+// Add adds or updates the given habit in the store.
+func (s *HTTPStore) Add(h Habit) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.pending[h.Name] = &h
+}
+
+// Delete deletes the habit with the given name from the store. If the
+// habit does not exist in the store, then the delete is a no-op.
+func (s *HTTPStore) Delete(name string) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.pending[name] = nil
+}
+
+// All returns a list of all habits contained in the store.
+func (s *HTTPStore) All() []Habit {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	var raw json.RawMessage
+	err := s.do(context.Background(), http.MethodGet, "/export?format=json", nil, &raw)
+	if err != nil {
+		s.setErr(fmt.Errorf("error getting habits: %w", err))
+		return nil
+	}
+	data := map[string]Habit{}
+	err = jsonCodec{}.Decode(bytes.NewReader(raw), &data)
+	if err != nil {
+		s.setErr(fmt.Errorf("error decoding habits: %w", err))
+		return nil
+	}
+	var habits []Habit
+	for name, h := range data {
+		if _, ok := s.pending[name]; ok {
+			continue
+		}
+		habits = append(habits, h)
+	}
+	for _, h := range s.pending {
+		if h != nil {
+			habits = append(habits, *h)
+		}
+	}
+	return habits
+}
+
+// Save sends the changes made since the last Save to the Server. An error is
+// returned if a previous request failed or if the changes cannot be sent, in
+// which case the changes that were not sent are kept for the next Save.
+func (s *HTTPStore) Save() error {
+	return s.SaveContext(context.Background())
+}
+
+// SaveContext sends the changes like Save, abandoning the requests once the
+// given context is done, in which case the changes that were not sent are
+// kept for the next save.
+func (s *HTTPStore) SaveContext(ctx context.Context) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.err != nil {
+		err := s.err
+		s.err = nil
+		return err
+	}
+	for name, h := range s.pending {
+		path := "/habits/" + url.PathEscape(name)
+		if h == nil {
+			err := s.do(ctx, http.MethodDelete, path, nil, nil)
+			if err != nil && err != errNotFound {
+				return fmt.Errorf("error deleting habit '%s': %w", name, err)
+			}
+			delete(s.pending, name)
+			continue
+		}
+		err := s.do(ctx, http.MethodPut, path, h, nil)
+		if err != nil {
+			return fmt.Errorf("error saving habit '%s': %w", name, err)
+		}
+		delete(s.pending, name)
+	}
+	return nil
+}
+
+// LoadContext discards the changes made since the last save, so that the
+// store reads the habits held by the Server, and checks that the Server can
+// still be reached before the given context is done.
+func (s *HTTPStore) LoadContext(ctx context.Context) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.pending = map[string]*Habit{}
+	s.err = nil
+	err := s.do(ctx, http.MethodGet, "/habits", nil, nil)
+	if err != nil {
+		return fmt.Errorf("error reloading remote store %q: %w", s.baseURL, err)
+	}
+	return nil
+}
+
+// do sends a request with the given method to the given path of the current
+// version of the Server's API under the given context, with the given body
+// encoded as JSON unless it is nil, and decodes the JSON response into result
+// unless it is nil. errNotFound is returned if the Server responds with 404 Not
+// Found. The caller must hold s.mtx.
+func (s *HTTPStore) do(ctx context.Context, method, path string, body, result any) error {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, s.baseURL+"/"+apiVersion+path, reqBody)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return errNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		var errResp errorResponse
+		err = json.NewDecoder(resp.Body).Decode(&errResp)
+		if err != nil || errResp.Error == "" {
+			return fmt.Errorf("unexpected response status %s", resp.Status)
+		}
+		return fmt.Errorf("%s (%s)", errResp.Error, resp.Status)
+	}
+	if result == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+// setErr records the first error encountered by a request so that it can be
+// returned by the next call to Save. The caller must hold s.mtx.
+func (s *HTTPStore) setErr(err error) {
+	if s.err == nil {
+		s.err = err
+	}
+}
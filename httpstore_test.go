@@ -0,0 +1,170 @@
+package habit_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http/httptest"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+func newRemoteStore(t *testing.T, token string) (string, habit.Store) {
+	t.Helper()
+	store, err := habit.OpenStore(t.TempDir() + "/test.store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(new(bytes.Buffer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(habit.NewServer(tracker, habit.WithToken(token)))
+	t.Cleanup(srv.Close)
+	return srv.URL, store
+}
+
+func TestHTTPStore_SaveSendsChangesToServer(t *testing.T) {
+	url, remote := newRemoteStore(t, "secret")
+	remote.Add(habit.Habit{Name: "reading"})
+	store, err := habit.OpenHTTPStore(url, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lastDone := time.Date(2024, time.February, 6, 13, 0, 0, 0, time.UTC)
+	want := habit.Habit{
+		Name:          "club/programming",
+		CurrentStreak: 1,
+		LongestStreak: 1,
+		LastDone:      lastDone,
+		History:       []habit.Completion{{At: lastDone, Note: "wrote tests"}},
+	}
+	store.Add(want)
+	store.Delete("reading")
+	_, ok := remote.Get("club/programming")
+	if ok {
+		t.Fatal("want unsaved habit not to be sent to the server")
+	}
+	err = store.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, ok := remote.Get("club/programming")
+	if !ok {
+		t.Fatal("want saved habit to be sent to the server")
+	}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+	_, ok = remote.Get("reading")
+	if ok {
+		t.Error("want deleted habit to be deleted from the server")
+	}
+}
+
+func TestHTTPStore_GetAndAllReturnHabitsFromServerAndUnsavedChanges(t *testing.T) {
+	url, remote := newRemoteStore(t, "")
+	remote.Add(habit.Habit{Name: "reading", CurrentStreak: 3})
+	remote.Add(habit.Habit{Name: "running"})
+	store, err := habit.OpenHTTPStore(url, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{Name: "programming"})
+	store.Delete("running")
+	got, ok := store.Get("reading")
+	if !ok {
+		t.Fatal("want habit 'reading' to exist")
+	}
+	if got.CurrentStreak != 3 {
+		t.Errorf("want current streak 3, got %d", got.CurrentStreak)
+	}
+	_, ok = store.Get("running")
+	if ok {
+		t.Error("want deleted habit 'running' not to exist")
+	}
+	_, ok = store.Get("missing")
+	if ok {
+		t.Error("want habit 'missing' not to exist")
+	}
+	var names []string
+	for _, hbt := range store.All() {
+		names = append(names, hbt.Name)
+	}
+	sort.Strings(names)
+	want := []string{"programming", "reading"}
+	if !cmp.Equal(want, names) {
+		t.Error(cmp.Diff(want, names))
+	}
+	err = store.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHTTPStore_TrackerTracksHabitsOnServer(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T13:00:00Z")
+	url, remote := newRemoteStore(t, "secret")
+	store, err := habit.OpenHTTPStore(url, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(new(bytes.Buffer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Track("programming")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, ok := remote.Get("programming")
+	if !ok {
+		t.Fatal("want tracked habit to be stored on the server")
+	}
+	if !got.LastDone.Equal(habit.Now()) {
+		t.Errorf("want last done %v, got %v", habit.Now(), got.LastDone)
+	}
+}
+
+func TestOpenHTTPStore_ReturnsErrorForInvalidTokenOrURL(t *testing.T) {
+	url, _ := newRemoteStore(t, "secret")
+	_, err := habit.OpenHTTPStore(url, "wrong")
+	if err == nil {
+		t.Error("want error for invalid token")
+	}
+	_, err = habit.OpenHTTPStore("ftp://example.com", "")
+	if err == nil {
+		t.Error("want error for non-HTTP URL")
+	}
+}
+
+func TestHTTPStore_SaveContextKeepsChangesGivenCancelledContext(t *testing.T) {
+	url, remote := newRemoteStore(t, "")
+	store, err := habit.OpenHTTPStore(url, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{Name: "reading"})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = store.SaveContext(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("want error %v, got %v", context.Canceled, err)
+	}
+	_, ok := remote.Get("reading")
+	if ok {
+		t.Fatal("want habit not to be sent to the server by a cancelled save")
+	}
+	err = store.SaveContext(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, ok = remote.Get("reading")
+	if !ok {
+		t.Error("want habit kept after a cancelled save to be sent by the next save")
+	}
+}
@@ -0,0 +1,134 @@
+package habit
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// errICSImport is returned when decoding iCalendar data, which can only be
+// exported.
+var errICSImport = errors.New("importing iCalendar files is not supported")
+
+// icsCodec encodes habit data as an iCalendar feed, so that habits show up in
+// calendar applications. Every completion is written as an event at the time
+// it was done, and every active habit is written as a recurring all-day event
+// on the last day of each of its periods, starting with the next period in
+// which it has not been done yet. Habits with a reminder time recur at that
+// time of day instead.
+type icsCodec struct {
+	// calendar determines the dates of each habit's periods.
+	calendar calendar
+	// now returns the current time, from which upcoming due dates are
+	// computed.
+	now func() time.Time
+}
+
+// icsDateTime is the layout of UTC date-times in iCalendar data.
+const icsDateTime = "20060102T150405Z"
+
+// Encode writes the given habit data to w as an iCalendar feed, sorted by
+// habit name.
+func (c icsCodec) Encode(w io.Writer, data map[string]Habit) error {
+	names := make([]string, 0, len(data))
+	for name := range data {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	now := c.now().In(c.calendar.location)
+	stamp := now.UTC().Format(icsDateTime)
+	bw := bufio.NewWriter(w)
+	line := func(format string, args ...any) {
+		writeICSLine(bw, fmt.Sprintf(format, args...))
+	}
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//aculclasure//habit//EN")
+	line("CALSCALE:GREGORIAN")
+	line("X-WR-CALNAME:Habits")
+	for _, name := range names {
+		hbt := data[name]
+		for _, c := range hbt.History {
+			line("BEGIN:VEVENT")
+			line("UID:done-%d-%x@habit", c.At.UnixNano(), hbt.Name)
+			line("DTSTAMP:%s", stamp)
+			line("DTSTART:%s", c.At.UTC().Format(icsDateTime))
+			line("SUMMARY:%s", icsText("Done: "+hbt.Name))
+			if c.Note != "" {
+				line("DESCRIPTION:%s", icsText(c.Note))
+			}
+			line("END:VEVENT")
+		}
+		if hbt.Archived || hbt.Avoid || hbt.Paused(now) {
+			continue
+		}
+		due := c.nextDue(hbt, now)
+		line("BEGIN:VEVENT")
+		line("UID:due-%x@habit", hbt.Name)
+		line("DTSTAMP:%s", stamp)
+		if hbt.ReminderTime != nil {
+			line("DTSTART:%s", due.Format("20060102")+"T"+
+				fmt.Sprintf("%02d%02d00", hbt.ReminderTime.Hour, hbt.ReminderTime.Minute))
+			line("DURATION:PT30M")
+		} else {
+			line("DTSTART;VALUE=DATE:%s", due.Format("20060102"))
+		}
+		if days := hbt.Frequency.Days(); days > 1 {
+			line("RRULE:FREQ=DAILY;INTERVAL=%d", days)
+		} else {
+			line("RRULE:FREQ=DAILY")
+		}
+		line("SUMMARY:%s", icsText("Due: "+hbt.Name))
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+	return bw.Flush()
+}
+
+// Decode returns an error, since iCalendar data cannot be imported.
+func (icsCodec) Decode(io.Reader, *map[string]Habit) error {
+	return errICSImport
+}
+
+// nextDue returns the date, as UTC midnight, of the last day of the first
+// period of the given Habit's frequency, as of the given timestamp, in which
+// the Habit has not been done yet.
+func (c icsCodec) nextDue(hbt Habit, now time.Time) time.Time {
+	index := hbt.Frequency.periodIndex(now, c.calendar)
+	if hbt.doneThisPeriod(now, c.calendar) {
+		index++
+	}
+	days := hbt.Frequency.Days()
+	return periodAnchor.AddDate(0, 0, (index+1)*days-1)
+}
+
+// icsText escapes the given value for use in an iCalendar text property.
+func icsText(value string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(value)
+}
+
+// writeICSLine writes the given content line to w, folded into lines of at
+// most 75 octets without splitting UTF-8 characters, and terminated by CRLF as
+// iCalendar requires.
+func writeICSLine(w *bufio.Writer, line string) {
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		w.WriteString(line[:cut])
+		w.WriteString("\r\n ")
+		line = line[cut:]
+		// Continuation lines start with a space, which counts toward the
+		// limit.
+		limit = 74
+	}
+	w.WriteString(line)
+	w.WriteString("\r\n")
+}
@@ -0,0 +1,120 @@
+package habit_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTracker_ExportICSWritesCompletionsAndUpcomingDueDates(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T20:00:00Z")
+	done := time.Date(2024, time.February, 6, 7, 30, 0, 0, time.UTC)
+	store, err := habit.OpenStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{
+		Name:          "reading",
+		CurrentStreak: 1,
+		LongestStreak: 1,
+		LastDone:      done,
+		History:       []habit.Completion{{At: done, Note: "chapter 3, part 1"}},
+		ReminderTime:  &habit.TimeOfDay{Hour: 21, Minute: 15},
+	})
+	store.Add(habit.Habit{
+		Name:      "cleaning",
+		Frequency: habit.Weekly,
+	})
+	store.Add(habit.Habit{Name: "old", Archived: true})
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(new(bytes.Buffer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	output := new(bytes.Buffer)
+	err = tracker.Export(output, habit.FormatICS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//aculclasure//habit//EN",
+		"CALSCALE:GREGORIAN",
+		"X-WR-CALNAME:Habits",
+		"BEGIN:VEVENT",
+		"UID:due-636c65616e696e67@habit",
+		"DTSTAMP:20240206T200000Z",
+		"DTSTART;VALUE=DATE:20240211",
+		"RRULE:FREQ=DAILY;INTERVAL=7",
+		"SUMMARY:Due: cleaning",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:done-1707204600000000000-72656164696e67@habit",
+		"DTSTAMP:20240206T200000Z",
+		"DTSTART:20240206T073000Z",
+		"SUMMARY:Done: reading",
+		`DESCRIPTION:chapter 3\, part 1`,
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:due-72656164696e67@habit",
+		"DTSTAMP:20240206T200000Z",
+		"DTSTART:20240207T211500",
+		"DURATION:PT30M",
+		"RRULE:FREQ=DAILY",
+		"SUMMARY:Due: reading",
+		"END:VEVENT",
+		"END:VCALENDAR",
+		"",
+	}
+	got := strings.Split(output.String(), "\r\n")
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestTracker_ExportICSFoldsLongLines(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T20:00:00Z")
+	done := time.Date(2024, time.February, 6, 7, 30, 0, 0, time.UTC)
+	store, err := habit.OpenStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{
+		Name:     "reading",
+		LastDone: done,
+		History:  []habit.Completion{{At: done, Note: strings.Repeat("é", 100)}},
+	})
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(new(bytes.Buffer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	output := new(bytes.Buffer)
+	err = tracker.Export(output, habit.FormatICS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range strings.Split(output.String(), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("want lines of at most 75 octets, got %d: %q", len(line), line)
+		}
+	}
+	unfolded := strings.ReplaceAll(output.String(), "\r\n ", "")
+	if !strings.Contains(unfolded, "DESCRIPTION:"+strings.Repeat("é", 100)+"\r\n") {
+		t.Errorf("want unfolded output to contain the full note, got %q", unfolded)
+	}
+}
+
+func TestTracker_ImportICSReturnsError(t *testing.T) {
+	tracker, err := habit.NewTracker(habit.WithStore(&memStore{habits: map[string]habit.Habit{}}), habit.WithOutput(new(bytes.Buffer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Import(strings.NewReader("BEGIN:VCALENDAR\r\n"), habit.FormatICS, habit.MergeLatest)
+	if err == nil {
+		t.Error("want error importing iCalendar data")
+	}
+}
@@ -0,0 +1,76 @@
+package habit
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// TrackOnce records the Habit with the given name as done at the given
+// timestamp with the given note like TrackNote, unless a completion with the
+// given idempotency key, such as a random ID generated by an app for a tap of
+// its done button, has already been tracked. A retried request or a second tap
+// then changes nothing, and a message saying so is written instead. A zero
+// timestamp is taken as now, and an empty key tracks the Habit every time.
+func (t *Tracker) TrackOnce(hbtName, id string, at time.Time, note string) error {
+	return t.TrackOnceContext(context.Background(), hbtName, id, at, note)
+}
+
+// TrackOnceContext tracks the Habit with the given name like TrackOnce, saving
+// the store under the given context.
+func (t *Tracker) TrackOnceContext(ctx context.Context, hbtName, id string, at time.Time, note string) error {
+	if t.trackedOnce(hbtName, id) {
+		return nil
+	}
+	if at.IsZero() {
+		at = t.now()
+	}
+	return t.trackContext(ctx, hbtName, Completion{At: at, Note: note, ID: id})
+}
+
+// LogAmountOnce logs the given amount of the quantity Habit with the given name
+// like LogAmount, unless an amount with the given idempotency key has already
+// been logged in the Habit's current period or has completed it, so that a
+// retried request does not count the amount twice. An empty key logs the
+// amount every time.
+func (t *Tracker) LogAmountOnce(hbtName string, amount float64, id string) error {
+	if t.trackedOnce(hbtName, id) {
+		return nil
+	}
+	hbt, ok := t.store.Get(hbtName)
+	if ok && id != "" && hbt.amountThisPeriod(t.now(), t.calendar) > 0 && slices.Contains(hbt.AmountIDs, id) {
+		fmt.Fprintf(t.output, "An amount of '%s' was already logged with id '%s'.\n", hbtName, id)
+		return nil
+	}
+	return t.logAmount(hbtName, amount, id)
+}
+
+// trackedOnce reports whether the Habit with the given name has a completion
+// with the given idempotency key, writing a message saying so if it does. It
+// reports false for an empty key.
+func (t *Tracker) trackedOnce(hbtName, id string) bool {
+	if id == "" {
+		return false
+	}
+	hbt, ok := t.store.Get(hbtName)
+	if !ok || !hbt.hasCompletion(id) {
+		return false
+	}
+	fmt.Fprintln(t.output, alreadyTracked(hbtName, id))
+	return true
+}
+
+// alreadyTracked returns the message saying that the Habit with the given name
+// was already tracked with the given idempotency key.
+func alreadyTracked(hbtName, id string) string {
+	return fmt.Sprintf("The habit '%s' was already tracked with id '%s'.", hbtName, id)
+}
+
+// hasCompletion reports whether the Habit has a completion with the given
+// idempotency key.
+func (h Habit) hasCompletion(id string) bool {
+	return slices.ContainsFunc(h.History, func(c Completion) bool {
+		return c.ID == id
+	})
+}
@@ -0,0 +1,142 @@
+package habit_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTracker_TrackOnceTracksHabitOncePerKey(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T13:00:00Z")
+	store := &memStore{habits: map[string]habit.Habit{}}
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output), habit.WithLocation(time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"tap-1", "tap-1", "tap-2"} {
+		err = tracker.TrackOnce("programming", id, time.Time{}, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := "Congratulations on starting your new habit 'programming'! Don't forget to do it again.\n" +
+		"The habit 'programming' was already tracked with id 'tap-1'.\n" +
+		"Way to go practicing your habit 'programming' more than once today!\n"
+	if got := output.String(); want != got {
+		t.Errorf("want output %q, got output %q", want, got)
+	}
+	at := habit.Now()
+	wantHistory := []habit.Completion{{At: at, ID: "tap-1"}, {At: at, ID: "tap-2"}}
+	if !cmp.Equal(wantHistory, store.habits["programming"].History) {
+		t.Error(cmp.Diff(wantHistory, store.habits["programming"].History))
+	}
+}
+
+func TestTracker_LogAmountOnceCountsAmountOncePerKey(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T13:00:00Z")
+	store := &memStore{habits: map[string]habit.Habit{
+		"water": {Name: "water", Target: 8, Unit: "glasses"},
+	}}
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(new(bytes.Buffer)), habit.WithLocation(time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	logs := []struct {
+		amount float64
+		id     string
+	}{
+		{amount: 5, id: "a"},
+		{amount: 5, id: "a"},
+		{amount: 3, id: "b"},
+		{amount: 3, id: "b"},
+		{amount: 2, id: ""},
+		{amount: 2, id: ""},
+	}
+	for _, l := range logs {
+		err = tracker.LogAmountOnce("water", l.amount, l.id)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	hbt := store.habits["water"]
+	if hbt.Amount != 12 {
+		t.Errorf("want amount 12, got %v", hbt.Amount)
+	}
+	wantHistory := []habit.Completion{{At: habit.Now(), Amount: 8, ID: "b"}}
+	if !cmp.Equal(wantHistory, hbt.History) {
+		t.Error(cmp.Diff(wantHistory, hbt.History))
+	}
+}
+
+func TestTracker_LogAmountOnceForgetsKeysOfEarlierPeriods(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T13:00:00Z")
+	store := &memStore{habits: map[string]habit.Habit{
+		"water": {
+			Name:      "water",
+			Target:    8,
+			Amount:    5,
+			AmountAt:  habit.Now().Add(-24 * time.Hour),
+			AmountIDs: []string{"a"},
+		},
+	}}
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(new(bytes.Buffer)), habit.WithLocation(time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.LogAmountOnce("water", 2, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hbt := store.habits["water"]
+	if hbt.Amount != 2 {
+		t.Errorf("want amount 2, got %v", hbt.Amount)
+	}
+	if !cmp.Equal([]string{"a"}, hbt.AmountIDs) {
+		t.Error(cmp.Diff([]string{"a"}, hbt.AmountIDs))
+	}
+}
+
+func TestTrackBatch_SkipsCompletionsAlreadyTrackedWithKey(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T13:00:00Z")
+	at := habit.Now().Add(-time.Hour)
+	store := &memStore{habits: map[string]habit.Habit{
+		"reading": {
+			Name:          "reading",
+			CurrentStreak: 1,
+			LongestStreak: 1,
+			LastDone:      at,
+			History:       []habit.Completion{{At: at, ID: "queued-1"}},
+		},
+	}}
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(new(bytes.Buffer)), habit.WithLocation(time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := tracker.TrackBatch([]habit.BatchCompletion{
+		{Habit: "reading", At: at, ID: "queued-1"},
+		{Habit: "reading", At: at.Add(time.Minute), ID: "queued-2"},
+		{Habit: "reading", At: at.Add(time.Minute), ID: "queued-2"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var messages []string
+	for _, res := range results {
+		messages = append(messages, res.Message)
+	}
+	want := []string{
+		"The habit 'reading' was already tracked with id 'queued-1'.",
+		"Way to go practicing your habit 'reading' more than once today!",
+		"The habit 'reading' was already tracked with id 'queued-2'.",
+	}
+	if !cmp.Equal(want, messages) {
+		t.Error(cmp.Diff(want, messages))
+	}
+	if got := len(store.habits["reading"].History); got != 2 {
+		t.Errorf("want 2 completions, got %d", got)
+	}
+}
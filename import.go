@@ -0,0 +1,144 @@
+package habit
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// A MergeStrategy determines how Import resolves a collision between an
+// imported Habit and an existing Habit with the same name.
+type MergeStrategy int
+
+const (
+	// MergeLatest keeps whichever Habit was done most recently, according to
+	// its LastDone timestamp.
+	MergeLatest MergeStrategy = iota
+	// MergeKeepExisting keeps the existing Habit and discards the imported one.
+	MergeKeepExisting
+	// MergeOverwrite replaces the existing Habit with the imported one.
+	MergeOverwrite
+)
+
+// String returns the command-line name of the MergeStrategy.
+func (m MergeStrategy) String() string {
+	switch m {
+	case MergeLatest:
+		return "latest"
+	case MergeKeepExisting:
+		return "keep-existing"
+	case MergeOverwrite:
+		return "overwrite"
+	}
+	return fmt.Sprintf("MergeStrategy(%d)", int(m))
+}
+
+// ParseMergeStrategy accepts the command-line name of a merge strategy and
+// returns the corresponding MergeStrategy. An error is returned if the name is
+// not recognized.
+func ParseMergeStrategy(name string) (MergeStrategy, error) {
+	for _, m := range []MergeStrategy{MergeLatest, MergeKeepExisting, MergeOverwrite} {
+		if m.String() == name {
+			return m, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown merge strategy %q (want keep-existing, overwrite, or latest)", name)
+}
+
+// resolve accepts an existing Habit and an imported Habit with the same name
+// and returns the Habit that should be kept according to the MergeStrategy.
+func (m MergeStrategy) resolve(existing, imported Habit) Habit {
+	switch m {
+	case MergeKeepExisting:
+		return existing
+	case MergeOverwrite:
+		return imported
+	default:
+		if imported.LastDone.After(existing.LastDone) {
+			return imported
+		}
+		return existing
+	}
+}
+
+// Import reads habit data encoded in the given Format from r and adds its
+// Habits to the Tracker's store, resolving name collisions with the given
+// MergeStrategy. An error is returned if the data cannot be decoded, if it
+// holds a habit without a name or named differently from its key, or if the
+// store cannot be saved.
+func (t *Tracker) Import(r io.Reader, format Format, strategy MergeStrategy) error {
+	imported := map[string]Habit{}
+	err := format.codec(t.calendar).Decode(r, &imported)
+	if err != nil {
+		return fmt.Errorf("error decoding imported %s data: %w", format, err)
+	}
+	return t.importHabits(imported, strategy)
+}
+
+// importHabits adds the given imported Habits to the Tracker's store,
+// resolving name collisions with the given MergeStrategy, and saves the store.
+// A Habit without a name is named after its key. An error is returned, and
+// nothing is imported, if any Habit is invalid as reported by checkHabit.
+func (t *Tracker) importHabits(imported map[string]Habit, strategy MergeStrategy) error {
+	for name, hbt := range imported {
+		hbt, err := checkHabit(name, hbt)
+		if err != nil {
+			return fmt.Errorf("cannot import: %w", err)
+		}
+		imported[name] = hbt
+	}
+	for name, hbt := range imported {
+		existing, ok := t.store.Get(name)
+		if ok {
+			hbt = strategy.resolve(existing, hbt)
+		}
+		t.store.Add(hbt)
+	}
+	err := t.save()
+	if err != nil {
+		return err
+	}
+	habitOutput := "habits"
+	if len(imported) == 1 {
+		habitOutput = "habit"
+	}
+	fmt.Fprintf(t.output, "Imported %d %s using the '%s' merge strategy.\n", len(imported), habitOutput, strategy)
+	return nil
+}
+
+// checkHabit returns the given Habit, to be stored under the given name,
+// named after it if it has no name. An error is returned if the name is
+// empty, the Habit is named differently, its target is negative or its
+// frequency is not a number of days.
+func checkHabit(name string, hbt Habit) (Habit, error) {
+	switch {
+	case name == "":
+		return hbt, errors.New("a habit has no name")
+	case hbt.Name == "":
+		hbt.Name = name
+	case hbt.Name != name:
+		return hbt, fmt.Errorf("habit '%s' is named '%s'", name, hbt.Name)
+	}
+	if hbt.Target < 0 {
+		return hbt, fmt.Errorf("habit '%s' has a negative target", name)
+	}
+	if hbt.Frequency < 0 {
+		return hbt, fmt.Errorf("habit '%s' has an unknown frequency %d", name, hbt.Frequency)
+	}
+	return hbt, nil
+}
+
+// addUnique adds the given Habit, imported from another app, to the given
+// imported Habits, suffixing its name with " (2)", " (3)" and so on if another
+// imported Habit already has the name, since other apps need not keep names
+// unique.
+func addUnique(imported map[string]Habit, hbt Habit) {
+	name := hbt.Name
+	for i := 2; ; i++ {
+		if _, taken := imported[hbt.Name]; !taken {
+			break
+		}
+		hbt.Name = fmt.Sprintf("%s (%d)", name, i)
+	}
+	imported[hbt.Name] = hbt
+}
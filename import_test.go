@@ -0,0 +1,227 @@
+package habit_test
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTracker_ImportResolvesConflictsWithMergeStrategy(t *testing.T) {
+	t.Parallel()
+	older, err := time.Parse(time.RFC3339, "2024-02-05T13:00:00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	newer, err := time.Parse(time.RFC3339, "2024-02-06T13:00:00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	existingNewer := habit.Habit{
+		Name:          "programming",
+		CurrentStreak: 3,
+		LongestStreak: 3,
+		LastDone:      newer,
+		History:       []habit.Completion{{At: newer}},
+	}
+	importedOlder := habit.Habit{
+		Name:          "programming",
+		CurrentStreak: 8,
+		LongestStreak: 8,
+		LastDone:      older,
+		History:       []habit.Completion{{At: older}},
+	}
+	existingOlder := habit.Habit{
+		Name:          "programming",
+		CurrentStreak: 3,
+		LongestStreak: 3,
+		LastDone:      older,
+		History:       []habit.Completion{{At: older}},
+	}
+	importedNewer := habit.Habit{
+		Name:          "programming",
+		CurrentStreak: 8,
+		LongestStreak: 8,
+		LastDone:      newer,
+		History:       []habit.Completion{{At: newer}},
+	}
+	testCases := map[string]struct {
+		strategy habit.MergeStrategy
+		existing habit.Habit
+		imported habit.Habit
+		want     habit.Habit
+	}{
+		"keep-existing keeps existing habit": {
+			strategy: habit.MergeKeepExisting,
+			existing: existingOlder,
+			imported: importedNewer,
+			want:     existingOlder,
+		},
+		"overwrite replaces existing habit": {
+			strategy: habit.MergeOverwrite,
+			existing: existingNewer,
+			imported: importedOlder,
+			want:     importedOlder,
+		},
+		"latest keeps imported habit when it was done more recently": {
+			strategy: habit.MergeLatest,
+			existing: existingOlder,
+			imported: importedNewer,
+			want:     importedNewer,
+		},
+		"latest keeps existing habit when it was done more recently": {
+			strategy: habit.MergeLatest,
+			existing: existingNewer,
+			imported: importedOlder,
+			want:     existingNewer,
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			store, err := habit.OpenStore(t.TempDir() + "/test.store")
+			if err != nil {
+				t.Fatal(err)
+			}
+			store.Add(tc.existing)
+			tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(io.Discard))
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = tracker.Import(openStoreFile(t, tc.imported), habit.FormatStore, tc.strategy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, ok := store.Get("programming")
+			if !ok {
+				t.Fatal("expected habit 'programming' to be present in store")
+			}
+			if !cmp.Equal(tc.want, got) {
+				t.Error(cmp.Diff(tc.want, got))
+			}
+		})
+	}
+}
+
+func TestTracker_ImportAddsNonConflictingHabits(t *testing.T) {
+	t.Parallel()
+	store, err := habit.OpenStore(t.TempDir() + "/test.store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{Name: "habit1"})
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(io.Discard))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Import(openStoreFile(t, habit.Habit{Name: "habit2"}), habit.FormatStore, habit.MergeKeepExisting)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []habit.Habit{
+		{Name: "habit1"},
+		{Name: "habit2"},
+	}
+	got := store.All()
+	if !cmp.Equal(want, got, habitSliceCmpOpt) {
+		t.Error(cmp.Diff(want, got, habitSliceCmpOpt))
+	}
+}
+
+func TestTracker_ImportNamesHabitsAfterTheirKeys(t *testing.T) {
+	t.Parallel()
+	testCases := map[string]struct {
+		data    string
+		want    []habit.Habit
+		wantErr bool
+	}{
+		"unnamed habit": {
+			data: `{"version": 1, "habits": {"reading": {"current_streak": 1}}}`,
+			want: []habit.Habit{{Name: "reading", CurrentStreak: 1}},
+		},
+		"habit named differently": {
+			data:    `{"version": 1, "habits": {"reading": {"name": "books"}}}`,
+			wantErr: true,
+		},
+		"empty key": {
+			data:    `{"version": 1, "habits": {"": {"name": ""}}}`,
+			wantErr: true,
+		},
+		"negative target": {
+			data:    `{"version": 1, "habits": {"water": {"name": "water", "target": -8}}}`,
+			wantErr: true,
+		},
+		"unknown frequency": {
+			data:    `{"version": 1, "habits": {"reading": {"name": "reading", "frequency": -1}}}`,
+			wantErr: true,
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			store, err := habit.OpenStore(t.TempDir() + "/test.store")
+			if err != nil {
+				t.Fatal(err)
+			}
+			tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(io.Discard))
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = tracker.Import(strings.NewReader(tc.data), habit.FormatJSON, habit.MergeLatest)
+			if tc.wantErr {
+				if err == nil {
+					t.Error("want error")
+				}
+				if got := store.All(); len(got) != 0 {
+					t.Errorf("want nothing imported, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := store.All()
+			if !cmp.Equal(tc.want, got, habitSliceCmpOpt) {
+				t.Error(cmp.Diff(tc.want, got, habitSliceCmpOpt))
+			}
+		})
+	}
+}
+
+func TestParseMergeStrategyReturnsErrorForUnknownName(t *testing.T) {
+	t.Parallel()
+	_, err := habit.ParseMergeStrategy("bogus")
+	if err == nil {
+		t.Error("expected an error when parsing unknown merge strategy")
+	}
+}
+
+// openStoreFile saves the given habits to a temporary store file and returns
+// the opened file.
+func openStoreFile(t *testing.T, habits ...habit.Habit) *os.File {
+	t.Helper()
+	path := t.TempDir() + "/import.store"
+	store, err := habit.OpenStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, hbt := range habits {
+		store.Add(hbt)
+	}
+	err = store.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { f.Close() })
+	return f
+}
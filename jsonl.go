@@ -0,0 +1,66 @@
+package habit
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+	"time"
+)
+
+// errJSONLImport is returned when decoding JSON Lines data, which can only be
+// exported.
+var errJSONLImport = errors.New("importing JSON Lines files is not supported")
+
+// A completionEvent is a line of the JSON Lines event stream written by
+// jsonlCodec.
+type completionEvent struct {
+	// Habit is the name of the habit that was done.
+	Habit string `json:"habit"`
+	// Timestamp is when the habit was done.
+	Timestamp time.Time `json:"timestamp"`
+	// Note is the note attached to the completion, if any.
+	Note string `json:"note,omitempty"`
+	// Amount is the amount logged when the completion reached the target
+	// of a quantity habit, if any.
+	Amount float64 `json:"amount,omitempty"`
+}
+
+// jsonlCodec encodes habit data as a JSON Lines event stream, with a JSON
+// object on each line for every completion of every habit, so that the whole
+// history can be piped into jq or loaded by analytics tools one event at a
+// time. Habits that have never been done are not written, and the data cannot
+// be imported.
+type jsonlCodec struct{}
+
+// Encode writes an event for every completion in the given habit data to w,
+// in chronological order, with completions at the same time ordered by habit
+// name.
+func (jsonlCodec) Encode(w io.Writer, data map[string]Habit) error {
+	var events []completionEvent
+	for _, hbt := range data {
+		for _, c := range hbt.History {
+			events = append(events, completionEvent{Habit: hbt.Name, Timestamp: c.At, Note: c.Note, Amount: c.Amount})
+		}
+	}
+	sort.Slice(events, func(i, j int) bool {
+		if !events[i].Timestamp.Equal(events[j].Timestamp) {
+			return events[i].Timestamp.Before(events[j].Timestamp)
+		}
+		return events[i].Habit < events[j].Habit
+	})
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	for _, e := range events {
+		err := enc.Encode(e)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Decode returns an error, since JSON Lines data cannot be imported.
+func (jsonlCodec) Decode(io.Reader, *map[string]Habit) error {
+	return errJSONLImport
+}
@@ -0,0 +1,295 @@
+package habit
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// A Catalog holds the text/template templates of a locale's messages by
+// message name, such as "streak" or "summary_streak". Each template is
+// executed with a MessageData and may call plural, like the templates given
+// with WithMessages. Messages missing from a Catalog are written in English.
+type Catalog map[string]string
+
+// DefaultLocale is the name of the locale whose messages are built in.
+const DefaultLocale = "en"
+
+// catalogEN holds the built-in English templates of the messages that are not
+// in defaultMessages. Words such as "day" or "today" are the names of the
+// words a message may be built from, and are translated like any other
+// message.
+var catalogEN = Catalog{
+	"day":         "day",
+	"days":        "days",
+	"week":        "week",
+	"weeks":       "weeks",
+	"period":      "period",
+	"periods":     "periods",
+	"today":       "today",
+	"this week":   "this week",
+	"this period": "this period",
+	"and":         "and",
+	"or":          "or",
+
+	"freeze": "You missed a {{.Period}} of '{{.Name}}', so a streak freeze kept your {{.Streak}}-{{.Period}} streak going. " +
+		"You have {{.Freezes}} {{plural .Freezes \"streak freeze\" \"streak freezes\"}} left.",
+	"backdated":       "Logged the habit '{{.Name}}' as done on {{.Date}}. You're now on a {{.Streak}}-{{.Period}} streak.",
+	"milestone":       "That's a streak milestone: you've earned the {{.Streak}}-{{.Period}} badge!",
+	"milestone_again": "You've reached the {{.Streak}}-{{.Period}} streak milestone again!",
+	"goal_reached": "You've reached your goal of {{.Goal}} {{plural .Goal .Period .Units}} in a row! " +
+		"If you're done with it, run 'habit archive {{.Name}}'.",
+
+	"tracked_all":       "Nice work! You tracked {{.Count}} habits: {{.List}}.",
+	"tracked_habit":     "'{{.Name}}' ({{.Summary}})",
+	"tracked_new_habit": "new habit",
+	"tracked_again":     "again {{.Current}}",
+	"tracked_freeze":    "{{.Streak}}-{{.Period}} streak, kept by a streak freeze",
+	"tracked_reset":     "new streak",
+	"tracked_streak":    "{{.Streak}}-{{.Period}} streak",
+	"tracked_backdated": "logged on {{.Date}}, {{.Streak}}-{{.Period}} streak",
+	"tracked_badge":     "badge earned",
+	"tracked_goal":      "goal reached",
+	"tracked_points":    "+{{.Points}} points",
+
+	"points_awarded": "+{{.Points}} points.",
+	"level_up":       "You've reached level {{.Level}}!",
+
+	"summary_paused":        "'{{.Name}}' is paused, so your {{.Streak}}-{{.Period}} streak is safe until you resume it.",
+	"summary_amount":        "You've logged {{.Amount}} of {{.Target}} for '{{.Name}}' {{.Current}}.",
+	"summary_amount_streak": "You're on a {{.Streak}}-{{.Period}} streak.",
+	"summary_not_started":   "You haven't done '{{.Name}}' yet. Today's a good day to start!",
+	"summary_broken": "It's been {{.DaysSince}} {{plural .DaysSince \"day\" \"days\"}} since you did '{{.Name}}'. " +
+		"Stay positive and get back on it!",
+	"summary_best": "You are currently on a {{.Streak}}-{{.Period}} streak for '{{.Name}}'. " +
+		"That's a new personal best. Keep it going!",
+	"summary_streak": "You are currently on a {{.Streak}}-{{.Period}} streak for '{{.Name}}'. Keep it going!",
+	"summary_avoid_best": "You've avoided '{{.Name}}' for {{.Streak}} {{plural .Streak \"day\" \"days\"}}. " +
+		"That's a new personal best. Keep it up!",
+	"summary_avoid":               "You've avoided '{{.Name}}' for {{.Streak}} {{plural .Streak \"day\" \"days\"}}. Keep it up!",
+	"summary_goal":                "Goal: {{.Period}} {{.Progress}}/{{.Goal}}.",
+	"summary_goal_reached":        "Goal of {{.Goal}} {{plural .Goal .Period .Units}} reached!",
+	"summary_strength":            "Strength: {{.Strength}}%.",
+	"summary_badge":               "{{.Streak}}-{{.Period}}",
+	"summary_badges":              "Badges: {{.List}}.",
+	"summary_routine":             "Routine '{{.Routine}}':",
+	"summary_named_goal":          "Goal '{{.GoalName}}': {{.Percent}}% over the last 7 days.",
+	"summary_named_goal_previous": "Goal '{{.GoalName}}': {{.Percent}}% over the last 7 days ({{.PreviousPercent}}% the 7 days before).",
+	"no_habits":                   "You're not currently tracking any habits.",
+	"no_habits_tagged":            "You're not tracking any habits tagged {{.List}}.",
+
+	"reminder_title": "Habit reminder",
+	"reminder_due": "You haven't done {{.List}} yet. " +
+		"Do {{plural .Count \"it\" \"them\"}} soon to keep your {{plural .Count \"streak\" \"streaks\"}} going!",
+	"reminder_overdue":  "{{.List}} {{plural .Count \"is\" \"are\"}} overdue.",
+	"reminder_deadline": "'{{.Name}}' (due by {{.Deadline}})",
+}
+
+var (
+	// localesMu guards locales.
+	localesMu sync.RWMutex
+	// locales holds the Catalogs of the registered locales by name.
+	locales = map[string]Catalog{
+		DefaultLocale: {},
+		"de":          catalogDE,
+		"es":          catalogES,
+	}
+)
+
+// RegisterLocale registers the Catalog of the locale with the given name, such
+// as "fr" or "pt_BR", so that it can be selected with WithLocale, replacing any
+// Catalog already registered under that name. An error is returned if the name
+// is empty, or if the Catalog holds an unknown message or a template that
+// cannot be parsed or executed.
+func RegisterLocale(name string, c Catalog) error {
+	name = normalizeLocale(name)
+	if name == "" {
+		return errors.New("locale name must be non-empty")
+	}
+	tmpl, err := parseCatalog(parseMessages(), c)
+	if err != nil {
+		return fmt.Errorf("invalid locale %s: %w", name, err)
+	}
+	err = checkMessages(tmpl)
+	if err != nil {
+		return fmt.Errorf("invalid locale %s: %w", name, err)
+	}
+	localesMu.Lock()
+	defer localesMu.Unlock()
+	locales[name] = c
+	return nil
+}
+
+// Locales returns the names of the registered locales in sorted order.
+func Locales() []string {
+	localesMu.RLock()
+	defer localesMu.RUnlock()
+	names := make([]string, 0, len(locales))
+	for name := range locales {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WithLocale returns an option that makes a Tracker write the messages of its
+// Track, summary and reminder output in the registered locale with the given
+// name. The name may be a POSIX locale such as "de_DE.UTF-8", in which case
+// the locale of its language, "de", is used if no locale is registered for
+// "de_DE". Templates given with WithMessages or WithMessageFile take
+// precedence over the locale's if they come later in the options. An error is
+// returned if no such locale is registered.
+func WithLocale(name string) option {
+	return func(t *Tracker) error {
+		c, ok := lookupLocale(name)
+		if !ok {
+			return fmt.Errorf("unknown locale %q (want one of %s)", name, strings.Join(Locales(), ", "))
+		}
+		tmpl, err := t.messages.Clone()
+		if err != nil {
+			return err
+		}
+		tmpl, err = parseCatalog(tmpl, c)
+		if err != nil {
+			return fmt.Errorf("invalid locale %s: %w", name, err)
+		}
+		err = checkMessages(tmpl)
+		if err != nil {
+			return fmt.Errorf("invalid locale %s: %w", name, err)
+		}
+		t.messages = tmpl
+		return nil
+	}
+}
+
+// LocaleFromEnv returns the locale named by the first of the LC_ALL,
+// LC_MESSAGES and LANG environment variables that is set, or DefaultLocale if
+// none is set or it names the "C" or "POSIX" locale.
+func LocaleFromEnv() string {
+	for _, key := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		value := os.Getenv(key)
+		if value == "" {
+			continue
+		}
+		if value == "C" || value == "POSIX" || strings.HasPrefix(value, "C.") {
+			return DefaultLocale
+		}
+		return value
+	}
+	return DefaultLocale
+}
+
+// lookupLocale returns the Catalog registered for the locale with the given
+// name, or for its language, and reports whether there is one.
+func lookupLocale(name string) (Catalog, bool) {
+	name = normalizeLocale(name)
+	localesMu.RLock()
+	defer localesMu.RUnlock()
+	if c, ok := locales[name]; ok {
+		return c, true
+	}
+	lang, _, found := strings.Cut(name, "_")
+	if !found {
+		return nil, false
+	}
+	c, ok := locales[lang]
+	return c, ok
+}
+
+// normalizeLocale returns the given locale name without its encoding or
+// modifier, such as ".UTF-8", with a lower-case language and "_" separating
+// the language from its region.
+func normalizeLocale(name string) string {
+	name, _, _ = strings.Cut(name, ".")
+	name, _, _ = strings.Cut(name, "@")
+	name = strings.ReplaceAll(strings.TrimSpace(name), "-", "_")
+	lang, region, found := strings.Cut(name, "_")
+	if !found {
+		return strings.ToLower(lang)
+	}
+	return strings.ToLower(lang) + "_" + region
+}
+
+// messageNames returns the names of every message that a Catalog may hold.
+func messageNames() []string {
+	var names []string
+	for name := range defaultMessages.byName() {
+		names = append(names, name)
+	}
+	for name := range catalogEN {
+		names = append(names, name)
+	}
+	return names
+}
+
+// isMessageName reports whether the given name is the name of a message.
+func isMessageName(name string) bool {
+	if _, ok := catalogEN[name]; ok {
+		return true
+	}
+	_, ok := defaultMessages.byName()[name]
+	return ok
+}
+
+// parseCatalog parses the templates of the given Catalog into the given
+// message templates, replacing the templates of the same names, and returns
+// the result. An error is returned if the Catalog holds an unknown message or
+// a template that cannot be parsed.
+func parseCatalog(tmpl *template.Template, c Catalog) (*template.Template, error) {
+	for name, text := range c {
+		if !isMessageName(name) {
+			return nil, fmt.Errorf("unknown message %q", name)
+		}
+		_, err := tmpl.New(name).Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s message: %w", name, err)
+		}
+	}
+	return tmpl, nil
+}
+
+// text executes the Tracker's message template with the given name with the
+// given data. The name is written as the message if the template fails, which
+// checkMessages makes unlikely.
+func (t *Tracker) text(name string, data MessageData) string {
+	buf := new(strings.Builder)
+	err := t.messages.ExecuteTemplate(buf, name, data)
+	if err != nil {
+		t.logger.Error("message template failed", "message", name, "error", err)
+		return name
+	}
+	return buf.String()
+}
+
+// word returns the Tracker's translation of the given word, such as "day" or
+// "and".
+func (t *Tracker) word(word string) string {
+	return t.text(word, MessageData{})
+}
+
+// messageData returns the MessageData describing the given Habit, with the
+// names of its period translated for the Tracker's locale.
+func (t *Tracker) messageData(hbt Habit) MessageData {
+	return MessageData{
+		Name:          hbt.Name,
+		Streak:        hbt.CurrentStreak,
+		LongestStreak: hbt.LongestStreak,
+		Unit:          t.word(hbt.Frequency.unit(hbt.CurrentStreak)),
+		Period:        t.word(hbt.Frequency.unit(1)),
+		Units:         t.word(hbt.Frequency.unit(2)),
+		Current:       t.word(hbt.Frequency.current()),
+		Goal:          hbt.Goal,
+		Freezes:       hbt.Freezes,
+	}
+}
+
+// joinWords joins the given items into a list in the Tracker's locale,
+// separating the last two items with the translation of the given
+// conjunction, "and" or "or".
+func (t *Tracker) joinWords(items []string, conjunction string) string {
+	return joinList(items, t.word(conjunction))
+}
@@ -0,0 +1,74 @@
+package habit
+
+// catalogDE holds the German templates of the messages.
+var catalogDE = Catalog{
+	"day":         "Tag",
+	"days":        "Tage",
+	"week":        "Woche",
+	"weeks":       "Wochen",
+	"period":      "Zeitraum",
+	"periods":     "Zeiträume",
+	"today":       "heute",
+	"this week":   "diese Woche",
+	"this period": "in diesem Zeitraum",
+	"and":         "und",
+	"or":          "oder",
+
+	"new_habit": "Glückwunsch zu deiner neuen Gewohnheit '{{.Name}}'! Vergiss nicht, sie zu wiederholen.",
+	"again":     "Super, du hast deine Gewohnheit '{{.Name}}' {{.Current}} mehr als einmal geübt!",
+	"streak":    "Gut gemacht: Du hast die Gewohnheit '{{.Name}}' jetzt {{.Streak}} {{.Unit}} in Folge geschafft.",
+	"reset": "Du hast die Gewohnheit '{{.Name}}' zuletzt vor {{.DaysSince}} {{plural .DaysSince \"Tag\" \"Tagen\"}} gemacht, " +
+		"also beginnst du heute eine neue Serie. Viel Erfolg!",
+	"freeze": "Du hast '{{.Name}}' einen Zeitraum lang verpasst, aber ein Serienschutz hat deine " +
+		"{{.Streak}}-{{.Units}}-Serie gerettet. Verbleibender Serienschutz: {{.Freezes}}.",
+	"backdated": "Die Gewohnheit '{{.Name}}' wurde als am {{.Date}} erledigt eingetragen. " +
+		"Du bist jetzt bei einer {{.Streak}}-{{.Units}}-Serie.",
+	"milestone":       "Ein Serien-Meilenstein: Du hast das Abzeichen {{.Streak}}-{{.Units}} verdient!",
+	"milestone_again": "Du hast den Meilenstein der {{.Streak}}-{{.Units}}-Serie erneut erreicht!",
+	"goal_reached": "Du hast dein Ziel erreicht: {{.Goal}} {{plural .Goal .Period .Units}} in Folge! " +
+		"Wenn du damit fertig bist, führe 'habit archive {{.Name}}' aus.",
+
+	"tracked_all":       "Gut gemacht! Du hast {{.Count}} Gewohnheiten eingetragen: {{.List}}.",
+	"tracked_habit":     "'{{.Name}}' ({{.Summary}})",
+	"tracked_new_habit": "neue Gewohnheit",
+	"tracked_again":     "{{.Current}} erneut",
+	"tracked_freeze":    "{{.Streak}}-{{.Units}}-Serie, durch Serienschutz gerettet",
+	"tracked_reset":     "neue Serie",
+	"tracked_streak":    "{{.Streak}}-{{.Units}}-Serie",
+	"tracked_backdated": "am {{.Date}} eingetragen, {{.Streak}}-{{.Units}}-Serie",
+	"tracked_badge":     "Abzeichen verdient",
+	"tracked_goal":      "Ziel erreicht",
+	"tracked_points":    "+{{.Points}} Punkte",
+
+	"points_awarded": "+{{.Points}} Punkte.",
+	"level_up":       "Du hast Level {{.Level}} erreicht!",
+
+	"summary_paused":        "'{{.Name}}' ist pausiert, deine {{.Streak}}-{{.Units}}-Serie ist also sicher, bis du weitermachst.",
+	"summary_amount":        "Du hast {{.Current}} {{.Amount}} von {{.Target}} für '{{.Name}}' eingetragen.",
+	"summary_amount_streak": "Du bist bei einer {{.Streak}}-{{.Units}}-Serie.",
+	"summary_not_started":   "Du hast '{{.Name}}' noch nicht gemacht. Heute ist ein guter Tag, um anzufangen!",
+	"summary_broken": "Es ist {{.DaysSince}} {{plural .DaysSince \"Tag\" \"Tage\"}} her, seit du '{{.Name}}' gemacht hast. " +
+		"Bleib positiv und fang wieder an!",
+	"summary_best": "Du bist gerade bei einer {{.Streak}}-{{.Units}}-Serie für '{{.Name}}'. " +
+		"Das ist ein neuer persönlicher Rekord. Weiter so!",
+	"summary_streak": "Du bist gerade bei einer {{.Streak}}-{{.Units}}-Serie für '{{.Name}}'. Weiter so!",
+	"summary_avoid_best": "Du hast '{{.Name}}' seit {{.Streak}} {{plural .Streak \"Tag\" \"Tagen\"}} vermieden. " +
+		"Das ist ein neuer persönlicher Rekord. Weiter so!",
+	"summary_avoid":               "Du hast '{{.Name}}' seit {{.Streak}} {{plural .Streak \"Tag\" \"Tagen\"}} vermieden. Weiter so!",
+	"summary_goal":                "Ziel: {{.Period}} {{.Progress}}/{{.Goal}}.",
+	"summary_goal_reached":        "Ziel erreicht: {{.Goal}} {{plural .Goal .Period .Units}}!",
+	"summary_strength":            "Stärke: {{.Strength}} %.",
+	"summary_badge":               "{{.Streak}}-{{.Units}}",
+	"summary_badges":              "Abzeichen: {{.List}}.",
+	"summary_routine":             "Routine '{{.Routine}}':",
+	"summary_named_goal":          "Ziel '{{.GoalName}}': {{.Percent}} % in den letzten 7 Tagen.",
+	"summary_named_goal_previous": "Ziel '{{.GoalName}}': {{.Percent}} % in den letzten 7 Tagen ({{.PreviousPercent}} % in den 7 Tagen davor).",
+	"no_habits":                   "Du verfolgst derzeit keine Gewohnheiten.",
+	"no_habits_tagged":            "Du verfolgst keine Gewohnheiten mit dem Tag {{.List}}.",
+
+	"reminder_title": "Gewohnheits-Erinnerung",
+	"reminder_due": "Du hast {{.List}} noch nicht erledigt. " +
+		"Mach {{plural .Count \"es\" \"sie\"}} bald, um {{plural .Count \"deine Serie\" \"deine Serien\"}} fortzusetzen!",
+	"reminder_overdue":  "{{.List}} {{plural .Count \"ist\" \"sind\"}} überfällig.",
+	"reminder_deadline": "'{{.Name}}' (fällig bis {{.Deadline}})",
+}
@@ -0,0 +1,76 @@
+package habit
+
+// catalogES holds the Spanish templates of the messages.
+var catalogES = Catalog{
+	"day":         "día",
+	"days":        "días",
+	"week":        "semana",
+	"weeks":       "semanas",
+	"period":      "periodo",
+	"periods":     "periodos",
+	"today":       "hoy",
+	"this week":   "esta semana",
+	"this period": "este periodo",
+	"and":         "y",
+	"or":          "o",
+
+	"new_habit": "¡Enhorabuena por empezar tu nuevo hábito '{{.Name}}'! No olvides repetirlo.",
+	"again":     "¡Muy bien, has practicado tu hábito '{{.Name}}' más de una vez {{.Current}}!",
+	"streak":    "Buen trabajo: llevas una racha de {{.Streak}} {{.Unit}} con el hábito '{{.Name}}'.",
+	"reset": "Hiciste el hábito '{{.Name}}' por última vez hace {{.DaysSince}} {{plural .DaysSince \"día\" \"días\"}}, " +
+		"así que hoy empiezas una nueva racha. ¡Suerte!",
+	"freeze": "No hiciste '{{.Name}}' durante un {{.Period}}, pero un comodín mantuvo tu racha de " +
+		"{{.Streak}} {{plural .Streak .Period .Units}}. " +
+		"Te {{plural .Freezes \"queda\" \"quedan\"}} {{.Freezes}} {{plural .Freezes \"comodín\" \"comodines\"}}.",
+	"backdated": "Se registró el hábito '{{.Name}}' como hecho el {{.Date}}. " +
+		"Ahora llevas una racha de {{.Streak}} {{plural .Streak .Period .Units}}.",
+	"milestone":       "¡Es un hito de racha: has ganado la insignia de {{.Streak}} {{plural .Streak .Period .Units}}!",
+	"milestone_again": "¡Has vuelto a alcanzar el hito de racha de {{.Streak}} {{plural .Streak .Period .Units}}!",
+	"goal_reached": "¡Has alcanzado tu meta de {{.Goal}} {{plural .Goal .Period .Units}}! " +
+		"Si ya terminaste con él, ejecuta 'habit archive {{.Name}}'.",
+
+	"tracked_all":       "¡Buen trabajo! Registraste {{.Count}} hábitos: {{.List}}.",
+	"tracked_habit":     "'{{.Name}}' ({{.Summary}})",
+	"tracked_new_habit": "hábito nuevo",
+	"tracked_again":     "otra vez {{.Current}}",
+	"tracked_freeze":    "racha de {{.Streak}} {{plural .Streak .Period .Units}}, mantenida por un comodín",
+	"tracked_reset":     "racha nueva",
+	"tracked_streak":    "racha de {{.Streak}} {{plural .Streak .Period .Units}}",
+	"tracked_backdated": "registrado el {{.Date}}, racha de {{.Streak}} {{plural .Streak .Period .Units}}",
+	"tracked_badge":     "insignia ganada",
+	"tracked_goal":      "meta alcanzada",
+	"tracked_points":    "+{{.Points}} puntos",
+
+	"points_awarded": "+{{.Points}} puntos.",
+	"level_up":       "¡Has alcanzado el nivel {{.Level}}!",
+
+	"summary_paused": "'{{.Name}}' está en pausa, así que tu racha de {{.Streak}} {{plural .Streak .Period .Units}} " +
+		"está a salvo hasta que lo reanudes.",
+	"summary_amount":        "Has registrado {{.Amount}} de {{.Target}} para '{{.Name}}' {{.Current}}.",
+	"summary_amount_streak": "Llevas una racha de {{.Streak}} {{plural .Streak .Period .Units}}.",
+	"summary_not_started":   "Todavía no has hecho '{{.Name}}'. ¡Hoy es un buen día para empezar!",
+	"summary_broken": "Han pasado {{.DaysSince}} {{plural .DaysSince \"día\" \"días\"}} desde que hiciste '{{.Name}}'. " +
+		"¡Sé positivo y retómalo!",
+	"summary_best": "Llevas una racha de {{.Streak}} {{plural .Streak .Period .Units}} con '{{.Name}}'. " +
+		"Es un nuevo récord personal. ¡Sigue así!",
+	"summary_streak": "Llevas una racha de {{.Streak}} {{plural .Streak .Period .Units}} con '{{.Name}}'. ¡Sigue así!",
+	"summary_avoid_best": "Has evitado '{{.Name}}' durante {{.Streak}} {{plural .Streak \"día\" \"días\"}}. " +
+		"Es un nuevo récord personal. ¡Sigue así!",
+	"summary_avoid":               "Has evitado '{{.Name}}' durante {{.Streak}} {{plural .Streak \"día\" \"días\"}}. ¡Sigue así!",
+	"summary_goal":                "Meta: {{.Period}} {{.Progress}}/{{.Goal}}.",
+	"summary_goal_reached":        "¡Meta de {{.Goal}} {{plural .Goal .Period .Units}} alcanzada!",
+	"summary_strength":            "Fuerza: {{.Strength}} %.",
+	"summary_badge":               "{{.Streak}} {{plural .Streak .Period .Units}}",
+	"summary_badges":              "Insignias: {{.List}}.",
+	"summary_routine":             "Rutina '{{.Routine}}':",
+	"summary_named_goal":          "Objetivo '{{.GoalName}}': {{.Percent}} % en los últimos 7 días.",
+	"summary_named_goal_previous": "Objetivo '{{.GoalName}}': {{.Percent}} % en los últimos 7 días ({{.PreviousPercent}} % los 7 días anteriores).",
+	"no_habits":                   "Ahora mismo no estás registrando ningún hábito.",
+	"no_habits_tagged":            "No estás registrando ningún hábito con la etiqueta {{.List}}.",
+
+	"reminder_title": "Recordatorio de hábitos",
+	"reminder_due": "Todavía no has hecho {{.List}}. " +
+		"¡{{plural .Count \"Hazlo\" \"Hazlos\"}} pronto para mantener {{plural .Count \"tu racha\" \"tus rachas\"}}!",
+	"reminder_overdue":  "{{.List}} {{plural .Count \"está atrasado\" \"están atrasados\"}}.",
+	"reminder_deadline": "'{{.Name}}' (antes de las {{.Deadline}})",
+}
@@ -0,0 +1,157 @@
+package habit_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTracker_TrackAndPrintSummaryInLocale(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T09:00:00Z")
+	lastDone := time.Date(2024, time.February, 5, 20, 0, 0, 0, time.UTC)
+	tests := map[string]string{
+		"es": "Buen trabajo: llevas una racha de 3 días con el hábito 'reading'.\n" +
+			"¡Enhorabuena por empezar tu nuevo hábito 'yoga'! No olvides repetirlo.\n" +
+			"Llevas una racha de 3 días con 'reading'. Es un nuevo récord personal. ¡Sigue así! Fuerza: 5 %.\n" +
+			"Has evitado 'smoking' durante 2 días. Es un nuevo récord personal. ¡Sigue así!\n" +
+			"Llevas una racha de 1 día con 'yoga'. ¡Sigue así! Fuerza: 5 %.\n",
+		"de_DE.UTF-8": "Gut gemacht: Du hast die Gewohnheit 'reading' jetzt 3 Tage in Folge geschafft.\n" +
+			"Glückwunsch zu deiner neuen Gewohnheit 'yoga'! Vergiss nicht, sie zu wiederholen.\n" +
+			"Du bist gerade bei einer 3-Tage-Serie für 'reading'. Das ist ein neuer persönlicher Rekord. Weiter so! Stärke: 5 %.\n" +
+			"Du hast 'smoking' seit 2 Tagen vermieden. Das ist ein neuer persönlicher Rekord. Weiter so!\n" +
+			"Du bist gerade bei einer 1-Tage-Serie für 'yoga'. Weiter so! Stärke: 5 %.\n",
+	}
+	for locale, want := range tests {
+		store := &memStore{habits: map[string]habit.Habit{
+			"reading": {Name: "reading", CurrentStreak: 2, LongestStreak: 2, LastDone: lastDone},
+			"smoking": {Name: "smoking", Avoid: true, LastDone: lastDone.Add(-24 * time.Hour)},
+		}}
+		output := new(bytes.Buffer)
+		tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output), habit.WithLocale(locale))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, name := range []string{"reading", "yoga"} {
+			err = tracker.Track(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		err = tracker.PrintSummary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := output.String(); want != got {
+			t.Errorf("%s: %s", locale, cmp.Diff(want, got))
+		}
+	}
+}
+
+func TestTracker_RemindDueInLocale(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T20:00:00Z")
+	doneYesterday := habit.Now().Add(-21 * time.Hour)
+	store := &memStore{habits: map[string]habit.Habit{
+		"reading": {Name: "reading", LastDone: doneYesterday},
+		"running": {Name: "running", LastDone: doneYesterday, Deadline: &habit.TimeOfDay{Hour: 9}},
+	}}
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(new(bytes.Buffer)), habit.WithLocale("es"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	notifier := new(recordingNotifier)
+	err = tracker.RemindDue(notifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"Todavía no has hecho 'reading' o 'running'. ¡Hazlos pronto para mantener tus rachas! " +
+			"'running' (antes de las 09:00) está atrasado.",
+	}
+	if !cmp.Equal(want, notifier.messages) {
+		t.Error(cmp.Diff(want, notifier.messages))
+	}
+}
+
+func TestRegisterLocaleAddsLocaleFallingBackToEnglish(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T09:00:00Z")
+	err := habit.RegisterLocale("fr", habit.Catalog{
+		"day":       "jour",
+		"days":      "jours",
+		"new_habit": "Bravo pour votre nouvelle habitude « {{.Name}} » !",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(
+		habit.WithStore(&memStore{habits: map[string]habit.Habit{}}),
+		habit.WithOutput(output),
+		habit.WithLocale("fr_CA"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Track("reading")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.PrintSummary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Bravo pour votre nouvelle habitude « reading » !\n" +
+		"You are currently on a 1-jour streak for 'reading'. Keep it going! Strength: 5%.\n"
+	if got := output.String(); want != got {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestRegisterLocaleReturnsErrorForInvalidCatalog(t *testing.T) {
+	t.Parallel()
+	tests := map[string]habit.Catalog{
+		"unknown message": {"goodbye": "Adieu"},
+		"unparseable":     {"streak": "{{.Name"},
+		"unknown field":   {"summary_streak": "{{.Habit}}"},
+	}
+	for name, c := range tests {
+		err := habit.RegisterLocale("xx", c)
+		if err == nil {
+			t.Errorf("%s: want error, got nil", name)
+		}
+	}
+	err := habit.RegisterLocale("", habit.Catalog{})
+	if err == nil {
+		t.Error("want error for empty locale name, got nil")
+	}
+}
+
+func TestNewTrackerReturnsErrorForUnknownLocale(t *testing.T) {
+	t.Parallel()
+	_, err := habit.NewTracker(habit.WithStore(&memStore{habits: map[string]habit.Habit{}}), habit.WithLocale("tlh"))
+	if err == nil {
+		t.Error("want error for unknown locale, got nil")
+	}
+}
+
+func TestLocaleFromEnv(t *testing.T) {
+	tests := []struct {
+		lcAll, lang string
+		want        string
+	}{
+		{"", "", habit.DefaultLocale},
+		{"", "C.UTF-8", habit.DefaultLocale},
+		{"", "de_DE.UTF-8", "de_DE.UTF-8"},
+		{"es_ES.UTF-8", "de_DE.UTF-8", "es_ES.UTF-8"},
+	}
+	for _, tc := range tests {
+		t.Setenv("LC_ALL", tc.lcAll)
+		t.Setenv("LC_MESSAGES", "")
+		t.Setenv("LANG", tc.lang)
+		if got := habit.LocaleFromEnv(); tc.want != got {
+			t.Errorf("LC_ALL=%q LANG=%q: want %q, got %q", tc.lcAll, tc.lang, tc.want, got)
+		}
+	}
+}
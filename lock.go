@@ -0,0 +1,112 @@
+package habit
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// errLockHeld is returned by tryLock when another process holds the lock.
+var errLockHeld = errors.New("lock is held by another process")
+
+// lockRetryInterval is how long acquireLock waits between attempts to take a
+// lock that is held by another process.
+const lockRetryInterval = 50 * time.Millisecond
+
+// WithLock returns a storeOption that makes a store hold an exclusive advisory
+// lock on a ".lock" file next to its store file from when it is opened until
+// it is closed, so that concurrent habit processes serialize their changes
+// instead of overwriting each other's saves. If the lock is held by another
+// process, opening the store waits up to the given timeout before failing.
+func WithLock(timeout time.Duration) storeOption {
+	return func(s *FileStore) {
+		s.lockTimeout = timeout
+		s.locking = true
+	}
+}
+
+// acquireLock opens the lock file at the given path and takes an exclusive
+// lock on it, retrying until the timeout elapses. The returned file must be
+// closed to release the lock.
+func acquireLock(path string, timeout time.Duration) (*os.File, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("error opening lock file %q: %w", path, err)
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		err = tryLock(f)
+		if err == nil {
+			return f, nil
+		}
+		if !errors.Is(err, errLockHeld) || time.Now().After(deadline) {
+			f.Close()
+			break
+		}
+		time.Sleep(lockRetryInterval)
+	}
+	if errors.Is(err, errLockHeld) {
+		return nil, errorOf(ErrStoreLocked, "store is locked by another habit process (lock file %q)", path)
+	}
+	return nil, fmt.Errorf("error locking %q: %w", path, err)
+}
+
+// WithLockPerChange returns a storeOption that makes a store take the lock
+// that WithLock holds only while a change is made, rather than for as long as
+// the store is open, so that a long-running process such as a server lets
+// other habit processes save changes in between its own. Changes made by a
+// Tracker hold the lock from before the store is reloaded until it is saved.
+// If the lock is held by another process, each change waits up to the given
+// timeout before failing.
+func WithLockPerChange(timeout time.Duration) storeOption {
+	return func(s *FileStore) {
+		s.lockTimeout = timeout
+		s.lockPerChange = true
+	}
+}
+
+// lockChange takes the store's lock and reloads its habits, if it was opened
+// with WithLockPerChange, and returns a function that releases the lock. For
+// other stores, it does nothing. An error is returned if the lock cannot be
+// taken or the store cannot be reloaded.
+func (s *FileStore) lockChange() (func(), error) {
+	if !s.lockPerChange {
+		return func() {}, nil
+	}
+	lock, err := acquireLock(s.path+".lock", s.lockTimeout)
+	if err != nil {
+		return nil, err
+	}
+	err = s.Reload()
+	if err != nil {
+		lock.Close()
+		return nil, err
+	}
+	return func() { lock.Close() }, nil
+}
+
+// lockChange takes the lock of the given Store for a change, as store's
+// lockChange does, if it is a Store that is only locked while it changes, and
+// returns a function that releases the lock. Other Stores are left as they
+// are.
+func lockChange(s Store) (func(), error) {
+	l, ok := s.(interface{ lockChange() (func(), error) })
+	if !ok {
+		return func() {}, nil
+	}
+	return l.lockChange()
+}
+
+// update calls fn, which changes and saves the Tracker's Habits, while holding
+// the lock of its store, having reloaded the store first, if the store is only
+// locked while it changes. The error returned by fn is returned, or an error
+// if the lock cannot be taken.
+func (t *Tracker) update(fn func() error) error {
+	unlock, err := lockChange(t.store)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return fn()
+}
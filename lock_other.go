@@ -0,0 +1,10 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd || windows)
+
+package habit
+
+import "os"
+
+// tryLock is a no-op on platforms without advisory file locking support.
+func tryLock(f *os.File) error {
+	return nil
+}
@@ -0,0 +1,29 @@
+package habit
+
+import "fmt"
+
+// logTimeFormat is the layout of completion timestamps written by PrintLog.
+const logTimeFormat = "2006-01-02 15:04"
+
+// PrintLog writes every completion of the Habit with the given name to the
+// given Tracker's output in chronological order, one per line, followed by its
+// note if it has one. An error is returned if the Habit does not exist.
+func (t *Tracker) PrintLog(hbtName string) error {
+	hbt, ok := t.store.Get(hbtName)
+	if !ok {
+		return errHabitNotFound(hbtName)
+	}
+	if len(hbt.History) < 1 {
+		fmt.Fprintf(t.output, "The habit '%s' has not been done yet.\n", hbtName)
+		return nil
+	}
+	for _, c := range hbt.History {
+		at := c.At.In(t.calendar.location).Format(logTimeFormat)
+		if c.Note == "" {
+			fmt.Fprintln(t.output, at)
+			continue
+		}
+		fmt.Fprintf(t.output, "%s  %s\n", at, c.Note)
+	}
+	return nil
+}
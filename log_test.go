@@ -0,0 +1,97 @@
+package habit_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTracker_TrackNoteStoresNoteWithCompletion(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T13:00:00Z")
+	first, err := time.Parse(time.RFC3339, "2024-02-05T20:00:00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	backdated := first.Add(-12 * time.Hour)
+	store, err := habit.OpenStore(t.TempDir() + "/test.store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(io.Discard))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.TrackNote("running", first, "5k in the rain")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.TrackNote("running", habit.Now(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.TrackNote("running", backdated, "easy jog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, ok := store.Get("running")
+	if !ok {
+		t.Fatal("expected habit 'running' to be present in store")
+	}
+	want := []habit.Completion{
+		{At: backdated, Note: "easy jog"},
+		{At: first, Note: "5k in the rain"},
+		{At: habit.Now()},
+	}
+	if !cmp.Equal(want, got.History) {
+		t.Error(cmp.Diff(want, got.History))
+	}
+}
+
+func TestTracker_PrintLogWritesCompletionsWithNotes(t *testing.T) {
+	t.Parallel()
+	first := time.Date(2024, time.February, 5, 7, 30, 0, 0, time.UTC)
+	store, err := habit.OpenStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{
+		Name: "running",
+		History: []habit.Completion{
+			{At: first, Note: "5k in the rain"},
+			{At: first.Add(25 * time.Hour)},
+		},
+	})
+	store.Add(habit.Habit{Name: "reading"})
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(
+		habit.WithStore(store),
+		habit.WithOutput(output),
+		habit.WithLocation(time.UTC),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.PrintLog("running")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.PrintLog("reading")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "2024-02-05 07:30  5k in the rain\n" +
+		"2024-02-06 08:30\n" +
+		"The habit 'reading' has not been done yet.\n"
+	got := output.String()
+	if want != got {
+		t.Errorf("want output %q, got output %q", want, got)
+	}
+	err = tracker.PrintLog("writing")
+	if err == nil {
+		t.Error("expected an error printing the log of a habit that does not exist")
+	}
+}
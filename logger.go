@@ -0,0 +1,75 @@
+package habit
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// WithLogger returns an option that makes a Tracker write structured logs to
+// the given slog.Handler, such as when the store is saved and how long the
+// save took, or when a Habit is tracked. A Tracker without a logger writes no
+// logs.
+func WithLogger(h slog.Handler) option {
+	return func(t *Tracker) error {
+		if h == nil {
+			return errors.New("log handler must be non-nil")
+		}
+		t.logger = slog.New(h)
+		return nil
+	}
+}
+
+// discardHandler is a slog.Handler that discards every log record.
+type discardHandler struct{}
+
+// Enabled reports that no level is enabled.
+func (discardHandler) Enabled(context.Context, slog.Level) bool { return false }
+
+// Handle discards the given record.
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+
+// WithAttrs returns the handler itself.
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+
+// WithGroup returns the handler itself.
+func (h discardHandler) WithGroup(string) slog.Handler { return h }
+
+// save saves the Tracker's store like saveContext, with no deadline.
+func (t *Tracker) save() error {
+	return t.saveContext(context.Background())
+}
+
+// saveContext saves the Tracker's store under the given context, logging how
+// long the save took and whether it failed.
+func (t *Tracker) saveContext(ctx context.Context) error {
+	start := time.Now()
+	err := SaveContext(ctx, t.store)
+	if err != nil {
+		t.logger.ErrorContext(ctx, "store save failed", "duration", time.Since(start), "error", err)
+		return err
+	}
+	t.logger.DebugContext(ctx, "store saved", "duration", time.Since(start))
+	return nil
+}
+
+// logTracked logs that the given Habit was tracked at the given timestamp.
+func (t *Tracker) logTracked(ctx context.Context, hbt Habit, at time.Time) {
+	t.logger.InfoContext(ctx, "habit tracked", "habit", hbt.Name, "at", at, "streak", hbt.CurrentStreak)
+}
+
+// A statusRecorder is an http.ResponseWriter that records the status code of
+// the response so that it can be logged.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader records the given status code and writes it to the underlying
+// http.ResponseWriter.
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
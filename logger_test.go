@@ -0,0 +1,94 @@
+package habit_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+// logRecords decodes the JSON log records written to the given buffer,
+// dropping the time and duration of each record so that they can be compared.
+func logRecords(t *testing.T, logs *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var records []map[string]any
+	scanner := bufio.NewScanner(logs)
+	for scanner.Scan() {
+		var rec map[string]any
+		err := json.Unmarshal(scanner.Bytes(), &rec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		delete(rec, slog.TimeKey)
+		delete(rec, "duration")
+		records = append(records, rec)
+	}
+	return records
+}
+
+func TestTracker_TrackLogsSaveAndTrackedHabit(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T09:00:00Z")
+	logs := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(
+		habit.WithStore(&memStore{habits: map[string]habit.Habit{}}),
+		habit.WithOutput(new(bytes.Buffer)),
+		habit.WithLogger(slog.NewJSONHandler(logs, &slog.HandlerOptions{Level: slog.LevelDebug})),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Track("programming")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []map[string]any{
+		{"level": "DEBUG", "msg": "store saved"},
+		{"level": "INFO", "msg": "habit tracked", "habit": "programming", "at": "2024-02-06T09:00:00Z", "streak": 1.0},
+	}
+	got := logRecords(t, logs)
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestNewTrackerReturnsErrorForNilLogHandler(t *testing.T) {
+	t.Parallel()
+	_, err := habit.NewTracker(habit.WithStore(&memStore{habits: map[string]habit.Habit{}}), habit.WithLogger(nil))
+	if err == nil {
+		t.Error("want error for nil log handler")
+	}
+}
+
+func TestServer_LogsHandledRequests(t *testing.T) {
+	t.Parallel()
+	logs := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(
+		habit.WithStore(&memStore{habits: map[string]habit.Habit{}}),
+		habit.WithOutput(new(bytes.Buffer)),
+		habit.WithLogger(slog.NewJSONHandler(logs, nil)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(habit.NewServer(tracker))
+	defer srv.Close()
+	resp, err := http.Get(srv.URL + "/habits/missing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	srv.Close()
+	want := []map[string]any{
+		{"level": "INFO", "msg": "request handled", "method": "GET", "path": "/habits/missing", "status": 404.0},
+	}
+	got := logRecords(t, logs)
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
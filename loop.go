@@ -0,0 +1,422 @@
+package habit
+
+import (
+	"archive/zip"
+	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// errLoopExport is returned when encoding Loop Habit Tracker data, which can
+// only be imported.
+var errLoopExport = errors.New("exporting Loop Habit Tracker files is not supported")
+
+// Magic numbers at the start of the files that Loop Habit Tracker exports.
+const (
+	loopSQLiteMagic = "SQLite format 3\x00"
+	loopZipMagic    = "PK\x03\x04"
+)
+
+const (
+	// loopYesManual is the value of a repetition of a yes-or-no habit that
+	// the user checked. Other values, such as those of the automatic
+	// checkmarks on the days covered by a habit done less than daily, are not
+	// completions.
+	loopYesManual = 2
+	// loopNumerical is the type of a habit whose repetitions record an
+	// amount, in thousandths, rather than yes or no.
+	loopNumerical = 1
+	// loopAtMost is the target type of a numerical habit whose amount must
+	// not exceed its target.
+	loopAtMost = 1
+)
+
+// loopCodec decodes the data of Loop Habit Tracker, the Android app, from
+// either the SQLite backup made with its "Export full backup" setting or the
+// zip archive of CSV files made with its "Export as CSV" setting. Each Loop
+// habit becomes a Habit whose History holds a completion on every day the
+// habit was checked, or on which a numerical habit met its target, and whose
+// streaks are recomputed from that history with computeStreaks. The codec
+// cannot encode.
+type loopCodec struct {
+	// calendar determines the timestamps of the dates that Loop records.
+	calendar calendar
+}
+
+// A loopHabit is a habit read from a Loop Habit Tracker export, before its
+// repetitions are turned into completions.
+type loopHabit struct {
+	name     string
+	freqNum  int
+	freqDen  int
+	archived bool
+	kind     int
+	// targetType and target apply to numerical habits only.
+	targetType int
+	target     float64
+	unit       string
+	reminder   *TimeOfDay
+	reps       []loopRepetition
+}
+
+// A loopRepetition is the value a Loop habit has on a date, in thousandths
+// for numerical habits.
+type loopRepetition struct {
+	year  int
+	month time.Month
+	day   int
+	value int64
+	note  string
+}
+
+// Encode returns an error, since Loop Habit Tracker data can only be imported.
+func (loopCodec) Encode(io.Writer, map[string]Habit) error {
+	return errLoopExport
+}
+
+// Decode reads a Loop Habit Tracker backup or CSV export from r into data.
+func (c loopCodec) Decode(r io.Reader, data *map[string]Habit) error {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	var habits []loopHabit
+	switch {
+	case bytes.HasPrefix(raw, []byte(loopSQLiteMagic)):
+		habits, err = readLoopBackup(raw)
+	case bytes.HasPrefix(raw, []byte(loopZipMagic)):
+		habits, err = readLoopCSV(raw)
+	default:
+		return errors.New("not a Loop Habit Tracker backup (.db) or CSV export (.zip)")
+	}
+	if err != nil {
+		return err
+	}
+	imported := map[string]Habit{}
+	for _, lh := range habits {
+		hbt := c.habit(lh)
+		if hbt.Name == "" {
+			continue
+		}
+		addUnique(imported, hbt)
+	}
+	*data = imported
+	return nil
+}
+
+// habit returns the Habit imported from the given Loop habit, with an empty
+// name if the Loop habit has none.
+func (c loopCodec) habit(lh loopHabit) Habit {
+	hbt := Habit{
+		Name:         strings.TrimSpace(lh.name),
+		Frequency:    loopFrequency(lh.freqNum, lh.freqDen),
+		Archived:     lh.archived,
+		ReminderTime: lh.reminder,
+	}
+	numerical := lh.kind == loopNumerical
+	if numerical {
+		hbt.Target = lh.target
+		hbt.Unit = strings.TrimSpace(lh.unit)
+	}
+	sort.Slice(lh.reps, func(i, j int) bool {
+		a, b := lh.reps[i], lh.reps[j]
+		return a.year < b.year || a.year == b.year && (a.month < b.month || a.month == b.month && a.day < b.day)
+	})
+	for _, rep := range lh.reps {
+		// Dates start at the calendar's day start, so that each completion
+		// falls on the date Loop recorded it on.
+		at := time.Date(rep.year, rep.month, rep.day, c.calendar.dayStart, 0, 0, 0, c.calendar.location)
+		if !numerical {
+			if rep.value == loopYesManual {
+				hbt.History = append(hbt.History, Completion{At: at, Note: rep.note})
+			}
+			continue
+		}
+		if rep.value < 0 {
+			continue
+		}
+		amount := float64(rep.value) / 1000
+		hbt.Amount, hbt.AmountAt = amount, at
+		done := amount >= hbt.Target
+		if lh.targetType == loopAtMost {
+			done = amount <= hbt.Target
+		}
+		if done {
+			hbt.History = append(hbt.History, Completion{At: at, Note: rep.note, Amount: amount})
+		}
+	}
+	if len(hbt.History) > 0 {
+		hbt.CurrentStreak, hbt.LongestStreak = computeStreaks(hbt, c.calendar)
+		hbt.LastDone = hbt.History[len(hbt.History)-1].At
+	}
+	return hbt
+}
+
+// loopFrequency returns the Frequency of a Loop habit done num times every den
+// days. Habits done several times within their period are given the Frequency
+// of the number of days between those times, rounded down.
+func loopFrequency(num, den int) Frequency {
+	if num <= 0 || den <= 0 {
+		return Daily
+	}
+	return Frequency(max(den/num, 1))
+}
+
+// readLoopBackup returns the habits in the given Loop Habit Tracker SQLite
+// backup, which is copied to a temporary file so that it can be opened.
+func readLoopBackup(raw []byte) ([]loopHabit, error) {
+	f, err := os.CreateTemp("", "habit-loop-*.db")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f.Name())
+	_, err = f.Write(raw)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("sqlite", f.Name())
+	if err != nil {
+		return nil, fmt.Errorf("error opening Loop Habit Tracker backup: %w", err)
+	}
+	defer db.Close()
+	habitCols, err := loopColumns(db, "Habits")
+	if err != nil {
+		return nil, err
+	}
+	// Older versions of Loop lack the columns of numerical habits and
+	// reminders, so missing columns are read as their defaults.
+	col := func(name, def string) string {
+		if habitCols[name] {
+			return fmt.Sprintf("COALESCE(%s, %s)", name, def)
+		}
+		return def
+	}
+	query := fmt.Sprintf(`SELECT id, COALESCE(name, ''), %s, %s, %s, %s, %s, %s, %s, %s, %s FROM Habits ORDER BY %s`,
+		col("freq_num", "1"), col("freq_den", "1"), col("archived", "0"),
+		col("type", "0"), col("target_type", "0"), col("target_value", "0"), col("unit", "''"),
+		col("reminder_hour", "-1"), col("reminder_min", "-1"), col("position", "id"))
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("error reading Loop Habit Tracker habits: %w", err)
+	}
+	defer rows.Close()
+	var habits []loopHabit
+	index := map[int64]int{}
+	for rows.Next() {
+		var id int64
+		var lh loopHabit
+		var hour, minute int
+		err := rows.Scan(&id, &lh.name, &lh.freqNum, &lh.freqDen, &lh.archived,
+			&lh.kind, &lh.targetType, &lh.target, &lh.unit, &hour, &minute)
+		if err != nil {
+			return nil, fmt.Errorf("error reading Loop Habit Tracker habits: %w", err)
+		}
+		if hour >= 0 && hour < 24 && minute >= 0 && minute < 60 {
+			lh.reminder = &TimeOfDay{Hour: hour, Minute: minute}
+		}
+		index[id] = len(habits)
+		habits = append(habits, lh)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading Loop Habit Tracker habits: %w", err)
+	}
+	repCols, err := loopColumns(db, "Repetitions")
+	if err != nil {
+		return nil, err
+	}
+	notes := "''"
+	if repCols["notes"] {
+		notes = "COALESCE(notes, '')"
+	}
+	reps, err := db.Query(`SELECT habit, timestamp, value, ` + notes + ` FROM Repetitions`)
+	if err != nil {
+		return nil, fmt.Errorf("error reading Loop Habit Tracker repetitions: %w", err)
+	}
+	defer reps.Close()
+	for reps.Next() {
+		var id, millis int64
+		var rep loopRepetition
+		err := reps.Scan(&id, &millis, &rep.value, &rep.note)
+		if err != nil {
+			return nil, fmt.Errorf("error reading Loop Habit Tracker repetitions: %w", err)
+		}
+		i, ok := index[id]
+		if !ok {
+			continue
+		}
+		// Loop records each date as midnight UTC.
+		rep.year, rep.month, rep.day = time.UnixMilli(millis).UTC().Date()
+		habits[i].reps = append(habits[i].reps, rep)
+	}
+	if err := reps.Err(); err != nil {
+		return nil, fmt.Errorf("error reading Loop Habit Tracker repetitions: %w", err)
+	}
+	return habits, nil
+}
+
+// loopColumns returns the set of the names of the columns of the given table
+// of a Loop Habit Tracker backup. An error is returned if the table does not
+// exist.
+func loopColumns(db *sql.DB, table string) (map[string]bool, error) {
+	rows, err := db.Query(`SELECT name FROM pragma_table_info(?)`, table)
+	if err != nil {
+		return nil, fmt.Errorf("error reading Loop Habit Tracker backup: %w", err)
+	}
+	defer rows.Close()
+	cols := map[string]bool{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("error reading Loop Habit Tracker backup: %w", err)
+		}
+		cols[strings.ToLower(name)] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading Loop Habit Tracker backup: %w", err)
+	}
+	if len(cols) == 0 {
+		return nil, fmt.Errorf("not a Loop Habit Tracker backup: no %s table", table)
+	}
+	return cols, nil
+}
+
+// readLoopCSV returns the habits in the given Loop Habit Tracker CSV export,
+// a zip archive holding a Habits.csv file listing the habits and, for each
+// habit, a directory named after its position and name holding a
+// Checkmarks.csv file with the habit's value on each date.
+func readLoopCSV(raw []byte) ([]loopHabit, error) {
+	zr, err := zip.NewReader(bytes.NewReader(raw), int64(len(raw)))
+	if err != nil {
+		return nil, fmt.Errorf("error reading Loop Habit Tracker CSV export: %w", err)
+	}
+	checkmarks := map[string]*zip.File{}
+	var habitsFile *zip.File
+	for _, f := range zr.File {
+		dir, name := path.Split(f.Name)
+		switch {
+		case dir == "" && name == "Habits.csv":
+			habitsFile = f
+		case dir != "" && name == "Checkmarks.csv":
+			// Directories are named after the habit's position, such as
+			// "001 Meditate".
+			position, _, _ := strings.Cut(path.Base(dir), " ")
+			checkmarks[position] = f
+		}
+	}
+	if habitsFile == nil {
+		return nil, errors.New("not a Loop Habit Tracker CSV export: no Habits.csv")
+	}
+	rc, err := habitsFile.Open()
+	if err != nil {
+		return nil, fmt.Errorf("error reading Habits.csv: %w", err)
+	}
+	defer rc.Close()
+	table, err := readCSVTable(rc)
+	if err != nil {
+		return nil, fmt.Errorf("error reading Habits.csv: %w", err)
+	}
+	field := table.field
+	var habits []loopHabit
+	for i, record := range table.records {
+		line := i + 2
+		lh := loopHabit{
+			name:     field(record, "name"),
+			freqNum:  1,
+			freqDen:  1,
+			unit:     field(record, "unit"),
+			archived: strings.EqualFold(field(record, "archived?", "archived"), "true"),
+		}
+		// Older versions of Loop name the frequency columns differently.
+		if value := field(record, "frequencynumerator", "numrepetitions"); value != "" {
+			lh.freqNum, err = strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("Habits.csv line %d: invalid frequency %q", line, value)
+			}
+		}
+		if value := field(record, "frequencydenominator", "interval"); value != "" {
+			lh.freqDen, err = strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("Habits.csv line %d: invalid frequency %q", line, value)
+			}
+		}
+		if strings.EqualFold(field(record, "type"), "numerical") || field(record, "type") == "1" {
+			lh.kind = loopNumerical
+		}
+		if value := field(record, "target type"); strings.EqualFold(value, "at most") || value == "1" {
+			lh.targetType = loopAtMost
+		}
+		if value := field(record, "target value"); value != "" {
+			lh.target, err = strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("Habits.csv line %d: invalid target %q", line, value)
+			}
+		}
+		if f, ok := checkmarks[field(record, "position")]; ok {
+			lh.reps, err = readLoopCheckmarks(f)
+			if err != nil {
+				return nil, err
+			}
+		}
+		habits = append(habits, lh)
+	}
+	return habits, nil
+}
+
+// readLoopCheckmarks returns the values in the given Checkmarks.csv file of a
+// Loop Habit Tracker CSV export, whose rows hold a date and a value.
+func readLoopCheckmarks(f *zip.File) ([]loopRepetition, error) {
+	records, err := readZipCSV(f)
+	if err != nil {
+		return nil, err
+	}
+	var reps []loopRepetition
+	for i, record := range records {
+		if len(record) < 2 {
+			continue
+		}
+		date, err := time.Parse(time.DateOnly, strings.TrimSpace(record[0]))
+		if err != nil {
+			// The file may start with a header.
+			if i == 0 {
+				continue
+			}
+			return nil, fmt.Errorf("%s line %d: invalid date %q", f.Name, i+1, record[0])
+		}
+		value, err := strconv.ParseInt(strings.TrimSpace(record[1]), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s line %d: invalid value %q", f.Name, i+1, record[1])
+		}
+		rep := loopRepetition{value: value}
+		rep.year, rep.month, rep.day = date.Date()
+		reps = append(reps, rep)
+	}
+	return reps, nil
+}
+
+// readZipCSV returns the records of the given CSV file in a zip archive.
+func readZipCSV(f *zip.File) ([][]string, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", f.Name, err)
+	}
+	defer rc.Close()
+	r := csv.NewReader(rc)
+	r.FieldsPerRecord = -1
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", f.Name, err)
+	}
+	return records, nil
+}
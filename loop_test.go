@@ -0,0 +1,138 @@
+package habit_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+// loopBackupSchema creates the tables of a Loop Habit Tracker backup, holding
+// a yes-or-no habit done daily, a numerical habit and an archived habit done
+// weekly.
+const loopBackupSchema = `
+CREATE TABLE Habits (id INTEGER PRIMARY KEY AUTOINCREMENT, archived INTEGER, color INTEGER,
+	description TEXT, freq_den INTEGER, freq_num INTEGER, highlight INTEGER, name TEXT,
+	position INTEGER, reminder_hour INTEGER, reminder_min INTEGER, reminder_days INTEGER NOT NULL DEFAULT 127,
+	type INTEGER NOT NULL DEFAULT 0, target_type INTEGER NOT NULL DEFAULT 0,
+	target_value REAL NOT NULL DEFAULT 0, unit TEXT NOT NULL DEFAULT "", question TEXT, uuid TEXT);
+CREATE TABLE Repetitions (id INTEGER PRIMARY KEY AUTOINCREMENT, habit INTEGER NOT NULL REFERENCES Habits(id),
+	timestamp INTEGER NOT NULL, value INTEGER NOT NULL, notes TEXT);
+INSERT INTO Habits (id, archived, freq_den, freq_num, name, position, reminder_hour, reminder_min, type, target_value, unit)
+VALUES (1, 0, 1, 1, 'Meditate', 0, 7, 30, 0, 0, ''),
+	(2, 0, 1, 1, 'Push-ups', 1, NULL, NULL, 1, 20, 'reps'),
+	(3, 1, 7, 1, 'Call mum', 2, NULL, NULL, 0, 0, '');
+INSERT INTO Repetitions (habit, timestamp, value, notes)
+VALUES (1, 1707091200000, 2, 'calm'), (1, 1707177600000, 2, NULL), (1, 1707264000000, 1, NULL),
+	(1, 1707350400000, 2, NULL),
+	(2, 1707091200000, 25000, NULL), (2, 1707177600000, 10000, NULL),
+	(3, 1707091200000, 2, NULL), (3, 1707177600000, 1, NULL);
+`
+
+// loopHabits are the habits, as of February 8, 2024, in loopBackupSchema.
+func loopHabits() []habit.Habit {
+	day := func(d int) time.Time { return time.Date(2024, time.February, d, 0, 0, 0, 0, time.UTC) }
+	return []habit.Habit{
+		{Name: "Meditate", Frequency: habit.Daily, CurrentStreak: 1, LongestStreak: 2, LastDone: day(8),
+			ReminderTime: &habit.TimeOfDay{Hour: 7, Minute: 30},
+			History:      []habit.Completion{{At: day(5), Note: "calm"}, {At: day(6)}, {At: day(8)}}},
+		{Name: "Push-ups", Frequency: habit.Daily, CurrentStreak: 1, LongestStreak: 1, LastDone: day(5),
+			Target: 20, Unit: "reps", Amount: 10, AmountAt: day(6),
+			History: []habit.Completion{{At: day(5), Amount: 25}}},
+		{Name: "Call mum", Frequency: habit.Weekly, CurrentStreak: 1, LongestStreak: 1, LastDone: day(5),
+			Archived: true, History: []habit.Completion{{At: day(5)}}},
+	}
+}
+
+func TestTracker_ImportLoopBackupConvertsRepetitionsToCompletions(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "Loop Habits Backup.db")
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Exec(loopBackupSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+	backup, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := &memStore{habits: map[string]habit.Habit{}}
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(new(bytes.Buffer)), habit.WithLocation(time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Import(bytes.NewReader(backup), habit.FormatLoop, habit.MergeLatest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := loopHabits()
+	got := store.All()
+	if !cmp.Equal(want, got, habitSliceCmpOpt) {
+		t.Error(cmp.Diff(want, got, habitSliceCmpOpt))
+	}
+}
+
+func TestTracker_ImportLoopCSVExportConvertsCheckmarksToCompletions(t *testing.T) {
+	t.Parallel()
+	files := map[string]string{
+		"Habits.csv": "Position,Name,Type,Question,Description,FrequencyNumerator,FrequencyDenominator,Color,Unit,Target Type,Target Value,Archived?\n" +
+			"001,Meditate,YES_NO,,,1,1,#FF8F00,,,,false\n" +
+			"002,Push-ups,NUMERICAL,,,1,1,#FF8F00,reps,AT_LEAST,20,false\n" +
+			"003,Call mum,YES_NO,,,1,7,#FF8F00,,,,true\n",
+		"001 Meditate/Checkmarks.csv": "2024-02-08,2\n2024-02-07,1\n2024-02-06,2\n2024-02-05,2\n",
+		"002 Push-ups/Checkmarks.csv": "2024-02-06,10000\n2024-02-05,25000\n",
+		"003 Call mum/Checkmarks.csv": "2024-02-06,1\n2024-02-05,2\n",
+	}
+	archive := new(bytes.Buffer)
+	zw := zip.NewWriter(archive)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	err := zw.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := &memStore{habits: map[string]habit.Habit{}}
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(new(bytes.Buffer)), habit.WithLocation(time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Import(archive, habit.FormatLoop, habit.MergeLatest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := loopHabits()
+	// The CSV export has neither notes nor reminders.
+	want[0].History[0].Note = ""
+	want[0].ReminderTime = nil
+	got := store.All()
+	if !cmp.Equal(want, got, habitSliceCmpOpt) {
+		t.Error(cmp.Diff(want, got, habitSliceCmpOpt))
+	}
+}
+
+func TestTracker_ImportLoopRejectsOtherFiles(t *testing.T) {
+	t.Parallel()
+	tracker, err := habit.NewTracker(habit.WithStore(&memStore{habits: map[string]habit.Habit{}}), habit.WithOutput(new(bytes.Buffer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Import(bytes.NewReader([]byte("name,completed_at\n")), habit.FormatLoop, habit.MergeLatest)
+	if err == nil {
+		t.Error("expected an error importing a file that is not a Loop Habit Tracker export")
+	}
+}
@@ -0,0 +1,233 @@
+package habit
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+)
+
+// MergeStores merges the Habits of store b into store a and saves a, for users
+// who ended up with two diverged store files, for example by tracking habits
+// on two machines. Habits found only in b are copied to a, and Habits found in
+// both are merged by taking the union of their completions, tags and pauses
+// and recomputing their streaks, with calendar dates in the local time zone.
+// Store b is left unchanged. An error is returned if a cannot be saved.
+func MergeStores(a, b Store) error {
+	if mergeInto(a, b.All(), nil, calendar{location: time.Local}) == 0 {
+		return nil
+	}
+	return a.Save()
+}
+
+// Merge merges the Habits of the given store into the Tracker's store like
+// MergeStores, with calendar dates determined like the Tracker's, and writes
+// the number of Habits that were added or changed to the Tracker's output. An
+// error is returned if the Tracker's store cannot be saved.
+func (t *Tracker) Merge(other Store) error {
+	updated := mergeInto(t.store, other.All(), nil, t.calendar)
+	if updated > 0 {
+		err := t.save()
+		if err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(t.output, "Merged the other store: %d %s updated.\n", updated, habitsUnit(updated))
+	return nil
+}
+
+// mergeInto adds the given Habits to the store, merging each one with the
+// store's Habit of the same name, if any, with mergeHabits. A Habit deleted at
+// the time given for its name in deleted, such as on another device, is
+// deleted from the store and left out of the given Habits, unless that copy of
+// it was done after it was deleted. It returns the number of the store's
+// Habits that were added, changed or deleted. The store is not saved.
+func mergeInto(s Store, habits []Habit, deleted map[string]time.Time, cal calendar) int {
+	updated := 0
+	for name, at := range deleted {
+		if existing, ok := s.Get(name); ok && !existing.LastDone.After(at) {
+			s.Delete(name)
+			updated++
+		}
+	}
+	for _, hbt := range habits {
+		if at, ok := deleted[hbt.Name]; ok && !hbt.LastDone.After(at) {
+			continue
+		}
+		existing, ok := s.Get(hbt.Name)
+		if ok {
+			hbt = mergeHabits(existing, hbt, cal)
+			if sameHabit(hbt, existing) {
+				continue
+			}
+		}
+		s.Add(hbt)
+		updated++
+	}
+	return updated
+}
+
+// mergeHabits returns the union of two diverged copies of the same Habit, such
+// as the copies kept by two devices. Completions, tags and pauses from both
+// copies are kept, along with the Badges either copy earned and the counts of
+// completions either copy pruned, without the completions pruned, and the
+// most points either copy earned. Settings such as the frequency and target
+// are taken from the copy done most recently, preferring a on ties. If either
+// copy has completions the other lacks, the streaks are recomputed from the
+// merged history, with calendar dates taken from the given calendar, and the
+// undo record is dropped since it no longer describes the last change.
+func mergeHabits(a, b Habit, cal calendar) Habit {
+	newer, older := a, b
+	if b.LastDone.After(a.LastDone) {
+		newer, older = b, a
+	}
+	merged := newer
+	merged.History = mergeHistory(newer.History, older.History)
+	merged.Compacted = mergeCompactions(newer.Compacted, older.Compacted)
+	if merged.Compacted != nil {
+		// Completions pruned from one copy are not brought back by the other.
+		merged.History = slices.DeleteFunc(merged.History, func(c Completion) bool {
+			return c.At.Before(merged.Compacted.Before)
+		})
+	}
+	merged.Tags = mergeTags(newer.Tags, older.Tags)
+	merged.Pauses = mergePauses(newer.Pauses, older.Pauses)
+	merged.Sessions = mergeSessions(newer.Sessions, older.Sessions)
+	merged.Badges = mergeBadges(newer.Badges, older.Badges)
+	if older.AmountAt.After(newer.AmountAt) {
+		merged.Amount = older.Amount
+		merged.AmountAt = older.AmountAt
+		merged.AmountIDs = older.AmountIDs
+	}
+	merged.LongestStreak = max(a.LongestStreak, b.LongestStreak)
+	merged.Points = max(a.Points, b.Points)
+	if len(merged.History) == len(newer.History) {
+		return merged
+	}
+	merged.Undo = nil
+	if merged.Avoid {
+		return merged
+	}
+	current, longest := computeStreaks(merged, cal)
+	merged.CurrentStreak = current
+	merged.LongestStreak = max(merged.LongestStreak, longest)
+	return merged
+}
+
+// mergeHistory returns the chronologically sorted union of two completion
+// histories. Completions at the same instant are combined, keeping a non-empty
+// note, amount and idempotency key and marking the completion frozen if either
+// copy is.
+func mergeHistory(a, b []Completion) []Completion {
+	var merged []Completion
+	index := map[int64]int{}
+	for _, c := range append(append([]Completion{}, a...), b...) {
+		key := c.At.UnixNano()
+		i, ok := index[key]
+		if !ok {
+			index[key] = len(merged)
+			merged = append(merged, c)
+			continue
+		}
+		if merged[i].Note == "" {
+			merged[i].Note = c.Note
+		}
+		if merged[i].Amount == 0 {
+			merged[i].Amount = c.Amount
+		}
+		if merged[i].ID == "" {
+			merged[i].ID = c.ID
+		}
+		merged[i].Frozen = merged[i].Frozen || c.Frozen
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].At.Before(merged[j].At)
+	})
+	return merged
+}
+
+// mergeTags returns the sorted union of two sets of tags.
+func mergeTags(a, b []string) []string {
+	merged := append([]string{}, a...)
+	for _, tag := range b {
+		found := false
+		for _, existing := range merged {
+			if existing == tag {
+				found = true
+			}
+		}
+		if !found {
+			merged = append(merged, tag)
+		}
+	}
+	if len(merged) == 0 {
+		return nil
+	}
+	sort.Strings(merged)
+	return merged
+}
+
+// mergeCompactions returns the union of two records of pruned completions,
+// either of which may be nil, pruned before the later of their timestamps and
+// keeping the larger count of a month in both.
+func mergeCompactions(a, b *Compaction) *Compaction {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	counts := map[string]int{}
+	for _, mc := range append(append([]MonthlyCount{}, a.Months...), b.Months...) {
+		counts[mc.Month] = max(counts[mc.Month], mc.Count)
+	}
+	return &Compaction{Before: laterOf(a.Before, b.Before), Months: monthlyCounts(counts)}
+}
+
+// mergePauses returns the union of two lists of pauses, sorted by start. Pauses
+// starting at the same instant are combined, preferring the one that has
+// ended.
+func mergePauses(a, b []Pause) []Pause {
+	var merged []Pause
+	index := map[int64]int{}
+	for _, p := range append(append([]Pause{}, a...), b...) {
+		key := p.From.UnixNano()
+		i, ok := index[key]
+		if !ok {
+			index[key] = len(merged)
+			merged = append(merged, p)
+			continue
+		}
+		if merged[i].Until.IsZero() {
+			merged[i].Until = p.Until
+		}
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].From.Before(merged[j].From)
+	})
+	return merged
+}
+
+// mergeSessions returns the union of two lists of sessions, sorted by start.
+// Sessions starting at the same instant are combined, preferring the one that
+// has been stopped.
+func mergeSessions(a, b []Session) []Session {
+	var merged []Session
+	index := map[int64]int{}
+	for _, s := range append(append([]Session{}, a...), b...) {
+		key := s.Start.UnixNano()
+		i, ok := index[key]
+		if !ok {
+			index[key] = len(merged)
+			merged = append(merged, s)
+			continue
+		}
+		if merged[i].End.IsZero() {
+			merged[i].End = s.End
+		}
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Start.Before(merged[j].Start)
+	})
+	return merged
+}
@@ -0,0 +1,94 @@
+package habit_test
+
+import (
+	"bytes"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMergeStores_TakesUnionOfCompletionsAndRecomputesStreaks(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T20:00:00Z")
+	// Each completion is done an hour earlier than the day before, so that
+	// completions on consecutive days are less than 24 hours apart.
+	day := func(d int) time.Time {
+		return time.Date(2024, time.February, d, 11-d, 0, 0, 0, time.UTC)
+	}
+	path := t.TempDir() + "/laptop.store"
+	a, err := habit.OpenStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.Add(habit.Habit{
+		Name: "programming", CurrentStreak: 2, LongestStreak: 2, LastDone: day(6),
+		History: []habit.Completion{{At: day(1)}, {At: day(2)}, {At: day(5)}, {At: day(6)}},
+	})
+	b := &memStore{habits: map[string]habit.Habit{
+		"programming": {
+			Name: "programming", CurrentStreak: 3, LongestStreak: 3, LastDone: day(4),
+			History: []habit.Completion{{At: day(2)}, {At: day(3)}, {At: day(4), Note: "fixed the bug"}},
+			Tags:    []string{"work"},
+		},
+		"reading": {
+			Name: "reading", CurrentStreak: 1, LongestStreak: 1, LastDone: day(6),
+			History: []habit.Completion{{At: day(6)}},
+		},
+	}}
+	err = habit.MergeStores(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reopened, err := habit.OpenStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []habit.Habit{
+		{
+			Name: "programming", CurrentStreak: 6, LongestStreak: 6, LastDone: day(6),
+			History: []habit.Completion{
+				{At: day(1)}, {At: day(2)}, {At: day(3)}, {At: day(4), Note: "fixed the bug"}, {At: day(5)}, {At: day(6)},
+			},
+			Tags: []string{"work"},
+		},
+		b.habits["reading"],
+	}
+	got := reopened.All()
+	sort.Slice(got, func(i, j int) bool {
+		return got[i].Name < got[j].Name
+	})
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+	if len(b.habits) != 2 || b.saves != 0 {
+		t.Error("want the other store left unchanged")
+	}
+}
+
+func TestTracker_MergeReportsUpdatedHabits(t *testing.T) {
+	t.Parallel()
+	lastDone := time.Date(2024, time.February, 6, 9, 0, 0, 0, time.UTC)
+	reading := habit.Habit{
+		Name: "reading", CurrentStreak: 1, LongestStreak: 1, LastDone: lastDone,
+		History: []habit.Completion{{At: lastDone}},
+	}
+	store := &memStore{habits: map[string]habit.Habit{"reading": reading}}
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Merge(&memStore{habits: map[string]habit.Habit{"reading": reading}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Merged the other store: 0 habits updated.\n"
+	if got := output.String(); want != got {
+		t.Error(cmp.Diff(want, got))
+	}
+	if store.saves != 0 {
+		t.Errorf("want no save when nothing changed, got %d saves", store.saves)
+	}
+}
@@ -0,0 +1,256 @@
+package habit
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"text/template"
+)
+
+// Messages holds text/template templates for the messages written when a
+// Habit is tracked, so that their tone or language can be customized. Each
+// template is executed with a MessageData. An empty template keeps the
+// built-in message.
+type Messages struct {
+	// NewHabit is written when a Habit is tracked for the first time.
+	NewHabit string
+	// Again is written when a Habit is tracked more than once in a period.
+	Again string
+	// Streak is written when a Habit's streak continues.
+	Streak string
+	// Reset is written when a Habit's streak was broken and starts over.
+	Reset string
+}
+
+// MessageData holds the values that message templates can refer to, such as
+// {{.Name}} or {{.Streak}}. Values that a message is not about are zero.
+type MessageData struct {
+	// Name is the name of the habit.
+	Name string
+	// Streak is the habit's current streak after it was tracked.
+	Streak int
+	// LongestStreak is the habit's longest streak before it was tracked.
+	LongestStreak int
+	// DaysSince is the number of whole days since the habit was last done.
+	DaysSince int
+	// Unit is the unit of the habit's streak, such as "days" or "week".
+	Unit string
+	// Period is the name of one of the habit's periods, such as "day" in
+	// "7-day streak".
+	Period string
+	// Units is the name of more than one of the habit's periods, such as
+	// "days".
+	Units string
+	// Current describes the habit's current period, such as "today" or
+	// "this week".
+	Current string
+	// Goal is the length of the streak the habit is being done for.
+	Goal int
+	// Progress is the number of periods of the habit's goal done so far.
+	Progress int
+	// Strength is the habit's strength, from 0 to 100.
+	Strength int
+	// Freezes is the number of streak freezes the habit has left.
+	Freezes int
+	// Date is the date a completion was logged on, such as "2024-02-06".
+	Date string
+	// Amount is the amount of a quantity habit logged in the current period.
+	Amount string
+	// Target is the amount of a quantity habit to log in each period, with
+	// its unit.
+	Target string
+	// Deadline is the time of day by which the habit is due, such as "09:00".
+	Deadline string
+	// Routine is the name of the routine being summarized.
+	Routine string
+	// GoalName is the name of a goal spanning several habits, such as
+	// "Get fit".
+	GoalName string
+	// Percent is a percentage, such as the adherence of a named goal.
+	Percent int
+	// PreviousPercent is the percentage it is compared with, such as the
+	// adherence of a named goal over the days before.
+	PreviousPercent int
+	// Points is the number of points earned by a completion.
+	Points int
+	// Level is the level reached by the points earned so far.
+	Level int
+	// Summary is a short summary of how a habit was tracked, such as
+	// "3-day streak", in a list of habits tracked together.
+	Summary string
+	// Count is the number of items in List.
+	Count int
+	// List is a list of items, such as habit names, joined for the message.
+	List string
+}
+
+// Names of the message templates, as defined with {{define}} in a message
+// file.
+const (
+	messageNewHabit = "new_habit"
+	messageAgain    = "again"
+	messageStreak   = "streak"
+	messageReset    = "reset"
+)
+
+// defaultMessages are the built-in message templates.
+var defaultMessages = Messages{
+	NewHabit: "Congratulations on starting your new habit '{{.Name}}'! Don't forget to do it again.",
+	Again:    "Way to go practicing your habit '{{.Name}}' more than once {{.Current}}!",
+	Streak:   "Nice work: you've done the habit '{{.Name}}' for {{.Streak}} {{.Unit}} in a row now.",
+	Reset: "You last did the habit '{{.Name}}' {{.DaysSince}} {{plural .DaysSince \"day\" \"days\"}} ago, " +
+		"so you're starting a new streak today. Good luck!",
+}
+
+// messageFuncs are the functions that message templates can call.
+var messageFuncs = template.FuncMap{
+	// plural returns one if count is 1, and otherwise other.
+	"plural": func(count int, one, other string) string {
+		if count == 1 {
+			return one
+		}
+		return other
+	},
+}
+
+// sampleMessageData is used to check that message templates can be executed.
+var sampleMessageData = MessageData{
+	Name:            "reading",
+	Streak:          2,
+	LongestStreak:   5,
+	DaysSince:       1,
+	Unit:            "days",
+	Period:          "day",
+	Units:           "days",
+	Current:         "today",
+	Goal:            30,
+	Progress:        2,
+	Strength:        74,
+	Freezes:         1,
+	Date:            "2024-02-06",
+	Amount:          "3",
+	Target:          "8 glasses",
+	Deadline:        "09:00",
+	Routine:         "morning",
+	GoalName:        "Get fit",
+	Percent:         75,
+	PreviousPercent: 50,
+	Points:          15,
+	Level:           3,
+	Summary:         "2-day streak",
+	Count:           2,
+	List:            "'reading' and 'running'",
+}
+
+// byName returns the templates of the Messages by template name.
+func (m Messages) byName() map[string]string {
+	return map[string]string{
+		messageNewHabit: m.NewHabit,
+		messageAgain:    m.Again,
+		messageStreak:   m.Streak,
+		messageReset:    m.Reset,
+	}
+}
+
+// parseMessages returns the built-in message templates.
+func parseMessages() *template.Template {
+	tmpl := template.New("messages").Funcs(messageFuncs)
+	for name, text := range defaultMessages.byName() {
+		template.Must(tmpl.New(name).Parse(text))
+	}
+	for name, text := range catalogEN {
+		template.Must(tmpl.New(name).Parse(text))
+	}
+	return tmpl
+}
+
+// WithMessages returns an option that makes a Tracker write the messages in
+// the given Messages instead of the built-in ones. An error is returned if
+// any of the templates cannot be parsed or executed.
+func WithMessages(m Messages) option {
+	return func(t *Tracker) error {
+		tmpl, err := t.messages.Clone()
+		if err != nil {
+			return err
+		}
+		for name, text := range m.byName() {
+			if text == "" {
+				continue
+			}
+			_, err = tmpl.New(name).Parse(text)
+			if err != nil {
+				return fmt.Errorf("invalid %s message: %w", name, err)
+			}
+		}
+		err = checkMessages(tmpl)
+		if err != nil {
+			return err
+		}
+		t.messages = tmpl
+		return nil
+	}
+}
+
+// WithMessageFile returns an option that makes a Tracker write the messages
+// defined in the given text/template file instead of the built-in ones. The
+// file defines each message it replaces by name, such as
+//
+//	{{define "streak"}}{{.Streak}} {{.Unit}} of '{{.Name}}'. Keep it up!{{end}}
+//
+// with the names "new_habit", "again", "streak" and "reset", or the name of
+// any other message in a Catalog. An error is returned if the file cannot be
+// read, defines an unknown message, or any of its templates cannot be
+// executed.
+func WithMessageFile(path string) option {
+	return func(t *Tracker) error {
+		tmpl, err := t.messages.Clone()
+		if err != nil {
+			return err
+		}
+		defined, err := template.New("").Funcs(messageFuncs).ParseFiles(path)
+		if err != nil {
+			return fmt.Errorf("error reading message file: %w", err)
+		}
+		for _, def := range defined.Templates() {
+			if def.Tree == nil || def.Name() == filepath.Base(path) {
+				// The file itself is a template of its own that holds
+				// nothing but definitions.
+				continue
+			}
+			if !isMessageName(def.Name()) {
+				return fmt.Errorf("unknown message %q in %s", def.Name(), path)
+			}
+			_, err = tmpl.AddParseTree(def.Name(), def.Tree)
+			if err != nil {
+				return err
+			}
+		}
+		err = checkMessages(tmpl)
+		if err != nil {
+			return err
+		}
+		t.messages = tmpl
+		return nil
+	}
+}
+
+// checkMessages executes each of the given message templates with sample data
+// so that mistakes, such as unknown fields, are found before any Habit is
+// tracked.
+func checkMessages(tmpl *template.Template) error {
+	for _, name := range messageNames() {
+		err := tmpl.ExecuteTemplate(io.Discard, name, sampleMessageData)
+		if err != nil {
+			return fmt.Errorf("invalid %s message: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// message executes the Tracker's message template with the given name for the
+// given Habit, which was last done the given number of days ago.
+func (t *Tracker) message(name string, hbt Habit, daysSince int) string {
+	data := t.messageData(hbt)
+	data.DaysSince = daysSince
+	return t.text(name, data)
+}
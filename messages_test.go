@@ -0,0 +1,98 @@
+package habit_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTracker_TrackWritesCustomMessages(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T09:00:00Z")
+	store := &memStore{habits: map[string]habit.Habit{
+		"reading": {Name: "reading", CurrentStreak: 2, LongestStreak: 4,
+			LastDone: time.Date(2024, time.February, 5, 20, 0, 0, 0, time.UTC)},
+		"running": {Name: "running", CurrentStreak: 3, LongestStreak: 3,
+			LastDone: time.Date(2024, time.February, 1, 20, 0, 0, 0, time.UTC)},
+	}}
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output), habit.WithMessages(habit.Messages{
+		Streak: "{{.Name}}: {{.Streak}} {{.Unit}} (best {{.LongestStreak}})",
+		Reset:  "{{.Name}}: {{.DaysSince}} {{plural .DaysSince \"day\" \"days\"}} since",
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"reading", "running", "yoga"} {
+		err = tracker.Track(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := "reading: 3 days (best 4)\n" +
+		"running: 5 days since\n" +
+		"Congratulations on starting your new habit 'yoga'! Don't forget to do it again.\n"
+	if got := output.String(); want != got {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestTracker_TrackWritesMessagesFromFile(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T09:00:00Z")
+	path := filepath.Join(t.TempDir(), "messages.tmpl")
+	err := os.WriteFile(path, []byte(`{{define "new_habit"}}Nouvelle habitude : {{.Name}}{{end}}
+{{define "again"}}Encore '{{.Name}}' {{.Current}} !{{end}}
+`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(
+		habit.WithStore(&memStore{habits: map[string]habit.Habit{}}),
+		habit.WithOutput(output),
+		habit.WithMessageFile(path),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		err = tracker.Track("reading")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := "Nouvelle habitude : reading\nEncore 'reading' today !\n"
+	if got := output.String(); want != got {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestNewTrackerReturnsErrorForInvalidMessages(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	unknown := filepath.Join(dir, "unknown.tmpl")
+	err := os.WriteFile(unknown, []byte(`{{define "goodbye"}}Bye, {{.Name}}{{end}}`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]habit.Messages{
+		"unparseable":   {Streak: "{{.Name"},
+		"unknown field": {Reset: "{{.Habit}}"},
+	}
+	for name, msgs := range tests {
+		_, err := habit.NewTracker(habit.WithStore(&memStore{habits: map[string]habit.Habit{}}), habit.WithMessages(msgs))
+		if err == nil {
+			t.Errorf("%s: want error, got nil", name)
+		}
+	}
+	for _, path := range []string{unknown, filepath.Join(dir, "missing.tmpl")} {
+		_, err := habit.NewTracker(habit.WithStore(&memStore{habits: map[string]habit.Habit{}}), habit.WithMessageFile(path))
+		if err == nil {
+			t.Errorf("want error for message file %s, got nil", path)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package habit
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// metricsContentType is the content type of the Prometheus text exposition
+// format written by WriteMetrics.
+const metricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// A metric describes a Prometheus metric reported for each Habit.
+type metric struct {
+	// name is the name of the metric.
+	name string
+	// kind is the Prometheus type of the metric: "gauge" or "counter".
+	kind string
+	// help describes the metric.
+	help string
+	// value returns the metric's value for the given Habit, given its
+	// summary.
+	value func(t *Tracker, hbt Habit, s HabitSummary) float64
+}
+
+// metrics are the metrics reported for each Habit by WriteMetrics.
+var metrics = []metric{
+	{
+		name: "habit_current_streak",
+		kind: "gauge",
+		help: "Current streak of the habit, in periods of its frequency, or 0 if the streak is broken.",
+		value: func(_ *Tracker, _ Habit, s HabitSummary) float64 {
+			if !s.StreakActive {
+				return 0
+			}
+			return float64(s.CurrentStreak)
+		},
+	},
+	{
+		name: "habit_longest_streak",
+		kind: "gauge",
+		help: "Longest streak of the habit, in periods of its frequency.",
+		value: func(_ *Tracker, _ Habit, s HabitSummary) float64 {
+			return float64(s.LongestStreak)
+		},
+	},
+	{
+		name: "habit_days_since_last_done",
+		kind: "gauge",
+		help: "Whole days since the habit was last done, or 0 if it has not been done yet.",
+		value: func(_ *Tracker, _ Habit, s HabitSummary) float64 {
+			return float64(s.DaysSinceDone)
+		},
+	},
+	{
+		name: "habit_completions_total",
+		kind: "counter",
+		help: "Number of times the habit has been done.",
+		value: func(_ *Tracker, _ Habit, s HabitSummary) float64 {
+			return float64(s.Completions)
+		},
+	},
+	{
+		name: "habit_done_this_period",
+		kind: "gauge",
+		help: "1 if the habit has been done in its current period, such as today for a daily habit, and 0 otherwise.",
+		value: func(_ *Tracker, _ Habit, s HabitSummary) float64 {
+			return boolValue(s.DoneThisPeriod)
+		},
+	},
+	{
+		name: "habit_streak_at_risk",
+		kind: "gauge",
+		help: "1 if the habit's streak breaks unless it is done before the current day ends, and 0 otherwise.",
+		value: func(t *Tracker, hbt Habit, _ HabitSummary) float64 {
+			return boolValue(t.status(hbt, t.now()) == statusAtRisk)
+		},
+	},
+}
+
+// boolValue returns 1 for true and 0 for false.
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// WriteMetrics writes metrics about every tracked Habit to w in the Prometheus
+// text exposition format, so that habits can be graphed and alerted on. Each
+// metric has a sample per Habit, labelled with the habit's name and
+// frequency. Archived Habits are left out. An error is returned if the metrics
+// cannot be written.
+func (t *Tracker) WriteMetrics(w io.Writer) error {
+	habits := t.sortedHabits(false)
+	summaries := t.Summarize()
+	bw := bufio.NewWriter(w)
+	for _, m := range metrics {
+		fmt.Fprintf(bw, "# HELP %s %s\n", m.name, m.help)
+		fmt.Fprintf(bw, "# TYPE %s %s\n", m.name, m.kind)
+		for i, hbt := range habits {
+			freq := hbt.Frequency.String()
+			if hbt.Avoid {
+				freq = "avoid"
+			}
+			fmt.Fprintf(bw, "%s{habit=\"%s\",frequency=\"%s\"} %g\n", m.name,
+				labelValue(hbt.Name), labelValue(freq), m.value(t, hbt, summaries[i]))
+		}
+	}
+	err := bw.Flush()
+	if err != nil {
+		return fmt.Errorf("error writing metrics: %w", err)
+	}
+	return nil
+}
+
+// labelValue escapes the given value for use as a Prometheus label value.
+func labelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
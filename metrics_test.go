@@ -0,0 +1,72 @@
+package habit_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTracker_WriteMetricsWritesPrometheusSamplesForEachHabit(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T20:00:00Z")
+	store := &memStore{habits: map[string]habit.Habit{
+		"programming": {Name: "programming", CurrentStreak: 5, LongestStreak: 9,
+			LastDone: time.Date(2024, time.February, 6, 9, 0, 0, 0, time.UTC),
+			History:  []habit.Completion{{At: time.Date(2024, time.February, 6, 9, 0, 0, 0, time.UTC)}}},
+		`say "hi"`: {Name: `say "hi"`, CurrentStreak: 3, LongestStreak: 3,
+			LastDone: time.Date(2024, time.February, 5, 21, 0, 0, 0, time.UTC),
+			History: []habit.Completion{
+				{At: time.Date(2024, time.February, 4, 21, 0, 0, 0, time.UTC)},
+				{At: time.Date(2024, time.February, 5, 21, 0, 0, 0, time.UTC)},
+			}},
+		"reading": {Name: "reading", CurrentStreak: 4, LongestStreak: 4, Frequency: habit.Weekly,
+			LastDone: time.Date(2024, time.January, 20, 9, 0, 0, 0, time.UTC),
+			History:  []habit.Completion{{At: time.Date(2024, time.January, 20, 9, 0, 0, 0, time.UTC)}}},
+		"old": {Name: "old", Archived: true},
+	}}
+	tracker, err := habit.NewTracker(habit.WithStore(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	err = tracker.WriteMetrics(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `# HELP habit_current_streak Current streak of the habit, in periods of its frequency, or 0 if the streak is broken.
+# TYPE habit_current_streak gauge
+habit_current_streak{habit="programming",frequency="daily"} 5
+habit_current_streak{habit="reading",frequency="weekly"} 0
+habit_current_streak{habit="say \"hi\"",frequency="daily"} 3
+# HELP habit_longest_streak Longest streak of the habit, in periods of its frequency.
+# TYPE habit_longest_streak gauge
+habit_longest_streak{habit="programming",frequency="daily"} 9
+habit_longest_streak{habit="reading",frequency="weekly"} 4
+habit_longest_streak{habit="say \"hi\"",frequency="daily"} 3
+# HELP habit_days_since_last_done Whole days since the habit was last done, or 0 if it has not been done yet.
+# TYPE habit_days_since_last_done gauge
+habit_days_since_last_done{habit="programming",frequency="daily"} 0
+habit_days_since_last_done{habit="reading",frequency="weekly"} 17
+habit_days_since_last_done{habit="say \"hi\"",frequency="daily"} 1
+# HELP habit_completions_total Number of times the habit has been done.
+# TYPE habit_completions_total counter
+habit_completions_total{habit="programming",frequency="daily"} 1
+habit_completions_total{habit="reading",frequency="weekly"} 1
+habit_completions_total{habit="say \"hi\"",frequency="daily"} 2
+# HELP habit_done_this_period 1 if the habit has been done in its current period, such as today for a daily habit, and 0 otherwise.
+# TYPE habit_done_this_period gauge
+habit_done_this_period{habit="programming",frequency="daily"} 1
+habit_done_this_period{habit="reading",frequency="weekly"} 0
+habit_done_this_period{habit="say \"hi\"",frequency="daily"} 0
+# HELP habit_streak_at_risk 1 if the habit's streak breaks unless it is done before the current day ends, and 0 otherwise.
+# TYPE habit_streak_at_risk gauge
+habit_streak_at_risk{habit="programming",frequency="daily"} 0
+habit_streak_at_risk{habit="reading",frequency="weekly"} 0
+habit_streak_at_risk{habit="say \"hi\"",frequency="daily"} 1
+`
+	if got := buf.String(); want != got {
+		t.Error(cmp.Diff(want, got))
+	}
+}
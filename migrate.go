@@ -0,0 +1,237 @@
+package habit
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// SchemaVersion is the version of the layout of the habit data written by this
+// package. It is incremented, and a migration appended to migrations, whenever
+// a change to Habit needs data written by older versions to be upgraded. Data
+// written before versions were introduced has version 0.
+const SchemaVersion = 1
+
+// migrations upgrade habit data between schema versions: migrations[i]
+// upgrades data from version i to version i+1, so there is exactly one
+// migration per schema version.
+var migrations = []func(data map[string]Habit){
+	seedStreaksAndHistory,
+}
+
+// versionedData is the layout of the habit data written by the gob and JSON
+// codecs, recording the schema version of the Habits it holds.
+type versionedData struct {
+	Version int              `json:"version"`
+	Habits  map[string]Habit `json:"habits"`
+}
+
+// errNewerSchema is the kind of error returned when habit data was written by
+// a newer version of this package, which is not a sign of a corrupt store.
+var errNewerSchema = errors.New("habit data has a newer schema version")
+
+// migrate upgrades the given habit data, written with the given schema
+// version, to SchemaVersion by applying every migration from that version on.
+// An error is returned if the data was written by a newer version of this
+// package, since decoding it would silently drop the fields that version
+// added.
+func migrate(version int, data map[string]Habit) error {
+	if version > SchemaVersion {
+		return errorOf(errNewerSchema, "habit data has schema version %d, but this version of habit only supports up to version %d; upgrade habit to read it",
+			version, SchemaVersion)
+	}
+	for _, m := range migrations[version:] {
+		m(data)
+	}
+	return nil
+}
+
+// seedStreaksAndHistory upgrades data from version 0. Data written before
+// LongestStreak was introduced decodes it as zero, so it is raised to at least
+// the CurrentStreak. Data written before History was introduced only knows
+// about the last completion, so the History is seeded with LastDone.
+func seedStreaksAndHistory(data map[string]Habit) {
+	for name, hbt := range data {
+		if hbt.LongestStreak < hbt.CurrentStreak {
+			hbt.LongestStreak = hbt.CurrentStreak
+		}
+		if len(hbt.History) == 0 && !hbt.LastDone.IsZero() {
+			hbt.History = []Completion{{At: hbt.LastDone}}
+		}
+		data[name] = hbt
+	}
+}
+
+// gobCodec persists versioned habit data using GOB encoding.
+type gobCodec struct{}
+
+// Encode writes the given habit data to w using GOB encoding, tagged with
+// SchemaVersion.
+func (gobCodec) Encode(w io.Writer, data map[string]Habit) error {
+	return gob.NewEncoder(w).Encode(versionedData{Version: SchemaVersion, Habits: data})
+}
+
+// Decode reads GOB-encoded habit data from r into the given map and migrates it
+// to SchemaVersion. Data written before versions were introduced, which is a
+// bare map of Habits, is also accepted.
+func (c gobCodec) Decode(r io.Reader, data *map[string]Habit) error {
+	vd, err := c.decodeRaw(r)
+	if err != nil {
+		return err
+	}
+	return decodeVersioned(vd, data)
+}
+
+// decodeRaw reads GOB-encoded habit data from r without migrating it.
+func (gobCodec) decodeRaw(r io.Reader) (versionedData, error) {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return versionedData{}, err
+	}
+	var vd versionedData
+	err = gob.NewDecoder(bytes.NewReader(raw)).Decode(&vd)
+	if err != nil {
+		// Unversioned data is a map, which gob refuses to decode into a
+		// struct.
+		vd = versionedData{}
+		err = gob.NewDecoder(bytes.NewReader(raw)).Decode(&vd.Habits)
+		if err != nil {
+			return versionedData{}, err
+		}
+	}
+	return vd, nil
+}
+
+// isLegacy reports whether the given data is a complete gob-encoded store file
+// as written before checksums were added: versioned habit data, or a bare map
+// of Habits from before versions, with nothing after it. Data cut short fails
+// to decode, and data with anything after it, such as part of a checksum, is
+// not a store file as written.
+func (gobCodec) isLegacy(raw []byte) bool {
+	r := bytes.NewReader(raw)
+	var vd versionedData
+	err := gob.NewDecoder(r).Decode(&vd)
+	if err != nil {
+		r = bytes.NewReader(raw)
+		var habits map[string]Habit
+		err = gob.NewDecoder(r).Decode(&habits)
+	}
+	return err == nil && r.Len() == 0
+}
+
+// jsonCodec persists versioned habit data as indented JSON so that it can be
+// inspected and edited by hand or by other tools.
+type jsonCodec struct{}
+
+// Encode writes the given habit data to w as indented JSON, tagged with
+// SchemaVersion.
+func (jsonCodec) Encode(w io.Writer, data map[string]Habit) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(versionedData{Version: SchemaVersion, Habits: data})
+}
+
+// Decode reads JSON-encoded habit data from r into the given map and migrates
+// it to SchemaVersion. Data written before versions were introduced, which is
+// an object mapping habit names to Habits, is also accepted.
+func (c jsonCodec) Decode(r io.Reader, data *map[string]Habit) error {
+	vd, err := c.decodeRaw(r)
+	if err != nil {
+		return err
+	}
+	return decodeVersioned(vd, data)
+}
+
+// decodeRaw reads JSON-encoded habit data from r without migrating it.
+func (jsonCodec) decodeRaw(r io.Reader) (versionedData, error) {
+	var raw json.RawMessage
+	err := json.NewDecoder(r).Decode(&raw)
+	if err != nil {
+		return versionedData{}, err
+	}
+	var vd versionedData
+	// Unversioned data has no numeric "version" key, although it may have a
+	// habit named "version", which fails to decode as a number.
+	err = json.Unmarshal(raw, &vd)
+	if err != nil || vd.Version == 0 {
+		vd = versionedData{}
+		err = json.Unmarshal(raw, &vd.Habits)
+		if err != nil {
+			return versionedData{}, err
+		}
+	}
+	return vd, nil
+}
+
+// A rawDecoder is a codec that can also decode habit data without migrating
+// it, so that the changes made by migrations can be shown.
+type rawDecoder interface {
+	// decodeRaw reads versioned habit data from r without migrating it.
+	decodeRaw(r io.Reader) (versionedData, error)
+}
+
+// decodeVersioned migrates the Habits of the given versioned data to
+// SchemaVersion and adds them to the given map.
+func decodeVersioned(vd versionedData, data *map[string]Habit) error {
+	if vd.Habits == nil {
+		vd.Habits = map[string]Habit{}
+	}
+	err := migrate(vd.Version, vd.Habits)
+	if err != nil {
+		return err
+	}
+	if *data == nil {
+		*data = map[string]Habit{}
+	}
+	for name, hbt := range vd.Habits {
+		(*data)[name] = hbt
+	}
+	return nil
+}
+
+// Migrate rewrites the Tracker's store file at SchemaVersion and writes the
+// schema version it was migrated from to the Tracker's output. Stores are
+// migrated in memory whenever they are opened, so this only makes the
+// migration permanent; if the store is wrapped in a Preview, the changes the
+// migrations make are recorded against the data as it was written. A store
+// opened with OpenEncryptedStore and WithPlaintextMigration whose file is not
+// encrypted yet is rewritten encrypted, even if it is at SchemaVersion. An
+// error is returned if the store has no file, such as a SQLite or remote
+// store, or if it cannot be read or saved.
+func (t *Tracker) Migrate() error {
+	version, written, err := unmigrated(t.store)
+	if err != nil {
+		return err
+	}
+	encrypting := unencrypted(t.store)
+	if version == SchemaVersion && !encrypting {
+		fmt.Fprintf(t.output, "The store is already at schema version %d.\n", SchemaVersion)
+		return nil
+	}
+	for name, hbt := range written {
+		if b, ok := t.store.(baseliner); ok {
+			before := hbt
+			b.setBaseline(name, &before)
+		}
+		current, ok := t.store.Get(name)
+		if ok {
+			t.store.Add(current)
+		}
+	}
+	err = t.save()
+	if err != nil {
+		return err
+	}
+	switch {
+	case version == SchemaVersion:
+		fmt.Fprintln(t.output, "Encrypted the store.")
+	case encrypting:
+		fmt.Fprintf(t.output, "Migrated the store from schema version %d to %d and encrypted it.\n", version, SchemaVersion)
+	default:
+		fmt.Fprintf(t.output, "Migrated the store from schema version %d to %d.\n", version, SchemaVersion)
+	}
+	return nil
+}
@@ -0,0 +1,209 @@
+package habit_test
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestOpenJSONStore_SaveWritesSchemaVersion(t *testing.T) {
+	t.Parallel()
+	path := t.TempDir() + "/habits.json"
+	store, err := habit.OpenJSONStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{Name: "programming"})
+	err = store.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got struct {
+		Version int                        `json:"version"`
+		Habits  map[string]json.RawMessage `json:"habits"`
+	}
+	err = json.Unmarshal(data, &got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Version != habit.SchemaVersion {
+		t.Errorf("want schema version %d, got %d", habit.SchemaVersion, got.Version)
+	}
+	if _, ok := got.Habits["programming"]; !ok {
+		t.Errorf("want habit 'programming' under habits, got %s", data)
+	}
+}
+
+func TestOpenJSONStoreMigratesUnversionedStore(t *testing.T) {
+	t.Parallel()
+	path := t.TempDir() + "/legacy.json"
+	err := os.WriteFile(path, []byte(`{
+  "version": {"name": "version", "current_streak": 2, "last_done": "2024-02-06T13:00:00Z"}
+}`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := habit.OpenJSONStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lastDone := time.Date(2024, time.February, 6, 13, 0, 0, 0, time.UTC)
+	want := []habit.Habit{{
+		Name:          "version",
+		CurrentStreak: 2,
+		LongestStreak: 2,
+		LastDone:      lastDone,
+		History:       []habit.Completion{{At: lastDone}},
+	}}
+	got := store.All()
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestOpenStoreReturnsErrorForNewerSchemaVersion(t *testing.T) {
+	t.Parallel()
+	newer := habit.SchemaVersion + 1
+	dir := t.TempDir()
+	err := os.WriteFile(dir+"/newer.json", []byte(fmt.Sprintf(`{"version": %d, "habits": {}}`, newer)), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Create(dir + "/newer.store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = gob.NewEncoder(f).Encode(struct {
+		Version int
+		Habits  map[string]habit.Habit
+	}{Version: newer, Habits: map[string]habit.Habit{"programming": {Name: "programming"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	for _, path := range []string{dir + "/newer.json", dir + "/newer.store"} {
+		_, err = habit.OpenStore(path)
+		if err == nil {
+			t.Errorf("%s: want error for newer schema version, got nil", path)
+			continue
+		}
+		if !strings.Contains(err.Error(), "schema version") {
+			t.Errorf("%s: want schema version error, got %q", path, err)
+		}
+	}
+}
+
+func TestOpenSQLiteStoreMigratesHabitsAndRecordsSchemaVersion(t *testing.T) {
+	t.Parallel()
+	path := t.TempDir() + "/habits.db"
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, stmt := range []string{
+		"CREATE TABLE habits (name TEXT PRIMARY KEY, data TEXT NOT NULL)",
+		`INSERT INTO habits VALUES ('programming', '{"name": "programming", "current_streak": 3, "last_done": "2024-02-06T13:00:00Z"}')`,
+	} {
+		_, err = db.Exec(stmt)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	db.Close()
+	store, err := habit.OpenSQLiteStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	got, ok := store.Get("programming")
+	if !ok {
+		t.Fatal("expected habit 'programming' to be present in store")
+	}
+	if got.LongestStreak != 3 || len(got.History) != 1 {
+		t.Errorf("want longest streak 3 and 1 completion, got %+v", got)
+	}
+	store.Close()
+	db, err = sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	var version int
+	err = db.QueryRow("PRAGMA user_version").Scan(&version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != habit.SchemaVersion {
+		t.Errorf("want user_version %d, got %d", habit.SchemaVersion, version)
+	}
+	_, err = db.Exec(fmt.Sprintf("PRAGMA user_version = %d", habit.SchemaVersion+1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+	_, err = habit.OpenSQLiteStore(path)
+	if err == nil {
+		t.Error("want error for newer schema version, got nil")
+	}
+}
+
+func TestTracker_MigrateRewritesStoreAtSchemaVersion(t *testing.T) {
+	t.Parallel()
+	path := t.TempDir() + "/legacy.json"
+	err := os.WriteFile(path, []byte(`{
+  "reading": {"name": "reading", "current_streak": 2, "last_done": "2024-02-06T13:00:00Z"}
+}`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := habit.OpenJSONStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	preview := habit.NewPreview(store)
+	buf := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(preview), habit.WithOutput(buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Migrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes := preview.Changes()
+	if len(changes) != 1 || changes[0].Before.LongestStreak != 0 || changes[0].After.LongestStreak != 2 {
+		t.Errorf("want the longest streak of 'reading' raised from 0 to 2, got %+v", changes)
+	}
+	tracker, err = habit.NewTracker(habit.WithStore(store), habit.WithOutput(buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Migrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Migrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := fmt.Sprintf(`Migrated the store from schema version 0 to %[1]d.
+Migrated the store from schema version 0 to %[1]d.
+The store is already at schema version %[1]d.
+`, habit.SchemaVersion)
+	if got := buf.String(); want != got {
+		t.Error(cmp.Diff(want, got))
+	}
+}
@@ -0,0 +1,111 @@
+package habit
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// streakMilestones are the streak lengths, in periods of a Habit's frequency,
+// that are celebrated when reached, earning the Habit a Badge and emitting an
+// EventStreakMilestone.
+var streakMilestones = []int{7, 30, 100, 365}
+
+// isStreakMilestone reports whether the given streak is one of the streak
+// milestones.
+func isStreakMilestone(streak int) bool {
+	for _, m := range streakMilestones {
+		if streak == m {
+			return true
+		}
+	}
+	return false
+}
+
+// A Badge records a streak milestone reached by a Habit. Each milestone's
+// Badge is earned once, the first time the milestone is reached.
+type Badge struct {
+	// Streak is the streak milestone that was reached, in periods of the
+	// habit's frequency.
+	Streak int `json:"streak"`
+	// EarnedAt is the timestamp of the completion that reached the
+	// milestone.
+	EarnedAt time.Time `json:"earned_at"`
+}
+
+// name returns the name of the Badge for a habit with the given frequency,
+// such as "7-day".
+func (b Badge) name(f Frequency) string {
+	return fmt.Sprintf("%d-%s", b.Streak, f.unit(1))
+}
+
+// hasBadge reports whether the Habit has earned the Badge for the given streak
+// milestone.
+func (h Habit) hasBadge(streak int) bool {
+	for _, b := range h.Badges {
+		if b.Streak == streak {
+			return true
+		}
+	}
+	return false
+}
+
+// badgeNames returns the names of the Habit's Badges, in the order they were
+// earned, or nil if it has none.
+func (h Habit) badgeNames() []string {
+	if len(h.Badges) == 0 {
+		return nil
+	}
+	names := make([]string, len(h.Badges))
+	for i, b := range h.Badges {
+		names[i] = b.name(h.Frequency)
+	}
+	return names
+}
+
+// celebrate records that the Habit's current streak, reached by the completion
+// at the given timestamp, is a streak milestone. The milestone's Badge is
+// added to the Habit unless it was already earned. It reports whether a new
+// Badge was earned.
+func (h *Habit) celebrate(at time.Time) bool {
+	b := Badge{Streak: h.CurrentStreak, EarnedAt: at}
+	if h.hasBadge(b.Streak) {
+		return false
+	}
+	h.Badges = append(h.Badges, b)
+	return true
+}
+
+// revokeBadges removes the Badges earned by the completion at the given
+// timestamp, such as when the completion is undone.
+func (h *Habit) revokeBadges(at time.Time) {
+	var kept []Badge
+	for _, b := range h.Badges {
+		if !b.EarnedAt.Equal(at) {
+			kept = append(kept, b)
+		}
+	}
+	h.Badges = kept
+}
+
+// mergeBadges returns the union of two lists of Badges, sorted by when they
+// were earned. A milestone earned in both lists keeps its earliest Badge.
+func mergeBadges(a, b []Badge) []Badge {
+	var merged []Badge
+	index := map[int]int{}
+	for _, badge := range append(append([]Badge{}, a...), b...) {
+		i, ok := index[badge.Streak]
+		if !ok {
+			index[badge.Streak] = len(merged)
+			merged = append(merged, badge)
+			continue
+		}
+		if badge.EarnedAt.Before(merged[i].EarnedAt) {
+			merged[i] = badge
+		}
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].EarnedAt.Before(merged[j].EarnedAt)
+	})
+	return merged
+}
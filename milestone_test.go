@@ -0,0 +1,94 @@
+package habit_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTracker_TrackCelebratesMilestoneAndEarnsBadgeOnce(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T09:00:00Z")
+	lastDone := time.Date(2024, time.February, 5, 13, 0, 0, 0, time.UTC)
+	store := &memStore{habits: map[string]habit.Habit{
+		"programming": {Name: "programming", CurrentStreak: 6, LongestStreak: 6, LastDone: lastDone,
+			History: []habit.Completion{{At: lastDone}}},
+	}}
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Track("programming")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Nice work: you've done the habit 'programming' for 7 days in a row now. " +
+		"That's a streak milestone: you've earned the 7-day badge!\n"
+	if got := output.String(); want != got {
+		t.Error(cmp.Diff(want, got))
+	}
+	wantBadges := []habit.Badge{{Streak: 7, EarnedAt: habit.Now()}}
+	if got := store.habits["programming"].Badges; !cmp.Equal(wantBadges, got) {
+		t.Error(cmp.Diff(wantBadges, got))
+	}
+	output.Reset()
+	err = tracker.PrintSummary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = "You are currently on a 7-day streak for 'programming'. That's a new personal best. Keep it going! Strength: 10%. Badges: 7-day.\n"
+	if got := output.String(); want != got {
+		t.Error(cmp.Diff(want, got))
+	}
+
+	hbt := store.habits["programming"]
+	hbt.CurrentStreak = 6
+	hbt.LastDone = habit.Now()
+	store.habits["programming"] = hbt
+	habit.Now = getTimeFunc(t, "2024-02-07T08:00:00Z")
+	output.Reset()
+	err = tracker.Track("programming")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = "Nice work: you've done the habit 'programming' for 7 days in a row now. " +
+		"You've reached the 7-day streak milestone again!\n"
+	if got := output.String(); want != got {
+		t.Error(cmp.Diff(want, got))
+	}
+	if got := store.habits["programming"].Badges; !cmp.Equal(wantBadges, got) {
+		t.Error(cmp.Diff(wantBadges, got))
+	}
+}
+
+func TestTracker_UndoRevokesBadgeEarnedByCompletion(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T09:00:00Z")
+	lastDone := time.Date(2024, time.February, 5, 13, 0, 0, 0, time.UTC)
+	store := &memStore{habits: map[string]habit.Habit{
+		"programming": {Name: "programming", CurrentStreak: 29, LongestStreak: 29, LastDone: lastDone,
+			History: []habit.Completion{{At: lastDone}},
+			Badges:  []habit.Badge{{Streak: 7, EarnedAt: lastDone.AddDate(0, 0, -22)}}},
+	}}
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(new(bytes.Buffer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Track("programming")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(store.habits["programming"].Badges); got != 2 {
+		t.Fatalf("want 2 badges after reaching 30 days, got %d", got)
+	}
+	err = tracker.Undo("programming")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []habit.Badge{{Streak: 7, EarnedAt: lastDone.AddDate(0, 0, -22)}}
+	if got := store.habits["programming"].Badges; !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
@@ -0,0 +1,263 @@
+package habit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// The lowest and highest moods that can be recorded with RecordMood.
+const (
+	MinMood = 1
+	MaxMood = 5
+)
+
+// errNoMoodLog is returned when recording or reading moods with a Tracker that
+// keeps no mood log.
+var errNoMoodLog = errors.New("no mood log is kept; set 'mood_log' in the config file")
+
+// errMoodLogEncrypted is returned when recording or reading moods with a
+// Tracker of an encrypted store, since the mood log would not be encrypted.
+var errMoodLogEncrypted = errors.New("moods are not kept for an encrypted store")
+
+// A Mood is a daily check-in of how the day went, recorded alongside the
+// Habits so that it can be correlated with them.
+type Mood struct {
+	// At is the timestamp when the mood was recorded.
+	At time.Time `json:"at"`
+	// Mood is the mood, from MinMood for a bad day to MaxMood for a great
+	// one.
+	Mood int `json:"mood"`
+	// Note is a free-form note about the day, if any.
+	Note string `json:"note,omitempty"`
+}
+
+// WithMoodLog returns an option that makes a Tracker append the moods recorded
+// with RecordMood to the file at the given path, one JSON-encoded Mood per
+// line, and read them back from it. The file is created when the first mood is
+// recorded.
+func WithMoodLog(path string) option {
+	return func(t *Tracker) error {
+		if path == "" {
+			return errors.New("mood log path must be non-empty")
+		}
+		t.moodLog = path
+		return nil
+	}
+}
+
+// withoutMoodLog returns an option that makes a Tracker keep no mood log,
+// returning the given error when moods are recorded or read.
+func withoutMoodLog(err error) option {
+	return func(t *Tracker) error {
+		t.moodLog = ""
+		t.noMoodLog = err
+		return nil
+	}
+}
+
+// RecordMood records the given mood, with an optional note, as today's
+// check-in, replacing any check-in recorded earlier today, and writes a
+// confirmation to the Tracker's output. An error is returned if the mood is
+// not between MinMood and MaxMood or it cannot be written to the mood log.
+func (t *Tracker) RecordMood(mood int, note string) error {
+	if mood < MinMood || mood > MaxMood {
+		return fmt.Errorf("invalid mood %d (want a number from %d to %d)", mood, MinMood, MaxMood)
+	}
+	moods, err := t.Moods()
+	if err != nil {
+		return err
+	}
+	now := t.now()
+	replaced := len(moods) > 0 && t.calendar.date(moods[len(moods)-1].At).Equal(t.calendar.date(now))
+	f, err := os.OpenFile(t.moodLog, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("cannot write mood log: %w", err)
+	}
+	defer f.Close()
+	err = json.NewEncoder(f).Encode(Mood{At: now, Mood: mood, Note: strings.TrimSpace(note)})
+	if err != nil {
+		return fmt.Errorf("cannot write mood log: %w", err)
+	}
+	err = f.Close()
+	if err != nil {
+		return fmt.Errorf("cannot write mood log: %w", err)
+	}
+	if replaced {
+		fmt.Fprintf(t.output, "Changed today's mood to %d out of %d.\n", mood, MaxMood)
+		return nil
+	}
+	fmt.Fprintf(t.output, "Checked in with a mood of %d out of %d today.\n", mood, MaxMood)
+	return nil
+}
+
+// Moods returns the moods recorded in the Tracker's mood log, one per day in
+// chronological order. A day's last check-in replaces any earlier ones. An
+// error is returned if the Tracker keeps no mood log or it cannot be read or
+// decoded.
+func (t *Tracker) Moods() ([]Mood, error) {
+	if t.moodLog == "" && t.noMoodLog != nil {
+		return nil, t.noMoodLog
+	}
+	if t.moodLog == "" {
+		return nil, errNoMoodLog
+	}
+	f, err := os.Open(t.moodLog)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	byDay := map[time.Time]Mood{}
+	sc := bufio.NewScanner(f)
+	for line := 1; sc.Scan(); line++ {
+		if strings.TrimSpace(sc.Text()) == "" {
+			continue
+		}
+		var m Mood
+		err = json.Unmarshal(sc.Bytes(), &m)
+		if err != nil {
+			return nil, fmt.Errorf("invalid mood on line %d of %s: %w", line, t.moodLog, err)
+		}
+		day := t.calendar.date(m.At)
+		if prev, ok := byDay[day]; !ok || !m.At.Before(prev.At) {
+			byDay[day] = m
+		}
+	}
+	if err = sc.Err(); err != nil {
+		return nil, err
+	}
+	moods := make([]Mood, 0, len(byDay))
+	for _, m := range byDay {
+		moods = append(moods, m)
+	}
+	sort.Slice(moods, func(i, j int) bool {
+		return moods[i].At.Before(moods[j].At)
+	})
+	return moods, nil
+}
+
+// A MoodCorrelation is how closely a Habit being done on a day goes together
+// with a good mood that day.
+type MoodCorrelation struct {
+	// Habit is the name of the habit.
+	Habit string `json:"habit"`
+	// Coefficient is the correlation between the habit being done on a day
+	// and the mood that day, from -1 to 1: positive if the mood was better on
+	// the days the habit was done, negative if it was worse, and 0 if doing
+	// the habit says nothing about the mood.
+	Coefficient float64 `json:"coefficient"`
+	// Days is the number of days compared: the days within the window with
+	// a recorded mood since the habit was first done.
+	Days int `json:"days"`
+	// DoneDays is the number of those days on which the habit was done.
+	DoneDays int `json:"done_days"`
+	// MoodDone is the average mood on the days the habit was done.
+	MoodDone float64 `json:"mood_done"`
+	// MoodNotDone is the average mood on the days the habit was not done.
+	MoodNotDone float64 `json:"mood_not_done"`
+}
+
+// MoodCorrelations returns the correlation between each active Habit being
+// done on a day and the mood recorded that day, over the given number of days
+// before today, sorted by the strength of the correlation, strongest first.
+// Like Correlations, it leaves out today, the days before each Habit was first
+// done, and Habits compared over fewer than 14 days or done on all or none of
+// them. Habits are also left out if the mood was the same on every day
+// compared. An error is returned if the window is not positive or the moods
+// cannot be read.
+func (t *Tracker) MoodCorrelations(window int) ([]MoodCorrelation, error) {
+	if window < 1 {
+		return nil, fmt.Errorf("invalid correlation window %d (want at least 1 day)", window)
+	}
+	moods, err := t.Moods()
+	if err != nil {
+		return nil, err
+	}
+	today := t.calendar.date(t.now())
+	first := today.AddDate(0, 0, -window)
+	correlations := []MoodCorrelation{}
+	for _, hbt := range t.sortedHabits(false) {
+		if len(hbt.History) == 0 {
+			continue
+		}
+		done := map[time.Time]bool{}
+		var start time.Time
+		for _, c := range hbt.History {
+			day := t.calendar.date(c.At)
+			done[day] = true
+			if start.IsZero() || day.Before(start) {
+				start = day
+			}
+		}
+		from := laterOf(first, start)
+		// sum, sumSq and n hold the sum of the moods, the sum of their
+		// squares and their number, on the days the habit was not done at
+		// index 0 and on the days it was done at index 1.
+		var sum, sumSq, n [2]int
+		for _, m := range moods {
+			day := t.calendar.date(m.At)
+			if day.Before(from) || !day.Before(today) {
+				continue
+			}
+			i := b2i(done[day])
+			sum[i] += m.Mood
+			sumSq[i] += m.Mood * m.Mood
+			n[i]++
+		}
+		if n[0]+n[1] < minCorrelationDays || n[0] == 0 || n[1] == 0 {
+			continue
+		}
+		// spread is the variance of all the moods times the square of
+		// their number, kept whole to tell exactly if it is zero.
+		total := n[0] + n[1]
+		spread := total*(sumSq[0]+sumSq[1]) - (sum[0]+sum[1])*(sum[0]+sum[1])
+		if spread == 0 {
+			continue
+		}
+		moodDone, moodNotDone := float64(sum[1])/float64(n[1]), float64(sum[0])/float64(n[0])
+		correlations = append(correlations, MoodCorrelation{
+			Habit:       hbt.Name,
+			Coefficient: (moodDone - moodNotDone) * math.Sqrt(float64(n[0]*n[1])/float64(spread)),
+			Days:        total,
+			DoneDays:    n[1],
+			MoodDone:    moodDone,
+			MoodNotDone: moodNotDone,
+		})
+	}
+	sort.SliceStable(correlations, func(i, j int) bool {
+		return math.Abs(correlations[i].Coefficient) > math.Abs(correlations[j].Coefficient)
+	})
+	return correlations, nil
+}
+
+// PrintMoodCorrelations writes the correlations between the Habits and the
+// mood, as returned by MoodCorrelations with the given window, to the
+// Tracker's output, each with the average moods on the days the Habit was and
+// was not done. An error is returned if the window is not positive or the
+// moods cannot be read.
+func (t *Tracker) PrintMoodCorrelations(window int) error {
+	correlations, err := t.MoodCorrelations(window)
+	if err != nil {
+		return err
+	}
+	if len(correlations) == 0 {
+		fmt.Fprintf(t.output, "Not enough check-ins to correlate your mood with your habits: record your mood with 'habit checkin -mood' on at least %d days.\n",
+			minCorrelationDays)
+		return nil
+	}
+	for _, c := range correlations {
+		fmt.Fprintf(t.output, "'%s': %+.2f, %s correlation with mood (%.1f on the %d %s done, %.1f on the %d %s not)\n",
+			c.Habit, c.Coefficient, correlationStrength(c.Coefficient),
+			c.MoodDone, c.DoneDays, Daily.unit(c.DoneDays), c.MoodNotDone, c.Days-c.DoneDays, Daily.unit(c.Days-c.DoneDays))
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package habit_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTracker_RecordMoodReplacesEarlierCheckInOfTheSameDay(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T09:00:00Z")
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(
+		habit.WithStore(&memStore{habits: map[string]habit.Habit{}}),
+		habit.WithOutput(output),
+		habit.WithMoodLog(filepath.Join(t.TempDir(), "habit.moods")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.RecordMood(2, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	habit.Now = getTimeFunc(t, "2024-02-06T21:00:00Z")
+	err = tracker.RecordMood(4, " slept well ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Checked in with a mood of 2 out of 5 today.\n" +
+		"Changed today's mood to 4 out of 5.\n"
+	if got := output.String(); want != got {
+		t.Error(cmp.Diff(want, got))
+	}
+	moods, err := tracker.Moods()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantMoods := []habit.Mood{{At: time.Date(2024, time.February, 6, 21, 0, 0, 0, time.UTC), Mood: 4, Note: "slept well"}}
+	if !cmp.Equal(wantMoods, moods) {
+		t.Error(cmp.Diff(wantMoods, moods))
+	}
+	for _, mood := range []int{0, 6} {
+		err = tracker.RecordMood(mood, "")
+		if err == nil {
+			t.Errorf("want an error for mood %d", mood)
+		}
+	}
+}
+
+func TestTracker_RecordMoodReturnsErrorWithoutMoodLog(t *testing.T) {
+	t.Parallel()
+	tracker, err := habit.NewTracker(habit.WithStore(&memStore{habits: map[string]habit.Habit{}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.RecordMood(3, "")
+	if err == nil {
+		t.Error("want an error recording a mood without a mood log")
+	}
+}
+
+func TestTracker_PrintMoodCorrelationsComparesMoodOnDaysDoneAndNotDone(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-03-01T09:00:00Z")
+	path := filepath.Join(t.TempDir(), "habit.moods")
+	var log bytes.Buffer
+	enc := json.NewEncoder(&log)
+	// The first check-in of February 10 is replaced by the later one, and
+	// today's check-in is left out.
+	enc.Encode(habit.Mood{At: time.Date(2024, time.February, 10, 8, 0, 0, 0, time.UTC), Mood: 1})
+	for day := 1; day <= 29; day++ {
+		mood := 2
+		if day%2 == 0 {
+			mood = 4
+		}
+		if day == 29 {
+			mood = 3
+		}
+		enc.Encode(habit.Mood{At: time.Date(2024, time.February, day, 21, 0, 0, 0, time.UTC), Mood: mood})
+	}
+	enc.Encode(habit.Mood{At: time.Date(2024, time.March, 1, 8, 0, 0, 0, time.UTC), Mood: 5})
+	err := os.WriteFile(path, log.Bytes(), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := &memStore{habits: map[string]habit.Habit{
+		"exercise": {Name: "exercise", History: februaryHistory(func(day int) bool { return day%2 == 0 })},
+		"reading":  {Name: "reading", History: februaryHistory(func(day int) bool { return day%4 == 1 })},
+		"yoga":     {Name: "yoga", History: februaryHistory(func(day int) bool { return day > 20 })},
+	}}
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output), habit.WithMoodLog(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.PrintMoodCorrelations(habit.DefaultCorrelationWindow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Yoga, started 9 days ago, has too little history to be correlated.
+	want := "'exercise': +0.98, strong correlation with mood (4.0 on the 14 days done, 2.1 on the 14 days not)\n" +
+		"'reading': -0.55, moderate correlation with mood (2.1 on the 8 days done, 3.3 on the 21 days not)\n"
+	if got := output.String(); want != got {
+		t.Error(cmp.Diff(want, got))
+	}
+}
@@ -0,0 +1,325 @@
+package habit
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// GoalWindowDays is the number of days, up to and including today, over which
+// the adherence of a named goal is computed.
+const GoalWindowDays = 7
+
+// A GoalMember is a Habit that counts toward a named goal, such as "Get fit",
+// with the weight of the Habit within the goal.
+type GoalMember struct {
+	// Habit is the name of the habit.
+	Habit string
+	// Weight is how much the habit counts toward the goal relative to the
+	// goal's other habits, such as 2 for a habit that counts twice as much.
+	Weight float64
+}
+
+// A GoalHabit is the adherence of one of a named goal's Habits.
+type GoalHabit struct {
+	// Name is the name of the habit.
+	Name string `json:"name"`
+	// Weight is how much the habit counts toward the goal.
+	Weight float64 `json:"weight"`
+	// Done is the number of periods of the habit's frequency in which it was
+	// done within the goal's window.
+	Done int `json:"done"`
+	// Periods is the number of periods of the habit's frequency within the
+	// goal's window since the habit was first done, not counting the current
+	// period unless the habit has been done in it. It is zero if the habit is
+	// paused or nothing was due yet.
+	Periods int `json:"periods"`
+}
+
+// A GoalReport is the adherence of a named goal, aggregated from the
+// adherence of its Habits.
+type GoalReport struct {
+	// Name is the name of the goal, such as "Get fit".
+	Name string `json:"name"`
+	// Habits are the goal's habits, sorted by name.
+	Habits []GoalHabit `json:"habits"`
+	// Adherence is the weighted average percentage of periods in which the
+	// goal's habits were done over the last GoalWindowDays days.
+	Adherence float64 `json:"adherence"`
+	// PreviousAdherence is the adherence over the GoalWindowDays days
+	// before, or nil if none of the goal's habits had been done by then.
+	PreviousAdherence *float64 `json:"previous_adherence,omitempty"`
+}
+
+// AddToGoal adds the given Habits, with their weights, to the named goal, such
+// as "Get fit", saves the store and writes the goal's Habits to the Tracker's
+// output. A Habit can count toward several goals; adding a Habit already in
+// the goal changes its weight. An error is returned if the goal name is
+// empty, no Habits are given, any of the Habits does not exist, is a habit to
+// avoid or has a weight that is not positive, or the store cannot be saved.
+func (t *Tracker) AddToGoal(goal string, members ...GoalMember) error {
+	if strings.TrimSpace(goal) == "" {
+		return errors.New("goal name cannot be empty")
+	}
+	if len(members) < 1 {
+		return fmt.Errorf("no habits given for goal '%s'", goal)
+	}
+	var habits []Habit
+	for _, m := range members {
+		hbt, ok := t.store.Get(m.Habit)
+		if !ok {
+			return errHabitNotFound(m.Habit)
+		}
+		if hbt.Avoid {
+			return fmt.Errorf("habit '%s' is a habit to avoid and cannot be part of a goal", m.Habit)
+		}
+		if m.Weight <= 0 {
+			return fmt.Errorf("invalid weight %g for habit '%s' (want a positive number)", m.Weight, m.Habit)
+		}
+		weights := make(map[string]float64, len(hbt.GoalWeights)+1)
+		for name, w := range hbt.GoalWeights {
+			weights[name] = w
+		}
+		weights[goal] = m.Weight
+		hbt.GoalWeights = weights
+		habits = append(habits, hbt)
+	}
+	for _, hbt := range habits {
+		t.store.Add(hbt)
+	}
+	err := t.save()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(t.output, "The goal '%s' is made up of %s.\n", goal, joinList(t.describeGoalHabits(goal), "and"))
+	return nil
+}
+
+// RemoveFromGoal removes the Habits with the given names from the named goal
+// and saves the store. Habits that are not in the goal are ignored. An error
+// is returned if no Habits are given, any of the Habits does not exist, or the
+// store cannot be saved.
+func (t *Tracker) RemoveFromGoal(goal string, hbtNames ...string) error {
+	if len(hbtNames) < 1 {
+		return fmt.Errorf("no habits given to remove from goal '%s'", goal)
+	}
+	var habits []Habit
+	for _, name := range hbtNames {
+		hbt, ok := t.store.Get(name)
+		if !ok {
+			return errHabitNotFound(name)
+		}
+		weights := map[string]float64{}
+		for name, w := range hbt.GoalWeights {
+			if name != goal {
+				weights[name] = w
+			}
+		}
+		hbt.GoalWeights = nil
+		if len(weights) > 0 {
+			hbt.GoalWeights = weights
+		}
+		habits = append(habits, hbt)
+	}
+	for _, hbt := range habits {
+		t.store.Add(hbt)
+	}
+	err := t.save()
+	if err != nil {
+		return err
+	}
+	verb := "is"
+	if len(habits) > 1 {
+		verb = "are"
+	}
+	fmt.Fprintf(t.output, "%s %s no longer part of the goal '%s'.\n", joinList(quoteNames(habits), "and"), verb, goal)
+	return nil
+}
+
+// Goals returns a GoalReport for each named goal of the Tracker's tracked
+// Habits, sorted by name. A Habit's adherence is the share of the periods of
+// its frequency within the last GoalWindowDays days in which it was done, as
+// in Risks: the current period counts only once the Habit is done in it, and
+// paused Habits are left out. Days before a Habit was first done are not
+// counted, so that starting a Habit does not lower the adherence of its goals.
+func (t *Tracker) Goals() []GoalReport {
+	now := t.now()
+	// The previous days end at the last instant before the first recent day.
+	previousEnd := t.calendar.start(now).AddDate(0, 0, 1-GoalWindowDays).Add(-time.Nanosecond)
+	reports := []GoalReport{}
+	for _, goal := range t.goalNames() {
+		r := GoalReport{Name: goal, Habits: []GoalHabit{}}
+		var total, weights, previousTotal, previousWeights float64
+		for _, hbt := range t.goalHabits(goal) {
+			gh := GoalHabit{Name: hbt.Name, Weight: hbt.GoalWeights[goal]}
+			if days := hbt.goalDays(now, t.calendar); days > 0 && !hbt.Paused(now) {
+				recent := computeStats(hbt, now, days, t.calendar)
+				if !hbt.doneThisPeriod(now, t.calendar) {
+					recent.PeriodsInWindow--
+				}
+				gh.Done, gh.Periods = recent.PeriodsDone, recent.PeriodsInWindow
+				if days := hbt.goalDays(previousEnd, t.calendar); days > 0 {
+					previous := computeStats(hbt, previousEnd, days, t.calendar)
+					previousTotal += gh.Weight * previous.CompletionRate()
+					previousWeights += gh.Weight
+				}
+			}
+			if gh.Periods > 0 {
+				total += gh.Weight * 100 * float64(gh.Done) / float64(gh.Periods)
+				weights += gh.Weight
+			}
+			r.Habits = append(r.Habits, gh)
+		}
+		if weights > 0 {
+			r.Adherence = total / weights
+		}
+		if previousWeights > 0 {
+			previous := previousTotal / previousWeights
+			r.PreviousAdherence = &previous
+		}
+		reports = append(reports, r)
+	}
+	return reports
+}
+
+// PrintGoals writes each named goal, as returned by Goals, to the Tracker's
+// output, with its adherence over the last GoalWindowDays days followed by
+// one line for each of its Habits.
+func (t *Tracker) PrintGoals() {
+	reports := t.Goals()
+	if len(reports) < 1 {
+		fmt.Fprintln(t.output, "You haven't added any habits to a goal.")
+		return
+	}
+	now := t.now()
+	for _, r := range reports {
+		fmt.Fprintln(t.output, r.describe())
+		for _, gh := range r.Habits {
+			hbt, _ := t.store.Get(gh.Name)
+			name := fmt.Sprintf("'%s'", gh.Name)
+			if gh.Weight != 1 {
+				name += fmt.Sprintf(" (weight %s)", formatAmount(gh.Weight, ""))
+			}
+			switch {
+			case hbt.Paused(now):
+				fmt.Fprintf(t.output, "  %s: paused\n", name)
+			case gh.Periods < 1:
+				fmt.Fprintf(t.output, "  %s: nothing due yet\n", name)
+			default:
+				fmt.Fprintf(t.output, "  %s: done in %d of %d %s\n",
+					name, gh.Done, gh.Periods, hbt.Frequency.unit(gh.Periods))
+			}
+		}
+	}
+}
+
+// describe returns a line describing the goal's adherence, such as "Goal 'Get
+// fit': 75% over the last 7 days (50% the 7 days before).".
+func (r GoalReport) describe() string {
+	line := fmt.Sprintf("Goal '%s': %.0f%% over the last %d days", r.Name, r.Adherence, GoalWindowDays)
+	if r.PreviousAdherence != nil {
+		line += fmt.Sprintf(" (%.0f%% the %d days before)", *r.PreviousAdherence, GoalWindowDays)
+	}
+	return line + "."
+}
+
+// hasHabit reports whether the Habit with the given name is one of the goal's
+// Habits.
+func (r GoalReport) hasHabit(hbtName string) bool {
+	for _, gh := range r.Habits {
+		if gh.Name == hbtName {
+			return true
+		}
+	}
+	return false
+}
+
+// summarizeGoals returns a summary line for each named goal with at least one
+// of the given Habits, sorted by goal name.
+func (t *Tracker) summarizeGoals(habits []Habit) []string {
+	included := map[string]bool{}
+	for _, hbt := range habits {
+		for goal := range hbt.GoalWeights {
+			included[goal] = true
+		}
+	}
+	var lines []string
+	for _, r := range t.Goals() {
+		if !included[r.Name] {
+			continue
+		}
+		data := MessageData{GoalName: r.Name, Percent: int(r.Adherence + 0.5)}
+		if r.PreviousAdherence == nil {
+			lines = append(lines, t.text("summary_named_goal", data))
+			continue
+		}
+		data.PreviousPercent = int(*r.PreviousAdherence + 0.5)
+		lines = append(lines, t.text("summary_named_goal_previous", data))
+	}
+	return lines
+}
+
+// describeGoalHabits returns the quoted names of the tracked Habits in the
+// named goal, each followed by its weight unless the weight is 1.
+func (t *Tracker) describeGoalHabits(goal string) []string {
+	var names []string
+	for _, hbt := range t.goalHabits(goal) {
+		name := fmt.Sprintf("'%s'", hbt.Name)
+		if w := hbt.GoalWeights[goal]; w != 1 {
+			name += fmt.Sprintf(" (weight %s)", formatAmount(w, ""))
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// goalNames returns the names of the named goals of the Tracker's tracked
+// Habits, sorted by name.
+func (t *Tracker) goalNames() []string {
+	seen := map[string]bool{}
+	var goals []string
+	for _, hbt := range t.sortedHabits(false) {
+		for goal := range hbt.GoalWeights {
+			if !seen[goal] {
+				seen[goal] = true
+				goals = append(goals, goal)
+			}
+		}
+	}
+	sort.Strings(goals)
+	return goals
+}
+
+// goalHabits returns the tracked Habits in the named goal, sorted by name.
+func (t *Tracker) goalHabits(goal string) []Habit {
+	var habits []Habit
+	for _, hbt := range t.sortedHabits(false) {
+		if _, ok := hbt.GoalWeights[goal]; ok {
+			habits = append(habits, hbt)
+		}
+	}
+	return habits
+}
+
+// goalDays returns the number of days, at most GoalWindowDays, from the date
+// the Habit was first done up to and including the date of the given
+// timestamp, or zero if the Habit had not been done by then.
+func (h Habit) goalDays(end time.Time, cal calendar) int {
+	first := h.firstDone()
+	if first.IsZero() || first.After(end) {
+		return 0
+	}
+	return min(GoalWindowDays, cal.daysBetween(first, end)+1)
+}
+
+// firstDone returns the timestamp when the Habit was first done, or the zero
+// time if it has never been done.
+func (h Habit) firstDone() time.Time {
+	first := h.LastDone
+	if len(h.History) > 0 && (first.IsZero() || h.History[0].At.Before(first)) {
+		first = h.History[0].At
+	}
+	return first
+}
@@ -0,0 +1,156 @@
+package habit_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTracker_AddToGoalAndRemoveFromGoalSetGoalWeights(t *testing.T) {
+	t.Parallel()
+	store := &memStore{habits: map[string]habit.Habit{
+		"running": {Name: "running", GoalWeights: map[string]float64{"Health": 1}},
+		"sleep":   {Name: "sleep"},
+		"yoga":    {Name: "yoga"},
+	}}
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.AddToGoal("Get fit",
+		habit.GoalMember{Habit: "running", Weight: 2},
+		habit.GoalMember{Habit: "sleep", Weight: 1},
+		habit.GoalMember{Habit: "yoga", Weight: 0.5},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.RemoveFromGoal("Get fit", "yoga")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantWeights := map[string]map[string]float64{
+		"running": {"Get fit": 2, "Health": 1},
+		"sleep":   {"Get fit": 1},
+		"yoga":    nil,
+	}
+	for name, want := range wantWeights {
+		if got := store.habits[name].GoalWeights; !cmp.Equal(want, got) {
+			t.Errorf("habit '%s': %s", name, cmp.Diff(want, got))
+		}
+	}
+	want := "The goal 'Get fit' is made up of 'running' (weight 2), 'sleep' and 'yoga' (weight 0.5).\n" +
+		"'yoga' is no longer part of the goal 'Get fit'.\n"
+	if got := output.String(); want != got {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestTracker_AddToGoalReturnsErrorForInvalidInput(t *testing.T) {
+	t.Parallel()
+	store := &memStore{habits: map[string]habit.Habit{
+		"running": {Name: "running"},
+		"smoking": {Name: "smoking", Avoid: true},
+	}}
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(new(bytes.Buffer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]struct {
+		goal    string
+		members []habit.GoalMember
+	}{
+		"empty goal name": {"", []habit.GoalMember{{Habit: "running", Weight: 1}}},
+		"no habits":       {"Get fit", nil},
+		"missing habit":   {"Get fit", []habit.GoalMember{{Habit: "running", Weight: 1}, {Habit: "swimming", Weight: 1}}},
+		"habit to avoid":  {"Get fit", []habit.GoalMember{{Habit: "smoking", Weight: 1}}},
+		"zero weight":     {"Get fit", []habit.GoalMember{{Habit: "running", Weight: 0}}},
+	}
+	for name, tc := range tests {
+		err = tracker.AddToGoal(tc.goal, tc.members...)
+		if err == nil {
+			t.Errorf("%s: want error, got nil", name)
+		}
+	}
+	if store.habits["running"].GoalWeights != nil || store.saves != 0 {
+		t.Error("want no habit changed after errors")
+	}
+}
+
+func newGoalTracker(t *testing.T, output *bytes.Buffer) *habit.Tracker {
+	t.Helper()
+	habit.Now = getTimeFunc(t, "2024-01-14T09:00:00Z")
+	running := dailyHistory(1, 2, 3, 4, 5, 6, 7, 8, 10, 12)
+	sleep := dailyHistory(5, 7, 8, 9, 10, 11, 12, 13)
+	yoga := dailyHistory(10)
+	store := &memStore{habits: map[string]habit.Habit{
+		"running": {
+			Name: "running", History: running, LastDone: running[len(running)-1].At,
+			GoalWeights: map[string]float64{"Get fit": 2},
+		},
+		"sleep": {
+			Name: "sleep", History: sleep, LastDone: sleep[len(sleep)-1].At,
+			GoalWeights: map[string]float64{"Get fit": 1},
+		},
+		"yoga": {
+			Name: "yoga", History: yoga, LastDone: yoga[len(yoga)-1].At,
+			GoalWeights: map[string]float64{"Get fit": 1},
+		},
+		"reading": {Name: "reading", History: sleep, LastDone: sleep[len(sleep)-1].At},
+	}}
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tracker
+}
+
+func TestTracker_PrintGoalsReportsWeightedAdherence(t *testing.T) {
+	output := new(bytes.Buffer)
+	tracker := newGoalTracker(t, output)
+	tracker.PrintGoals()
+	// Today is not counted since none of the habits has been done yet, and
+	// neither are the days before 'yoga' was started. In the 7 days before,
+	// 'running' was done every day and 'sleep' on 2 of the 3 days since it
+	// was started.
+	want := "Goal 'Get fit': 56% over the last 7 days (89% the 7 days before).\n" +
+		"  'running' (weight 2): done in 3 of 6 days\n" +
+		"  'sleep': done in 6 of 6 days\n" +
+		"  'yoga': done in 1 of 4 days\n"
+	if got := output.String(); want != got {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestTracker_PrintSummaryAndPrintStatsReportGoals(t *testing.T) {
+	output := new(bytes.Buffer)
+	tracker := newGoalTracker(t, output)
+	err := tracker.PrintSummary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Goal 'Get fit': 56% over the last 7 days (89% the 7 days before).\n"
+	if got := output.String(); !strings.HasSuffix(got, want) {
+		t.Errorf("want summary ending with %q, got:\n%s", want, got)
+	}
+	output.Reset()
+	err = tracker.PrintStats("yoga", 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := output.String(); !strings.HasSuffix(got, want) {
+		t.Errorf("want stats ending with %q, got:\n%s", want, got)
+	}
+	output.Reset()
+	err = tracker.PrintStats("reading", 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := output.String(); strings.Contains(got, "Goal") {
+		t.Errorf("want no goals in stats of a habit without goals, got:\n%s", got)
+	}
+}
@@ -0,0 +1,336 @@
+package habit
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// apiVersion is the first segment of the paths under which the current
+// version of the REST API is served, such as /v1/habits. The API is also
+// served without it for clients written before it was versioned.
+const apiVersion = "v1"
+
+// An apiOperation describes an endpoint of the REST API in the OpenAPI
+// document served by a Server.
+type apiOperation struct {
+	// method is the HTTP method of the endpoint.
+	method string
+	// path is the path of the endpoint below the API version, with path
+	// parameters in braces, such as /habits/{name}.
+	path string
+	// summary is a one-line description of the endpoint.
+	summary string
+	// params lists the endpoint's query and header parameters, which are all
+	// optional.
+	params []apiParameter
+	// request is a value of the type of the endpoint's JSON request body, or
+	// nil if it takes no body.
+	request any
+	// response is a value of the type of the endpoint's JSON response body,
+	// or nil if it responds with contentType.
+	response any
+	// contentType is the media type of the endpoint's response if it is not
+	// JSON.
+	contentType string
+	// public is true if the endpoint is served without a token.
+	public bool
+}
+
+// An apiParameter describes a query or header parameter of an apiOperation.
+type apiParameter struct {
+	name string
+	// in is where the parameter is given, "query" or "header".
+	in          string
+	description string
+	// schema is the JSON schema of the parameter's value.
+	schema map[string]any
+}
+
+// apiOperations lists the endpoints of the REST API in the order they appear
+// in the OpenAPI document. Request and response schemas are generated from the
+// types of their values, so that the document follows changes to them.
+var apiOperations = []apiOperation{
+	{
+		method:   http.MethodGet,
+		path:     "/habits",
+		summary:  "List summaries of all habits",
+		params:   []apiParameter{{name: "tag", in: "query", description: "only list habits with this tag (can be repeated)", schema: map[string]any{"type": "array", "items": map[string]any{"type": "string"}}}},
+		response: []HabitSummary{},
+	},
+	{
+		method:   http.MethodGet,
+		path:     "/habits/{name}",
+		summary:  "Get a habit with its full history",
+		response: Habit{},
+	},
+	{
+		method:   http.MethodPut,
+		path:     "/habits/{name}",
+		summary:  "Store a habit as is, replacing any existing one",
+		request:  Habit{},
+		response: Habit{},
+	},
+	{
+		method:   http.MethodDelete,
+		path:     "/habits/{name}",
+		summary:  "Delete a habit",
+		response: messageResponse{},
+	},
+	{
+		method:   http.MethodPost,
+		path:     "/habits/{name}/track",
+		summary:  "Track a habit, now or at the given time, with an optional note",
+		params:   []apiParameter{{name: "Idempotency-Key", in: "header", description: "key with which the habit is only tracked once however often the request is retried", schema: map[string]any{"type": "string"}}},
+		request:  trackRequest{},
+		response: messageResponse{},
+	},
+	{
+		method:   http.MethodPost,
+		path:     "/completions:batch",
+		summary:  "Track a batch of completions, such as those queued offline, with a single save",
+		request:  batchRequest{},
+		response: batchResponse{},
+	},
+	{
+		method:   http.MethodGet,
+		path:     "/habits/{name}/stats",
+		summary:  "Get the statistics of a habit",
+		params:   []apiParameter{{name: "days", in: "query", description: "number of days to compute statistics over (default 30)", schema: map[string]any{"type": "integer"}}},
+		response: statsResponse{},
+	},
+	{
+		method:   http.MethodGet,
+		path:     "/stats",
+		summary:  "Get the statistics of all habits",
+		params:   []apiParameter{{name: "days", in: "query", description: "number of days to compute statistics over (default 30)", schema: map[string]any{"type": "integer"}}},
+		response: []statsResponse{},
+	},
+	{
+		method:      http.MethodGet,
+		path:        "/export",
+		summary:     "Export every habit",
+		params:      []apiParameter{{name: "format", in: "query", description: "format to export in: store, json, jsonl, csv or ics (default json)", schema: map[string]any{"type": "string"}}},
+		contentType: "*/*",
+	},
+	{
+		method:      http.MethodGet,
+		path:        "/metrics",
+		summary:     "Get the metrics of every habit in the Prometheus text format",
+		contentType: "text/plain",
+	},
+	{
+		method:   http.MethodPost,
+		path:     "/graphql",
+		summary:  "Run a GraphQL query or mutation",
+		request:  graphqlRequest{},
+		response: map[string]any{},
+	},
+	{
+		method:   http.MethodPost,
+		path:     "/shares",
+		summary:  "Create a read-only share link for some habits",
+		request:  shareRequest{},
+		response: shareResponse{},
+	},
+	{
+		method:   http.MethodGet,
+		path:     "/shared/{token}",
+		summary:  "Get summaries of the habits shared by a share link, without a token",
+		response: sharedResponse{},
+		public:   true,
+	},
+}
+
+// pathParameter matches the path parameters in the paths of apiOperations.
+var pathParameter = regexp.MustCompile(`\{(\w+)\}`)
+
+// serveOpenAPI writes the OpenAPI document of the REST API if the given
+// request asks for it at /openapi.json or /v1/openapi.json, and reports
+// whether it did. The document holds no habit data, so it is served without a
+// token, like the dashboard, so that clients can be generated from it.
+func (s *Server) serveOpenAPI(w http.ResponseWriter, r *http.Request) bool {
+	path := strings.TrimPrefix(r.URL.Path, "/"+apiVersion)
+	if path != "/openapi.json" || (r.Method != http.MethodGet && r.Method != http.MethodHead) {
+		return false
+	}
+	writeJSON(w, http.StatusOK, s.openAPIDocument())
+	return true
+}
+
+// openAPIDocument returns the OpenAPI 3 document describing the REST API
+// served by the Server, requiring a bearer token if the Server has a token or
+// users.
+func (s *Server) openAPIDocument() map[string]any {
+	schemas := map[string]any{}
+	paths := map[string]any{}
+	for _, op := range apiOperations {
+		var params []any
+		for _, match := range pathParameter.FindAllStringSubmatch(op.path, -1) {
+			params = append(params, map[string]any{
+				"name":     match[1],
+				"in":       "path",
+				"required": true,
+				"schema":   map[string]any{"type": "string"},
+			})
+		}
+		for _, p := range op.params {
+			params = append(params, map[string]any{
+				"name":        p.name,
+				"in":          p.in,
+				"description": p.description,
+				"schema":      p.schema,
+			})
+		}
+		content := map[string]any{op.contentType: map[string]any{"schema": map[string]any{"type": "string"}}}
+		if op.response != nil {
+			content = jsonContent(reflect.TypeOf(op.response), schemas)
+		}
+		operation := map[string]any{
+			"summary":     op.summary,
+			"operationId": operationID(op),
+			"responses": map[string]any{
+				"200":     map[string]any{"description": "OK", "content": content},
+				"default": map[string]any{"description": "Error", "content": jsonContent(reflect.TypeOf(errorResponse{}), schemas)},
+			},
+		}
+		if params != nil {
+			operation["parameters"] = params
+		}
+		if op.public {
+			operation["security"] = []any{}
+		}
+		if op.request != nil {
+			operation["requestBody"] = map[string]any{
+				"required": op.method == http.MethodPut,
+				"content":  jsonContent(reflect.TypeOf(op.request), schemas),
+			}
+		}
+		item, ok := paths[op.path].(map[string]any)
+		if !ok {
+			item = map[string]any{}
+			paths[op.path] = item
+		}
+		item[strings.ToLower(op.method)] = operation
+	}
+	doc := map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":       "habit",
+			"description": "Track habits and their streaks.",
+			"version":     strings.TrimPrefix(apiVersion, "v"),
+		},
+		"servers": []any{map[string]any{"url": "/" + apiVersion}},
+		"paths":   paths,
+		"components": map[string]any{
+			"schemas": schemas,
+		},
+	}
+	if s.token != "" || len(s.users) > 0 {
+		components := doc["components"].(map[string]any)
+		components["securitySchemes"] = map[string]any{
+			"bearerAuth": map[string]any{"type": "http", "scheme": "bearer"},
+		}
+		doc["security"] = []any{map[string]any{"bearerAuth": []any{}}}
+	}
+	return doc
+}
+
+// operationID returns the OpenAPI operation ID of the given operation, such as
+// getHabitsNameStats for GET /habits/{name}/stats and postCompletionsBatch for
+// POST /completions:batch.
+func operationID(op apiOperation) string {
+	id := strings.ToLower(op.method)
+	for _, part := range strings.FieldsFunc(op.path, func(r rune) bool {
+		return strings.ContainsRune("/{}:", r)
+	}) {
+		id += exportedName(part)
+	}
+	return id
+}
+
+// jsonContent returns the OpenAPI content of a JSON body of the given type,
+// adding the schemas of the structs it uses to schemas.
+func jsonContent(t reflect.Type, schemas map[string]any) map[string]any {
+	return map[string]any{"application/json": map[string]any{"schema": jsonSchema(t, schemas)}}
+}
+
+// jsonSchema returns the JSON schema of the JSON encoding of values of the
+// given type. Structs are described once in schemas, under their names with
+// the first letter upper-cased, and referred to by the returned schema.
+func jsonSchema(t reflect.Type, schemas map[string]any) map[string]any {
+	if t == reflect.TypeOf(time.Time{}) {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return jsonSchema(t.Elem(), schemas)
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": jsonSchema(t.Elem(), schemas)}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": jsonSchema(t.Elem(), schemas)}
+	case reflect.Struct:
+		name := exportedName(t.Name())
+		if _, ok := schemas[name]; !ok {
+			// The placeholder stops recursive types from recursing forever.
+			schemas[name] = nil
+			properties := map[string]any{}
+			var required []string
+			addProperties(t, properties, &required, schemas)
+			schema := map[string]any{"type": "object", "properties": properties}
+			if required != nil {
+				schema["required"] = required
+			}
+			schemas[name] = schema
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + name}
+	}
+	return map[string]any{}
+}
+
+// addProperties adds the JSON schemas of the fields of the given struct type
+// to properties, and the names of those that are always encoded to required,
+// including the fields of embedded structs as encoding/json does.
+func addProperties(t reflect.Type, properties map[string]any, required *[]string, schemas map[string]any) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if field.Anonymous && tag == "" {
+			addProperties(field.Type, properties, required, schemas)
+			continue
+		}
+		if !field.IsExported() || tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = jsonSchema(field.Type, schemas)
+		if !strings.Contains(opts, "omitempty") {
+			*required = append(*required, name)
+		}
+	}
+}
+
+// exportedName returns the given name with its first letter upper-cased.
+func exportedName(name string) string {
+	if name == "" {
+		return name
+	}
+	r := []rune(name)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
@@ -0,0 +1,169 @@
+package habit_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+// openAPIDocument is the part of an OpenAPI document checked by tests.
+type openAPIDocument struct {
+	OpenAPI    string                                `json:"openapi"`
+	Servers    []struct{ URL string }                `json:"servers"`
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Security   []map[string][]string                 `json:"security"`
+	Components struct {
+		Schemas map[string]struct {
+			Properties map[string]map[string]any `json:"properties"`
+			Required   []string                  `json:"required"`
+		} `json:"schemas"`
+	} `json:"components"`
+}
+
+func getOpenAPIDocument(t *testing.T, url string) openAPIDocument {
+	t.Helper()
+	resp, err := http.Get(url + "/openapi.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("want status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	var doc openAPIDocument
+	err = json.NewDecoder(resp.Body).Decode(&doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func TestServer_GetOpenAPIReturnsDocumentWithoutToken(t *testing.T) {
+	t.Parallel()
+	store, err := habit.OpenStore(t.TempDir() + "/test.store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(new(bytes.Buffer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(habit.NewServer(tracker, habit.WithToken("secret")))
+	defer srv.Close()
+	doc := getOpenAPIDocument(t, srv.URL)
+	if doc.OpenAPI != "3.0.3" {
+		t.Errorf("want OpenAPI version 3.0.3, got %q", doc.OpenAPI)
+	}
+	if len(doc.Servers) != 1 || doc.Servers[0].URL != "/v1" {
+		t.Errorf("want server URL /v1, got %v", doc.Servers)
+	}
+	wantSecurity := []map[string][]string{{"bearerAuth": {}}}
+	if !cmp.Equal(wantSecurity, doc.Security) {
+		t.Error(cmp.Diff(wantSecurity, doc.Security))
+	}
+	var methods []string
+	for _, method := range []string{"get", "put", "delete"} {
+		if _, ok := doc.Paths["/habits/{name}"][method]; ok {
+			methods = append(methods, method)
+		}
+	}
+	wantMethods := []string{"get", "put", "delete"}
+	if !cmp.Equal(wantMethods, methods) {
+		t.Error(cmp.Diff(wantMethods, methods))
+	}
+	habitSchema := doc.Components.Schemas["Habit"]
+	wantHistory := map[string]any{
+		"type":  "array",
+		"items": map[string]any{"$ref": "#/components/schemas/Completion"},
+	}
+	if !cmp.Equal(wantHistory, habitSchema.Properties["history"]) {
+		t.Error(cmp.Diff(wantHistory, habitSchema.Properties["history"]))
+	}
+	wantRequired := []string{"name", "current_streak", "longest_streak", "last_done"}
+	if !cmp.Equal(wantRequired, habitSchema.Required) {
+		t.Error(cmp.Diff(wantRequired, habitSchema.Required))
+	}
+	// The fields of embedded structs are properties of the embedding struct.
+	if _, ok := doc.Components.Schemas["StatsResponse"].Properties["periods_done"]; !ok {
+		t.Error("want StatsResponse to have property periods_done")
+	}
+}
+
+func TestServer_RoutesEveryOperationOfOpenAPIDocument(t *testing.T) {
+	t.Parallel()
+	srv, _ := newTestServer(t)
+	doc := getOpenAPIDocument(t, srv.URL+"/v1")
+	bodies := map[string]string{
+		"put /habits/{name}":        `{"name": "programming"}`,
+		"post /habits/{name}/track": `{"note": "a chapter"}`,
+		"post /graphql":             `{"query": "{ habits { name } }"}`,
+		"post /completions:batch":   `{"completions": [{"habit": "reading"}]}`,
+		"delete /habits/{name}":     "",
+		"get /habits/{name}/stats":  "",
+		"get /habits/{name}":        "",
+		"get /habits":               "",
+		"get /stats":                "",
+		"get /export":               "",
+		"get /metrics":              "",
+		"post /shares":              `{"habits": ["programming"]}`,
+		"get /shared/{token}":       "",
+	}
+	for path, item := range doc.Paths {
+		for method := range item {
+			op := method + " " + path
+			t.Run(op, func(t *testing.T) {
+				body, ok := bodies[op]
+				if !ok {
+					t.Fatalf("no test request for %s", op)
+				}
+				srv, _ := newTestServer(t, habit.Habit{Name: "programming"})
+				url := srv.URL + "/v1" + strings.ReplaceAll(path, "{name}", "programming")
+				url = strings.ReplaceAll(url, "{token}", newShareToken(t, "programming"))
+				req, err := http.NewRequest(strings.ToUpper(method), url, strings.NewReader(body))
+				if err != nil {
+					t.Fatal(err)
+				}
+				resp, err := http.DefaultClient.Do(req)
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer resp.Body.Close()
+				if resp.StatusCode != http.StatusOK {
+					msg, _ := io.ReadAll(resp.Body)
+					t.Errorf("want status %d, got %d: %s", http.StatusOK, resp.StatusCode, msg)
+				}
+			})
+		}
+	}
+}
+
+func TestServer_ServesVersionedAndUnversionedRoutes(t *testing.T) {
+	t.Parallel()
+	srv, _ := newTestServer(t, habit.Habit{Name: "programming"})
+	var bodies []string
+	for _, path := range []string{"/v1/habits/programming", "/habits/programming"} {
+		resp, err := http.Get(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("%s: want status %d, got %d", path, http.StatusOK, resp.StatusCode)
+		}
+		bodies = append(bodies, string(body))
+	}
+	if bodies[0] != bodies[1] {
+		t.Errorf("want same body for versioned and unversioned routes, got %q and %q", bodies[0], bodies[1])
+	}
+}
@@ -0,0 +1,113 @@
+package habit
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// A Pause is a span of time during which a Habit's streak cannot be broken,
+// such as while its owner is sick or travelling.
+type Pause struct {
+	// From is the timestamp when the pause started.
+	From time.Time `json:"from"`
+	// Until is the timestamp when the pause ends. It is zero if the pause
+	// lasts until the habit is resumed.
+	Until time.Time `json:"until,omitempty"`
+}
+
+// covers returns true if the Pause covers the given timestamp.
+func (p Pause) covers(at time.Time) bool {
+	return !at.Before(p.From) && (p.Until.IsZero() || at.Before(p.Until))
+}
+
+// Paused returns true if the Habit is paused at the given timestamp.
+func (h Habit) Paused(at time.Time) bool {
+	for _, p := range h.Pauses {
+		if p.covers(at) {
+			return true
+		}
+	}
+	return false
+}
+
+// pausedTime returns the time between the 2 given timestamps during which the
+// Habit was paused.
+func (h Habit) pausedTime(from, to time.Time) time.Duration {
+	var paused time.Duration
+	for _, p := range h.Pauses {
+		start, end := p.From, p.Until
+		if end.IsZero() || end.After(to) {
+			end = to
+		}
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(start) {
+			paused += end.Sub(start)
+		}
+	}
+	return paused
+}
+
+// Pause pauses the Habit with the given name until the given timestamp, or
+// until it is resumed if the timestamp is zero, and saves the store. The
+// Habit's streak is not broken by periods missed while it is paused. An error
+// is returned if the Habit does not exist, is already paused, or the timestamp
+// is not in the future, or if the store cannot be saved.
+func (t *Tracker) Pause(hbtName string, until time.Time) error {
+	hbt, ok := t.store.Get(hbtName)
+	if !ok {
+		return errHabitNotFound(hbtName)
+	}
+	now := t.now()
+	if hbt.Paused(now) {
+		return fmt.Errorf("habit '%s' is already paused", hbtName)
+	}
+	if !until.IsZero() && !until.After(now) {
+		return fmt.Errorf("cannot pause habit '%s' until %q because it is not in the future",
+			hbtName, until.Format(time.RFC3339))
+	}
+	hbt.Pauses = append(hbt.Pauses, Pause{From: now, Until: until})
+	t.store.Add(hbt)
+	err := t.save()
+	if err != nil {
+		return err
+	}
+	if until.IsZero() {
+		fmt.Fprintf(t.output, "The habit '%s' is paused until you resume it. Your streak is safe.\n", hbtName)
+		return nil
+	}
+	fmt.Fprintf(t.output, "The habit '%s' is paused until %s. Your streak is safe.\n",
+		hbtName, until.In(t.calendar.location).Format(time.DateOnly))
+	return nil
+}
+
+// Resume ends the current pause of the Habit with the given name and saves the
+// store. An error is returned if the Habit does not exist or is not paused, or
+// if the store cannot be saved.
+func (t *Tracker) Resume(hbtName string) error {
+	hbt, ok := t.store.Get(hbtName)
+	if !ok {
+		return errHabitNotFound(hbtName)
+	}
+	now := t.now()
+	resumed := false
+	hbt.Pauses = slices.Clone(hbt.Pauses)
+	for i, p := range hbt.Pauses {
+		if p.covers(now) {
+			hbt.Pauses[i].Until = now
+			resumed = true
+		}
+	}
+	if !resumed {
+		return fmt.Errorf("habit '%s' is not paused", hbtName)
+	}
+	t.store.Add(hbt)
+	err := t.save()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(t.output, "The habit '%s' has been resumed. Welcome back!\n", hbtName)
+	return nil
+}
@@ -0,0 +1,208 @@
+package habit_test
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTracker_TrackKeepsStreakAfterPausedDays(t *testing.T) {
+	lastDone, err := time.Parse(time.RFC3339, "2024-02-01T13:00:00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := habit.OpenStore(t.TempDir() + "/test.store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{
+		Name:          "programming",
+		CurrentStreak: 3,
+		LongestStreak: 3,
+		LastDone:      lastDone,
+		History:       []habit.Completion{{At: lastDone}},
+	})
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	habit.Now = getTimeFunc(t, "2024-02-01T20:00:00Z")
+	until := time.Date(2024, time.February, 5, 0, 0, 0, 0, time.UTC)
+	err = tracker.Pause("programming", until)
+	if err != nil {
+		t.Fatal(err)
+	}
+	habit.Now = getTimeFunc(t, "2024-02-05T09:00:00Z")
+	err = tracker.Track("programming")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "The habit 'programming' is paused until 2024-02-05. Your streak is safe.\n" +
+		"Nice work: you've done the habit 'programming' for 4 days in a row now.\n"
+	got := output.String()
+	if want != got {
+		t.Errorf("want output %q, got output %q", want, got)
+	}
+}
+
+func TestTracker_PrintSummaryReportsPausedHabit(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-10T13:00:00Z")
+	lastDone, err := time.Parse(time.RFC3339, "2024-02-01T13:00:00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := habit.OpenStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{
+		Name:          "programming",
+		CurrentStreak: 3,
+		LongestStreak: 3,
+		LastDone:      lastDone,
+		Pauses:        []habit.Pause{{From: lastDone.Add(time.Hour)}},
+	})
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.PrintSummary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "'programming' is paused, so your 3-day streak is safe until you resume it.\n"
+	got := output.String()
+	if want != got {
+		t.Errorf("want output %q, got output %q", want, got)
+	}
+	if due := tracker.Due(); len(due) != 0 {
+		t.Errorf("want no habits due while paused, got %d", len(due))
+	}
+}
+
+func TestTracker_ResumeEndsPauseAtCurrentTime(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-10T13:00:00Z")
+	from, err := time.Parse(time.RFC3339, "2024-02-01T13:00:00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := habit.OpenStore(t.TempDir() + "/test.store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{Name: "programming", Pauses: []habit.Pause{{From: from}}})
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Resume("programming")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, ok := store.Get("programming")
+	if !ok {
+		t.Fatal("expected habit 'programming' to be present in store")
+	}
+	want := []habit.Pause{{From: from, Until: habit.Now()}}
+	if !cmp.Equal(want, got.Pauses) {
+		t.Error(cmp.Diff(want, got.Pauses))
+	}
+	wantOutput := "The habit 'programming' has been resumed. Welcome back!\n"
+	if wantOutput != output.String() {
+		t.Errorf("want output %q, got output %q", wantOutput, output.String())
+	}
+	err = tracker.Resume("programming")
+	if err == nil {
+		t.Error("expected an error resuming a habit that is not paused")
+	}
+}
+
+func TestTracker_ResumeIsRecordedByAuditLogAndPreview(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-10T13:00:00Z")
+	from := time.Date(2024, time.February, 1, 13, 0, 0, 0, time.UTC)
+	paused := habit.Habit{Name: "programming", Pauses: []habit.Pause{{From: from}}}
+
+	logPath := filepath.Join(t.TempDir(), "audit.log")
+	tracker, err := habit.NewTracker(
+		habit.WithStore(habit.WithAuditLog(&memStore{habits: map[string]habit.Habit{"programming": paused}}, logPath, "resume")),
+		habit.WithOutput(io.Discard),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Resume("programming")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	entries, err := habit.ReadAuditLog(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Fields["pauses"] == nil {
+		t.Errorf("want the changed pauses recorded, got %+v", entries)
+	}
+
+	preview := habit.NewPreview(&memStore{habits: map[string]habit.Habit{
+		"programming": {Name: "programming", Pauses: []habit.Pause{{From: from}}},
+	}})
+	tracker, err = habit.NewTracker(habit.WithStore(preview), habit.WithOutput(io.Discard))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Resume("programming")
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes := preview.Changes()
+	if len(changes) != 1 {
+		t.Fatalf("want 1 change, got %+v", changes)
+	}
+	want := []habit.Pause{{From: from}}
+	if !cmp.Equal(want, changes[0].Before.Pauses) {
+		t.Error(cmp.Diff(want, changes[0].Before.Pauses))
+	}
+	want = []habit.Pause{{From: from, Until: habit.Now()}}
+	if !cmp.Equal(want, changes[0].After.Pauses) {
+		t.Error(cmp.Diff(want, changes[0].After.Pauses))
+	}
+}
+
+func TestTracker_PauseReturnsErrorForInvalidInput(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-10T13:00:00Z")
+	store, err := habit.OpenStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{Name: "programming"})
+	store.Add(habit.Habit{Name: "reading", Pauses: []habit.Pause{{From: habit.Now().Add(-time.Hour)}}})
+	tracker, err := habit.NewTracker(habit.WithStore(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Pause("writing", time.Time{})
+	if err == nil {
+		t.Error("expected an error pausing a habit that does not exist")
+	}
+	err = tracker.Pause("reading", time.Time{})
+	if err == nil {
+		t.Error("expected an error pausing a habit that is already paused")
+	}
+	err = tracker.Pause("programming", habit.Now().Add(-time.Hour))
+	if err == nil {
+		t.Error("expected an error pausing a habit until a time in the past")
+	}
+}
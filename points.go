@@ -0,0 +1,135 @@
+package habit
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// PointsPerCompletion is the number of points a completion earns before its
+// streak multiplier is applied.
+const PointsPerCompletion = 10
+
+// levelStep is the number of points that each level takes more than the level
+// before, so that level 2 takes 100 points, level 3 another 200 and so on.
+const levelStep = 100
+
+// A Score is the points earned by tracking Habits and the level they add up
+// to.
+type Score struct {
+	// Points is the total number of points earned by all habits, archived or
+	// not.
+	Points int `json:"points"`
+	// Level is the level the points add up to, starting at 1.
+	Level int `json:"level"`
+	// NextLevel is the number of points still needed to reach the next level.
+	NextLevel int `json:"next_level"`
+	// Habits are the habits that have earned points, sorted by points, most
+	// first.
+	Habits []HabitPoints `json:"habits"`
+}
+
+// HabitPoints are the points earned by a Habit.
+type HabitPoints struct {
+	// Name is the name of the habit.
+	Name string `json:"name"`
+	// Points is the number of points the habit has earned.
+	Points int `json:"points"`
+}
+
+// WithGamification returns an option that makes a Tracker award points each
+// time a Habit is done in a new period, multiplied for long streaks, and level
+// up as the points add up. The points are kept with each Habit, and
+// EventPointsAwarded and EventLevelUp are emitted when they are earned.
+func WithGamification() option {
+	return func(t *Tracker) error {
+		t.gamification = true
+		return nil
+	}
+}
+
+// Score returns the points earned by the Tracker's Habits and the level they
+// add up to.
+func (t *Tracker) Score() Score {
+	s := Score{Habits: []HabitPoints{}}
+	for _, hbt := range t.store.All() {
+		if hbt.Points == 0 {
+			continue
+		}
+		s.Points += hbt.Points
+		s.Habits = append(s.Habits, HabitPoints{Name: hbt.Name, Points: hbt.Points})
+	}
+	sort.Slice(s.Habits, func(i, j int) bool {
+		if s.Habits[i].Points != s.Habits[j].Points {
+			return s.Habits[i].Points > s.Habits[j].Points
+		}
+		return s.Habits[i].Name < s.Habits[j].Name
+	})
+	s.Level = levelOf(s.Points)
+	s.NextLevel = levelPoints(s.Level+1) - s.Points
+	return s
+}
+
+// PrintScore writes the Tracker's Score to its output: the level and points,
+// followed by the points of each Habit that has earned any, most first.
+func (t *Tracker) PrintScore() {
+	if !t.gamification {
+		fmt.Fprintln(t.output, "Points and levels are turned off. Set gamification = true in the config file to earn them.")
+		return
+	}
+	s := t.Score()
+	fmt.Fprintf(t.output, "Level %d: %d points, %d more to reach level %d.\n", s.Level, s.Points, s.NextLevel, s.Level+1)
+	for _, hp := range s.Habits {
+		fmt.Fprintf(t.output, "  '%s': %d points\n", hp.Name, hp.Points)
+	}
+}
+
+// award adds the points earned by the given Habit's completion at the given
+// timestamp, which reached its current streak, to the Habit, and appends the
+// messages and events announcing them to the given trackResult.
+func (t *Tracker) award(hbt *Habit, at time.Time, res *trackResult) {
+	before := t.Score().Points
+	points := completionPoints(hbt.CurrentStreak)
+	hbt.Points += points
+	data := t.messageData(*hbt)
+	data.Points = points
+	res.message += " " + t.text("points_awarded", data)
+	res.summary += ", " + t.text("tracked_points", data)
+	res.events = append(res.events, Event{Type: EventPointsAwarded, Habit: hbt.Name, Streak: hbt.CurrentStreak,
+		Points: points, Frequency: hbt.Frequency, At: at})
+	level := levelOf(before + points)
+	if level > levelOf(before) {
+		data.Level = level
+		res.message += " " + t.text("level_up", data)
+		res.events = append(res.events, Event{Type: EventLevelUp, Habit: hbt.Name, Level: level,
+			Frequency: hbt.Frequency, At: at})
+	}
+}
+
+// completionPoints returns the points earned by a completion that reached the
+// given streak: PointsPerCompletion, plus half as much again for each streak
+// milestone the streak has reached, so that a 7-day streak earns 15 points
+// per completion and a 30-day streak 20.
+func completionPoints(streak int) int {
+	reached := 0
+	for _, m := range streakMilestones {
+		if streak >= m {
+			reached++
+		}
+	}
+	return PointsPerCompletion * (2 + reached) / 2
+}
+
+// levelOf returns the level that the given number of points adds up to.
+func levelOf(points int) int {
+	level := 1
+	for levelPoints(level+1) <= points {
+		level++
+	}
+	return level
+}
+
+// levelPoints returns the number of points needed to reach the given level.
+func levelPoints(level int) int {
+	return levelStep * level * (level - 1) / 2
+}
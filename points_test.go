@@ -0,0 +1,124 @@
+package habit_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTracker_TrackAwardsPointsWithStreakMultiplierAndLevelsUp(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T09:00:00Z")
+	lastDone := time.Date(2024, time.February, 5, 13, 0, 0, 0, time.UTC)
+	store := &memStore{habits: map[string]habit.Habit{
+		"programming": {Name: "programming", CurrentStreak: 6, LongestStreak: 6, LastDone: lastDone, Points: 60},
+		"reading":     {Name: "reading", CurrentStreak: 1, LongestStreak: 1, LastDone: lastDone, Points: 20},
+	}}
+	output := new(bytes.Buffer)
+	var got []habit.Event
+	tracker, err := habit.NewTracker(
+		habit.WithStore(store),
+		habit.WithOutput(output),
+		habit.WithGamification(),
+		habit.WithEventHandler(func(e habit.Event) error {
+			got = append(got, e)
+			return nil
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"reading", "programming", "programming"} {
+		err = tracker.Track(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The 7-day streak of 'programming' earns half as many points again,
+	// which brings the total to the 100 points of level 2. Doing it again
+	// the same day earns nothing.
+	want := []habit.Event{
+		{Type: habit.EventPointsAwarded, Habit: "reading", Streak: 2, Points: 10, At: habit.Now()},
+		{Type: habit.EventStreakMilestone, Habit: "programming", Streak: 7, At: habit.Now()},
+		{Type: habit.EventPointsAwarded, Habit: "programming", Streak: 7, Points: 15, At: habit.Now()},
+		{Type: habit.EventLevelUp, Habit: "programming", Level: 2, At: habit.Now()},
+	}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+	if got := store.habits["programming"].Points; got != 75 {
+		t.Errorf("want 'programming' to have 75 points, got %d", got)
+	}
+	wantOutput := "Nice work: you've done the habit 'reading' for 2 days in a row now. +10 points.\n" +
+		"Nice work: you've done the habit 'programming' for 7 days in a row now. " +
+		"That's a streak milestone: you've earned the 7-day badge! +15 points. You've reached level 2!\n" +
+		"Way to go practicing your habit 'programming' more than once today!\n"
+	if got := output.String(); wantOutput != got {
+		t.Error(cmp.Diff(wantOutput, got))
+	}
+}
+
+func TestTracker_TrackAwardsNoPointsWithoutGamification(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T09:00:00Z")
+	store := &memStore{habits: map[string]habit.Habit{}}
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(new(bytes.Buffer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Track("running")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := store.habits["running"].Points; got != 0 {
+		t.Errorf("want no points, got %d", got)
+	}
+}
+
+func TestTracker_UndoTakesBackPoints(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T09:00:00Z")
+	lastDone := time.Date(2024, time.February, 5, 13, 0, 0, 0, time.UTC)
+	store := &memStore{habits: map[string]habit.Habit{
+		"reading": {
+			Name: "reading", CurrentStreak: 1, LongestStreak: 1, LastDone: lastDone,
+			History: []habit.Completion{{At: lastDone}}, Points: 10,
+		},
+	}}
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(new(bytes.Buffer)), habit.WithGamification())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Track("reading")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Undo("reading")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := store.habits["reading"].Points; got != 10 {
+		t.Errorf("want 10 points after undo, got %d", got)
+	}
+}
+
+func TestTracker_PrintScoreShowsLevelAndPointsByHabit(t *testing.T) {
+	t.Parallel()
+	store := &memStore{habits: map[string]habit.Habit{
+		"meditation":  {Name: "meditation", Points: 120},
+		"programming": {Name: "programming", Points: 245, Archived: true},
+		"reading":     {Name: "reading"},
+	}}
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output), habit.WithGamification())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker.PrintScore()
+	want := "Level 3: 365 points, 235 more to reach level 4.\n" +
+		"  'programming': 245 points\n" +
+		"  'meditation': 120 points\n"
+	if got := output.String(); want != got {
+		t.Error(cmp.Diff(want, got))
+	}
+}
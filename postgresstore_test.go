@@ -0,0 +1,153 @@
+package habit_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+// openTestPostgresDB opens the PostgreSQL database named by the
+// HABIT_TEST_POSTGRES_DSN environment variable, skipping the test if it is not
+// set.
+func openTestPostgresDB(t *testing.T) *habit.PostgresDB {
+	t.Helper()
+	dsn := os.Getenv("HABIT_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("HABIT_TEST_POSTGRES_DSN is not set")
+	}
+	db, err := habit.OpenPostgresDB(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// testPostgresUser returns a user name unique to the test and run, so that
+// tests sharing a database do not see each other's habits.
+func testPostgresUser(t *testing.T, name string) string {
+	return t.Name() + "/" + name + "/" + time.Now().Format(time.RFC3339Nano)
+}
+
+func TestPostgresStore_SaveSavesStorePersistently(t *testing.T) {
+	t.Parallel()
+	db := openTestPostgresDB(t)
+	user := testPostgresUser(t, "alice")
+	store, err := db.Store(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := time.Date(2024, time.February, 5, 20, 0, 0, 0, time.UTC)
+	store.Add(habit.Habit{Name: "habit1", CurrentStreak: 2, LongestStreak: 2, LastDone: done,
+		History: []habit.Completion{{At: done.Add(-24 * time.Hour)}, {At: done, Note: "read a chapter"}, {At: done}}})
+	store.Add(habit.Habit{Name: "habit2", Tags: []string{"health"}})
+	store.Add(habit.Habit{Name: "habit3"})
+	store.Delete("habit3")
+	err = store.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	store2, err := db.Store(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []habit.Habit{
+		{Name: "habit1", CurrentStreak: 2, LongestStreak: 2, LastDone: done,
+			History: []habit.Completion{{At: done.Add(-24 * time.Hour)}, {At: done, Note: "read a chapter"}, {At: done}}},
+		{Name: "habit2", Tags: []string{"health"}},
+	}
+	got := store2.All()
+	if !cmp.Equal(want, got, habitSliceCmpOpt) {
+		t.Error(cmp.Diff(want, got, habitSliceCmpOpt))
+	}
+}
+
+func TestPostgresStore_SaveRewritesChangedHistory(t *testing.T) {
+	t.Parallel()
+	db := openTestPostgresDB(t)
+	store, err := db.Store(testPostgresUser(t, "alice"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := time.Date(2024, time.February, 5, 20, 0, 0, 0, time.UTC)
+	store.Add(habit.Habit{Name: "habit1", History: []habit.Completion{{At: done}, {At: done.Add(time.Hour)}, {At: done.Add(2 * time.Hour)}}})
+	err = store.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := habit.Habit{Name: "habit1", History: []habit.Completion{
+		{At: done.Add(-24 * time.Hour), Note: "backdated"},
+		{At: done, Note: "edited", Frozen: true},
+	}}
+	store.Add(want)
+	err = store.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, ok := store.Get("habit1")
+	if !ok {
+		t.Fatal("expected ok to be true when getting saved habit")
+	}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestPostgresStore_KeepsUsersHabitsApart(t *testing.T) {
+	t.Parallel()
+	db := openTestPostgresDB(t)
+	alice, err := db.Store(testPostgresUser(t, "alice"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob, err := db.Store(testPostgresUser(t, "bob"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice.Add(habit.Habit{Name: "reading", CurrentStreak: 3})
+	err = alice.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob.Add(habit.Habit{Name: "reading", CurrentStreak: 1})
+	bob.Add(habit.Habit{Name: "running"})
+	err = bob.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob.Delete("reading")
+	err = bob.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []habit.Habit{{Name: "reading", CurrentStreak: 3}}
+	got := alice.All()
+	if !cmp.Equal(want, got, habitSliceCmpOpt) {
+		t.Error(cmp.Diff(want, got, habitSliceCmpOpt))
+	}
+	want = []habit.Habit{{Name: "running"}}
+	got = bob.All()
+	if !cmp.Equal(want, got, habitSliceCmpOpt) {
+		t.Error(cmp.Diff(want, got, habitSliceCmpOpt))
+	}
+}
+
+func TestPostgresDB_StoreReturnsErrorGivenEmptyUser(t *testing.T) {
+	t.Parallel()
+	db := openTestPostgresDB(t)
+	_, err := db.Store("")
+	if err == nil {
+		t.Error("expected an error for an empty user name but did not get one")
+	}
+}
+
+func TestOpenPostgresDBReturnsErrorGivenUnreachableServer(t *testing.T) {
+	t.Parallel()
+	_, err := habit.OpenPostgresDB("postgres://habit@127.0.0.1:1/habit?connect_timeout=1")
+	if err == nil {
+		t.Error("expected an error for an unreachable server but did not get one")
+	}
+}
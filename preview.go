@@ -0,0 +1,328 @@
+package habit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// A Change describes how a single Habit was changed.
+type Change struct {
+	// Habit is the name of the changed habit.
+	Habit string `json:"habit"`
+	// Before is the habit before the change. It is nil if the change created
+	// the habit.
+	Before *Habit `json:"before,omitempty"`
+	// After is the habit after the change. It is nil if the change deleted the
+	// habit.
+	After *Habit `json:"after,omitempty"`
+}
+
+// A changeSet records the Habits of a Store that have been added or deleted,
+// as they were before their first change, so that the net Changes can be
+// determined.
+type changeSet struct {
+	// before holds the changed Habits as they were before their first
+	// change, or nil for Habits that did not exist.
+	before map[string]*Habit
+}
+
+// A baseliner is a Store that records Changes and accepts an explicit
+// baseline for a Habit, such as its data before being migrated.
+type baseliner interface {
+	// setBaseline records the given Habit, or nil if it did not exist, as the
+	// Habit with the given name before any change.
+	setBaseline(name string, before *Habit)
+}
+
+// touch records the Habit with the given name, as found in the given Store,
+// unless it was already changed.
+func (c *changeSet) touch(s Store, name string) {
+	if _, ok := c.before[name]; ok {
+		return
+	}
+	if c.before == nil {
+		c.before = map[string]*Habit{}
+	}
+	c.before[name] = nil
+	if hbt, ok := s.Get(name); ok {
+		c.before[name] = &hbt
+	}
+}
+
+// setBaseline records the given Habit as the Habit with the given name before
+// any change, replacing any earlier record.
+func (c *changeSet) setBaseline(name string, before *Habit) {
+	if c.before == nil {
+		c.before = map[string]*Habit{}
+	}
+	c.before[name] = before
+}
+
+// changes returns the Changes between the recorded Habits and the Habits now
+// in the given Store, sorted by habit name. Habits that ended up as they were
+// are left out.
+func (c *changeSet) changes(s Store) []Change {
+	names := make([]string, 0, len(c.before))
+	for name := range c.before {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var changes []Change
+	for _, name := range names {
+		ch := Change{Habit: name, Before: c.before[name]}
+		if hbt, ok := s.Get(name); ok {
+			ch.After = &hbt
+		}
+		switch {
+		case ch.Before == nil && ch.After == nil:
+			continue
+		case ch.Before != nil && ch.After != nil && sameHabit(*ch.Before, *ch.After):
+			continue
+		}
+		changes = append(changes, ch)
+	}
+	return changes
+}
+
+// reset forgets every recorded Habit.
+func (c *changeSet) reset() {
+	c.before = nil
+}
+
+// A Preview is a Store that applies changes to the Habits of another Store in
+// memory without ever saving them, so that the changes a command would make
+// can be shown with WriteChanges instead of being made.
+type Preview struct {
+	Store
+	changeSet
+}
+
+// NewPreview returns a Preview of changes to the given Store.
+func NewPreview(s Store) *Preview {
+	return &Preview{Store: s}
+}
+
+// Add adds or updates the given habit in memory.
+func (p *Preview) Add(h Habit) {
+	p.touch(p.Store, h.Name)
+	p.Store.Add(h)
+}
+
+// Delete deletes the habit with the given name in memory.
+func (p *Preview) Delete(name string) {
+	p.touch(p.Store, name)
+	p.Store.Delete(name)
+}
+
+// Save does nothing, leaving the underlying store as it was.
+func (p *Preview) Save() error {
+	return nil
+}
+
+// SaveContext does nothing, like Save.
+func (p *Preview) SaveContext(context.Context) error {
+	return nil
+}
+
+// Changes returns the Changes made to the Preview's Habits since it was
+// created, sorted by habit name.
+func (p *Preview) Changes() []Change {
+	return p.changes(p.Store)
+}
+
+// Reload reloads the underlying store, if it supports reloading, discarding
+// the changes made to the Preview.
+func (p *Preview) Reload() error {
+	return p.LoadContext(context.Background())
+}
+
+// LoadContext reloads the underlying store under the given context like
+// Reload.
+func (p *Preview) LoadContext(ctx context.Context) error {
+	p.reset()
+	return LoadContext(ctx, p.Store)
+}
+
+// lockChange takes the lock of the underlying store for a change and reloads
+// it, if it is only locked while it changes, discarding the changes made to
+// the Preview, so that a dry run of a long-running command previews each
+// change against the habits other habit processes saved.
+func (p *Preview) lockChange() (func(), error) {
+	p.reset()
+	return lockChange(p.Store)
+}
+
+// unmigrated returns the data in the underlying store's file before any
+// migrations, if the underlying store has a file.
+func (p *Preview) unmigrated() (int, map[string]Habit, error) {
+	return unmigrated(p.Store)
+}
+
+// unencrypted reports whether the underlying store is encrypted but its file
+// is not yet.
+func (p *Preview) unencrypted() bool {
+	return unencrypted(p.Store)
+}
+
+// syncCodec returns the codec of the habit data the underlying store syncs
+// with a remote.
+func (p *Preview) syncCodec() codec {
+	return syncCodec(p.Store)
+}
+
+// damaged returns the data that the underlying store could not decode, if it
+// was opened with WithSalvage. It is never quarantined, since a Preview writes
+// nothing.
+func (p *Preview) damaged() []damagedRecord {
+	return damagedRecords(p.Store)
+}
+
+// backups returns the backups of the underlying store's file, if it keeps
+// any.
+func (p *Preview) backups() ([]Backup, error) {
+	b, ok := p.Store.(backupStore)
+	if !ok {
+		return nil, errNoBackups
+	}
+	return b.backups()
+}
+
+// backupHabits returns the habits of the underlying store's backup with the
+// given ID.
+func (p *Preview) backupHabits(id string) (map[string]Habit, error) {
+	b, ok := p.Store.(backupStore)
+	if !ok {
+		return nil, errNoBackups
+	}
+	return b.backupHabits(id)
+}
+
+// Close closes the underlying store, if it needs closing.
+func (p *Preview) Close() error {
+	if c, ok := p.Store.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// unmigrated returns the schema version and Habits of the given Store's data
+// as it was written, before any migrations. An error is returned if the Store
+// has no such data, such as a SQLite or remote store.
+func unmigrated(s Store) (int, map[string]Habit, error) {
+	u, ok := s.(interface {
+		unmigrated() (int, map[string]Habit, error)
+	})
+	if !ok {
+		return 0, nil, fmt.Errorf("this store is migrated automatically")
+	}
+	return u.unmigrated()
+}
+
+// diffContext is the number of unchanged lines shown around each change by
+// WriteChanges.
+const diffContext = 2
+
+// WriteChanges writes the given Changes to w as a diff of each Habit's JSON
+// encoding, with removed lines prefixed by "-" and added lines by "+", like
+// the output of diff -u. An error is returned if the Changes cannot be
+// written.
+func WriteChanges(w io.Writer, changes []Change) error {
+	bw := bufio.NewWriter(w)
+	for _, ch := range changes {
+		switch {
+		case ch.Before == nil:
+			fmt.Fprintf(bw, "--- /dev/null\n+++ %s\n", ch.Habit)
+		case ch.After == nil:
+			fmt.Fprintf(bw, "--- %s\n+++ /dev/null\n", ch.Habit)
+		default:
+			fmt.Fprintf(bw, "--- %s\n+++ %s\n", ch.Habit, ch.Habit)
+		}
+		before, err := habitLines(ch.Before)
+		if err != nil {
+			return err
+		}
+		after, err := habitLines(ch.After)
+		if err != nil {
+			return err
+		}
+		writeDiff(bw, before, after)
+	}
+	return bw.Flush()
+}
+
+// habitLines returns the lines of the indented JSON encoding of the given
+// Habit, or no lines if it is nil.
+func habitLines(hbt *Habit) ([]string, error) {
+	if hbt == nil {
+		return nil, nil
+	}
+	data, err := json.MarshalIndent(hbt, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return strings.Split(string(data), "\n"), nil
+}
+
+// writeDiff writes the differences between the lines a and b to w, keeping
+// diffContext unchanged lines around each change and separating the changes
+// with "@@" lines.
+func writeDiff(w io.Writer, a, b []string) {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and
+	// b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	var lines []string
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, " "+a[i])
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, "-"+a[i])
+			i++
+		default:
+			lines = append(lines, "+"+b[j])
+			j++
+		}
+	}
+	last := -1
+	for k, line := range lines {
+		if !changedNear(lines, k) {
+			continue
+		}
+		if last >= 0 && k > last+1 {
+			fmt.Fprintln(w, "@@")
+		}
+		fmt.Fprintln(w, line)
+		last = k
+	}
+}
+
+// changedNear reports whether any of the diff lines within diffContext lines
+// of the k-th one is a change.
+func changedNear(lines []string, k int) bool {
+	for i := max(0, k-diffContext); i <= min(len(lines)-1, k+diffContext); i++ {
+		if lines[i][0] != ' ' {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,163 @@
+package habit_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPreview_RecordsChangesWithoutSaving(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T09:00:00Z")
+	reading := habit.Habit{Name: "reading", CurrentStreak: 1, LongestStreak: 1}
+	inner := &memStore{habits: map[string]habit.Habit{"reading": reading}}
+	preview := habit.NewPreview(inner)
+	tracker, err := habit.NewTracker(habit.WithStore(preview), habit.WithOutput(new(bytes.Buffer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Track("programming")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Delete("reading")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inner.saves != 0 {
+		t.Errorf("want no saves of the underlying store, got %d", inner.saves)
+	}
+	changes := preview.Changes()
+	if len(changes) != 2 {
+		t.Fatalf("want 2 changes, got %d: %+v", len(changes), changes)
+	}
+	if changes[0].Habit != "programming" || changes[0].Before != nil || changes[0].After == nil {
+		t.Errorf("want 'programming' created, got %+v", changes[0])
+	}
+	if changes[1].Habit != "reading" || !cmp.Equal(&reading, changes[1].Before) || changes[1].After != nil {
+		t.Errorf("want 'reading' deleted, got %+v", changes[1])
+	}
+}
+
+func TestPreview_LeavesOutHabitsChangedBack(t *testing.T) {
+	t.Parallel()
+	reading := habit.Habit{Name: "reading", Tags: []string{"books"}}
+	preview := habit.NewPreview(&memStore{habits: map[string]habit.Habit{"reading": reading}})
+	preview.Add(habit.Habit{Name: "reading"})
+	preview.Add(reading)
+	preview.Add(habit.Habit{Name: "programming"})
+	preview.Delete("programming")
+	if changes := preview.Changes(); len(changes) != 0 {
+		t.Errorf("want no changes, got %+v", changes)
+	}
+}
+
+func TestWriteChanges_WritesDiffOfEachHabit(t *testing.T) {
+	t.Parallel()
+	day := time.Date(2024, time.February, 6, 9, 0, 0, 0, time.UTC)
+	before := habit.Habit{
+		Name: "reading", CurrentStreak: 1, LongestStreak: 1, LastDone: day,
+		History: []habit.Completion{{At: day}}, AmountAt: day,
+	}
+	after := before
+	after.CurrentStreak, after.LongestStreak, after.LastDone = 2, 2, day.AddDate(0, 0, 1)
+	after.History = append(after.History, habit.Completion{At: after.LastDone})
+	buf := new(bytes.Buffer)
+	err := habit.WriteChanges(buf, []habit.Change{
+		{Habit: "programming", Before: &habit.Habit{Name: "programming", LastDone: day, AmountAt: day}},
+		{Habit: "reading", Before: &before, After: &after},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `--- programming
++++ /dev/null
+-{
+-  "name": "programming",
+-  "current_streak": 0,
+-  "longest_streak": 0,
+-  "last_done": "2024-02-06T09:00:00Z",
+-  "amount_at": "2024-02-06T09:00:00Z"
+-}
+--- reading
++++ reading
+ {
+   "name": "reading",
+-  "current_streak": 1,
+-  "longest_streak": 1,
+-  "last_done": "2024-02-06T09:00:00Z",
++  "current_streak": 2,
++  "longest_streak": 2,
++  "last_done": "2024-02-07T09:00:00Z",
+   "history": [
+     {
+       "at": "2024-02-06T09:00:00Z"
++    },
++    {
++      "at": "2024-02-07T09:00:00Z"
+     }
+   ],
+`
+	if got := buf.String(); want != got {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestPreview_TakesLockOfStoreLockedPerChange(t *testing.T) {
+	t.Parallel()
+	path := t.TempDir() + "/test.store"
+	store, err := habit.OpenStore(path, habit.WithLockPerChange(100*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	preview := habit.NewPreview(store)
+	tracker, err := habit.NewTracker(habit.WithStore(preview), habit.WithOutput(new(bytes.Buffer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(habit.NewServer(tracker))
+	defer srv.Close()
+	track := func(name string) int {
+		t.Helper()
+		resp, err := http.Post(srv.URL+"/habits/"+name+"/track", "application/json", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	other, err := habit.OpenStore(path, habit.WithLock(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := track("reading"); got != http.StatusServiceUnavailable {
+		t.Errorf("want status %d while another process holds the lock, got %d", http.StatusServiceUnavailable, got)
+	}
+	other.Add(habit.Habit{Name: "cycling"})
+	err = other.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other.Close()
+	if got := track("reading"); got != http.StatusOK {
+		t.Fatalf("want status %d, got %d", http.StatusOK, got)
+	}
+	if _, ok := preview.Get("cycling"); !ok {
+		t.Error("want habit saved by another process reloaded before the change")
+	}
+	changes := preview.Changes()
+	if len(changes) != 1 || changes[0].Habit != "reading" {
+		t.Errorf("want only 'reading' changed, got %+v", changes)
+	}
+	reopened, err := habit.OpenStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := reopened.Get("reading"); ok {
+		t.Error("want previewed change not saved")
+	}
+}
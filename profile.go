@@ -0,0 +1,124 @@
+package habit
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// profileEnv is the environment variable naming the profile to use when the
+// -profile flag is not given.
+const profileEnv = "HABIT_PROFILE"
+
+// profileStoreExt is the extension of the store files of the profiles created
+// with CreateProfile.
+const profileStoreExt = ".store"
+
+// ProfileDir returns the directory holding the store files of the profiles
+// created with CreateProfile, which is the profiles directory within the habit
+// directory of the user's config directory, such as ~/.config/habit/profiles
+// on Linux.
+func ProfileDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "habit", "profiles"), nil
+}
+
+// validateProfileName returns an error unless the given name can name a
+// profile, whose store file is named after it.
+func validateProfileName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("invalid profile name %q", name)
+	}
+	return nil
+}
+
+// CreateProfile creates the profile with the given name, such as "work", with
+// an empty store file of its own in ProfileDir, and returns the path of the
+// store file. An error is returned if the name is invalid, the profile
+// already exists, or its store file cannot be created.
+func CreateProfile(name string) (string, error) {
+	err := validateProfileName(name)
+	if err != nil {
+		return "", err
+	}
+	dir, err := ProfileDir()
+	if err != nil {
+		return "", fmt.Errorf("error finding profile directory: %w", err)
+	}
+	path := filepath.Join(dir, name+profileStoreExt)
+	if _, err := os.Stat(path); err == nil {
+		return "", fmt.Errorf("profile '%s' already exists", name)
+	}
+	err = os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return "", fmt.Errorf("error creating profile directory: %w", err)
+	}
+	s, err := OpenStore(path)
+	if err != nil {
+		return "", err
+	}
+	defer s.Close()
+	err = s.Save()
+	if err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// ProfileStore returns the path or URL of the store of the profile with the
+// given name: the store given for the profile in the Config's profiles, if
+// any, and otherwise the store file of the profile created with
+// CreateProfile. An error is returned if there is no such profile.
+func (c Config) ProfileStore(name string) (string, error) {
+	if p, ok := c.Profiles[name]; ok {
+		return p.Store, nil
+	}
+	err := validateProfileName(name)
+	if err != nil {
+		return "", err
+	}
+	dir, err := ProfileDir()
+	if err != nil {
+		return "", fmt.Errorf("error finding profile directory: %w", err)
+	}
+	path := filepath.Join(dir, name+profileStoreExt)
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("unknown profile '%s'; create it with 'habit profile create %s'", name, name)
+	}
+	return path, nil
+}
+
+// ProfileNames returns the names of the profiles given in the Config's
+// profiles and of those created with CreateProfile, in sorted order.
+func (c Config) ProfileNames() ([]string, error) {
+	seen := map[string]bool{}
+	var names []string
+	for name := range c.Profiles {
+		seen[name] = true
+		names = append(names, name)
+	}
+	dir, err := ProfileDir()
+	if err != nil {
+		return nil, fmt.Errorf("error finding profile directory: %w", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("error reading profile directory: %w", err)
+	}
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), profileStoreExt)
+		if !ok || e.IsDir() || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
@@ -0,0 +1,70 @@
+package habit_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCreateProfile_CreatesStoreFoundByProfileStore(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	path, err := habit.CreateProfile("work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := habit.OpenStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Close()
+	got, err := habit.Config{}.ProfileStore("work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != got {
+		t.Errorf("want store %q, got %q", path, got)
+	}
+	_, err = habit.CreateProfile("work")
+	if err == nil {
+		t.Error("want error creating existing profile, got nil")
+	}
+}
+
+func TestConfig_ProfileStorePrefersConfiguredProfiles(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	_, err := habit.CreateProfile("work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := habit.Config{Profiles: map[string]habit.ProfileConfig{
+		"work": {Store: "postgres://habit@db/work"},
+		"team": {Store: "redis://redis:6379/0"},
+	}}
+	got, err := cfg.ProfileStore("work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "postgres://habit@db/work" {
+		t.Errorf("want configured store, got %q", got)
+	}
+	names, err := cfg.ProfileNames()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"team", "work"}
+	if !cmp.Equal(want, names) {
+		t.Error(cmp.Diff(want, names))
+	}
+}
+
+func TestConfig_ProfileStoreReturnsErrorGivenUnknownOrInvalidProfile(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	for _, name := range []string{"missing", "", "..", filepath.Join("a", "b")} {
+		_, err := habit.Config{}.ProfileStore(name)
+		if err == nil {
+			t.Errorf("%q: want error, got nil", name)
+		}
+	}
+}
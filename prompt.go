@@ -0,0 +1,28 @@
+package habit
+
+import "fmt"
+
+// Prompt returns a terse, single-line summary of how many tracked Habits have
+// been done today out of the total number of tracked Habits, such as
+// "habits: 4/6 ✓". Like Due, it leaves out Habits that are paused, off their
+// schedule today or Habits to avoid. The summary has no
+// trailing newline so that it can be embedded in a shell prompt, and is only
+// colorized if the Tracker was created with color enabled.
+func (t *Tracker) Prompt() string {
+	now := t.now()
+	total, done := 0, 0
+	for _, hbt := range t.sortedHabits(false) {
+		if !t.expected(hbt, now) {
+			continue
+		}
+		total++
+		if hbt.doneThisPeriod(now, t.calendar) {
+			done++
+		}
+	}
+	color := colorYellow
+	if done == total {
+		color = colorGreen
+	}
+	return t.colorize(color, fmt.Sprintf("habits: %d/%d ✓", done, total))
+}
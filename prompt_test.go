@@ -0,0 +1,80 @@
+package habit_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+)
+
+func TestTracker_PromptReturnsDoneOverTotalForSeededStore(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T12:00:00Z")
+	yesterday, err := time.Parse(time.RFC3339, "2024-02-05T09:00:00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := habit.OpenStore(t.TempDir() + "/test.store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{Name: "programming", LastDone: habit.Now()})
+	store.Add(habit.Habit{Name: "reading", LastDone: habit.Now()})
+	store.Add(habit.Habit{Name: "running", LastDone: yesterday})
+	// February 6 is a Tuesday, a rest day for the gym, and meditation is
+	// paused, so neither counts, as neither is due.
+	store.Add(habit.Habit{Name: "gym", LastDone: yesterday, Weekdays: []time.Weekday{time.Monday}})
+	store.Add(habit.Habit{Name: "meditation", LastDone: yesterday, Pauses: []habit.Pause{{From: yesterday}}})
+	tracker, err := habit.NewTracker(habit.WithStore(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "habits: 2/3 ✓"
+	got := tracker.Prompt()
+	if want != got {
+		t.Errorf("want prompt %q, got prompt %q", want, got)
+	}
+}
+
+func TestTracker_PromptReturnsZeroOverZeroForEmptyStore(t *testing.T) {
+	t.Parallel()
+	store, err := habit.OpenStore(t.TempDir() + "/test.store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker, err := habit.NewTracker(habit.WithStore(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "habits: 0/0 ✓"
+	got := tracker.Prompt()
+	if want != got {
+		t.Errorf("want prompt %q, got prompt %q", want, got)
+	}
+}
+
+func TestTracker_PromptCountsHabitsDoneTodayInTrackerLocation(t *testing.T) {
+	// 20:00 UTC on February 5 is February 6 in Tokyo, as is the current time.
+	habit.Now = getTimeFunc(t, "2024-02-06T03:00:00Z")
+	lastDone, err := time.Parse(time.RFC3339, "2024-02-05T20:00:00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := habit.OpenStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{Name: "programming", LastDone: lastDone})
+	for loc, want := range map[*time.Location]string{
+		time.UTC:                       "habits: 0/1 ✓",
+		time.FixedZone("JST", 9*60*60): "habits: 1/1 ✓",
+	} {
+		tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithLocation(loc))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := tracker.Prompt()
+		if want != got {
+			t.Errorf("%s: want prompt %q, got %q", loc, want, got)
+		}
+	}
+}
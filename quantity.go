@@ -0,0 +1,108 @@
+package habit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// SetTarget makes the Habit with the given name a quantity habit, such as
+// drinking 8 glasses of water, that is done once the amounts logged with
+// LogAmount within one of its periods add up to the given target, measured in
+// the given unit. The Habit is added to the store if it does not exist yet,
+// and the store is saved. An error is returned if the target is not a positive
+// amount or the store cannot be saved.
+func (t *Tracker) SetTarget(hbtName string, target float64, unit string) error {
+	if !positiveAmount(target) {
+		return errors.New("the target must be a positive amount")
+	}
+	hbt, ok := t.store.Get(hbtName)
+	if !ok {
+		hbt = Habit{Name: hbtName}
+	}
+	hbt.Target = target
+	hbt.Unit = unit
+	t.store.Add(hbt)
+	err := t.save()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(t.output, "The habit '%s' now has a target of %s %s.\n",
+		hbtName, formatAmount(target, unit), hbt.Frequency.current())
+	return nil
+}
+
+// LogAmount adds the given amount to the amount of the quantity Habit with the
+// given name logged in its current period and saves the store. When the
+// amount logged reaches the Habit's target, the Habit is tracked as done,
+// extending its streak. An error is returned if the Habit does not exist or
+// has no target, if the amount is not a positive amount, or if the store
+// cannot be saved.
+func (t *Tracker) LogAmount(hbtName string, amount float64) error {
+	return t.logAmount(hbtName, amount, "")
+}
+
+// positiveAmount reports whether the given value is a positive amount, which
+// rules out NaN and infinity since they cannot be added up or stored as JSON.
+func positiveAmount(v float64) bool {
+	return v > 0 && !math.IsNaN(v) && !math.IsInf(v, 0)
+}
+
+// logAmount logs the given amount of the quantity Habit with the given name
+// like LogAmount, recording the given idempotency key, if any, with the amount
+// and with the completion if the amount reaches the Habit's target.
+func (t *Tracker) logAmount(hbtName string, amount float64, id string) error {
+	hbt, ok := t.store.Get(hbtName)
+	if !ok {
+		return errHabitNotFound(hbtName)
+	}
+	if hbt.Target <= 0 {
+		return fmt.Errorf("habit '%s' has no target; set one with 'habit target'", hbtName)
+	}
+	if !positiveAmount(amount) {
+		return errors.New("the amount must be positive")
+	}
+	now := t.now()
+	before := hbt.amountThisPeriod(now, t.calendar)
+	hbt.Amount = before + amount
+	hbt.AmountAt = now
+	if before == 0 {
+		// The keys of the amounts logged in earlier periods are forgotten.
+		hbt.AmountIDs = nil
+	}
+	if id != "" {
+		hbt.AmountIDs = append(hbt.AmountIDs, id)
+	}
+	t.store.Add(hbt)
+	fmt.Fprintf(t.output, "Logged %s of '%s': %s of %s %s.\n",
+		formatAmount(amount, hbt.Unit), hbtName, formatAmount(hbt.Amount, ""),
+		formatAmount(hbt.Target, hbt.Unit), hbt.Frequency.current())
+	if before >= hbt.Target || hbt.Amount < hbt.Target {
+		return t.save()
+	}
+	fmt.Fprintln(t.output, "Target reached!")
+	return t.trackContext(context.Background(), hbtName, Completion{At: now, Amount: hbt.Amount, ID: id})
+}
+
+// amountThisPeriod returns the amount of the quantity Habit logged within the
+// period of its Frequency that contains the given timestamp, with calendar
+// dates taken from the given calendar.
+func (h Habit) amountThisPeriod(now time.Time, cal calendar) float64 {
+	if h.AmountAt.IsZero() || h.Frequency.periodIndex(h.AmountAt, cal) != h.Frequency.periodIndex(now, cal) {
+		return 0
+	}
+	return h.Amount
+}
+
+// formatAmount returns the given amount followed by the given unit, if any,
+// for use in output messages.
+func formatAmount(amount float64, unit string) string {
+	s := strconv.FormatFloat(amount, 'f', -1, 64)
+	if unit == "" {
+		return s
+	}
+	return s + " " + unit
+}
@@ -0,0 +1,127 @@
+package habit_test
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+)
+
+func TestTracker_LogAmountTracksHabitWhenTargetIsReached(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T13:00:00Z")
+	store, err := habit.OpenStore(t.TempDir() + "/test.store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.SetTarget("water", 8, "glasses")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, amount := range []float64{5, 3, 1.5} {
+		err = tracker.LogAmount("water", amount)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = tracker.PrintSummary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "The habit 'water' now has a target of 8 glasses today.\n" +
+		"Logged 5 glasses of 'water': 5 of 8 glasses today.\n" +
+		"Logged 3 glasses of 'water': 8 of 8 glasses today.\n" +
+		"Target reached!\n" +
+		"Congratulations on starting your new habit 'water'! Don't forget to do it again.\n" +
+		"Logged 1.5 glasses of 'water': 9.5 of 8 glasses today.\n" +
+		"You've logged 9.5 of 8 glasses for 'water' today. You're on a 1-day streak. Strength: 5%.\n"
+	got := output.String()
+	if want != got {
+		t.Errorf("want output %q, got output %q", want, got)
+	}
+	hbt, ok := store.Get("water")
+	if !ok {
+		t.Fatal("expected habit 'water' to be present in store")
+	}
+	if len(hbt.History) != 1 {
+		t.Fatalf("want 1 completion once the target is reached, got %d", len(hbt.History))
+	}
+	if hbt.History[0].Amount != 8 {
+		t.Errorf("want completion amount 8, got %v", hbt.History[0].Amount)
+	}
+}
+
+func TestTracker_LogAmountStartsFromZeroInNewPeriod(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T13:00:00Z")
+	store, err := habit.OpenStore(t.TempDir() + "/test.store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{
+		Name:     "reading",
+		Target:   30,
+		Unit:     "pages",
+		Amount:   25,
+		AmountAt: habit.Now().Add(-20 * time.Hour),
+	})
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.LogAmount("reading", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Logged 10 pages of 'reading': 10 of 30 pages today.\n"
+	got := output.String()
+	if want != got {
+		t.Errorf("want output %q, got output %q", want, got)
+	}
+	summaries := tracker.Summarize()
+	if len(summaries) != 1 || summaries[0].Amount != 10 {
+		t.Errorf("want summary with amount 10, got %v", summaries)
+	}
+}
+
+func TestTracker_LogAmountReturnsErrorForInvalidInput(t *testing.T) {
+	t.Parallel()
+	store, err := habit.OpenStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{Name: "running"})
+	store.Add(habit.Habit{Name: "water", Target: 8})
+	tracker, err := habit.NewTracker(habit.WithStore(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.LogAmount("swimming", 1)
+	if err == nil {
+		t.Error("expected an error logging an amount of a habit that does not exist")
+	}
+	err = tracker.LogAmount("running", 1)
+	if err == nil {
+		t.Error("expected an error logging an amount of a habit without a target")
+	}
+	for _, v := range []float64{0, -1, math.NaN(), math.Inf(1), math.Inf(-1)} {
+		err = tracker.LogAmount("water", v)
+		if err == nil {
+			t.Errorf("expected an error logging an amount of %v", v)
+		}
+		err = tracker.SetTarget("water", v, "glasses")
+		if err == nil {
+			t.Errorf("expected an error setting a target of %v", v)
+		}
+	}
+	hbt, _ := store.Get("water")
+	if hbt.Target != 8 || hbt.Amount != 0 {
+		t.Errorf("want target 8 and no amount, got target %v and amount %v", hbt.Target, hbt.Amount)
+	}
+}
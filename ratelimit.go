@@ -0,0 +1,157 @@
+package habit
+
+import (
+	"container/list"
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"sync"
+
+	"golang.org/x/time/rate"
+)
+
+// DefaultMaxBodySize is the largest request body, in bytes, that a Server
+// reads unless WithMaxBodySize is given.
+const DefaultMaxBodySize = 1 << 20
+
+// maxRateLimiters is the most clients whose rate limiters a rateLimiter keeps.
+// Beyond it, the client that sent a request least recently is forgotten, so
+// that requests from many addresses cannot use up the Server's memory.
+const maxRateLimiters = 1024
+
+// A rateLimiter limits the rate of the requests of each client of a Server
+// with a token bucket of its own, so that one misbehaving script cannot hammer
+// the store and hold up the other clients.
+type rateLimiter struct {
+	// limit is the rate at which each client's bucket refills.
+	limit rate.Limit
+	// burst is the size of each client's bucket.
+	burst int
+	// mtx guards clients and recent.
+	mtx sync.Mutex
+	// clients holds the element of recent of each client by client key.
+	clients map[string]*list.Element
+	// recent lists the clientBuckets of the clients, those that sent a
+	// request most recently first.
+	recent *list.List
+}
+
+// A clientBucket is the token bucket of a client of a rateLimiter.
+type clientBucket struct {
+	// key is the client's key.
+	key string
+	// limiter is the client's bucket.
+	limiter *rate.Limiter
+}
+
+// WithRateLimit returns a serverOption that makes a Server reject requests
+// beyond the given number of requests per second from each client, allowing
+// bursts of up to the given number of requests, with status 429 Too Many
+// Requests, or ResourceExhausted over gRPC. Each token, whether the Server's
+// or a user's, has a limit of its own, and if the Server has no token each IP
+// address does. Requests with a missing or invalid token also count against
+// a limit of their IP address, beyond which the address's requests are
+// rejected before their tokens are checked, so that tokens cannot be guessed
+// by brute force. A rate of zero or less turns rate limiting off, and a burst
+// below 1 is taken as 1.
+func WithRateLimit(perSecond float64, burst int) serverOption {
+	return func(s *Server) {
+		if perSecond <= 0 {
+			s.limiter = nil
+			return
+		}
+		s.limiter = &rateLimiter{
+			limit:   rate.Limit(perSecond),
+			burst:   max(burst, 1),
+			clients: map[string]*list.Element{},
+			recent:  list.New(),
+		}
+	}
+}
+
+// WithMaxBodySize returns a serverOption that makes a Server reject requests
+// with bodies larger than the given number of bytes with status 413 Content
+// Too Large, and gRPC messages larger than it, instead of DefaultMaxBodySize.
+// A size of zero or less allows bodies of any size.
+func WithMaxBodySize(size int64) serverOption {
+	return func(s *Server) {
+		s.maxBodySize = size
+	}
+}
+
+// allow reports whether the client with the given key may send a request
+// now, taking a token from its bucket if so.
+func (l *rateLimiter) allow(key string) bool {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	e, ok := l.clients[key]
+	if ok {
+		l.recent.MoveToFront(e)
+		return e.Value.(*clientBucket).limiter.Allow()
+	}
+	if l.recent.Len() >= maxRateLimiters {
+		oldest := l.recent.Back()
+		l.recent.Remove(oldest)
+		delete(l.clients, oldest.Value.(*clientBucket).key)
+	}
+	b := &clientBucket{key: key, limiter: rate.NewLimiter(l.limit, l.burst)}
+	l.clients[key] = l.recent.PushFront(b)
+	return b.limiter.Allow()
+}
+
+// exhausted reports whether the bucket of the client with the given key is
+// empty, without taking a token from it.
+func (l *rateLimiter) exhausted(key string) bool {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	e, ok := l.clients[key]
+	return ok && e.Value.(*clientBucket).limiter.Tokens() < 1
+}
+
+// rateKey returns the key by which the rate of the requests of the given user
+// from the given remote address is limited: the user's name if the requests
+// carry a user's token, the Server's token if they carry that, and otherwise
+// the IP address of the remote address.
+func (s *Server) rateKey(user, remoteAddr string) string {
+	if user != "" {
+		return "user:" + user
+	}
+	if s.token != "" {
+		return "token"
+	}
+	return "ip:" + remoteHost(remoteAddr)
+}
+
+// remoteHost returns the IP address of the given remote address, or the
+// remote address itself if it has no port.
+func remoteHost(remoteAddr string) string {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return remoteAddr
+	}
+	return host
+}
+
+// authKey returns the key by which the failed authentication attempts from
+// the given remote address are limited, apart from the requests of any token.
+func authKey(remoteAddr string) string {
+	return "auth:" + remoteHost(remoteAddr)
+}
+
+// retryAfter returns the number of whole seconds, at least 1, after which a
+// client whose bucket refills at the given rate has a token again.
+func retryAfter(limit rate.Limit) int {
+	return max(int(math.Ceil(1/float64(limit))), 1)
+}
+
+// bodyErrorStatus returns the status code of the response to a request whose
+// body cannot be decoded with the given error: 413 Content Too Large if the
+// body is larger than the Server allows, and 400 Bad Request otherwise.
+func bodyErrorStatus(err error) int {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
+}
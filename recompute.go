@@ -0,0 +1,74 @@
+package habit
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// Recompute derives the current and longest streaks of the Habits with the
+// given names, or of every Habit if no names are given, from their completion
+// histories, following the same rules as Track, and saves the store. It fixes
+// streaks that have drifted from the history, such as after an import, a
+// merge or a bug, and writes a message for every Habit whose streaks changed
+// to the Tracker's output. The longest streak of a Habit whose history was
+// compacted is never lowered, since it may be among the pruned completions.
+// Habits to avoid are left alone, since their streaks are counted from their
+// last relapse whenever they are shown. An error is returned if a named Habit
+// does not exist or the store cannot be saved.
+func (t *Tracker) Recompute(names ...string) error {
+	var habits []Habit
+	for _, name := range names {
+		hbt, ok := t.store.Get(name)
+		if !ok {
+			return errHabitNotFound(name)
+		}
+		habits = append(habits, hbt)
+	}
+	if len(names) == 0 {
+		habits = t.store.All()
+		sort.Slice(habits, func(i, j int) bool {
+			return habits[i].Name < habits[j].Name
+		})
+	}
+	var messages []string
+	for _, hbt := range habits {
+		if hbt.Avoid {
+			continue
+		}
+		current, longest := t.recompute(hbt)
+		if current == hbt.CurrentStreak && longest == hbt.LongestStreak {
+			continue
+		}
+		messages = append(messages, fmt.Sprintf("Recomputed the streaks of '%s': current %d (was %d), longest %d (was %d).",
+			hbt.Name, current, hbt.CurrentStreak, longest, hbt.LongestStreak))
+		hbt.CurrentStreak, hbt.LongestStreak = current, longest
+		t.store.Add(hbt)
+	}
+	if len(messages) == 0 {
+		fmt.Fprintf(t.output, "The streaks of %d %s already match the history.\n", len(habits), plural(len(habits), "habit"))
+		return nil
+	}
+	err := t.save()
+	if err != nil {
+		return err
+	}
+	for _, msg := range messages {
+		fmt.Fprintln(t.output, msg)
+	}
+	return nil
+}
+
+// recompute returns the current and longest streaks of the given Habit derived
+// from its completion history, in chronological order.
+func (t *Tracker) recompute(hbt Habit) (current, longest int) {
+	hbt.History = slices.Clone(hbt.History)
+	sort.SliceStable(hbt.History, func(i, j int) bool {
+		return hbt.History[i].At.Before(hbt.History[j].At)
+	})
+	current, longest = computeStreaks(hbt, t.calendar)
+	if hbt.Compacted != nil {
+		longest = max(longest, hbt.LongestStreak)
+	}
+	return current, longest
+}
@@ -0,0 +1,74 @@
+package habit_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+)
+
+func TestTracker_RecomputeDerivesStreaksFromHistory(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T13:00:00Z")
+	now := habit.Now()
+	store := &memStore{habits: map[string]habit.Habit{
+		"reading": {
+			Name:          "reading",
+			CurrentStreak: 5,
+			LongestStreak: 1,
+			LastDone:      now.Add(-time.Hour),
+			History: []habit.Completion{
+				{At: now.Add(-time.Hour)},
+				{At: now.Add(-20 * time.Hour)},
+				{At: now.Add(-100 * time.Hour)},
+			},
+		},
+		"cleaning": {
+			Name:          "cleaning",
+			CurrentStreak: 1,
+			LongestStreak: 1,
+			LastDone:      now.Add(-time.Hour),
+			History:       []habit.Completion{{At: now.Add(-time.Hour)}},
+		},
+	}}
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output), habit.WithLocation(time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Recompute()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := "Recomputed the streaks of 'reading': current 2 (was 5), longest 2 (was 1).\n", output.String(); want != got {
+		t.Errorf("want output %q, got output %q", want, got)
+	}
+	if store.saves != 1 {
+		t.Errorf("want 1 save, got %d", store.saves)
+	}
+	hbt := store.habits["reading"]
+	if hbt.CurrentStreak != 2 || hbt.LongestStreak != 2 {
+		t.Errorf("want streaks 2 and 2, got %d and %d", hbt.CurrentStreak, hbt.LongestStreak)
+	}
+	output.Reset()
+	err = tracker.Recompute("reading")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := "The streaks of 1 habit already match the history.\n", output.String(); want != got {
+		t.Errorf("want output %q, got output %q", want, got)
+	}
+}
+
+func TestTracker_RecomputeReturnsErrorForUnknownHabit(t *testing.T) {
+	t.Parallel()
+	tracker, err := habit.NewTracker(habit.WithStore(&memStore{habits: map[string]habit.Habit{}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Recompute("reading")
+	if !errors.Is(err, habit.ErrHabitNotFound) {
+		t.Errorf("want ErrHabitNotFound, got %v", err)
+	}
+}
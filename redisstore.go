@@ -0,0 +1,422 @@
+package habit
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// A RedisStore provides a store for Habits that is persisted to a Redis
+// server, so that a server with an ephemeral disk can keep its habits
+// elsewhere. Each Habit is kept as a hash of its fields, and its history as a
+// sorted set of its completions ordered by time. Changes made with Add and
+// Delete are buffered in memory until Save writes them in a single
+// transaction.
+type RedisStore struct {
+	client  *redis.Client
+	prefix  string
+	pending map[string]*Habit
+	err     error
+	mtx     sync.Mutex
+}
+
+// RedisOptions configures the connection of a RedisStore.
+type RedisOptions struct {
+	// Username is the user to authenticate as, for servers with access
+	// control lists.
+	Username string
+	// Password is the password to authenticate with. No authentication is
+	// used if it is empty.
+	Password string
+	// DB is the number of the Redis database to use.
+	DB int
+	// TLS is true if the connection to the server is encrypted with TLS.
+	TLS bool
+	// Prefix is prepended to the name of every key the store uses, so that
+	// several stores can share a database. It defaults to "habit:".
+	Prefix string
+}
+
+// defaultRedisPrefix is the key prefix used when RedisOptions has none.
+const defaultRedisPrefix = "habit:"
+
+// OpenRedisStore connects to the Redis server at the given address, such as
+// "localhost:6379", with the given options and returns a RedisStore backed by
+// it, upgrading the habits it holds to SchemaVersion if necessary. An error is
+// returned if the server cannot be reached or its habits cannot be upgraded.
+func OpenRedisStore(addr string, opts RedisOptions) (*RedisStore, error) {
+	cfg := &redis.Options{
+		Addr:     addr,
+		Username: opts.Username,
+		Password: opts.Password,
+		DB:       opts.DB,
+	}
+	if opts.TLS {
+		cfg.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	client := redis.NewClient(cfg)
+	prefix := opts.Prefix
+	if prefix == "" {
+		prefix = defaultRedisPrefix
+	}
+	s := &RedisStore{
+		client:  client,
+		prefix:  prefix,
+		pending: map[string]*Habit{},
+	}
+	ctx := context.Background()
+	err := client.Ping(ctx).Err()
+	if err != nil {
+		client.Close()
+		return nil, fmt.Errorf("error connecting to redis store %q: %w", addr, err)
+	}
+	err = s.migrate(ctx)
+	if err != nil {
+		client.Close()
+		return nil, fmt.Errorf("error migrating redis store %q: %w", addr, err)
+	}
+	return s, nil
+}
+
+// isRedisStore reports whether the given store path is the URL of a Redis
+// server rather than a file path.
+func isRedisStore(path string) bool {
+	return strings.HasPrefix(path, "redis://") || strings.HasPrefix(path, "rediss://")
+}
+
+// openRedisURL opens the RedisStore at the given URL, such as
+// "redis://:password@localhost:6379/0?prefix=work:", with the "rediss" scheme
+// for servers that require TLS.
+func openRedisURL(rawURL string) (*RedisStore, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis store URL: %w", err)
+	}
+	prefix := u.Query().Get("prefix")
+	query := u.Query()
+	query.Del("prefix")
+	u.RawQuery = query.Encode()
+	cfg, err := redis.ParseURL(u.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis store URL: %w", err)
+	}
+	return OpenRedisStore(cfg.Addr, RedisOptions{
+		Username: cfg.Username,
+		Password: cfg.Password,
+		DB:       cfg.DB,
+		TLS:      cfg.TLSConfig != nil,
+		Prefix:   prefix,
+	})
+}
+
+// namesKey returns the key of the set holding the names of the store's
+// Habits.
+func (s *RedisStore) namesKey() string {
+	return s.prefix + "names"
+}
+
+// habitKey returns the key of the hash holding the fields of the Habit with
+// the given name.
+func (s *RedisStore) habitKey(name string) string {
+	return s.prefix + "habit:" + name
+}
+
+// historyKey returns the key of the sorted set holding the history of the
+// Habit with the given name.
+func (s *RedisStore) historyKey(name string) string {
+	return s.prefix + "history:" + name
+}
+
+// versionKey returns the key holding the schema version of the store's
+// Habits.
+func (s *RedisStore) versionKey() string {
+	return s.prefix + "schema_version"
+}
+
+// migrate upgrades the store's Habits to SchemaVersion, which is recorded in
+// the version key, in a single transaction. A store without a version key
+// holds no Habits yet or was written before versions were recorded.
+func (s *RedisStore) migrate(ctx context.Context) error {
+	version, err := s.client.Get(ctx, s.versionKey()).Int()
+	if errors.Is(err, redis.Nil) {
+		var count int64
+		count, err = s.client.SCard(ctx, s.namesKey()).Result()
+		if err == nil && count == 0 {
+			return s.client.Set(ctx, s.versionKey(), SchemaVersion, 0).Err()
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if version == SchemaVersion {
+		return nil
+	}
+	habits, err := s.load(ctx)
+	if err != nil {
+		return err
+	}
+	data := make(map[string]Habit, len(habits))
+	for _, h := range habits {
+		data[h.Name] = h
+	}
+	err = migrate(version, data)
+	if err != nil {
+		return err
+	}
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, h := range data {
+			err := s.write(ctx, pipe, h)
+			if err != nil {
+				return err
+			}
+		}
+		pipe.Set(ctx, s.versionKey(), SchemaVersion, 0)
+		return nil
+	})
+	return err
+}
+
+// Get returns the habit with the given name and a bool indicating if the habit
+// exists in the store.
+func (s *RedisStore) Get(name string) (Habit, bool) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if h, ok := s.pending[name]; ok {
+		if h == nil {
+			return Habit{}, false
+		}
+		return *h, true
+	}
+	habits, err := s.read(context.Background(), []string{name})
+	if err != nil {
+		s.setErr(err)
+		return Habit{}, false
+	}
+	if len(habits) == 0 {
+		return Habit{}, false
+	}
+	return habits[0], true
+}
+
+// Add adds or updates the given habit in the store.
+func (s *RedisStore) Add(h Habit) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.pending[h.Name] = &h
+}
+
+// Delete deletes the habit with the given name from the store. If the
+// habit does not exist in the store, then the delete is a no-op.
+func (s *RedisStore) Delete(name string) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.pending[name] = nil
+}
+
+// All returns a list of all habits contained in the store.
+func (s *RedisStore) All() []Habit {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	saved, err := s.load(context.Background())
+	if err != nil {
+		s.setErr(err)
+		return nil
+	}
+	var habits []Habit
+	for _, h := range saved {
+		if _, ok := s.pending[h.Name]; !ok {
+			habits = append(habits, h)
+		}
+	}
+	for _, h := range s.pending {
+		if h != nil {
+			habits = append(habits, *h)
+		}
+	}
+	return habits
+}
+
+// load returns every Habit saved to the Redis server.
+func (s *RedisStore) load(ctx context.Context) ([]Habit, error) {
+	names, err := s.client.SMembers(ctx, s.namesKey()).Result()
+	if err != nil {
+		return nil, fmt.Errorf("error querying habits: %w", err)
+	}
+	return s.read(ctx, names)
+}
+
+// read returns the saved Habits with the given names, skipping the names of
+// Habits that do not exist.
+func (s *RedisStore) read(ctx context.Context, names []string) ([]Habit, error) {
+	fields := make([]*redis.MapStringStringCmd, len(names))
+	history := make([]*redis.StringSliceCmd, len(names))
+	_, err := s.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, name := range names {
+			fields[i] = pipe.HGetAll(ctx, s.habitKey(name))
+			history[i] = pipe.ZRange(ctx, s.historyKey(name), 0, -1)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error querying habits: %w", err)
+	}
+	var habits []Habit
+	for i, name := range names {
+		if len(fields[i].Val()) == 0 {
+			continue
+		}
+		h, err := decodeRedisHabit(fields[i].Val(), history[i].Val())
+		if err != nil {
+			return nil, errorOf(ErrStoreCorrupt, "error decoding habit '%s': %w", name, err)
+		}
+		habits = append(habits, h)
+	}
+	return habits, nil
+}
+
+// write queues the commands that replace the given Habit's hash and history
+// with its current fields and completions in the given pipeline.
+func (s *RedisStore) write(ctx context.Context, pipe redis.Pipeliner, h Habit) error {
+	fields, err := redisFields(h)
+	if err != nil {
+		return fmt.Errorf("error encoding habit '%s': %w", h.Name, err)
+	}
+	pipe.Del(ctx, s.habitKey(h.Name), s.historyKey(h.Name))
+	pipe.HSet(ctx, s.habitKey(h.Name), fields)
+	if len(h.History) > 0 {
+		members := make([]redis.Z, len(h.History))
+		for i, c := range h.History {
+			member, err := json.Marshal(c)
+			if err != nil {
+				return fmt.Errorf("error encoding habit '%s': %w", h.Name, err)
+			}
+			members[i] = redis.Z{Score: float64(c.At.UnixMicro()), Member: string(member)}
+		}
+		pipe.ZAdd(ctx, s.historyKey(h.Name), members...)
+	}
+	pipe.SAdd(ctx, s.namesKey(), h.Name)
+	return nil
+}
+
+// redisFields returns the fields of the hash that holds the given Habit: the
+// JSON encoding of each of its fields apart from its history, by the field's
+// JSON name.
+func redisFields(h Habit) (map[string]any, error) {
+	h.History = nil
+	data, err := json.Marshal(h)
+	if err != nil {
+		return nil, err
+	}
+	var raw map[string]json.RawMessage
+	err = json.Unmarshal(data, &raw)
+	if err != nil {
+		return nil, err
+	}
+	fields := make(map[string]any, len(raw))
+	for name, value := range raw {
+		fields[name] = string(value)
+	}
+	return fields, nil
+}
+
+// decodeRedisHabit returns the Habit held in the given hash fields, as
+// written by redisFields, with the completions in the given sorted set
+// members as its history.
+func decodeRedisHabit(fields map[string]string, history []string) (Habit, error) {
+	raw := make(map[string]json.RawMessage, len(fields))
+	for name, value := range fields {
+		raw[name] = json.RawMessage(value)
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return Habit{}, err
+	}
+	var h Habit
+	err = json.Unmarshal(data, &h)
+	if err != nil {
+		return Habit{}, err
+	}
+	for _, member := range history {
+		var c Completion
+		err = json.Unmarshal([]byte(member), &c)
+		if err != nil {
+			return Habit{}, err
+		}
+		h.History = append(h.History, c)
+	}
+	return h, nil
+}
+
+// Save writes the changes made since the last Save to the Redis server in a
+// single transaction. An error is returned if a previous query failed or if
+// the changes cannot be written.
+func (s *RedisStore) Save() error {
+	return s.SaveContext(context.Background())
+}
+
+// SaveContext writes the changes like Save, under the given context. The
+// changes are kept for the next save if they cannot be written.
+func (s *RedisStore) SaveContext(ctx context.Context) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.err != nil {
+		err := s.err
+		s.err = nil
+		return err
+	}
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for name, h := range s.pending {
+			if h == nil {
+				pipe.Del(ctx, s.habitKey(name), s.historyKey(name))
+				pipe.SRem(ctx, s.namesKey(), name)
+				continue
+			}
+			err := s.write(ctx, pipe, *h)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error saving habits to redis: %w", err)
+	}
+	s.pending = map[string]*Habit{}
+	return nil
+}
+
+// LoadContext discards the changes made since the last save, so that the
+// store reads the habits saved to the Redis server, and checks that the
+// server can still be reached before the given context is done.
+func (s *RedisStore) LoadContext(ctx context.Context) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.pending = map[string]*Habit{}
+	s.err = nil
+	err := s.client.Ping(ctx).Err()
+	if err != nil {
+		return fmt.Errorf("error connecting to redis: %w", err)
+	}
+	return nil
+}
+
+// Close closes the connection to the Redis server. Changes that have not been
+// saved are discarded.
+func (s *RedisStore) Close() error {
+	return s.client.Close()
+}
+
+// setErr records the first error encountered by a query so that it can be
+// returned by the next call to Save. The caller must hold s.mtx.
+func (s *RedisStore) setErr(err error) {
+	if s.err == nil {
+		s.err = err
+	}
+}
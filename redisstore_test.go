@@ -0,0 +1,166 @@
+package habit_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRedisStore_SaveSavesStorePersistently(t *testing.T) {
+	t.Parallel()
+	srv := miniredis.RunT(t)
+	store, err := habit.OpenRedisStore(srv.Addr(), habit.RedisOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := time.Date(2024, time.February, 5, 20, 0, 0, 0, time.UTC)
+	store.Add(habit.Habit{Name: "habit1", CurrentStreak: 2, LongestStreak: 2, LastDone: done,
+		History: []habit.Completion{{At: done.Add(-24 * time.Hour)}, {At: done, Note: "read a chapter"}}})
+	store.Add(habit.Habit{Name: "habit2", Tags: []string{"health"}})
+	store.Add(habit.Habit{Name: "habit3"})
+	store.Delete("habit3")
+	err = store.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = store.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	store2, err := habit.OpenRedisStore(srv.Addr(), habit.RedisOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store2.Close()
+	want := []habit.Habit{
+		{Name: "habit1", CurrentStreak: 2, LongestStreak: 2, LastDone: done,
+			History: []habit.Completion{{At: done.Add(-24 * time.Hour)}, {At: done, Note: "read a chapter"}}},
+		{Name: "habit2", Tags: []string{"health"}},
+	}
+	got := store2.All()
+	if !cmp.Equal(want, got, habitSliceCmpOpt) {
+		t.Error(cmp.Diff(want, got, habitSliceCmpOpt))
+	}
+	if !srv.Exists("habit:history:habit1") || srv.Exists("habit:habit:habit3") {
+		t.Errorf("want keys for habit1 but not habit3, got %v", srv.Keys())
+	}
+}
+
+func TestRedisStore_GetReturnsUnsavedChanges(t *testing.T) {
+	t.Parallel()
+	store, err := habit.OpenRedisStore(miniredis.RunT(t).Addr(), habit.RedisOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	store.Add(habit.Habit{Name: "habit1"})
+	err = store.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{Name: "habit2", CurrentStreak: 1})
+	got, ok := store.Get("habit2")
+	if !ok {
+		t.Fatal("expected ok to be true when getting unsaved habit")
+	}
+	want := habit.Habit{Name: "habit2", CurrentStreak: 1}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+	store.Delete("habit1")
+	_, ok = store.Get("habit1")
+	if ok {
+		t.Error("wanted ok to be false when getting habit deleted but not yet saved")
+	}
+	_, ok = store.Get("nonexistent-key")
+	if ok {
+		t.Error("wanted ok to be false when getting non-existent key")
+	}
+}
+
+func TestRedisStore_PrefixSeparatesStoresSharingADatabase(t *testing.T) {
+	t.Parallel()
+	srv := miniredis.RunT(t)
+	work, err := habit.OpenRedisStore(srv.Addr(), habit.RedisOptions{Prefix: "work:"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer work.Close()
+	personal, err := habit.OpenRedisStore(srv.Addr(), habit.RedisOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer personal.Close()
+	work.Add(habit.Habit{Name: "email"})
+	err = work.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := personal.All(); len(got) != 0 {
+		t.Errorf("want no habits in the unprefixed store, got %v", got)
+	}
+}
+
+func TestRedisStore_SaveContextKeepsChangesGivenCancelledContext(t *testing.T) {
+	t.Parallel()
+	store, err := habit.OpenRedisStore(miniredis.RunT(t).Addr(), habit.RedisOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	store.Add(habit.Habit{Name: "habit1"})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = store.SaveContext(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("want error %v, got %v", context.Canceled, err)
+	}
+	err = store.SaveContext(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = store.LoadContext(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, ok := store.Get("habit1")
+	if !ok {
+		t.Error("want habit kept after a cancelled save to be saved by the next save")
+	}
+}
+
+func TestRedisStore_ReportsCorruptHabitOnSave(t *testing.T) {
+	t.Parallel()
+	srv := miniredis.RunT(t)
+	store, err := habit.OpenRedisStore(srv.Addr(), habit.RedisOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	srv.SAdd("habit:names", "habit1")
+	srv.HSet("habit:habit:habit1", "name", "not JSON")
+	_, ok := store.Get("habit1")
+	if ok {
+		t.Error("wanted ok to be false when getting a corrupt habit")
+	}
+	err = store.Save()
+	if !errors.Is(err, habit.ErrStoreCorrupt) {
+		t.Errorf("want error %v, got %v", habit.ErrStoreCorrupt, err)
+	}
+}
+
+func TestOpenRedisStoreReturnsErrorGivenUnreachableServer(t *testing.T) {
+	t.Parallel()
+	srv := miniredis.RunT(t)
+	addr := srv.Addr()
+	srv.Close()
+	_, err := habit.OpenRedisStore(addr, habit.RedisOptions{})
+	if err == nil {
+		t.Error("want error for unreachable server, got nil")
+	}
+}
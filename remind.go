@@ -0,0 +1,176 @@
+package habit
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// A Notifier delivers reminders to the user.
+type Notifier interface {
+	// Notify delivers a reminder with the given title and message.
+	Notify(title, message string) error
+}
+
+// TerminalNotifier returns a Notifier that writes reminders to the given
+// writer, one per line.
+func TerminalNotifier(w io.Writer) Notifier {
+	return terminalNotifier{w: w}
+}
+
+// terminalNotifier writes reminders to a writer.
+type terminalNotifier struct {
+	w io.Writer
+}
+
+// Notify writes the given message to the notifier's writer.
+func (n terminalNotifier) Notify(title, message string) error {
+	_, err := fmt.Fprintln(n.w, message)
+	return err
+}
+
+// DesktopNotifier returns a Notifier that shows reminders as desktop
+// notifications, using osascript on macOS and notify-send elsewhere. If the
+// notification command is not available or fails, reminders are written to the
+// given fallback writer instead.
+func DesktopNotifier(fallback io.Writer) Notifier {
+	n := commandNotifier{fallback: TerminalNotifier(fallback)}
+	switch runtime.GOOS {
+	case "darwin":
+		n.name = "osascript"
+		n.args = func(title, message string) []string {
+			return []string{"-e", fmt.Sprintf("display notification %s with title %s",
+				appleScriptString(message), appleScriptString(title))}
+		}
+	default:
+		n.name = "notify-send"
+		n.args = func(title, message string) []string {
+			return []string{title, message}
+		}
+	}
+	_, err := exec.LookPath(n.name)
+	if err != nil {
+		return n.fallback
+	}
+	return n
+}
+
+// commandNotifier shows reminders by running a notification command.
+type commandNotifier struct {
+	// name is the name of the notification command.
+	name string
+	// args returns the command's arguments for the given title and message.
+	args func(title, message string) []string
+	// fallback is used if the command fails.
+	fallback Notifier
+}
+
+// Notify runs the notifier's command with the given title and message, falling
+// back to the notifier's fallback if the command fails.
+func (n commandNotifier) Notify(title, message string) error {
+	err := exec.Command(n.name, n.args(title, message)...).Run()
+	if err != nil {
+		return n.fallback.Notify(title, message)
+	}
+	return nil
+}
+
+// appleScriptString returns the given value as a quoted AppleScript string.
+func appleScriptString(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+// RemindDue sends a single reminder with the given Notifier listing the Habits
+// that are still due and which of them are past their deadline, reloading the
+// store first if it supports reloading. Habits snoozed with Snooze are left
+// out, and no reminder is sent if nothing else is due. An error is returned if
+// the store cannot be reloaded or the reminder cannot be sent.
+func (t *Tracker) RemindDue(notifier Notifier) error {
+	return t.RemindDueContext(context.Background(), notifier)
+}
+
+// RemindDueContext sends a reminder like RemindDue, reloading the store under
+// the given context.
+func (t *Tracker) RemindDueContext(ctx context.Context, notifier Notifier) error {
+	err := LoadContext(ctx, t.store)
+	if err != nil {
+		return err
+	}
+	now := t.now()
+	var due []Habit
+	for _, hbt := range t.Due() {
+		if !hbt.snoozed(now) {
+			due = append(due, hbt)
+		}
+	}
+	if len(due) < 1 {
+		return nil
+	}
+	names := make([]string, len(due))
+	var overdue []string
+	for i, hbt := range due {
+		names[i] = fmt.Sprintf("'%s'", hbt.Name)
+		if hbt.overdue(now, t.calendar) {
+			overdue = append(overdue, t.text("reminder_deadline", MessageData{Name: hbt.Name, Deadline: hbt.Deadline.String()}))
+		}
+	}
+	message := t.text("reminder_due", MessageData{Count: len(names), List: t.joinWords(names, "or")})
+	if len(overdue) > 0 {
+		message += " " + t.text("reminder_overdue", MessageData{Count: len(overdue), List: t.joinWords(overdue, "and")})
+	}
+	err = notifier.Notify(t.word("reminder_title"), message)
+	if err != nil {
+		return err
+	}
+	t.logger.InfoContext(ctx, "reminder sent", "due", len(due), "overdue", len(overdue))
+	return nil
+}
+
+// Remind calls RemindDue with the given Notifier every day at the given time of
+// day, starting with the next occurrence of that time, until the given context
+// is cancelled. An error is returned if a reminder cannot be sent.
+func (t *Tracker) Remind(ctx context.Context, at TimeOfDay, notifier Notifier) error {
+	return t.RemindEvery(ctx, at, 0, notifier)
+}
+
+// RemindEvery calls RemindDue with the given Notifier every day at the given
+// time of day like Remind, and then again at the given interval for the rest
+// of the day, so that it keeps nagging about the Habits that are still due
+// until they are tracked or snoozed. If the time of day has already passed
+// today, the reminders start at the next interval. An interval of zero sends a
+// single reminder a day. An error is returned if a reminder cannot be sent.
+func (t *Tracker) RemindEvery(ctx context.Context, at TimeOfDay, every time.Duration, notifier Notifier) error {
+	now := t.now()
+	next := at.On(now)
+	for !next.After(now) {
+		next = t.nextReminder(next, at, every)
+	}
+	for {
+		timer := time.NewTimer(next.Sub(t.now()))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+		err := t.RemindDueContext(ctx, notifier)
+		if err != nil {
+			return err
+		}
+		next = t.nextReminder(next, at, every)
+	}
+}
+
+// nextReminder returns the time of the reminder following the one at the
+// given time: the given interval later if that is still the same calendar
+// date, and otherwise the given time of day on the next date.
+func (t *Tracker) nextReminder(prev time.Time, at TimeOfDay, every time.Duration) time.Time {
+	if every > 0 && t.calendar.day(prev.Add(every)).Equal(t.calendar.day(prev)) {
+		return prev.Add(every)
+	}
+	return at.On(prev.AddDate(0, 0, 1))
+}
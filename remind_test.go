@@ -0,0 +1,147 @@
+package habit_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+// recordingNotifier records the messages of the reminders it is sent.
+type recordingNotifier struct {
+	messages []string
+	notified func()
+}
+
+func (n *recordingNotifier) Notify(title, message string) error {
+	n.messages = append(n.messages, message)
+	if n.notified != nil {
+		n.notified()
+	}
+	return nil
+}
+
+func TestTracker_RemindDueSendsReminderListingDueHabits(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T20:00:00Z")
+	doneToday := habit.Now().Add(-time.Hour)
+	doneYesterday := habit.Now().Add(-21 * time.Hour)
+	store, err := habit.OpenStore(t.TempDir() + "/test.store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{Name: "reading", LastDone: doneYesterday})
+	store.Add(habit.Habit{Name: "programming", LastDone: doneToday})
+	store.Add(habit.Habit{Name: "running", LastDone: doneYesterday})
+	err = store.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(new(bytes.Buffer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	notifier := new(recordingNotifier)
+	err = tracker.RemindDue(notifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Delete("running")
+	err = store.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.RemindDue(notifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Delete("reading")
+	err = store.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.RemindDue(notifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"You haven't done 'reading' or 'running' yet. Do them soon to keep your streaks going!",
+		"You haven't done 'reading' yet. Do it soon to keep your streak going!",
+	}
+	if !cmp.Equal(want, notifier.messages) {
+		t.Error(cmp.Diff(want, notifier.messages))
+	}
+}
+
+func TestTracker_RemindDueReloadsHabitsSavedByOtherProcesses(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T20:00:00Z")
+	path := t.TempDir() + "/test.store"
+	store, err := habit.OpenStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(new(bytes.Buffer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := habit.OpenStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other.Add(habit.Habit{Name: "reading", LastDone: habit.Now().Add(-21 * time.Hour)})
+	err = other.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	notifier := new(recordingNotifier)
+	err = tracker.RemindDue(notifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"You haven't done 'reading' yet. Do it soon to keep your streak going!"}
+	if !cmp.Equal(want, notifier.messages) {
+		t.Error(cmp.Diff(want, notifier.messages))
+	}
+}
+
+func TestTracker_RemindSendsReminderAtTimeOfDayUntilCancelled(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T19:59:59.9Z")
+	store, err := habit.OpenStore(t.TempDir() + "/test.store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{Name: "reading", LastDone: habit.Now().Add(-21 * time.Hour)})
+	err = store.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(new(bytes.Buffer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	notifier := &recordingNotifier{notified: cancel}
+	err = tracker.Remind(ctx, habit.TimeOfDay{Hour: 20}, notifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"You haven't done 'reading' yet. Do it soon to keep your streak going!"}
+	if !cmp.Equal(want, notifier.messages) {
+		t.Error(cmp.Diff(want, notifier.messages))
+	}
+}
+
+func TestTerminalNotifierWritesMessage(t *testing.T) {
+	output := new(bytes.Buffer)
+	err := habit.TerminalNotifier(output).Notify("Habit reminder", "You haven't done 'reading' yet.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "You haven't done 'reading' yet.\n"
+	if want != output.String() {
+		t.Errorf("want output %q, got %q", want, output.String())
+	}
+}
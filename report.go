@@ -0,0 +1,225 @@
+package habit
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// A ReportPeriod is the span of time covered by a report.
+type ReportPeriod int
+
+const (
+	// ReportMonth covers a calendar month.
+	ReportMonth ReportPeriod = iota
+	// ReportWeek covers a week from Monday to Sunday.
+	ReportWeek
+)
+
+// ParseReportPeriod accepts "month" or "week" and returns the corresponding
+// ReportPeriod. An error is returned if the value is not recognized.
+func ParseReportPeriod(value string) (ReportPeriod, error) {
+	switch value {
+	case "month":
+		return ReportMonth, nil
+	case "week":
+		return ReportWeek, nil
+	}
+	return 0, fmt.Errorf("invalid report period %q (want month or week)", value)
+}
+
+// A ReportFormat is the markup in which a report is written.
+type ReportFormat int
+
+const (
+	// ReportMarkdown writes reports as Markdown, suitable for pasting into a
+	// journal or notes app.
+	ReportMarkdown ReportFormat = iota
+)
+
+// ParseReportFormat accepts "markdown" or "md" and returns the corresponding
+// ReportFormat. An error is returned if the value is not recognized.
+func ParseReportFormat(value string) (ReportFormat, error) {
+	switch value {
+	case "markdown", "md":
+		return ReportMarkdown, nil
+	}
+	return 0, fmt.Errorf("invalid report format %q (want markdown)", value)
+}
+
+// PrintReport writes a review of every active Habit over the ReportPeriod that
+// contains the given date to the given Tracker's output in the given
+// ReportFormat. The review has a table of streaks and completion rates, a
+// completion calendar, the streak milestones reached and the notes attached to
+// completions in the period. Archived Habits and Habits to avoid are left out.
+func (t *Tracker) PrintReport(period ReportPeriod, format ReportFormat, date time.Time) error {
+	if format != ReportMarkdown {
+		return fmt.Errorf("unsupported report format %d", format)
+	}
+	now := t.now()
+	day := t.calendar.day(date)
+	var first, last time.Time
+	var title string
+	switch period {
+	case ReportWeek:
+		first = day.AddDate(0, 0, -weekdayIndex(day))
+		last = first.AddDate(0, 0, 6)
+		title = "Week of " + first.Format("2 January 2006")
+	default:
+		first = day.AddDate(0, 0, 1-day.Day())
+		last = first.AddDate(0, 1, -1)
+		title = first.Format("January 2006")
+	}
+	var habits []Habit
+	for _, hbt := range t.sortedHabits(false) {
+		if !hbt.Avoid {
+			habits = append(habits, hbt)
+		}
+	}
+	fmt.Fprintf(t.output, "# Habit report: %s\n\n", title)
+	if len(habits) < 1 {
+		fmt.Fprintln(t.output, "You're not currently tracking any habits.")
+		return nil
+	}
+	end := last
+	if today := t.calendar.day(now); today.Before(end) {
+		end = today
+	}
+	t.reportStreaks(habits, first, end, now)
+	t.reportCalendar(habits, first, last, now)
+	t.reportMilestones(habits, first, last)
+	t.reportNotes(habits, first, last)
+	return nil
+}
+
+// reportStreaks writes the streaks table of a report covering the days from
+// first to end, as of the given timestamp.
+func (t *Tracker) reportStreaks(habits []Habit, first, end, now time.Time) {
+	fmt.Fprintln(t.output, "## Streaks")
+	fmt.Fprintln(t.output)
+	fmt.Fprintln(t.output, "| Habit | Current streak | Longest streak | Done | Completion rate |")
+	fmt.Fprintln(t.output, "| --- | ---: | ---: | ---: | ---: |")
+	for _, hbt := range habits {
+		current, longest := hbt.streaks(now, t.calendar)
+		if hbt.missedPeriods(now, t.calendar) >= 1 {
+			current = 0
+		}
+		freq := hbt.Frequency
+		periods := map[int]bool{}
+		done := 0
+		for _, c := range hbt.History {
+			day := t.calendar.day(c.At)
+			if day.Before(first) || day.After(end) {
+				continue
+			}
+			done++
+			periods[freq.periodIndex(c.At, t.calendar)] = true
+		}
+		total := freq.periodIndex(end, t.calendar) - freq.periodIndex(first, t.calendar) + 1
+		rate := 0.0
+		if !end.Before(first) {
+			rate = float64(len(periods)) * 100 / float64(total)
+		}
+		fmt.Fprintf(t.output, "| %s | %d %s | %d %s | %d | %.0f%% |\n", markdownText(hbt.Name),
+			current, freq.unit(current), longest, freq.unit(longest), done, rate)
+	}
+	fmt.Fprintln(t.output)
+}
+
+// reportCalendar writes the completion calendar of a report covering the days
+// from first to last, with a cell for each day showing how many of the habits
+// were done that day. Days after the given timestamp are left blank.
+func (t *Tracker) reportCalendar(habits []Habit, first, last, now time.Time) {
+	done := map[time.Time]int{}
+	for _, hbt := range habits {
+		days := map[time.Time]bool{}
+		for _, c := range hbt.History {
+			days[t.calendar.day(c.At)] = true
+		}
+		for day := range days {
+			done[day]++
+		}
+	}
+	today := t.calendar.day(now)
+	fmt.Fprintln(t.output, "## Completion calendar")
+	fmt.Fprintln(t.output)
+	fmt.Fprintln(t.output, "| Mon | Tue | Wed | Thu | Fri | Sat | Sun |")
+	fmt.Fprintln(t.output, "| :-: | :-: | :-: | :-: | :-: | :-: | :-: |")
+	start := first.AddDate(0, 0, -weekdayIndex(first))
+	for week := start; !week.After(last); week = week.AddDate(0, 0, 7) {
+		cells := make([]string, 7)
+		for i := range cells {
+			day := week.AddDate(0, 0, i)
+			switch {
+			case day.Before(first) || day.After(last):
+				cells[i] = " "
+			case day.After(today):
+				cells[i] = fmt.Sprint(day.Day())
+			case done[day] == len(habits):
+				cells[i] = fmt.Sprintf("**%d** (%d/%d)", day.Day(), done[day], len(habits))
+			default:
+				cells[i] = fmt.Sprintf("%d (%d/%d)", day.Day(), done[day], len(habits))
+			}
+		}
+		fmt.Fprintf(t.output, "| %s |\n", strings.Join(cells, " | "))
+	}
+	fmt.Fprintln(t.output)
+}
+
+// reportMilestones writes the streak milestones reached by the habits between
+// the days first and last.
+func (t *Tracker) reportMilestones(habits []Habit, first, last time.Time) {
+	fmt.Fprintln(t.output, "## Milestones")
+	fmt.Fprintln(t.output)
+	reached := 0
+	for _, hbt := range habits {
+		streaks := completionStreaks(hbt, t.calendar)
+		for i, c := range hbt.History {
+			day := t.calendar.day(c.At)
+			if day.Before(first) || day.After(last) || !isStreakMilestone(streaks[i]) {
+				continue
+			}
+			if i > 0 && streaks[i-1] == streaks[i] {
+				continue
+			}
+			e := Event{Type: EventStreakMilestone, Habit: hbt.Name, Streak: streaks[i], Frequency: hbt.Frequency}
+			fmt.Fprintf(t.output, "- %s: %s\n", day.Format(time.DateOnly), markdownText(e.Message()))
+			reached++
+		}
+	}
+	if reached == 0 {
+		fmt.Fprintln(t.output, "No streak milestones were reached.")
+	}
+}
+
+// reportNotes writes the notes attached to the habits' completions between the
+// days first and last, if there are any.
+func (t *Tracker) reportNotes(habits []Habit, first, last time.Time) {
+	var notes []string
+	for _, hbt := range habits {
+		for _, c := range hbt.History {
+			day := t.calendar.day(c.At)
+			if c.Note == "" || day.Before(first) || day.After(last) {
+				continue
+			}
+			notes = append(notes, fmt.Sprintf("- %s **%s**: %s",
+				day.Format(time.DateOnly), markdownText(hbt.Name), markdownText(c.Note)))
+		}
+	}
+	if len(notes) < 1 {
+		return
+	}
+	fmt.Fprintln(t.output)
+	fmt.Fprintln(t.output, "## Notes")
+	fmt.Fprintln(t.output)
+	for _, note := range notes {
+		fmt.Fprintln(t.output, note)
+	}
+}
+
+// markdownText escapes the characters in the given value that would otherwise
+// be read as Markdown or break a table cell.
+func markdownText(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "|", `\|`, "*", `\*`, "_", `\_`, "`", "\\`",
+		"[", `\[`, "]", `\]`, "\n", " ").Replace(value)
+}
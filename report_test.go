@@ -0,0 +1,128 @@
+package habit_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTracker_PrintReportWritesMarkdownReviewOfMonth(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-10T20:00:00Z")
+	// Consecutive completions are less than a day apart so that they extend
+	// the streak.
+	at := func(day int) time.Time {
+		return time.Date(2024, time.February, day, 9, -day, 0, 0, time.UTC)
+	}
+	var history []habit.Completion
+	for day := 1; day <= 7; day++ {
+		history = append(history, habit.Completion{At: at(day)})
+	}
+	history[2].Note = "fixed a | pipe"
+	store, err := habit.OpenStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{
+		Name:          "programming",
+		CurrentStreak: 7,
+		LongestStreak: 7,
+		LastDone:      at(7),
+		History:       history,
+	})
+	store.Add(habit.Habit{
+		Name:          "reading",
+		CurrentStreak: 1,
+		LongestStreak: 2,
+		LastDone:      at(10),
+		History:       []habit.Completion{{At: time.Date(2024, time.January, 30, 9, 0, 0, 0, time.UTC)}, {At: at(10)}},
+	})
+	store.Add(habit.Habit{Name: "smoking", Avoid: true, LastDone: at(1)})
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.PrintReport(habit.ReportMonth, habit.ReportMarkdown, habit.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "# Habit report: February 2024\n" +
+		"\n" +
+		"## Streaks\n" +
+		"\n" +
+		"| Habit | Current streak | Longest streak | Done | Completion rate |\n" +
+		"| --- | ---: | ---: | ---: | ---: |\n" +
+		"| programming | 0 days | 7 days | 7 | 70% |\n" +
+		"| reading | 1 day | 2 days | 1 | 10% |\n" +
+		"\n" +
+		"## Completion calendar\n" +
+		"\n" +
+		"| Mon | Tue | Wed | Thu | Fri | Sat | Sun |\n" +
+		"| :-: | :-: | :-: | :-: | :-: | :-: | :-: |\n" +
+		"|   |   |   | 1 (1/2) | 2 (1/2) | 3 (1/2) | 4 (1/2) |\n" +
+		"| 5 (1/2) | 6 (1/2) | 7 (1/2) | 8 (0/2) | 9 (0/2) | 10 (1/2) | 11 |\n" +
+		"| 12 | 13 | 14 | 15 | 16 | 17 | 18 |\n" +
+		"| 19 | 20 | 21 | 22 | 23 | 24 | 25 |\n" +
+		"| 26 | 27 | 28 | 29 |   |   |   |\n" +
+		"\n" +
+		"## Milestones\n" +
+		"\n" +
+		"- 2024-02-07: Reached a 7-day streak for 'programming'!\n" +
+		"\n" +
+		"## Notes\n" +
+		"\n" +
+		"- 2024-02-03 **programming**: fixed a \\| pipe\n"
+	got := output.String()
+	if want != got {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestTracker_PrintReportCoversWeekContainingDate(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-10T20:00:00Z")
+	store, err := habit.OpenStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lastDone := time.Date(2024, time.February, 6, 9, 0, 0, 0, time.UTC)
+	store.Add(habit.Habit{
+		Name:          "programming",
+		CurrentStreak: 1,
+		LongestStreak: 1,
+		LastDone:      lastDone,
+		History:       []habit.Completion{{At: lastDone}},
+	})
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.PrintReport(habit.ReportWeek, habit.ReportMarkdown, lastDone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "# Habit report: Week of 5 February 2024\n" +
+		"\n" +
+		"## Streaks\n" +
+		"\n" +
+		"| Habit | Current streak | Longest streak | Done | Completion rate |\n" +
+		"| --- | ---: | ---: | ---: | ---: |\n" +
+		"| programming | 0 days | 1 day | 1 | 17% |\n" +
+		"\n" +
+		"## Completion calendar\n" +
+		"\n" +
+		"| Mon | Tue | Wed | Thu | Fri | Sat | Sun |\n" +
+		"| :-: | :-: | :-: | :-: | :-: | :-: | :-: |\n" +
+		"| 5 (0/1) | **6** (1/1) | 7 (0/1) | 8 (0/1) | 9 (0/1) | 10 (0/1) | 11 |\n" +
+		"\n" +
+		"## Milestones\n" +
+		"\n" +
+		"No streak milestones were reached.\n"
+	got := output.String()
+	if want != got {
+		t.Error(cmp.Diff(want, got))
+	}
+}
@@ -0,0 +1,135 @@
+package habit
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// RiskRecentDays is the number of days, up to and including today, over which
+// Risks computes the recent completion rate of each Habit.
+const RiskRecentDays = 14
+
+// RiskPriorDays is the number of days before the recent ones over which Risks
+// computes the completion rate each Habit's recent rate is compared with.
+const RiskPriorDays = 30
+
+// DefaultRiskDrop is the drop in completion rate, in percentage points, from
+// which Risks flags a Habit by default.
+const DefaultRiskDrop = 25
+
+// A Risk is a Habit whose completion rate over the last RiskRecentDays days has
+// dropped significantly from its rate over the RiskPriorDays days before, so
+// that its streak is likely to die unless something changes.
+type Risk struct {
+	// Name is the name of the habit.
+	Name string `json:"name"`
+	// Frequency is how often the habit must be done.
+	Frequency Frequency `json:"frequency"`
+	// CurrentStreak is the habit's current streak, in periods of its
+	// frequency.
+	CurrentStreak int `json:"current_streak"`
+	// RecentDone is the number of periods within the recent days in which
+	// the habit was done.
+	RecentDone int `json:"recent_done"`
+	// RecentPeriods is the number of periods that overlap the recent days,
+	// not counting the current period unless the habit was done in it.
+	RecentPeriods int `json:"recent_periods"`
+	// PriorDone is the number of periods within the prior days in which the
+	// habit was done.
+	PriorDone int `json:"prior_done"`
+	// PriorPeriods is the number of periods that overlap the prior days.
+	PriorPeriods int `json:"prior_periods"`
+}
+
+// RecentRate returns the percentage of the recent periods in which the habit
+// was done.
+func (r Risk) RecentRate() float64 {
+	return float64(r.RecentDone) * 100 / float64(r.RecentPeriods)
+}
+
+// PriorRate returns the percentage of the prior periods in which the habit was
+// done.
+func (r Risk) PriorRate() float64 {
+	return float64(r.PriorDone) * 100 / float64(r.PriorPeriods)
+}
+
+// Drop returns the drop in completion rate from the prior periods to the
+// recent ones, in percentage points.
+func (r Risk) Drop() float64 {
+	return r.PriorRate() - r.RecentRate()
+}
+
+// Risks returns the active Habits whose completion rate over the last
+// RiskRecentDays days, up to and including today, is at least the given number
+// of percentage points lower than over the RiskPriorDays days before, sorted
+// by the size of the drop, largest first. The current period only counts once
+// the Habit is done in it, so that Habits not done yet today are not flagged
+// for it. Habits to avoid, paused Habits and Habits not done at all in the
+// prior days are left out. An error is returned if the drop is not between 0
+// and 100.
+func (t *Tracker) Risks(drop float64) ([]Risk, error) {
+	if drop <= 0 || drop > 100 {
+		return nil, fmt.Errorf("invalid drop %g (want a number of percentage points from 1 to 100)", drop)
+	}
+	now := t.now()
+	// The prior days end at the last instant before the first recent day.
+	priorEnd := t.calendar.start(now).AddDate(0, 0, 1-RiskRecentDays).Add(-time.Nanosecond)
+	risks := []Risk{}
+	for _, hbt := range t.sortedHabits(false) {
+		if hbt.Avoid || hbt.Paused(now) {
+			continue
+		}
+		recent := computeStats(hbt, now, RiskRecentDays, t.calendar)
+		prior := computeStats(hbt, priorEnd, RiskPriorDays, t.calendar)
+		if !hbt.doneThisPeriod(now, t.calendar) {
+			recent.PeriodsInWindow--
+		}
+		if prior.PeriodsDone == 0 || recent.PeriodsInWindow < 1 {
+			continue
+		}
+		current, _ := hbt.streaks(now, t.calendar)
+		r := Risk{
+			Name:          hbt.Name,
+			Frequency:     hbt.Frequency,
+			CurrentStreak: current,
+			RecentDone:    recent.PeriodsDone,
+			RecentPeriods: recent.PeriodsInWindow,
+			PriorDone:     prior.PeriodsDone,
+			PriorPeriods:  prior.PeriodsInWindow,
+		}
+		if r.Drop() >= drop {
+			risks = append(risks, r)
+		}
+	}
+	sort.SliceStable(risks, func(i, j int) bool {
+		return risks[i].Drop() > risks[j].Drop()
+	})
+	return risks, nil
+}
+
+// PrintRisks writes the Habits at risk, as returned by Risks with the given
+// drop, to the Tracker's output, each with its recent and prior completion
+// rates, or a message saying that none are at risk. An error is returned if
+// the drop is not between 0 and 100.
+func (t *Tracker) PrintRisks(drop float64) error {
+	risks, err := t.Risks(drop)
+	if err != nil {
+		return err
+	}
+	if len(risks) == 0 {
+		fmt.Fprintf(t.output, "No habits are at risk: their completion rates in the last %d days are within %g points of the %d days before.\n",
+			RiskRecentDays, drop, RiskPriorDays)
+		return nil
+	}
+	for _, r := range risks {
+		fmt.Fprintf(t.output, "'%s' is at risk: done in %d of the last %d %s (%.0f%%), down from %d of the %d %s before (%.0f%%).",
+			r.Name, r.RecentDone, r.RecentPeriods, r.Frequency.unit(r.RecentPeriods), r.RecentRate(),
+			r.PriorDone, r.PriorPeriods, r.Frequency.unit(r.PriorPeriods), r.PriorRate())
+		if r.CurrentStreak > 1 {
+			fmt.Fprintf(t.output, " Don't let your %d-%s streak go!", r.CurrentStreak, r.Frequency.unit(1))
+		}
+		fmt.Fprintln(t.output)
+	}
+	return nil
+}
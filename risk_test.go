@@ -0,0 +1,82 @@
+package habit_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+)
+
+// historyBetween returns completions at 20:00 UTC on each day from the first
+// given date to the second, inclusive.
+func historyBetween(from, to time.Time) []habit.Completion {
+	var history []habit.Completion
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		history = append(history, habit.Completion{At: day.Add(20 * time.Hour)})
+	}
+	return history
+}
+
+func TestTracker_PrintRisksFlagsHabitsDoneLessOftenRecently(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-20T09:00:00Z")
+	date := func(month time.Month, day int) time.Time {
+		return time.Date(2024, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	reading := historyBetween(date(time.January, 8), date(time.February, 6))
+	reading = append(reading,
+		habit.Completion{At: date(time.February, 10).Add(20 * time.Hour)},
+		habit.Completion{At: date(time.February, 15).Add(20 * time.Hour)})
+	store := &memStore{habits: map[string]habit.Habit{
+		"reading": {Name: "reading", History: reading, LastDone: reading[len(reading)-1].At},
+		"running": {
+			Name:          "running",
+			CurrentStreak: 43,
+			LongestStreak: 43,
+			History:       historyBetween(date(time.January, 8), date(time.February, 19)),
+			LastDone:      date(time.February, 19).Add(20 * time.Hour),
+		},
+		"yoga": {
+			Name:     "yoga",
+			History:  historyBetween(date(time.February, 18), date(time.February, 19)),
+			LastDone: date(time.February, 19).Add(20 * time.Hour),
+		},
+	}}
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.PrintRisks(habit.DefaultRiskDrop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "'reading' is at risk: done in 2 of the last 13 days (15%), down from 30 of the 30 days before (100%).\n"
+	if got := output.String(); want != got {
+		t.Errorf("want output %q, got output %q", want, got)
+	}
+	store.Delete("reading")
+	output.Reset()
+	err = tracker.PrintRisks(habit.DefaultRiskDrop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = "No habits are at risk: their completion rates in the last 14 days are within 25 points of the 30 days before.\n"
+	if got := output.String(); want != got {
+		t.Errorf("want output %q, got output %q", want, got)
+	}
+}
+
+func TestTracker_RisksReturnsErrorForInvalidDrop(t *testing.T) {
+	t.Parallel()
+	tracker, err := habit.NewTracker(habit.WithStore(&memStore{habits: map[string]habit.Habit{}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, drop := range []float64{0, -10, 101} {
+		_, err = tracker.Risks(drop)
+		if err == nil {
+			t.Errorf("want an error for drop %g", drop)
+		}
+	}
+}
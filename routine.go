@@ -0,0 +1,169 @@
+package habit
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// AddToRoutine adds the Habits with the given names to the named routine, such
+// as "morning", so that they can all be tracked at once with DoRoutine, saves
+// the store and writes the routine's Habits to the Tracker's output. A Habit
+// belongs to at most one routine, so Habits already in another routine are
+// moved. An error is returned if the routine name is empty, no Habits are
+// given, any of the Habits does not exist or is a habit to avoid, or the store
+// cannot be saved.
+func (t *Tracker) AddToRoutine(routine string, hbtNames ...string) error {
+	if strings.TrimSpace(routine) == "" {
+		return errors.New("routine name cannot be empty")
+	}
+	if len(hbtNames) < 1 {
+		return fmt.Errorf("no habits given for routine '%s'", routine)
+	}
+	var habits []Habit
+	for _, name := range hbtNames {
+		hbt, ok := t.store.Get(name)
+		if !ok {
+			return errHabitNotFound(name)
+		}
+		if hbt.Avoid {
+			return fmt.Errorf("habit '%s' is a habit to avoid and cannot be part of a routine", name)
+		}
+		hbt.Routine = routine
+		habits = append(habits, hbt)
+	}
+	for _, hbt := range habits {
+		t.store.Add(hbt)
+	}
+	err := t.save()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(t.output, "The routine '%s' is %s.\n", routine, joinList(quoteNames(t.routineHabits(routine)), "and"))
+	return nil
+}
+
+// RemoveFromRoutine removes the Habits with the given names from their
+// routines and saves the store. Habits that are not in a routine are ignored.
+// An error is returned if no Habits are given, any of the Habits does not
+// exist, or the store cannot be saved.
+func (t *Tracker) RemoveFromRoutine(hbtNames ...string) error {
+	if len(hbtNames) < 1 {
+		return errors.New("no habits given to remove from their routines")
+	}
+	var habits []Habit
+	for _, name := range hbtNames {
+		hbt, ok := t.store.Get(name)
+		if !ok {
+			return errHabitNotFound(name)
+		}
+		hbt.Routine = ""
+		habits = append(habits, hbt)
+	}
+	for _, hbt := range habits {
+		t.store.Add(hbt)
+	}
+	err := t.save()
+	if err != nil {
+		return err
+	}
+	verb := "is"
+	if len(habits) > 1 {
+		verb = "are"
+	}
+	fmt.Fprintf(t.output, "%s %s no longer in a routine.\n", joinList(quoteNames(habits), "and"), verb)
+	return nil
+}
+
+// DoRoutine tracks every Habit in the named routine that has not been done in
+// its current period and is not paused, like TrackAll. An error is returned if
+// no tracked Habit is in the routine or the Habits cannot be tracked.
+func (t *Tracker) DoRoutine(routine string) error {
+	habits := t.routineHabits(routine)
+	if len(habits) < 1 {
+		return fmt.Errorf("routine '%s' does not exist", routine)
+	}
+	now := t.now()
+	var names []string
+	for _, hbt := range habits {
+		if hbt.doneThisPeriod(now, t.calendar) || hbt.Paused(now) {
+			continue
+		}
+		names = append(names, hbt.Name)
+	}
+	if len(names) < 1 {
+		fmt.Fprintf(t.output, "You've already done every habit in the routine '%s'.\n", routine)
+		return nil
+	}
+	return t.TrackAll(names...)
+}
+
+// PrintRoutines writes each routine with its Habits to the Tracker's output,
+// one routine per line and sorted by name.
+func (t *Tracker) PrintRoutines() {
+	routines := t.routines()
+	if len(routines) < 1 {
+		fmt.Fprintln(t.output, "You haven't added any habits to a routine.")
+		return
+	}
+	for _, routine := range routines {
+		var names []string
+		for _, hbt := range t.routineHabits(routine) {
+			names = append(names, hbt.Name)
+		}
+		fmt.Fprintf(t.output, "%s: %s\n", routine, strings.Join(names, ", "))
+	}
+}
+
+// routines returns the names of the routines of the Tracker's tracked Habits,
+// sorted by name.
+func (t *Tracker) routines() []string {
+	seen := map[string]bool{}
+	var routines []string
+	for _, hbt := range t.sortedHabits(false) {
+		if hbt.Routine != "" && !seen[hbt.Routine] {
+			seen[hbt.Routine] = true
+			routines = append(routines, hbt.Routine)
+		}
+	}
+	sort.Strings(routines)
+	return routines
+}
+
+// routineHabits returns the tracked Habits in the named routine, sorted by
+// name.
+func (t *Tracker) routineHabits(routine string) []Habit {
+	var habits []Habit
+	for _, hbt := range t.sortedHabits(false) {
+		if hbt.Routine == routine {
+			habits = append(habits, hbt)
+		}
+	}
+	return habits
+}
+
+// groupByRoutine returns the given Habits grouped by routine: first the Habits
+// that are not in a routine, under the empty routine name, then the Habits of
+// each routine, with the routines sorted by name. The order of the Habits
+// within each group is kept.
+func groupByRoutine(habits []Habit) (routines []string, groups map[string][]Habit) {
+	groups = map[string][]Habit{}
+	for _, hbt := range habits {
+		if _, ok := groups[hbt.Routine]; !ok {
+			routines = append(routines, hbt.Routine)
+		}
+		groups[hbt.Routine] = append(groups[hbt.Routine], hbt)
+	}
+	sort.Strings(routines)
+	return routines, groups
+}
+
+// quoteNames returns the quoted names of the given Habits.
+func quoteNames(habits []Habit) []string {
+	names := make([]string, len(habits))
+	for i, hbt := range habits {
+		names[i] = fmt.Sprintf("'%s'", hbt.Name)
+	}
+	return names
+}
@@ -0,0 +1,145 @@
+package habit_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTracker_AddToRoutineAndRemoveFromRoutineSetRoutine(t *testing.T) {
+	t.Parallel()
+	store := &memStore{habits: map[string]habit.Habit{
+		"meditation": {Name: "meditation"},
+		"running":    {Name: "running", Routine: "evening"},
+		"stretching": {Name: "stretching"},
+	}}
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.AddToRoutine("morning", "running", "meditation", "stretching")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.RemoveFromRoutine("stretching")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]string{"meditation": "morning", "running": "morning", "stretching": ""} {
+		if got := store.habits[name].Routine; want != got {
+			t.Errorf("want habit '%s' in routine %q, got %q", name, want, got)
+		}
+	}
+	want := "The routine 'morning' is 'meditation', 'running' and 'stretching'.\n" +
+		"'stretching' is no longer in a routine.\n"
+	if got := output.String(); want != got {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestTracker_AddToRoutineReturnsErrorForInvalidInput(t *testing.T) {
+	t.Parallel()
+	store := &memStore{habits: map[string]habit.Habit{
+		"running": {Name: "running"},
+		"smoking": {Name: "smoking", Avoid: true},
+	}}
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(new(bytes.Buffer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string][]string{
+		"empty routine name": {"", "running"},
+		"no habits":          {"morning"},
+		"missing habit":      {"morning", "running", "swimming"},
+		"habit to avoid":     {"morning", "smoking"},
+	}
+	for name, args := range tests {
+		err = tracker.AddToRoutine(args[0], args[1:]...)
+		if err == nil {
+			t.Errorf("%s: want error, got nil", name)
+		}
+	}
+	if store.habits["running"].Routine != "" || store.saves != 0 {
+		t.Error("want no habit changed after errors")
+	}
+}
+
+func TestTracker_DoRoutineTracksHabitsNotDoneYet(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T08:00:00Z")
+	yesterday := time.Date(2024, time.February, 5, 10, 0, 0, 0, time.UTC)
+	today := time.Date(2024, time.February, 6, 7, 0, 0, 0, time.UTC)
+	store := &memStore{habits: map[string]habit.Habit{
+		"meditation": {
+			Name: "meditation", Routine: "morning", CurrentStreak: 1, LongestStreak: 1,
+			LastDone: yesterday, History: []habit.Completion{{At: yesterday}},
+		},
+		"running": {
+			Name: "running", Routine: "morning", CurrentStreak: 1, LongestStreak: 1,
+			LastDone: today, History: []habit.Completion{{At: today}},
+		},
+		"stretching": {Name: "stretching", Routine: "morning", LastDone: yesterday},
+		"reading":    {Name: "reading", Routine: "evening", LastDone: yesterday},
+	}}
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.DoRoutine("morning")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.DoRoutine("morning")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Nice work! You tracked 2 habits: 'meditation' (2-day streak) and 'stretching' (1-day streak).\n" +
+		"You've already done every habit in the routine 'morning'.\n"
+	if got := output.String(); want != got {
+		t.Error(cmp.Diff(want, got))
+	}
+	if got := len(store.habits["running"].History); got != 1 {
+		t.Errorf("want 'running' left with 1 completion, got %d", got)
+	}
+	if got := store.habits["reading"].LastDone; !got.Equal(yesterday) {
+		t.Errorf("want 'reading' left untracked, got last done %v", got)
+	}
+	err = tracker.DoRoutine("bedtime")
+	if err == nil {
+		t.Error("want error for a routine with no habits, got nil")
+	}
+}
+
+func TestTracker_PrintSummaryGroupsHabitsByRoutine(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T08:00:00Z")
+	today := time.Date(2024, time.February, 6, 7, 0, 0, 0, time.UTC)
+	store := &memStore{habits: map[string]habit.Habit{
+		"running":    {Name: "running", Routine: "morning", CurrentStreak: 1, LastDone: today},
+		"meditation": {Name: "meditation", Routine: "morning", CurrentStreak: 1, LastDone: today},
+		"reading":    {Name: "reading", Routine: "evening", CurrentStreak: 1, LastDone: today},
+		"water":      {Name: "water", CurrentStreak: 1, LastDone: today},
+	}}
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.PrintSummary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `You are currently on a 1-day streak for 'water'. Keep it going!
+Routine 'evening':
+  You are currently on a 1-day streak for 'reading'. Keep it going!
+Routine 'morning':
+  You are currently on a 1-day streak for 'meditation'. Keep it going!
+  You are currently on a 1-day streak for 'running'. Keep it going!
+`
+	if got := output.String(); want != got {
+		t.Error(cmp.Diff(want, got))
+	}
+}
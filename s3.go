@@ -0,0 +1,191 @@
+package habit
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// s3Remote is a SyncRemote that keeps the habit data in an object of an S3
+// bucket, or of a bucket in an S3-compatible service, signing its requests
+// with AWS Signature Version 4.
+type s3Remote struct {
+	endpoint     string
+	region       string
+	bucket       string
+	key          string
+	accessKey    string
+	secretKey    string
+	sessionToken string
+	client       *http.Client
+	version      remoteVersion
+}
+
+// newS3Remote returns an s3Remote for the object at the given s3://bucket/key
+// URL, with credentials taken from the environment.
+func newS3Remote(u *url.URL) (*s3Remote, error) {
+	key := strings.TrimPrefix(u.Path, "/")
+	if u.Host == "" || key == "" {
+		return nil, fmt.Errorf("invalid S3 sync remote %q (want s3://bucket/key)", u.String())
+	}
+	r := &s3Remote{
+		endpoint:     strings.TrimSuffix(u.Query().Get("endpoint"), "/"),
+		region:       u.Query().Get("region"),
+		bucket:       u.Host,
+		key:          key,
+		accessKey:    os.Getenv("AWS_ACCESS_KEY_ID"),
+		secretKey:    os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		sessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+		client:       &http.Client{Timeout: syncTimeout},
+	}
+	if r.region == "" {
+		r.region = os.Getenv("AWS_REGION")
+	}
+	if r.region == "" {
+		r.region = "us-east-1"
+	}
+	if r.endpoint == "" {
+		r.endpoint = "https://s3." + r.region + ".amazonaws.com"
+	}
+	if r.accessKey == "" || r.secretKey == "" {
+		return nil, fmt.Errorf("the S3 sync remote needs AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY to be set")
+	}
+	return r, nil
+}
+
+// Pull downloads the object, returning nil if it does not exist yet.
+func (r *s3Remote) Pull() ([]byte, error) {
+	resp, err := r.do(http.MethodGet, nil, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		r.version.observe(resp)
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected response status %s", resp.Status)
+	}
+	r.version.observe(resp)
+	return io.ReadAll(resp.Body)
+}
+
+// Push uploads the given data, replacing the object unless it changed since
+// the last Pull.
+func (r *s3Remote) Push(data []byte) error {
+	resp, err := r.do(http.MethodPut, data, time.Now())
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		r.version.observe(resp)
+		return nil
+	case http.StatusPreconditionFailed:
+		return ErrRemoteChanged
+	}
+	return fmt.Errorf("unexpected response status %s", resp.Status)
+}
+
+// do sends a request with the given method and body for the object, signed as
+// of the given time, using a path-style URL so that buckets of S3-compatible
+// services work too.
+func (r *s3Remote) do(method string, body []byte, now time.Time) (*http.Response, error) {
+	path := "/" + s3Escape(r.bucket) + "/" + s3Escape(r.key)
+	req, err := http.NewRequest(method, r.endpoint+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if method == http.MethodPut {
+		// The condition is not signed, which S3 allows for headers other
+		// than those of the signature.
+		r.version.condition(req)
+	}
+	r.sign(req, path, body, now)
+	return r.client.Do(req)
+}
+
+// sign adds the headers of AWS Signature Version 4 to the given request for
+// the object at the given escaped path, with the given body, as of the given
+// time.
+func (r *s3Remote) sign(req *http.Request, path string, body []byte, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	headers := []string{
+		"host:" + req.URL.Host,
+		"x-amz-content-sha256:" + payloadHash,
+		"x-amz-date:" + amzDate,
+	}
+	if r.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", r.sessionToken)
+		headers = append(headers, "x-amz-security-token:"+r.sessionToken)
+	}
+	var names []string
+	for _, h := range headers {
+		name, _, _ := strings.Cut(h, ":")
+		names = append(names, name)
+	}
+	signedHeaders := strings.Join(names, ";")
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		"",
+		strings.Join(headers, "\n") + "\n",
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := date + "/" + r.region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+	key := []byte("AWS4" + r.secretKey)
+	for _, part := range []string{date, r.region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		r.accessKey, scope, signedHeaders, signature))
+}
+
+// s3Escape escapes every byte of the given object key or bucket name except
+// the unreserved characters and slashes, as required by AWS Signature Version
+// 4.
+func s3Escape(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '.', c == '_', c == '~', c == '/':
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// sha256Hex returns the hex-encoded SHA-256 hash of the given data.
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// hmacSHA256 returns the HMAC-SHA256 of the given message with the given key.
+func hmacSHA256(key []byte, message string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(message))
+	return mac.Sum(nil)
+}
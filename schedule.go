@@ -0,0 +1,126 @@
+package habit
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// weekdayNames maps the names and abbreviations accepted by ParseWeekdays to
+// the days of the week they name.
+var weekdayNames = map[string][]time.Weekday{
+	"sun": {time.Sunday}, "sunday": {time.Sunday},
+	"mon": {time.Monday}, "monday": {time.Monday},
+	"tue": {time.Tuesday}, "tuesday": {time.Tuesday},
+	"wed": {time.Wednesday}, "wednesday": {time.Wednesday},
+	"thu": {time.Thursday}, "thursday": {time.Thursday},
+	"fri": {time.Friday}, "friday": {time.Friday},
+	"sat": {time.Saturday}, "saturday": {time.Saturday},
+	"weekdays": {time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+	"weekends": {time.Saturday, time.Sunday},
+}
+
+// ParseWeekdays accepts a list of days of the week separated by commas or
+// spaces, such as "mon,wed,fri", "Monday Friday", "weekdays" or "weekends",
+// and returns the days in order from Sunday without duplicates. The value
+// "daily" returns no days, meaning every day. An error is returned if a day
+// cannot be parsed.
+func ParseWeekdays(value string) ([]time.Weekday, error) {
+	fields := strings.FieldsFunc(strings.ToLower(value), func(r rune) bool {
+		return r == ',' || r == ' '
+	})
+	if len(fields) == 1 && fields[0] == "daily" {
+		return nil, nil
+	}
+	if len(fields) < 1 {
+		return nil, fmt.Errorf("no days of the week given")
+	}
+	seen := map[time.Weekday]bool{}
+	var days []time.Weekday
+	for _, field := range fields {
+		named, ok := weekdayNames[field]
+		if !ok {
+			return nil, fmt.Errorf("invalid day of the week %q (want a day such as mon or monday, weekdays, weekends or daily)", field)
+		}
+		for _, day := range named {
+			if !seen[day] {
+				seen[day] = true
+				days = append(days, day)
+			}
+		}
+	}
+	sort.Slice(days, func(i, j int) bool {
+		return days[i] < days[j]
+	})
+	if len(days) == 7 {
+		return nil, nil
+	}
+	return days, nil
+}
+
+// SetSchedule sets the days of the week on which the daily Habit with the
+// given name must be done and saves the store. On the other days, the Habit
+// is not due and its streak is not broken by not doing it. No days means every
+// day. An error is returned if the Habit does not exist or is not a daily
+// Habit, or if the store cannot be saved.
+func (t *Tracker) SetSchedule(hbtName string, weekdays []time.Weekday) error {
+	hbt, ok := t.store.Get(hbtName)
+	if !ok {
+		return errHabitNotFound(hbtName)
+	}
+	if len(weekdays) > 0 && hbt.Frequency.Days() != 1 {
+		return fmt.Errorf("habit '%s' is tracked %s; only daily habits can be scheduled on days of the week",
+			hbtName, hbt.Frequency)
+	}
+	hbt.Weekdays = weekdays
+	t.store.Add(hbt)
+	err := t.save()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(t.output, "The habit '%s' is now scheduled %s.\n", hbtName, describeWeekdays(weekdays))
+	return nil
+}
+
+// describeWeekdays returns a description of the given schedule, such as "on
+// Monday, Wednesday and Friday" or "every day".
+func describeWeekdays(weekdays []time.Weekday) string {
+	if len(weekdays) < 1 {
+		return "every day"
+	}
+	names := make([]string, len(weekdays))
+	for i, day := range weekdays {
+		names[i] = day.String()
+	}
+	return "on " + joinList(names, "and")
+}
+
+// scheduled returns true if the Habit must be done on the calendar date of the
+// given timestamp in the given calendar. Habits without Weekdays, and Habits
+// that are not daily, are scheduled every day.
+func (h Habit) scheduled(at time.Time, cal calendar) bool {
+	if len(h.Weekdays) < 1 || h.Frequency.Days() != 1 {
+		return true
+	}
+	weekday := cal.date(at).Weekday()
+	for _, day := range h.Weekdays {
+		if day == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// scheduledDaysBetween returns the number of calendar dates strictly between
+// the dates of the 2 given timestamps on which the Habit is scheduled and not
+// paused.
+func (h Habit) scheduledDaysBetween(from, to time.Time, cal calendar) int {
+	days := 0
+	for start := cal.start(from).AddDate(0, 0, 1); cal.date(start).Before(cal.date(to)); start = start.AddDate(0, 0, 1) {
+		if h.scheduled(start, cal) && h.pausedTime(start, start.AddDate(0, 0, 1)) == 0 {
+			days++
+		}
+	}
+	return days
+}
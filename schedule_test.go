@@ -0,0 +1,145 @@
+package habit_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+var mondayWednesdayFriday = []time.Weekday{time.Monday, time.Wednesday, time.Friday}
+
+func TestParseWeekdays(t *testing.T) {
+	t.Parallel()
+	tests := map[string][]time.Weekday{
+		"mon,wed,fri":       mondayWednesdayFriday,
+		"Friday Monday wed": mondayWednesdayFriday,
+		"weekends":          {time.Sunday, time.Saturday},
+		"weekdays, sat":     {time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday},
+		"weekdays weekends": nil,
+		"daily":             nil,
+	}
+	for value, want := range tests {
+		got, err := habit.ParseWeekdays(value)
+		if err != nil {
+			t.Errorf("%q: %v", value, err)
+			continue
+		}
+		if !cmp.Equal(want, got) {
+			t.Errorf("%q: %s", value, cmp.Diff(want, got))
+		}
+	}
+	for _, value := range []string{"", "funday", "mon,daily"} {
+		_, err := habit.ParseWeekdays(value)
+		if err == nil {
+			t.Errorf("%q: want error, got nil", value)
+		}
+	}
+}
+
+func TestTracker_SetScheduleSetsWeekdaysOfDailyHabit(t *testing.T) {
+	t.Parallel()
+	store := &memStore{habits: map[string]habit.Habit{
+		"gym":     {Name: "gym"},
+		"laundry": {Name: "laundry", Frequency: habit.Weekly},
+	}}
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.SetSchedule("gym", mondayWednesdayFriday)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := store.habits["gym"].Weekdays; !cmp.Equal(mondayWednesdayFriday, got) {
+		t.Error(cmp.Diff(mondayWednesdayFriday, got))
+	}
+	err = tracker.SetSchedule("laundry", mondayWednesdayFriday)
+	if err == nil {
+		t.Error("want error scheduling a weekly habit on days of the week, got nil")
+	}
+	want := "The habit 'gym' is now scheduled on Monday, Wednesday and Friday.\n"
+	if got := output.String(); want != got {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestTracker_TrackKeepsStreakOverUnscheduledDays(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-05T09:00:00Z")
+	friday := time.Date(2024, time.February, 2, 20, 0, 0, 0, time.UTC)
+	store := &memStore{habits: map[string]habit.Habit{
+		"gym": {
+			Name: "gym", CurrentStreak: 3, LongestStreak: 3, LastDone: friday,
+			History: []habit.Completion{{At: friday}}, Weekdays: mondayWednesdayFriday,
+		},
+	}}
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Track("gym")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Nice work: you've done the habit 'gym' for 4 days in a row now.\n"
+	if got := output.String(); want != got {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestTracker_TrackBreaksStreakOverMissedScheduledDay(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-09T09:00:00Z")
+	monday := time.Date(2024, time.February, 5, 20, 0, 0, 0, time.UTC)
+	store := &memStore{habits: map[string]habit.Habit{
+		"gym": {
+			Name: "gym", CurrentStreak: 3, LongestStreak: 3, LastDone: monday,
+			History: []habit.Completion{{At: monday}}, Weekdays: mondayWednesdayFriday,
+		},
+	}}
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(new(bytes.Buffer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Track("gym")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := store.habits["gym"].CurrentStreak; got != 1 {
+		t.Errorf("want streak broken by missing Wednesday, got streak %d", got)
+	}
+}
+
+func TestTracker_DueAndSummarySkipDaysOffSchedule(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-03T09:00:00Z")
+	friday := time.Date(2024, time.February, 2, 20, 0, 0, 0, time.UTC)
+	store := &memStore{habits: map[string]habit.Habit{
+		"gym":     {Name: "gym", CurrentStreak: 3, LongestStreak: 5, LastDone: friday, Weekdays: mondayWednesdayFriday},
+		"reading": {Name: "reading", CurrentStreak: 3, LastDone: friday},
+	}}
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, hbt := range tracker.Due() {
+		names = append(names, hbt.Name)
+	}
+	if want := []string{"reading"}; !cmp.Equal(want, names) {
+		t.Error(cmp.Diff(want, names))
+	}
+	habit.Now = getTimeFunc(t, "2024-02-04T23:00:00Z")
+	err = tracker.PrintSummary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "You are currently on a 3-day streak for 'gym'. Keep it going!\n" +
+		"It's been 2 days since you did 'reading'. Stay positive and get back on it!\n"
+	if got := output.String(); want != got {
+		t.Error(cmp.Diff(want, got))
+	}
+}
@@ -0,0 +1,605 @@
+package habit
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// A Server exposes a Tracker as a JSON REST API, so that habits can be tracked
+// from phones, scripts and other machines, and serves a web dashboard using the
+// API at / and an OpenAPI 3 document describing the API at /openapi.json. It
+// serves the following endpoints under /v1, and for older clients also without
+// the version:
+//
+//	GET    /habits                    summaries of all habits, optionally filtered by ?tag=
+//	GET    /habits/{name}             a single habit with its full history
+//	PUT    /habits/{name}             store a habit as is, replacing any existing one
+//	POST   /habits/{name}/track       track a habit, with an optional {"at", "note"} body
+//	POST   /completions:batch         track {"completions": [{"habit", "at", "note", "id"}]} with a single save
+//	DELETE /habits/{name}             delete a habit
+//	GET    /habits/{name}/stats       statistics for a habit over ?days= (default 30)
+//	GET    /stats                     statistics for all habits over ?days=
+//	GET    /export                    every habit in the ?format= given (default json)
+//	GET    /metrics                   metrics for every habit in the Prometheus text format
+//	POST   /graphql                   a GraphQL query or mutation, also accepted as GET ?query=
+//	POST   /shares                    a read-only share link for {"habits", "days"}
+//	GET    /shared/{token}            summaries of the habits shared by a share link
+//
+// If the Server has a token or users, every request to the API except those
+// for shared habits must carry one of their tokens as a bearer token, and a
+// request carrying a user's token is tied to that user. Requests are handled
+// one at a time, so a Server is safe for concurrent use. If the store was
+// opened with WithLockPerChange, each request takes its lock and reloads it,
+// so that habits changed by other habit processes while the Server runs are
+// kept.
+type Server struct {
+	// tracker is the Tracker whose store and settings are used to handle
+	// requests.
+	tracker *Tracker
+	// token is the bearer token that requests must carry, if any.
+	token string
+	// users holds the token of each user by user name.
+	users map[string]string
+	// stores returns the store of each user, if the users' habits are kept
+	// apart.
+	stores UserStores
+	// userStores caches the stores returned by stores by user name.
+	userStores map[string]Store
+	// limiter limits the rate of each client's requests, if set.
+	limiter *rateLimiter
+	// maxBodySize is the largest request body, in bytes, that is read, or
+	// zero or less if bodies of any size are read.
+	maxBodySize int64
+	// shareSecret is the secret that share tokens are signed with. Sharing
+	// is turned off if it is empty.
+	shareSecret []byte
+	// tlsConfig is the TLS configuration of the gRPC server, if any.
+	tlsConfig *tls.Config
+	// mtx serializes requests to the tracker.
+	mtx sync.Mutex
+}
+
+// serverOption provides a functional option that can be used in the
+// NewServer() function.
+type serverOption func(*Server)
+
+// WithToken returns a serverOption that makes a Server reject requests that
+// do not carry the given token in an "Authorization: Bearer" header. An empty
+// token accepts every request.
+func WithToken(token string) serverOption {
+	return func(s *Server) {
+		s.token = token
+	}
+}
+
+// A UserStores returns the Store holding the habits of the user with the given
+// name, so that a Server can keep each user's habits apart.
+type UserStores func(user string) (Store, error)
+
+// WithUsers returns a serverOption that makes a Server accept the token of each
+// of the given users, given by user name, as a bearer token and tie the
+// requests carrying it to that user. Requests tied to a user read and change
+// the habits in the store that the given UserStores returns for the user, or
+// in the Server's Tracker's store, which every user then shares, if it is nil.
+// Users with an empty token are ignored.
+func WithUsers(tokens map[string]string, stores UserStores) serverOption {
+	return func(s *Server) {
+		s.users = map[string]string{}
+		for user, token := range tokens {
+			if token != "" {
+				s.users[user] = token
+			}
+		}
+		s.stores = stores
+	}
+}
+
+// userContextKey is the key of the user that a request is tied to in its
+// context.
+type userContextKey struct{}
+
+// UserFromContext returns the name of the user that the request with the given
+// context is tied to, and reports whether it is tied to one. Requests are tied
+// to a user when they carry a token given with WithUsers.
+func UserFromContext(ctx context.Context) (string, bool) {
+	user, ok := ctx.Value(userContextKey{}).(string)
+	return user, ok
+}
+
+// NewServer returns a Server that handles requests with the given Tracker,
+// configured with the given options.
+func NewServer(tracker *Tracker, opts ...serverOption) *Server {
+	s := &Server{tracker: tracker, userStores: map[string]Store{}, maxBodySize: DefaultMaxBodySize}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// A trackRequest is the optional body of a request to track a habit.
+type trackRequest struct {
+	// At is the timestamp when the habit was done. It is the current time if
+	// zero.
+	At time.Time `json:"at"`
+	// Note is a freeform note to attach to the completion.
+	Note string `json:"note"`
+}
+
+// A batchRequest is the body of a request to track a batch of completions.
+type batchRequest struct {
+	// Completions are the completions to track.
+	Completions []BatchCompletion `json:"completions"`
+}
+
+// A batchResponse carries the outcome of tracking each completion of a
+// batchRequest, in the order of the request.
+type batchResponse struct {
+	// Results are the outcomes of tracking the completions.
+	Results []BatchResult `json:"results"`
+}
+
+// A messageResponse carries the message written by the Tracker for a request
+// that changed a habit.
+type messageResponse struct {
+	// Message is the message written by the Tracker.
+	Message string `json:"message"`
+}
+
+// An errorResponse describes why a request failed.
+type errorResponse struct {
+	// Error is the error message.
+	Error string `json:"error"`
+}
+
+// A statsResponse carries the statistics of a habit.
+type statsResponse struct {
+	HabitStats
+	// CompletionRate is the percentage of periods within the window in which
+	// the habit was done.
+	CompletionRate float64 `json:"completion_rate"`
+	// BestWeekday is the day of the week on which the habit has been done
+	// most often.
+	BestWeekday string `json:"best_weekday,omitempty"`
+	// WorstWeekday is the day of the week on which the habit has been done
+	// least often.
+	WorstWeekday string `json:"worst_weekday,omitempty"`
+}
+
+// errNotFound is returned by routes that do not match any endpoint.
+var errNotFound = errors.New("not found")
+
+// An endpoint handles a request and returns the response status code with
+// either a body to encode as JSON or an error.
+type endpoint func(r *http.Request) (int, any, error)
+
+// ServeHTTP routes the given request to the endpoint that handles it and logs
+// the request with the Tracker's logger. Requests are authenticated, rate
+// limited and have their bodies limited in size before waiting for the
+// requests ahead of them.
+func (s *Server) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	w := &statusRecorder{ResponseWriter: rw, status: http.StatusOK}
+	defer func() {
+		attrs := []any{"method", r.Method, "path", r.URL.Path, "status", w.status, "duration", time.Since(start)}
+		if user, ok := UserFromContext(r.Context()); ok {
+			attrs = append(attrs, "user", user)
+		}
+		s.tracker.logger.InfoContext(r.Context(), "request handled", attrs...)
+	}()
+	if s.serveDashboard(w, r) || s.serveOpenAPI(w, r) || s.serveShared(w, r) {
+		return
+	}
+	if s.limiter != nil && s.limiter.exhausted(authKey(r.RemoteAddr)) {
+		w.Header().Set("Retry-After", strconv.Itoa(retryAfter(s.limiter.limit)))
+		writeJSON(w, http.StatusTooManyRequests, errorResponse{Error: "too many failed authentication attempts"})
+		return
+	}
+	user, ok := s.authenticate(r.Header.Get("Authorization"))
+	if !ok {
+		if s.limiter != nil {
+			s.limiter.allow(authKey(r.RemoteAddr))
+		}
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		writeJSON(w, http.StatusUnauthorized, errorResponse{Error: "missing or invalid token"})
+		return
+	}
+	if user != "" {
+		r = r.WithContext(context.WithValue(r.Context(), userContextKey{}, user))
+	}
+	if s.limiter != nil && !s.limiter.allow(s.rateKey(user, r.RemoteAddr)) {
+		w.Header().Set("Retry-After", strconv.Itoa(retryAfter(s.limiter.limit)))
+		writeJSON(w, http.StatusTooManyRequests, errorResponse{Error: "rate limit exceeded"})
+		return
+	}
+	if s.maxBodySize > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, s.maxBodySize)
+	}
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	t, err := s.userTracker(r.Context())
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: err.Error()})
+		return
+	}
+	unlock, err := lockChange(t.store)
+	if err != nil {
+		writeJSON(w, lockErrorStatus(err), errorResponse{Error: err.Error()})
+		return
+	}
+	defer unlock()
+	parts, ok := pathParts(r.URL)
+	if !ok {
+		writeJSON(w, http.StatusNotFound, errorResponse{Error: errNotFound.Error()})
+		return
+	}
+	if parts[0] == apiVersion {
+		parts = parts[1:]
+	}
+	switch {
+	case len(parts) == 1 && parts[0] == "habits":
+		s.handle(w, r, map[string]endpoint{
+			http.MethodGet: func(r *http.Request) (int, any, error) {
+				return s.listHabits(t, r)
+			},
+		})
+	case len(parts) == 1 && parts[0] == "stats":
+		s.handle(w, r, map[string]endpoint{
+			http.MethodGet: func(r *http.Request) (int, any, error) {
+				return s.stats(t, r, "")
+			},
+		})
+	case len(parts) == 1 && parts[0] == "completions:batch":
+		s.handle(w, r, map[string]endpoint{
+			http.MethodPost: func(r *http.Request) (int, any, error) {
+				return s.trackBatch(t, r)
+			},
+		})
+	case len(parts) == 1 && parts[0] == "export":
+		s.export(t, w, r)
+	case len(parts) == 1 && parts[0] == "metrics":
+		s.metrics(t, w, r)
+	case len(parts) == 1 && parts[0] == "graphql":
+		s.graphql(t, w, r)
+	case len(parts) == 1 && parts[0] == "shares":
+		s.handle(w, r, map[string]endpoint{
+			http.MethodPost: func(r *http.Request) (int, any, error) {
+				return s.createShare(t, r)
+			},
+		})
+	case len(parts) == 2 && parts[0] == "habits":
+		name := parts[1]
+		s.handle(w, r, map[string]endpoint{
+			http.MethodGet: func(*http.Request) (int, any, error) {
+				return s.getHabit(t, name)
+			},
+			http.MethodPut: func(r *http.Request) (int, any, error) {
+				return s.putHabit(t, r, name)
+			},
+			http.MethodDelete: func(r *http.Request) (int, any, error) {
+				return s.deleteHabit(t, r, name)
+			},
+		})
+	case len(parts) == 3 && parts[0] == "habits" && parts[2] == "track":
+		s.handle(w, r, map[string]endpoint{
+			http.MethodPost: func(r *http.Request) (int, any, error) {
+				return s.trackHabit(t, r, parts[1])
+			},
+		})
+	case len(parts) == 3 && parts[0] == "habits" && parts[2] == "stats":
+		s.handle(w, r, map[string]endpoint{
+			http.MethodGet: func(r *http.Request) (int, any, error) {
+				return s.stats(t, r, parts[1])
+			},
+		})
+	default:
+		writeJSON(w, http.StatusNotFound, errorResponse{Error: errNotFound.Error()})
+	}
+}
+
+// pathParts splits the given URL's path into unescaped segments, so that
+// habit names may contain slashes, and reports whether the path is valid.
+func pathParts(u *url.URL) ([]string, bool) {
+	parts := strings.Split(strings.Trim(u.EscapedPath(), "/"), "/")
+	for i, part := range parts {
+		p, err := url.PathUnescape(part)
+		if err != nil || p == "" {
+			return nil, false
+		}
+		parts[i] = p
+	}
+	return parts, true
+}
+
+// authenticate reports whether the given Authorization header value carries
+// the Server's token or the token of one of its users as a bearer token, and
+// returns the name of the user whose token it carries, if any. Every request
+// is authorized if the Server has neither a token nor users.
+func (s *Server) authenticate(authorization string) (string, bool) {
+	if s.token == "" && len(s.users) == 0 {
+		return "", true
+	}
+	got, ok := strings.CutPrefix(authorization, "Bearer ")
+	if !ok {
+		return "", false
+	}
+	if s.token != "" && subtle.ConstantTimeCompare([]byte(got), []byte(s.token)) == 1 {
+		return "", true
+	}
+	for user, token := range s.users {
+		if subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1 {
+			return user, true
+		}
+	}
+	return "", false
+}
+
+// userTracker returns the Tracker that handles the request with the given
+// context: a copy of the Server's Tracker using the store of the user that the
+// request is tied to, if the Server keeps its users' habits apart, and
+// otherwise the Server's Tracker. The caller must hold s.mtx.
+func (s *Server) userTracker(ctx context.Context) (*Tracker, error) {
+	user, ok := UserFromContext(ctx)
+	if !ok || s.stores == nil {
+		return s.tracker, nil
+	}
+	store, ok := s.userStores[user]
+	if !ok {
+		var err error
+		store, err = s.stores(user)
+		if err != nil {
+			return nil, fmt.Errorf("error opening store of user '%s': %w", user, err)
+		}
+		s.userStores[user] = store
+	}
+	return s.tracker.withStore(store), nil
+}
+
+// lockErrorStatus returns the status code of the response to a request for
+// which the store cannot be locked with the given error: 503 Service
+// Unavailable if another habit process holds the lock, and 500 Internal Server
+// Error otherwise.
+func lockErrorStatus(err error) int {
+	if errors.Is(err, ErrStoreLocked) {
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
+
+// handle calls the endpoint for the request's method, and writes its result
+// or error to w as JSON.
+func (s *Server) handle(w http.ResponseWriter, r *http.Request, endpoints map[string]endpoint) {
+	ep, ok := endpoints[r.Method]
+	if !ok {
+		var allowed []string
+		for method := range endpoints {
+			allowed = append(allowed, method)
+		}
+		sort.Strings(allowed)
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		writeJSON(w, http.StatusMethodNotAllowed, errorResponse{
+			Error: fmt.Sprintf("method %s not allowed", r.Method),
+		})
+		return
+	}
+	status, body, err := ep(r)
+	if err != nil {
+		writeJSON(w, status, errorResponse{Error: err.Error()})
+		return
+	}
+	writeJSON(w, status, body)
+}
+
+// listHabits handles GET /habits.
+func (s *Server) listHabits(t *Tracker, r *http.Request) (int, any, error) {
+	return http.StatusOK, t.Summarize(r.URL.Query()["tag"]...), nil
+}
+
+// getHabit handles GET /habits/{name}.
+func (s *Server) getHabit(t *Tracker, name string) (int, any, error) {
+	hbt, ok := t.store.Get(name)
+	if !ok {
+		return http.StatusNotFound, nil, errHabitNotFound(name)
+	}
+	return http.StatusOK, hbt, nil
+}
+
+// trackHabit handles POST /habits/{name}/track. A request with an
+// Idempotency-Key header is tracked once however often it is retried. A
+// completion that cannot be tracked, such as one in the future or of an
+// archived habit, is a bad request, while a store that cannot be locked or
+// saved is a server error.
+func (s *Server) trackHabit(t *Tracker, r *http.Request, name string) (int, any, error) {
+	var req trackRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return bodyErrorStatus(err), nil, fmt.Errorf("invalid request body: %w", err)
+	}
+	output := new(bytes.Buffer)
+	t = t.withOutput(output)
+	switch id := r.Header.Get("Idempotency-Key"); {
+	case id != "":
+		err = t.TrackOnceContext(r.Context(), name, id, req.At, req.Note)
+	case req.At.IsZero() && req.Note == "":
+		err = t.TrackContext(r.Context(), name)
+	case req.At.IsZero():
+		err = t.TrackNoteContext(r.Context(), name, t.now(), req.Note)
+	default:
+		err = t.TrackNoteContext(r.Context(), name, req.At, req.Note)
+	}
+	if err != nil {
+		// A habit that still cannot be tracked failed validation rather
+		// than its store.
+		if errors.Is(err, ErrFutureTimestamp) || t.checkTrackable(name, t.now()) != nil {
+			return http.StatusBadRequest, nil, err
+		}
+		return lockErrorStatus(err), nil, err
+	}
+	return http.StatusOK, messageResponse{Message: strings.TrimSpace(output.String())}, nil
+}
+
+// trackBatch handles POST /completions:batch, which tracks the completions in
+// the request body with a single store save, replying with the outcome of
+// each even if some cannot be tracked.
+func (s *Server) trackBatch(t *Tracker, r *http.Request) (int, any, error) {
+	var req batchRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return bodyErrorStatus(err), nil, fmt.Errorf("invalid request body: %w", err)
+	}
+	if len(req.Completions) == 0 {
+		return http.StatusBadRequest, nil, errors.New("no completions to track")
+	}
+	results, err := t.withOutput(io.Discard).TrackBatchContext(r.Context(), req.Completions)
+	if err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+	return http.StatusOK, batchResponse{Results: results}, nil
+}
+
+// putHabit handles PUT /habits/{name}, which stores the habit in the request
+// body as is, replacing any existing habit with the same name. A habit is
+// checked like an imported one, and named after the path if it has no name.
+func (s *Server) putHabit(t *Tracker, r *http.Request, name string) (int, any, error) {
+	var hbt Habit
+	err := json.NewDecoder(r.Body).Decode(&hbt)
+	if err != nil {
+		return bodyErrorStatus(err), nil, fmt.Errorf("invalid request body: %w", err)
+	}
+	hbt, err = checkHabit(name, hbt)
+	if err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+	t.store.Add(hbt)
+	err = t.saveContext(r.Context())
+	if err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+	return http.StatusOK, hbt, nil
+}
+
+// deleteHabit handles DELETE /habits/{name}.
+func (s *Server) deleteHabit(t *Tracker, r *http.Request, name string) (int, any, error) {
+	_, ok := t.store.Get(name)
+	if !ok {
+		return http.StatusNotFound, nil, errHabitNotFound(name)
+	}
+	output := new(bytes.Buffer)
+	err := t.withOutput(output).DeleteContext(r.Context(), name)
+	if err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+	return http.StatusOK, messageResponse{Message: strings.TrimSpace(output.String())}, nil
+}
+
+// stats handles GET /stats and GET /habits/{name}/stats.
+func (s *Server) stats(t *Tracker, r *http.Request, name string) (int, any, error) {
+	window := DefaultStatsWindow
+	if days := r.URL.Query().Get("days"); days != "" {
+		var err error
+		window, err = strconv.Atoi(days)
+		if err != nil {
+			return http.StatusBadRequest, nil, fmt.Errorf("invalid number of days %q", days)
+		}
+	}
+	if name != "" {
+		_, ok := t.store.Get(name)
+		if !ok {
+			return http.StatusNotFound, nil, errHabitNotFound(name)
+		}
+	}
+	stats, err := t.Stats(name, window)
+	if err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+	resp := []statsResponse{}
+	for _, st := range stats {
+		sr := statsResponse{HabitStats: st, CompletionRate: st.CompletionRate()}
+		if st.Completions > 0 {
+			sr.BestWeekday = st.BestWeekday().String()
+			sr.WorstWeekday = st.WorstWeekday().String()
+		}
+		resp = append(resp, sr)
+	}
+	if name != "" {
+		return http.StatusOK, resp[0], nil
+	}
+	return http.StatusOK, resp, nil
+}
+
+// export handles GET /export, which writes every habit in the format given by
+// ?format= (default json), as the export command does.
+func (s *Server) export(t *Tracker, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeJSON(w, http.StatusMethodNotAllowed, errorResponse{
+			Error: fmt.Sprintf("method %s not allowed", r.Method),
+		})
+		return
+	}
+	format := FormatJSON
+	if name := r.URL.Query().Get("format"); name != "" {
+		var err error
+		format, err = ParseFormat(name)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+			return
+		}
+	}
+	buf := new(bytes.Buffer)
+	err := t.Export(buf, format)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: err.Error()})
+		return
+	}
+	switch format {
+	case FormatJSON:
+		w.Header().Set("Content-Type", "application/json")
+	case FormatCSV:
+		w.Header().Set("Content-Type", "text/csv")
+	case FormatICS:
+		w.Header().Set("Content-Type", "text/calendar")
+	default:
+		w.Header().Set("Content-Type", "application/octet-stream")
+	}
+	w.Write(buf.Bytes())
+}
+
+// metrics handles GET /metrics, which writes the metrics of every habit in the
+// Prometheus text exposition format for scraping.
+func (s *Server) metrics(t *Tracker, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeJSON(w, http.StatusMethodNotAllowed, errorResponse{
+			Error: fmt.Sprintf("method %s not allowed", r.Method),
+		})
+		return
+	}
+	buf := new(bytes.Buffer)
+	err := t.WriteMetrics(buf)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: err.Error()})
+		return
+	}
+	w.Header().Set("Content-Type", metricsContentType)
+	w.Write(buf.Bytes())
+}
+
+// writeJSON writes the given body to w as JSON with the given status code.
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
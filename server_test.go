@@ -0,0 +1,747 @@
+package habit_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+func newTestServer(t *testing.T, habits ...habit.Habit) (*httptest.Server, habit.Store) {
+	t.Helper()
+	store, err := habit.OpenStore(t.TempDir() + "/test.store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, hbt := range habits {
+		store.Add(hbt)
+	}
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(new(bytes.Buffer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(habit.NewServer(tracker, habit.WithShareSecret(testShareSecret)))
+	t.Cleanup(srv.Close)
+	return srv, store
+}
+
+func TestServer_GetHabitsReturnsSummariesOfAllHabits(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T13:00:00Z")
+	lastDone := habit.Now().Add(-time.Hour)
+	srv, _ := newTestServer(t,
+		habit.Habit{Name: "reading", CurrentStreak: 1, LongestStreak: 1, LastDone: lastDone},
+		habit.Habit{Name: "programming", CurrentStreak: 2, LongestStreak: 2, LastDone: lastDone},
+	)
+	resp, err := http.Get(srv.URL + "/habits")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("want status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	var summaries []habit.HabitSummary
+	err = json.NewDecoder(resp.Body).Decode(&summaries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, s := range summaries {
+		got = append(got, s.Name)
+	}
+	want := []string{"programming", "reading"}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestServer_PostTrackTracksHabitAndReturnsMessage(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T13:00:00Z")
+	srv, store := newTestServer(t)
+	resp, err := http.Post(srv.URL+"/habits/programming/track", "application/json",
+		strings.NewReader(`{"note": "wrote tests"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("want status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	var body struct{ Message string }
+	err = json.NewDecoder(resp.Body).Decode(&body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantMsg := "Congratulations on starting your new habit 'programming'! Don't forget to do it again."
+	if wantMsg != body.Message {
+		t.Errorf("want message %q, got %q", wantMsg, body.Message)
+	}
+	hbt, ok := store.Get("programming")
+	if !ok {
+		t.Fatal("want habit 'programming' to be stored")
+	}
+	want := []habit.Completion{{At: habit.Now(), Note: "wrote tests"}}
+	if !cmp.Equal(want, hbt.History) {
+		t.Error(cmp.Diff(want, hbt.History))
+	}
+}
+
+func TestServer_PostTrackWithIdempotencyKeyTracksHabitOnce(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T13:00:00Z")
+	srv, store := newTestServer(t)
+	for i := 0; i < 2; i++ {
+		req, err := http.NewRequest(http.MethodPost, srv.URL+"/v1/habits/programming/track", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Idempotency-Key", "tap-1")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("want status %d, got %d", http.StatusOK, resp.StatusCode)
+		}
+	}
+	hbt, ok := store.Get("programming")
+	if !ok {
+		t.Fatal("want habit 'programming' to be stored")
+	}
+	if len(hbt.History) != 1 {
+		t.Errorf("want 1 completion, got %d", len(hbt.History))
+	}
+}
+
+func TestServer_PostBatchTracksCompletionsAndReturnsResults(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T13:00:00Z")
+	srv, store := newTestServer(t)
+	body := `{"completions": [
+		{"habit": "programming", "at": "2024-02-06T09:00:00Z", "note": "fixed a bug"},
+		{"habit": "programming", "at": "2024-02-05T12:00:00Z"},
+		{"habit": "reading", "at": "2024-02-07T09:00:00Z"}
+	]}`
+	resp, err := http.Post(srv.URL+"/v1/completions:batch", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("want status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	var got struct {
+		Results []habit.BatchResult `json:"results"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tracked []string
+	for _, res := range got.Results {
+		tracked = append(tracked, res.Habit+" "+strconv.FormatBool(res.Error == ""))
+	}
+	wantTracked := []string{"programming true", "programming true", "reading false"}
+	if !cmp.Equal(wantTracked, tracked) {
+		t.Error(cmp.Diff(wantTracked, tracked))
+	}
+	hbt, ok := store.Get("programming")
+	if !ok {
+		t.Fatal("want habit 'programming' to be stored")
+	}
+	if hbt.CurrentStreak != 2 || len(hbt.History) != 2 {
+		t.Errorf("want streak of 2 with 2 completions, got streak of %d with %d completions", hbt.CurrentStreak, len(hbt.History))
+	}
+	if _, ok := store.Get("reading"); ok {
+		t.Error("want habit 'reading' done in the future not to be stored")
+	}
+}
+
+func TestServer_DeleteRemovesHabit(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T13:00:00Z")
+	srv, store := newTestServer(t, habit.Habit{Name: "programming", LastDone: habit.Now()})
+	req, err := http.NewRequest(http.MethodDelete, srv.URL+"/habits/programming", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("want status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	_, ok := store.Get("programming")
+	if ok {
+		t.Error("want habit 'programming' to be deleted")
+	}
+}
+
+func TestServer_GetStatsReturnsStatsForHabit(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T20:00:00Z")
+	lastDone := time.Date(2024, time.February, 6, 13, 0, 0, 0, time.UTC)
+	srv, _ := newTestServer(t, habit.Habit{
+		Name:          "programming",
+		CurrentStreak: 1,
+		LongestStreak: 1,
+		LastDone:      lastDone,
+		History:       []habit.Completion{{At: lastDone}},
+	})
+	resp, err := http.Get(srv.URL + "/habits/programming/stats?days=10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("want status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	var got map[string]any
+	err = json.NewDecoder(resp.Body).Decode(&got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"name":              "programming",
+		"frequency":         float64(0),
+		"completions":       float64(1),
+		"window":            float64(10),
+		"periods_done":      float64(1),
+		"periods_in_window": float64(10),
+		"current_streak":    float64(1),
+		"longest_streak":    float64(1),
+		"average_streak":    float64(1),
+		"strength":          5.2,
+		"weekdays":          []any{0.0, 0.0, 1.0, 0.0, 0.0, 0.0, 0.0},
+		"completion_rate":   float64(10),
+		"best_weekday":      "Tuesday",
+		"worst_weekday":     "Monday",
+	}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestServer_ReturnsErrorStatusForInvalidRequests(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T13:00:00Z")
+	srv, _ := newTestServer(t)
+	testCases := map[string]struct {
+		method string
+		path   string
+		want   int
+	}{
+		"missing habit":     {method: http.MethodGet, path: "/habits/missing", want: http.StatusNotFound},
+		"delete missing":    {method: http.MethodDelete, path: "/habits/missing", want: http.StatusNotFound},
+		"stats for missing": {method: http.MethodGet, path: "/habits/missing/stats", want: http.StatusNotFound},
+		"unknown path":      {method: http.MethodGet, path: "/unknown", want: http.StatusNotFound},
+		"wrong method":      {method: http.MethodGet, path: "/habits/programming/track", want: http.StatusMethodNotAllowed},
+		"invalid days":      {method: http.MethodGet, path: "/stats?days=x", want: http.StatusBadRequest},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			req, err := http.NewRequest(tc.method, srv.URL+tc.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if tc.want != resp.StatusCode {
+				t.Errorf("want status %d, got %d", tc.want, resp.StatusCode)
+			}
+			var body struct{ Error string }
+			err = json.NewDecoder(resp.Body).Decode(&body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if body.Error == "" {
+				t.Error("want error message in response body")
+			}
+		})
+	}
+}
+
+func TestServer_PutStoresHabitAsIs(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T13:00:00Z")
+	srv, store := newTestServer(t)
+	body := `{"name": "programming", "current_streak": 4, "longest_streak": 5, "last_done": "2024-02-06T12:00:00Z"}`
+	req, err := http.NewRequest(http.MethodPut, srv.URL+"/habits/programming", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("want status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	want := habit.Habit{
+		Name:          "programming",
+		CurrentStreak: 4,
+		LongestStreak: 5,
+		LastDone:      time.Date(2024, time.February, 6, 12, 0, 0, 0, time.UTC),
+	}
+	got, ok := store.Get("programming")
+	if !ok {
+		t.Fatal("want habit 'programming' to be stored")
+	}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestServer_PutRejectsInvalidHabitsWithBadRequest(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T13:00:00Z")
+	srv, store := newTestServer(t)
+	testCases := map[string]string{
+		"habit named differently": `{"name": "reading"}`,
+		"negative target":         `{"name": "programming", "target": -1}`,
+		"unknown frequency":       `{"name": "programming", "frequency": -7}`,
+	}
+	for name, body := range testCases {
+		t.Run(name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPut, srv.URL+"/habits/programming", strings.NewReader(body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusBadRequest {
+				t.Errorf("want status %d, got %d", http.StatusBadRequest, resp.StatusCode)
+			}
+			if got := store.All(); len(got) != 0 {
+				t.Errorf("want nothing stored, got %+v", got)
+			}
+		})
+	}
+}
+
+func TestServer_PutNamesUnnamedHabitAfterPath(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T13:00:00Z")
+	srv, store := newTestServer(t)
+	req, err := http.NewRequest(http.MethodPut, srv.URL+"/habits/programming", strings.NewReader(`{"current_streak": 1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("want status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	want := habit.Habit{Name: "programming", CurrentStreak: 1}
+	got, ok := store.Get("programming")
+	if !ok {
+		t.Fatal("want habit 'programming' to be stored")
+	}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestServer_RejectsRequestsWithoutToken(t *testing.T) {
+	store, err := habit.OpenStore(t.TempDir() + "/test.store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(new(bytes.Buffer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(habit.NewServer(tracker, habit.WithToken("secret")))
+	defer srv.Close()
+	testCases := map[string]struct {
+		header string
+		want   int
+	}{
+		"no token":    {header: "", want: http.StatusUnauthorized},
+		"wrong token": {header: "Bearer wrong", want: http.StatusUnauthorized},
+		"valid token": {header: "Bearer secret", want: http.StatusOK},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, srv.URL+"/habits", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.header != "" {
+				req.Header.Set("Authorization", tc.header)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if tc.want != resp.StatusCode {
+				t.Errorf("want status %d, got %d", tc.want, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestServer_RejectsRequestsBeyondRateLimitOfToken(t *testing.T) {
+	store, err := habit.OpenStore(t.TempDir() + "/test.store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(new(bytes.Buffer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(habit.NewServer(tracker, habit.WithToken("secret"), habit.WithRateLimit(0.01, 2)))
+	defer srv.Close()
+	var got []int
+	for i := 0; i < 3; i++ {
+		req, err := http.NewRequest(http.MethodGet, srv.URL+"/habits", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer secret")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		got = append(got, resp.StatusCode)
+		if resp.StatusCode == http.StatusTooManyRequests && resp.Header.Get("Retry-After") != "100" {
+			t.Errorf("want Retry-After 100, got %q", resp.Header.Get("Retry-After"))
+		}
+	}
+	want := []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestServer_RejectsBodiesLargerThanMaxBodySize(t *testing.T) {
+	store, err := habit.OpenStore(t.TempDir() + "/test.store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(new(bytes.Buffer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(habit.NewServer(tracker, habit.WithMaxBodySize(32)))
+	defer srv.Close()
+	testCases := map[string]struct {
+		note string
+		want int
+	}{
+		"small body": {note: "a chapter", want: http.StatusOK},
+		"large body": {note: strings.Repeat("a chapter ", 10), want: http.StatusRequestEntityTooLarge},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			body := `{"note": "` + tc.note + `"}`
+			resp, err := http.Post(srv.URL+"/habits/reading/track", "application/json", strings.NewReader(body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if tc.want != resp.StatusCode {
+				t.Errorf("want status %d, got %d", tc.want, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestServer_ServesDashboardWithoutToken(t *testing.T) {
+	store, err := habit.OpenStore(t.TempDir() + "/test.store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(new(bytes.Buffer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(habit.NewServer(tracker, habit.WithToken("secret")))
+	defer srv.Close()
+	testCases := map[string]struct {
+		contentType string
+		contains    string
+	}{
+		"/":          {contentType: "text/html", contains: "<title>Habits</title>"},
+		"/app.js":    {contentType: "javascript", contains: "/track"},
+		"/style.css": {contentType: "text/css", contains: ".heatmap"},
+	}
+	for path, tc := range testCases {
+		resp, err := http.Get(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("%s: want status %d, got %d", path, http.StatusOK, resp.StatusCode)
+		}
+		if !strings.Contains(resp.Header.Get("Content-Type"), tc.contentType) {
+			t.Errorf("%s: want content type %s, got %q", path, tc.contentType, resp.Header.Get("Content-Type"))
+		}
+		if !strings.Contains(string(body), tc.contains) {
+			t.Errorf("%s: want body containing %q, got %q", path, tc.contains, body)
+		}
+	}
+	resp, err := http.Get(srv.URL + "/habits")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("want the API to still require a token, got status %d", resp.StatusCode)
+	}
+}
+
+func TestServer_GetMetricsReturnsPrometheusMetrics(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T13:00:00Z")
+	lastDone := habit.Now().Add(-time.Hour)
+	srv, _ := newTestServer(t,
+		habit.Habit{Name: "reading", CurrentStreak: 7, LongestStreak: 7, LastDone: lastDone},
+	)
+	resp, err := http.Get(srv.URL + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("want status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	if got := resp.Header.Get("Content-Type"); !strings.HasPrefix(got, "text/plain; version=0.0.4") {
+		t.Errorf("want Prometheus text content type, got %q", got)
+	}
+	var body bytes.Buffer
+	body.ReadFrom(resp.Body)
+	want := `habit_current_streak{habit="reading",frequency="daily"} 7` + "\n"
+	if !strings.Contains(body.String(), want) {
+		t.Errorf("want metrics to contain %q, got %q", want, body.String())
+	}
+}
+
+func TestServer_ScopesRequestsToUserOfToken(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T13:00:00Z")
+	dir := t.TempDir()
+	tracker, err := habit.NewTracker(habit.WithStore(&memStore{habits: map[string]habit.Habit{}}), habit.WithOutput(new(bytes.Buffer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var users []string
+	stores := func(user string) (habit.Store, error) {
+		users = append(users, user)
+		return habit.OpenStore(dir + "/" + user + ".store")
+	}
+	srv := httptest.NewServer(habit.NewServer(tracker,
+		habit.WithUsers(map[string]string{"alice": "alice-token", "bob": "bob-token"}, stores)))
+	defer srv.Close()
+	do := func(method, path, token string) []habit.HabitSummary {
+		t.Helper()
+		req, err := http.NewRequest(method, srv.URL+path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("want status %d, got %d", http.StatusOK, resp.StatusCode)
+		}
+		var summaries []habit.HabitSummary
+		if method == http.MethodGet {
+			err = json.NewDecoder(resp.Body).Decode(&summaries)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		return summaries
+	}
+	do(http.MethodPost, "/habits/reading/track", "alice-token")
+	do(http.MethodPost, "/habits/running/track", "bob-token")
+	do(http.MethodPost, "/habits/cycling/track", "bob-token")
+	var got []string
+	for _, s := range do(http.MethodGet, "/habits", "alice-token") {
+		got = append(got, s.Name)
+	}
+	want := []string{"reading"}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+	want = []string{"alice", "bob"}
+	if !cmp.Equal(want, users) {
+		t.Errorf("want each user's store opened once, got %v", users)
+	}
+}
+
+func TestServer_LocksStorePerRequestKeepingChangesOfOtherProcesses(t *testing.T) {
+	t.Parallel()
+	path := t.TempDir() + "/test.store"
+	store, err := habit.OpenStore(path, habit.WithLockPerChange(100*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(new(bytes.Buffer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(habit.NewServer(tracker))
+	defer srv.Close()
+	track := func(name string) int {
+		t.Helper()
+		resp, err := http.Post(srv.URL+"/habits/"+name+"/track", "application/json", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	if got := track("reading"); got != http.StatusOK {
+		t.Fatalf("want status %d, got %d", http.StatusOK, got)
+	}
+	// Another habit process, such as 'habit track', saves a change while
+	// the server is running.
+	other, err := habit.OpenStore(path, habit.WithLock(0))
+	if err != nil {
+		t.Fatalf("want store unlocked between requests, got %v", err)
+	}
+	if got := track("running"); got != http.StatusServiceUnavailable {
+		t.Errorf("want status %d while another process holds the lock, got %d", http.StatusServiceUnavailable, got)
+	}
+	other.Add(habit.Habit{Name: "cycling"})
+	err = other.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other.Close()
+	if got := track("running"); got != http.StatusOK {
+		t.Fatalf("want status %d, got %d", http.StatusOK, got)
+	}
+	reopened, err := habit.OpenStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, hbt := range reopened.All() {
+		got = append(got, hbt.Name)
+	}
+	sort.Strings(got)
+	want := []string{"cycling", "reading", "running"}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestServer_RejectsRequestsAfterTooManyFailedAuthenticationAttempts(t *testing.T) {
+	t.Parallel()
+	store := &memStore{habits: map[string]habit.Habit{}}
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(new(bytes.Buffer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := habit.NewServer(tracker, habit.WithToken("secret"), habit.WithRateLimit(0.01, 2))
+	get := func(remoteAddr, token string) int {
+		req := httptest.NewRequest(http.MethodGet, "/v1/habits", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	got := []int{
+		get("192.0.2.1:1234", "guess1"),
+		get("192.0.2.1:1234", "guess2"),
+		// Once its guesses are used up, not even the right token is
+		// checked.
+		get("192.0.2.1:1234", "secret"),
+		get("192.0.2.2:1234", "secret"),
+	}
+	want := []int{http.StatusUnauthorized, http.StatusUnauthorized, http.StatusTooManyRequests, http.StatusOK}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestServer_ForgetsLeastRecentClientsBeyondMaxRateLimiters(t *testing.T) {
+	t.Parallel()
+	store := &memStore{habits: map[string]habit.Habit{}}
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(new(bytes.Buffer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := habit.NewServer(tracker, habit.WithRateLimit(0.01, 1))
+	get := func(remoteAddr string) int {
+		req := httptest.NewRequest(http.MethodGet, "/v1/habits", nil)
+		req.RemoteAddr = remoteAddr
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	get("192.0.2.1:1234")
+	if got := get("192.0.2.1:1234"); got != http.StatusTooManyRequests {
+		t.Fatalf("want status %d, got %d", http.StatusTooManyRequests, got)
+	}
+	// Requests from many other addresses, each of which uses up its bucket,
+	// push the first address's bucket out rather than growing without
+	// bound.
+	for i := 0; i < 1024; i++ {
+		get(fmt.Sprintf("198.51.%d.%d:1234", i/256, i%256))
+	}
+	if got := get("192.0.2.1:1234"); got != http.StatusOK {
+		t.Errorf("want status %d once the bucket is forgotten, got %d", http.StatusOK, got)
+	}
+}
+
+func TestServer_TrackReturnsBadRequestOnlyForCompletionsThatCannotBeTracked(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T13:00:00Z")
+	srv, _ := newTestServer(t, habit.Habit{Name: "knitting", Archived: true})
+	track := func(srv *httptest.Server, name, body string) int {
+		t.Helper()
+		resp, err := http.Post(srv.URL+"/habits/"+name+"/track", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	if got := track(srv, "knitting", ""); got != http.StatusBadRequest {
+		t.Errorf("want status %d tracking archived habit, got %d", http.StatusBadRequest, got)
+	}
+	if got := track(srv, "reading", `{"at": "2024-02-07T13:00:00Z"}`); got != http.StatusBadRequest {
+		t.Errorf("want status %d tracking completion in the future, got %d", http.StatusBadRequest, got)
+	}
+	// The store's directory does not exist, so it cannot be saved.
+	store, err := habit.OpenStore(t.TempDir() + "/missing/test.store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(new(bytes.Buffer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	unsaved := httptest.NewServer(habit.NewServer(tracker))
+	defer unsaved.Close()
+	if got := track(unsaved, "reading", ""); got != http.StatusInternalServerError {
+		t.Errorf("want status %d when the store cannot be saved, got %d", http.StatusInternalServerError, got)
+	}
+}
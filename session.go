@@ -0,0 +1,170 @@
+package habit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+)
+
+// minutesUnit is the unit of the target of a duration habit, whose amounts are
+// the minutes timed with StartSession and StopSession.
+const minutesUnit = "minutes"
+
+// A Session is a span of time spent on a duration habit, timed with
+// StartSession and StopSession.
+type Session struct {
+	// Start is the timestamp when the session started.
+	Start time.Time `json:"start"`
+	// End is the timestamp when the session was stopped. It is zero while
+	// the session is running.
+	End time.Time `json:"end,omitempty"`
+}
+
+// runningSession returns the index in the Habit's Sessions of the session that
+// has not been stopped yet, or -1 if none is running.
+func (h Habit) runningSession() int {
+	for i, s := range h.Sessions {
+		if s.End.IsZero() {
+			return i
+		}
+	}
+	return -1
+}
+
+// StartSession starts timing a session of the duration Habit with the given
+// name, a quantity habit whose target is in minutes, such as writing for 30
+// minutes a day, and saves the store. The session runs until it is stopped
+// with StopSession. An error is returned if the Habit does not exist, is not
+// a duration habit or already has a session running, or if the store cannot
+// be saved.
+func (t *Tracker) StartSession(hbtName string) error {
+	return t.update(func() error {
+		return t.startSession(hbtName)
+	})
+}
+
+// startSession starts a session like StartSession, without taking the lock of
+// a store that is only locked while it changes.
+func (t *Tracker) startSession(hbtName string) error {
+	hbt, ok := t.store.Get(hbtName)
+	if !ok {
+		return errHabitNotFound(hbtName)
+	}
+	if hbt.Target <= 0 || hbt.Unit != minutesUnit {
+		return fmt.Errorf("habit '%s' is not a duration habit; set a target in minutes with 'habit target %s <amount> minutes'", hbtName, hbtName)
+	}
+	if i := hbt.runningSession(); i >= 0 {
+		return fmt.Errorf("a session of habit '%s' is already running since %s", hbtName,
+			hbt.Sessions[i].Start.In(t.calendar.location).Format("15:04"))
+	}
+	now := t.now()
+	hbt.Sessions = append(hbt.Sessions, Session{Start: now})
+	t.store.Add(hbt)
+	err := t.save()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(t.output, "Started a session of '%s' at %s.\n", hbtName, now.In(t.calendar.location).Format("15:04"))
+	return nil
+}
+
+// StopSession stops the running session of the duration Habit with the given
+// name and logs the minutes it lasted, rounded to the nearest minute, as
+// LogAmount does, so that the Habit is tracked as done once the minutes of its
+// current period reach its target. All the minutes of a session count towards
+// the period in which it is stopped. A session shorter than half a minute is
+// recorded without logging anything. An error is returned if the Habit does
+// not exist or has no session running, or if the store cannot be saved.
+func (t *Tracker) StopSession(hbtName string) error {
+	return t.update(func() error {
+		return t.stopSession(hbtName)
+	})
+}
+
+// stopSession stops a session like StopSession, without taking the lock of a
+// store that is only locked while it changes.
+func (t *Tracker) stopSession(hbtName string) error {
+	hbt, ok := t.store.Get(hbtName)
+	if !ok {
+		return errHabitNotFound(hbtName)
+	}
+	i := hbt.runningSession()
+	if i < 0 {
+		return fmt.Errorf("habit '%s' has no session running", hbtName)
+	}
+	now := t.now()
+	hbt.Sessions = slices.Clone(hbt.Sessions)
+	hbt.Sessions[i].End = now
+	elapsed := now.Sub(hbt.Sessions[i].Start)
+	t.store.Add(hbt)
+	minutes := math.Round(elapsed.Minutes())
+	if minutes <= 0 {
+		err := t.save()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(t.output, "Stopped the session of '%s' after %s, too short to log.\n", hbtName, elapsed.Round(time.Second))
+		return nil
+	}
+	fmt.Fprintf(t.output, "Stopped the session of '%s' after %s.\n", hbtName, elapsed.Round(time.Second))
+	return t.logAmount(hbtName, minutes, "")
+}
+
+// TimeSession starts a session of the duration Habit with the given name as
+// StartSession does, and redraws a line with the time elapsed and the minutes
+// of the Habit's current period on the Tracker's output every second until
+// the given context is cancelled or, if d is positive, until d has elapsed,
+// such as for a 25-minute pomodoro. It then reloads the store, so that
+// habits changed by other processes meanwhile are kept, and stops the session
+// as StopSession does. A store opened with WithLockPerChange is only locked
+// while the session is started and stopped, not while it runs. An error is
+// returned if the session cannot be started or stopped, or if the store cannot
+// be reloaded.
+func (t *Tracker) TimeSession(ctx context.Context, hbtName string, d time.Duration) error {
+	err := t.StartSession(hbtName)
+	if err != nil {
+		return err
+	}
+	hbt, _ := t.store.Get(hbtName)
+	start := hbt.Sessions[hbt.runningSession()].Start
+	before := hbt.amountThisPeriod(start, t.calendar)
+	var done <-chan time.Time
+	if d > 0 {
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		done = timer.C
+	}
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		elapsed := t.now().Sub(start)
+		fmt.Fprintf(t.output, "\r%s  %s  (%s of %s %s)", hbtName, formatElapsed(elapsed),
+			formatAmount(before+math.Round(elapsed.Minutes()), ""), formatAmount(hbt.Target, hbt.Unit),
+			hbt.Frequency.current())
+		select {
+		case <-ctx.Done():
+		case <-done:
+		case <-ticker.C:
+			continue
+		}
+		fmt.Fprintln(t.output)
+		err = LoadContext(context.Background(), t.store)
+		if err != nil {
+			return err
+		}
+		return t.StopSession(hbtName)
+	}
+}
+
+// formatElapsed returns the given duration as minutes and seconds, preceded by
+// hours if it is an hour or longer, such as 12:05 or 1:02:30.
+func formatElapsed(d time.Duration) string {
+	d = d.Round(time.Second)
+	h, m, s := int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, s)
+	}
+	return fmt.Sprintf("%02d:%02d", m, s)
+}
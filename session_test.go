@@ -0,0 +1,144 @@
+package habit_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTracker_StopSessionLogsMinutesUntilTargetIsReached(t *testing.T) {
+	store := &memStore{habits: map[string]habit.Habit{
+		"writing": {Name: "writing", Target: 30, Unit: "minutes"},
+	}}
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, session := range [][2]string{
+		{"2024-02-06T09:00:00Z", "2024-02-06T09:20:10Z"},
+		{"2024-02-06T20:00:00Z", "2024-02-06T20:11:00Z"},
+	} {
+		habit.Now = getTimeFunc(t, session[0])
+		err = tracker.StartSession("writing")
+		if err != nil {
+			t.Fatal(err)
+		}
+		habit.Now = getTimeFunc(t, session[1])
+		err = tracker.StopSession("writing")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := "Started a session of 'writing' at 09:00.\n" +
+		"Stopped the session of 'writing' after 20m10s.\n" +
+		"Logged 20 minutes of 'writing': 20 of 30 minutes today.\n" +
+		"Started a session of 'writing' at 20:00.\n" +
+		"Stopped the session of 'writing' after 11m0s.\n" +
+		"Logged 11 minutes of 'writing': 31 of 30 minutes today.\n" +
+		"Target reached!\n"
+	if got := output.String(); !strings.HasPrefix(got, want) {
+		t.Errorf("want output starting with %q, got output %q", want, got)
+	}
+	hbt, _ := store.Get("writing")
+	if hbt.CurrentStreak != 1 {
+		t.Errorf("want the habit tracked once its target is reached, got streak %d", hbt.CurrentStreak)
+	}
+	wantSessions := []habit.Session{
+		{Start: time.Date(2024, time.February, 6, 9, 0, 0, 0, time.UTC), End: time.Date(2024, time.February, 6, 9, 20, 10, 0, time.UTC)},
+		{Start: time.Date(2024, time.February, 6, 20, 0, 0, 0, time.UTC), End: time.Date(2024, time.February, 6, 20, 11, 0, 0, time.UTC)},
+	}
+	if !cmp.Equal(wantSessions, hbt.Sessions) {
+		t.Error(cmp.Diff(wantSessions, hbt.Sessions))
+	}
+}
+
+func TestTracker_StopSessionIsRecordedByPreview(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T09:20:00Z")
+	start := time.Date(2024, time.February, 6, 9, 0, 0, 0, time.UTC)
+	preview := habit.NewPreview(&memStore{habits: map[string]habit.Habit{
+		"writing": {Name: "writing", Target: 30, Unit: "minutes", Sessions: []habit.Session{{Start: start}}},
+	}})
+	tracker, err := habit.NewTracker(habit.WithStore(preview), habit.WithOutput(new(bytes.Buffer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.StopSession("writing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes := preview.Changes()
+	if len(changes) != 1 {
+		t.Fatalf("want 1 change, got %+v", changes)
+	}
+	want := []habit.Session{{Start: start}}
+	if !cmp.Equal(want, changes[0].Before.Sessions) {
+		t.Error(cmp.Diff(want, changes[0].Before.Sessions))
+	}
+	want = []habit.Session{{Start: start, End: habit.Now()}}
+	if !cmp.Equal(want, changes[0].After.Sessions) {
+		t.Error(cmp.Diff(want, changes[0].After.Sessions))
+	}
+}
+
+func TestTracker_StartSessionReturnsErrorForHabitWithoutMinutesTargetOrRunningSession(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T20:00:00Z")
+	store := &memStore{habits: map[string]habit.Habit{
+		"reading": {Name: "reading"},
+		"water":   {Name: "water", Target: 8, Unit: "glasses"},
+		"writing": {Name: "writing", Target: 30, Unit: "minutes"},
+	}}
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(new(bytes.Buffer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"reading", "water", "running"} {
+		err = tracker.StartSession(name)
+		if err == nil {
+			t.Errorf("want an error starting a session of habit '%s'", name)
+		}
+	}
+	err = tracker.StopSession("writing")
+	if err == nil {
+		t.Error("want an error stopping a session that is not running")
+	}
+	err = tracker.StartSession("writing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.StartSession("writing")
+	if err == nil {
+		t.Error("want an error starting a session that is already running")
+	}
+}
+
+func TestTracker_TimeSessionShowsElapsedTimeUntilDurationElapses(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T20:00:00Z")
+	store := &memStore{habits: map[string]habit.Habit{
+		"writing": {Name: "writing", Target: 30, Unit: "minutes"},
+	}}
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.TimeSession(context.Background(), "writing", 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Started a session of 'writing' at 20:00.\n" +
+		"\rwriting  00:00  (0 of 30 minutes today)\n" +
+		"Stopped the session of 'writing' after 0s, too short to log.\n"
+	if got := output.String(); want != got {
+		t.Errorf("want output %q, got output %q", want, got)
+	}
+	hbt, _ := store.Get("writing")
+	if len(hbt.Sessions) != 1 || hbt.Sessions[0].End.IsZero() {
+		t.Errorf("want 1 stopped session, got %v", hbt.Sessions)
+	}
+}
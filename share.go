@@ -0,0 +1,239 @@
+package habit
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultShareDays is the number of days for which a share link is valid
+// unless told otherwise.
+const DefaultShareDays = 30
+
+// errSharingOff is returned when a share link is requested from a Server
+// without a share secret.
+var errSharingOff = errors.New("sharing is turned off; set a share secret to create share links")
+
+// errInvalidShare is returned for share tokens that were not signed with the
+// Server's share secret or have expired.
+var errInvalidShare = errors.New("invalid or expired share link")
+
+// WithShareSecret returns a serverOption that lets a Server create share
+// links, signed with the given secret, that show the streaks of selected
+// habits to anyone who has the link, read-only and without a token, until the
+// link expires. Changing the secret revokes every link signed with the old
+// one. An empty secret turns sharing off.
+func WithShareSecret(secret string) serverOption {
+	return func(s *Server) {
+		s.shareSecret = []byte(secret)
+	}
+}
+
+// shareClaims are the contents of a share token.
+type shareClaims struct {
+	// User is the user whose habits are shared, if the Server has users.
+	User string `json:"user,omitempty"`
+	// Habits are the names of the shared habits.
+	Habits []string `json:"habits"`
+	// Expires is the timestamp after which the token is no longer valid.
+	Expires time.Time `json:"expires"`
+}
+
+// A shareRequest is the body of a request to create a share link.
+type shareRequest struct {
+	// Habits are the names of the habits to share.
+	Habits []string `json:"habits"`
+	// Days is the number of days for which the link is valid, or
+	// DefaultShareDays if zero.
+	Days int `json:"days"`
+}
+
+// A shareResponse carries a new share link.
+type shareResponse struct {
+	// Token is the signed share token.
+	Token string `json:"token"`
+	// URL is the link at which the shared habits can be viewed.
+	URL string `json:"url"`
+	// Expires is the timestamp after which the link is no longer valid.
+	Expires time.Time `json:"expires"`
+}
+
+// A sharedResponse carries the habits shared by a share link.
+type sharedResponse struct {
+	// Habits are the summaries of the shared habits that still exist and are
+	// not archived, sorted by name.
+	Habits []HabitSummary `json:"habits"`
+	// Expires is the timestamp after which the link is no longer valid.
+	Expires time.Time `json:"expires"`
+}
+
+// ShareToken returns a share token, signed with the Server's share secret,
+// that shows the Habits with the given names to anyone who has it for the
+// given number of days, at the link returned by ShareURL, along with the time
+// it expires. An error is returned if the Server has no share secret, no
+// Habits are given, any of them does not exist or the number of days is not
+// positive.
+func (s *Server) ShareToken(hbtNames []string, days int) (string, time.Time, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.shareToken(s.tracker, "", hbtNames, days)
+}
+
+// shareToken returns a share token for the Habits with the given names of the
+// given user, found with the given Tracker, like ShareToken, along with its
+// expiry. The caller must hold s.mtx.
+func (s *Server) shareToken(t *Tracker, user string, hbtNames []string, days int) (string, time.Time, error) {
+	if len(s.shareSecret) == 0 {
+		return "", time.Time{}, errSharingOff
+	}
+	if len(hbtNames) < 1 {
+		return "", time.Time{}, errors.New("no habits given to share")
+	}
+	if days <= 0 {
+		return "", time.Time{}, fmt.Errorf("invalid number of days %d (want a positive number)", days)
+	}
+	for _, name := range hbtNames {
+		if _, ok := t.store.Get(name); !ok {
+			return "", time.Time{}, errHabitNotFound(name)
+		}
+	}
+	claims := shareClaims{User: user, Habits: hbtNames, Expires: t.now().AddDate(0, 0, days).UTC()}
+	data, err := json.Marshal(claims)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	payload := base64.RawURLEncoding.EncodeToString(data)
+	return payload + "." + s.signShare(payload), claims.Expires, nil
+}
+
+// signShare returns the signature of the given share token payload.
+func (s *Server) signShare(payload string) string {
+	mac := hmac.New(sha256.New, s.shareSecret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// verifyShare returns the claims of the given share token, or errInvalidShare
+// if it was not signed with the Server's share secret or has expired as of
+// the given timestamp.
+func (s *Server) verifyShare(token string, now time.Time) (shareClaims, error) {
+	payload, signature, ok := strings.Cut(token, ".")
+	if !ok || len(s.shareSecret) == 0 || !hmac.Equal([]byte(signature), []byte(s.signShare(payload))) {
+		return shareClaims{}, errInvalidShare
+	}
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return shareClaims{}, errInvalidShare
+	}
+	var claims shareClaims
+	err = json.Unmarshal(data, &claims)
+	if err != nil || !now.Before(claims.Expires) {
+		return shareClaims{}, errInvalidShare
+	}
+	return claims, nil
+}
+
+// serveShared writes the habits shared by the token in the given request if
+// it asks for them at /shared/{token} or /v1/shared/{token}, and reports
+// whether it did. Shared habits are served without a token, like the
+// dashboard, since the share token is signed, but are rate limited like the
+// API, by IP address apart from the API's clients, so that viewers of a share
+// link cannot use up the requests of the Server's token.
+func (s *Server) serveShared(w http.ResponseWriter, r *http.Request) bool {
+	parts, ok := pathParts(r.URL)
+	if ok && parts[0] == apiVersion {
+		parts = parts[1:]
+	}
+	if !ok || len(parts) != 2 || parts[0] != "shared" {
+		return false
+	}
+	if s.limiter != nil && !s.limiter.allow("share:"+remoteHost(r.RemoteAddr)) {
+		w.Header().Set("Retry-After", strconv.Itoa(retryAfter(s.limiter.limit)))
+		writeJSON(w, http.StatusTooManyRequests, errorResponse{Error: "rate limit exceeded"})
+		return true
+	}
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.handle(w, r, map[string]endpoint{
+		http.MethodGet: func(r *http.Request) (int, any, error) {
+			return s.shared(r, parts[1])
+		},
+	})
+	return true
+}
+
+// shared handles GET /shared/{token}.
+func (s *Server) shared(r *http.Request, token string) (int, any, error) {
+	claims, err := s.verifyShare(token, s.tracker.now())
+	if err != nil {
+		return http.StatusNotFound, nil, err
+	}
+	ctx := r.Context()
+	if claims.User != "" {
+		ctx = context.WithValue(ctx, userContextKey{}, claims.User)
+	}
+	t, err := s.userTracker(ctx)
+	if err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+	unlock, err := lockChange(t.store)
+	if err != nil {
+		return lockErrorStatus(err), nil, err
+	}
+	defer unlock()
+	resp := sharedResponse{Habits: []HabitSummary{}, Expires: claims.Expires}
+	for _, summary := range t.Summarize() {
+		if slices.Contains(claims.Habits, summary.Name) {
+			resp.Habits = append(resp.Habits, summary)
+		}
+	}
+	return http.StatusOK, resp, nil
+}
+
+// createShare handles POST /shares, which creates a share link for the
+// habits in the request body of the user the request is tied to.
+func (s *Server) createShare(t *Tracker, r *http.Request) (int, any, error) {
+	var req shareRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return bodyErrorStatus(err), nil, fmt.Errorf("invalid request body: %w", err)
+	}
+	if req.Days == 0 {
+		req.Days = DefaultShareDays
+	}
+	user, _ := UserFromContext(r.Context())
+	token, expires, err := s.shareToken(t, user, req.Habits, req.Days)
+	switch {
+	case errors.Is(err, errSharingOff):
+		return http.StatusNotImplemented, nil, err
+	case errors.Is(err, ErrHabitNotFound):
+		return http.StatusNotFound, nil, err
+	case err != nil:
+		return http.StatusBadRequest, nil, err
+	}
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return http.StatusOK, shareResponse{
+		Token:   token,
+		URL:     ShareURL(scheme+"://"+r.Host, token),
+		Expires: expires,
+	}, nil
+}
+
+// ShareURL returns the link at which the habits shared by the given token can
+// be viewed on the server at the given base URL, such as
+// "https://habits.example.com".
+func ShareURL(baseURL, token string) string {
+	return strings.TrimSuffix(baseURL, "/") + "/" + apiVersion + "/shared/" + token
+}
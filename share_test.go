@@ -0,0 +1,206 @@
+package habit_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+// testShareSecret is the share secret of the servers returned by
+// newTestServer.
+const testShareSecret = "share-secret"
+
+// newShareToken returns a share token for the habits with the given names,
+// signed with testShareSecret.
+func newShareToken(t *testing.T, hbtNames ...string) string {
+	t.Helper()
+	store := &memStore{habits: map[string]habit.Habit{}}
+	for _, name := range hbtNames {
+		store.habits[name] = habit.Habit{Name: name}
+	}
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(new(bytes.Buffer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, _, err := habit.NewServer(tracker, habit.WithShareSecret(testShareSecret)).ShareToken(hbtNames, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestServer_SharedLinkShowsSelectedHabitsWithoutToken(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T13:00:00Z")
+	lastDone := habit.Now().Add(-time.Hour)
+	store, err := habit.OpenStore(t.TempDir() + "/test.store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{Name: "reading", CurrentStreak: 4, LongestStreak: 9, LastDone: lastDone})
+	store.Add(habit.Habit{Name: "running", CurrentStreak: 2, LongestStreak: 2, LastDone: lastDone})
+	store.Add(habit.Habit{Name: "therapy", CurrentStreak: 1, LongestStreak: 1, LastDone: lastDone})
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(new(bytes.Buffer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(habit.NewServer(tracker, habit.WithToken("secret"), habit.WithShareSecret(testShareSecret)))
+	defer srv.Close()
+	req, err := http.NewRequest(http.MethodPost, srv.URL+"/v1/shares",
+		strings.NewReader(`{"habits": ["reading", "running"], "days": 7}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("want status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	var share struct {
+		URL     string
+		Expires time.Time
+	}
+	err = json.NewDecoder(resp.Body).Decode(&share)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2024, time.February, 13, 13, 0, 0, 0, time.UTC); !want.Equal(share.Expires) {
+		t.Errorf("want link to expire at %v, got %v", want, share.Expires)
+	}
+	// The link is opened by a friend who has no token.
+	resp, err = http.Get(share.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("want status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	var shared struct{ Habits []habit.HabitSummary }
+	err = json.NewDecoder(resp.Body).Decode(&shared)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, s := range shared.Habits {
+		got = append(got, s.Name)
+	}
+	want := []string{"reading", "running"}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+	// The share link only reads the shared habits.
+	req, err = http.NewRequest(http.MethodPost, share.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("want status %d for POST, got %d", http.StatusMethodNotAllowed, resp.StatusCode)
+	}
+}
+
+func TestServer_SharedLinkRejectsTamperedAndExpiredTokens(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T13:00:00Z")
+	srv, _ := newTestServer(t, habit.Habit{Name: "reading"}, habit.Habit{Name: "running"})
+	token := newShareToken(t, "reading")
+	payload, signature, _ := strings.Cut(token, ".")
+	otherPayload, _, _ := strings.Cut(newShareToken(t, "reading", "running"), ".")
+	testCases := map[string]string{
+		"unsigned":         payload,
+		"wrong signature":  payload + "." + strings.ToUpper(signature),
+		"tampered payload": otherPayload + "." + signature,
+	}
+	for name, token := range testCases {
+		resp, err := http.Get(srv.URL + "/v1/shared/" + token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("%s: want status %d, got %d", name, http.StatusNotFound, resp.StatusCode)
+		}
+	}
+	// The token is valid for a day.
+	habit.Now = getTimeFunc(t, "2024-02-07T13:00:00Z")
+	resp, err := http.Get(srv.URL + "/v1/shared/" + token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expired: want status %d, got %d", http.StatusNotFound, resp.StatusCode)
+	}
+}
+
+func TestServer_PostSharesReturnsErrorWithoutShareSecret(t *testing.T) {
+	t.Parallel()
+	store := &memStore{habits: map[string]habit.Habit{"reading": {Name: "reading"}}}
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(new(bytes.Buffer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(habit.NewServer(tracker))
+	defer srv.Close()
+	resp, err := http.Post(srv.URL+"/v1/shares", "application/json", strings.NewReader(`{"habits": ["reading"]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotImplemented {
+		t.Errorf("want status %d, got %d", http.StatusNotImplemented, resp.StatusCode)
+	}
+}
+
+func TestServer_SharedLinkViewersCannotUseUpRateLimitOfToken(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T13:00:00Z")
+	store := &memStore{habits: map[string]habit.Habit{"reading": {Name: "reading"}}}
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(new(bytes.Buffer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(habit.NewServer(tracker,
+		habit.WithToken("secret"), habit.WithShareSecret(testShareSecret), habit.WithRateLimit(0.01, 2)))
+	defer srv.Close()
+	token := newShareToken(t, "reading")
+	var got []int
+	for i := 0; i < 3; i++ {
+		resp, err := http.Get(srv.URL + "/v1/shared/" + token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		got = append(got, resp.StatusCode)
+	}
+	want := []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/v1/habits", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("want status %d for the token after share link viewers are limited, got %d", http.StatusOK, resp.StatusCode)
+	}
+}
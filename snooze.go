@@ -0,0 +1,43 @@
+package habit
+
+import (
+	"fmt"
+	"time"
+)
+
+// Snooze holds back reminders of the Habit with the given name for the given
+// duration, so that a reminder daemon started with Remind, which reloads the
+// store before each reminder, stops nagging about a habit that the user will
+// do later, and saves the store. Tracking the Habit acknowledges its reminders
+// instead, since a Habit done in its current period is no longer due. A
+// message saying until when the Habit is snoozed is written to the Tracker's
+// output. An error is returned if the Habit does not exist, the duration is
+// not positive, or the store cannot be saved.
+func (t *Tracker) Snooze(hbtName string, d time.Duration) error {
+	hbt, ok := t.store.Get(hbtName)
+	if !ok {
+		return errHabitNotFound(hbtName)
+	}
+	if d <= 0 {
+		return fmt.Errorf("invalid snooze duration %s (want a positive duration, such as 1h)", d)
+	}
+	until := t.now().Add(d)
+	hbt.SnoozedUntil = &until
+	t.store.Add(hbt)
+	err := t.save()
+	if err != nil {
+		return err
+	}
+	layout := "15:04"
+	if !t.calendar.day(until).Equal(t.calendar.day(t.now())) {
+		layout = "2006-01-02 15:04"
+	}
+	fmt.Fprintf(t.output, "Snoozed reminders of '%s' until %s.\n", hbtName, until.In(t.calendar.location).Format(layout))
+	return nil
+}
+
+// snoozed reports whether reminders of the Habit are held back at the given
+// timestamp.
+func (h Habit) snoozed(now time.Time) bool {
+	return h.SnoozedUntil != nil && now.Before(*h.SnoozedUntil)
+}
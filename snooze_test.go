@@ -0,0 +1,110 @@
+package habit_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTracker_SnoozeHoldsBackRemindersUntilSnoozeEnds(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T20:00:00Z")
+	store, err := habit.OpenStore(t.TempDir() + "/test.store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{Name: "reading", LastDone: habit.Now().Add(-21 * time.Hour)})
+	store.Add(habit.Habit{Name: "running", LastDone: habit.Now().Add(-21 * time.Hour)})
+	err = store.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Snooze("running", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := "Snoozed reminders of 'running' until 21:00.\n", output.String(); want != got {
+		t.Errorf("want output %q, got output %q", want, got)
+	}
+	notifier := new(recordingNotifier)
+	err = tracker.RemindDue(notifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	habit.Now = getTimeFunc(t, "2024-02-06T21:00:00Z")
+	err = tracker.RemindDue(notifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"You haven't done 'reading' yet. Do it soon to keep your streak going!",
+		"You haven't done 'reading' or 'running' yet. Do them soon to keep your streaks going!",
+	}
+	if !cmp.Equal(want, notifier.messages) {
+		t.Error(cmp.Diff(want, notifier.messages))
+	}
+}
+
+func TestTracker_SnoozeReturnsErrorForUnknownHabitOrInvalidDuration(t *testing.T) {
+	t.Parallel()
+	store := &memStore{habits: map[string]habit.Habit{"reading": {Name: "reading"}}}
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(new(bytes.Buffer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Snooze("running", time.Hour)
+	if err == nil {
+		t.Error("want an error snoozing a habit that does not exist")
+	}
+	err = tracker.Snooze("reading", 0)
+	if err == nil {
+		t.Error("want an error snoozing a habit for no time")
+	}
+	if store.saves != 0 {
+		t.Errorf("want the store left unsaved, got %d saves", store.saves)
+	}
+}
+
+func TestTracker_RemindEveryRepeatsReminderAtInterval(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T19:59:59.9Z")
+	store, err := habit.OpenStore(t.TempDir() + "/test.store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{Name: "reading", LastDone: habit.Now().Add(-21 * time.Hour)})
+	err = store.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(new(bytes.Buffer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	notifier := new(recordingNotifier)
+	notifier.notified = func() {
+		if len(notifier.messages) == 2 {
+			cancel()
+		}
+	}
+	err = tracker.RemindEvery(ctx, habit.TimeOfDay{Hour: 20}, 100*time.Millisecond, notifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"You haven't done 'reading' yet. Do it soon to keep your streak going!",
+		"You haven't done 'reading' yet. Do it soon to keep your streak going!",
+	}
+	if !cmp.Equal(want, notifier.messages) {
+		t.Error(cmp.Diff(want, notifier.messages))
+	}
+}
@@ -0,0 +1,76 @@
+package habit
+
+import (
+	"strings"
+	"time"
+)
+
+// sparklineDays is the number of days, up to and including today, covered by
+// a Habit's sparkline.
+const sparklineDays = 30
+
+// Sparkline glyphs.
+const (
+	sparkDone   = "●"
+	sparkMissed = "○"
+	sparkNone   = "·"
+)
+
+// sparkline returns a row of dots showing the Habit's consistency over the last
+// sparklineDays days as of the given timestamp, oldest first, with calendar
+// dates taken from the given calendar. A day is marked done if the Habit was
+// done on it, and missed if it was the last day of a period in which the Habit
+// was not done. Days within a done period, days before the Habit was started,
+// days off its schedule, days by the end of which it was paused and the days
+// of the current period are neither, so that they never look like misses. For
+// a Habit to avoid, days without a relapse are marked done and relapses
+// missed.
+func (h Habit) sparkline(now time.Time, cal calendar) string {
+	var started time.Time
+	if !h.LastDone.IsZero() {
+		started = cal.date(h.LastDone)
+	}
+	done := map[time.Time]bool{}
+	periodsDone := map[int]bool{}
+	for _, c := range h.History {
+		day := cal.date(c.At)
+		done[day] = true
+		periodsDone[h.Frequency.periodIndex(c.At, cal)] = true
+		if started.IsZero() || day.Before(started) {
+			started = day
+		}
+	}
+	if !h.Avoid && !h.LastDone.IsZero() {
+		// The last completion may be missing from a compacted history.
+		done[cal.date(h.LastDone)] = true
+		periodsDone[h.Frequency.periodIndex(h.LastDone, cal)] = true
+	}
+	today := cal.start(now)
+	current := h.Frequency.periodIndex(now, cal)
+	var b strings.Builder
+	for i := sparklineDays - 1; i >= 0; i-- {
+		at := today.AddDate(0, 0, -i)
+		day := cal.date(at)
+		period := h.Frequency.periodIndex(at, cal)
+		glyph := sparkNone
+		switch {
+		case started.IsZero() || day.Before(started):
+		case h.Avoid && done[day]:
+			glyph = sparkMissed
+		case h.Avoid:
+			if i > 0 {
+				glyph = sparkDone
+			}
+		case done[day]:
+			glyph = sparkDone
+		case h.Paused(at.AddDate(0, 0, 1).Add(-time.Nanosecond)) || !h.scheduled(at, cal) ||
+			periodsDone[period] || period == current:
+		case h.Frequency.periodIndex(at.AddDate(0, 0, 1), cal) == period:
+			// The Habit can still be done later in the period.
+		default:
+			glyph = sparkMissed
+		}
+		b.WriteString(glyph)
+	}
+	return b.String()
+}
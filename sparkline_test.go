@@ -0,0 +1,68 @@
+package habit_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTracker_PrintListShowsSparklineOfLast30Days(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-01-31T09:00:00Z")
+	at := func(day, hour int) time.Time {
+		return time.Date(2024, time.January, day, hour, 0, 0, 0, time.UTC)
+	}
+	store := &memStore{habits: map[string]habit.Habit{
+		"cleaning": {
+			Name:      "cleaning",
+			Frequency: habit.Weekly,
+			History:   dailyHistory(3, 17),
+			LastDone:  at(17, 20),
+		},
+		"gym": {
+			Name:     "gym",
+			Weekdays: []time.Weekday{time.Monday, time.Wednesday, time.Friday},
+			History:  dailyHistory(22, 24),
+			LastDone: at(24, 20),
+		},
+		"reading": {
+			Name:     "reading",
+			History:  dailyHistory(10, 11, 12, 13, 14, 16, 17, 18, 19, 20),
+			LastDone: at(20, 20),
+		},
+		"smoking": {
+			Name:     "smoking",
+			Avoid:    true,
+			History:  []habit.Completion{{At: at(20, 9)}, {At: at(25, 9)}},
+			LastDone: at(25, 9),
+		},
+		"yoga": {
+			Name:     "yoga",
+			History:  dailyHistory(20, 21, 22, 23, 24),
+			LastDone: at(24, 20),
+			Pauses:   []habit.Pause{{From: at(25, 8), Until: at(28, 8)}},
+		},
+	}}
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.PrintList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Weekly cleaning is only missed on the Sundays ending the weeks it was
+	// not done, the gym on the scheduled days it was not done, and yoga not
+	// on the days it was paused. Today is not missed until it is over.
+	want := "cleaning: current streak 0, longest streak 0, weekly, last 30 days ·●··········○··●··········○···\n" +
+		"gym: current streak 0, longest streak 0, daily on Monday, Wednesday and Friday, last 30 days ····················●·●·○··○··\n" +
+		"reading: current streak 0, longest streak 0, daily, last 30 days ········●●●●●○●●●●●○○○○○○○○○○·\n" +
+		"smoking: current streak 6, longest streak 6, avoid, last 30 days ··················○●●●●○●●●●●·\n" +
+		"yoga: current streak 0, longest streak 0, daily, last 30 days ··················●●●●●···○○○·\n"
+	if got := output.String(); want != got {
+		t.Error(cmp.Diff(want, got))
+	}
+}
@@ -0,0 +1,475 @@
+package habit
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	// Register the pure-Go "sqlite" database/sql driver.
+	_ "modernc.org/sqlite"
+)
+
+// A SQLiteStore provides a store for Habits that is persisted to a SQLite
+// database, so that large numbers of habits can be queried efficiently and
+// concurrently by multiple processes. The completions of each Habit are kept
+// in a table of their own, indexed by habit and time, rather than in the
+// Habit's data. Changes made with Add and Delete are buffered in memory until
+// Save commits them in a single transaction, writing only the rows of the
+// habits and completions that changed.
+//
+// With WithLock or WithLockPerChange, a SQLiteStore takes the same lock as a
+// FileStore, on a ".lock" file next to the database, so that habit processes
+// serialize their changes rather than saving habits they read before another
+// process changed them.
+type SQLiteStore struct {
+	db            *sql.DB
+	pending       map[string]*Habit
+	err           error
+	lockPath      string
+	lockPerChange bool
+	lockTimeout   time.Duration
+	lock          *os.File
+	mtx           sync.Mutex
+}
+
+// sqliteSchema creates the tables used by a SQLiteStore if they do not exist.
+const sqliteSchema = `CREATE TABLE IF NOT EXISTS habits (
+	name TEXT PRIMARY KEY,
+	data TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS completions (
+	habit TEXT NOT NULL,
+	seq INTEGER NOT NULL,
+	at TEXT NOT NULL,
+	frozen INTEGER NOT NULL DEFAULT 0,
+	note TEXT NOT NULL DEFAULT '',
+	amount REAL NOT NULL DEFAULT 0,
+	id TEXT NOT NULL DEFAULT '',
+	PRIMARY KEY (habit, seq)
+);
+CREATE INDEX IF NOT EXISTS completions_habit_at ON completions (habit, at)`
+
+// sqliteTimeLayout is the layout of the completion timestamps in a SQLite
+// store, always in UTC and with a fixed number of digits, so that they sort
+// in time order.
+const sqliteTimeLayout = "2006-01-02T15:04:05.000000000Z"
+
+// OpenSQLiteStore opens the SQLite database with the given data source name,
+// creating the habit tables if necessary, and returns a SQLiteStore backed by
+// it, configured with the given options. An error is returned if an option
+// other than WithLock and WithLockPerChange is given, or if the database
+// cannot be locked, opened or initialized.
+func OpenSQLiteStore(dsn string, opts ...storeOption) (*SQLiteStore, error) {
+	cfg, err := databaseOptions("sqlite", dsn, opts)
+	if err != nil {
+		return nil, err
+	}
+	s := &SQLiteStore{
+		pending:       map[string]*Habit{},
+		lockPath:      dsn + ".lock",
+		lockPerChange: cfg.lockPerChange,
+		lockTimeout:   cfg.lockTimeout,
+	}
+	if cfg.locking {
+		s.lock, err = acquireLock(s.lockPath, s.lockTimeout)
+		if err != nil {
+			return nil, err
+		}
+	}
+	s.db, err = openSQLite(dsn)
+	if err != nil {
+		s.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// openSQLite opens the SQLite database with the given data source name,
+// creating the habit tables and upgrading its habits if necessary.
+func openSQLite(dsn string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("error opening sqlite store %q: %w", dsn, err)
+	}
+	// A single connection keeps the busy timeout and in-memory databases
+	// consistent across queries.
+	db.SetMaxOpenConns(1)
+	for _, stmt := range []string{"PRAGMA busy_timeout = 5000", sqliteSchema} {
+		_, err = db.Exec(stmt)
+		if err != nil {
+			db.Close()
+			return nil, fmt.Errorf("error initializing sqlite store %q: %w", dsn, err)
+		}
+	}
+	err = migrateSQLite(db)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error migrating sqlite store %q: %w", dsn, err)
+	}
+	return db, nil
+}
+
+// migrateSQLite upgrades the habits in the given database to SchemaVersion,
+// which is recorded in the database's user_version, in a single transaction.
+// The histories of habits saved before completions had a table of their own
+// are moved out of the habits' data into the completions table.
+func migrateSQLite(db *sql.DB) error {
+	ctx := context.Background()
+	var version int
+	err := db.QueryRow("PRAGMA user_version").Scan(&version)
+	if err != nil {
+		return err
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	var habits []Habit
+	if version == SchemaVersion {
+		habits, err = querySQLiteHabits(ctx, tx, "", "json_extract(data, '$.history') IS NOT NULL")
+	} else {
+		habits, err = querySQLiteHabits(ctx, tx, "", "")
+	}
+	if err != nil {
+		return err
+	}
+	if version == SchemaVersion && len(habits) == 0 {
+		return nil
+	}
+	data := map[string]Habit{}
+	for _, h := range habits {
+		data[h.Name] = h
+	}
+	err = migrate(version, data)
+	if err != nil {
+		return err
+	}
+	for _, h := range data {
+		err = writeSQLiteHabit(ctx, tx, h, nil)
+		if err != nil {
+			return err
+		}
+	}
+	// PRAGMA statements cannot take parameters.
+	_, err = tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", SchemaVersion))
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Get returns the habit with the given name and a bool indicating if the habit
+// exists in the store.
+func (s *SQLiteStore) Get(name string) (Habit, bool) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if h, ok := s.pending[name]; ok {
+		if h == nil {
+			return Habit{}, false
+		}
+		return *h, true
+	}
+	if name == "" {
+		return Habit{}, false
+	}
+	habits, err := querySQLiteHabits(context.Background(), s.db, name, "")
+	if err != nil {
+		s.setErr(err)
+		return Habit{}, false
+	}
+	if len(habits) == 0 {
+		return Habit{}, false
+	}
+	return habits[0], true
+}
+
+// Add adds or updates the given habit in the store.
+func (s *SQLiteStore) Add(h Habit) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.pending[h.Name] = &h
+}
+
+// Delete deletes the habit with the given name from the store. If the
+// habit does not exist in the store, then the delete is a no-op.
+func (s *SQLiteStore) Delete(name string) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.pending[name] = nil
+}
+
+// All returns a list of all habits contained in the store.
+func (s *SQLiteStore) All() []Habit {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	saved, err := querySQLiteHabits(context.Background(), s.db, "", "")
+	if err != nil {
+		s.setErr(err)
+		return nil
+	}
+	var habits []Habit
+	for _, h := range saved {
+		if _, ok := s.pending[h.Name]; !ok {
+			habits = append(habits, h)
+		}
+	}
+	for _, h := range s.pending {
+		if h != nil {
+			habits = append(habits, *h)
+		}
+	}
+	return habits
+}
+
+// Save commits the changes made since the last Save to the database in a
+// single transaction. An error is returned if a previous query failed or if
+// the changes cannot be committed.
+func (s *SQLiteStore) Save() error {
+	return s.SaveContext(context.Background())
+}
+
+// SaveContext commits the changes like Save, rolling the transaction back if
+// the given context is done before it is committed, in which case the changes
+// are kept for the next save.
+func (s *SQLiteStore) SaveContext(ctx context.Context) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.err != nil {
+		err := s.err
+		s.err = nil
+		return err
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting sqlite transaction: %w", err)
+	}
+	defer tx.Rollback()
+	for name, h := range s.pending {
+		if h == nil {
+			_, err = tx.ExecContext(ctx, "DELETE FROM habits WHERE name = ?", name)
+			if err == nil {
+				_, err = tx.ExecContext(ctx, "DELETE FROM completions WHERE habit = ?", name)
+			}
+			if err != nil {
+				return fmt.Errorf("error deleting habit '%s': %w", name, err)
+			}
+			continue
+		}
+		var prev *Habit
+		if name != "" {
+			saved, err := querySQLiteHabits(ctx, tx, name, "")
+			if err != nil {
+				return err
+			}
+			if len(saved) > 0 {
+				prev = &saved[0]
+			}
+		}
+		err = writeSQLiteHabit(ctx, tx, *h, prev)
+		if err != nil {
+			return err
+		}
+	}
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("error committing sqlite transaction: %w", err)
+	}
+	s.pending = map[string]*Habit{}
+	return nil
+}
+
+// querySQLiteHabits returns the habits in the database with their histories,
+// or only the habit with the given name if it is non-empty. If where is
+// non-empty, only the habits whose rows match the given SQL condition are
+// returned.
+func querySQLiteHabits(ctx context.Context, q queryer, name, where string) ([]Habit, error) {
+	query := "SELECT name, data FROM habits WHERE (? = '' OR name = ?)"
+	if where != "" {
+		query += " AND " + where
+	}
+	rows, err := q.QueryContext(ctx, query, name, name)
+	if err != nil {
+		return nil, fmt.Errorf("error querying habits: %w", err)
+	}
+	var habits []Habit
+	index := map[string]int{}
+	for rows.Next() {
+		var hbtName, data string
+		err = rows.Scan(&hbtName, &data)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("error reading habits: %w", err)
+		}
+		var h Habit
+		err = json.Unmarshal([]byte(data), &h)
+		if err != nil {
+			rows.Close()
+			return nil, errorOf(ErrStoreCorrupt, "error decoding habit '%s': %w", hbtName, err)
+		}
+		// A habit saved before completions had a table of their own keeps
+		// its history in its data until it is migrated.
+		if len(h.History) == 0 {
+			index[hbtName] = len(habits)
+		}
+		habits = append(habits, h)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading habits: %w", err)
+	}
+	if len(index) == 0 {
+		return habits, nil
+	}
+	rows, err = q.QueryContext(ctx, `SELECT habit, at, frozen, note, amount, id FROM completions
+		WHERE (? = '' OR habit = ?) ORDER BY habit, seq`, name, name)
+	if err != nil {
+		return nil, fmt.Errorf("error querying completions: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var hbtName, at string
+		var c Completion
+		err = rows.Scan(&hbtName, &at, &c.Frozen, &c.Note, &c.Amount, &c.ID)
+		if err != nil {
+			return nil, fmt.Errorf("error reading completions: %w", err)
+		}
+		i, ok := index[hbtName]
+		if !ok {
+			continue
+		}
+		c.At, err = time.Parse(sqliteTimeLayout, at)
+		if err != nil {
+			return nil, errorOf(ErrStoreCorrupt, "error decoding completion of habit '%s': %w", hbtName, err)
+		}
+		habits[i].History = append(habits[i].History, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading completions: %w", err)
+	}
+	return habits, nil
+}
+
+// writeSQLiteHabit writes the given Habit in the given transaction. Only what
+// changed since prev, the Habit as it is saved in the database, is written:
+// its row if its fields changed, and the rows of the completions that were
+// added or changed, so that tracking a habit inserts a single completion. A
+// nil prev, for a Habit whose saved rows are not known, rewrites the Habit and
+// its whole history.
+func writeSQLiteHabit(ctx context.Context, tx *sql.Tx, h Habit, prev *Habit) error {
+	if prev != nil && sameHabit(*prev, h) {
+		return nil
+	}
+	history := h.History
+	h.History = nil
+	data, err := json.Marshal(h)
+	if err != nil {
+		return fmt.Errorf("error encoding habit '%s': %w", h.Name, err)
+	}
+	var saved []Completion
+	var savedData []byte
+	if prev != nil {
+		p := *prev
+		saved, p.History = p.History, nil
+		savedData, err = json.Marshal(p)
+		if err != nil {
+			return fmt.Errorf("error encoding habit '%s': %w", h.Name, err)
+		}
+	}
+	if !bytes.Equal(data, savedData) {
+		_, err = tx.ExecContext(ctx, `INSERT INTO habits (name, data) VALUES (?, ?)
+			ON CONFLICT (name) DO UPDATE SET data = excluded.data`, h.Name, string(data))
+		if err != nil {
+			return fmt.Errorf("error saving habit '%s': %w", h.Name, err)
+		}
+	}
+	if prev == nil || len(history) < len(saved) {
+		_, err = tx.ExecContext(ctx, "DELETE FROM completions WHERE habit = ? AND seq >= ?", h.Name, len(history))
+		if err != nil {
+			return fmt.Errorf("error saving habit '%s': %w", h.Name, err)
+		}
+	}
+	for i, c := range history {
+		if i < len(saved) && sameCompletion(saved[i], c) {
+			continue
+		}
+		_, err = tx.ExecContext(ctx, `INSERT INTO completions (habit, seq, at, frozen, note, amount, id)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (habit, seq) DO UPDATE
+			SET at = excluded.at, frozen = excluded.frozen, note = excluded.note, amount = excluded.amount,
+				id = excluded.id`,
+			h.Name, i, c.At.UTC().Format(sqliteTimeLayout), c.Frozen, c.Note, c.Amount, c.ID)
+		if err != nil {
+			return fmt.Errorf("error saving habit '%s': %w", h.Name, err)
+		}
+	}
+	return nil
+}
+
+// sameCompletion reports whether the given completions hold the same data,
+// ignoring the locations of their timestamps.
+func sameCompletion(a, b Completion) bool {
+	return a.At.Equal(b.At) && a.Frozen == b.Frozen && a.Note == b.Note && a.Amount == b.Amount && a.ID == b.ID
+}
+
+// LoadContext discards the changes made since the last save, so that the
+// store reads the habits committed to the database, and checks that the
+// database can still be reached before the given context is done.
+func (s *SQLiteStore) LoadContext(ctx context.Context) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.pending = map[string]*Habit{}
+	s.err = nil
+	err := s.db.PingContext(ctx)
+	if err != nil {
+		return fmt.Errorf("error connecting to sqlite database: %w", err)
+	}
+	return nil
+}
+
+// lockChange takes the store's lock and discards the changes made since the
+// last save, if it was opened with WithLockPerChange, and returns a function
+// that releases the lock. For other stores, it does nothing. An error is
+// returned if the lock cannot be taken or the database cannot be reached.
+func (s *SQLiteStore) lockChange() (func(), error) {
+	if !s.lockPerChange {
+		return func() {}, nil
+	}
+	lock, err := acquireLock(s.lockPath, s.lockTimeout)
+	if err != nil {
+		return nil, err
+	}
+	err = s.LoadContext(context.Background())
+	if err != nil {
+		lock.Close()
+		return nil, err
+	}
+	return func() { lock.Close() }, nil
+}
+
+// Close closes the underlying database and releases the store's lock, if it
+// holds one. Changes that have not been saved are discarded.
+func (s *SQLiteStore) Close() error {
+	var err error
+	if s.db != nil {
+		err = s.db.Close()
+	}
+	if s.lock != nil {
+		s.lock.Close()
+		s.lock = nil
+	}
+	return err
+}
+
+// setErr records the first error encountered by a query so that it can be
+// returned by the next call to Save. The caller must hold s.mtx.
+func (s *SQLiteStore) setErr(err error) {
+	if s.err == nil {
+		s.err = err
+	}
+}
@@ -0,0 +1,337 @@
+package habit_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSQLiteStore_SaveSavesStorePersistently(t *testing.T) {
+	t.Parallel()
+	path := t.TempDir() + "/habits.db"
+	store, err := habit.OpenSQLiteStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{Name: "habit1", CurrentStreak: 2, LongestStreak: 2})
+	store.Add(habit.Habit{Name: "habit2"})
+	store.Add(habit.Habit{Name: "habit3"})
+	store.Delete("habit3")
+	err = store.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = store.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	store2, err := habit.OpenSQLiteStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store2.Close()
+	want := []habit.Habit{
+		{Name: "habit1", CurrentStreak: 2, LongestStreak: 2},
+		{Name: "habit2"},
+	}
+	got := store2.All()
+	if !cmp.Equal(want, got, habitSliceCmpOpt) {
+		t.Error(cmp.Diff(want, got, habitSliceCmpOpt))
+	}
+}
+
+func TestSQLiteStore_GetReturnsUnsavedChanges(t *testing.T) {
+	t.Parallel()
+	store, err := habit.OpenSQLiteStore(t.TempDir() + "/habits.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	store.Add(habit.Habit{Name: "habit1"})
+	err = store.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{Name: "habit2", CurrentStreak: 1})
+	got, ok := store.Get("habit2")
+	if !ok {
+		t.Fatal("expected ok to be true when getting unsaved habit")
+	}
+	want := habit.Habit{Name: "habit2", CurrentStreak: 1}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+	store.Delete("habit1")
+	_, ok = store.Get("habit1")
+	if ok {
+		t.Error("wanted ok to be false when getting habit deleted but not yet saved")
+	}
+}
+
+func TestSQLiteStore_GetReturnsNotOkGivenNonExistentHabit(t *testing.T) {
+	t.Parallel()
+	store, err := habit.OpenSQLiteStore(t.TempDir() + "/habits.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	_, ok := store.Get("nonexistent-key")
+	if ok {
+		t.Error("wanted ok to be false when getting non-existent key")
+	}
+}
+
+func TestSQLiteStore_SaveContextKeepsChangesGivenCancelledContext(t *testing.T) {
+	t.Parallel()
+	store, err := habit.OpenSQLiteStore(t.TempDir() + "/habits.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	store.Add(habit.Habit{Name: "habit1"})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = store.SaveContext(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("want error %v, got %v", context.Canceled, err)
+	}
+	err = store.SaveContext(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = store.LoadContext(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, ok := store.Get("habit1")
+	if !ok {
+		t.Error("want habit kept after a cancelled save to be saved by the next save")
+	}
+}
+
+func TestSQLiteStore_SaveKeepsCompletionsInTheirOwnTable(t *testing.T) {
+	t.Parallel()
+	path := t.TempDir() + "/habits.db"
+	store, err := habit.OpenSQLiteStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	history := []habit.Completion{
+		{At: time.Date(2024, time.February, 5, 13, 0, 0, 0, time.UTC), Note: "5k"},
+		{At: time.Date(2024, time.February, 6, 13, 0, 0, 500, time.UTC), Frozen: true, Amount: 2, ID: "abc"},
+	}
+	store.Add(habit.Habit{Name: "running", CurrentStreak: 2, LongestStreak: 2, LastDone: history[1].At, History: history})
+	err = store.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	var count int
+	err = db.QueryRow(`SELECT count(*) FROM completions
+		WHERE habit = 'running' AND at >= '2024-02-06'`).Scan(&count)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Errorf("want 1 completion since February 6 in the completions table, got %d", count)
+	}
+	var data string
+	err = db.QueryRow("SELECT data FROM habits WHERE name = 'running'").Scan(&data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved habit.Habit
+	err = json.Unmarshal([]byte(data), &saved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved.History) != 0 {
+		t.Errorf("want no history in the habit's data, got %+v", saved.History)
+	}
+	got, ok := store.Get("running")
+	if !ok {
+		t.Fatal("expected habit 'running' to be present in store")
+	}
+	if !cmp.Equal(history, got.History) {
+		t.Error(cmp.Diff(history, got.History))
+	}
+	store.Add(habit.Habit{Name: "running", History: history[:1]})
+	err = store.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ = store.Get("running")
+	if !cmp.Equal(history[:1], got.History) {
+		t.Error(cmp.Diff(history[:1], got.History))
+	}
+}
+
+func TestOpenSQLiteStoreMovesHistoriesOutOfHabitData(t *testing.T) {
+	t.Parallel()
+	path := t.TempDir() + "/habits.db"
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, stmt := range []string{
+		"CREATE TABLE habits (name TEXT PRIMARY KEY, data TEXT NOT NULL)",
+		`INSERT INTO habits VALUES ('programming', '{"name": "programming", "current_streak": 1,
+			"last_done": "2024-02-06T13:00:00Z", "history": [{"at": "2024-02-06T13:00:00Z", "note": "Go"}]}')`,
+		fmt.Sprintf("PRAGMA user_version = %d", habit.SchemaVersion),
+	} {
+		_, err = db.Exec(stmt)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	db.Close()
+	store, err := habit.OpenSQLiteStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	got, ok := store.Get("programming")
+	if !ok {
+		t.Fatal("expected habit 'programming' to be present in store")
+	}
+	want := []habit.Completion{{At: time.Date(2024, time.February, 6, 13, 0, 0, 0, time.UTC), Note: "Go"}}
+	if !cmp.Equal(want, got.History) {
+		t.Error(cmp.Diff(want, got.History))
+	}
+	db, err = sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	var count int
+	err = db.QueryRow("SELECT count(*) FROM completions WHERE habit = 'programming'").Scan(&count)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Errorf("want the history moved to the completions table, got %d completions", count)
+	}
+}
+
+func TestOpenSQLiteStoreWithLockReturnsErrorWhileAnotherStoreHoldsLock(t *testing.T) {
+	t.Parallel()
+	path := t.TempDir() + "/habits.db"
+	store, err := habit.OpenSQLiteStore(path, habit.WithLock(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = habit.Open(path, habit.WithLock(100*time.Millisecond))
+	if !errors.Is(err, habit.ErrStoreLocked) {
+		t.Fatalf("want ErrStoreLocked opening store locked by another store, got %v", err)
+	}
+	err = store.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	store2, err := habit.OpenSQLiteStore(path, habit.WithLock(0))
+	if err != nil {
+		t.Fatalf("expected store to open after lock was released: %v", err)
+	}
+	store2.Close()
+}
+
+func TestOpenSQLiteStoreReturnsErrorNamingUnsupportedOptions(t *testing.T) {
+	t.Parallel()
+	_, err := habit.Open(t.TempDir()+"/habits.db", habit.WithLock(0), habit.WithBackup(), habit.WithSalvage())
+	if err == nil || !strings.Contains(err.Error(), "WithBackup, WithSalvage") {
+		t.Fatalf("want error naming WithBackup and WithSalvage, got %v", err)
+	}
+}
+
+func TestSQLiteStore_SaveWritesOnlyChangedRows(t *testing.T) {
+	t.Parallel()
+	path := t.TempDir() + "/habits.db"
+	store, err := habit.OpenSQLiteStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	var history []habit.Completion
+	for d := 1; d <= 5; d++ {
+		history = append(history, habit.Completion{At: time.Date(2024, time.February, d, 13, 0, 0, 0, time.UTC)})
+	}
+	store.Add(habit.Habit{Name: "running", CurrentStreak: 5, LongestStreak: 5, LastDone: history[4].At, History: history})
+	store.Add(habit.Habit{Name: "reading", CurrentStreak: 1, LongestStreak: 1, LastDone: history[4].At, History: history[4:]})
+	err = store.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	// Triggers count the rows each save writes.
+	for _, stmt := range []string{
+		"CREATE TABLE writes (tbl TEXT NOT NULL)",
+		"CREATE TRIGGER habits_insert AFTER INSERT ON habits BEGIN INSERT INTO writes VALUES ('habits'); END",
+		"CREATE TRIGGER habits_update AFTER UPDATE ON habits BEGIN INSERT INTO writes VALUES ('habits'); END",
+		"CREATE TRIGGER completions_insert AFTER INSERT ON completions BEGIN INSERT INTO writes VALUES ('completions'); END",
+		"CREATE TRIGGER completions_update AFTER UPDATE ON completions BEGIN INSERT INTO writes VALUES ('completions'); END",
+	} {
+		_, err = db.Exec(stmt)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	writes := func() map[string]int {
+		t.Helper()
+		rows, err := db.Query("SELECT tbl, count(*) FROM writes GROUP BY tbl")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rows.Close()
+		got := map[string]int{}
+		for rows.Next() {
+			var tbl string
+			var n int
+			err = rows.Scan(&tbl, &n)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got[tbl] = n
+		}
+		_, err = db.Exec("DELETE FROM writes")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return got
+	}
+	running, _ := store.Get("running")
+	reading, _ := store.Get("reading")
+	done := time.Date(2024, time.February, 6, 13, 0, 0, 0, time.UTC)
+	running.History = append(running.History, habit.Completion{At: done, Note: "10k"})
+	running.CurrentStreak, running.LongestStreak, running.LastDone = 6, 6, done
+	store.Add(running)
+	store.Add(reading)
+	err = store.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int{"habits": 1, "completions": 1}
+	if got := writes(); !cmp.Equal(want, got) {
+		t.Errorf("want the changed habit and its new completion written, got %s", cmp.Diff(want, got))
+	}
+	got, _ := store.Get("running")
+	if !cmp.Equal(running, got) {
+		t.Error(cmp.Diff(running, got))
+	}
+}
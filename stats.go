@@ -0,0 +1,219 @@
+package habit
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultStatsWindow is the number of days, up to and including today, over
+// which completion rates are computed by default.
+const DefaultStatsWindow = 30
+
+// HabitStats describes how consistently a Habit has been done, computed from
+// its completion history.
+type HabitStats struct {
+	// Name is the name of the habit.
+	Name string `json:"name"`
+	// Frequency is how often the habit must be done.
+	Frequency Frequency `json:"frequency"`
+	// Completions is the total number of times the habit has been done.
+	Completions int `json:"completions"`
+	// Window is the number of days, up to and including today, covered by
+	// PeriodsDone and PeriodsInWindow.
+	Window int `json:"window"`
+	// PeriodsDone is the number of periods of the habit's frequency within
+	// the window in which the habit was done.
+	PeriodsDone int `json:"periods_done"`
+	// PeriodsInWindow is the number of periods of the habit's frequency that
+	// overlap the window.
+	PeriodsInWindow int `json:"periods_in_window"`
+	// CurrentStreak is the habit's current streak, in periods of its
+	// frequency. It is 0 once a period has been missed.
+	CurrentStreak int `json:"current_streak"`
+	// LongestStreak is the habit's longest streak, in periods of its
+	// frequency.
+	LongestStreak int `json:"longest_streak"`
+	// Strength is the habit's strength, from 0 to 100, an exponentially
+	// smoothed average of the periods it was done and missed, which a missed
+	// period lowers gradually rather than resetting.
+	Strength float64 `json:"strength"`
+	// AverageStreak is the mean length of every streak in the habit's
+	// history, in periods of its frequency.
+	AverageStreak float64 `json:"average_streak"`
+	// Weekdays holds the number of distinct days the habit was done on each
+	// day of the week, indexed by time.Weekday.
+	Weekdays [7]int `json:"weekdays"`
+	// Deadline is the time of day by which the habit should be done each
+	// day. It is nil if the habit has no deadline.
+	Deadline *TimeOfDay `json:"deadline,omitempty"`
+	// OnTime is the number of days within the window on which the habit was
+	// first done by its deadline.
+	OnTime int `json:"on_time,omitempty"`
+}
+
+// CompletionRate returns the percentage of periods within the window in which
+// the habit was done.
+func (s HabitStats) CompletionRate() float64 {
+	if s.PeriodsInWindow == 0 {
+		return 0
+	}
+	return float64(s.PeriodsDone) * 100 / float64(s.PeriodsInWindow)
+}
+
+// OnTimeRate returns the percentage of the periods within the window in which
+// the habit was done that it was done by its deadline. It is only meaningful
+// if the habit has a deadline.
+func (s HabitStats) OnTimeRate() float64 {
+	if s.PeriodsDone == 0 {
+		return 0
+	}
+	return float64(s.OnTime) * 100 / float64(s.PeriodsDone)
+}
+
+// BestWeekday returns the day of the week on which the habit has been done
+// most often. Ties are broken in favour of the earlier day, starting from
+// Monday. The result is only meaningful if Completions is non-zero.
+func (s HabitStats) BestWeekday() time.Weekday {
+	return s.pickWeekday(func(a, b int) bool { return a > b })
+}
+
+// WorstWeekday returns the day of the week on which the habit has been done
+// least often. Ties are broken in favour of the earlier day, starting from
+// Monday. The result is only meaningful if Completions is non-zero.
+func (s HabitStats) WorstWeekday() time.Weekday {
+	return s.pickWeekday(func(a, b int) bool { return a < b })
+}
+
+// pickWeekday returns the day of the week, starting from Monday, whose count
+// is preferred over every other count by the given comparison.
+func (s HabitStats) pickWeekday(better func(a, b int) bool) time.Weekday {
+	pick := time.Monday
+	for i := 1; i < 7; i++ {
+		day := time.Weekday((i + 1) % 7)
+		if better(s.Weekdays[day], s.Weekdays[pick]) {
+			pick = day
+		}
+	}
+	return pick
+}
+
+// Stats returns a HabitStats for the Habit with the given name, computed over
+// a window of the given number of days. If the name is empty, a HabitStats for
+// every tracked Habit is returned, sorted by name. An error is returned if the
+// named Habit does not exist or the window is not positive.
+func (t *Tracker) Stats(hbtName string, window int) ([]HabitStats, error) {
+	if window < 1 {
+		return nil, fmt.Errorf("invalid stats window %d (want at least 1 day)", window)
+	}
+	var habits []Habit
+	if hbtName != "" {
+		hbt, ok := t.store.Get(hbtName)
+		if !ok {
+			return nil, errHabitNotFound(hbtName)
+		}
+		habits = append(habits, hbt)
+	} else {
+		habits = t.sortedHabits(false)
+	}
+	now := t.now()
+	stats := []HabitStats{}
+	for _, hbt := range habits {
+		stats = append(stats, computeStats(hbt, now, window, t.calendar))
+	}
+	return stats, nil
+}
+
+// computeStats returns the HabitStats of the given Habit as of the given
+// timestamp over a window of the given number of days, with calendar dates
+// taken from the given calendar.
+func computeStats(hbt Habit, now time.Time, window int, cal calendar) HabitStats {
+	freq := hbt.Frequency
+	first := cal.day(now).AddDate(0, 0, 1-window)
+	current, longest := hbt.streaks(now, cal)
+	if !hbt.Avoid && hbt.missedPeriods(now, cal) > 0 {
+		// The stored streak is only reset when the habit is next done.
+		current = 0
+	}
+	stats := HabitStats{
+		Name:            hbt.Name,
+		Frequency:       freq,
+		Completions:     hbt.completions(),
+		Window:          window,
+		PeriodsInWindow: freq.periodIndex(now, cal) - freq.periodIndex(first, cal) + 1,
+		CurrentStreak:   current,
+		LongestStreak:   longest,
+		Strength:        hbt.strength(now, cal),
+		Deadline:        hbt.Deadline,
+	}
+	periods := map[int]bool{}
+	days := map[time.Time]bool{}
+	for _, c := range hbt.History {
+		if !c.At.Before(first) && !c.At.After(now) {
+			period := freq.periodIndex(c.At, cal)
+			if !periods[period] && hbt.Deadline != nil && hbt.onTime(c.At, cal) {
+				stats.OnTime++
+			}
+			periods[period] = true
+		}
+		day := cal.day(c.At)
+		if !days[day] {
+			days[day] = true
+			stats.Weekdays[day.Weekday()]++
+		}
+	}
+	stats.PeriodsDone = len(periods)
+	runs := streakRuns(hbt, cal)
+	if len(runs) > 0 {
+		total := 0
+		for _, run := range runs {
+			total += run
+		}
+		stats.AverageStreak = float64(total) / float64(len(runs))
+	}
+	return stats
+}
+
+// PrintStats writes statistics for the Habit with the given name, computed
+// over a window of the given number of days, to the given Tracker's output. If
+// the name is empty, statistics for every tracked Habit are written, sorted by
+// name. The adherence of the named goals of the Habits follows their
+// statistics. An error is returned if the named Habit does not exist or the
+// window is not positive.
+func (t *Tracker) PrintStats(hbtName string, window int) error {
+	stats, err := t.Stats(hbtName, window)
+	if err != nil {
+		return err
+	}
+	if len(stats) < 1 {
+		fmt.Fprintln(t.output, "You're not currently tracking any habits.")
+		return nil
+	}
+	for _, s := range stats {
+		timesOutput := "times"
+		if s.Completions == 1 {
+			timesOutput = "time"
+		}
+		fmt.Fprintf(t.output, "'%s' has been done %d %s. Current streak: %d. Longest streak: %d.\n",
+			s.Name, s.Completions, timesOutput, s.CurrentStreak, s.LongestStreak)
+		fmt.Fprintf(t.output, "  Done in %d of the last %d %s (%.0f%%). Average streak: %.1f %s.\n",
+			s.PeriodsDone, s.PeriodsInWindow, s.Frequency.unit(s.PeriodsInWindow),
+			s.CompletionRate(), s.AverageStreak, s.Frequency.unit(0))
+		if s.Completions > 0 {
+			fmt.Fprintf(t.output, "  Habit strength: %.0f%%.\n", s.Strength)
+		}
+		if s.Deadline != nil && s.PeriodsDone > 0 {
+			fmt.Fprintf(t.output, "  Done by %s in %d of those %d %s (%.0f%% on time).\n",
+				s.Deadline, s.OnTime, s.PeriodsDone, s.Frequency.unit(s.PeriodsDone), s.OnTimeRate())
+		}
+		if s.Completions > 0 {
+			fmt.Fprintf(t.output, "  Most consistent day: %s. Least consistent day: %s.\n",
+				s.BestWeekday(), s.WorstWeekday())
+		}
+	}
+	for _, r := range t.Goals() {
+		if hbtName == "" || r.hasHabit(hbtName) {
+			fmt.Fprintln(t.output, r.describe())
+		}
+	}
+	return nil
+}
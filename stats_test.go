@@ -0,0 +1,162 @@
+package habit_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTracker_PrintStatsWritesStatsForAllHabitsSortedByName(t *testing.T) {
+	lastDone := time.Date(2024, time.February, 6, 13, 0, 0, 0, time.UTC)
+	store, err := habit.OpenStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{
+		Name:          "reading",
+		CurrentStreak: 1,
+		LongestStreak: 1,
+		LastDone:      lastDone,
+		History:       []habit.Completion{{At: lastDone}},
+	})
+	store.Add(habit.Habit{
+		Name:          "programming",
+		CurrentStreak: 2,
+		LongestStreak: 3,
+		LastDone:      lastDone,
+		History:       []habit.Completion{{At: lastDone}, {At: lastDone}, {At: lastDone}, {At: lastDone}},
+	})
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	habit.Now = getTimeFunc(t, "2024-02-06T20:00:00Z")
+	err = tracker.PrintStats("", habit.DefaultStatsWindow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "'programming' has been done 4 times. Current streak: 2. Longest streak: 3.\n" +
+		"  Done in 1 of the last 30 days (3%). Average streak: 1.0 days.\n" +
+		"  Habit strength: 5%.\n" +
+		"  Most consistent day: Tuesday. Least consistent day: Monday.\n" +
+		"'reading' has been done 1 time. Current streak: 1. Longest streak: 1.\n" +
+		"  Done in 1 of the last 30 days (3%). Average streak: 1.0 days.\n" +
+		"  Habit strength: 5%.\n" +
+		"  Most consistent day: Tuesday. Least consistent day: Monday.\n"
+	got := output.String()
+	if want != got {
+		t.Errorf("want output %q, got output %q", want, got)
+	}
+}
+
+func TestTracker_StatsComputesRatesStreaksAndWeekdaysFromHistory(t *testing.T) {
+	at := func(day, hour int) habit.Completion {
+		return habit.Completion{At: time.Date(2024, time.January, day, hour, 0, 0, 0, time.UTC)}
+	}
+	store, err := habit.OpenStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{
+		Name:          "programming",
+		CurrentStreak: 2,
+		LongestStreak: 3,
+		LastDone:      at(9, 6).At,
+		History: []habit.Completion{
+			at(1, 9), at(2, 8), at(3, 7), // 3-day streak
+			at(5, 7),                      // 1-day streak
+			at(8, 7), at(8, 18), at(9, 6), // 2-day streak
+		},
+	})
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(new(bytes.Buffer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	habit.Now = getTimeFunc(t, "2024-01-14T12:00:00Z")
+	got, err := tracker.Stats("programming", 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []habit.HabitStats{{
+		Name:            "programming",
+		Completions:     7,
+		Window:          7,
+		PeriodsDone:     2,
+		PeriodsInWindow: 7,
+		CurrentStreak:   0,
+		LongestStreak:   3,
+		Strength:        20.2,
+		AverageStreak:   2,
+		Weekdays:        [7]int{time.Monday: 2, time.Tuesday: 2, time.Wednesday: 1, time.Friday: 1},
+	}}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+	if got[0].BestWeekday() != time.Monday {
+		t.Errorf("want best weekday Monday, got %s", got[0].BestWeekday())
+	}
+	if got[0].WorstWeekday() != time.Thursday {
+		t.Errorf("want worst weekday Thursday, got %s", got[0].WorstWeekday())
+	}
+}
+
+func TestTracker_StatsReportsNoCurrentStreakOnceItHasLapsed(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T13:00:00Z")
+	store, err := habit.OpenStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{
+		Name:          "reading",
+		CurrentStreak: 3,
+		LongestStreak: 3,
+		LastDone:      time.Date(2024, time.February, 5, 9, 0, 0, 0, time.UTC),
+	})
+	store.Add(habit.Habit{
+		Name:          "running",
+		CurrentStreak: 3,
+		LongestStreak: 3,
+		LastDone:      time.Date(2024, time.February, 2, 9, 0, 0, 0, time.UTC),
+	})
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(new(bytes.Buffer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats, err := tracker.Stats("", 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]int{}
+	for _, s := range stats {
+		got[s.Name] = s.CurrentStreak
+	}
+	want := map[string]int{"reading": 3, "running": 0}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestTracker_StatsReturnsErrorForInvalidInput(t *testing.T) {
+	t.Parallel()
+	store, err := habit.OpenStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{Name: "programming"})
+	tracker, err := habit.NewTracker(habit.WithStore(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = tracker.Stats("reading", habit.DefaultStatsWindow)
+	if err == nil {
+		t.Error("expected an error getting stats for a habit that does not exist")
+	}
+	_, err = tracker.Stats("programming", 0)
+	if err == nil {
+		t.Error("expected an error getting stats over an empty window")
+	}
+}
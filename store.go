@@ -1,25 +1,206 @@
 package habit
 
 import (
-	"encoding/gob"
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
+	"path/filepath"
+	"strings"
 	"sync"
+	"time"
 )
 
-// A store provides a concurrency-safe store for Habits that is persisted to a
-// local file.
-type store struct {
-	path string
-	data map[string]Habit
-	mtx  sync.Mutex
+// A Store persists Habits for a Tracker. Implementations may keep changes made
+// with Add and Delete in memory until Save is called. Any type with these
+// methods can be given to a Tracker with WithStore in place of the stores in
+// this package. Store is declared here, rather than in a habitstore package
+// of its own, because it is made of Habits and its implementations, such as
+// FileStore and SQLiteStore, need the package's codecs and migrations, so a
+// separate package would import habit while habit imported it to open stores.
+type Store interface {
+	// Get returns the habit with the given name and a bool indicating if the
+	// habit exists in the store.
+	Get(name string) (Habit, bool)
+	// Add adds or updates the given habit in the store.
+	Add(h Habit)
+	// Delete deletes the habit with the given name from the store. If the
+	// habit does not exist in the store, then the delete is a no-op.
+	Delete(name string)
+	// All returns a list of all habits contained in the store.
+	All() []Habit
+	// Save persists the store's habits. An error is returned if the habits
+	// cannot be persisted.
+	Save() error
+}
+
+// A ContextStore is a Store whose habits can be saved and loaded under a
+// context, so that a slow disk, database or network stops blocking once the
+// context is cancelled or its deadline passes.
+type ContextStore interface {
+	Store
+	// SaveContext persists the store's habits like Save. The context's error
+	// is returned if it is done before the habits are persisted.
+	SaveContext(ctx context.Context) error
+	// LoadContext replaces the store's habits with the persisted ones,
+	// discarding changes that have not been saved. The context's error is
+	// returned if it is done before the habits are loaded.
+	LoadContext(ctx context.Context) error
+}
+
+// SaveContext saves the given Store with its SaveContext method if it is a
+// ContextStore, or otherwise with Save unless the context is already done.
+func SaveContext(ctx context.Context, s Store) error {
+	if cs, ok := s.(ContextStore); ok {
+		return cs.SaveContext(ctx)
+	}
+	err := ctx.Err()
+	if err != nil {
+		return err
+	}
+	return s.Save()
+}
+
+// LoadContext reloads the given Store with its LoadContext method if it is a
+// ContextStore, or otherwise with its Reload method if it has one, unless the
+// context is already done. A Store that cannot be reloaded is left as it is.
+func LoadContext(ctx context.Context, s Store) error {
+	if cs, ok := s.(ContextStore); ok {
+		return cs.LoadContext(ctx)
+	}
+	err := ctx.Err()
+	if err != nil {
+		return err
+	}
+	if r, ok := s.(interface{ Reload() error }); ok {
+		return r.Reload()
+	}
+	return nil
+}
+
+// Open opens the store at the given path, choosing the Store implementation
+// from the path's file extension: ".db", ".sqlite", and ".sqlite3" files are
+// opened with OpenSQLiteStore, ".bolt" and ".bbolt" files with OpenBoltStore,
+// and all other files with OpenStore, configured with the given options. An
+// error is returned if the store cannot be opened, or if it is a database and
+// an option it does not support is given.
+func Open(path string, opts ...storeOption) (Store, error) {
+	switch filepath.Ext(path) {
+	case ".db", ".sqlite", ".sqlite3":
+		s, err := OpenSQLiteStore(path, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return s, nil
+	case ".bolt", ".bbolt":
+		s, err := OpenBoltStore(path, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return s, nil
+	}
+	s, err := OpenStore(path, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// A FileStore is a concurrency-safe Store for Habits that is persisted to a
+// local file, opened with OpenStore or OpenJSONStore.
+type FileStore struct {
+	path          string
+	data          map[string]Habit
+	codec         codec
+	backup        bool
+	backupDir     string
+	backupKeep    int
+	backupMaxAge  time.Duration
+	locking       bool
+	lockPerChange bool
+	lockTimeout   time.Duration
+	lock          *os.File
+	salvage       bool
+	damage        []damagedRecord
+	restored      string
+	mtx           sync.Mutex
+}
+
+// storeOption provides a functional option that can be used in the
+// OpenStore() and OpenJSONStore() functions. OpenSQLiteStore() and
+// OpenBoltStore() support the locking options only.
+type storeOption func(*FileStore)
+
+// databaseStore reports whether Open opens the store at the given path as a
+// database, which supports the locking options only, rather than a FileStore.
+func databaseStore(path string) bool {
+	switch filepath.Ext(path) {
+	case ".db", ".sqlite", ".sqlite3", ".bolt", ".bbolt":
+		return true
+	}
+	return false
+}
+
+// databaseOptions returns a FileStore configured with the given options, for a
+// database store of the given kind to read their settings from. An error
+// naming the options is returned if any of them are options that database
+// stores do not support.
+func databaseOptions(kind, path string, opts []storeOption) (*FileStore, error) {
+	s := &FileStore{}
+	for _, opt := range opts {
+		opt(s)
+	}
+	var unsupported []string
+	if s.backup {
+		unsupported = append(unsupported, "WithBackup")
+	}
+	if s.backupDir != "" {
+		unsupported = append(unsupported, "WithBackupDir")
+	}
+	if s.salvage {
+		unsupported = append(unsupported, "WithSalvage")
+	}
+	if len(unsupported) > 0 {
+		return nil, fmt.Errorf("%s store %q does not support %s", kind, path, strings.Join(unsupported, ", "))
+	}
+	return s, nil
+}
+
+// WithBackup returns a storeOption that makes a store keep a copy of the
+// previous version of its file, with a ".bak" extension appended, each time
+// it is saved.
+func WithBackup() storeOption {
+	return func(s *FileStore) {
+		s.backup = true
+	}
+}
+
+// WithSalvage returns a storeOption that makes a store open a file it cannot
+// fully decode instead of failing with ErrStoreCorrupt. The records that
+// decode are loaded, and the rest of the file is set aside for Tracker.Check
+// to report and quarantine. Encrypted files are never salvaged, since data
+// that fails to decrypt is more likely to have the wrong passphrase than to be
+// corrupt.
+func WithSalvage() storeOption {
+	return func(s *FileStore) {
+		s.salvage = true
+	}
+}
+
+// A codec encodes and decodes the habit data persisted by a store.
+type codec interface {
+	// Encode writes the given habit data to w.
+	Encode(w io.Writer, data map[string]Habit) error
+	// Decode reads habit data from r into the given map.
+	Decode(r io.Reader, data *map[string]Habit) error
 }
 
 // Get returns the habit with the given name and a bool indicating if the habit
 // exists in the store.
-func (s *store) Get(name string) (Habit, bool) {
+func (s *FileStore) Get(name string) (Habit, bool) {
 	s.mtx.Lock()
 	defer s.mtx.Unlock()
 	h, ok := s.data[name]
@@ -27,7 +208,7 @@ func (s *store) Get(name string) (Habit, bool) {
 }
 
 // Add adds or updates the given habit in the store.
-func (s *store) Add(h Habit) {
+func (s *FileStore) Add(h Habit) {
 	s.mtx.Lock()
 	defer s.mtx.Unlock()
 	s.data[h.Name] = h
@@ -35,14 +216,14 @@ func (s *store) Add(h Habit) {
 
 // Delete deletes the habit with the given name from the store. If the
 // habit does not exist in the store, then the delete is a no-op.
-func (s *store) Delete(name string) {
+func (s *FileStore) Delete(name string) {
 	s.mtx.Lock()
 	defer s.mtx.Unlock()
 	delete(s.data, name)
 }
 
 // All returns a list of all habits contained in the store.
-func (s *store) All() []Habit {
+func (s *FileStore) All() []Habit {
 	s.mtx.Lock()
 	defer s.mtx.Unlock()
 	var habits []Habit
@@ -52,42 +233,303 @@ func (s *store) All() []Habit {
 	return habits
 }
 
-// Save saves the store to a GOB-encoded file. An error is returned if there is
+// Save saves the store to a file encoded with the store's codec. The data is
+// written to a temporary file in the same directory, synced to disk, and then
+// renamed over the store file, so a crash while saving never leaves a
+// partially-written store file behind. If backups are enabled, the previous
+// store file is kept with a ".bak" extension. An error is returned if there is
 // a problem encoding the store's data or saving the store's data to a local
 // file.
-func (s *store) Save() error {
+func (s *FileStore) Save() error {
+	return s.SaveContext(context.Background())
+}
+
+// SaveContext saves the store like Save, unless the given context is done
+// before the store file is replaced, in which case the store file is left as
+// it was and the context's error is returned.
+func (s *FileStore) SaveContext(ctx context.Context) error {
 	s.mtx.Lock()
 	defer s.mtx.Unlock()
-	f, err := os.Create(s.path)
+	err := ctx.Err()
+	if err != nil {
+		return err
+	}
+	dir, base := filepath.Split(s.path)
+	if dir == "" {
+		dir = "."
+	}
+	f, err := os.CreateTemp(dir, base+".tmp-*")
 	if err != nil {
 		return fmt.Errorf("error creating store %q: %w", s.path, err)
 	}
-	err = gob.NewEncoder(f).Encode(&s.data)
+	tmpPath := f.Name()
+	defer os.Remove(tmpPath)
+	err = s.writeTemp(f)
+	if err != nil {
+		return err
+	}
+	err = ctx.Err()
+	if err != nil {
+		return err
+	}
+	// A damaged store file must not replace the good backup it was
+	// restored from.
+	if s.backup && s.restored == "" {
+		err = backupFile(s.path, s.path+".bak")
+		if err != nil {
+			return fmt.Errorf("error backing up store %q: %w", s.path, err)
+		}
+	}
+	if s.restored == "" {
+		err = s.rotateBackups()
+		if err != nil {
+			return fmt.Errorf("error backing up store %q: %w", s.path, err)
+		}
+	}
+	err = os.Rename(tmpPath, s.path)
+	if err != nil {
+		return fmt.Errorf("error replacing store %q: %w", s.path, err)
+	}
+	syncDir(dir)
+	s.restored = ""
+	return nil
+}
+
+// writeTemp encodes the store's data to the given temporary file with the same
+// permissions as the existing store file, syncs it to disk and closes it.
+func (s *FileStore) writeTemp(f *os.File) error {
+	defer f.Close()
+	mode := fs.FileMode(0o644)
+	info, err := os.Stat(s.path)
+	if err == nil {
+		mode = info.Mode().Perm()
+	}
+	err = f.Chmod(mode)
+	if err != nil {
+		return fmt.Errorf("error creating store %q: %w", s.path, err)
+	}
+	err = s.codec.Encode(f, s.data)
 	if err != nil {
 		return fmt.Errorf("error encoding habit data to store %q: %w", s.path, err)
 	}
+	err = f.Sync()
+	if err != nil {
+		return fmt.Errorf("error syncing store %q: %w", s.path, err)
+	}
+	err = f.Close()
+	if err != nil {
+		return fmt.Errorf("error closing store %q: %w", s.path, err)
+	}
 	return nil
 }
 
-// OpenStore opens the store file at the given path and returns a store
-// initialized with the key-value data contained in the file. An error is
-// returned if there is a problem opening the store file or decoding its data.
-func OpenStore(path string) (*store, error) {
-	s := &store{
-		path: path,
-		data: map[string]Habit{},
+// backupFile replaces the backup file at the given path with a copy of the
+// file at path src. It is a no-op if src does not exist.
+func backupFile(src, backup string) error {
+	err := os.Remove(backup)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	err = os.Link(src, backup)
+	if err == nil || errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	// Fall back to copying on file systems without hard links.
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(backup, data, 0o600)
+}
+
+// syncDir syncs the directory at the given path so that a rename within it is
+// durable. Errors are ignored since not every platform supports syncing
+// directories.
+func syncDir(path string) {
+	d, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer d.Close()
+	d.Sync()
+}
+
+// OpenStore opens the store file at the given path and returns a FileStore
+// initialized with the key-value data contained in the file and configured with
+// the given options. Files with a ".json" extension are JSON-encoded and all
+// other files are GOB-encoded and end with a checksum of their data. If a file
+// does not match its checksum or cannot be decoded, the habits of its most
+// recent backup that can be decoded are loaded instead. An
+// error is returned if there is a problem opening the store file or decoding
+// its data and no backup can stand in for it.
+func OpenStore(path string, opts ...storeOption) (*FileStore, error) {
+	if filepath.Ext(path) == ".json" {
+		return OpenJSONStore(path, opts...)
+	}
+	return openStore(path, checksumCodec{inner: gobCodec{}}, opts)
+}
+
+// OpenJSONStore opens the JSON-encoded store file at the given path and returns
+// a FileStore initialized with the key-value data contained in the file and
+// configured with the given options. An error is returned if there is a problem
+// opening the store file or decoding its data.
+func OpenJSONStore(path string, opts ...storeOption) (*FileStore, error) {
+	return openStore(path, jsonCodec{}, opts)
+}
+
+// openStore opens the store file at the given path and decodes its data using
+// the given codec.
+func openStore(path string, c codec, opts []storeOption) (*FileStore, error) {
+	s := &FileStore{
+		path:  path,
+		data:  map[string]Habit{},
+		codec: c,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.locking {
+		lock, err := acquireLock(path+".lock", s.lockTimeout)
+		if err != nil {
+			return nil, err
+		}
+		s.lock = lock
+	}
+	err := s.load()
+	if err != nil {
+		s.Close()
+		return nil, err
 	}
-	f, err := os.Open(path)
+	return s, nil
+}
+
+// load decodes the data in the store's file into the store. A store file that
+// does not exist yet is treated as empty.
+func (s *FileStore) load() error {
+	raw, err := os.ReadFile(s.path)
 	if errors.Is(err, fs.ErrNotExist) {
-		return s, nil
+		return nil
 	}
 	if err != nil {
-		return nil, fmt.Errorf("error opening store %q: %w", path, err)
+		return fmt.Errorf("error opening store %q: %w", s.path, err)
+	}
+	s.damage, s.restored = nil, ""
+	err = s.codec.Decode(bytes.NewReader(raw), &s.data)
+	if errors.Is(err, errNewerSchema) {
+		return fmt.Errorf("error decoding store data: %w", err)
+	}
+	if err == nil {
+		return nil
+	}
+	// A store file that fails to decode was damaged after it was written, so
+	// its last good backup is the best copy of the habits.
+	backup, ok := s.loadBackup()
+	if ok {
+		s.restored = backup
+		s.damage = []damagedRecord{{data: raw, err: err,
+			fix: fmt.Sprintf("move its contents to a quarantine file and keep the habits of its backup %s", backup)}}
+		return nil
+	}
+	if s.salvage && s.salvageable() {
+		return s.salvageData(raw, err)
+	}
+	return errorOf(ErrStoreCorrupt, "error decoding store data: %w", err)
+}
+
+// loadBackup replaces the store's habits with those of the most recent backup
+// of its file that can be decoded, and returns the backup's path. It reports
+// false if there is no such backup.
+func (s *FileStore) loadBackup() (string, bool) {
+	for _, path := range s.backupPaths() {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		data := map[string]Habit{}
+		err = s.codec.Decode(bytes.NewReader(raw), &data)
+		if err != nil {
+			continue
+		}
+		s.data = data
+		return path, true
+	}
+	return "", false
+}
+
+// backupPaths returns the paths of the backups of the store's file, most
+// recent first.
+func (s *FileStore) backupPaths() []string {
+	backups, _ := s.backups()
+	var paths []string
+	for _, b := range backups {
+		paths = append(paths, b.Path)
+	}
+	return paths
+}
+
+// restoredBackup returns the path of the backup whose habits the store loaded
+// because its file was damaged, or an empty string if it loaded its file.
+func (s *FileStore) restoredBackup() string {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.restored
+}
+
+// unmigrated returns the schema version of the data in the store's file and
+// its Habits as they were written, before any migrations. A store file that
+// does not exist yet has no Habits at SchemaVersion. An error is returned if
+// the file cannot be read or decoded.
+func (s *FileStore) unmigrated() (int, map[string]Habit, error) {
+	dec, ok := s.codec.(rawDecoder)
+	if !ok {
+		return 0, nil, errors.New("cannot decode unmigrated habit data")
+	}
+	f, err := os.Open(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return SchemaVersion, nil, nil
+	}
+	if err != nil {
+		return 0, nil, fmt.Errorf("error opening store %q: %w", s.path, err)
 	}
 	defer f.Close()
-	err = gob.NewDecoder(f).Decode(&s.data)
+	vd, err := dec.decodeRaw(f)
 	if err != nil {
-		return nil, fmt.Errorf("error decoding store data: %w", err)
+		return 0, nil, errorOf(ErrStoreCorrupt, "error decoding store data: %w", err)
 	}
-	return s, nil
+	return vd.Version, vd.Habits, nil
+}
+
+// Reload replaces the store's habits with the ones currently in its file, so
+// that a long-running process sees changes saved by other habit processes.
+// Changes that have not been saved are discarded. An error is returned if the
+// store file cannot be read or decoded.
+func (s *FileStore) Reload() error {
+	return s.LoadContext(context.Background())
+}
+
+// LoadContext reloads the store like Reload, unless the given context is
+// already done, in which case the store is left as it was and the context's
+// error is returned.
+func (s *FileStore) LoadContext(ctx context.Context) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	err := ctx.Err()
+	if err != nil {
+		return err
+	}
+	s.data = map[string]Habit{}
+	return s.load()
+}
+
+// Close releases the store's lock, if it holds one. Changes that have not been
+// saved are discarded.
+func (s *FileStore) Close() error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.lock == nil {
+		return nil
+	}
+	err := s.lock.Close()
+	s.lock = nil
+	return err
 }
@@ -1,8 +1,13 @@
 package habit_test
 
 import (
+	"context"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/aculclasure/habit"
 	"github.com/google/go-cmp/cmp"
@@ -197,3 +202,237 @@ func TestOpenStoreReturnsErrorForUnreadablePath(t *testing.T) {
 		t.Error("expected an error when opening unreadable path")
 	}
 }
+
+func TestOpenStoreMigratesLegacyStore(t *testing.T) {
+	t.Parallel()
+	type legacyHabit struct {
+		Name          string
+		CurrentStreak int
+		LastDone      time.Time
+	}
+	path := t.TempDir() + "/legacy.store"
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lastDone := time.Date(2024, time.February, 6, 13, 0, 0, 0, time.UTC)
+	legacy := map[string]legacyHabit{
+		"programming": {Name: "programming", CurrentStreak: 4, LastDone: lastDone},
+	}
+	err = gob.NewEncoder(f).Encode(&legacy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	store, err := habit.OpenStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, ok := store.Get("programming")
+	if !ok {
+		t.Fatal("expected habit 'programming' to be present in store")
+	}
+	want := habit.Habit{
+		Name:          "programming",
+		CurrentStreak: 4,
+		LongestStreak: 4,
+		LastDone:      lastDone,
+		History:       []habit.Completion{{At: lastDone}},
+	}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestOpenJSONStore_SaveSavesStorePersistently(t *testing.T) {
+	t.Parallel()
+	path := t.TempDir() + "/temp.store"
+	store, err := habit.OpenJSONStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{Name: "habit1", CurrentStreak: 2, LongestStreak: 2})
+	store.Add(habit.Habit{Name: "habit2", ReminderTime: &habit.TimeOfDay{Hour: 7}})
+	err = store.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	store2, err := habit.OpenJSONStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []habit.Habit{
+		{Name: "habit1", CurrentStreak: 2, LongestStreak: 2},
+		{Name: "habit2", ReminderTime: &habit.TimeOfDay{Hour: 7}},
+	}
+	got := store2.All()
+	if !cmp.Equal(want, got, habitSliceCmpOpt) {
+		t.Error(cmp.Diff(want, got, habitSliceCmpOpt))
+	}
+}
+
+func TestOpenStoreUsesJSONForJSONFileExtension(t *testing.T) {
+	t.Parallel()
+	path := t.TempDir() + "/temp.json"
+	store, err := habit.OpenStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{Name: "habit1"})
+	err = store.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !json.Valid(data) {
+		t.Errorf("expected store file with .json extension to contain JSON, got %q", data)
+	}
+}
+
+func TestOpenJSONStoreReturnsErrorForInvalidData(t *testing.T) {
+	t.Parallel()
+	path := t.TempDir() + "/invalid.json"
+	err := os.WriteFile(path, []byte("{not json"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = habit.OpenJSONStore(path)
+	if err == nil {
+		t.Error("expected an error when opening store file with invalid JSON")
+	}
+}
+
+func TestStore_SaveLeavesNoTemporaryFiles(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	store, err := habit.OpenStore(dir + "/temp.store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{Name: "habit1"})
+	for i := 0; i < 2; i++ {
+		err = store.Save()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.Name())
+	}
+	want := []string{"temp.store"}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestStore_SaveWithBackupKeepsPreviousVersion(t *testing.T) {
+	t.Parallel()
+	path := t.TempDir() + "/temp.store"
+	store, err := habit.OpenStore(path, habit.WithBackup())
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{Name: "habit1"})
+	err = store.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{Name: "habit2"})
+	err = store.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	backup, err := habit.OpenStore(path + ".bak")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []habit.Habit{{Name: "habit1"}}
+	got := backup.All()
+	if !cmp.Equal(want, got, habitSliceCmpOpt) {
+		t.Error(cmp.Diff(want, got, habitSliceCmpOpt))
+	}
+}
+
+func TestOpenStoreWithLockReturnsErrorWhileAnotherStoreHoldsLock(t *testing.T) {
+	t.Parallel()
+	path := t.TempDir() + "/temp.store"
+	store, err := habit.OpenStore(path, habit.WithLock(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = habit.OpenStore(path, habit.WithLock(100*time.Millisecond))
+	if !errors.Is(err, habit.ErrStoreLocked) {
+		t.Fatalf("want ErrStoreLocked opening store locked by another store, got %v", err)
+	}
+	err = store.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	store2, err := habit.OpenStore(path, habit.WithLock(0))
+	if err != nil {
+		t.Fatalf("expected store to open after lock was released: %v", err)
+	}
+	store2.Close()
+}
+
+func TestStore_SaveContextLeavesStoreFileGivenCancelledContext(t *testing.T) {
+	t.Parallel()
+	path := t.TempDir() + "/temp.store"
+	store, err := habit.OpenStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{Name: "habit1"})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = store.SaveContext(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("want error %v, got %v", context.Canceled, err)
+	}
+	_, err = os.Stat(path)
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("want store file not to be written, got error %v", err)
+	}
+}
+
+func TestLoadContextReloadsHabitsSavedByAnotherStore(t *testing.T) {
+	t.Parallel()
+	path := t.TempDir() + "/temp.store"
+	store, err := habit.OpenStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{Name: "unsaved"})
+	other, err := habit.OpenStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other.Add(habit.Habit{Name: "habit1"})
+	err = habit.SaveContext(context.Background(), other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = habit.LoadContext(ctx, store)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("want error %v, got %v", context.Canceled, err)
+	}
+	err = habit.LoadContext(context.Background(), store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []habit.Habit{{Name: "habit1"}}
+	got := store.All()
+	if !cmp.Equal(want, got, habitSliceCmpOpt) {
+		t.Error(cmp.Diff(want, got, habitSliceCmpOpt))
+	}
+}
@@ -0,0 +1,59 @@
+package habit
+
+// computeStreaks accepts a Habit with a chronologically sorted completion
+// history and returns the Habit's current streak as of its last completion and
+// its longest streak. It applies the same rules as Tracker.Track: completions
+// in the same period do not extend a streak, and a gap of at least one full
+// period, not counting time the Habit was paused, starts a new streak unless a
+// streak freeze was used for the completion after the gap. Periods are made of
+// dates in the given calendar.
+func computeStreaks(hbt Habit, cal calendar) (current, longest int) {
+	for _, run := range streakRuns(hbt, cal) {
+		current = run
+		if run > longest {
+			longest = run
+		}
+	}
+	return current, longest
+}
+
+// streakRuns accepts a Habit with a chronologically sorted completion history
+// and returns the length of every streak in it, in order, following the rules
+// described on computeStreaks.
+func streakRuns(hbt Habit, cal calendar) []int {
+	freq := hbt.Frequency
+	var runs []int
+	for i, c := range hbt.History {
+		switch {
+		case i == 0:
+			runs = append(runs, 1)
+		case freq.periodIndex(hbt.History[i-1].At, cal) == freq.periodIndex(c.At, cal):
+		case hbt.periodsBetween(hbt.History[i-1].At, c.At, cal) >= 1 && !c.Frozen:
+			runs = append(runs, 1)
+		default:
+			runs[len(runs)-1]++
+		}
+	}
+	return runs
+}
+
+// completionStreaks accepts a Habit with a chronologically sorted completion
+// history and returns the Habit's streak as of each completion, following the
+// rules described on computeStreaks.
+func completionStreaks(hbt Habit, cal calendar) []int {
+	freq := hbt.Frequency
+	streaks := make([]int, len(hbt.History))
+	for i, c := range hbt.History {
+		switch {
+		case i == 0:
+			streaks[i] = 1
+		case freq.periodIndex(hbt.History[i-1].At, cal) == freq.periodIndex(c.At, cal):
+			streaks[i] = streaks[i-1]
+		case hbt.periodsBetween(hbt.History[i-1].At, c.At, cal) >= 1 && !c.Frozen:
+			streaks[i] = 1
+		default:
+			streaks[i] = streaks[i-1] + 1
+		}
+	}
+	return streaks
+}
@@ -0,0 +1,108 @@
+package habit
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// errStreaksExport is returned when encoding Streaks data, which can only be
+// imported.
+var errStreaksExport = errors.New("exporting Streaks files is not supported")
+
+// streaksDateLayouts are the layouts of the dates in a Streaks export.
+var streaksDateLayouts = []string{"20060102", time.DateOnly}
+
+// streaksCodec decodes the CSV file exported by Streaks, the iOS app, which
+// has a row for every entry of every task, naming the task in its title
+// column, the kind of entry in its entry_type column, such as
+// "completed_manually" or "missed_auto", and the date of the entry in its
+// entry_date column. Each task becomes a Habit whose History holds a
+// completion for every completed entry, at the time in the entry_timestamp
+// column if there is one, and whose streaks are recomputed from that history
+// with computeStreaks. The codec cannot encode.
+type streaksCodec struct {
+	// calendar determines the dates on which streaks are recomputed, and
+	// its location is the time zone in which dates without a time are read.
+	calendar calendar
+}
+
+// Encode returns an error, since Streaks data can only be imported.
+func (streaksCodec) Encode(io.Writer, map[string]Habit) error {
+	return errStreaksExport
+}
+
+// Decode reads a Streaks CSV export from r into data.
+func (c streaksCodec) Decode(r io.Reader, data *map[string]Habit) error {
+	table, err := readCSVTable(r)
+	if err != nil {
+		return fmt.Errorf("error reading Streaks export: %w", err)
+	}
+	if !table.has("title", "task") || !table.has("entry_date", "entry_timestamp") {
+		return errors.New("not a Streaks export: want title and entry_date columns")
+	}
+	// Tasks are kept by title in the order they first appear, so that tasks
+	// with the same title are told apart by their task_id column.
+	tasks := map[string]*Habit{}
+	var order []string
+	for i, record := range table.records {
+		line := i + 2
+		name := table.field(record, "title", "task")
+		if name == "" {
+			continue
+		}
+		key := table.field(record, "task_id") + "\x00" + name
+		hbt, ok := tasks[key]
+		if !ok {
+			hbt = &Habit{Name: name}
+			tasks[key] = hbt
+			order = append(order, key)
+		}
+		entry := strings.ToLower(table.field(record, "entry_type"))
+		if entry != "" && !strings.HasPrefix(entry, "completed") {
+			continue
+		}
+		at, err := c.entryTime(table.field(record, "entry_timestamp"), table.field(record, "entry_date"))
+		if err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		hbt.History = append(hbt.History, Completion{At: at})
+	}
+	imported := map[string]Habit{}
+	for _, key := range order {
+		hbt := *tasks[key]
+		sort.Slice(hbt.History, func(i, j int) bool {
+			return hbt.History[i].At.Before(hbt.History[j].At)
+		})
+		if len(hbt.History) > 0 {
+			hbt.CurrentStreak, hbt.LongestStreak = computeStreaks(hbt, c.calendar)
+			hbt.LastDone = hbt.History[len(hbt.History)-1].At
+		}
+		addUnique(imported, hbt)
+	}
+	*data = imported
+	return nil
+}
+
+// entryTime returns the time of a Streaks entry with the given timestamp and
+// date: the timestamp if it is set, and otherwise the start of the date in the
+// codec's calendar.
+func (c streaksCodec) entryTime(timestamp, date string) (time.Time, error) {
+	if timestamp != "" {
+		at, err := time.Parse(time.RFC3339, timestamp)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid entry timestamp %q (want RFC3339)", timestamp)
+		}
+		return at, nil
+	}
+	for _, layout := range streaksDateLayouts {
+		day, err := time.Parse(layout, date)
+		if err == nil {
+			return time.Date(day.Year(), day.Month(), day.Day(), c.calendar.dayStart, 0, 0, 0, c.calendar.location), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid entry date %q (want YYYYMMDD or YYYY-MM-DD)", date)
+}
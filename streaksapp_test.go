@@ -0,0 +1,58 @@
+package habit_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTracker_ImportStreaksConvertsCompletedEntries(t *testing.T) {
+	t.Parallel()
+	export := "task_id,title,icon,entry_type,entry_date,entry_timestamp,entry_time_zone,quantity\n" +
+		"A1,Floss,ic_tooth,completed_manually,20240205,,Europe/London,\n" +
+		"A1,Floss,ic_tooth,completed_manually,20240206,,Europe/London,\n" +
+		"A1,Floss,ic_tooth,missed_auto,20240207,,Europe/London,\n" +
+		"A1,Floss,ic_tooth,completed_manually,20240208,,Europe/London,\n" +
+		"B2,Run,ic_run,completed_manually,20240206,2024-02-06T07:15:00Z,Europe/London,5000\n" +
+		"C3,Run,ic_run,completed_manually,20240207,2024-02-07T18:00:00Z,Europe/London,\n"
+	store := &memStore{habits: map[string]habit.Habit{}}
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(new(bytes.Buffer)), habit.WithLocation(time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Import(strings.NewReader(export), habit.FormatStreaks, habit.MergeLatest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	day := func(d int) time.Time { return time.Date(2024, time.February, d, 0, 0, 0, 0, time.UTC) }
+	morning := time.Date(2024, time.February, 6, 7, 15, 0, 0, time.UTC)
+	evening := time.Date(2024, time.February, 7, 18, 0, 0, 0, time.UTC)
+	want := []habit.Habit{
+		{Name: "Floss", CurrentStreak: 1, LongestStreak: 2, LastDone: day(8),
+			History: []habit.Completion{{At: day(5)}, {At: day(6)}, {At: day(8)}}},
+		{Name: "Run", CurrentStreak: 1, LongestStreak: 1, LastDone: morning,
+			History: []habit.Completion{{At: morning}}},
+		{Name: "Run (2)", CurrentStreak: 1, LongestStreak: 1, LastDone: evening,
+			History: []habit.Completion{{At: evening}}},
+	}
+	got := store.All()
+	if !cmp.Equal(want, got, habitSliceCmpOpt) {
+		t.Error(cmp.Diff(want, got, habitSliceCmpOpt))
+	}
+}
+
+func TestTracker_ImportStreaksRejectsOtherCSVFiles(t *testing.T) {
+	t.Parallel()
+	tracker, err := habit.NewTracker(habit.WithStore(&memStore{habits: map[string]habit.Habit{}}), habit.WithOutput(new(bytes.Buffer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Import(strings.NewReader("name,completed_at\nFloss,2024-02-05\n"), habit.FormatStreaks, habit.MergeLatest)
+	if err == nil {
+		t.Error("expected an error importing a CSV file that is not a Streaks export")
+	}
+}
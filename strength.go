@@ -0,0 +1,68 @@
+package habit
+
+import (
+	"math"
+	"time"
+)
+
+// strengthHalfLife is the number of missed days after which the strength of a
+// daily Habit halves. Habits with longer periods decay more slowly per period
+// but faster per day, as in the Loop Habit Tracker, whose score this follows.
+const strengthHalfLife = 13
+
+// strength returns the strength of the Habit as of the given timestamp, from 0
+// to 100 to one decimal place: an exponentially smoothed average of its
+// periods since it was first done, in which each period done pulls the
+// strength up and each period missed pulls it down, with recent periods
+// weighing the most. Unlike a streak, which a single miss resets, the strength
+// of a Habit done most of the time degrades gradually. The current period only
+// counts once it is done, and periods during which the Habit was paused or off
+// its schedule are skipped. Habits to avoid and Habits never done have a
+// strength of zero.
+func (h Habit) strength(now time.Time, cal calendar) float64 {
+	if h.Avoid || len(h.History) == 0 {
+		return 0
+	}
+	freq := h.Frequency
+	done := map[int]bool{}
+	first := freq.periodIndex(h.History[0].At, cal)
+	for _, c := range h.History {
+		period := freq.periodIndex(c.At, cal)
+		done[period] = true
+		first = min(first, period)
+	}
+	multiplier := math.Pow(0.5, math.Sqrt(float64(freq.Days()))/strengthHalfLife)
+	current := freq.periodIndex(now, cal)
+	score := 0.0
+	for period := first; period <= current; period++ {
+		if done[period] {
+			score = score*multiplier + 1 - multiplier
+			continue
+		}
+		start := freq.periodStart(period, cal)
+		if period == current || h.Paused(start) || !h.scheduled(start, cal) {
+			continue
+		}
+		score *= multiplier
+	}
+	return math.Round(1000*score) / 10
+}
+
+// periodStart returns the timestamp at which the period of the Frequency with
+// the given index, as returned by periodIndex, starts.
+func (f Frequency) periodStart(index int, cal calendar) time.Time {
+	year, month, day := periodAnchor.AddDate(0, 0, index*f.Days()).Date()
+	return time.Date(year, month, day, cal.dayStart, 0, 0, 0, cal.location)
+}
+
+// describeStrength returns the strength of the given Habit as of the given
+// timestamp for summaries, such as "Strength: 74%.", or an empty string if it
+// is a Habit to avoid or has never been done.
+func (t *Tracker) describeStrength(hbt Habit, now time.Time) string {
+	if hbt.Avoid || len(hbt.History) == 0 {
+		return ""
+	}
+	data := t.messageData(hbt)
+	data.Strength = int(math.Round(hbt.strength(now, t.calendar)))
+	return t.text("summary_strength", data)
+}
@@ -0,0 +1,46 @@
+package habit_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+)
+
+// dailyHistory returns completions at 20:00 UTC on each of the given days of
+// January 2024.
+func dailyHistory(days ...int) []habit.Completion {
+	var history []habit.Completion
+	for _, day := range days {
+		history = append(history, habit.Completion{At: time.Date(2024, time.January, day, 20, 0, 0, 0, time.UTC)})
+	}
+	return history
+}
+
+func TestTracker_SummarizeLowersStrengthGraduallyForMissedDays(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-01-13T09:00:00Z")
+	store := &memStore{habits: map[string]habit.Habit{
+		"gym": {
+			Name:     "gym",
+			Weekdays: []time.Weekday{time.Monday, time.Wednesday, time.Friday},
+			History:  dailyHistory(1, 3, 5, 8, 10, 12),
+		},
+		"reading": {Name: "reading", History: dailyHistory(1, 2, 3, 4, 5, 6, 7, 8, 9, 10)},
+		"smoking": {Name: "smoking", Avoid: true, History: dailyHistory(1)},
+	}}
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(new(bytes.Buffer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The 10 days of reading raise its strength to 41.3, and the 2 days
+	// missed since lower it to 37.1 rather than resetting it. Today does not
+	// count until it is done, and neither do the days off the gym's
+	// schedule.
+	want := map[string]float64{"gym": 27.4, "reading": 37.1, "smoking": 0}
+	for _, s := range tracker.Summarize() {
+		if want[s.Name] != s.Strength {
+			t.Errorf("want habit '%s' to have strength %.1f, got %.1f", s.Name, want[s.Name], s.Strength)
+		}
+	}
+}
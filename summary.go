@@ -0,0 +1,119 @@
+package habit
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// A HabitSummary describes the current state of a tracked Habit in a form
+// suited to scripts and dashboards.
+type HabitSummary struct {
+	// Name is the name of the habit.
+	Name string `json:"name"`
+	// Frequency is how often the habit must be done, such as "daily".
+	Frequency string `json:"frequency"`
+	// CurrentStreak is the habit's current streak, in periods of its
+	// frequency.
+	CurrentStreak int `json:"current_streak"`
+	// LongestStreak is the habit's longest streak, in periods of its
+	// frequency.
+	LongestStreak int `json:"longest_streak"`
+	// LastDone is the timestamp when the habit was last done.
+	LastDone time.Time `json:"last_done"`
+	// DaysSinceDone is the number of whole days since the habit was last
+	// done. It is 0 for a habit that has not been done yet.
+	DaysSinceDone int `json:"days_since_done"`
+	// StreakActive is true if the habit's streak has not been broken yet.
+	StreakActive bool `json:"streak_active"`
+	// DoneThisPeriod is true if the habit has been done in its current
+	// period, such as today for a daily habit.
+	DoneThisPeriod bool `json:"done_this_period"`
+	// Completions is the total number of times the habit has been done.
+	Completions int `json:"completions"`
+	// Paused is true if the habit is currently paused.
+	Paused bool `json:"paused"`
+	// Tags are the categories the habit belongs to.
+	Tags []string `json:"tags,omitempty"`
+	// Routine is the name of the routine the habit belongs to, if any.
+	Routine string `json:"routine,omitempty"`
+	// GoalWeights are the weights of the habit in the named goals it counts
+	// toward, keyed by goal name.
+	GoalWeights map[string]float64 `json:"goal_weights,omitempty"`
+	// Goal is the length of the streak the habit is being done for, in
+	// periods of its frequency.
+	Goal int `json:"goal,omitempty"`
+	// GoalPercent is the percentage of the habit's goal its current streak
+	// has reached, at most 100.
+	GoalPercent float64 `json:"goal_percent,omitempty"`
+	// Target is the amount of a quantity habit to log in each period.
+	Target float64 `json:"target,omitempty"`
+	// Unit is the unit in which a quantity habit's amounts are measured.
+	Unit string `json:"unit,omitempty"`
+	// Amount is the amount of a quantity habit logged in the current period.
+	Amount float64 `json:"amount,omitempty"`
+	// Strength is the habit's strength, from 0 to 100, an exponentially
+	// smoothed average of the periods it was done and missed.
+	Strength float64 `json:"strength"`
+	// Badges are the names of the streak milestones the habit has reached,
+	// such as "7-day", in the order they were earned.
+	Badges []string `json:"badges,omitempty"`
+	// Points is the number of points the habit has earned.
+	Points int `json:"points,omitempty"`
+	// Avoid is true if the habit is something to avoid, in which case
+	// LastDone is the time of the last relapse.
+	Avoid bool `json:"avoid,omitempty"`
+}
+
+// Summarize returns a HabitSummary for each tracked Habit, sorted by name. If
+// any tags are given, only the Habits with at least one of the tags are
+// summarized.
+func (t *Tracker) Summarize(tags ...string) []HabitSummary {
+	now := t.now()
+	summaries := []HabitSummary{}
+	for _, hbt := range t.sortedHabits(false, tags...) {
+		current, longest := hbt.streaks(now, t.calendar)
+		daysSince := 0
+		if !hbt.LastDone.IsZero() {
+			daysSince = t.calendar.daysBetween(hbt.LastDone, now)
+		}
+		summaries = append(summaries, HabitSummary{
+			Name:           hbt.Name,
+			Frequency:      hbt.Frequency.String(),
+			CurrentStreak:  current,
+			LongestStreak:  longest,
+			LastDone:       hbt.LastDone,
+			DaysSinceDone:  daysSince,
+			StreakActive:   hbt.Avoid || hbt.missedPeriods(now, t.calendar) < 1,
+			DoneThisPeriod: hbt.doneThisPeriod(now, t.calendar),
+			Completions:    hbt.completions(),
+			Paused:         hbt.Paused(now),
+			Tags:           hbt.Tags,
+			Routine:        hbt.Routine,
+			GoalWeights:    hbt.GoalWeights,
+			Goal:           hbt.Goal,
+			GoalPercent:    hbt.goalPercent(now, t.calendar),
+			Target:         hbt.Target,
+			Unit:           hbt.Unit,
+			Amount:         hbt.amountThisPeriod(now, t.calendar),
+			Strength:       hbt.strength(now, t.calendar),
+			Badges:         hbt.badgeNames(),
+			Points:         hbt.Points,
+			Avoid:          hbt.Avoid,
+		})
+	}
+	return summaries
+}
+
+// PrintSummaryJSON writes the result of Summarize with the given tags to the
+// given Tracker's output as an indented JSON array. An error is returned if the
+// summary cannot be written.
+func (t *Tracker) PrintSummaryJSON(tags ...string) error {
+	enc := json.NewEncoder(t.output)
+	enc.SetIndent("", "  ")
+	err := enc.Encode(t.Summarize(tags...))
+	if err != nil {
+		return fmt.Errorf("error writing summary: %w", err)
+	}
+	return nil
+}
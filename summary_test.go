@@ -0,0 +1,97 @@
+package habit_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTracker_SummarizeReturnsSummariesSortedByName(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T13:00:00Z")
+	threeDaysAgo, err := time.Parse(time.RFC3339, "2024-02-03T13:00:00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := habit.OpenStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{
+		Name:          "reading",
+		CurrentStreak: 2,
+		LongestStreak: 4,
+		LastDone:      threeDaysAgo,
+		History:       []habit.Completion{{At: threeDaysAgo}},
+	})
+	store.Add(habit.Habit{
+		Name:          "programming",
+		CurrentStreak: 5,
+		LongestStreak: 5,
+		LastDone:      habit.Now(),
+		Frequency:     habit.Weekly,
+		History:       []habit.Completion{{At: threeDaysAgo}, {At: habit.Now()}},
+	})
+	tracker, err := habit.NewTracker(habit.WithStore(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []habit.HabitSummary{
+		{
+			Name:           "programming",
+			Frequency:      "weekly",
+			CurrentStreak:  5,
+			LongestStreak:  5,
+			LastDone:       habit.Now(),
+			DaysSinceDone:  0,
+			StreakActive:   true,
+			DoneThisPeriod: true,
+			Completions:    2,
+			Strength:       24.6,
+		},
+		{
+			Name:           "reading",
+			Frequency:      "daily",
+			CurrentStreak:  2,
+			LongestStreak:  4,
+			LastDone:       threeDaysAgo,
+			DaysSinceDone:  3,
+			StreakActive:   false,
+			DoneThisPeriod: false,
+			Completions:    1,
+			Strength:       4.7,
+		},
+	}
+	got := tracker.Summarize()
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestTracker_PrintSummaryJSONWritesEmptyArrayForEmptyStore(t *testing.T) {
+	t.Parallel()
+	store, err := habit.OpenStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.PrintSummaryJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []habit.HabitSummary
+	err = json.Unmarshal(output.Bytes(), &got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got == nil || len(got) != 0 {
+		t.Errorf("want empty JSON array, got output %q", output.String())
+	}
+}
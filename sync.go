@@ -0,0 +1,496 @@
+package habit
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// A SyncRemote stores a copy of the habit data shared by several devices,
+// which is merged with each device's store by Tracker.Sync.
+type SyncRemote interface {
+	// Pull returns the habit data stored on the remote, or nil if the remote
+	// has none yet.
+	Pull() ([]byte, error)
+	// Push replaces the habit data stored on the remote with the given data.
+	// It returns an error wrapping ErrRemoteChanged, and replaces nothing, if
+	// the data on the remote changed since the last Pull.
+	Push(data []byte) error
+}
+
+// syncTimeout is how long a request to an HTTP sync remote may take.
+const syncTimeout = 30 * time.Second
+
+// syncAttempts is how many times Sync pulls, merges and pushes the habit data
+// before giving up while other devices keep changing the remote.
+const syncAttempts = 3
+
+// ParseSyncRemote accepts the URL of a sync remote and returns the
+// corresponding SyncRemote:
+//
+//   - git+https://, git+ssh:// and git+file:// URLs, and URLs or paths ending
+//     in ".git", are git repositories holding a habits.json file, which is
+//     cloned into the user's cache directory.
+//   - s3://bucket/key URLs are objects in an S3 bucket, or in an
+//     S3-compatible service such as Backblaze B2 when an "endpoint" query
+//     parameter is given. The region is taken from the "region" query
+//     parameter or the AWS_REGION environment variable, and credentials from
+//     the AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN
+//     environment variables.
+//   - webdav:// and webdavs:// URLs are files on a WebDAV server, reached over
+//     HTTP or HTTPS, with basic authentication if the URL has a user.
+//
+// An error is returned if the URL is not a supported sync remote.
+func ParseSyncRemote(value string) (SyncRemote, error) {
+	if strings.HasPrefix(value, "git+") {
+		return newGitRemote(strings.TrimPrefix(value, "git+"))
+	}
+	if strings.HasSuffix(value, ".git") {
+		return newGitRemote(value)
+	}
+	u, err := url.Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sync remote %q: %w", value, err)
+	}
+	switch u.Scheme {
+	case "s3":
+		return newS3Remote(u)
+	case "webdav", "webdavs":
+		return newWebDAVRemote(u)
+	}
+	return nil, fmt.Errorf("invalid sync remote %q (want a git repository, s3:// or webdav:// URL)", value)
+}
+
+// Sync merges the habit data stored on the given SyncRemote into the Tracker's
+// store, saves the store, and pushes the merged habits back to the remote if
+// they differ from its copy, so that habits tracked on every device are kept.
+// Habits found on only one side are copied to the other, and Habits found on
+// both are merged field by field, taking the union of their completions and
+// recomputing their streaks. Habits deleted on either side, as recorded in the
+// Tracker's deletion log or on the remote, are deleted on both unless they were
+// done after being deleted, and the remote keeps a record of the deletion for
+// the devices that sync later. The number of Habits updated on each side is
+// written to the Tracker's output. The data on the remote is encrypted like
+// the store if the store was opened with OpenEncryptedStore. If the remote
+// changes while it is synced, the sync starts over from pulling it, so that
+// the changes of other devices are never overwritten. An error is returned if
+// the remote cannot be reached, its data cannot be decoded, it keeps changing,
+// or the store cannot be saved.
+func (t *Tracker) Sync(remote SyncRemote) error {
+	var err error
+	for attempt := 0; attempt < syncAttempts; attempt++ {
+		err = t.syncOnce(remote)
+		if !errors.Is(err, ErrRemoteChanged) {
+			return err
+		}
+	}
+	return err
+}
+
+// syncOnce pulls the habit data of the given SyncRemote, merges it into the
+// Tracker's store and pushes the merged habits back as Sync does, once.
+func (t *Tracker) syncOnce(remote SyncRemote) error {
+	data, err := remote.Pull()
+	if err != nil {
+		return fmt.Errorf("error pulling habits: %w", err)
+	}
+	var remoteDeleted map[string]time.Time
+	remoteHabits := map[string]Habit{}
+	if data != nil {
+		err = deletionCodec(t.store, &remoteDeleted).Decode(bytes.NewReader(data), &remoteHabits)
+		if err != nil {
+			return fmt.Errorf("error decoding pulled habits: %w", err)
+		}
+	}
+	localDeleted, err := t.deletions()
+	if err != nil {
+		return err
+	}
+	deleted := map[string]time.Time{}
+	for name, at := range remoteDeleted {
+		deleted[name] = at
+	}
+	for name, at := range localDeleted {
+		// A Habit added again since it was deleted here is kept.
+		if _, ok := t.store.Get(name); !ok && at.After(deleted[name]) {
+			deleted[name] = at
+		}
+	}
+	var pulledHabits []Habit
+	for _, rh := range remoteHabits {
+		pulledHabits = append(pulledHabits, rh)
+	}
+	pulled := mergeInto(t.store, pulledHabits, deleted, t.calendar)
+	if pulled > 0 {
+		err = t.save()
+		if err != nil {
+			return err
+		}
+	}
+	local := map[string]Habit{}
+	pushed := 0
+	for _, hbt := range t.store.All() {
+		local[hbt.Name] = hbt
+		if rh, ok := remoteHabits[hbt.Name]; !ok || !sameHabit(hbt, rh) {
+			pushed++
+		}
+	}
+	for name := range deleted {
+		if _, ok := local[name]; ok {
+			// The Habit was done after it was deleted, so it is kept.
+			delete(deleted, name)
+			continue
+		}
+		if _, ok := remoteHabits[name]; ok {
+			pushed++
+		}
+	}
+	if pushed > 0 || !sameDeletions(deleted, remoteDeleted) {
+		var buf bytes.Buffer
+		err = deletionCodec(t.store, &deleted).Encode(&buf, local)
+		if err != nil {
+			return err
+		}
+		err = remote.Push(buf.Bytes())
+		if err != nil {
+			return fmt.Errorf("error pushing habits: %w", err)
+		}
+	}
+	err = t.clearDeletions()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(t.output, "Synced your habits: %d %s updated here and %d on the remote.\n",
+		pulled, habitsUnit(pulled), pushed)
+	return nil
+}
+
+// syncCodec returns the codec of the habit data synced with a remote for the
+// given Store: JSON, encrypted like the store's file if the Store is
+// encrypted.
+func syncCodec(s Store) codec {
+	c, ok := s.(interface{ syncCodec() codec })
+	if !ok {
+		return jsonCodec{}
+	}
+	return c.syncCodec()
+}
+
+// syncCodec returns the codec of the habit data the store syncs with a
+// remote: JSON, encrypted with the store's passphrase if its file is.
+func (s *FileStore) syncCodec() codec {
+	if c, ok := s.codec.(encryptedCodec); ok {
+		return encryptedCodec{inner: jsonCodec{}, passphrase: c.passphrase}
+	}
+	return jsonCodec{}
+}
+
+// remoteVersion records the version of the data on an HTTP sync remote seen
+// by its last Pull, so that its next Push only replaces that version.
+type remoteVersion struct {
+	// pulled is true once the remote has been pulled.
+	pulled bool
+	// exists is true if the remote had data when it was pulled.
+	exists bool
+	// etag is the entity tag of the remote's data, if the server sent one.
+	etag string
+}
+
+// observe records the version of the data returned in the given response to
+// a Pull or a Push.
+func (v *remoteVersion) observe(resp *http.Response) {
+	v.pulled = true
+	v.exists = resp.StatusCode != http.StatusNotFound
+	v.etag = resp.Header.Get("ETag")
+}
+
+// condition adds the header to the given request of a Push that makes the
+// server refuse it if the data changed since it was pulled: If-Match with the
+// entity tag of the pulled data, or If-None-Match if there was none. A server
+// that sent no entity tag cannot be asked, so no header is added.
+func (v *remoteVersion) condition(req *http.Request) {
+	switch {
+	case !v.pulled:
+	case v.etag != "":
+		req.Header.Set("If-Match", v.etag)
+	case !v.exists:
+		req.Header.Set("If-None-Match", "*")
+	}
+}
+
+// sameHabit reports whether the given Habits hold the same data once encoded,
+// ignoring differences such as the locations of their timestamps.
+func sameHabit(a, b Habit) bool {
+	aData, aErr := json.Marshal(a)
+	bData, bErr := json.Marshal(b)
+	return aErr == nil && bErr == nil && bytes.Equal(aData, bData)
+}
+
+// sameDeletions reports whether the given records of when Habits were deleted
+// hold the same deletions.
+func sameDeletions(a, b map[string]time.Time) bool {
+	return maps.EqualFunc(a, b, time.Time.Equal)
+}
+
+// habitsUnit returns "habit" or "habits" for the given count.
+func habitsUnit(count int) string {
+	if count == 1 {
+		return "habit"
+	}
+	return "habits"
+}
+
+// syncFile is the name of the file holding the habit data in a git sync
+// remote.
+const syncFile = "habits.json"
+
+// gitRemote is a SyncRemote that keeps the habit data in a git repository,
+// using a clone in the user's cache directory.
+type gitRemote struct {
+	url string
+	dir string
+}
+
+// newGitRemote returns a gitRemote for the repository with the given URL,
+// cloned into a directory of the user's cache directory named after the URL.
+func newGitRemote(repoURL string) (*gitRemote, error) {
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		return nil, fmt.Errorf("cannot find a directory for the git sync remote: %w", err)
+	}
+	sum := sha256.Sum256([]byte(repoURL))
+	return &gitRemote{
+		url: repoURL,
+		dir: filepath.Join(cache, "habit", "sync", hex.EncodeToString(sum[:8])),
+	}, nil
+}
+
+// Pull fetches the repository's default branch into the clone and returns the
+// contents of its habits.json file, or nil if the repository is empty or has
+// no such file.
+func (r *gitRemote) Pull() ([]byte, error) {
+	_, err := os.Stat(filepath.Join(r.dir, ".git"))
+	if errors.Is(err, fs.ErrNotExist) {
+		err = os.MkdirAll(r.dir, 0o700)
+		if err != nil {
+			return nil, err
+		}
+		_, err = r.git("init", "--quiet")
+		if err != nil {
+			return nil, err
+		}
+		_, err = r.git("remote", "add", "origin", r.url)
+	}
+	if err != nil {
+		return nil, err
+	}
+	branch, err := r.branch()
+	if err != nil || branch == "" {
+		return nil, err
+	}
+	_, err = r.git("fetch", "--quiet", "origin", branch)
+	if err != nil {
+		return nil, err
+	}
+	_, err = r.git("reset", "--hard", "--quiet", "FETCH_HEAD")
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(r.dir, syncFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return data, err
+}
+
+// Push commits the given data as the repository's habits.json file and pushes
+// it to the repository's default branch, or to "main" if the repository is
+// empty. An error wrapping ErrRemoteChanged is returned if the push is
+// rejected because the branch moved since it was fetched.
+func (r *gitRemote) Push(data []byte) error {
+	branch, err := r.branch()
+	if err != nil {
+		return err
+	}
+	if branch == "" {
+		branch = "main"
+	}
+	err = os.WriteFile(filepath.Join(r.dir, syncFile), data, 0o600)
+	if err != nil {
+		return err
+	}
+	_, err = r.git("add", syncFile)
+	if err != nil {
+		return err
+	}
+	status, err := r.git("status", "--porcelain")
+	if err != nil || status == "" {
+		return err
+	}
+	args := []string{"commit", "--quiet", "-m", "Sync habits"}
+	if email, _ := r.git("config", "user.email"); email == "" {
+		args = append([]string{"-c", "user.name=habit", "-c", "user.email=habit@localhost"}, args...)
+	}
+	_, err = r.git(args...)
+	if err != nil {
+		return err
+	}
+	_, err = r.git("push", "--quiet", "origin", "HEAD:refs/heads/"+branch)
+	if err != nil && strings.Contains(err.Error(), "[rejected]") {
+		return fmt.Errorf("%w: %v", ErrRemoteChanged, err)
+	}
+	return err
+}
+
+// branch returns the name of the repository's default branch: the branch its
+// HEAD points to, or else "main", "master" or its first branch. An empty string
+// is returned if the repository has no branches yet.
+func (r *gitRemote) branch() (string, error) {
+	out, err := r.git("ls-remote", "--symref", "origin", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(out, "\n") {
+		ref, ok := strings.CutPrefix(line, "ref: refs/heads/")
+		if ok {
+			name, _, _ := strings.Cut(ref, "\t")
+			return name, nil
+		}
+	}
+	out, err = r.git("ls-remote", "--heads", "origin")
+	if err != nil {
+		return "", err
+	}
+	var branches []string
+	for _, line := range strings.Split(out, "\n") {
+		_, ref, ok := strings.Cut(line, "\trefs/heads/")
+		if ok {
+			branches = append(branches, ref)
+		}
+	}
+	for _, name := range []string{"main", "master"} {
+		for _, branch := range branches {
+			if branch == name {
+				return name, nil
+			}
+		}
+	}
+	if len(branches) > 0 {
+		return branches[0], nil
+	}
+	return "", nil
+}
+
+// git runs git with the given arguments in the clone and returns its trimmed
+// standard output. An error holding git's standard error is returned if git
+// fails.
+func (r *gitRemote) git(args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", fmt.Errorf("git %s: %s", args[0], msg)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// webDAVRemote is a SyncRemote that keeps the habit data in a file on a WebDAV
+// server.
+type webDAVRemote struct {
+	url      string
+	user     string
+	password string
+	client   *http.Client
+	version  remoteVersion
+}
+
+// newWebDAVRemote returns a webDAVRemote for the file at the given webdav:// or
+// webdavs:// URL.
+func newWebDAVRemote(u *url.URL) (*webDAVRemote, error) {
+	if u.Host == "" || u.Path == "" {
+		return nil, fmt.Errorf("invalid WebDAV sync remote %q (want webdav://host/path/habits.json)", u.Redacted())
+	}
+	r := &webDAVRemote{client: &http.Client{Timeout: syncTimeout}}
+	if u.User != nil {
+		r.user = u.User.Username()
+		r.password, _ = u.User.Password()
+	}
+	target := *u
+	target.User = nil
+	target.Scheme = "http"
+	if u.Scheme == "webdavs" {
+		target.Scheme = "https"
+	}
+	r.url = target.String()
+	return r, nil
+}
+
+// Pull downloads the file, returning nil if it does not exist yet.
+func (r *webDAVRemote) Pull() ([]byte, error) {
+	resp, err := r.do(http.MethodGet, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		r.version.observe(resp)
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected response status %s", resp.Status)
+	}
+	r.version.observe(resp)
+	return io.ReadAll(resp.Body)
+}
+
+// Push uploads the given data, replacing the file unless it changed since the
+// last Pull.
+func (r *webDAVRemote) Push(data []byte) error {
+	resp, err := r.do(http.MethodPut, data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusNoContent:
+		r.version.observe(resp)
+		return nil
+	case http.StatusPreconditionFailed:
+		return ErrRemoteChanged
+	}
+	return fmt.Errorf("unexpected response status %s", resp.Status)
+}
+
+// do sends a request with the given method and body to the file's URL.
+func (r *webDAVRemote) do(method string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequest(method, r.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if r.user != "" {
+		req.SetBasicAuth(r.user, r.password)
+	}
+	if method == http.MethodPut {
+		r.version.condition(req)
+	}
+	return r.client.Do(req)
+}
@@ -0,0 +1,459 @@
+package habit_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+// memRemote is an in-memory SyncRemote.
+type memRemote struct {
+	data   []byte
+	pushes int
+}
+
+func (r *memRemote) Pull() ([]byte, error) {
+	return r.data, nil
+}
+
+func (r *memRemote) Push(data []byte) error {
+	r.data = data
+	r.pushes++
+	return nil
+}
+
+func remoteHabits(t *testing.T, data []byte) map[string]habit.Habit {
+	t.Helper()
+	var vd struct {
+		Habits map[string]habit.Habit `json:"habits"`
+	}
+	err := json.Unmarshal(data, &vd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return vd.Habits
+}
+
+func TestTracker_SyncMergesHistoriesOnBothSides(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T20:00:00Z")
+	// Each completion is done an hour earlier than the day before, so that
+	// completions on consecutive days are less than 24 hours apart.
+	day := func(d int) time.Time {
+		return time.Date(2024, time.February, d, 11-d, 0, 0, 0, time.UTC)
+	}
+	remote := &memRemote{}
+	remotePath := t.TempDir() + "/remote.json"
+	remoteStore, err := habit.OpenStore(remotePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	remoteStore.Add(habit.Habit{
+		Name: "programming", CurrentStreak: 3, LongestStreak: 3, LastDone: day(4),
+		History: []habit.Completion{{At: day(2)}, {At: day(3)}, {At: day(4), Note: "on the train"}},
+		Tags:    []string{"work"},
+	})
+	remoteStore.Add(habit.Habit{Name: "reading", CurrentStreak: 1, LongestStreak: 1, LastDone: day(6),
+		History: []habit.Completion{{At: day(6)}}})
+	err = remoteStore.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	remote.data, err = os.ReadFile(remotePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := habit.OpenStore(t.TempDir() + "/local.store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{
+		Name: "programming", CurrentStreak: 2, LongestStreak: 2, LastDone: day(6),
+		History: []habit.Completion{{At: day(2)}, {At: day(3)}, {At: day(5)}, {At: day(6)}},
+		Tags:    []string{"daily"},
+	})
+	store.Add(habit.Habit{Name: "cycling", CurrentStreak: 1, LongestStreak: 1, LastDone: day(6),
+		History: []habit.Completion{{At: day(6)}}})
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Sync(remote)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := habit.Habit{
+		Name: "programming", CurrentStreak: 5, LongestStreak: 5, LastDone: day(6),
+		History: []habit.Completion{{At: day(2)}, {At: day(3)}, {At: day(4), Note: "on the train"}, {At: day(5)}, {At: day(6)}},
+		Tags:    []string{"daily", "work"},
+	}
+	got, ok := store.Get("programming")
+	if !ok {
+		t.Fatal("expected habit 'programming' to be present in store")
+	}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+	if _, ok := store.Get("reading"); !ok {
+		t.Error("expected habit 'reading' to be pulled from the remote")
+	}
+	pushed := remoteHabits(t, remote.data)
+	if len(pushed) != 3 || !cmp.Equal(want, pushed["programming"]) {
+		t.Errorf("want the 3 merged habits pushed, got %+v", pushed)
+	}
+	wantOutput := "Synced your habits: 2 habits updated here and 2 on the remote.\n"
+	if got := output.String(); wantOutput != got {
+		t.Error(cmp.Diff(wantOutput, got))
+	}
+	output.Reset()
+	err = tracker.Sync(remote)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if remote.pushes != 1 {
+		t.Errorf("want no push when already in sync, got %d pushes", remote.pushes)
+	}
+	wantOutput = "Synced your habits: 0 habits updated here and 0 on the remote.\n"
+	if got := output.String(); wantOutput != got {
+		t.Error(cmp.Diff(wantOutput, got))
+	}
+}
+
+// fileServer serves a single file that can be read with GET and replaced with
+// PUT, recording the requests it receives. Each version of the file has its
+// own ETag, and a PUT whose If-Match or If-None-Match condition does not hold
+// is refused.
+type fileServer struct {
+	mtx      sync.Mutex
+	data     []byte
+	version  int
+	requests []*http.Request
+}
+
+func (s *fileServer) etag() string {
+	return fmt.Sprintf("\"v%d\"", s.version)
+}
+
+func (s *fileServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.requests = append(s.requests, r)
+	switch r.Method {
+	case http.MethodGet:
+		if s.data == nil {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("ETag", s.etag())
+		w.Write(s.data)
+	case http.MethodPut:
+		match := r.Header.Get("If-Match")
+		if match != "" && (s.data == nil || match != s.etag()) ||
+			r.Header.Get("If-None-Match") == "*" && s.data != nil {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.data = data
+		s.version++
+		w.Header().Set("ETag", s.etag())
+	}
+}
+
+// put replaces the file as another device would.
+func (s *fileServer) put(data []byte) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.data = data
+	s.version++
+}
+
+func TestParseSyncRemote_WebDAVRemotePullsAndPushesFile(t *testing.T) {
+	t.Parallel()
+	files := &fileServer{}
+	srv := httptest.NewServer(files)
+	defer srv.Close()
+	remote, err := habit.ParseSyncRemote("webdav://me:secret@" + strings.TrimPrefix(srv.URL, "http://") + "/habit/habits.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := remote.Pull()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data != nil {
+		t.Errorf("want no data from empty remote, got %q", data)
+	}
+	err = remote.Push([]byte("habits"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err = remote.Pull()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "habits" {
+		t.Errorf("want pushed data, got %q", data)
+	}
+	r := files.requests[1]
+	user, password, ok := r.BasicAuth()
+	if r.URL.Path != "/habit/habits.json" || !ok || user != "me" || password != "secret" {
+		t.Errorf("want authenticated PUT of /habit/habits.json, got %s %s", r.Method, r.URL)
+	}
+}
+
+func TestParseSyncRemote_S3RemoteSignsRequests(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY")
+	t.Setenv("AWS_SESSION_TOKEN", "")
+	files := &fileServer{}
+	srv := httptest.NewServer(files)
+	defer srv.Close()
+	remote, err := habit.ParseSyncRemote("s3://my-bucket/backups/habits.json?region=eu-west-1&endpoint=" + srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = remote.Push([]byte("habits"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := remote.Pull()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "habits" {
+		t.Errorf("want pushed data, got %q", data)
+	}
+	r := files.requests[0]
+	if r.URL.Path != "/my-bucket/backups/habits.json" {
+		t.Errorf("want path-style object URL, got %s", r.URL.Path)
+	}
+	auth := r.Header.Get("Authorization")
+	wantPrefix := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/"
+	if !strings.HasPrefix(auth, wantPrefix) || !strings.Contains(auth, "/eu-west-1/s3/aws4_request, SignedHeaders=host;x-amz-content-sha256;x-amz-date, Signature=") {
+		t.Errorf("want SigV4 authorization, got %q", auth)
+	}
+}
+
+func TestParseSyncRemoteReturnsErrorForUnsupportedRemote(t *testing.T) {
+	t.Parallel()
+	for _, value := range []string{"ftp://example.com/habits.json", "webdav://", "habits.json"} {
+		_, err := habit.ParseSyncRemote(value)
+		if err == nil {
+			t.Errorf("%s: want error, got nil", value)
+		}
+	}
+}
+
+func TestParseSyncRemote_WebDAVRemoteRefusesPushIfFileChangedSincePull(t *testing.T) {
+	t.Parallel()
+	files := &fileServer{}
+	srv := httptest.NewServer(files)
+	defer srv.Close()
+	remote, err := habit.ParseSyncRemote("webdav://" + strings.TrimPrefix(srv.URL, "http://") + "/habits.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = remote.Pull()
+	if err != nil {
+		t.Fatal(err)
+	}
+	files.put([]byte("other device"))
+	err = remote.Push([]byte("habits"))
+	if !errors.Is(err, habit.ErrRemoteChanged) {
+		t.Fatalf("want ErrRemoteChanged pushing over a file created since pull, got %v", err)
+	}
+	_, err = remote.Pull()
+	if err != nil {
+		t.Fatal(err)
+	}
+	files.put([]byte("other device again"))
+	err = remote.Push([]byte("habits"))
+	if !errors.Is(err, habit.ErrRemoteChanged) {
+		t.Fatalf("want ErrRemoteChanged pushing over a file changed since pull, got %v", err)
+	}
+	if string(files.data) != "other device again" {
+		t.Errorf("want the other device's file kept, got %q", files.data)
+	}
+}
+
+// racingRemote is a memRemote whose data is replaced by another device's
+// between the first Pull and Push.
+type racingRemote struct {
+	memRemote
+	other []byte
+}
+
+func (r *racingRemote) Push(data []byte) error {
+	if r.other != nil {
+		r.data, r.other = r.other, nil
+		return habit.ErrRemoteChanged
+	}
+	return r.memRemote.Push(data)
+}
+
+func TestTracker_SyncStartsOverIfRemoteChangesWhileSyncing(t *testing.T) {
+	t.Parallel()
+	done := time.Date(2024, time.February, 6, 9, 0, 0, 0, time.UTC)
+	otherPath := t.TempDir() + "/other.json"
+	other, err := habit.OpenStore(otherPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other.Add(habit.Habit{Name: "reading", CurrentStreak: 1, LongestStreak: 1, LastDone: done,
+		History: []habit.Completion{{At: done}}})
+	err = other.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	remote := &racingRemote{}
+	remote.other, err = os.ReadFile(otherPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := &memStore{habits: map[string]habit.Habit{"cycling": {Name: "cycling", CurrentStreak: 1, LongestStreak: 1, LastDone: done,
+		History: []habit.Completion{{At: done}}}}}
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(io.Discard))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Sync(remote)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pushed := remoteHabits(t, remote.data)
+	if _, ok := pushed["reading"]; !ok || len(pushed) != 2 {
+		t.Errorf("want both devices' habits pushed, got %+v", pushed)
+	}
+	if _, ok := store.Get("reading"); !ok {
+		t.Error("expected habit 'reading' of the other device to be pulled")
+	}
+}
+
+func TestTracker_SyncEncryptsHabitsOfEncryptedStore(t *testing.T) {
+	t.Parallel()
+	done := time.Date(2024, time.February, 6, 9, 0, 0, 0, time.UTC)
+	store, err := habit.OpenEncryptedStore(t.TempDir()+"/habits.store", []byte("correct horse"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{Name: "meditation", CurrentStreak: 1, LongestStreak: 1, LastDone: done,
+		History: []habit.Completion{{At: done}}})
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(io.Discard))
+	if err != nil {
+		t.Fatal(err)
+	}
+	remote := &memRemote{}
+	err = tracker.Sync(remote)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(remote.data, []byte("meditation")) {
+		t.Fatalf("want pushed habits encrypted, got %q", remote.data)
+	}
+	store2, err := habit.OpenEncryptedStore(t.TempDir()+"/habits.store", []byte("correct horse"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker2, err := habit.NewTracker(habit.WithStore(store2), habit.WithOutput(io.Discard))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker2.Sync(remote)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := store2.Get("meditation"); !ok {
+		t.Error("expected habit 'meditation' to be pulled by a store with the same passphrase")
+	}
+}
+
+func TestTracker_SyncDeletesHabitsDeletedOnAnotherDevice(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T09:00:00Z")
+	remote := &memRemote{}
+	dir := t.TempDir()
+	// newDevice returns the store and tracker of a device keeping its deletion
+	// log in the given file.
+	newDevice := func(name string) (*memStore, *habit.Tracker) {
+		store := &memStore{habits: map[string]habit.Habit{}}
+		tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(io.Discard),
+			habit.WithDeletionLog(dir+"/"+name+".deleted"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return store, tracker
+	}
+	laptop, laptopTracker := newDevice("laptop")
+	desktop, desktopTracker := newDevice("desktop")
+	err := laptopTracker.TrackAll("programming", "reading", "writing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tracker := range []*habit.Tracker{laptopTracker, desktopTracker} {
+		err = tracker.Sync(remote)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	habit.Now = getTimeFunc(t, "2024-02-06T10:00:00Z")
+	err = laptopTracker.Delete("reading")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = laptopTracker.Rename("writing", "journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = desktopTracker.Delete("programming")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The laptop tracks programming after the desktop deleted it, so it is
+	// kept.
+	habit.Now = getTimeFunc(t, "2024-02-07T09:00:00Z")
+	err = laptopTracker.Track("programming")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tracker := range []*habit.Tracker{desktopTracker, laptopTracker, desktopTracker} {
+		err = tracker.Sync(remote)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []string{"journal", "programming"}
+	for name, store := range map[string]*memStore{"laptop": laptop, "desktop": desktop} {
+		var got []string
+		for _, hbt := range store.All() {
+			got = append(got, hbt.Name)
+		}
+		sort.Strings(got)
+		if !cmp.Equal(want, got) {
+			t.Errorf("%s: %s", name, cmp.Diff(want, got))
+		}
+	}
+	if got := remoteHabits(t, remote.data); len(got) != 2 {
+		t.Errorf("want 2 habits on the remote, got %+v", got)
+	}
+	if _, err := os.Stat(dir + "/laptop.deleted"); !os.IsNotExist(err) {
+		t.Errorf("want the synced deletion log removed, got %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+package habit
+
+import (
+	"fmt"
+	"text/tabwriter"
+	"time"
+)
+
+// Table statuses of a Habit.
+const (
+	statusDone    = "done"
+	statusDue     = "due"
+	statusAtRisk  = "at risk"
+	statusBroken  = "broken"
+	statusPaused  = "paused"
+	statusAvoided = "avoided"
+	statusRestDay = "rest day"
+)
+
+// PrintTable writes an aligned table of the tracked Habits with their current
+// streaks, when they were last done, a sparkline of the last 30 days and
+// whether they are due to the given Tracker's output. If any tags are given,
+// only the Habits with at least one of the tags are listed. When color is
+// enabled, the status of Habits whose streak breaks unless they are done today
+// is highlighted, as are finished and broken streaks. An error is returned if
+// the table cannot be written.
+func (t *Tracker) PrintTable(tags ...string) error {
+	habits := t.sortedHabits(false, tags...)
+	if len(habits) < 1 {
+		_, err := fmt.Fprintln(t.output, t.noHabitsMessage(tags))
+		if err != nil {
+			return fmt.Errorf("error writing table: %w", err)
+		}
+		return nil
+	}
+	now := t.now()
+	tw := tabwriter.NewWriter(t.output, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "HABIT\tSTREAK\tLONGEST\tLAST DONE\tLAST %d DAYS\tSTATUS\n", sparklineDays)
+	for _, hbt := range habits {
+		current, longest := hbt.streaks(now, t.calendar)
+		status := t.status(hbt, now)
+		if status == statusBroken {
+			current = 0
+		}
+		color := ""
+		switch status {
+		case statusDone, statusAvoided:
+			color = colorGreen
+		case statusAtRisk:
+			color = colorYellow
+		case statusBroken:
+			color = colorRed
+		}
+		// The status is the last column so that its escape sequences do not
+		// affect the alignment of the others.
+		if color != "" {
+			status = t.colorize(color, status)
+		}
+		fmt.Fprintf(tw, "%s\t%d %s\t%d %s\t%s\t%s\t%s\n", hbt.Name,
+			current, hbt.Frequency.unit(current), longest, hbt.Frequency.unit(longest),
+			t.lastDone(hbt, now), hbt.sparkline(now, t.calendar), status)
+	}
+	// The tabwriter buffers every row, so writing them fails on Flush.
+	err := tw.Flush()
+	if err != nil {
+		return fmt.Errorf("error writing table: %w", err)
+	}
+	return nil
+}
+
+// status returns the table status of the given Habit as of the given
+// timestamp.
+func (t *Tracker) status(hbt Habit, now time.Time) string {
+	switch {
+	case hbt.Avoid:
+		return statusAvoided
+	case hbt.Paused(now):
+		return statusPaused
+	case hbt.doneThisPeriod(now, t.calendar):
+		return statusDone
+	case !hbt.scheduled(now, t.calendar):
+		return statusRestDay
+	case hbt.LastDone.IsZero():
+		return statusDue
+	case hbt.missedPeriods(now, t.calendar) >= 1:
+		return statusBroken
+	}
+	// The streak breaks once a whole period passes without the habit being
+	// done, so it is at risk if today ends the current period.
+	tomorrow := t.calendar.start(now).AddDate(0, 0, 1)
+	if hbt.missedPeriods(tomorrow, t.calendar) >= 1 {
+		return statusAtRisk
+	}
+	return statusDue
+}
+
+// lastDone returns when the given Habit was last done relative to the given
+// timestamp, such as "today" or "3 days ago".
+func (t *Tracker) lastDone(hbt Habit, now time.Time) string {
+	if hbt.LastDone.IsZero() {
+		return "never"
+	}
+	switch days := t.calendar.daysBetween(hbt.LastDone, now); days {
+	case 0:
+		return "today"
+	case 1:
+		return "yesterday"
+	default:
+		return fmt.Sprintf("%d days ago", days)
+	}
+}
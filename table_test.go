@@ -0,0 +1,75 @@
+package habit_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+func newTableStore(t *testing.T) habit.Store {
+	t.Helper()
+	habit.Now = getTimeFunc(t, "2024-02-06T20:00:00Z")
+	now := habit.Now()
+	store, err := habit.OpenStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{Name: "programming", CurrentStreak: 4, LongestStreak: 9, LastDone: now.Add(-time.Hour)})
+	store.Add(habit.Habit{Name: "reading", CurrentStreak: 12, LongestStreak: 12, LastDone: now.Add(-21 * time.Hour)})
+	store.Add(habit.Habit{Name: "running", CurrentStreak: 3, LongestStreak: 5, LastDone: now.Add(-72 * time.Hour)})
+	store.Add(habit.Habit{
+		Name:          "cleaning",
+		Frequency:     habit.Weekly,
+		CurrentStreak: 2,
+		LongestStreak: 2,
+		LastDone:      now.AddDate(0, 0, -6),
+	})
+	return store
+}
+
+func TestTracker_PrintTableWritesAlignedTableOfHabits(t *testing.T) {
+	store := newTableStore(t)
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.PrintTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "HABIT        STREAK   LONGEST  LAST DONE   LAST 30 DAYS                    STATUS\n" +
+		"cleaning     2 weeks  2 weeks  6 days ago  ·······················●······  due\n" +
+		"programming  4 days   9 days   today       ·····························●  done\n" +
+		"reading      12 days  12 days  yesterday   ····························●·  at risk\n" +
+		"running      0 days   5 days   3 days ago  ··························●○○·  broken\n"
+	got := output.String()
+	if want != got {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestTracker_PrintTableColorizesStatusWhenColorIsEnabled(t *testing.T) {
+	store := newTableStore(t)
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output), habit.WithColor(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.PrintTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "HABIT        STREAK   LONGEST  LAST DONE   LAST 30 DAYS                    STATUS\n" +
+		"cleaning     2 weeks  2 weeks  6 days ago  ·······················●······  due\n" +
+		"programming  4 days   9 days   today       ·····························●  \033[32mdone\033[0m\n" +
+		"reading      12 days  12 days  yesterday   ····························●·  \033[33mat risk\033[0m\n" +
+		"running      0 days   5 days   3 days ago  ··························●○○·  \033[31mbroken\033[0m\n"
+	got := output.String()
+	if want != got {
+		t.Error(cmp.Diff(want, got))
+	}
+}
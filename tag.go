@@ -0,0 +1,97 @@
+package habit
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Tag adds the given tags, such as "health" or "morning", to the Habit with
+// the given name and saves the store. Tags the Habit already has are ignored.
+// An error is returned if the Habit does not exist, no tags are given, a tag is
+// empty, or the store cannot be saved.
+func (t *Tracker) Tag(hbtName string, tags ...string) error {
+	hbt, ok := t.store.Get(hbtName)
+	if !ok {
+		return errHabitNotFound(hbtName)
+	}
+	if len(tags) < 1 {
+		return fmt.Errorf("no tags given for habit '%s'", hbtName)
+	}
+	hbt.Tags = slices.Clone(hbt.Tags)
+	for _, tag := range tags {
+		if strings.TrimSpace(tag) == "" {
+			return fmt.Errorf("cannot tag habit '%s' with an empty tag", hbtName)
+		}
+		if !hbt.HasTag(tag) {
+			hbt.Tags = append(hbt.Tags, tag)
+		}
+	}
+	sort.Strings(hbt.Tags)
+	return t.saveTags(hbt)
+}
+
+// Untag removes the given tags from the Habit with the given name and saves
+// the store. Tags the Habit does not have are ignored. An error is returned if
+// the Habit does not exist or the store cannot be saved.
+func (t *Tracker) Untag(hbtName string, tags ...string) error {
+	hbt, ok := t.store.Get(hbtName)
+	if !ok {
+		return errHabitNotFound(hbtName)
+	}
+	var kept []string
+	for _, tag := range hbt.Tags {
+		remove := false
+		for _, untag := range tags {
+			if tag == untag {
+				remove = true
+			}
+		}
+		if !remove {
+			kept = append(kept, tag)
+		}
+	}
+	hbt.Tags = kept
+	return t.saveTags(hbt)
+}
+
+// saveTags saves the given Habit after its tags were changed and reports its
+// tags.
+func (t *Tracker) saveTags(hbt Habit) error {
+	t.store.Add(hbt)
+	err := t.save()
+	if err != nil {
+		return err
+	}
+	if len(hbt.Tags) < 1 {
+		fmt.Fprintf(t.output, "The habit '%s' has no tags.\n", hbt.Name)
+		return nil
+	}
+	fmt.Fprintf(t.output, "The habit '%s' is tagged %s.\n", hbt.Name, strings.Join(hbt.Tags, ", "))
+	return nil
+}
+
+// HasTag returns true if the Habit has the given tag.
+func (h Habit) HasTag(tag string) bool {
+	for _, t := range h.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// hasAnyTag returns true if the Habit has at least one of the given tags, or
+// if no tags are given.
+func (h Habit) hasAnyTag(tags []string) bool {
+	if len(tags) < 1 {
+		return true
+	}
+	for _, tag := range tags {
+		if h.HasTag(tag) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,139 @@
+package habit_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTracker_TagAddsSortedTagsWithoutDuplicates(t *testing.T) {
+	t.Parallel()
+	store, err := habit.OpenStore(t.TempDir() + "/test.store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{Name: "running", Tags: []string{"morning"}})
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Tag("running", "health", "morning", "outdoors")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Untag("running", "outdoors", "evening")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, ok := store.Get("running")
+	if !ok {
+		t.Fatal("expected habit 'running' to be present in store")
+	}
+	want := []string{"health", "morning"}
+	if !cmp.Equal(want, got.Tags) {
+		t.Error(cmp.Diff(want, got.Tags))
+	}
+	wantOutput := "The habit 'running' is tagged health, morning, outdoors.\n" +
+		"The habit 'running' is tagged health, morning.\n"
+	if wantOutput != output.String() {
+		t.Errorf("want output %q, got output %q", wantOutput, output.String())
+	}
+}
+
+func TestTracker_TagIsRecordedByPreview(t *testing.T) {
+	t.Parallel()
+	tags := append(make([]string, 0, 4), "morning")
+	preview := habit.NewPreview(&memStore{habits: map[string]habit.Habit{
+		"running": {Name: "running", Tags: tags},
+	}})
+	tracker, err := habit.NewTracker(habit.WithStore(preview), habit.WithOutput(new(bytes.Buffer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Tag("running", "health")
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes := preview.Changes()
+	if len(changes) != 1 {
+		t.Fatalf("want 1 change, got %+v", changes)
+	}
+	want := []string{"morning"}
+	if !cmp.Equal(want, changes[0].Before.Tags) {
+		t.Error(cmp.Diff(want, changes[0].Before.Tags))
+	}
+	want = []string{"health", "morning"}
+	if !cmp.Equal(want, changes[0].After.Tags) {
+		t.Error(cmp.Diff(want, changes[0].After.Tags))
+	}
+}
+
+func TestTracker_TagReturnsErrorForInvalidInput(t *testing.T) {
+	t.Parallel()
+	store, err := habit.OpenStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{Name: "running"})
+	tracker, err := habit.NewTracker(habit.WithStore(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Tag("swimming", "health")
+	if err == nil {
+		t.Error("expected an error tagging a habit that does not exist")
+	}
+	err = tracker.Tag("running")
+	if err == nil {
+		t.Error("expected an error tagging a habit without tags")
+	}
+	err = tracker.Tag("running", " ")
+	if err == nil {
+		t.Error("expected an error tagging a habit with an empty tag")
+	}
+}
+
+func TestTracker_PrintListAndSummaryFilterByTag(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T13:00:00Z")
+	lastDone := habit.Now().Add(-time.Hour)
+	store, err := habit.OpenStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{Name: "running", CurrentStreak: 1, LongestStreak: 1, LastDone: lastDone, Tags: []string{"health"}})
+	store.Add(habit.Habit{Name: "reading", CurrentStreak: 1, LongestStreak: 1, LastDone: lastDone, Tags: []string{"learning"}})
+	store.Add(habit.Habit{Name: "writing", CurrentStreak: 1, LongestStreak: 1, LastDone: lastDone})
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.PrintList("health", "learning")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.PrintSummary("health")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.PrintList("music")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "reading: current streak 1, longest streak 1, daily, last 30 days ·····························● [learning]\n" +
+		"running: current streak 1, longest streak 1, daily, last 30 days ·····························● [health]\n" +
+		"You are currently on a 1-day streak for 'running'. Keep it going!\n" +
+		"You're not tracking any habits tagged music.\n"
+	got := output.String()
+	if want != got {
+		t.Errorf("want output %q, got output %q", want, got)
+	}
+	summaries := tracker.Summarize("learning")
+	if len(summaries) != 1 || summaries[0].Name != "reading" {
+		t.Errorf("want summary of 'reading' only, got %v", summaries)
+	}
+}
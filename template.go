@@ -0,0 +1,155 @@
+package habit
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// A Template declares a set of Habits to create at once, such as the habits of
+// a program like "75 Hard" or the habits kept on another machine.
+type Template struct {
+	// Habits are the Habits declared by the Template.
+	Habits []HabitTemplate `yaml:"habits"`
+}
+
+// A HabitTemplate declares a single Habit of a Template.
+type HabitTemplate struct {
+	// Name is the name of the habit.
+	Name string `yaml:"name"`
+	// Frequency is "daily", "weekly" or a number of days, as accepted by
+	// ParseFrequency. An empty frequency means daily.
+	Frequency string `yaml:"frequency"`
+	// Tags are the habit's tags.
+	Tags []string `yaml:"tags"`
+	// Target is the amount to log in each period for a quantity habit, or
+	// zero.
+	Target float64 `yaml:"target"`
+	// Unit is the unit of the Target, such as "glasses".
+	Unit string `yaml:"unit"`
+	// Reminder is the preferred time of day to do the habit, as HH:MM, or
+	// empty.
+	Reminder string `yaml:"reminder"`
+	// Avoid is true if the habit is something to avoid, such as smoking.
+	Avoid bool `yaml:"avoid"`
+}
+
+// LoadTemplate reads a Template as YAML, or JSON, from the given reader. An
+// error is returned if the Template cannot be parsed or has unknown keys.
+func LoadTemplate(r io.Reader) (Template, error) {
+	var tmpl Template
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+	err := dec.Decode(&tmpl)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return Template{}, fmt.Errorf("parsing template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// habit returns the Habit declared by the HabitTemplate, with no completions,
+// or an error if any of its fields are invalid.
+func (ht HabitTemplate) habit() (Habit, error) {
+	hbt := Habit{
+		Name:   strings.TrimSpace(ht.Name),
+		Target: ht.Target,
+		Unit:   ht.Unit,
+		Avoid:  ht.Avoid,
+	}
+	if hbt.Name == "" {
+		return Habit{}, errors.New("a habit in the template has no name")
+	}
+	if ht.Frequency != "" {
+		freq, err := ParseFrequency(ht.Frequency)
+		if err != nil {
+			return Habit{}, fmt.Errorf("habit '%s': %w", hbt.Name, err)
+		}
+		hbt.Frequency = freq
+	}
+	for _, tag := range ht.Tags {
+		if strings.TrimSpace(tag) == "" {
+			return Habit{}, fmt.Errorf("habit '%s' has an empty tag", hbt.Name)
+		}
+		if !hbt.HasTag(tag) {
+			hbt.Tags = append(hbt.Tags, tag)
+		}
+	}
+	sort.Strings(hbt.Tags)
+	if ht.Target < 0 {
+		return Habit{}, fmt.Errorf("habit '%s': the target must be a positive amount", hbt.Name)
+	}
+	if ht.Target > 0 && ht.Avoid {
+		return Habit{}, fmt.Errorf("habit '%s' cannot both have a target and be a habit to avoid", hbt.Name)
+	}
+	if ht.Reminder != "" {
+		at, err := ParseTimeOfDay(ht.Reminder)
+		if err != nil {
+			return Habit{}, fmt.Errorf("habit '%s': %w", hbt.Name, err)
+		}
+		hbt.ReminderTime = &at
+	}
+	return hbt, nil
+}
+
+// CreateFromTemplate adds every Habit declared by the given Template that does
+// not exist yet to the store, saves the store once and reports the Habits that
+// were created and those that were skipped because they already exist. Habits
+// to avoid start their streak now. Nothing is created if an error is
+// returned, which happens if the Template declares no Habits, declares a Habit
+// more than once or has an invalid Habit, or if the store cannot be saved.
+func (t *Tracker) CreateFromTemplate(tmpl Template) error {
+	if len(tmpl.Habits) < 1 {
+		return errors.New("the template has no habits")
+	}
+	var habits []Habit
+	seen := map[string]bool{}
+	for _, ht := range tmpl.Habits {
+		hbt, err := ht.habit()
+		if err != nil {
+			return err
+		}
+		if seen[hbt.Name] {
+			return fmt.Errorf("habit '%s' is declared more than once in the template", hbt.Name)
+		}
+		seen[hbt.Name] = true
+		habits = append(habits, hbt)
+	}
+	now := t.now()
+	var created, skipped []string
+	for _, hbt := range habits {
+		name := fmt.Sprintf("'%s'", hbt.Name)
+		if _, ok := t.store.Get(hbt.Name); ok {
+			skipped = append(skipped, name)
+			continue
+		}
+		if hbt.Avoid {
+			hbt.LastDone = now
+		}
+		t.store.Add(hbt)
+		created = append(created, name)
+	}
+	if len(created) > 0 {
+		err := t.save()
+		if err != nil {
+			return err
+		}
+		habitOutput := "habits"
+		if len(created) == 1 {
+			habitOutput = "habit"
+		}
+		fmt.Fprintf(t.output, "Created %d %s from the template: %s.\n",
+			len(created), habitOutput, joinList(created, "and"))
+	}
+	if len(skipped) > 0 {
+		verb := "already exist"
+		if len(skipped) == 1 {
+			verb = "already exists"
+		}
+		fmt.Fprintf(t.output, "Skipped %s, which %s.\n", joinList(skipped, "and"), verb)
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package habit_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLoadTemplate_ReadsHabitsFromYAML(t *testing.T) {
+	t.Parallel()
+	input := `habits:
+  - name: reading
+    frequency: daily
+    tags: [mind]
+    target: 10
+    unit: pages
+  - name: workout
+    frequency: 2
+    reminder: "06:30"
+  - name: alcohol
+    avoid: true
+`
+	want := habit.Template{Habits: []habit.HabitTemplate{
+		{Name: "reading", Frequency: "daily", Tags: []string{"mind"}, Target: 10, Unit: "pages"},
+		{Name: "workout", Frequency: "2", Reminder: "06:30"},
+		{Name: "alcohol", Avoid: true},
+	}}
+	got, err := habit.LoadTemplate(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestLoadTemplate_ReturnsErrorForUnknownKeys(t *testing.T) {
+	t.Parallel()
+	_, err := habit.LoadTemplate(strings.NewReader("habits:\n  - name: reading\n    frequncy: daily\n"))
+	if err == nil {
+		t.Error("want error for misspelled key, got nil")
+	}
+}
+
+func TestTracker_CreateFromTemplateAddsNewHabitsAndSkipsExistingOnes(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T13:00:00Z")
+	store := &memStore{habits: map[string]habit.Habit{
+		"reading": {Name: "reading", CurrentStreak: 3, LongestStreak: 3, LastDone: habit.Now()},
+	}}
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.CreateFromTemplate(habit.Template{Habits: []habit.HabitTemplate{
+		{Name: "reading", Target: 10, Unit: "pages"},
+		{Name: "workout", Frequency: "weekly", Tags: []string{"health", "body"}, Reminder: "06:30"},
+		{Name: "water", Target: 8, Unit: "glasses"},
+		{Name: "alcohol", Avoid: true},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if store.saves != 1 {
+		t.Errorf("want store to be saved once, got %d saves", store.saves)
+	}
+	reminder := habit.TimeOfDay{Hour: 6, Minute: 30}
+	want := map[string]habit.Habit{
+		"reading": {Name: "reading", CurrentStreak: 3, LongestStreak: 3, LastDone: habit.Now()},
+		"workout": {Name: "workout", Frequency: habit.Weekly, Tags: []string{"body", "health"}, ReminderTime: &reminder},
+		"water":   {Name: "water", Target: 8, Unit: "glasses"},
+		"alcohol": {Name: "alcohol", Avoid: true, LastDone: habit.Now()},
+	}
+	if !cmp.Equal(want, store.habits) {
+		t.Error(cmp.Diff(want, store.habits))
+	}
+	wantOutput := "Created 3 habits from the template: 'workout', 'water' and 'alcohol'.\n" +
+		"Skipped 'reading', which already exists.\n"
+	if got := output.String(); wantOutput != got {
+		t.Error(cmp.Diff(wantOutput, got))
+	}
+}
+
+func TestTracker_PrintSummaryReportsHabitsFromTemplateAsNotStarted(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T13:00:00Z")
+	store := &memStore{habits: map[string]habit.Habit{}}
+	output := new(bytes.Buffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.CreateFromTemplate(habit.Template{Habits: []habit.HabitTemplate{{Name: "run"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	output.Reset()
+	err = tracker.PrintSummary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "You haven't done 'run' yet. Today's a good day to start!\n"
+	if got := output.String(); want != got {
+		t.Error(cmp.Diff(want, got))
+	}
+	summaries := tracker.Summarize()
+	if len(summaries) != 1 || summaries[0].DaysSinceDone != 0 {
+		t.Errorf("want summary with 0 days since done, got %+v", summaries)
+	}
+}
+
+func TestTracker_CreateFromTemplateCreatesNothingForInvalidTemplate(t *testing.T) {
+	t.Parallel()
+	testCases := map[string][]habit.HabitTemplate{
+		"no habits":        nil,
+		"missing name":     {{Name: "reading"}, {Frequency: "daily"}},
+		"repeated habit":   {{Name: "reading"}, {Name: "reading"}},
+		"bad frequency":    {{Name: "reading"}, {Name: "workout", Frequency: "hourly"}},
+		"bad reminder":     {{Name: "reading"}, {Name: "workout", Reminder: "6am"}},
+		"negative target":  {{Name: "reading"}, {Name: "water", Target: -1}},
+		"avoid and target": {{Name: "reading"}, {Name: "alcohol", Avoid: true, Target: 1}},
+		"empty tag":        {{Name: "reading"}, {Name: "workout", Tags: []string{" "}}},
+	}
+	for desc, habits := range testCases {
+		store := &memStore{habits: map[string]habit.Habit{}}
+		tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(io.Discard))
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = tracker.CreateFromTemplate(habit.Template{Habits: habits})
+		if err == nil {
+			t.Errorf("%s: want error, got nil", desc)
+		}
+		if len(store.habits) != 0 || store.saves != 0 {
+			t.Errorf("%s: want nothing created, got %d habits and %d saves", desc, len(store.habits), store.saves)
+		}
+	}
+}
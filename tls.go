@@ -0,0 +1,77 @@
+package habit
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// WithTLS returns a serverOption that makes the gRPC server returned by
+// Server.GRPCServer accept only TLS connections with the given configuration,
+// such as one returned by LoadTLSConfig or AutocertTLSConfig. The Server's
+// HTTP handler is served over TLS by the http.Server it is given to.
+func WithTLS(config *tls.Config) serverOption {
+	return func(s *Server) {
+		s.tlsConfig = config
+	}
+}
+
+// LoadTLSConfig returns a TLS configuration that serves the certificate in the
+// given PEM-encoded certificate file with the private key in the given key
+// file. An error is returned if either file cannot be read or parsed.
+func LoadTLSConfig(certFile, keyFile string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("error loading TLS certificate: %w", err)
+	}
+	return &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}, nil
+}
+
+// AutocertTLSConfig returns a TLS configuration that serves certificates for
+// the given domains obtained from Let's Encrypt on first use and renewed before
+// they expire, accepting its terms of service. Certificates are cached in the
+// given directory, or in the user's cache directory if it is empty, so that
+// restarts do not request new ones. Let's Encrypt must be able to reach the
+// server on port 443 of each domain to verify it.
+func AutocertTLSConfig(cacheDir string, domains ...string) (*tls.Config, error) {
+	if len(domains) == 0 {
+		return nil, errors.New("no domains to obtain certificates for")
+	}
+	if cacheDir == "" {
+		cache, err := os.UserCacheDir()
+		if err != nil {
+			return nil, fmt.Errorf("cannot find a directory for certificates: %w", err)
+		}
+		cacheDir = filepath.Join(cache, "habit", "autocert")
+	}
+	m := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(domains...),
+		Cache:      autocert.DirCache(cacheDir),
+	}
+	return m.TLSConfig(), nil
+}
+
+// Flush saves the stores of the Server's Tracker and of every user it has
+// served once the requests in progress are done, so that no change is lost
+// when the Server stops. It is meant to be called after the http.Server and
+// gRPC server serving the Server have been shut down. The context's error is
+// returned if it is done before the stores are saved, and otherwise the errors
+// saving the stores, if any.
+func (s *Server) Flush(ctx context.Context) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	errs := []error{s.tracker.saveContext(ctx)}
+	for user, store := range s.userStores {
+		err := s.tracker.withStore(store).saveContext(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error saving store of user '%s': %w", user, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,141 @@
+package habit_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+)
+
+// writeCertificate writes a self-signed certificate for localhost and its
+// private key to PEM files in a temporary directory and returns their paths.
+func writeCertificate(t *testing.T) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	err = os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func TestLoadTLSConfig_ServesRequestsOverTLSWithCertificate(t *testing.T) {
+	t.Parallel()
+	certFile, keyFile := writeCertificate(t)
+	config, err := habit.LoadTLSConfig(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := habit.OpenStore(t.TempDir() + "/test.store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(new(bytes.Buffer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewUnstartedServer(habit.NewServer(tracker, habit.WithTLS(config)))
+	srv.TLS = config
+	srv.StartTLS()
+	defer srv.Close()
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	}}
+	resp, err := client.Get(srv.URL + "/habits")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("want status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	if resp.TLS == nil || len(resp.TLS.PeerCertificates) == 0 {
+		t.Fatal("want response over TLS")
+	}
+	want := []string{"localhost"}
+	got := resp.TLS.PeerCertificates[0].DNSNames
+	if len(got) != 1 || got[0] != want[0] {
+		t.Errorf("want certificate for %v, got %v", want, got)
+	}
+}
+
+func TestLoadTLSConfig_ReturnsErrorForMissingFiles(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	_, err := habit.LoadTLSConfig(filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem"))
+	if err == nil {
+		t.Error("want error for missing certificate files")
+	}
+}
+
+func TestAutocertTLSConfig_ReturnsErrorWithoutDomains(t *testing.T) {
+	t.Parallel()
+	_, err := habit.AutocertTLSConfig(t.TempDir())
+	if err == nil {
+		t.Error("want error without domains")
+	}
+}
+
+func TestServer_FlushSavesUnsavedChanges(t *testing.T) {
+	t.Parallel()
+	path := t.TempDir() + "/test.store"
+	store, err := habit.OpenStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(new(bytes.Buffer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := habit.NewServer(tracker)
+	store.Add(habit.Habit{Name: "programming"})
+	err = srv.Flush(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved, err := habit.OpenStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, ok := saved.Get("programming")
+	if !ok {
+		t.Error("want habit 'programming' to be saved")
+	}
+}
@@ -0,0 +1,92 @@
+package habit
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+)
+
+// trayIconSize is the width and height in pixels of the tray icon.
+const trayIconSize = 32
+
+// Tray icon colors, for when Habits are still due and when all are done.
+var (
+	trayDueColor  = color.RGBA{R: 0xe0, G: 0x8a, B: 0x1e, A: 0xff}
+	trayDoneColor = color.RGBA{R: 0x2e, G: 0xa0, B: 0x43, A: 0xff}
+)
+
+// A TrayItem is a Habit listed in the menu of the tray applet started with
+// RunTray.
+type TrayItem struct {
+	// Name is the name of the Habit.
+	Name string `json:"name"`
+	// Done is true if the Habit has been done in its current period.
+	Done bool `json:"done"`
+}
+
+// A TrayStatus is what the tray applet started with RunTray shows.
+type TrayStatus struct {
+	// Title is shown next to the tray icon, such as "3 left".
+	Title string `json:"title"`
+	// Tooltip is shown when hovering over the tray icon and at the top of
+	// its menu, such as "3 habits left today".
+	Tooltip string `json:"tooltip"`
+	// Items are the Habits listed in the menu, sorted by name.
+	Items []TrayItem `json:"items"`
+	// Remaining is the number of Items not done yet.
+	Remaining int `json:"remaining"`
+}
+
+// TrayStatus returns what the tray applet shows: how many Habits remain to be
+// done today, and every Habit that is neither avoided, paused nor off its
+// schedule today, marked as done if it has been done in its current period.
+func (t *Tracker) TrayStatus() TrayStatus {
+	now := t.now()
+	var status TrayStatus
+	for _, hbt := range t.sortedHabits(false) {
+		if hbt.Avoid || hbt.Paused(now) || !hbt.scheduled(now, t.calendar) {
+			continue
+		}
+		done := hbt.doneThisPeriod(now, t.calendar)
+		if !done {
+			status.Remaining++
+		}
+		status.Items = append(status.Items, TrayItem{Name: hbt.Name, Done: done})
+	}
+	switch {
+	case len(status.Items) == 0:
+		status.Tooltip = "No habits due today"
+	case status.Remaining == 0:
+		status.Title = "All done"
+		status.Tooltip = "All habits done today"
+	default:
+		status.Title = fmt.Sprintf("%d left", status.Remaining)
+		status.Tooltip = fmt.Sprintf("%d %s left today", status.Remaining, plural(status.Remaining, "habit"))
+	}
+	return status
+}
+
+// trayIcon returns a PNG image of a filled circle to show in the tray, in
+// the due color if any Habits remain and in the done color otherwise.
+func trayIcon(remaining int) []byte {
+	fill := trayDoneColor
+	if remaining > 0 {
+		fill = trayDueColor
+	}
+	img := image.NewRGBA(image.Rect(0, 0, trayIconSize, trayIconSize))
+	r := trayIconSize / 2
+	for y := 0; y < trayIconSize; y++ {
+		for x := 0; x < trayIconSize; x++ {
+			dx, dy := x-r, y-r
+			if dx*dx+dy*dy < (r-1)*(r-1) {
+				img.Set(x, y, fill)
+			}
+		}
+	}
+	var buf bytes.Buffer
+	// Encoding an in-memory image cannot fail.
+	_ = png.Encode(&buf, img)
+	return buf.Bytes()
+}
@@ -0,0 +1,14 @@
+//go:build !((darwin && cgo && !ios) || windows || (linux && !android))
+
+package habit
+
+import (
+	"context"
+	"errors"
+)
+
+// RunTray returns an error on platforms without a system tray, and on macOS
+// when built without cgo.
+func (t *Tracker) RunTray(ctx context.Context, path string, notifier Notifier) error {
+	return errors.New("the tray applet is not supported on this platform")
+}
@@ -0,0 +1,220 @@
+//go:build (darwin && cgo && !ios) || windows || (linux && !android)
+
+package habit
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"fyne.io/systray"
+	"github.com/fsnotify/fsnotify"
+)
+
+// RunTray runs a tray applet that sits in the system tray or menu bar until
+// the given context is cancelled or the user quits it from its menu. It shows
+// how many Habits remain to be done today, as TrayStatus does, and lists them
+// in a menu where clicking a Habit tracks it, showing the tracking message
+// with the given Notifier. The store is reloaded every minute and before each
+// Habit is tracked, and if path is the path of the store file, as soon as the
+// file changes, so that the applet keeps up with habits tracked elsewhere. A
+// store opened with WithLockPerChange is locked while each Habit is tracked,
+// and if another habit process holds its lock, the Habit is not tracked and
+// the error is shown at the top of the menu. It must be called from the main
+// goroutine. An error is returned if the store
+// file cannot be watched or the store cannot be reloaded.
+func (t *Tracker) RunTray(ctx context.Context, path string, notifier Notifier) error {
+	errs := make(chan error, 1)
+	systray.Run(func() {
+		errs <- t.runTray(ctx, path, notifier)
+		systray.Quit()
+	}, nil)
+	select {
+	case err := <-errs:
+		return err
+	default:
+		return nil
+	}
+}
+
+// runTray draws the tray applet and handles clicks on its menu until the given
+// context is cancelled or the user quits.
+func (t *Tracker) runTray(ctx context.Context, path string, notifier Notifier) error {
+	var changes <-chan fsnotify.Event
+	var watchErrs <-chan error
+	if path != "" {
+		watcher, err := watchStoreFile(path)
+		if err != nil {
+			return err
+		}
+		defer watcher.Close()
+		changes, watchErrs = watcher.Events, watcher.Errors
+	}
+	ticker := time.NewTicker(DefaultWatchInterval)
+	defer ticker.Stop()
+	debounce := time.NewTimer(watchDebounce)
+	debounce.Stop()
+	defer debounce.Stop()
+	clicks := make(chan string)
+	quit := make(chan struct{})
+	// stop is closed when the menu is rebuilt, ending the goroutines that
+	// wait for clicks on its items.
+	var stop chan struct{}
+	defer func() {
+		if stop != nil {
+			close(stop)
+		}
+	}()
+	var shown *TrayStatus
+	// notice replaces the tooltip at the top of the menu until the next
+	// refresh, such as when a Habit could not be tracked because another
+	// habit process holds the store's lock.
+	var notice string
+	for {
+		err := LoadContext(ctx, t.store)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		status := t.TrayStatus()
+		if notice != "" {
+			status.Tooltip, notice = notice, ""
+		}
+		if shown == nil || status.Remaining != shown.Remaining {
+			systray.SetIcon(trayIconData(trayIcon(status.Remaining)))
+		}
+		systray.SetTitle(status.Title)
+		systray.SetTooltip(status.Tooltip)
+		if shown == nil || status.Tooltip != shown.Tooltip || !slices.Equal(status.Items, shown.Items) {
+			if stop != nil {
+				close(stop)
+			}
+			stop = make(chan struct{})
+			systray.ResetMenu()
+			addTrayMenu(status, clicks, quit, stop)
+		}
+		shown = &status
+	wait:
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-quit:
+				return nil
+			case <-ticker.C:
+				break wait
+			case <-debounce.C:
+				break wait
+			case event := <-changes:
+				if storeFileChanged(event, path) {
+					debounce.Reset(watchDebounce)
+				}
+			case err := <-watchErrs:
+				return fmt.Errorf("error watching store: %w", err)
+			case name := <-clicks:
+				err := t.trackFromTray(ctx, name, notifier)
+				if errors.Is(err, ErrStoreLocked) {
+					notice = fmt.Sprintf("'%s' not saved: %v", name, err)
+				}
+				break wait
+			}
+		}
+	}
+}
+
+// addTrayMenu adds an item for each Habit in the given status to the tray
+// menu, followed by an item to quit. The names of the Habits clicked are sent
+// to clicks, and quit is closed when the quit item is clicked, until stop is
+// closed.
+func addTrayMenu(status TrayStatus, clicks chan<- string, quit chan struct{}, stop <-chan struct{}) {
+	header := systray.AddMenuItem(status.Tooltip, "")
+	header.Disable()
+	systray.AddSeparator()
+	for _, item := range status.Items {
+		mi := systray.AddMenuItemCheckbox(item.Name, "Mark '"+item.Name+"' as done", item.Done)
+		if item.Done {
+			mi.Disable()
+			continue
+		}
+		go func(name string) {
+			select {
+			case <-mi.ClickedCh:
+				select {
+				case clicks <- name:
+				case <-stop:
+				}
+			case <-stop:
+			}
+		}(item.Name)
+	}
+	if len(status.Items) > 0 {
+		systray.AddSeparator()
+	}
+	quitItem := systray.AddMenuItem("Quit", "Quit the habit tray")
+	go func() {
+		select {
+		case <-quitItem.ClickedCh:
+			close(quit)
+		case <-stop:
+		}
+	}()
+}
+
+// trackFromTray reloads the store and tracks the Habit with the given name,
+// holding the store's lock from the reload until it is saved if it is only
+// locked while it changes, and shows the tracking message, or the error if it
+// cannot be tracked, with the given Notifier. The error is also returned, such
+// as ErrStoreLocked if another habit process holds the lock, in which case
+// nothing is saved.
+func (t *Tracker) trackFromTray(ctx context.Context, name string, notifier Notifier) error {
+	output := new(bytes.Buffer)
+	err := LoadContext(ctx, t.store)
+	if err == nil {
+		err = t.update(func() error {
+			return t.withOutput(output).Track(name)
+		})
+	}
+	message := strings.TrimSpace(output.String())
+	if err != nil {
+		message = err.Error()
+	}
+	if message != "" {
+		// A message that cannot be shown is not worth stopping the applet.
+		_ = notifier.Notify("Habit tracker", message)
+	}
+	return err
+}
+
+// trayIconData returns the given PNG image in the format the tray expects on
+// the current platform: an ICO file holding the PNG image on Windows, and the
+// PNG image itself elsewhere.
+func trayIconData(img []byte) []byte {
+	if runtime.GOOS != "windows" {
+		return img
+	}
+	var buf bytes.Buffer
+	// The ICO header and the directory entry of its single image.
+	header := struct {
+		Reserved, Type, Count       uint16
+		Width, Height, Colors, Zero uint8
+		Planes, BitCount            uint16
+		Size, Offset                uint32
+	}{
+		Type: 1, Count: 1,
+		Width: trayIconSize, Height: trayIconSize,
+		Planes: 1, BitCount: 32,
+		Size: uint32(len(img)), Offset: 22,
+	}
+	// Writing to a bytes.Buffer cannot fail.
+	_ = binary.Write(&buf, binary.LittleEndian, header)
+	buf.Write(img)
+	return buf.Bytes()
+}
@@ -0,0 +1,49 @@
+package habit_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTracker_TrayStatusCountsHabitsLeftToday(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T20:00:00Z")
+	lastDone := habit.Now().Add(-21 * time.Hour)
+	store := &memStore{habits: map[string]habit.Habit{
+		"programming": {Name: "programming", LastDone: lastDone},
+		"reading":     {Name: "reading", LastDone: lastDone},
+		"running":     {Name: "running", LastDone: lastDone, Pauses: []habit.Pause{{From: lastDone}}},
+		"smoking":     {Name: "smoking", Avoid: true},
+	}}
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(new(bytes.Buffer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := habit.TrayStatus{
+		Title:   "2 left",
+		Tooltip: "2 habits left today",
+		Items: []habit.TrayItem{
+			{Name: "programming"},
+			{Name: "reading"},
+		},
+		Remaining: 2,
+	}
+	if got := tracker.TrayStatus(); !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+	for i, name := range []string{"reading", "programming"} {
+		err = tracker.Track(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want.Items[1-i].Done = true
+		want.Remaining--
+	}
+	want.Title, want.Tooltip = "All done", "All habits done today"
+	if got := tracker.TrayStatus(); !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
@@ -0,0 +1,197 @@
+package habit
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// tuiHistoryLength is the number of most recent completions shown in the
+// details pane of the terminal UI.
+const tuiHistoryLength = 7
+
+// Terminal UI styles.
+var (
+	tuiTitleStyle    = lipgloss.NewStyle().Bold(true)
+	tuiSelectedStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("12"))
+	tuiDetailsStyle  = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).Padding(0, 1)
+	tuiHelpStyle     = lipgloss.NewStyle().Faint(true)
+)
+
+// RunTUI runs an interactive terminal UI that reads keystrokes from in and
+// draws to out until the user quits. It lists every active Habit with a
+// checkbox showing whether it has been done in its current period and its
+// streaks, and a details pane with the selected Habit's recent history, so
+// that each Habit can be tracked with a single keystroke. An error is returned
+// if the terminal cannot be used.
+func (t *Tracker) RunTUI(in io.Reader, out io.Writer) error {
+	m := &tuiModel{tracker: t}
+	m.reload()
+	_, err := tea.NewProgram(m, tea.WithInput(in), tea.WithOutput(out), tea.WithAltScreen()).Run()
+	return err
+}
+
+// tuiModel is the state of the terminal UI.
+type tuiModel struct {
+	// tracker tracks the habits shown in the UI.
+	tracker *Tracker
+	// habits are the active habits, sorted by name.
+	habits []Habit
+	// cursor is the index of the selected habit.
+	cursor int
+	// status is the output or error of the last action.
+	status string
+}
+
+// reload reads the active habits from the tracker's store, keeping the cursor
+// within the list.
+func (m *tuiModel) reload() {
+	m.habits = m.habits[:0]
+	for _, hbt := range m.tracker.sortedHabits(false) {
+		if !hbt.Avoid {
+			m.habits = append(m.habits, hbt)
+		}
+	}
+	m.cursor = max(0, min(m.cursor, len(m.habits)-1))
+}
+
+// Init returns no initial command.
+func (m *tuiModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles a keystroke: moving the cursor, tracking or undoing the
+// selected habit, or quitting.
+func (m *tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	// Keys typed faster than they are read arrive together, so each one is
+	// handled on its own.
+	if key.Type == tea.KeyRunes && len(key.Runes) > 1 {
+		var cmd tea.Cmd
+		for _, r := range key.Runes {
+			_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+			if cmd != nil {
+				return m, cmd
+			}
+		}
+		return m, nil
+	}
+	switch key.String() {
+	case "q", "esc", "ctrl+c":
+		return m, tea.Quit
+	case "up", "k":
+		m.cursor = max(0, m.cursor-1)
+	case "down", "j":
+		m.cursor = min(len(m.habits)-1, m.cursor+1)
+	case " ", "enter", "x":
+		m.act(func(t *Tracker, name string) error { return t.Track(name) })
+	case "u":
+		m.act(func(t *Tracker, name string) error { return t.Undo(name) })
+	}
+	return m, nil
+}
+
+// act calls the given action with the selected habit's name and a copy of the
+// tracker whose output becomes the status line, holding the lock of a store
+// that is only locked while it changes, then reloads the habits.
+func (m *tuiModel) act(action func(t *Tracker, name string) error) {
+	if len(m.habits) < 1 {
+		return
+	}
+	output := new(bytes.Buffer)
+	name := m.habits[m.cursor].Name
+	err := m.tracker.update(func() error {
+		return action(m.tracker.withOutput(output), name)
+	})
+	m.status = strings.TrimSpace(output.String())
+	if err != nil {
+		m.status = err.Error()
+	}
+	m.reload()
+}
+
+// View draws the habit list, the details pane and the status line.
+func (m *tuiModel) View() string {
+	now := m.tracker.now()
+	cal := m.tracker.calendar
+	var b strings.Builder
+	b.WriteString(tuiTitleStyle.Render("Habits for "+now.Format("Monday, 2 January")) + "\n\n")
+	if len(m.habits) < 1 {
+		b.WriteString("You're not currently tracking any habits.\n")
+	}
+	width := 0
+	for _, hbt := range m.habits {
+		width = max(width, len(hbt.Name))
+	}
+	for i, hbt := range m.habits {
+		check := " "
+		if hbt.doneThisPeriod(now, cal) {
+			check = "x"
+		}
+		cursor := " "
+		if i == m.cursor {
+			cursor = ">"
+		}
+		current := hbt.CurrentStreak
+		if hbt.Paused(now) || hbt.missedPeriods(now, cal) >= 1 {
+			current = 0
+		}
+		row := fmt.Sprintf("%s [%s] %-*s  %d %s", cursor, check, width, hbt.Name,
+			current, hbt.Frequency.unit(current))
+		if i == m.cursor {
+			row = tuiSelectedStyle.Render(row)
+		}
+		b.WriteString(row + "\n")
+	}
+	if len(m.habits) > 0 {
+		b.WriteString("\n" + tuiDetailsStyle.Render(m.details(m.habits[m.cursor])) + "\n")
+	}
+	if m.status != "" {
+		b.WriteString("\n" + m.status + "\n")
+	}
+	b.WriteString("\n" + tuiHelpStyle.Render("↑/↓ select • space track • u undo • q quit") + "\n")
+	return b.String()
+}
+
+// details returns the contents of the details pane for the given habit.
+func (m *tuiModel) details(hbt Habit) string {
+	loc := m.tracker.calendar.location
+	var b strings.Builder
+	b.WriteString(tuiTitleStyle.Render(hbt.Name) + "\n")
+	fmt.Fprintf(&b, "Done %s. Longest streak: %d %s. Done %d %s in total.\n",
+		hbt.Frequency, hbt.LongestStreak, hbt.Frequency.unit(hbt.LongestStreak),
+		hbt.completions(), timesUnit(hbt.completions()))
+	if len(hbt.Tags) > 0 {
+		fmt.Fprintf(&b, "Tags: %s\n", strings.Join(hbt.Tags, ", "))
+	}
+	if len(hbt.History) < 1 {
+		b.WriteString("Not done yet.")
+		return b.String()
+	}
+	b.WriteString("Recent history:")
+	history := hbt.History[max(0, len(hbt.History)-tuiHistoryLength):]
+	for i := len(history) - 1; i >= 0; i-- {
+		c := history[i]
+		line := "\n  " + c.At.In(loc).Format(logTimeFormat)
+		if c.Note != "" {
+			line += "  " + c.Note
+		}
+		b.WriteString(line)
+	}
+	return b.String()
+}
+
+// timesUnit returns "time" or "times" depending on the given count.
+func timesUnit(count int) string {
+	if count == 1 {
+		return "time"
+	}
+	return "times"
+}
@@ -0,0 +1,51 @@
+package habit_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+)
+
+func TestTracker_RunTUITracksSelectedHabitsWithOneKeystrokeEach(t *testing.T) {
+	habit.Now = getTimeFunc(t, "2024-02-06T20:00:00Z")
+	lastDone := habit.Now().Add(-21 * time.Hour)
+	store, err := habit.OpenStore(t.TempDir() + "/test.store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"programming", "reading", "running"} {
+		store.Add(habit.Habit{
+			Name:          name,
+			CurrentStreak: 3,
+			LongestStreak: 3,
+			LastDone:      lastDone,
+			History:       []habit.Completion{{At: lastDone}},
+		})
+	}
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(new(bytes.Buffer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Track programming, skip reading, track running, then quit.
+	output := new(bytes.Buffer)
+	err = tracker.RunTUI(strings.NewReader(" jj q"), output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int{"programming": 4, "reading": 3, "running": 4}
+	for name, streak := range want {
+		hbt, ok := store.Get(name)
+		if !ok {
+			t.Fatalf("want habit '%s' to exist", name)
+		}
+		if streak != hbt.CurrentStreak {
+			t.Errorf("want habit '%s' to have streak %d, got %d", name, streak, hbt.CurrentStreak)
+		}
+	}
+	if !strings.Contains(output.String(), "Nice work: you've done the habit 'running' for 4 days in a row now.") {
+		t.Errorf("want output to show the tracking message, got %q", output.String())
+	}
+}
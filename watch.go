@@ -0,0 +1,110 @@
+package habit
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// clearScreen moves the cursor to the top left of the terminal and clears it.
+const clearScreen = "\033[H\033[2J"
+
+// DefaultWatchInterval is how often Watch redraws the table of Habits when the
+// store does not change.
+const DefaultWatchInterval = time.Minute
+
+// watchDebounce is how long Watch waits after a change to the store file for
+// further changes before redrawing, so that a save that writes the file in
+// several steps redraws the table once.
+const watchDebounce = 100 * time.Millisecond
+
+// Watch clears the terminal and writes the table of the tracked Habits, as
+// PrintTable does, to the Tracker's output under a heading with the current
+// date and time, and then redraws it every interval until the given context
+// is cancelled, reloading the store first. If path is the path of the store
+// file, the table is also redrawn as soon as the file changes, such as when
+// a habit is tracked in another terminal. If any tags are given, only the
+// Habits with at least one of the tags are listed. An error is returned if
+// the store file cannot be watched, the store cannot be reloaded or the table
+// cannot be written.
+func (t *Tracker) Watch(ctx context.Context, interval time.Duration, path string, tags ...string) error {
+	if interval <= 0 {
+		return fmt.Errorf("invalid watch interval %s (want a positive duration)", interval)
+	}
+	var changes <-chan fsnotify.Event
+	var watchErrs <-chan error
+	if path != "" {
+		watcher, err := watchStoreFile(path)
+		if err != nil {
+			return err
+		}
+		defer watcher.Close()
+		changes, watchErrs = watcher.Events, watcher.Errors
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	debounce := time.NewTimer(watchDebounce)
+	debounce.Stop()
+	defer debounce.Stop()
+	for {
+		err := LoadContext(ctx, t.store)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		fmt.Fprint(t.output, clearScreen)
+		fmt.Fprintf(t.output, "Habits on %s\n\n", t.now().Format("Monday 2 January 2006, 15:04"))
+		err = t.PrintTable(tags...)
+		if err != nil {
+			return err
+		}
+	wait:
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+				break wait
+			case <-debounce.C:
+				break wait
+			case event := <-changes:
+				if storeFileChanged(event, path) {
+					debounce.Reset(watchDebounce)
+				}
+			case err := <-watchErrs:
+				return fmt.Errorf("error watching store: %w", err)
+			}
+		}
+	}
+}
+
+// watchStoreFile returns a watcher of the changes to the store file at the
+// given path.
+func watchStoreFile(path string) (*fsnotify.Watcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("error watching store: %w", err)
+	}
+	// The directory is watched rather than the file, since saving the store
+	// replaces the file with a new one.
+	err = watcher.Add(filepath.Dir(path))
+	if err != nil {
+		watcher.Close()
+		return nil, fmt.Errorf("error watching store: %w", err)
+	}
+	return watcher, nil
+}
+
+// storeFileChanged reports whether the given event of a watcher returned by
+// watchStoreFile is a change to the store file at the given path. Events for
+// the lock, temporary and journal files that accompany the store file count
+// as changes too.
+func storeFileChanged(event fsnotify.Event, path string) bool {
+	return !event.Has(fsnotify.Chmod) && strings.HasPrefix(filepath.Base(event.Name), filepath.Base(path))
+}
@@ -0,0 +1,97 @@
+package habit_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+)
+
+// lockedBuffer is a bytes.Buffer that is safe for concurrent use, so that a
+// test can read what a Tracker writes in another goroutine.
+type lockedBuffer struct {
+	buf bytes.Buffer
+	mtx sync.Mutex
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	return b.buf.String()
+}
+
+// waitForOutput fails the test unless the given buffer contains want within a
+// few seconds.
+func waitForOutput(t *testing.T, output *lockedBuffer, want string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(output.String(), want) {
+		if time.Now().After(deadline) {
+			t.Fatalf("want output containing %q, got %q", want, output.String())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestTracker_WatchRedrawsTableWhenStoreFileChanges(t *testing.T) {
+	t.Parallel()
+	path := t.TempDir() + "/habits.store"
+	store, err := habit.OpenStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(habit.Habit{Name: "reading"})
+	err = store.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	output := new(lockedBuffer)
+	tracker, err := habit.NewTracker(habit.WithStore(store), habit.WithOutput(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	go func() {
+		errs <- tracker.Watch(ctx, time.Hour, path)
+	}()
+	waitForOutput(t, output, "reading")
+	other, err := habit.OpenStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other.Add(habit.Habit{Name: "running"})
+	err = other.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForOutput(t, output, "running")
+	cancel()
+	err = <-errs
+	if err != nil {
+		t.Errorf("want no error after cancelling, got %v", err)
+	}
+}
+
+func TestTracker_WatchReturnsErrorGivenInvalidInterval(t *testing.T) {
+	t.Parallel()
+	tracker, err := habit.NewTracker(habit.WithStore(&memStore{habits: map[string]habit.Habit{}}),
+		habit.WithOutput(new(bytes.Buffer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Watch(context.Background(), 0, "")
+	if err == nil {
+		t.Error("want error for zero interval, got nil")
+	}
+}
@@ -0,0 +1,92 @@
+package habit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// A WebhookFormat is the shape of the JSON payload posted to a webhook.
+type WebhookFormat int
+
+const (
+	// WebhookJSON posts the Event itself, with its message.
+	WebhookJSON WebhookFormat = iota
+	// WebhookSlack posts the Event's message as a Slack incoming webhook
+	// message.
+	WebhookSlack
+	// WebhookDiscord posts the Event's message as a Discord webhook message.
+	WebhookDiscord
+)
+
+// webhookTimeout is how long a webhook waits for a response.
+const webhookTimeout = 10 * time.Second
+
+// A Webhook posts Events as JSON to a URL.
+type Webhook struct {
+	// URL is the URL to post Events to.
+	URL string
+	// Format is the shape of the posted payload.
+	Format WebhookFormat
+}
+
+// ParseWebhook accepts the URL of a webhook and returns the corresponding
+// Webhook, with its Format chosen from the URL: Slack and Discord webhook URLs
+// receive messages in their formats and all other URLs receive Events as
+// generic JSON. An error is returned if the URL is not an HTTP or HTTPS URL.
+func ParseWebhook(value string) (Webhook, error) {
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return Webhook{}, fmt.Errorf("invalid webhook URL %q", value)
+	}
+	hook := Webhook{URL: value}
+	switch {
+	case u.Host == "hooks.slack.com":
+		hook.Format = WebhookSlack
+	case (u.Host == "discord.com" || u.Host == "discordapp.com") && strings.HasPrefix(u.Path, "/api/webhooks/"):
+		hook.Format = WebhookDiscord
+	}
+	return hook, nil
+}
+
+// payload returns the JSON payload posted to the Webhook for the given Event.
+func (w Webhook) payload(e Event) any {
+	switch w.Format {
+	case WebhookSlack:
+		return struct {
+			Text string `json:"text"`
+		}{Text: e.Message()}
+	case WebhookDiscord:
+		return struct {
+			Content string `json:"content"`
+		}{Content: e.Message()}
+	}
+	return struct {
+		Event
+		Message string `json:"message"`
+	}{Event: e, Message: e.Message()}
+}
+
+// Handle posts the given Event to the Webhook's URL. It has the signature of
+// an EventHandler. An error is returned if the Event cannot be posted or the
+// webhook does not respond with a 2xx status.
+func (w Webhook) Handle(e Event) error {
+	data, err := json.Marshal(w.payload(e))
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: webhookTimeout}
+	resp, err := client.Post(w.URL, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("error posting to webhook: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with status %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package habit_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aculclasure/habit"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseWebhookChoosesFormatFromURL(t *testing.T) {
+	testCases := map[string]habit.WebhookFormat{
+		"https://hooks.slack.com/services/T000/B000/XXXX":    habit.WebhookSlack,
+		"https://discord.com/api/webhooks/123/abc":           habit.WebhookDiscord,
+		"https://example.com/hooks/habit":                    habit.WebhookJSON,
+		"http://localhost:9000/discord.com/api/webhooks/1/2": habit.WebhookJSON,
+	}
+	for value, want := range testCases {
+		t.Run(value, func(t *testing.T) {
+			hook, err := habit.ParseWebhook(value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want != hook.Format {
+				t.Errorf("want format %d, got %d", want, hook.Format)
+			}
+		})
+	}
+}
+
+func TestParseWebhookReturnsErrorForInvalidURL(t *testing.T) {
+	for _, value := range []string{"", "hooks.slack.com", "ftp://example.com"} {
+		_, err := habit.ParseWebhook(value)
+		if err == nil {
+			t.Errorf("want error for webhook URL %q", value)
+		}
+	}
+}
+
+func TestWebhook_HandlePostsEventInWebhookFormat(t *testing.T) {
+	event := habit.Event{
+		Type:   habit.EventStreakMilestone,
+		Habit:  "running",
+		Streak: 7,
+		At:     time.Date(2024, time.February, 6, 9, 0, 0, 0, time.UTC),
+	}
+	testCases := map[string]struct {
+		format habit.WebhookFormat
+		want   map[string]any
+	}{
+		"json": {
+			format: habit.WebhookJSON,
+			want: map[string]any{
+				"type":      "streak_milestone",
+				"habit":     "running",
+				"streak":    7.0,
+				"frequency": 0.0,
+				"at":        "2024-02-06T09:00:00Z",
+				"message":   "Reached a 7-day streak for 'running'!",
+			},
+		},
+		"slack": {
+			format: habit.WebhookSlack,
+			want:   map[string]any{"text": "Reached a 7-day streak for 'running'!"},
+		},
+		"discord": {
+			format: habit.WebhookDiscord,
+			want:   map[string]any{"content": "Reached a 7-day streak for 'running'!"},
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			var got map[string]any
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				err := json.NewDecoder(r.Body).Decode(&got)
+				if err != nil {
+					t.Error(err)
+				}
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			defer srv.Close()
+			err := habit.Webhook{URL: srv.URL, Format: tc.format}.Handle(event)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !cmp.Equal(tc.want, got) {
+				t.Error(cmp.Diff(tc.want, got))
+			}
+		})
+	}
+}
+
+func TestWebhook_HandleReturnsErrorForFailedResponse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+	err := habit.Webhook{URL: srv.URL}.Handle(habit.Event{Type: habit.EventHabitCreated, Habit: "running"})
+	if err == nil {
+		t.Error("want error for failed webhook response")
+	}
+}